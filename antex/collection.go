@@ -0,0 +1,162 @@
+package antex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// ErrNotFound is returned when no valid antenna or frequency is found.
+var ErrNotFound = errors.New("antenna not found")
+
+// Collection is a set of antennas read from ANTEX files, e.g.,
+//
+//	_, _, _, _, ants := ReadAntexFile("igs20.atx")
+//	c := Collection(ants)
+type Collection []antenna
+
+// findSat returns the satellite antenna of the satellite valid at t.
+func (c Collection) findSat(id gnss.SatID, t time.Time) (*antenna, error) {
+	for i := range c {
+		a := &c[i]
+		if s, err := gnss.ParseSatID(a.S1); err != nil || s != id {
+			continue
+		}
+		if !a.ValidFrom.IsZero() && t.Before(a.ValidFrom) {
+			continue
+		}
+		if !a.ValidUntil.IsZero() && t.After(a.ValidUntil) {
+			continue
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("%w: sat=%s, t=%v", ErrNotFound, id, t)
+}
+
+// findFreq returns the pcv of the frequency, e.g., sys='G' and freq=1 for G01.
+func (a *antenna) findFreq(sys byte, freq int) (*pcv, error) {
+	for i := range a.PCV {
+		if a.PCV[i].Sys == sys && a.PCV[i].Freq == freq {
+			return &a.PCV[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: type='%s', freq=%c%02d", ErrNotFound, a.Type, sys, freq)
+}
+
+// SatPCO returns the phase center offset (m) of the satellite antenna valid
+// at t, for the frequency freq of the satellite system (e.g., 1 for "G01").
+// The satellite IDs of the file are parsed by gnss.ParseSatID.
+//
+// The offset is given in the satellite body frame (x, y, z), which is
+// recorded in the "NORTH / EAST / UP" record of ANTEX.
+func (c Collection) SatPCO(id gnss.SatID, t time.Time, freq int) (pco [3]float64, err error) {
+	a, err := c.findSat(id, t)
+	if err != nil {
+		return pco, err
+	}
+	p, err := a.findFreq(byte(id.Sys), freq)
+	if err != nil {
+		return pco, err
+	}
+
+	// mm -> m
+	return [3]float64{p.PCO.N * 1e-3, p.PCO.E * 1e-3, p.PCO.U * 1e-3}, nil
+}
+
+// SatPCV returns the non-azimuth dependent phase center variation (m) of the
+// satellite antenna valid at t at the nadir angle (rad). The values are
+// linearly interpolated, and clamped outside the grid.
+func (c Collection) SatPCV(id gnss.SatID, t time.Time, freq int, nadir float64) (float64, error) {
+	a, err := c.findSat(id, t)
+	if err != nil {
+		return 0., err
+	}
+	p, err := a.findFreq(byte(id.Sys), freq)
+	if err != nil {
+		return 0., err
+	}
+
+	// mm -> m
+	return p.interpNoazi(nadir*180./math.Pi) * 1e-3, nil
+}
+
+// interpNoazi interpolates the non-azimuth dependent PCV (mm) at the zenith
+// (or nadir) angle zen (deg).
+func (p *pcv) interpNoazi(zen float64) float64 {
+	n := len(p.Vnonaz)
+	switch {
+	case n == 0:
+		return 0.
+	case n == 1 || p.Dzen <= 0 || zen <= p.Zen1:
+		return p.Vnonaz[0]
+	}
+
+	x := (zen - p.Zen1) / p.Dzen
+	i := int(x)
+	if i >= n-1 {
+		return p.Vnonaz[n-1]
+	}
+	a := x - float64(i)
+	return (1-a)*p.Vnonaz[i] + a*p.Vnonaz[i+1]
+}
+
+// findRcv returns the receiver antenna of the type including the radome
+// (e.g., "TRM59800.00     NONE"). The type mean calibration is preferred to
+// the individual ones.
+func (c Collection) findRcv(antType string) (*antenna, error) {
+	var found *antenna
+	for i := range c {
+		a := &c[i]
+		if a.isSatelliteAntenna || a.Type != antType {
+			continue
+		}
+		if a.S1 == "" {
+			return a, nil
+		}
+		if found == nil {
+			found = a
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: type='%s'", ErrNotFound, antType)
+	}
+	return found, nil
+}
+
+// RcvPCO returns the phase center offset (m) of the receiver antenna from the
+// antenna reference point in the order of north, east, up, for the frequency
+// freq of the satellite system sys (e.g., 'G' and 1 for "G01").
+func (c Collection) RcvPCO(antType string, sys byte, freq int) (neu [3]float64, err error) {
+	a, err := c.findRcv(antType)
+	if err != nil {
+		return neu, err
+	}
+	p, err := a.findFreq(sys, freq)
+	if err != nil {
+		return neu, err
+	}
+
+	// mm -> m
+	return [3]float64{p.PCO.N * 1e-3, p.PCO.E * 1e-3, p.PCO.U * 1e-3}, nil
+}
+
+// RcvPCV returns the non-azimuth dependent phase center variation (m) of the
+// receiver antenna at the zenith angle zen (rad), for the frequency freq of
+// the satellite system sys. The values are linearly interpolated, and
+// clamped outside the grid.
+func (c Collection) RcvPCV(antType string, sys byte, freq int, zen float64) (float64, error) {
+	a, err := c.findRcv(antType)
+	if err != nil {
+		return 0., err
+	}
+	p, err := a.findFreq(sys, freq)
+	if err != nil {
+		return 0., err
+	}
+
+	// mm -> m
+	return p.interpNoazi(zen*180./math.Pi) * 1e-3, nil
+}
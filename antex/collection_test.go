@@ -0,0 +1,112 @@
+package antex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// testAntex is an ANTEX excerpt with a satellite and a receiver antenna.
+var testAntex = func() string {
+	var b strings.Builder
+	for _, l := range [][2]string{
+		{"     1.4            M", "ANTEX VERSION / SYST"},
+		{"A                                                           ", "PCV TYPE / REFANT"},
+		{"", "END OF HEADER"},
+		{"", "START OF ANTENNA"},
+		{"BLOCK IIR-M         G05                 G050      2009-043A", "TYPE / SERIAL NO"},
+		{"                                             0    29-JAN-17", "METH / BY / # / DATE"},
+		{"     0.0", "DAZI"},
+		{"     0.0  17.0   1.0", "ZEN1 / ZEN2 / DZEN"},
+		{"     1", "# OF FREQUENCIES"},
+		{"  2009     8    17     0     0    0.0000000", "VALID FROM"},
+		{"   G01", "START OF FREQUENCY"},
+		{"      0.00      0.00    739.00", "NORTH / EAST / UP"},
+		{"   NOAZI    0.00    1.00    2.00    3.00    4.00    5.00    6.00    7.00    8.00    9.00   10.00   11.00   12.00   13.00   14.00   15.00   16.00   17.00", ""},
+		{"   G01", "END OF FREQUENCY"},
+		{"", "END OF ANTENNA"},
+		{"", "START OF ANTENNA"},
+		{"TRM59800.00     NONE", "TYPE / SERIAL NO"},
+		{"ROBOT               Geo++ GmbH           0    01-MAR-11", "METH / BY / # / DATE"},
+		{"     0.0", "DAZI"},
+		{"     0.0  90.0  45.0", "ZEN1 / ZEN2 / DZEN"},
+		{"     1", "# OF FREQUENCIES"},
+		{"   G01", "START OF FREQUENCY"},
+		{"      1.28     -0.36     66.10", "NORTH / EAST / UP"},
+		{"   NOAZI    0.00   -1.00    2.00", ""},
+		{"   G01", "END OF FREQUENCY"},
+		{"", "END OF ANTENNA"},
+	} {
+		if l[1] == "" {
+			b.WriteString(l[0] + "\n")
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%-60s%s\n", l[0], l[1]))
+	}
+	return b.String()
+}()
+
+// TestCollection checks the lookup of the satellite and the receiver PCO
+// and PCV.
+func TestCollection(t *testing.T) {
+	s := mscanner.NewScanner(strings.NewReader(testAntex))
+	ScanHeader(s)
+	c := Collection(ReadAntexData(s))
+
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pco, err := c.SatPCO(gnss.SatID{Sys: gnss.GPS, PRN: 5}, epoch, 1)
+	if err != nil || pco != [3]float64{0., 0., 0.739} {
+		t.Errorf("SatPCO: get %v, err=%v", pco, err)
+	}
+
+	// 2.5 mm at 2.5 deg by the linear interpolation, and clamped at the end
+	for _, tt := range []struct{ nadir, want float64 }{{2.5, 2.5e-3}, {30., 17e-3}} {
+		v, err := c.SatPCV(gnss.SatID{Sys: gnss.GPS, PRN: 5}, epoch, 1, tt.nadir*math.Pi/180.)
+		if err != nil || math.Abs(v-tt.want) > 1e-12 {
+			t.Errorf("SatPCV(%.1f): get %v, want %v, err=%v", tt.nadir, v, tt.want, err)
+		}
+	}
+
+	// receiver antenna; the satellite antenna is not matched
+	neu, err := c.RcvPCO("TRM59800.00     NONE", 'G', 1)
+	if err != nil || math.Abs(neu[0]-1.28e-3)+math.Abs(neu[1]+0.36e-3)+math.Abs(neu[2]-66.10e-3) > 1e-12 {
+		t.Errorf("RcvPCO: get %v, err=%v", neu, err)
+	}
+	if _, err := c.RcvPCO("BLOCK IIR-M", 'G', 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RcvPCO: satellite antenna matched: err=%v", err)
+	}
+
+	// -0.5 mm at 22.5 deg of the zenith angle, and clamped below the horizon
+	for _, tt := range []struct{ zen, want float64 }{{22.5, -0.5e-3}, {67.5, 0.5e-3}, {95., 2e-3}} {
+		v, err := c.RcvPCV("TRM59800.00     NONE", 'G', 1, tt.zen*math.Pi/180.)
+		if err != nil || math.Abs(v-tt.want) > 1e-12 {
+			t.Errorf("RcvPCV(%.1f): get %v, want %v, err=%v", tt.zen, v, tt.want, err)
+		}
+	}
+	if _, err := c.RcvPCV("TRM59800.00     NONE", 'G', 2, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RcvPCV: get err=%v, want %v", err, ErrNotFound)
+	}
+
+	// not found: another satellite, before the valid period, another frequency
+	for _, tt := range []struct {
+		id   gnss.SatID
+		t    time.Time
+		freq int
+	}{
+		{gnss.SatID{Sys: gnss.GPS, PRN: 6}, epoch, 1},
+		{gnss.SatID{Sys: gnss.Galileo, PRN: 5}, epoch, 1},
+		{gnss.SatID{Sys: gnss.GPS, PRN: 5}, time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC), 1},
+		{gnss.SatID{Sys: gnss.GPS, PRN: 5}, epoch, 2},
+	} {
+		if _, err := c.SatPCO(tt.id, tt.t, tt.freq); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%+v: get err=%v, want %v", tt, err, ErrNotFound)
+		}
+	}
+}
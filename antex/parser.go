@@ -190,6 +190,8 @@ func scanOneAntenna(s *mscanner.Scanner) (ant antenna, err error) {
 			ant.S1 = strings.TrimSpace(buf[20:40])
 			ant.S2 = strings.TrimSpace(buf[40:50])
 			ant.S3 = strings.TrimSpace(buf[50:60])
+			// S3 (COSPAR ID) is blank for receiver antennas
+			ant.isSatelliteAntenna = ant.S3 != ""
 		case "METH / BY / # / DATE":
 		case "DAZI":
 			dazi, e = strconv.ParseFloat(strings.TrimSpace(buf[:60]), 64)
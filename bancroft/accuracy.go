@@ -0,0 +1,108 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// DisplacementFunc returns the displacement (ENU, m) of the station at pos
+// (ECEF, m) at the epoch, e.g., by the solid Earth tide (see
+// tide.SolidEarth), to be removed from the solutions.
+type DisplacementFunc func(epoch time.Time, pos [3]float64) [3]float64
+
+// RemoveDisplacement returns the solution with the displacement at the epoch
+// of the solution subtracted from the position.
+func RemoveDisplacement(sol Solution, disp DisplacementFunc) Solution {
+	if sol.Err != nil || disp == nil {
+		return sol
+	}
+	d := disp(sol.Epoch, sol.Position)
+	sol.Position = coord.ENUToECEF(sol.Position, [3]float64{-d[0], -d[1], -d[2]})
+	return sol
+}
+
+// PosError is the error of a position against the reference position.
+type PosError struct {
+	// ENU is the error vector (m) in the local east, north, up frame at the
+	// reference position (see coord.ECEFToENU).
+	ENU [3]float64
+
+	// Horizontal and ThreeD are the horizontal and the 3D errors (m).
+	Horizontal, ThreeD float64
+}
+
+// EvalSolution returns the error of the solution against the reference
+// position ref (ECEF, m).
+func EvalSolution(sol Solution, ref [3]float64) PosError {
+	enu := coord.ECEFToENU(ref, sol.Position)
+	return PosError{
+		ENU:        enu,
+		Horizontal: math.Hypot(enu[0], enu[1]),
+		ThreeD:     math.Sqrt(sqr(enu[0]) + sqr(enu[1]) + sqr(enu[2])),
+	}
+}
+
+// SeriesAccuracy is the statistics of the errors of the solutions against
+// the reference position.
+type SeriesAccuracy struct {
+	// NumEpochs is the number of the solutions evaluated; the failed ones
+	// (Solution.Err != nil) are skipped.
+	NumEpochs int
+
+	// MeanENU and RMSENU are the mean and the RMS (m) of the errors in ENU,
+	// and RMSH and RMS3D are the RMS of the horizontal and the 3D errors.
+	MeanENU, RMSENU [3]float64
+	RMSH, RMS3D     float64
+
+	// CEP50 and CEP95 are the 50th and the 95th percentile (m) of the
+	// horizontal errors.
+	CEP50, CEP95 float64
+
+	// Worst is the epoch of the largest 3D error WorstError (m).
+	Worst      time.Time
+	WorstError float64
+}
+
+// EvalSeries returns the statistics of the errors of the solutions against
+// the reference position ref (ECEF, m).
+//
+// The displacement disp, e.g., of the solid Earth tide, is removed from the
+// solutions if not nil (see RemoveDisplacement).
+func EvalSeries(sols []Solution, ref [3]float64, disp DisplacementFunc) (acc SeriesAccuracy, err error) {
+	hs := make([]float64, 0, len(sols))
+	for _, s := range sols {
+		if s.Err != nil {
+			continue
+		}
+		e := EvalSolution(RemoveDisplacement(s, disp), ref)
+		for k := range 3 {
+			acc.MeanENU[k] += e.ENU[k]
+			acc.RMSENU[k] += sqr(e.ENU[k])
+		}
+		if e.ThreeD > acc.WorstError || len(hs) == 0 {
+			acc.Worst, acc.WorstError = s.Epoch, e.ThreeD
+		}
+		hs = append(hs, e.Horizontal)
+	}
+
+	n := len(hs)
+	if n == 0 {
+		return acc, fmt.Errorf("no solution to evaluate: n=%d", len(sols))
+	}
+	acc.NumEpochs = n
+
+	for k := range 3 {
+		acc.MeanENU[k] /= float64(n)
+		acc.RMSENU[k] = math.Sqrt(acc.RMSENU[k] / float64(n))
+	}
+	acc.RMSH = math.Hypot(acc.RMSENU[0], acc.RMSENU[1])
+	acc.RMS3D = math.Sqrt(sqr(acc.RMSENU[0]) + sqr(acc.RMSENU[1]) + sqr(acc.RMSENU[2]))
+
+	acc.CEP50 = percentile(hs, 0.5)
+	acc.CEP95 = percentile(hs, 0.95)
+
+	return acc, nil
+}
@@ -0,0 +1,66 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestEvalSeries checks the statistics of the known errors.
+func TestEvalSeries(t *testing.T) {
+	ref := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// horizontal errors 1, 2, ..., 4 m to the east, 3 m up, and a failure
+	var sols []Solution
+	for k := 1; k <= 4; k++ {
+		sols = append(sols, Solution{
+			Epoch:    t0.Add(time.Duration(k) * time.Second),
+			Position: coord.ENUToECEF(ref, [3]float64{float64(k), 0., 3.}),
+		})
+	}
+	sols = append(sols, Solution{Err: errors.New("failed")})
+
+	e := EvalSolution(sols[3], ref)
+	if math.Abs(e.Horizontal-4.) > 1e-6 || math.Abs(e.ThreeD-5.) > 1e-6 {
+		t.Errorf("EvalSolution: %+v", e)
+	}
+
+	acc, err := EvalSeries(sols, ref, nil)
+	if err != nil {
+		t.Fatalf("EvalSeries: %v", err)
+	}
+	for _, tt := range []struct {
+		name      string
+		get, want float64
+	}{
+		{"mean E", acc.MeanENU[0], 2.5},
+		{"mean U", acc.MeanENU[2], 3.},
+		{"RMS E", acc.RMSENU[0], math.Sqrt(30. / 4.)},
+		{"RMS 3D", acc.RMS3D, math.Sqrt(30./4. + 9.)},
+		{"CEP50", acc.CEP50, 2.5},
+		{"CEP95", acc.CEP95, 3.85},
+		{"worst", acc.WorstError, 5.},
+	} {
+		if math.Abs(tt.get-tt.want) > 1e-6 {
+			t.Errorf("%s: get %f, want %f", tt.name, tt.get, tt.want)
+		}
+	}
+	if acc.NumEpochs != 4 || !acc.Worst.Equal(sols[3].Epoch) {
+		t.Errorf("epochs=%d, worst=%v", acc.NumEpochs, acc.Worst)
+	}
+
+	// the displacement of 3 m up is removed
+	up := func(time.Time, [3]float64) [3]float64 { return [3]float64{0., 0., 3.} }
+	acc, _ = EvalSeries(sols, ref, up)
+	if math.Abs(acc.MeanENU[2]) > 1e-5 || math.Abs(acc.MeanENU[0]-2.5) > 1e-5 {
+		t.Errorf("displacement not removed: mean=%v", acc.MeanENU)
+	}
+
+	if _, err := EvalSeries(sols[4:], ref, nil); err == nil {
+		t.Errorf("no error for no solution")
+	}
+}
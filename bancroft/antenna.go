@@ -0,0 +1,111 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// SatAntenna provides the phase center offset and variation of the
+// satellite antennas, e.g., antex.Collection.
+type SatAntenna interface {
+	// SatPCO returns the phase center offset (m) in the satellite body frame.
+	SatPCO(id gnss.SatID, t time.Time, freq int) ([3]float64, error)
+
+	// SatPCV returns the phase center variation (m) at the nadir angle (rad).
+	SatPCV(id gnss.SatID, t time.Time, freq int, nadir float64) (float64, error)
+}
+
+// ApplySatAntenna corrects the satellite positions referring to the center
+// of mass (e.g., SP3) to the antenna phase center, and removes the nadir
+// dependent PCV from the pseudoranges.
+//
+// The PCO in the satellite body frame is rotated into ECEF by the nominal
+// yaw attitude: the z-axis points to the geocenter, the y-axis is normal to
+// the plane of the Sun and the satellite, and the x-axis completes the
+// right-handed frame. rcv is the approximate receiver position and sun is
+// the Sun position (ECEF, m), e.g., coord.SunPositionECEF. freq is the ANTEX
+// frequency number (e.g., 1 for "G01").
+//
+// The satellites without ID or without a valid entry for the epoch are left
+// uncorrected, and their labels are returned in missing.
+func ApplySatAntenna(satDatas []SatData, t time.Time, ant SatAntenna, freq int, rcv, sun [3]float64) (corrected []SatData, missing []string, err error) {
+	corrected = make([]SatData, len(satDatas))
+	copy(corrected, satDatas)
+
+	for i, s := range satDatas {
+		if s.ID.IsZero() {
+			missing = append(missing, s.Label(i))
+			continue
+		}
+
+		pco, e1 := ant.SatPCO(s.ID, t, freq)
+		if e1 != nil {
+			missing = append(missing, s.Label(i))
+			continue
+		}
+
+		ex, ey, ez, e2 := satAttitude([3]float64{s.X, s.Y, s.Z}, sun)
+		if e2 != nil {
+			return nil, nil, fmt.Errorf("sat=%s: %w", s.Label(i), e2)
+		}
+
+		// the corrections are applied after both the PCO and the PCV are
+		// found, leaving the satellite uncorrected if either is missing
+		pos := [3]float64{s.X, s.Y, s.Z}
+		for k := range 3 {
+			pos[k] += pco[0]*ex[k] + pco[1]*ey[k] + pco[2]*ez[k]
+		}
+
+		// nadir angle of the receiver seen from the satellite
+		e := lineOfSight(pos, rcv[0], rcv[1], rcv[2])
+		nadir := math.Acos(math.Max(-1., math.Min(1., e[0]*ez[0]+e[1]*ez[1]+e[2]*ez[2])))
+
+		pcv, e3 := ant.SatPCV(s.ID, t, freq, nadir)
+		if e3 != nil {
+			missing = append(missing, s.Label(i))
+			continue
+		}
+		c := &corrected[i]
+		c.X, c.Y, c.Z = pos[0], pos[1], pos[2]
+		c.PR -= pcv
+	}
+
+	return corrected, missing, nil
+}
+
+// satAttitude returns the unit vectors of the satellite body frame in ECEF
+// for the nominal yaw attitude.
+func satAttitude(sat, sun [3]float64) (ex, ey, ez [3]float64, err error) {
+	r := math.Sqrt(sqr(sat[0]) + sqr(sat[1]) + sqr(sat[2]))
+	if r == 0 {
+		return ex, ey, ez, fmt.Errorf("%w: zero satellite position", ErrBadInput)
+	}
+	for k := range 3 {
+		ez[k] = -sat[k] / r
+	}
+
+	es := lineOfSight(sat, sun[0], sun[1], sun[2])
+	ey = cross(ez, es)
+	ny := math.Sqrt(sqr(ey[0]) + sqr(ey[1]) + sqr(ey[2]))
+	if ny == 0 || math.IsNaN(ny) {
+		return ex, ey, ez, fmt.Errorf("%w: attitude undefined for the Sun position", ErrBadInput)
+	}
+	for k := range 3 {
+		ey[k] /= ny
+	}
+	ex = cross(ey, ez)
+
+	return ex, ey, ez, nil
+}
+
+// cross returns the cross product a x b.
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
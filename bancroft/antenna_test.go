@@ -0,0 +1,101 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// testSatAntenna is a SatAntenna with the radial PCO and the PCV linear to
+// the nadir angle for the satellites in the map, where the PCV is not found
+// for the negative PCO.
+type testSatAntenna map[gnss.SatID]float64
+
+func (a testSatAntenna) SatPCO(id gnss.SatID, t time.Time, freq int) ([3]float64, error) {
+	z, ok := a[id]
+	if !ok {
+		return [3]float64{}, errors.New("not found")
+	}
+	return [3]float64{0., 0., z}, nil
+}
+
+func (a testSatAntenna) SatPCV(id gnss.SatID, t time.Time, freq int, nadir float64) (float64, error) {
+	if a[id] < 0 {
+		return 0, errors.New("not found")
+	}
+	return 0.01 * nadir, nil
+}
+
+// TestApplySatAntenna checks the PCO along the radial direction and the PCV
+// at the nadir angle, and the report of the missing satellites.
+func TestApplySatAntenna(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sun := [3]float64{1.4e11, 3e10, -2e10}
+
+	satDatas := testSatDatas()
+	satDatas[8].ID = gnss.SatID{}
+
+	ant := testSatAntenna{}
+	for _, id := range satIDs[:7] {
+		ant[id] = 2.
+	}
+
+	corrected, missing, err := ApplySatAntenna(satDatas, time.Time{}, ant, 1, site, sun)
+	if err != nil {
+		t.Fatalf("ApplySatAntenna: %v", err)
+	}
+	if want := []string{"G19", "#8"}; !slices.Equal(missing, want) {
+		t.Errorf("missing: get %v, want %v", missing, want)
+	}
+
+	for i, s := range satDatas[:7] {
+		c := corrected[i]
+		r0 := math.Sqrt(sqr(s.X) + sqr(s.Y) + sqr(s.Z))
+		r1 := math.Sqrt(sqr(c.X) + sqr(c.Y) + sqr(c.Z))
+		if math.Abs(r0-r1-2.) > 1e-6 {
+			t.Errorf("%s: radial shift=%f, want 2", s.ID, r0-r1)
+		}
+
+		// the nadir angle is below 14 deg for the visible satellites
+		if d := s.PR - c.PR; !(d > 0 && d < 0.01*14*math.Pi/180.) {
+			t.Errorf("%s: PCV=%f", s.ID, d)
+		}
+	}
+	for i := 7; i < 9; i++ {
+		if corrected[i] != satDatas[i] {
+			t.Errorf("%s corrected: %+v", satDatas[i].Label(i), corrected[i])
+		}
+	}
+}
+
+// TestApplySatAntennaNoPCV checks the satellite of the PCO but without the
+// PCV is left uncorrected.
+func TestApplySatAntennaNoPCV(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sun := [3]float64{1.4e11, 3e10, -2e10}
+
+	satDatas := testSatDatas()
+	ant := testSatAntenna{}
+	for _, id := range satIDs {
+		ant[id] = 2.
+	}
+	ant[satIDs[2]] = -2.
+
+	corrected, missing, err := ApplySatAntenna(satDatas, time.Time{}, ant, 1, site, sun)
+	if err != nil {
+		t.Fatalf("ApplySatAntenna: %v", err)
+	}
+	if want := []string{satIDs[2].String()}; !slices.Equal(missing, want) {
+		t.Errorf("missing: get %v, want %v", missing, want)
+	}
+	if corrected[2] != satDatas[2] {
+		t.Errorf("%s corrected: get %+v, want %+v", satIDs[2], corrected[2], satDatas[2])
+	}
+	if corrected[3] == satDatas[3] {
+		t.Errorf("%s not corrected", satIDs[3])
+	}
+}
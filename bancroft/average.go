@@ -0,0 +1,152 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// AveragerOpts defines the thresholds to reject the epochs in Averager.
+// The tests are disabled if zero.
+type AveragerOpts struct {
+	// MaxPDOP is the threshold of Solution.DOP.PDOP. The epochs without
+	// the DOP values are not tested.
+	MaxPDOP float64
+
+	// MaxRMS is the threshold (m) of Solution.RMS.
+	MaxRMS float64
+
+	// Displacement is removed from the solutions before averaging if not
+	// nil, e.g., the solid Earth tide (see RemoveDisplacement).
+	Displacement DisplacementFunc
+}
+
+// Averager accumulates the solutions of a static receiver into the weighted
+// mean position with the scatter, one epoch at a time.
+//
+// The weight of an epoch is the inverse of the trace of the position
+// covariance if Solution.Cov is given, or the inverse of the squared
+// Solution.RMS otherwise. The mean and the scatter are updated by the
+// weighted incremental algorithm (West, 1979), so the solutions need not be
+// kept in memory.
+type Averager struct {
+	opts AveragerOpts
+
+	// origin of the local ENU frame: the first accepted position
+	origin [3]float64
+
+	nepoch, nrej int
+	first, last  time.Time
+
+	// sum of the weights and of the squared weights
+	w1, w2 float64
+
+	// weighted mean (ENU, m) and sum of the weighted squared deviations
+	mean, m2 [3]float64
+}
+
+// AverageReport is the result of Averager.
+type AverageReport struct {
+	// Position is the weighted mean position (ECEF, m).
+	Position [3]float64
+
+	// NumEpochs is the number of the epochs averaged, and NumRejected is the
+	// number of the epochs rejected by the errors or the thresholds.
+	NumEpochs, NumRejected int
+
+	// First and Last are the first and the last epoch averaged.
+	First, Last time.Time
+
+	// SigmaENU is the weighted standard deviation (m) of the epoch positions
+	// in the local east, north, up frame at the first accepted position.
+	SigmaENU [3]float64
+
+	// SigmaMean is the formal standard error (m) of the mean in ENU, i.e.,
+	// SigmaENU divided by the square root of the effective number of the
+	// epochs. Note that it is optimistic for the time-correlated errors,
+	// e.g., of the multipath and the atmospheric delays.
+	SigmaMean [3]float64
+}
+
+// NewAverager returns a new Averager with the options.
+func NewAverager(opts AveragerOpts) *Averager {
+	return &Averager{opts: opts}
+}
+
+// Add adds the solution of an epoch, and reports whether it was accepted.
+func (a *Averager) Add(sol Solution) bool {
+	if !a.accept(sol) {
+		a.nrej++
+		return false
+	}
+
+	sol = RemoveDisplacement(sol, a.opts.Displacement)
+	w := epochWeight(sol)
+	if a.nepoch == 0 {
+		a.origin = sol.Position
+		a.first = sol.Epoch
+	}
+	a.nepoch++
+	a.last = sol.Epoch
+
+	enu := coord.ECEFToENU(a.origin, sol.Position)
+	a.w1 += w
+	a.w2 += w * w
+	for k := range 3 {
+		d := enu[k] - a.mean[k]
+		a.mean[k] += w / a.w1 * d
+		a.m2[k] += w * d * (enu[k] - a.mean[k])
+	}
+
+	return true
+}
+
+// accept tests the solution by the error and the thresholds.
+func (a *Averager) accept(sol Solution) bool {
+	switch {
+	case sol.Err != nil:
+		return false
+	case a.opts.MaxPDOP > 0 && sol.DOP.PDOP > a.opts.MaxPDOP:
+		return false
+	case a.opts.MaxRMS > 0 && !(sol.RMS <= a.opts.MaxRMS):
+		return false
+	}
+	return true
+}
+
+// epochWeight returns the weight of the solution.
+func epochWeight(sol Solution) float64 {
+	if sol.Cov != nil {
+		if tr := sol.Cov.At(0, 0) + sol.Cov.At(1, 1) + sol.Cov.At(2, 2); tr > 0 {
+			return 1. / tr
+		}
+	}
+	if sol.RMS > 0 {
+		return 1. / sqr(sol.RMS)
+	}
+	return 1.
+}
+
+// Report returns the result of the epochs added so far.
+func (a *Averager) Report() (rep AverageReport, err error) {
+	rep.NumEpochs, rep.NumRejected = a.nepoch, a.nrej
+	if a.nepoch == 0 {
+		return rep, fmt.Errorf("no epoch averaged: rejected=%d", a.nrej)
+	}
+	rep.First, rep.Last = a.first, a.last
+	rep.Position = coord.ENUToECEF(a.origin, a.mean)
+
+	// unbiased weighted variance for the reliability weights, and the
+	// effective number of the epochs
+	neff := a.w1 * a.w1 / a.w2
+	if a.nepoch > 1 {
+		for k := range 3 {
+			rep.SigmaENU[k] = math.Sqrt(a.m2[k] / (a.w1 - a.w2/a.w1))
+			rep.SigmaMean[k] = rep.SigmaENU[k] / math.Sqrt(neff)
+		}
+	}
+
+	return rep, nil
+}
@@ -0,0 +1,81 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestAverager checks the mean and the scatter of the synthetic solutions
+// with the known noise, and the rejection by the thresholds.
+func TestAverager(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sigma := [3]float64{1., 1.5, 3.}
+
+	a := NewAverager(AveragerOpts{MaxPDOP: 6., MaxRMS: 10.})
+	if _, err := a.Report(); err == nil {
+		t.Errorf("no error for empty averager")
+	}
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := 5000
+	for k := range n {
+		enu := [3]float64{sigma[0] * rng.NormFloat64(), sigma[1] * rng.NormFloat64(), sigma[2] * rng.NormFloat64()}
+		sol := Solution{
+			Epoch:    t0.Add(time.Duration(k) * time.Second),
+			Position: coord.ENUToECEF(site, enu),
+			RMS:      2.,
+			DOP:      DOP{PDOP: 2.},
+		}
+		switch k % 100 {
+		case 1:
+			sol.Err = errors.New("failed")
+		case 2:
+			sol.DOP.PDOP = 20.
+		case 3:
+			sol.RMS = 50.
+		}
+		a.Add(sol)
+	}
+
+	rep, err := a.Report()
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if rep.NumRejected != 150 || rep.NumEpochs != n-150 {
+		t.Errorf("epochs=%d, rejected=%d", rep.NumEpochs, rep.NumRejected)
+	}
+	if !rep.First.Equal(t0) || !rep.Last.Equal(t0.Add(time.Duration(n-1)*time.Second)) {
+		t.Errorf("first=%v, last=%v", rep.First, rep.Last)
+	}
+
+	d := coord.ECEFToENU(site, rep.Position)
+	for k := range 3 {
+		if math.Abs(d[k]) > 4.*rep.SigmaMean[k] {
+			t.Errorf("mean[%d]: %f, sigma=%f", k, d[k], rep.SigmaMean[k])
+		}
+		if math.Abs(rep.SigmaENU[k]/sigma[k]-1.) > 0.05 {
+			t.Errorf("sigma[%d]: get %f, want %f", k, rep.SigmaENU[k], sigma[k])
+		}
+	}
+}
+
+// TestAveragerWeights checks that the epochs of the smaller RMS dominate
+// the mean.
+func TestAveragerWeights(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	a := NewAverager(AveragerOpts{})
+	a.Add(Solution{Position: coord.ENUToECEF(site, [3]float64{0., 0., 0.}), RMS: 1.})
+	a.Add(Solution{Position: coord.ENUToECEF(site, [3]float64{0., 0., 9.}), RMS: 3.})
+
+	rep, _ := a.Report()
+	if d := coord.ECEFToENU(site, rep.Position); math.Abs(d[2]-0.9) > 1e-6 {
+		t.Errorf("weighted mean: get %f, want 0.9", d[2])
+	}
+}
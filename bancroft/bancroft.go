@@ -27,6 +27,13 @@ Example:
 
 	fmt.Printf("pos: x=%.3f, y=%.3f, z=%.3f, dt=%e\n", x, y, z, dt)
 
+Concurrency:
+
+The functions of this package do not share any mutable state, and are safe
+for concurrent use by multiple goroutines as long as the input slices are not
+modified during the call. See CalcPosSeriesParallel for the parallel batch
+processing.
+
 Reference:
 
 S. Bancroft, "An Algebraic Solution of the GPS Equations," in IEEE Transactions on Aerospace and Electronic Systems, vol. AES-21, no. 1, pp. 56-59, Jan. 1985, doi: 10.1109/TAES.1985.310538.
@@ -37,6 +44,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/satoshi-pes/gnss"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -45,15 +53,74 @@ const LightVelocity = 299792458.
 
 // SatData defines the input data for Bancroft().
 // X, Y, Z (m) are the satellite position, and PR is the pseudorange (m).
+//
+// The receiver clock bias dt (s) solved in this package is defined by
+//
+//	PR = |sat - rcv| - c*dt
+//
+// following the 4th component of the Bancroft solution, i.e., dt is the
+// negative of the usual receiver clock offset.
 // X, Y, Z may be modified by the traveltime (PR/C), and PR could be corrected
 // by known biases such as tropospheric delay before the call of Bancroft().
+//
+// ID (e.g., G14) is used to report the satellite in the errors and the
+// diagnostics; the index in the input is used if zero. ID.Sys gives the
+// satellite system for CalcPosMultiSys, which requires the IDs.
+// CN0 is used by WeightModel, and 0 means unknown.
 type SatData struct {
-	X, Y, Z float64 // satellite position (m)
-	PR      float64 // pseudorange (m)
+	X, Y, Z float64    // satellite position (m)
+	PR      float64    // pseudorange (m)
+	ID      gnss.SatID // satellite ID (optional)
+	CN0     float64    // carrier-to-noise density ratio (dB-Hz, optional)
+}
+
+// Label returns the ID of the satellite, e.g., "G14", or "#i" for the index i
+// if the ID is zero.
+func (s SatData) Label(i int) string {
+	if !s.ID.IsZero() {
+		return s.ID.String()
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+// Candidate is one of the two possible solutions of the Bancroft equation.
+// X, Y, Z (m) are the receiver position, Dt (s) is the receiver clock bias,
+// and Residual (m) is the RMS of the pseudorange residuals for the candidate.
+type Candidate struct {
+	X, Y, Z, Dt float64
+	Residual    float64
 }
 
 // CalcPos solves the GNSS equation using Bancroft method (Bancroft, 1985).
+//
+// Of the two candidates given by CalcPosAll, the solution closer to the
+// Earth's surface is adopted. See CalcPosWithOpts to change the behavior.
 func CalcPos(satDatas []SatData) (x, y, z, dt float64, err error) {
+	return CalcPosWithOpts(satDatas, CalcPosOpts{})
+}
+
+// CalcPosAll solves the GNSS equation using Bancroft method (Bancroft, 1985),
+// and returns both candidate solutions of the quadratic equation (eq.15)
+// without selecting one. The selection is left to the caller.
+//
+// Non-finite values and zero satellite positions are rejected with
+// ErrBadInput, and ErrNoRealSolution is returned if the quadratic equation
+// has no real root.
+//
+// With exactly four satellites, the allocation-free solver calcPosAll4 is
+// used instead of the general one.
+func CalcPosAll(satDatas []SatData) (cands [2]Candidate, err error) {
+	if err = checkSatDatas(satDatas, false); err != nil {
+		return cands, err
+	}
+	if len(satDatas) == 4 {
+		return calcPosAll4(satDatas)
+	}
+	return calcPosAllN(satDatas)
+}
+
+// calcPosAllN is the general solver of CalcPosAll using gonum.
+func calcPosAllN(satDatas []SatData) (cands [2]Candidate, err error) {
 	// make B matrix and i0, r vectors
 	//
 	// A  = (a1, a2, ..., an)'  (eq.5)
@@ -66,69 +133,79 @@ func CalcPos(satDatas []SatData) (x, y, z, dt float64, err error) {
 	//
 	B, r, i0, err := constructBancroftMatrices(satDatas)
 	if err != nil {
-		return 0., 0., 0., 0., err
+		return cands, err
 	}
 
 	// solve the quadratic equation by Bancroft for lambda:
 	// <u,u>lam^2 + 2(<u,v>-1)lam + <v,v> = 0   (eq.15)
-	var u, v mat.VecDense
-	u.MulVec(B, i0) // (eq.10)
-	v.MulVec(B, r)  // (eq.11)
+	var u, v [4]float64
+	mat.NewVecDense(4, u[:]).MulVec(B, i0) // (eq.10)
+	mat.NewVecDense(4, v[:]).MulVec(B, r)  // (eq.11)
+
+	return bancroftCandidates(satDatas, u, v)
+}
 
+// bancroftCandidates returns the two candidates of the solution (eq.16) by
+// the roots of the quadratic equation for u = B i0 and v = B r, which is
+// common to the solvers.
+func bancroftCandidates(satDatas []SatData, u, v [4]float64) (cands [2]Candidate, err error) {
 	lam1, lam2, err := solveBancroftQuadraticEq(u, v)
 	if err != nil {
-		return 0., 0., 0., 0., err
+		return cands, err
 	}
 
 	// (eq.16)
 	// possible two solutions
-	s1 := make([]float64, 4)
-	s2 := make([]float64, 4)
-
-	for i := range 4 {
-		s1[i] = lam1*u.AtVec(i) + v.AtVec(i)
-		s2[i] = lam2*u.AtVec(i) + v.AtVec(i)
+	for k, lam := range [2]float64{lam1, lam2} {
+		var s [4]float64
+		for i := range 4 {
+			s[i] = lam*u[i] + v[i]
+		}
+		cands[k] = newCandidate(satDatas, s)
 	}
 
-	// test two possible solutions.
-	// the solution closer to the Earth's surface is adopted as the true solution.
-	EarthRadius := 6378000. // Earth's radius (m)
-	res1 := math.Abs(EarthRadius - math.Sqrt(sqr(s1[0])+sqr(s1[1])+sqr(s1[2])))
-	res2 := math.Abs(EarthRadius - math.Sqrt(sqr(s2[0])+sqr(s2[1])+sqr(s2[2])))
+	return cands, nil
+}
 
-	x, y, z, dt = s1[0], s1[1], s1[2], s1[3]/LightVelocity
-	if res2 < res1 {
-		x, y, z, dt = s2[0], s2[1], s2[2], s2[3]/LightVelocity
+// newCandidate returns the Candidate for the solution s = (x, y, z, c*dt).
+func newCandidate(satDatas []SatData, s [4]float64) Candidate {
+	return Candidate{
+		X:        s[0],
+		Y:        s[1],
+		Z:        s[2],
+		Dt:       s[3] / LightVelocity,
+		Residual: residualRMS(satDatas, s[:]),
 	}
-
-	return x, y, z, dt, nil
 }
 
-func solveBancroftQuadraticEq(u, v mat.VecDense) (lam1, lam2 float64, err error) {
-	// (eq.12)
-	E, err := minkowski4D(u, u)
-	if err != nil {
-		return 0., 0., err
+// residualRMS returns the RMS of the pseudorange residuals for the solution
+// s = (x, y, z, c*dt).
+func residualRMS(satDatas []SatData, s []float64) float64 {
+	var sum float64
+	for _, sd := range satDatas {
+		rho := math.Sqrt(sqr(sd.X-s[0]) + sqr(sd.Y-s[1]) + sqr(sd.Z-s[2]))
+		sum += sqr(sd.PR - (rho - s[3]))
 	}
+	return math.Sqrt(sum / float64(len(satDatas)))
+}
 
-	// (eq.13)
-	uv, err := minkowski4D(u, v)
-	if err != nil {
-		return 0., 0., err
-	}
+// solveBancroftQuadraticEq returns the roots of the quadratic equation of
+// Bancroft (eq.15) for u = B i0 and v = B r.
+func solveBancroftQuadraticEq(u, v [4]float64) (lam1, lam2 float64, err error) {
+	// (eq.12)-(eq.14)
+	E, _ := calcMinkowski4D(u[:], u[:])
+	uv, _ := calcMinkowski4D(u[:], v[:])
 	F := uv - 1.
-
-	// (eq.14)
-	G, err := minkowski4D(v, v)
-	if err != nil {
-		return 0., 0., err
-	}
+	G, _ := calcMinkowski4D(v[:], v[:])
 
 	// (eq.15)
 	// solve the quadratic equation Ex^2 + 2Fx + G = 0
-	a, b, c := E, F, G
-	lam1 = (-b + math.Sqrt(b*b-a*c)) / a // solution1
-	lam2 = (-b - math.Sqrt(b*b-a*c)) / a // solution2
+	d := F*F - E*G
+	if d < 0 {
+		return 0., 0., fmt.Errorf("%w: discriminant=%e", ErrNoRealSolution, d)
+	}
+	lam1 = (-F + math.Sqrt(d)) / E // solution1
+	lam2 = (-F - math.Sqrt(d)) / E // solution2
 
 	return lam1, lam2, nil
 }
@@ -146,7 +223,7 @@ func solveBancroftQuadraticEq(u, v mat.VecDense) (lam1, lam2 float64, err error)
 func constructBancroftMatrices(satDatas []SatData) (B *mat.Dense, r, i0 *mat.VecDense, err error) {
 	n := len(satDatas)
 	if n < 4 {
-		err = fmt.Errorf("not enough satellite")
+		err = fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
 		return
 	}
 
@@ -173,10 +250,11 @@ func constructBancroftMatrices(satDatas []SatData) (B *mat.Dense, r, i0 *mat.Vec
 	case n == 4:
 		err = B.Inverse(A)
 		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrSingularGeometry, err)
 			return
 		}
 	case n > 4:
-		B, err = generalizedInverse(A)
+		B, _, err = generalizedInverse(A)
 		if err != nil {
 			return
 		}
@@ -185,14 +263,55 @@ func constructBancroftMatrices(satDatas []SatData) (B *mat.Dense, r, i0 *mat.Vec
 	return B, r, i0, nil
 }
 
+// GeometryCond returns the condition number of the matrix A (eq.5) for the
+// satellites, estimated from its QR decomposition. A large value indicates
+// that the solution is sensitive to the errors of the inputs, and
+// ErrSingularGeometry is returned above 1e12.
+func GeometryCond(satDatas []SatData) (float64, error) {
+	if len(satDatas) < 4 {
+		return 0., fmt.Errorf("%w: n=%d", ErrTooFewSats, len(satDatas))
+	}
+
+	A := mat.NewDense(len(satDatas), 4, nil)
+	for i, s := range satDatas {
+		A.SetRow(i, []float64{s.X, s.Y, s.Z, s.PR})
+	}
+
+	var qr mat.QR
+	qr.Factorize(A)
+	cond := qr.Cond()
+	if !(cond < maxCond) {
+		return cond, fmt.Errorf("%w: cond=%e", ErrSingularGeometry, cond)
+	}
+
+	return cond, nil
+}
+
 // Minkowski4D returns following result for two 4-dimensional vectors.
 // <a,b> = a1*b1 + a2*b2 + a3*b3 - a4*b4
 //
 // Note that above operation is similar to the spacetime interval for
 // the coordinate system (x, y, z, ct):
 // ds^2 = dx^2 + dy^2 + dz^2 - (cdt)^2
-func minkowski4D(a, b mat.VecDense) (float64, error) {
-	return calcMinkowski4D(a.RawVector().Data, b.RawVector().Data)
+//
+// An error is returned if the length of a or b is not 4.
+func Minkowski4D(a, b mat.Vector) (float64, error) {
+	if a.Len() != 4 || b.Len() != 4 {
+		return 0., fmt.Errorf("invalid vector size: len(a)=%d, len(b)=%d", a.Len(), b.Len())
+	}
+
+	// fast path on the raw slices
+	ra, oka := a.(mat.RawVectorer)
+	rb, okb := b.(mat.RawVectorer)
+	if oka && okb && ra.RawVector().Inc == 1 && rb.RawVector().Inc == 1 {
+		return calcMinkowski4D(ra.RawVector().Data[:4], rb.RawVector().Data[:4])
+	}
+
+	var va, vb [4]float64
+	for i := range 4 {
+		va[i], vb[i] = a.AtVec(i), b.AtVec(i)
+	}
+	return calcMinkowski4D(va[:], vb[:])
 }
 
 func calcMinkowski4D(a, b []float64) (v float64, err error) {
@@ -203,21 +322,36 @@ func calcMinkowski4D(a, b []float64) (v float64, err error) {
 	return v, nil
 }
 
-// generalizedInverse returns the generalized inverse of the given matrix A.
-func generalizedInverse(A *mat.Dense) (*mat.Dense, error) {
-	var AtA, AtAi mat.Dense
-	var AI mat.Dense
-
-	AtA.Mul(A.T(), A)         // A^t*A
-	err := AtAi.Inverse(&AtA) // (A^t*A)^-1
-	if err != nil {
-		return &AI, err
+// generalizedInverse returns the generalized inverse of the given matrix A,
+// B = (A'A)^-1 A', and the condition number of A.
+//
+// B is computed from the QR decomposition A = QR as B = R^-1 Q' instead of
+// inverting the normal matrix A'A, which squares the condition number.
+// ErrSingularGeometry is returned if A is rank deficient, i.e., its condition
+// number exceeds maxCond.
+func generalizedInverse(A *mat.Dense) (*mat.Dense, float64, error) {
+	n, m := A.Dims()
+
+	var qr mat.QR
+	qr.Factorize(A)
+
+	cond := qr.Cond()
+	if !(cond < maxCond) {
+		return nil, cond, fmt.Errorf("%w: cond=%e", ErrSingularGeometry, cond)
 	}
 
-	// generalized inverse
-	AI.Mul(&AtAi, A.T()) // (A^t*A)^-1 * A^t
+	// least squares solution of A*B = I
+	var AI mat.Dense
+	I := mat.NewDiagDense(n, nil)
+	for i := range n {
+		I.SetDiag(i, 1.)
+	}
+	AI.ReuseAs(m, n)
+	if err := qr.SolveTo(&AI, false, I); err != nil {
+		return nil, cond, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
 
-	return &AI, nil
+	return &AI, cond, nil
 }
 
 func sqr(x float64) float64 {
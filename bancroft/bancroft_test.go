@@ -1,9 +1,14 @@
 package bancroft
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"testing"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/rinex"
 )
 
 func ExampleCalcPos() {
@@ -32,6 +37,7 @@ func TestCalcPos(t *testing.T) {
 		satDatas[i].Y = sp.Y * 1000. // km -> m
 		satDatas[i].Z = sp.Z * 1000. // km -> m
 		satDatas[i].PR = rangeData[i] + sp.C*0.000001*LightVelocity
+		satDatas[i].ID = satIDs[i]
 	}
 
 	// expected solution
@@ -43,7 +49,10 @@ func TestCalcPos(t *testing.T) {
 	// test
 	x, y, z, dt, err := CalcPos(satDatas)
 
-	if x != x0 || y != y0 || z != z0 || dt != dt0 || err != nil {
+	// the generalized inverse by QR agrees with the normal equations
+	// used to produce the expected solution to high precision
+	const tol, dtTol = 1e-6, 1e-15 // m, s
+	if math.Abs(x-x0) > tol || math.Abs(y-y0) > tol || math.Abs(z-z0) > tol || math.Abs(dt-dt0) > dtTol || err != nil {
 		t.Errorf("\nget (x, y, z, dt) = %f, %f, %f, %e\nwant(x, y, z, dt) = %f, %f, %f, %e\nerr: %v", x, y, z, dt, x0, y0, z0, dt0, err)
 	}
 
@@ -56,29 +65,36 @@ func TestCalcPos(t *testing.T) {
 	msg += "site position (RINEX header):  -3721695.1985  3545492.6126  3763541.7139\n"
 	msg += fmt.Sprintf("site position (by test)     :  %.4f  %.4f  %.4f\n", x, y, z)
 	msg += fmt.Sprintf("site position (want)        :  %.4f  %.4f  %.4f\n", x0, y0, z0)
+	e := EvalSolution(Solution{Position: [3]float64{x, y, z}}, [3]float64{-3721695.1985, 3545492.6126, 3763541.7139})
+	msg += fmt.Sprintf("error (E, N, U)             :  %.4f  %.4f  %.4f\n", e.ENU[0], e.ENU[1], e.ENU[2])
 	t.Logf(msg)
 }
 
-// This stores pseudoranges observed at the GEONET site "0255" (KOMATSU station).
-//
-// used data: 02551960.24o
-// timetag  : 2024-07-14 00:00:00.0000000
-// satellite: GPS (G14, G04, G22, G06, G17, G03, G21, G19, G02)
-// obs code : C1C
+// testObsFile is the excerpt of the RINEX file of the GEONET site "0255"
+// (KOMATSU station) of the rinex fixtures, whose C1 of GPS at 00:00 is that
+// of 02551960.24o.
 //
 // The data was obtained from Geospatial Information Authority Website:
 // https://terras.gsi.go.jp
-var rangeData = []float64{
-	20969460.336, // G14
-	24172308.906, // G04
-	21337486.820, // G22
-	22889983.813, // G06
-	20931837.352, // G17
-	20333361.633, // G03
-	24731230.883, // G21
-	22113357.180, // G19
-	23285127.484, // G02
-}
+const testObsFile = "../rinex/testdata/02551960_excerpt.24o"
+
+// rangeData stores the pseudoranges C1 of the satellites of satIDs at the
+// first epoch 2024-07-14 00:00:00 of testObsFile.
+var rangeData = func() []float64 {
+	f, err := rinex.ReadObsFile(testObsFile)
+	if err != nil {
+		panic(err)
+	}
+	var pr []float64
+	for _, id := range satIDs {
+		o, ok := f.Epochs[0].Obs[id.String()]["C1"]
+		if !ok {
+			panic("no C1 of " + id.String())
+		}
+		pr = append(pr, o.Value)
+	}
+	return pr
+}()
 
 // satPosData is a test data storing satellite precise ephemeris obrained from
 // IGS rapid orbit of "igr23230.sp3".
@@ -99,6 +115,114 @@ var satPosData = []satPos{
 	{-18350.488725, -6421.169947, 18706.745770, -399.560198}, // G02
 }
 
+// satIDs stores the satellite IDs of rangeData and satPosData.
+var satIDs = func() []gnss.SatID {
+	var ids []gnss.SatID
+	for _, s := range []string{"G14", "G04", "G22", "G06", "G17", "G03", "G21", "G19", "G02"} {
+		id, err := gnss.ParseSatID(s)
+		if err != nil {
+			panic(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}()
+
 type satPos struct {
 	X, Y, Z, C float64
 }
+
+// TestCalcPosAll checks that CalcPos adopts one of the candidates returned by
+// CalcPosAll, and that the adopted one fits the pseudoranges better than the
+// other.
+func TestCalcPosAll(t *testing.T) {
+	satDatas := testSatDatas()
+
+	cands, err := CalcPosAll(satDatas)
+	if err != nil {
+		t.Fatalf("CalcPosAll: %v", err)
+	}
+
+	x, y, z, dt, err := CalcPos(satDatas)
+	if err != nil {
+		t.Fatalf("CalcPos: %v", err)
+	}
+
+	found := -1
+	for i, c := range cands {
+		if c.X == x && c.Y == y && c.Z == z && c.Dt == dt {
+			found = i
+		}
+	}
+	switch found {
+	case 0, 1:
+		adopted, other := cands[found], cands[1-found]
+		if adopted.Residual >= other.Residual {
+			t.Errorf("adopted solution has larger residual: adopted=%f, other=%f", adopted.Residual, other.Residual)
+		}
+
+		// the residual of the pseudoranges PR = rho - c*Dt (see SatData), which
+		// is that of the ionospheric and the tropospheric delays of the data
+		var sum float64
+		for _, s := range satDatas {
+			rho := math.Sqrt(sqr(s.X-adopted.X) + sqr(s.Y-adopted.Y) + sqr(s.Z-adopted.Z))
+			sum += sqr(s.PR - (rho - adopted.Dt*LightVelocity))
+		}
+		if rms := math.Sqrt(sum / float64(len(satDatas))); math.Abs(adopted.Residual-rms) > 1e-6 || rms > 50 {
+			t.Errorf("residual: get %f, want %f", adopted.Residual, rms)
+		}
+	default:
+		t.Errorf("CalcPos result is not in candidates: %+v", cands)
+	}
+}
+
+// testSatDatas returns the SatData made from satPosData and rangeData.
+func testSatDatas() []SatData {
+	satDatas := make([]SatData, len(satPosData))
+	for i, sp := range satPosData {
+		satDatas[i].X = sp.X * 1000. // km -> m
+		satDatas[i].Y = sp.Y * 1000. // km -> m
+		satDatas[i].Z = sp.Z * 1000. // km -> m
+		satDatas[i].PR = rangeData[i] + sp.C*0.000001*LightVelocity
+		satDatas[i].ID = satIDs[i]
+	}
+	return satDatas
+}
+
+// TestSingularGeometry checks ErrSingularGeometry is returned for the
+// rank deficient geometry.
+func TestSingularGeometry(t *testing.T) {
+	satDatas := testSatDatas()[:5]
+
+	// the 5th satellite is the same as the 1st
+	satDatas[4] = satDatas[0]
+	satDatas[1] = satDatas[0]
+
+	_, err := CalcPosAll(satDatas)
+	if !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+
+	// four satellites
+	_, err = CalcPosAll(satDatas[:4])
+	if !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+}
+
+// TestGeometryCond checks the condition number is finite for the test data
+// and ErrSingularGeometry is returned for the duplicated satellites.
+func TestGeometryCond(t *testing.T) {
+	satDatas := testSatDatas()
+	cond, err := GeometryCond(satDatas)
+	if err != nil || cond < 1 {
+		t.Errorf("GeometryCond: cond=%e, err=%v", cond, err)
+	}
+
+	satDatas[1] = satDatas[0]
+	satDatas[2] = satDatas[0]
+	satDatas = satDatas[:5]
+	if _, err := GeometryCond(satDatas); !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+}
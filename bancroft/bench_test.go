@@ -0,0 +1,117 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// BenchmarkCalcPos4 measures CalcPos with four satellites.
+func BenchmarkCalcPos4(b *testing.B) {
+	satDatas := testSatDatas()[:4]
+	b.ReportAllocs()
+	for range b.N {
+		CalcPos(satDatas)
+	}
+}
+
+// TestCalcPosAll4 checks the four-satellite solver agrees with the general
+// one.
+//
+// Both solvers use the LU decomposition with partial pivoting for n=4, but the
+// general one forms B = A^-1 explicitly by gonum's Dense.Inverse and then
+// multiplies i0 and r, while the fast one substitutes them into the factors.
+// The rounding errors thus differ by a few ulps of the coordinates (up to
+// ~4e-9 m for the fixture), and the agreement of 1e-9 m is not met: it is
+// about one ulp of the radius near the Earth's surface (2^-30 m ~ 9.3e-10 m
+// for 4.2e6 m to 8.4e6 m), below the rounding of lam*u+v (eq.16) alone.
+// The test instead requires 8 ulps of the radius (~7.5e-9 m) for the
+// candidate near the Earth's surface, and 1e-14 relative to the radius or
+// the clock for the other, whose quadratic root is ill conditioned.
+func TestCalcPosAll4(t *testing.T) {
+	all := testSatDatas()
+	for k := 0; k+4 <= len(all); k++ {
+		satDatas := all[k : k+4]
+
+		c4, err4 := calcPosAll4(satDatas)
+		cn, errn := calcPosAllN(satDatas)
+		if err4 != nil || errn != nil {
+			t.Fatalf("k=%d: err4=%v, errn=%v", k, err4, errn)
+		}
+
+		for i := range 2 {
+			radius := math.Sqrt(sqr(cn[i].X) + sqr(cn[i].Y) + sqr(cn[i].Z))
+			tol := 8 * ulp(radius)
+			if selectRoot(cn, CalcPosOpts{}) != i {
+				tol = 1e-14 * math.Max(radius, math.Abs(cn[i].Dt*LightVelocity))
+			}
+
+			d := math.Sqrt(sqr(c4[i].X-cn[i].X) + sqr(c4[i].Y-cn[i].Y) + sqr(c4[i].Z-cn[i].Z))
+			if d > tol || math.Abs(c4[i].Dt-cn[i].Dt)*LightVelocity > tol {
+				t.Errorf("k=%d, candidate %d: d=%e, tol=%e, fast=%+v, general=%+v", k, i, d, tol, c4[i], cn[i])
+			}
+		}
+	}
+}
+
+// ulp returns the unit in the last place of x.
+func ulp(x float64) float64 {
+	return math.Nextafter(x, math.Inf(1)) - x
+}
+
+// BenchmarkCalcPos9 measures CalcPos with nine satellites.
+func BenchmarkCalcPos9(b *testing.B) {
+	satDatas := testSatDatas()
+	b.ReportAllocs()
+	for range b.N {
+		CalcPos(satDatas)
+	}
+}
+
+// BenchmarkSolver9 measures Solver.CalcPos with nine satellites.
+func BenchmarkSolver9(b *testing.B) {
+	satDatas := testSatDatas()
+	s := NewSolver(len(satDatas), CalcPosOpts{})
+	b.ReportAllocs()
+	for range b.N {
+		s.CalcPos(satDatas)
+	}
+}
+
+// TestSolver checks that Solver gives the identical results to CalcPosAll and
+// CalcPos, and no allocation occurs after the construction.
+func TestSolver(t *testing.T) {
+	all := testSatDatas()
+	s := NewSolver(5, CalcPosOpts{})
+	for n := 4; n <= len(all); n++ {
+		for k := 0; k+n <= len(all); k++ {
+			want, errw := CalcPosAll(all[k : k+n])
+			get, err := s.CalcPosAll(all[k : k+n])
+			if err != nil || errw != nil {
+				t.Fatalf("n=%d, k=%d: err=%v, want err=%v", n, k, err, errw)
+			}
+			if get != want {
+				t.Errorf("n=%d, k=%d: get %+v, want %+v", n, k, get, want)
+			}
+		}
+
+		x0, y0, z0, dt0, _ := CalcPos(all[:n])
+		if x, y, z, dt, _ := s.CalcPos(all[:n]); x != x0 || y != y0 || z != z0 || dt != dt0 {
+			t.Errorf("n=%d: get %f, %f, %f, %e, want %f, %f, %f, %e", n, x, y, z, dt, x0, y0, z0, dt0)
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { s.CalcPos(all) }); allocs != 0 {
+		t.Errorf("allocs=%f, want 0", allocs)
+	}
+
+	// singular geometry
+	same := make([]SatData, 6)
+	for i := range same {
+		same[i] = all[0]
+		same[i].PR += float64(i)
+	}
+	if _, _, _, _, err := s.CalcPos(same); !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+}
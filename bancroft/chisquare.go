@@ -0,0 +1,85 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// ChiSquareResult stores the result of the chi-square consistency test.
+type ChiSquareResult struct {
+	// Available is false if the test cannot be performed due to no
+	// redundancy (the degrees of freedom is zero).
+	Available bool
+
+	DOF       int     // degrees of freedom (n-4)
+	Statistic float64 // sum of the squared weighted residuals
+	Threshold float64 // chi-square threshold for the false alarm probability
+	Pass      bool    // true if Statistic <= Threshold
+}
+
+// Residuals returns the pseudorange residuals (m), PR - (range - c*dt), for
+// the position (m) and the clock bias (s), in the order of satDatas.
+// See LabeledResiduals for the residuals with the satellite labels.
+func Residuals(satDatas []SatData, x, y, z, dt float64) []float64 {
+	v := make([]float64, len(satDatas))
+	for i, s := range satDatas {
+		rho := math.Sqrt(sqr(s.X-x) + sqr(s.Y-y) + sqr(s.Z-z))
+		v[i] = s.PR - (rho - dt*LightVelocity)
+	}
+	return v
+}
+
+// SatResidual is the pseudorange residual (m) of the satellite labeled by
+// SatData.Label.
+type SatResidual struct {
+	ID       string
+	Residual float64
+}
+
+// LabeledResiduals returns the pseudorange residuals given by Residuals with
+// the satellite labels.
+func LabeledResiduals(satDatas []SatData, x, y, z, dt float64) []SatResidual {
+	v := Residuals(satDatas, x, y, z, dt)
+	res := make([]SatResidual, len(v))
+	for i := range v {
+		res[i] = SatResidual{ID: satDatas[i].Label(i), Residual: v[i]}
+	}
+	return res
+}
+
+// ChiSquareTest performs the global consistency test of the post-fit
+// residuals (m), comparing the sum of the squared weighted residuals
+//
+//	T = sum(w_i * v_i^2)
+//
+// with the chi-square threshold of n-4 degrees of freedom for the false alarm
+// probability pfa. The weights are 1/sigma^2 (1/m^2) of the observations.
+//
+// If the degrees of freedom is zero or negative, the test is unavailable and
+// the result with Available=false is returned without an error.
+func ChiSquareTest(residuals, weights []float64, pfa float64) (res ChiSquareResult, err error) {
+	if len(residuals) != len(weights) {
+		return res, fmt.Errorf("size mismatch: residuals=%d, weights=%d", len(residuals), len(weights))
+	}
+	if !(pfa > 0 && pfa < 1) {
+		return res, fmt.Errorf("invalid false alarm probability: pfa=%v", pfa)
+	}
+
+	res.DOF = len(residuals) - 4
+	if res.DOF <= 0 {
+		return res, nil
+	}
+	res.Available = true
+
+	for i, v := range residuals {
+		res.Statistic += weights[i] * v * v
+	}
+
+	// quantile of the chi-square distribution
+	res.Threshold = 2. * mathext.GammaIncRegInv(0.5*float64(res.DOF), 1.-pfa)
+	res.Pass = res.Statistic <= res.Threshold
+
+	return res, nil
+}
@@ -0,0 +1,46 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestChiSquareTest checks the threshold with the tabulated values, and the
+// unavailable case.
+func TestChiSquareTest(t *testing.T) {
+	tests := []struct {
+		n         int
+		pfa       float64
+		threshold float64 // from the chi-square table
+	}{
+		{5, 0.05, 3.841},
+		{6, 0.01, 9.210},
+		{9, 0.001, 20.515},
+	}
+
+	for _, tt := range tests {
+		v := make([]float64, tt.n)
+		w := make([]float64, tt.n)
+		for i := range w {
+			v[i] = 1.
+			w[i] = 1.
+		}
+
+		res, err := ChiSquareTest(v, w, tt.pfa)
+		if err != nil {
+			t.Fatalf("ChiSquareTest: %v", err)
+		}
+		if !res.Available || res.DOF != tt.n-4 || math.Abs(res.Threshold-tt.threshold) > 1e-3 {
+			t.Errorf("n=%d, pfa=%v: get %+v, want threshold=%f", tt.n, tt.pfa, res, tt.threshold)
+		}
+		if res.Statistic != float64(tt.n) || res.Pass != (res.Statistic <= res.Threshold) {
+			t.Errorf("n=%d, pfa=%v: invalid statistic: %+v", tt.n, tt.pfa, res)
+		}
+	}
+
+	// four satellites: no redundancy
+	res, err := ChiSquareTest([]float64{1, 1, 1, 1}, []float64{1, 1, 1, 1}, 0.01)
+	if err != nil || res.Available {
+		t.Errorf("test must be unavailable with four satellites: res=%+v, err=%v", res, err)
+	}
+}
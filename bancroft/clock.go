@@ -0,0 +1,104 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// ClockSample is the receiver clock bias Dt (s) solved at the epoch.
+type ClockSample struct {
+	Epoch time.Time
+	Dt    float64
+}
+
+// ClockDriftResult stores the result of EstimateClockDrift.
+type ClockDriftResult struct {
+	// Offset (s) at the first epoch, after bridging the jumps
+	Offset float64
+
+	// Drift (s/s) and its standard deviation
+	Drift, DriftSigma float64
+
+	// Jumps stores the epochs at which the clock jumps were detected, and
+	// JumpSizes the sizes (s) of the jumps.
+	Jumps     []time.Time
+	JumpSizes []float64
+}
+
+// EstimateClockDrift fits the receiver clock offset and drift to the time
+// series of the clock biases.
+//
+// Clock jumps, e.g., millisecond jumps of the receiver clock steering, are
+// detected as the differences of consecutive samples deviating from the
+// robust (median) drift by more than jumpThreshold (s), and bridged before the
+// least squares fit. 1e-4 s is used if jumpThreshold is zero.
+//
+// The samples must be in time order.
+func EstimateClockDrift(samples []ClockSample, jumpThreshold float64) (res ClockDriftResult, err error) {
+	n := len(samples)
+	if n < 3 {
+		return res, fmt.Errorf("not enough samples: n=%d", n)
+	}
+	if jumpThreshold == 0 {
+		jumpThreshold = 1e-4
+	}
+
+	// robust drift from the median of the slopes
+	slopes := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		dt := samples[i].Epoch.Sub(samples[i-1].Epoch).Seconds()
+		if dt <= 0 {
+			return res, fmt.Errorf("epochs not in time order: %v, %v", samples[i-1].Epoch, samples[i].Epoch)
+		}
+		slopes = append(slopes, (samples[i].Dt-samples[i-1].Dt)/dt)
+	}
+	sorted := slices.Clone(slopes)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	// detect and bridge the jumps
+	t := make([]float64, n)
+	y := make([]float64, n)
+	y[0] = samples[0].Dt
+	var cum float64
+	for i := 1; i < n; i++ {
+		t[i] = samples[i].Epoch.Sub(samples[0].Epoch).Seconds()
+
+		step := samples[i].Dt - samples[i-1].Dt
+		pred := median * (t[i] - t[i-1])
+		if math.Abs(step-pred) > jumpThreshold {
+			res.Jumps = append(res.Jumps, samples[i].Epoch)
+			res.JumpSizes = append(res.JumpSizes, step-pred)
+			cum += step - pred
+		}
+		y[i] = samples[i].Dt - cum
+	}
+
+	// least squares fit of y = offset + drift*t
+	var st, sy, stt, sty float64
+	for i := range n {
+		st += t[i]
+		sy += y[i]
+		stt += t[i] * t[i]
+		sty += t[i] * y[i]
+	}
+	fn := float64(n)
+	det := fn*stt - st*st
+	if det == 0 {
+		return res, fmt.Errorf("singular fit")
+	}
+	res.Drift = (fn*sty - st*sy) / det
+	res.Offset = (sy - res.Drift*st) / fn
+
+	// standard deviation of the drift from the residuals
+	var ss float64
+	for i := range n {
+		ss += sqr(y[i] - (res.Offset + res.Drift*t[i]))
+	}
+	sigma2 := ss / float64(n-2)
+	res.DriftSigma = math.Sqrt(sigma2 * fn / det)
+
+	return res, nil
+}
@@ -0,0 +1,44 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestEstimateClockDrift checks the drift is estimated across the
+// millisecond jumps.
+func TestEstimateClockDrift(t *testing.T) {
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	const (
+		offset = 2e-4 // s
+		drift  = 5e-8 // s/s
+	)
+
+	var samples []ClockSample
+	for i := range 120 {
+		dt := offset + drift*float64(i)*30. + 1e-9*math.Sin(float64(i))
+		if i >= 40 {
+			dt -= 1e-3
+		}
+		if i >= 90 {
+			dt += 1e-3
+		}
+		samples = append(samples, ClockSample{Epoch: t0.Add(time.Duration(i) * 30 * time.Second), Dt: dt})
+	}
+
+	res, err := EstimateClockDrift(samples, 0)
+	if err != nil {
+		t.Fatalf("EstimateClockDrift: %v", err)
+	}
+
+	if math.Abs(res.Drift-drift) > 1e-12 || math.Abs(res.Offset-offset) > 1e-9 {
+		t.Errorf("get offset=%e, drift=%e, want offset=%e, drift=%e", res.Offset, res.Drift, offset, drift)
+	}
+	if res.DriftSigma <= 0 || res.DriftSigma > 1e-12 {
+		t.Errorf("invalid sigma: %e", res.DriftSigma)
+	}
+	if len(res.Jumps) != 2 || !res.Jumps[0].Equal(samples[40].Epoch) || !res.Jumps[1].Equal(samples[90].Epoch) {
+		t.Errorf("invalid jumps: %v", res.Jumps)
+	}
+}
@@ -0,0 +1,118 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// msRange is the distance (m) the light travels in 1 ms.
+const msRange = LightVelocity * 1e-3
+
+// CoarseTimeData is the input of CalcPosCoarseTime: the satellite position
+// (ECEF, m) at the coarse time with the pseudorange known only modulo 1 ms,
+// i.e., SatData.PR in [0, c*1ms), and the satellite velocity (ECEF, m/s).
+type CoarseTimeData struct {
+	SatData
+	VX, VY, VZ float64
+}
+
+// CoarseTimeResult stores the result of CalcPosCoarseTime.
+type CoarseTimeResult struct {
+	// position (m) and the receiver clock bias (s) as dt of CalcPos. Dt is
+	// defined only modulo 1 ms, as the integer milliseconds of the
+	// pseudoranges are reconstructed relative to a reference satellite.
+	X, Y, Z, Dt float64
+
+	// TimeError is the error (s) of the coarse time, i.e., the satellite
+	// positions at the true time are those at the coarse time + TimeError.
+	TimeError float64
+
+	// Iterations is the number of the Gauss-Newton iterations.
+	Iterations int
+}
+
+// CalcPosCoarseTime solves the position from the sub-millisecond
+// pseudoranges at the coarse time, e.g., of the assisted GNSS, by the
+// five-state formulation (van Diggelen, 2009, A-GPS, chap. 4).
+//
+// The integer milliseconds of the pseudoranges are reconstructed from the
+// ranges at the a-priori position approxPos (ECEF, m) relative to the
+// satellite nearest to it, which requires the a-priori position within about
+// 100 km and the coarse time within a few seconds. The state (x, y, z,
+// common bias, coarse time error) is then estimated by the Gauss-Newton
+// iteration, where the partials of the coarse time error are the range rates
+// of the satellites. At least five satellites are required.
+func CalcPosCoarseTime(sats []CoarseTimeData, approxPos [3]float64) (res CoarseTimeResult, err error) {
+	n := len(sats)
+	if n < 5 {
+		return res, fmt.Errorf("%w: n=%d, unknowns=5", ErrTooFewSats, n)
+	}
+	for i, s := range sats {
+		if !(s.PR >= 0 && s.PR < msRange) {
+			return res, fmt.Errorf("%w: sub-millisecond pseudorange out of [0, %.3f): sat=%s, pr=%.3f", ErrBadInput, msRange, s.Label(i), s.PR)
+		}
+	}
+
+	// reconstruct the full pseudoranges relative to the nearest satellite
+	rho := make([]float64, n)
+	ref := 0
+	for i, s := range sats {
+		rho[i] = math.Sqrt(sqr(s.X-approxPos[0]) + sqr(s.Y-approxPos[1]) + sqr(s.Z-approxPos[2]))
+		if rho[i] < rho[ref] {
+			ref = i
+		}
+	}
+	full := make([]float64, n)
+	full[ref] = sats[ref].PR + math.Round((rho[ref]-sats[ref].PR)/msRange)*msRange
+	bias := full[ref] - rho[ref]
+	for i, s := range sats {
+		if i == ref {
+			continue
+		}
+		full[i] = s.PR + math.Round((rho[i]+bias-s.PR)/msRange)*msRange
+	}
+
+	// state: x, y, z, b (m), dtc (s) with PR = |sat + v*dtc - rcv| + b
+	state := [5]float64{approxPos[0], approxPos[1], approxPos[2], bias, 0.}
+	A := mat.NewDense(n, 5, nil)
+	dy := mat.NewVecDense(n, nil)
+	const maxIter, tol = 20, 1e-4
+	for iter := range maxIter {
+		for i, s := range sats {
+			sx := s.X + s.VX*state[4]
+			sy := s.Y + s.VY*state[4]
+			sz := s.Z + s.VZ*state[4]
+			e := lineOfSight([3]float64{state[0], state[1], state[2]}, sx, sy, sz)
+			r := math.Sqrt(sqr(sx-state[0]) + sqr(sy-state[1]) + sqr(sz-state[2]))
+
+			A.SetRow(i, []float64{-e[0], -e[1], -e[2], 1., e[0]*s.VX + e[1]*s.VY + e[2]*s.VZ})
+			dy.SetVec(i, full[i]-(r+state[3]))
+		}
+
+		var dx mat.VecDense
+		if err = dx.SolveVec(A, dy); err != nil {
+			return res, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+		}
+		for j := range 5 {
+			state[j] += dx.AtVec(j)
+		}
+
+		// the correction of the time error in meters of the satellite motion
+		norm := math.Sqrt(sqr(dx.AtVec(0)) + sqr(dx.AtVec(1)) + sqr(dx.AtVec(2)) + sqr(dx.AtVec(3)) + sqr(dx.AtVec(4)*4e3))
+		if norm < tol {
+			res = CoarseTimeResult{
+				X:          state[0],
+				Y:          state[1],
+				Z:          state[2],
+				Dt:         -state[3] / LightVelocity,
+				TimeError:  state[4],
+				Iterations: iter + 1,
+			}
+			return res, nil
+		}
+	}
+
+	return res, fmt.Errorf("not converged: maxIter=%d", maxIter)
+}
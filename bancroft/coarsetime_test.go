@@ -0,0 +1,61 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCalcPosCoarseTime checks the position and the time error are recovered
+// from the sub-millisecond pseudoranges.
+func TestCalcPosCoarseTime(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, Dt: 2.345e-4, NumSats: 8, MinElevation: 10., Seed: 1})
+
+	// satellites moving at 3.9 km/s perpendicular to the radius; the
+	// positions are given at the coarse time 1.5 s before the true time
+	const timeErr = 1.5
+	sats := make([]CoarseTimeData, len(sc.SatDatas))
+	for i, s := range sc.SatDatas {
+		r := [3]float64{s.X, s.Y, s.Z}
+		v := cross(r, [3]float64{0.3, -0.5, 1.})
+		nv := math.Sqrt(sqr(v[0]) + sqr(v[1]) + sqr(v[2]))
+		for k := range 3 {
+			v[k] *= 3.9e3 / nv
+		}
+		sats[i] = CoarseTimeData{
+			SatData: SatData{
+				X:  s.X - v[0]*timeErr,
+				Y:  s.Y - v[1]*timeErr,
+				Z:  s.Z - v[2]*timeErr,
+				PR: math.Mod(s.PR, msRange),
+			},
+			VX: v[0], VY: v[1], VZ: v[2],
+		}
+	}
+
+	// a-priori 50 km away
+	approx := coord.ENUToECEF(site, [3]float64{30e3, -40e3, 0.})
+	res, err := CalcPosCoarseTime(sats, approx)
+	if err != nil {
+		t.Fatalf("CalcPosCoarseTime: %v", err)
+	}
+
+	d := math.Sqrt(sqr(res.X-site[0]) + sqr(res.Y-site[1]) + sqr(res.Z-site[2]))
+	if d > 1e-3 || math.Abs(res.TimeError-timeErr) > 1e-6 {
+		t.Errorf("d=%e, time error=%f, want %f", d, res.TimeError, timeErr)
+	}
+
+	// the clock bias modulo 1 ms
+	if ddt := math.Mod(res.Dt-sc.Dt+1e-3, 1e-3); math.Min(ddt, 1e-3-ddt) > 1e-12 {
+		t.Errorf("dt=%e, want %e modulo 1 ms", res.Dt, sc.Dt)
+	}
+
+	// full pseudoranges are rejected
+	sats[0].PR = sc.SatDatas[0].PR
+	if _, err := CalcPosCoarseTime(sats, approx); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
@@ -0,0 +1,70 @@
+package bancroft
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// DefaultMaxCorrectionAge is the maximum age of the DGNSS corrections used
+// by CorrectionSet.Apply when maxAge is not given.
+const DefaultMaxCorrectionAge = 30 * time.Second
+
+// PRCorrection is the scalar pseudorange correction of a satellite broadcast
+// by a DGNSS reference station, as in RTCM 2 message type 1.
+type PRCorrection struct {
+	ID  gnss.SatID // satellite ID
+	T0  time.Time  // time of validity
+	PRC float64    // pseudorange correction (m)
+	RRC float64    // range-rate correction (m/s)
+}
+
+// CorrectionSet is a set of the DGNSS corrections of the satellites.
+type CorrectionSet []PRCorrection
+
+// Apply returns the copy of satDatas with the pseudoranges at the time t
+// corrected by the age-adjusted corrections:
+//
+//	PR' = PR + PRC + RRC*(t-T0)
+//
+// The satellites are paired with the corrections by SatData.ID, and the
+// latest correction is used if a satellite has several. The satellites whose
+// correction is older than maxAge (DefaultMaxCorrectionAge if zero) are
+// dropped, and those without a correction are returned uncorrected; n is the
+// number of the corrected satellites.
+func (cs CorrectionSet) Apply(satDatas []SatData, t time.Time, maxAge time.Duration) (corrected []SatData, n int, err error) {
+	if maxAge == 0 {
+		maxAge = DefaultMaxCorrectionAge
+	}
+
+	latest := make(map[gnss.SatID]int, len(cs))
+	for i, c := range cs {
+		if j, ok := latest[c.ID]; !ok || c.T0.After(cs[j].T0) {
+			latest[c.ID] = i
+		}
+	}
+
+	corrected = make([]SatData, 0, len(satDatas))
+	for i, s := range satDatas {
+		if s.ID.IsZero() {
+			return nil, 0, fmt.Errorf("%w: no satellite ID: sat=%s", ErrBadInput, s.Label(i))
+		}
+		j, ok := latest[s.ID]
+		if !ok {
+			corrected = append(corrected, s)
+			continue
+		}
+
+		c := cs[j]
+		age := t.Sub(c.T0)
+		if age > maxAge || age < -maxAge {
+			continue
+		}
+		s.PR += c.PRC + c.RRC*age.Seconds()
+		corrected = append(corrected, s)
+		n++
+	}
+
+	return corrected, n, nil
+}
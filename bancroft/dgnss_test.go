@@ -0,0 +1,70 @@
+package bancroft
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCorrectionSet checks that the age-adjusted corrections remove the
+// errors, and the stale corrections are dropped.
+func TestCorrectionSet(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 9, MinElevation: 10., Seed: 3})
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(10 * time.Second)
+
+	// the errors at t1 drift at 0.1*i m/s from the values at t0
+	sats := make([][3]float64, len(sc.SatDatas))
+	errs := make([]float64, len(sats))
+	cs := make(CorrectionSet, len(sats))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+		errs[i] = 5. + 3.*float64(i) + 0.1*float64(i)*10.
+		cs[i] = PRCorrection{ID: satIDs[i], T0: t0, PRC: -(5. + 3.*float64(i)), RRC: -0.1 * float64(i)}
+	}
+	satDatas := ComputePseudoranges(site, 1e-4, sats, errs)
+	for i := range satDatas {
+		satDatas[i].ID = satIDs[i]
+	}
+
+	// stale correction of the first satellite, and no correction of the last
+	cs[0].T0 = t0.Add(-time.Minute)
+	cs = append(cs[:len(cs)-1], PRCorrection{ID: satIDs[1], T0: t0.Add(-5 * time.Second), PRC: 100.})
+
+	corrected, n, err := cs.Apply(satDatas, t1, 0)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if n != 7 || len(corrected) != 8 {
+		t.Fatalf("corrected=%d, returned=%d", n, len(corrected))
+	}
+	if corrected[0].ID != satIDs[1] || corrected[7].ID != satIDs[8] {
+		t.Errorf("satellites: first=%s, last=%s", corrected[0].ID, corrected[7].ID)
+	}
+
+	// without the uncorrected satellite
+	sol, err := Solve(corrected[:7], CalcPosOpts{})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	d := coord.ECEFToENU(site, sol.Position)
+	if d[0]*d[0]+d[1]*d[1]+d[2]*d[2] > 1e-6 {
+		t.Errorf("error (ENU): %v", d)
+	}
+
+	// all the corrections are stale with the shorter limit
+	if _, n, _ := cs.Apply(satDatas, t1, 5*time.Second); n != 0 {
+		t.Errorf("corrected=%d, want 0", n)
+	}
+
+	// no satellite ID
+	satDatas[2].ID = gnss.SatID{}
+	if _, _, err := cs.Apply(satDatas, t1, 0); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
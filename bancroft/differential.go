@@ -0,0 +1,89 @@
+package bancroft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// ErrEpochMismatch is returned when the epochs of the base and the rover are
+// too far apart to be differenced.
+var ErrEpochMismatch = errors.New("epoch mismatch")
+
+// CalcPosDiff solves the rover position by the between-receiver single
+// differences of the pseudoranges against the base station at the known
+// position basePos (ECEF, m).
+//
+// The satellites are paired by SatData.ID, and the epochs must agree within
+// tol. The errors common to the receivers, such as the satellite clock and
+// most of the atmospheric delays, are canceled by correcting the rover
+// pseudoranges by those of the base:
+//
+//	PR_r' = PR_r - (PR_b - |sat - basePos|) = |sat - rover| - c*(dt_r - dt_b)
+//
+// and the rover is solved by Solve with the satellite positions of the
+// rover. Solution.ClockBias is the differential clock dt_r - dt_b (s),
+// Solution.NumSats is the number of the common satellites, and the labels
+// of the satellites observed by only one of the receivers are listed in
+// Solution.Excluded.
+func CalcPosDiff(base, rover EpochData, basePos [3]float64, tol time.Duration) (sol Solution, err error) {
+	if d := rover.Epoch.Sub(base.Epoch); d > tol || d < -tol {
+		return sol, fmt.Errorf("%w: base=%v, rover=%v, tol=%v", ErrEpochMismatch, base.Epoch, rover.Epoch, tol)
+	}
+
+	baseIdx, err := indexByID(base.SatDatas)
+	if err != nil {
+		return sol, fmt.Errorf("base: %w", err)
+	}
+	roverIdx, err := indexByID(rover.SatDatas)
+	if err != nil {
+		return sol, fmt.Errorf("rover: %w", err)
+	}
+
+	var excluded []string
+	common := make([]SatData, 0, len(rover.SatDatas))
+	for _, s := range rover.SatDatas {
+		i, ok := baseIdx[s.ID]
+		if !ok {
+			excluded = append(excluded, s.ID.String())
+			continue
+		}
+		b := base.SatDatas[i]
+		rho := math.Sqrt(sqr(b.X-basePos[0]) + sqr(b.Y-basePos[1]) + sqr(b.Z-basePos[2]))
+		s.PR -= b.PR - rho
+		common = append(common, s)
+	}
+	for _, s := range base.SatDatas {
+		if _, ok := roverIdx[s.ID]; !ok {
+			excluded = append(excluded, s.ID.String())
+		}
+	}
+
+	sol, err = Solve(common, CalcPosOpts{})
+	if err != nil {
+		return Solution{Excluded: excluded}, fmt.Errorf("common=%d: %w", len(common), err)
+	}
+	sol.Epoch = rover.Epoch
+	sol.Excluded = excluded
+
+	return sol, nil
+}
+
+// indexByID returns the map of the satellite IDs to the indices. The
+// satellites must have the unique IDs.
+func indexByID(satDatas []SatData) (map[gnss.SatID]int, error) {
+	idx := make(map[gnss.SatID]int, len(satDatas))
+	for i, s := range satDatas {
+		if s.ID.IsZero() {
+			return nil, fmt.Errorf("%w: no satellite ID: sat=%s", ErrBadInput, s.Label(i))
+		}
+		if _, ok := idx[s.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicated satellite ID: sat=%s", ErrBadInput, s.ID)
+		}
+		idx[s.ID] = i
+	}
+	return idx, nil
+}
@@ -0,0 +1,65 @@
+package bancroft
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCalcPosDiff checks that the errors common to the base and the rover
+// are canceled, and the unmatched satellites are reported.
+func TestCalcPosDiff(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	rover := coord.ENUToECEF(site, [3]float64{5000., -2000., 30.})
+
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 9, MinElevation: 10., Seed: 3})
+	sats := make([][3]float64, len(sc.SatDatas))
+	common := make([]float64, len(sats))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+		common[i] = 5. + 3.*float64(i) // satellite clock and atmosphere
+	}
+
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := EpochData{Epoch: epoch, SatDatas: ComputePseudoranges(site, 1e-4, sats, common)}
+	r := EpochData{Epoch: epoch.Add(time.Millisecond), SatDatas: ComputePseudoranges(rover, -3e-4, sats, common)}
+	for i := range sats {
+		b.SatDatas[i].ID = satIDs[i]
+		r.SatDatas[i].ID = satIDs[i]
+	}
+
+	// G14 only for the rover, and G02 only for the base
+	b.SatDatas = b.SatDatas[1:]
+	r.SatDatas = r.SatDatas[:8]
+
+	sol, err := CalcPosDiff(b, r, site, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CalcPosDiff: %v", err)
+	}
+	if sol.NumSats != 7 || !slices.Equal(sol.Excluded, []string{"G14", "G02"}) {
+		t.Errorf("common=%d, excluded=%v", sol.NumSats, sol.Excluded)
+	}
+
+	d := coord.ECEFToENU(rover, sol.Position)
+	if d[0]*d[0]+d[1]*d[1]+d[2]*d[2] > 1e-6 {
+		t.Errorf("rover error (ENU): %v", d)
+	}
+	if got := sol.ClockBias; got < -4e-4-1e-12 || got > -4e-4+1e-12 {
+		t.Errorf("differential clock: get %e, want %e", got, -4e-4)
+	}
+
+	// epochs too far apart
+	if _, err := CalcPosDiff(b, r, site, 0); !errors.Is(err, ErrEpochMismatch) {
+		t.Errorf("get err=%v, want %v", err, ErrEpochMismatch)
+	}
+
+	// no satellite ID
+	r.SatDatas[0].ID = gnss.SatID{}
+	if _, err := CalcPosDiff(b, r, site, time.Second); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
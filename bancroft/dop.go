@@ -0,0 +1,166 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss/coord"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DOP stores the dilution of precision values.
+// The horizontal and vertical components are defined in the local east,
+// north, up frame at the receiver position (see coord.ENURotation).
+type DOP struct {
+	GDOP, PDOP, HDOP, VDOP, TDOP float64
+}
+
+// CalcDOP returns the DOP values for the satellites seen from the receiver
+// position pos (ECEF, m).
+func CalcDOP(pos [3]float64, satDatas []SatData) (dop DOP, err error) {
+	H, err := designMatrix(pos, satDatas)
+	if err != nil {
+		return dop, err
+	}
+
+	var N, Q mat.Dense
+	N.Mul(H.T(), H)
+	if err = Q.Inverse(&N); err != nil {
+		return dop, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+
+	return dopFromCofactor(pos, &Q), nil
+}
+
+// CalcWDOP returns the DOP values and the weighted DOP values for the
+// satellites seen from the receiver position pos (ECEF, m). The weighted DOP
+// values are computed from (H'WH)^-1, where W is the diagonal matrix of the
+// weights, e.g., 1/sigma^2 of the pseudoranges.
+//
+// The weights are normalized by their mean, so that the weighted DOP values
+// are comparable with the plain ones; equal weights give wdop == dop.
+func CalcWDOP(pos [3]float64, satDatas []SatData, weights []float64) (dop, wdop DOP, err error) {
+	if len(weights) != len(satDatas) {
+		return dop, wdop, fmt.Errorf("%w: size mismatch: sats=%d, weights=%d", ErrBadInput, len(satDatas), len(weights))
+	}
+
+	H, err := designMatrix(pos, satDatas)
+	if err != nil {
+		return dop, wdop, err
+	}
+
+	var mean float64
+	equal := true
+	for _, w := range weights {
+		if !(w > 0) || math.IsInf(w, 0) {
+			return dop, wdop, fmt.Errorf("%w: invalid weight: w=%v", ErrBadInput, w)
+		}
+		mean += w
+		equal = equal && w == weights[0]
+	}
+	mean /= float64(len(weights))
+
+	var N, Q mat.Dense
+	N.Mul(H.T(), H)
+	if err = Q.Inverse(&N); err != nil {
+		return dop, wdop, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+	dop = dopFromCofactor(pos, &Q)
+
+	// the normalized weights are all 1 for equal weights
+	if equal {
+		return dop, dop, nil
+	}
+
+	WH := mat.DenseCopyOf(H)
+	for i, w := range weights {
+		row := WH.RawRowView(i)
+		for j := range row {
+			row[j] *= w / mean
+		}
+	}
+
+	var Nw, Qw mat.Dense
+	Nw.Mul(H.T(), WH)
+	if err = Qw.Inverse(&Nw); err != nil {
+		return dop, wdop, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+
+	return dop, dopFromCofactor(pos, &Qw), nil
+}
+
+// dopFromCofactor returns the DOP values from the cofactor matrix Q of the
+// state (x, y, z, clock) in the ECEF frame.
+func dopFromCofactor(pos [3]float64, Q mat.Matrix) (dop DOP) {
+	// rotate the position block into ENU: Qenu = R Qxyz R'
+	lat, lon, _ := coord.XYZToLLH(pos[0], pos[1], pos[2])
+	R := coord.ENURotation(lat, lon)
+	var qenu [3]float64
+	for k := range 3 {
+		for i := range 3 {
+			for j := range 3 {
+				qenu[k] += R[k][i] * Q.At(i, j) * R[k][j]
+			}
+		}
+	}
+	qt := Q.At(3, 3)
+
+	dop.HDOP = math.Sqrt(qenu[0] + qenu[1])
+	dop.VDOP = math.Sqrt(qenu[2])
+	dop.PDOP = math.Sqrt(qenu[0] + qenu[1] + qenu[2])
+	dop.TDOP = math.Sqrt(qt)
+	dop.GDOP = math.Sqrt(qenu[0] + qenu[1] + qenu[2] + qt)
+
+	return dop
+}
+
+// DesignMatrix returns the design matrix H of the linearized pseudorange
+// equations at the receiver position approxPos (ECEF, m), and the predicted
+// geometric ranges rho_i (m). The i-th row of H is
+//
+//	(-e_i, 1)
+//
+// where e_i is the line-of-sight unit vector from the receiver to the i-th
+// satellite, i.e., the partials of the range with respect to the receiver
+// position and of the pseudorange with respect to the clock term b = -c*dt
+// of the model PR_i = rho_i + b (see SatData). The solvers of the package
+// use the same partials.
+//
+// ErrBadInput is returned if no satellite is given, or a satellite is at the
+// receiver position.
+func DesignMatrix(approxPos [3]float64, satDatas []SatData) (H *mat.Dense, predicted []float64, err error) {
+	n := len(satDatas)
+	if n == 0 {
+		return nil, nil, fmt.Errorf("%w: no satellite", ErrBadInput)
+	}
+
+	H = mat.NewDense(n, 4, nil)
+	predicted = make([]float64, n)
+	for i, s := range satDatas {
+		h, rho := designRow(approxPos, s)
+		if !(rho > 0) {
+			return nil, nil, fmt.Errorf("%w: satellite at the receiver position: sat=%s", ErrBadInput, s.Label(i))
+		}
+		H.SetRow(i, []float64{h[0], h[1], h[2], 1.})
+		predicted[i] = rho
+	}
+	return H, predicted, nil
+}
+
+// designMatrix is DesignMatrix for the DOP values, which requires four or
+// more satellites.
+func designMatrix(pos [3]float64, satDatas []SatData) (*mat.Dense, error) {
+	if n := len(satDatas); n < 4 {
+		return nil, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	}
+	H, _, err := DesignMatrix(pos, satDatas)
+	return H, err
+}
+
+// designRow returns the partials -e of the range with respect to the
+// receiver position pos, and the range rho (m) to the satellite.
+func designRow(pos [3]float64, s SatData) (h [3]float64, rho float64) {
+	dx, dy, dz := pos[0]-s.X, pos[1]-s.Y, pos[2]-s.Z
+	rho = math.Sqrt(dx*dx + dy*dy + dz*dz)
+	return [3]float64{dx / rho, dy / rho, dz / rho}, rho
+}
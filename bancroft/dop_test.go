@@ -0,0 +1,107 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestCalcWDOP checks that the equal weights reproduce the plain DOP values,
+// and the weighted DOP values are independent of the scale of the weights.
+func TestCalcWDOP(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	n := len(sc.SatDatas)
+
+	plain, err := CalcDOP(site, sc.SatDatas)
+	if err != nil {
+		t.Fatalf("CalcDOP: %v", err)
+	}
+
+	// equal weights
+	equal := make([]float64, n)
+	for i := range equal {
+		equal[i] = 0.1
+	}
+	dop, wdop, err := CalcWDOP(site, sc.SatDatas, equal)
+	if err != nil {
+		t.Fatalf("CalcWDOP: %v", err)
+	}
+	if dop != plain || wdop != plain {
+		t.Errorf("equal weights: dop=%+v, wdop=%+v, want %+v", dop, wdop, plain)
+	}
+
+	// elevation dependent weights, and the same weights scaled
+	w1, w2 := make([]float64, n), make([]float64, n)
+	for i, el := range sc.El {
+		w1[i] = sqr(math.Sin(el))
+		w2[i] = 100. * w1[i]
+	}
+	_, wdop1, err := CalcWDOP(site, sc.SatDatas, w1)
+	if err != nil {
+		t.Fatalf("CalcWDOP: %v", err)
+	}
+	_, wdop2, _ := CalcWDOP(site, sc.SatDatas, w2)
+
+	if wdop1 == plain {
+		t.Errorf("weights not applied: wdop=%+v", wdop1)
+	}
+	if math.Abs(wdop1.GDOP-wdop2.GDOP) > 1e-9*wdop1.GDOP {
+		t.Errorf("scale dependent: GDOP=%f, %f", wdop1.GDOP, wdop2.GDOP)
+	}
+
+	// invalid weights
+	if _, _, err := CalcWDOP(site, sc.SatDatas, w1[:n-1]); err == nil {
+		t.Errorf("no error for size mismatch")
+	}
+	w1[0] = 0.
+	if _, _, err := CalcWDOP(site, sc.SatDatas, w1); err == nil {
+		t.Errorf("no error for zero weight")
+	}
+}
+
+// TestDesignMatrix checks the design matrix against the numerical partials
+// of the pseudoranges, and the predicted ranges against ComputePseudorange.
+func TestDesignMatrix(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+
+	H, predicted, err := DesignMatrix(site, sc.SatDatas)
+	if err != nil {
+		t.Fatalf("DesignMatrix: %v", err)
+	}
+	if r, c := H.Dims(); r != len(sc.SatDatas) || c != 4 {
+		t.Fatalf("dims: %dx%d", r, c)
+	}
+
+	const d = 1. // m
+	for i, s := range sc.SatDatas {
+		sat := [3]float64{s.X, s.Y, s.Z}
+		if got, want := predicted[i], ComputePseudorange(site, 0., sat); math.Abs(got-want) > 1e-6 {
+			t.Errorf("sat %d: predicted %f, want %f", i, got, want)
+		}
+
+		for k := range 3 {
+			p := site
+			p[k] += d
+			want := (ComputePseudorange(p, 0., sat) - predicted[i]) / d
+			if math.Abs(H.At(i, k)-want) > 1e-6 {
+				t.Errorf("sat %d: d/dx%d=%f, want %f", i, k, H.At(i, k), want)
+			}
+		}
+
+		// PR = rho + b for b = -c*dt
+		want := (ComputePseudorange(site, -d/LightVelocity, sat) - predicted[i]) / d
+		if math.Abs(H.At(i, 3)-want) > 1e-6 {
+			t.Errorf("sat %d: d/db=%f, want %f", i, H.At(i, 3), want)
+		}
+	}
+
+	if _, _, err := DesignMatrix(site, nil); !errors.Is(err, ErrBadInput) {
+		t.Errorf("no satellite: get err=%v, want %v", err, ErrBadInput)
+	}
+	bad := []SatData{{X: site[0], Y: site[1], Z: site[2]}}
+	if _, _, err := DesignMatrix(site, bad); !errors.Is(err, ErrBadInput) {
+		t.Errorf("satellite at the receiver: get err=%v, want %v", err, ErrBadInput)
+	}
+}
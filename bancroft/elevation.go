@@ -0,0 +1,32 @@
+package bancroft
+
+import (
+	"fmt"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// FilterByElevation returns the satellites whose elevation angles seen from
+// approxPos (ECEF, m) are not lower than cutoffDeg (deg), together with the
+// indices of the excluded satellites in satDatas.
+//
+// An error is returned if fewer than four satellites remain.
+func FilterByElevation(satDatas []SatData, approxPos [3]float64, cutoffDeg float64) (kept []SatData, excluded []int, err error) {
+	cutoff := coord.Deg2Rad(cutoffDeg)
+
+	kept = make([]SatData, 0, len(satDatas))
+	for i, s := range satDatas {
+		_, el := coord.AzEl(approxPos, [3]float64{s.X, s.Y, s.Z})
+		if el < cutoff {
+			excluded = append(excluded, i)
+			continue
+		}
+		kept = append(kept, s)
+	}
+
+	if len(kept) < 4 {
+		return kept, excluded, fmt.Errorf("%w: above the elevation mask: n=%d, cutoff=%.1f", ErrTooFewSats, len(kept), cutoffDeg)
+	}
+
+	return kept, excluded, nil
+}
@@ -0,0 +1,43 @@
+package bancroft
+
+import (
+	"testing"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestFilterByElevation checks the satellites are split by the elevation mask.
+func TestFilterByElevation(t *testing.T) {
+	satDatas := testSatDatas()
+	pos := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	const cutoff = 30.
+	kept, excluded, err := FilterByElevation(satDatas, pos, cutoff)
+	if err != nil {
+		t.Fatalf("FilterByElevation: %v", err)
+	}
+	if len(kept)+len(excluded) != len(satDatas) {
+		t.Fatalf("invalid number of satellites: kept=%d, excluded=%d", len(kept), len(excluded))
+	}
+
+	for _, i := range excluded {
+		s := satDatas[i]
+		if _, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z}); coord.Rad2Deg(el) >= cutoff {
+			t.Errorf("satellite above the mask is excluded: i=%d, el=%f", i, coord.Rad2Deg(el))
+		}
+	}
+	for _, s := range kept {
+		if _, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z}); coord.Rad2Deg(el) < cutoff {
+			t.Errorf("satellite below the mask is kept: el=%f", coord.Rad2Deg(el))
+		}
+	}
+
+	if _, _, err := FilterByElevation(satDatas, pos, 89.); err == nil {
+		t.Errorf("no error for too few satellites")
+	}
+
+	// used in the iterated solver
+	if _, _, _, _, _, err := CalcPosIterated(satDatas, nil, IterOpts{ElevationMask: 10.}); err != nil {
+		t.Errorf("CalcPosIterated with elevation mask: %v", err)
+	}
+}
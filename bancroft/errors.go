@@ -0,0 +1,62 @@
+package bancroft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCond is the maximum condition number of the Bancroft matrix A regarded
+// as non-singular.
+const maxCond = 1e12
+
+// MinPseudorange and MaxPseudorange (m) bracket the pseudoranges accepted by
+// CalcPosWithOpts, which are sane for the MEO satellites. The check is
+// disabled by CalcPosOpts.SkipRangeCheck.
+const (
+	MinPseudorange = 15000e3
+	MaxPseudorange = 50000e3
+)
+
+// The errors of the solvers are wrapped with the context, and can be tested
+// by errors.Is.
+var (
+	// ErrTooFewSats is returned when the number of satellites is less than
+	// the number of the unknowns.
+	ErrTooFewSats = errors.New("not enough satellite")
+
+	// ErrBadInput is returned when the input is invalid, such as NaN values
+	// or zero satellite positions.
+	ErrBadInput = errors.New("invalid input")
+
+	// ErrSingularGeometry is returned when the satellite geometry makes the
+	// equations rank deficient or too ill-conditioned to be solved.
+	ErrSingularGeometry = errors.New("singular satellite geometry")
+
+	// ErrNoRealSolution is returned when the quadratic equation of Bancroft
+	// method has no real root.
+	ErrNoRealSolution = errors.New("no real solution")
+)
+
+// checkSatDatas checks that the inputs are finite and the satellite
+// positions are non-zero. The pseudoranges are also checked against
+// [MinPseudorange, MaxPseudorange] if checkRange is true.
+func checkSatDatas(satDatas []SatData, checkRange bool) error {
+	for i, s := range satDatas {
+		if !isFinite(s.X) || !isFinite(s.Y) || !isFinite(s.Z) || !isFinite(s.PR) {
+			return fmt.Errorf("%w: non-finite value: sat=%s, %+v", ErrBadInput, s.Label(i), s)
+		}
+		if s.X == 0 && s.Y == 0 && s.Z == 0 {
+			return fmt.Errorf("%w: zero satellite position: sat=%s", ErrBadInput, s.Label(i))
+		}
+		if checkRange && (s.PR < MinPseudorange || s.PR > MaxPseudorange) {
+			return fmt.Errorf("%w: pseudorange out of range: sat=%s, pr=%.3f", ErrBadInput, s.Label(i), s.PR)
+		}
+	}
+	return nil
+}
+
+// isFinite reports whether v is neither NaN nor Inf.
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
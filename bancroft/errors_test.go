@@ -0,0 +1,63 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestSentinelErrors checks that the invalid inputs are reported by the
+// sentinel errors.
+func TestSentinelErrors(t *testing.T) {
+	modify := func(f func(s []SatData)) []SatData {
+		s := testSatDatas()
+		f(s)
+		return s
+	}
+
+	for _, tt := range []struct {
+		name     string
+		satDatas []SatData
+		opts     CalcPosOpts
+		want     error
+	}{
+		{"too few", testSatDatas()[:3], CalcPosOpts{}, ErrTooFewSats},
+		{"NaN PR", modify(func(s []SatData) { s[1].PR = math.NaN() }), CalcPosOpts{}, ErrBadInput},
+		{"Inf X", modify(func(s []SatData) { s[2].X = math.Inf(1) }), CalcPosOpts{}, ErrBadInput},
+		{"zero position", modify(func(s []SatData) { s[3].X, s[3].Y, s[3].Z = 0, 0, 0 }), CalcPosOpts{}, ErrBadInput},
+		{"PR in km", modify(func(s []SatData) { s[0].PR *= 1e-3 }), CalcPosOpts{}, ErrBadInput},
+		{"singular", modify(func(s []SatData) {
+			for i := range s {
+				s[i] = s[0]
+			}
+		}), CalcPosOpts{}, ErrSingularGeometry},
+	} {
+		_, _, _, _, err := CalcPosWithOpts(tt.satDatas, tt.opts)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// the range check can be disabled
+	s := modify(func(s []SatData) { s[0].PR += 30000e3 })
+	if _, _, _, _, err := CalcPosWithOpts(s, CalcPosOpts{SkipRangeCheck: true}); errors.Is(err, ErrBadInput) {
+		t.Errorf("range check not disabled: %v", err)
+	}
+}
+
+// TestNoRealSolution checks that the negative discriminant of the quadratic
+// equation is reported by ErrNoRealSolution.
+func TestNoRealSolution(t *testing.T) {
+	// four satellites at 20,000 km on the axes with the pseudoranges
+	// inconsistent with any receiver position
+	satDatas := []SatData{
+		{X: 20000e3, PR: 1000e3},
+		{Y: 20000e3, PR: 1000e3},
+		{Z: 20000e3, PR: 1000e3},
+		{X: -20000e3, PR: 30000e3},
+	}
+	_, err := CalcPosAll(satDatas)
+	if !errors.Is(err, ErrNoRealSolution) {
+		t.Errorf("get err=%v, want %v", err, ErrNoRealSolution)
+	}
+}
@@ -0,0 +1,91 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+)
+
+// calcPosAll4 is the solver of CalcPosAll for exactly four satellites.
+//
+// The 4x4 system is solved by the LU decomposition with partial pivoting on
+// arrays, so that no heap allocation occurs. The equations are the same as
+// those of calcPosAllN with B = A^-1.
+func calcPosAll4(satDatas []SatData) (cands [2]Candidate, err error) {
+	var A [4][4]float64
+	var r, i0 [4]float64
+	for i, s := range satDatas[:4] {
+		A[i] = [4]float64{s.X, s.Y, s.Z, s.PR}
+		r[i] = 0.5 * (sqr(s.X) + sqr(s.Y) + sqr(s.Z) - sqr(s.PR)) // (eq.7)
+		i0[i] = 1.                                                // (eq.6)
+	}
+
+	var piv [4]int
+	if err = luDecompose4(&A, &piv); err != nil {
+		return cands, err
+	}
+	u := luSolve4(&A, &piv, i0) // (eq.10)
+	v := luSolve4(&A, &piv, r)  // (eq.11)
+
+	return bancroftCandidates(satDatas, u, v)
+}
+
+// luDecompose4 performs the in-place LU decomposition of A with partial
+// pivoting, where piv stores the row permutation.
+// ErrSingularGeometry is returned if a pivot is negligible compared with the
+// largest element of A.
+func luDecompose4(A *[4][4]float64, piv *[4]int) error {
+	var amax float64
+	for i := range 4 {
+		piv[i] = i
+		for j := range 4 {
+			amax = math.Max(amax, math.Abs(A[i][j]))
+		}
+	}
+
+	for k := range 4 {
+		// pivot
+		p := k
+		for i := k + 1; i < 4; i++ {
+			if math.Abs(A[i][k]) > math.Abs(A[p][k]) {
+				p = i
+			}
+		}
+		if !(math.Abs(A[p][k]) > 1e-14*amax) {
+			return fmt.Errorf("%w: pivot=%e", ErrSingularGeometry, A[p][k])
+		}
+		if p != k {
+			A[p], A[k] = A[k], A[p]
+			piv[p], piv[k] = piv[k], piv[p]
+		}
+
+		for i := k + 1; i < 4; i++ {
+			A[i][k] /= A[k][k]
+			for j := k + 1; j < 4; j++ {
+				A[i][j] -= A[i][k] * A[k][j]
+			}
+		}
+	}
+
+	return nil
+}
+
+// luSolve4 solves A x = b with the LU decomposition given by luDecompose4.
+func luSolve4(LU *[4][4]float64, piv *[4]int, b [4]float64) (x [4]float64) {
+	// forward substitution: L y = P b
+	for i := range 4 {
+		x[i] = b[piv[i]]
+		for j := 0; j < i; j++ {
+			x[i] -= LU[i][j] * x[j]
+		}
+	}
+
+	// backward substitution: U x = y
+	for i := 3; i >= 0; i-- {
+		for j := i + 1; j < 4; j++ {
+			x[i] -= LU[i][j] * x[j]
+		}
+		x[i] /= LU[i][i]
+	}
+
+	return x
+}
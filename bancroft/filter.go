@@ -0,0 +1,381 @@
+package bancroft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FilterModel defines the process model of Filter.
+type FilterModel int
+
+const (
+	// ConstantVelocity propagates the position with the estimated velocity
+	// driven by the white noise acceleration. This is the default.
+	ConstantVelocity FilterModel = iota
+
+	// Static fixes the velocity to zero for a static receiver.
+	Static
+)
+
+// initial standard deviations of the state of Filter
+const (
+	filterInitPosSigma   = 100. // m
+	filterInitVelSigma   = 10.  // m/s
+	filterInitClkSigma   = 100. // m
+	filterInitDriftSigma = 100. // m/s
+)
+
+// the number of the states of Filter: x, y, z, vx, vy, vz, c*dt, c*ddt
+const nFilterState = 8
+
+var (
+	// ErrFilterNotInitialized is returned when Filter is used before Init.
+	ErrFilterNotInitialized = errors.New("filter not initialized")
+
+	// ErrFilterDiverged is returned when the innovations reject the majority
+	// of the measurements, i.e., the state is inconsistent with them.
+	ErrFilterDiverged = errors.New("filter diverged")
+)
+
+// FilterOpts defines options for Filter.
+type FilterOpts struct {
+	// Model is the process model.
+	Model FilterModel
+
+	// AccelPSD is the power spectral density (m^2/s^3) of the white noise
+	// acceleration for ConstantVelocity. 1 is used if zero.
+	AccelPSD float64
+
+	// ClockPSD (m^2/s) and DriftPSD (m^2/s^3) are the power spectral
+	// densities of the receiver clock bias and drift in length.
+	// 100 and 1 are used if zero.
+	ClockPSD, DriftPSD float64
+
+	// SigmaPR is the standard deviation (m) of the pseudoranges.
+	// 5 is used if zero.
+	SigmaPR float64
+
+	// Gate is the threshold of the innovation normalized by its standard
+	// deviation above which the measurement is rejected. 5 is used if zero,
+	// and the gating is disabled if negative.
+	Gate float64
+
+	// AutoReset re-initializes the filter by the snapshot solution of the
+	// epoch on the divergence instead of returning ErrFilterDiverged.
+	AutoReset bool
+}
+
+// Filter is the extended Kalman filter for the positioning across epochs,
+// whose state is the position, velocity (ECEF), the receiver clock bias and
+// drift. The measurements are the same []SatData as CalcPos.
+//
+// A Filter is used as
+//
+//	f := NewFilter(opts)
+//	f.Init(epoch0, satDatas0)
+//	for each epoch:
+//		f.Predict(epoch)
+//		sol, err := f.Update(satDatas)
+//
+// Filter is not safe for concurrent use.
+type Filter struct {
+	opts FilterOpts
+
+	x     *mat.VecDense
+	P     *mat.SymDense
+	epoch time.Time
+
+	initialized bool
+	resets      int
+}
+
+// NewFilter returns a new Filter with the options.
+func NewFilter(opts FilterOpts) *Filter {
+	if opts.AccelPSD == 0 {
+		opts.AccelPSD = 1.
+	}
+	if opts.ClockPSD == 0 {
+		opts.ClockPSD = 100.
+	}
+	if opts.DriftPSD == 0 {
+		opts.DriftPSD = 1.
+	}
+	if opts.SigmaPR == 0 {
+		opts.SigmaPR = 5.
+	}
+	if opts.Gate == 0 {
+		opts.Gate = 5.
+	}
+	return &Filter{opts: opts}
+}
+
+// Init initializes the state by the snapshot solution of Solve at the epoch,
+// with zero velocity and clock drift.
+func (f *Filter) Init(epoch time.Time, satDatas []SatData) error {
+	sol, err := Solve(satDatas, CalcPosOpts{})
+	if err != nil {
+		return fmt.Errorf("filter init: %w", err)
+	}
+
+	f.x = mat.NewVecDense(nFilterState, nil)
+	f.x.SetVec(0, sol.Position[0])
+	f.x.SetVec(1, sol.Position[1])
+	f.x.SetVec(2, sol.Position[2])
+	f.x.SetVec(6, sol.ClockBias*LightVelocity)
+
+	velSigma := filterInitVelSigma
+	if f.opts.Model == Static {
+		velSigma = 0.
+	}
+	f.P = mat.NewSymDense(nFilterState, nil)
+	for i, s := range []float64{
+		filterInitPosSigma, filterInitPosSigma, filterInitPosSigma,
+		velSigma, velSigma, velSigma,
+		filterInitClkSigma, filterInitDriftSigma,
+	} {
+		f.P.SetSym(i, i, s*s)
+	}
+
+	f.epoch = epoch
+	f.initialized = true
+
+	return nil
+}
+
+// Reset discards the state. Init must be called before the next use.
+func (f *Filter) Reset() {
+	f.initialized = false
+	f.x, f.P = nil, nil
+}
+
+// Resets returns the number of the automatic re-initializations on the
+// divergence.
+func (f *Filter) Resets() int {
+	return f.resets
+}
+
+// Predict propagates the state to the epoch by the process model.
+func (f *Filter) Predict(epoch time.Time) error {
+	if !f.initialized {
+		return ErrFilterNotInitialized
+	}
+	dt := epoch.Sub(f.epoch).Seconds()
+	if dt < 0 {
+		return fmt.Errorf("epoch before the filter epoch: %v < %v", epoch, f.epoch)
+	}
+
+	F := mat.NewDense(nFilterState, nFilterState, nil)
+	Q := mat.NewDense(nFilterState, nFilterState, nil)
+	for i := range nFilterState {
+		F.Set(i, i, 1.)
+	}
+
+	if f.opts.Model == ConstantVelocity {
+		q := f.opts.AccelPSD
+		for i := range 3 {
+			F.Set(i, i+3, dt)
+			Q.Set(i, i, q*dt*dt*dt/3.)
+			Q.Set(i, i+3, q*dt*dt/2.)
+			Q.Set(i+3, i, q*dt*dt/2.)
+			Q.Set(i+3, i+3, q*dt)
+		}
+	}
+
+	// clock bias and drift
+	qb, qd := f.opts.ClockPSD, f.opts.DriftPSD
+	F.Set(6, 7, dt)
+	Q.Set(6, 6, qb*dt+qd*dt*dt*dt/3.)
+	Q.Set(6, 7, qd*dt*dt/2.)
+	Q.Set(7, 6, qd*dt*dt/2.)
+	Q.Set(7, 7, qd*dt)
+
+	var x mat.VecDense
+	x.MulVec(F, f.x)
+	f.x = &x
+
+	// P = F P F' + Q
+	var FP, P mat.Dense
+	FP.Mul(F, f.P)
+	P.Mul(&FP, F.T())
+	P.Add(&P, Q)
+	f.P = symmetrize(&P)
+
+	f.epoch = epoch
+
+	return nil
+}
+
+// Update updates the state by the pseudoranges, and returns the solution at
+// the filter epoch with the covariance of the state.
+//
+// The measurements whose normalized innovations exceed FilterOpts.Gate are
+// rejected and listed in Solution.Excluded. If more than half of them are
+// rejected, ErrFilterDiverged is returned, or the filter is re-initialized if
+// FilterOpts.AutoReset is true.
+func (f *Filter) Update(satDatas []SatData) (sol Solution, err error) {
+	if !f.initialized {
+		return sol, ErrFilterNotInitialized
+	}
+	if err = checkSatDatas(satDatas, false); err != nil {
+		return sol, err
+	}
+
+	n := len(satDatas)
+	if n == 0 {
+		return sol, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	}
+
+	H, v := f.linearize(satDatas)
+	S := f.innovationCov(H)
+
+	// innovation gating
+	var used, rejected []int
+	for i := range n {
+		if f.opts.Gate > 0 && math.Abs(v.AtVec(i)) > f.opts.Gate*math.Sqrt(S.At(i, i)) {
+			rejected = append(rejected, i)
+			continue
+		}
+		used = append(used, i)
+	}
+
+	if 2*len(rejected) > n {
+		if !f.opts.AutoReset {
+			return sol, fmt.Errorf("%w: rejected=%d, n=%d", ErrFilterDiverged, len(rejected), n)
+		}
+		if err = f.Init(f.epoch, satDatas); err != nil {
+			return sol, err
+		}
+		f.resets++
+		return f.solution(satDatas, nil), nil
+	}
+
+	if len(rejected) > 0 {
+		sub := make([]SatData, len(used))
+		for k, i := range used {
+			sub[k] = satDatas[i]
+		}
+		H, v = f.linearize(sub)
+		S = f.innovationCov(H)
+	}
+
+	// K = P H' S^-1
+	var Sinv mat.Dense
+	if err = Sinv.Inverse(S); err != nil {
+		return sol, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+	var PHt, K mat.Dense
+	PHt.Mul(f.P, H.T())
+	K.Mul(&PHt, &Sinv)
+
+	// x = x + K v
+	var dx mat.VecDense
+	dx.MulVec(&K, v)
+	f.x.AddVec(f.x, &dx)
+
+	// P = (I - K H) P (I - K H)' + K R K' (Joseph form)
+	IKH := mat.NewDense(nFilterState, nFilterState, nil)
+	IKH.Mul(&K, H)
+	IKH.Scale(-1., IKH)
+	for i := range nFilterState {
+		IKH.Set(i, i, IKH.At(i, i)+1.)
+	}
+	var A, P, KKt mat.Dense
+	A.Mul(IKH, f.P)
+	P.Mul(&A, IKH.T())
+	KKt.Mul(&K, K.T())
+	KKt.Scale(sqr(f.opts.SigmaPR), &KKt)
+	P.Add(&P, &KKt)
+	f.P = symmetrize(&P)
+
+	return f.solution(satDatas, rejected), nil
+}
+
+// linearize returns the design matrix and the innovations of the
+// pseudoranges at the state.
+func (f *Filter) linearize(satDatas []SatData) (H *mat.Dense, v *mat.VecDense) {
+	n := len(satDatas)
+	H = mat.NewDense(n, nFilterState, nil)
+	v = mat.NewVecDense(n, nil)
+
+	pos := [3]float64{f.x.AtVec(0), f.x.AtVec(1), f.x.AtVec(2)}
+	b := f.x.AtVec(6)
+	for i, s := range satDatas {
+		rho := math.Sqrt(sqr(s.X-pos[0]) + sqr(s.Y-pos[1]) + sqr(s.Z-pos[2]))
+		e := lineOfSight(pos, s.X, s.Y, s.Z)
+
+		// PR = rho - c*dt
+		H.Set(i, 0, -e[0])
+		H.Set(i, 1, -e[1])
+		H.Set(i, 2, -e[2])
+		H.Set(i, 6, -1.)
+		v.SetVec(i, s.PR-(rho-b))
+	}
+
+	return H, v
+}
+
+// innovationCov returns S = H P H' + R.
+func (f *Filter) innovationCov(H *mat.Dense) *mat.Dense {
+	var HP, S mat.Dense
+	HP.Mul(H, f.P)
+	S.Mul(&HP, H.T())
+	r := sqr(f.opts.SigmaPR)
+	for i := range S.RawMatrix().Rows {
+		S.Set(i, i, S.At(i, i)+r)
+	}
+	return &S
+}
+
+// solution returns the Solution of the state. The satellites of the indices
+// rejected are listed in Excluded.
+func (f *Filter) solution(satDatas []SatData, rejected []int) Solution {
+	sol := Solution{
+		Epoch:      f.epoch,
+		Position:   [3]float64{f.x.AtVec(0), f.x.AtVec(1), f.x.AtVec(2)},
+		Velocity:   [3]float64{f.x.AtVec(3), f.x.AtVec(4), f.x.AtVec(5)},
+		ClockBias:  f.x.AtVec(6) / LightVelocity,
+		ClockDrift: f.x.AtVec(7) / LightVelocity,
+		Cov:        mat.NewSymDense(nFilterState, nil),
+	}
+	sol.Cov.CopySym(f.P)
+
+	used := make([]SatData, 0, len(satDatas))
+	for i, s := range satDatas {
+		if len(rejected) > 0 && rejected[0] == i {
+			sol.Excluded = append(sol.Excluded, s.Label(i))
+			rejected = rejected[1:]
+			continue
+		}
+		used = append(used, s)
+		sol.SatIDs = append(sol.SatIDs, s.ID)
+	}
+	sol.NumSats = len(used)
+
+	p := sol.Position
+	sol.Residuals = Residuals(used, p[0], p[1], p[2], sol.ClockBias)
+	var ss float64
+	for _, r := range sol.Residuals {
+		ss += r * r
+	}
+	if len(used) > 0 {
+		sol.RMS = math.Sqrt(ss / float64(len(used)))
+	}
+
+	return sol
+}
+
+// symmetrize returns (A + A')/2 as a SymDense.
+func symmetrize(A *mat.Dense) *mat.SymDense {
+	n, _ := A.Dims()
+	S := mat.NewSymDense(n, nil)
+	for i := range n {
+		for j := i; j < n; j++ {
+			S.SetSym(i, j, 0.5*(A.At(i, j)+A.At(j, i)))
+		}
+	}
+	return S
+}
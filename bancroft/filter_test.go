@@ -0,0 +1,138 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// kinematicEpochs returns the pseudoranges of the receiver moving to the east
+// at 10 m/s from the site, with the noise of 3 m, and the true positions.
+func kinematicEpochs(site [3]float64, n int) (epochs []EpochData, truth [][3]float64) {
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	sats := make([][3]float64, len(sc.SatDatas))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+	}
+
+	rng := rand.New(rand.NewPCG(2, 2))
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for k := range n {
+		pos := coord.ENUToECEF(site, [3]float64{10. * float64(k), 0., 0.})
+		noise := make([]float64, len(sats))
+		for i := range noise {
+			noise[i] = 3. * rng.NormFloat64()
+		}
+		epochs = append(epochs, EpochData{
+			Epoch:    t0.Add(time.Duration(k) * time.Second),
+			SatDatas: ComputePseudoranges(pos, 1e-4+1e-8*float64(k), sats, noise),
+		})
+		truth = append(truth, pos)
+	}
+	return epochs, truth
+}
+
+// TestFilter checks that the filtered track is less noisy than the snapshot
+// solutions, and the velocity is estimated.
+func TestFilter(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	epochs, truth := kinematicEpochs(site, 120)
+
+	f := NewFilter(FilterOpts{SigmaPR: 3.})
+	if _, err := f.Update(epochs[0].SatDatas); !errors.Is(err, ErrFilterNotInitialized) {
+		t.Errorf("get err=%v, want %v", err, ErrFilterNotInitialized)
+	}
+	if err := f.Init(epochs[0].Epoch, epochs[0].SatDatas); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	var ssFilter, ssSnap float64
+	var sol Solution
+	for k, ep := range epochs[1:] {
+		if err := f.Predict(ep.Epoch); err != nil {
+			t.Fatalf("Predict: %v", err)
+		}
+		var err error
+		sol, err = f.Update(ep.SatDatas)
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		// skip the convergence
+		if k < 30 {
+			continue
+		}
+		x, y, z, _, _ := CalcPos(ep.SatDatas)
+		p := truth[k+1]
+		ssFilter += sqr(sol.Position[0]-p[0]) + sqr(sol.Position[1]-p[1]) + sqr(sol.Position[2]-p[2])
+		ssSnap += sqr(x-p[0]) + sqr(y-p[1]) + sqr(z-p[2])
+	}
+
+	if !(ssFilter < 0.5*ssSnap) {
+		t.Errorf("filter not smoothing: filter=%f, snapshot=%f", math.Sqrt(ssFilter), math.Sqrt(ssSnap))
+	}
+
+	lat, lon, _ := coord.XYZToLLH(site[0], site[1], site[2])
+	vel := coord.Rotate(coord.ENURotation(lat, lon), sol.Velocity)
+	if math.Abs(vel[0]-10.) > 1. || math.Abs(vel[1]) > 1. || math.Abs(vel[2]) > 1. {
+		t.Errorf("velocity (ENU): %v", vel)
+	}
+	if sol.Cov == nil || sol.Cov.SymmetricDim() != 8 || !(sol.Cov.At(0, 0) > 0) {
+		t.Errorf("invalid covariance: %v", sol.Cov)
+	}
+}
+
+// TestFilterGating checks the outlier is rejected by the innovation gating,
+// and the filter is re-initialized on the divergence.
+func TestFilterGating(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	epochs, _ := kinematicEpochs(site, 40)
+
+	f := NewFilter(FilterOpts{SigmaPR: 3.})
+	f.Init(epochs[0].Epoch, epochs[0].SatDatas)
+	for _, ep := range epochs[1:30] {
+		f.Predict(ep.Epoch)
+		f.Update(ep.SatDatas)
+	}
+
+	// outlier of 200 m
+	ep := epochs[30]
+	ep.SatDatas[2].PR += 200.
+	ep.SatDatas[2].ID = gnss.SatID{Sys: gnss.GPS, PRN: 3}
+	f.Predict(ep.Epoch)
+	sol, err := f.Update(ep.SatDatas)
+	if err != nil || len(sol.Excluded) != 1 || sol.Excluded[0] != "G03" || sol.NumSats != 7 {
+		t.Errorf("outlier not rejected: excluded=%v, err=%v", sol.Excluded, err)
+	}
+
+	// the receiver jumps by 10 km
+	jump := func(ep EpochData) EpochData {
+		sats := make([][3]float64, len(ep.SatDatas))
+		for i, s := range ep.SatDatas {
+			sats[i] = [3]float64{s.X, s.Y, s.Z}
+		}
+		pos := coord.ENUToECEF(site, [3]float64{10000., 0., 0.})
+		return EpochData{Epoch: ep.Epoch, SatDatas: ComputePseudoranges(pos, 1e-4, sats, nil)}
+	}
+
+	f.Predict(epochs[31].Epoch)
+	if _, err := f.Update(jump(epochs[31]).SatDatas); !errors.Is(err, ErrFilterDiverged) {
+		t.Errorf("get err=%v, want %v", err, ErrFilterDiverged)
+	}
+
+	f = NewFilter(FilterOpts{SigmaPR: 3., AutoReset: true})
+	f.Init(epochs[0].Epoch, epochs[0].SatDatas)
+	f.Predict(epochs[1].Epoch)
+	sol, err = f.Update(jump(epochs[1]).SatDatas)
+	if err != nil || f.Resets() != 1 {
+		t.Fatalf("not reset: resets=%d, err=%v", f.Resets(), err)
+	}
+	if enu := coord.ECEFToENU(site, sol.Position); math.Abs(enu[0]-10000.) > 1. {
+		t.Errorf("position after reset: %v", enu)
+	}
+}
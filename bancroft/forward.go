@@ -0,0 +1,32 @@
+package bancroft
+
+import "math"
+
+// ComputePseudorange returns the pseudorange (m) observed at the receiver
+// position rcv (ECEF, m) with the receiver clock bias dt (s) for the satellite
+// position sat (ECEF, m). This is the forward model of CalcPos:
+//
+//	PR = |sat - rcv| - c*dt
+//
+// See SatData for the sign of dt.
+func ComputePseudorange(rcv [3]float64, dt float64, sat [3]float64) float64 {
+	rho := math.Sqrt(sqr(sat[0]-rcv[0]) + sqr(sat[1]-rcv[1]) + sqr(sat[2]-rcv[2]))
+	return rho - dt*LightVelocity
+}
+
+// ComputePseudoranges returns the SatData for the satellite positions sats
+// (ECEF, m) with the pseudoranges computed by ComputePseudorange.
+//
+// If extra is not nil, extra[i] (m) is added to the pseudorange of the i-th
+// satellite, which can be used to simulate the tropospheric and ionospheric
+// delays, the noises, etc.
+func ComputePseudoranges(rcv [3]float64, dt float64, sats [][3]float64, extra []float64) []SatData {
+	satDatas := make([]SatData, len(sats))
+	for i, s := range sats {
+		satDatas[i] = SatData{X: s[0], Y: s[1], Z: s[2], PR: ComputePseudorange(rcv, dt, s)}
+		if extra != nil {
+			satDatas[i].PR += extra[i]
+		}
+	}
+	return satDatas
+}
@@ -0,0 +1,44 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestForwardRoundTrip synthesizes the pseudoranges from the known position,
+// and checks CalcPos recovers it.
+func TestForwardRoundTrip(t *testing.T) {
+	rcv := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	const dt = -1.5e-4 // s
+
+	for n := 4; n <= len(satPosData); n++ {
+		satDatas := ComputePseudoranges(rcv, dt, testSatPositions()[:n], nil)
+
+		x, y, z, dt1, err := CalcPos(satDatas)
+		if err != nil {
+			t.Fatalf("n=%d: CalcPos: %v", n, err)
+		}
+
+		d := math.Sqrt(sqr(x-rcv[0]) + sqr(y-rcv[1]) + sqr(z-rcv[2]))
+		if d > 1e-5 || math.Abs(dt1-dt) > 1e-13 {
+			t.Errorf("n=%d: pos error=%e m, dt=%e, want dt=%e", n, d, dt1, dt)
+		}
+	}
+
+	// extra terms
+	extra := make([]float64, len(satPosData))
+	extra[2] = 10.
+	satDatas := ComputePseudoranges(rcv, dt, testSatPositions(), extra)
+	if want := ComputePseudorange(rcv, dt, testSatPositions()[2]) + 10.; satDatas[2].PR != want {
+		t.Errorf("extra not added: get %f, want %f", satDatas[2].PR, want)
+	}
+}
+
+// testSatPositions returns the satellite positions (m) of satPosData.
+func testSatPositions() [][3]float64 {
+	pos := make([][3]float64, len(satPosData))
+	for i, sp := range satPosData {
+		pos[i] = [3]float64{sp.X * 1000., sp.Y * 1000., sp.Z * 1000.}
+	}
+	return pos
+}
@@ -0,0 +1,60 @@
+package bancroft
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// DefaultMaxGeometryCond is the default threshold of the condition number of
+// the design matrix used by AssessGeometry.
+const DefaultMaxGeometryCond = 1e4
+
+// GeometryAssessment stores the result of AssessGeometry.
+type GeometryAssessment struct {
+	NumSats int
+
+	// Cond is the condition number of the design matrix
+	Cond float64
+
+	// GDOP is the approximate GDOP at the approximate position
+	GDOP float64
+
+	// OK is true if the geometry is solvable
+	OK bool
+}
+
+// AssessGeometry checks the satellite geometry seen from the approximate
+// receiver position approxPos (ECEF, m) before solving: at least four
+// satellites, and the condition number of the design matrix (see CalcDOP)
+// below maxCond. DefaultMaxGeometryCond is used if maxCond is zero.
+//
+// Near-coplanar line-of-sight vectors, e.g., all satellites at the same
+// elevation, make the design matrix singular. ErrSingularGeometry is returned
+// with the assessment in that case.
+func AssessGeometry(satDatas []SatData, approxPos [3]float64, maxCond float64) (a GeometryAssessment, err error) {
+	if maxCond == 0 {
+		maxCond = DefaultMaxGeometryCond
+	}
+	a.NumSats = len(satDatas)
+
+	H, err := designMatrix(approxPos, satDatas)
+	if err != nil {
+		return a, err
+	}
+
+	a.Cond = mat.Cond(H, 2)
+	if !(a.Cond < maxCond) {
+		return a, fmt.Errorf("%w: cond=%e, threshold=%e", ErrSingularGeometry, a.Cond, maxCond)
+	}
+
+	var N, Q mat.Dense
+	N.Mul(H.T(), H)
+	if err = Q.Inverse(&N); err != nil {
+		return a, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+	a.GDOP = dopFromCofactor(approxPos, &Q).GDOP
+	a.OK = true
+
+	return a, nil
+}
@@ -0,0 +1,42 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestAssessGeometry checks the assessment for the good, clustered, and
+// coplanar geometries.
+func TestAssessGeometry(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	// good geometry
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	a, err := AssessGeometry(sc.SatDatas, site, 0)
+	if err != nil || !a.OK || a.GDOP > 10 {
+		t.Errorf("good geometry: %+v, err=%v", a, err)
+	}
+
+	// GDOP agrees with CalcDOP
+	dop, err := CalcDOP(site, sc.SatDatas)
+	if err != nil || math.Abs(dop.GDOP-a.GDOP) > 1e-12 {
+		t.Errorf("CalcDOP: %+v, err=%v", dop, err)
+	}
+
+	// all satellites at the same elevation: coplanar line-of-sight vectors
+	coplanar := make([][3]float64, 6)
+	for i := range coplanar {
+		az, el := float64(i)*math.Pi/3., math.Pi/6.
+		coplanar[i] = satOnSky(site, siteRotation(site), az, el, 26560e3)
+	}
+	_, err = AssessGeometry(ComputePseudoranges(site, 0, coplanar, nil), site, 0)
+	if !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("coplanar geometry: get err=%v, want %v", err, ErrSingularGeometry)
+	}
+
+	// too few satellites
+	if _, err := AssessGeometry(ComputePseudoranges(site, 0, coplanar[:3], nil), site, 0); err == nil {
+		t.Errorf("no error for too few satellites")
+	}
+}
@@ -0,0 +1,153 @@
+package bancroft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// SearchRegion defines the region of GridSearch.
+type SearchRegion struct {
+	// Center is the center of the region (ECEF, m), e.g., an approximate
+	// position on the Earth's surface.
+	Center [3]float64
+
+	// HalfWidth is the half widths (m) of the region in the local east,
+	// north, up frame at Center, e.g., {10e3, 10e3, 10e3}.
+	HalfWidth [3]float64
+
+	// MinDt and MaxDt bound the receiver clock bias (s) as dt of CalcPos,
+	// e.g., -1e-3 and 1e-3.
+	MinDt, MaxDt float64
+
+	// MaxLevels is the maximum number of the coarse-to-fine levels.
+	// 30 is used if zero.
+	MaxLevels int
+}
+
+const (
+	// gridHalfPoints is the number of the grid points on each side of the
+	// center per axis; a level evaluates (2*gridHalfPoints+1)^3 points.
+	gridHalfPoints = 5
+
+	// gridTol is the grid spacing (m) at which the search stops.
+	gridTol = 1e-3
+)
+
+// GridSearch solves the position minimizing the RMS of the pseudorange
+// residuals by the coarse-to-fine grid search over the region. At each
+// level, the grid around the best point of the previous level is refined by
+// the factor of gridHalfPoints/2. The clock bias is solved at each grid point
+// by the mean of the residuals and clamped to [MinDt, MaxDt].
+//
+// The search is bounded by SearchRegion.MaxLevels levels, and may converge
+// to a local minimum; the solution is marked by Solution.LowQuality.
+func GridSearch(satDatas []SatData, region SearchRegion) (sol Solution, err error) {
+	if len(satDatas) < 4 {
+		return sol, fmt.Errorf("%w: n=%d", ErrTooFewSats, len(satDatas))
+	}
+	if err = checkSatDatas(satDatas, false); err != nil {
+		return sol, err
+	}
+	hw := region.HalfWidth
+	if !(hw[0] > 0 && hw[1] > 0 && hw[2] > 0) || region.MinDt > region.MaxDt {
+		return sol, fmt.Errorf("%w: invalid search region: %+v", ErrBadInput, region)
+	}
+	maxLevels := region.MaxLevels
+	if maxLevels == 0 {
+		maxLevels = 30
+	}
+
+	// local frame at the center
+	lat, lon, _ := coord.XYZToLLH(region.Center[0], region.Center[1], region.Center[2])
+	R := coord.ENURotation(lat, lon)
+	toECEF := func(enu [3]float64) [3]float64 {
+		d := coord.RotateT(R, enu)
+		return [3]float64{region.Center[0] + d[0], region.Center[1] + d[1], region.Center[2] + d[2]}
+	}
+
+	var best [3]float64 // ENU
+	bestCost, bestDt := math.Inf(1), 0.
+	var step [3]float64
+	for range maxLevels {
+		for k := range 3 {
+			step[k] = hw[k] / gridHalfPoints
+		}
+
+		c := best
+		for i := -gridHalfPoints; i <= gridHalfPoints; i++ {
+			for j := -gridHalfPoints; j <= gridHalfPoints; j++ {
+				for k := -gridHalfPoints; k <= gridHalfPoints; k++ {
+					enu := [3]float64{c[0] + float64(i)*step[0], c[1] + float64(j)*step[1], c[2] + float64(k)*step[2]}
+					cost, dt := gridCost(satDatas, toECEF(enu), region.MinDt, region.MaxDt)
+					if cost < bestCost {
+						best, bestCost, bestDt = enu, cost, dt
+					}
+				}
+			}
+		}
+
+		if math.Max(step[0], math.Max(step[1], step[2])) < gridTol {
+			break
+		}
+
+		// the next level covers two steps around the best point
+		for k := range 3 {
+			hw[k] = 2. * step[k]
+		}
+	}
+
+	p := toECEF(best)
+	sol = Solution{
+		Position:   p,
+		ClockBias:  bestDt,
+		NumSats:    len(satDatas),
+		SatIDs:     make([]gnss.SatID, len(satDatas)),
+		Residuals:  Residuals(satDatas, p[0], p[1], p[2], bestDt),
+		RMS:        math.Sqrt(bestCost / float64(len(satDatas))),
+		LowQuality: true,
+	}
+	for i, s := range satDatas {
+		sol.SatIDs[i] = s.ID
+	}
+
+	return sol, nil
+}
+
+// gridCost returns the sum of the squared residuals at the position pos
+// (ECEF, m) with the clock bias dt (s) solved in [minDt, maxDt].
+func gridCost(satDatas []SatData, pos [3]float64, minDt, maxDt float64) (cost, dt float64) {
+	// PR = rho - c*dt
+	var sum float64
+	for _, s := range satDatas {
+		rho := math.Sqrt(sqr(s.X-pos[0]) + sqr(s.Y-pos[1]) + sqr(s.Z-pos[2]))
+		sum += rho - s.PR
+	}
+	dt = sum / float64(len(satDatas)) / LightVelocity
+	dt = math.Max(minDt, math.Min(maxDt, dt))
+
+	for _, s := range satDatas {
+		rho := math.Sqrt(sqr(s.X-pos[0]) + sqr(s.Y-pos[1]) + sqr(s.Z-pos[2]))
+		cost += sqr(s.PR - (rho - dt*LightVelocity))
+	}
+	return cost, dt
+}
+
+// SolveWithFallback solves the position by Solve, and by GridSearch over the
+// region only if Solve fails except for the invalid inputs. The solution of
+// the fallback is marked by Solution.LowQuality.
+func SolveWithFallback(satDatas []SatData, opts CalcPosOpts, region SearchRegion) (Solution, error) {
+	sol, err := Solve(satDatas, opts)
+	if err == nil || errors.Is(err, ErrBadInput) || errors.Is(err, ErrTooFewSats) {
+		return sol, err
+	}
+
+	sol, e := GridSearch(satDatas, region)
+	if e != nil {
+		return sol, fmt.Errorf("fallback failed: '%w', primary error='%v'", e, err)
+	}
+	return sol, nil
+}
@@ -0,0 +1,54 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestSolveWithFallback checks the grid search is used when Bancroft method
+// has no real solution for the clustered geometry with an outlier.
+func TestSolveWithFallback(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, Dt: 1e-4, NumSats: 4, MinElevation: 10., Cluster: 20., Seed: 1})
+	satDatas := sc.SatDatas
+	satDatas[0].PR += 1000.
+
+	if _, err := CalcPosAll(satDatas); !errors.Is(err, ErrNoRealSolution) {
+		t.Fatalf("Bancroft not failed: err=%v", err)
+	}
+
+	region := SearchRegion{
+		Center:    coord.ENUToECEF(site, [3]float64{3000., -2000., 500.}),
+		HalfWidth: [3]float64{10e3, 10e3, 10e3},
+		MinDt:     -1e-3,
+		MaxDt:     1e-3,
+	}
+	sol, err := SolveWithFallback(satDatas, CalcPosOpts{}, region)
+	if err != nil {
+		t.Fatalf("SolveWithFallback: %v", err)
+	}
+	if !sol.LowQuality {
+		t.Errorf("fallback solution not marked")
+	}
+
+	// better fit than the true position and the center
+	c, _ := gridCost(satDatas, region.Center, region.MinDt, region.MaxDt)
+	s, _ := gridCost(satDatas, site, region.MinDt, region.MaxDt)
+	if rms := sol.RMS; !(rms <= math.Sqrt(c/4.)) || !(rms <= math.Sqrt(s/4.)+1e-3) {
+		t.Errorf("RMS=%f, center=%f, truth=%f", rms, math.Sqrt(c/4.), math.Sqrt(s/4.))
+	}
+
+	// no fallback for the good data
+	sc = GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	if sol, err := SolveWithFallback(sc.SatDatas, CalcPosOpts{}, region); err != nil || sol.LowQuality {
+		t.Errorf("fallback used: %+v, err=%v", sol, err)
+	}
+
+	// invalid region
+	if _, err := GridSearch(satDatas, SearchRegion{Center: site}); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
@@ -0,0 +1,90 @@
+package bancroft
+
+import "fmt"
+
+// carrier frequencies (Hz) for the group delay scaling
+const (
+	freqL1  = 1575.42e6 // GPS/QZSS L1, Galileo E1
+	freqL2  = 1227.60e6 // GPS/QZSS L2
+	freqL5  = 1176.45e6 // GPS/QZSS L5, Galileo E5a
+	freqE5b = 1207.14e6 // Galileo E5b
+)
+
+// GroupDelay stores the broadcast group delay parameters (s) of a satellite.
+type GroupDelay struct {
+	// TGD is the TGD of GPS and QZSS, or TGD1 (B1I) of BeiDou.
+	TGD float64
+
+	// TGD2 is TGD2 (B2I) of BeiDou.
+	TGD2 float64
+
+	// BGD is the BGD of Galileo paired with the clock used: BGD(E1,E5a) for
+	// the F/NAV clock, or BGD(E1,E5b) for the I/NAV clock.
+	BGD float64
+}
+
+// GroupDelayCorrection returns the range correction (m) of the broadcast
+// group delay for the pseudorange of the satellite system sys and the RINEX
+// frequency band, to be added to the pseudorange with the broadcast clock
+// correction (see BroadcastClockCorrection).
+//
+// The broadcast clocks of GPS, QZSS and Galileo refer to the
+// ionosphere-free combination of the two frequencies, and that of BeiDou to
+// B3I. The conventions of the signal specifications are:
+//
+//	GPS, QZSS (IS-GPS-200, 705; ISC not applied):
+//	  L1 (1):  -c*TGD
+//	  L2 (2):  -c*(f1/f2)^2*TGD
+//	  L5 (5):  -c*(f1/f5)^2*TGD
+//	Galileo (OS SIS ICD 5.1.5):
+//	  E1 (1):  -c*BGD
+//	  E5a (5): -c*(f1/f5a)^2*BGD  with BGD(E1,E5a) of F/NAV
+//	  E5b (7): -c*(f1/f5b)^2*BGD  with BGD(E1,E5b) of I/NAV
+//	BeiDou (ICD B1I/B3I 5.2.4.10; B1I is 2 since RINEX 3.02, 1 before):
+//	  B1I (1, 2): -c*TGD1
+//	  B2I (7):    -c*TGD2
+//	  B3I (6):    0
+//
+// ErrBadInput is returned for the other systems and bands.
+func GroupDelayCorrection(sys byte, band int, gd GroupDelay) (float64, error) {
+	var tgd float64
+	switch sys {
+	case 'G', 'J':
+		switch band {
+		case 1:
+			tgd = gd.TGD
+		case 2:
+			tgd = sqr(freqL1/freqL2) * gd.TGD
+		case 5:
+			tgd = sqr(freqL1/freqL5) * gd.TGD
+		default:
+			return 0., fmt.Errorf("%w: unsupported band: sys='%c', band=%d", ErrBadInput, sys, band)
+		}
+	case 'E':
+		switch band {
+		case 1:
+			tgd = gd.BGD
+		case 5:
+			tgd = sqr(freqL1/freqL5) * gd.BGD
+		case 7:
+			tgd = sqr(freqL1/freqE5b) * gd.BGD
+		default:
+			return 0., fmt.Errorf("%w: unsupported band: sys='%c', band=%d", ErrBadInput, sys, band)
+		}
+	case 'C':
+		switch band {
+		case 1, 2:
+			tgd = gd.TGD
+		case 7:
+			tgd = gd.TGD2
+		case 6:
+			tgd = 0.
+		default:
+			return 0., fmt.Errorf("%w: unsupported band: sys='%c', band=%d", ErrBadInput, sys, band)
+		}
+	default:
+		return 0., fmt.Errorf("%w: no group delay parameter: sys='%c'", ErrBadInput, sys)
+	}
+
+	return -tgd * LightVelocity, nil
+}
@@ -0,0 +1,53 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestGroupDelayCorrection checks the signs and the scaling of the group
+// delay corrections of the systems.
+func TestGroupDelayCorrection(t *testing.T) {
+	const tgd = 5e-9 // s, about 1.5 m
+	gamma := sqr(77. / 60.)
+
+	for _, tt := range []struct {
+		sys  byte
+		band int
+		gd   GroupDelay
+		want float64 // m
+	}{
+		{'G', 1, GroupDelay{TGD: tgd}, -tgd * LightVelocity},
+		{'G', 2, GroupDelay{TGD: tgd}, -gamma * tgd * LightVelocity},
+		{'G', 5, GroupDelay{TGD: tgd}, -sqr(154./115.) * tgd * LightVelocity},
+		{'J', 1, GroupDelay{TGD: -tgd}, tgd * LightVelocity},
+		{'E', 1, GroupDelay{BGD: tgd}, -tgd * LightVelocity},
+		{'E', 5, GroupDelay{BGD: tgd}, -sqr(154./115.) * tgd * LightVelocity},
+		{'E', 7, GroupDelay{BGD: tgd}, -sqr(154./118.) * tgd * LightVelocity},
+		{'C', 2, GroupDelay{TGD: tgd, TGD2: 2 * tgd}, -tgd * LightVelocity},
+		{'C', 7, GroupDelay{TGD: tgd, TGD2: 2 * tgd}, -2 * tgd * LightVelocity},
+		{'C', 6, GroupDelay{TGD: tgd, TGD2: 2 * tgd}, 0.},
+	} {
+		got, err := GroupDelayCorrection(tt.sys, tt.band, tt.gd)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%c%d: get %f, want %f, err=%v", tt.sys, tt.band, got, tt.want, err)
+		}
+	}
+
+	// the ionosphere-free combination of L1 and L2 is free of TGD
+	c1, _ := GroupDelayCorrection('G', 1, GroupDelay{TGD: tgd})
+	c2, _ := GroupDelayCorrection('G', 2, GroupDelay{TGD: tgd})
+	if d := (gamma*c1 - c2) / (gamma - 1.); math.Abs(d) > 1e-9 {
+		t.Errorf("ionosphere-free correction: %e", d)
+	}
+
+	for _, tt := range []struct {
+		sys  byte
+		band int
+	}{{'R', 1}, {'G', 7}, {'E', 2}, {'C', 5}} {
+		if _, err := GroupDelayCorrection(tt.sys, tt.band, GroupDelay{}); !errors.Is(err, ErrBadInput) {
+			t.Errorf("%c%d: get err=%v, want %v", tt.sys, tt.band, err, ErrBadInput)
+		}
+	}
+}
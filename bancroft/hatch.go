@@ -0,0 +1,103 @@
+package bancroft
+
+import (
+	"math"
+	"time"
+)
+
+// HatchOpts defines options for HatchFilter.
+type HatchOpts struct {
+	// TimeConstant caps the smoothing window to limit the divergence of the
+	// code and the carrier by the ionosphere. 100 s is used if zero.
+	TimeConstant time.Duration
+
+	// MaxGap is the maximum interval of the epochs of a satellite; the
+	// smoothing is reset after a longer gap. 30 s is used if zero.
+	MaxGap time.Duration
+
+	// SlipThreshold (m) is the threshold of the difference between the code
+	// and the carrier changes from the previous epoch above which a cycle
+	// slip is detected and the smoothing is reset. 10 m is used if zero.
+	SlipThreshold float64
+}
+
+// HatchFilter smooths the pseudoranges of the satellites by the carrier
+// phases (Hatch, 1982):
+//
+//	Ps_k = P_k/n + (n-1)/n * (Ps_{k-1} + L_k - L_{k-1})
+//
+// where P and L are the pseudorange and the carrier phase (m) of the same
+// frequency, and n is the number of the epochs since the reset capped at
+// TimeConstant divided by the interval.
+//
+// HatchFilter is not safe for concurrent use.
+type HatchFilter struct {
+	opts HatchOpts
+	sats map[string]*hatchState
+}
+
+// hatchState is the smoothing state of a satellite.
+type hatchState struct {
+	epoch    time.Time
+	n        int
+	pr, l    float64 // previous pseudorange and carrier phase (m)
+	smoothed float64
+}
+
+// NewHatchFilter returns a new HatchFilter with the options.
+func NewHatchFilter(opts HatchOpts) *HatchFilter {
+	if opts.TimeConstant == 0 {
+		opts.TimeConstant = 100 * time.Second
+	}
+	if opts.MaxGap == 0 {
+		opts.MaxGap = 30 * time.Second
+	}
+	if opts.SlipThreshold == 0 {
+		opts.SlipThreshold = 10.
+	}
+	return &HatchFilter{opts: opts, sats: make(map[string]*hatchState)}
+}
+
+// Smooth adds the pseudorange pr and the carrier phase l (m) of the satellite
+// at the epoch, and returns the smoothed pseudorange. reset reports whether
+// the smoothing was (re)started at the epoch, by the first epoch, a data gap,
+// or a cycle slip.
+func (h *HatchFilter) Smooth(id string, epoch time.Time, pr, l float64) (smoothed float64, reset bool) {
+	s, ok := h.sats[id]
+	if ok {
+		dt := epoch.Sub(s.epoch)
+		slip := math.Abs((l-s.l)-(pr-s.pr)) > h.opts.SlipThreshold
+		if dt <= 0 || dt > h.opts.MaxGap || slip || math.IsNaN(l) {
+			ok = false
+		}
+	}
+
+	if !ok {
+		s = &hatchState{epoch: epoch, n: 1, pr: pr, l: l, smoothed: pr}
+		h.sats[id] = s
+		return pr, true
+	}
+
+	nmax := max(1, int(math.Round(float64(h.opts.TimeConstant)/float64(epoch.Sub(s.epoch)))))
+	s.n = min(s.n+1, nmax)
+
+	w := 1. / float64(s.n)
+	s.smoothed = w*pr + (1.-w)*(s.smoothed+l-s.l)
+	s.epoch, s.pr, s.l = epoch, pr, l
+
+	return s.smoothed, false
+}
+
+// Window returns the current smoothing window (epochs) of the satellite, or
+// zero if the satellite is not tracked.
+func (h *HatchFilter) Window(id string) int {
+	if s, ok := h.sats[id]; ok {
+		return s.n
+	}
+	return 0
+}
+
+// Reset discards the smoothing state of the satellite.
+func (h *HatchFilter) Reset(id string) {
+	delete(h.sats, id)
+}
@@ -0,0 +1,64 @@
+package bancroft
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// TestHatchFilter checks the noise reduction, the window cap, and the resets
+// by a cycle slip and a data gap.
+func TestHatchFilter(t *testing.T) {
+	h := NewHatchFilter(HatchOpts{TimeConstant: 60 * time.Second})
+	rng := rand.New(rand.NewPCG(1, 1))
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	const amb = 1234.5 // carrier ambiguity (m)
+	rho := func(k int) float64 { return 2.2e7 + 500.*float64(k) }
+
+	var ss float64
+	var ns int
+	for k := range 300 {
+		epoch := t0.Add(time.Duration(k) * time.Second)
+		l := rho(k) + amb + 0.002*rng.NormFloat64()
+		if k >= 200 {
+			l += 30. // cycle slip
+		}
+		ps, reset := h.Smooth("G01", epoch, rho(k)+2.*rng.NormFloat64(), l)
+
+		switch {
+		case k == 0 || k == 200:
+			if !reset || h.Window("G01") != 1 {
+				t.Errorf("k=%d: not reset: window=%d", k, h.Window("G01"))
+			}
+		case reset:
+			t.Errorf("k=%d: unexpected reset", k)
+		}
+		if k >= 100 && k < 200 {
+			ss += sqr(ps - rho(k))
+			ns++
+		}
+	}
+
+	// 100 epochs since the slip capped at 60
+	if w := h.Window("G01"); w != 60 {
+		t.Errorf("window=%d, want 60", w)
+	}
+	if rms := math.Sqrt(ss / float64(ns)); rms > 0.5 {
+		t.Errorf("smoothed RMS=%f, raw=2", rms)
+	}
+
+	// data gap
+	if _, reset := h.Smooth("G01", t0.Add(400*time.Second), rho(400), rho(400)+amb+30.); !reset {
+		t.Errorf("not reset after the gap")
+	}
+
+	if h.Window("G02") != 0 {
+		t.Errorf("untracked satellite has a window")
+	}
+	h.Reset("G01")
+	if h.Window("G01") != 0 {
+		t.Errorf("not reset")
+	}
+}
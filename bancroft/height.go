@@ -0,0 +1,91 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// HeightConstraint defines the known height of the receiver used by
+// CalcPosHeightConstrained.
+type HeightConstraint struct {
+	// Height is the ellipsoidal height (m) on WGS84, or the orthometric
+	// (MSL) height if Geoid is given.
+	Height float64
+
+	// Geoid is the geoid height (m), i.e., ellipsoidal = Height + Geoid.
+	Geoid float64
+
+	// Sigma is the standard deviation (m) of the height.
+	Sigma float64
+}
+
+// CalcPosHeightConstrained solves the position with the known height as a
+// pseudo-observation, which allows a solution from three satellites.
+//
+// The height constraint enters the least squares with the weight 1/Sigma^2,
+// relative to the weight 1/sigmaPR^2 of the pseudoranges, so its influence
+// decreases as the number of satellites increases.
+//
+// approxPos (ECEF, m) is the initial position of the iteration. If it is the
+// zero vector, the solution of CalcPos is used with four or more satellites,
+// and otherwise the point at the given height below the mean of the satellite
+// positions is used.
+func CalcPosHeightConstrained(satDatas []SatData, hc HeightConstraint, sigmaPR float64, approxPos [3]float64) (x, y, z, dt float64, err error) {
+	if len(satDatas) < 3 {
+		return 0., 0., 0., 0., fmt.Errorf("%w: n=%d", ErrTooFewSats, len(satDatas))
+	}
+	if !(hc.Sigma > 0) || !(sigmaPR > 0) {
+		return 0., 0., 0., 0., fmt.Errorf("%w: invalid sigma: height=%v, pr=%v", ErrBadInput, hc.Sigma, sigmaPR)
+	}
+	h := hc.Height + hc.Geoid
+
+	// initial value
+	x0 := make([]float64, 4)
+	switch {
+	case approxPos != [3]float64{}:
+		x0[0], x0[1], x0[2] = approxPos[0], approxPos[1], approxPos[2]
+	case len(satDatas) >= 4:
+		var dt0 float64
+		x0[0], x0[1], x0[2], dt0, err = CalcPos(satDatas)
+		if err != nil {
+			return 0., 0., 0., 0., err
+		}
+		x0[3] = -dt0 * LightVelocity
+	default:
+		var sx, sy, sz float64
+		for _, s := range satDatas {
+			sx, sy, sz = sx+s.X, sy+s.Y, sz+s.Z
+		}
+		lat, lon, _ := coord.XYZToLLH(sx, sy, sz)
+		x0[0], x0[1], x0[2] = coord.LLHToXYZ(lat, lon, h)
+	}
+
+	if x0[3] == 0 {
+		// initial clock from the mean of the residuals
+		for _, s := range satDatas {
+			x0[3] += s.PR - math.Sqrt(sqr(s.X-x0[0])+sqr(s.Y-x0[1])+sqr(s.Z-x0[2]))
+		}
+		x0[3] /= float64(len(satDatas))
+	}
+
+	w := make([]float64, len(satDatas))
+	for i := range w {
+		w[i] = 1. / sqr(sigmaPR)
+	}
+
+	p := lsqProblem{
+		satDatas: satDatas,
+		clk:      make([]int, len(satDatas)),
+		nclk:     1,
+		weights:  w,
+		height:   &heightObs{h: h, w: 1. / sqr(hc.Sigma)},
+	}
+	state, _, err := p.solve(x0, 20, 1e-4)
+	if err != nil {
+		return 0., 0., 0., 0., err
+	}
+
+	return state[0], state[1], state[2], -state[3] / LightVelocity, nil
+}
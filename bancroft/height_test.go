@@ -0,0 +1,31 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCalcPosHeightConstrained checks the solution from three satellites with
+// the known height.
+func TestCalcPosHeightConstrained(t *testing.T) {
+	pos := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	_, _, h := coord.XYZToLLH(pos[0], pos[1], pos[2])
+	const dt = 1e-4 // receiver clock (s)
+
+	satDatas := ComputePseudoranges(pos, dt, testSatPositions(), nil)
+
+	hc := HeightConstraint{Height: h, Sigma: 0.01}
+	for _, n := range []int{3, 4, len(satDatas)} {
+		x, y, z, dt1, err := CalcPosHeightConstrained(satDatas[:n], hc, 1., [3]float64{})
+		if err != nil {
+			t.Errorf("n=%d: %v", n, err)
+			continue
+		}
+		d := math.Sqrt(sqr(x-pos[0]) + sqr(y-pos[1]) + sqr(z-pos[2]))
+		if d > 1e-3 || math.Abs(dt1-dt) > 1e-11 {
+			t.Errorf("n=%d: pos error=%e m, dt=%e, want dt=%e", n, d, dt1, dt)
+		}
+	}
+}
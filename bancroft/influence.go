@@ -0,0 +1,90 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Leverage returns the leverage of the satellites seen from the receiver
+// position pos (ECEF, m), i.e., the diagonal elements of the hat matrix
+//
+//	A (A'WA)^-1 A'W
+//
+// where A is the design matrix (see DesignMatrix) and W is the diagonal
+// matrix of the weights (all 1 if nil). The leverage is in [0, 1] and sums
+// up to the number of the unknowns (4); the errors of the satellites with
+// the leverage close to 1 propagate into the solution almost entirely and
+// can hardly be detected by the residuals.
+func Leverage(pos [3]float64, satDatas []SatData, weights []float64) ([]float64, error) {
+	if weights != nil && len(weights) != len(satDatas) {
+		return nil, fmt.Errorf("%w: size mismatch: sats=%d, weights=%d", ErrBadInput, len(satDatas), len(weights))
+	}
+
+	H, err := designMatrix(pos, satDatas)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(satDatas)
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1.
+		if weights != nil {
+			w[i] = weights[i]
+		}
+		if !(w[i] > 0) || math.IsInf(w[i], 0) {
+			return nil, fmt.Errorf("%w: invalid weight: w=%v", ErrBadInput, w[i])
+		}
+	}
+
+	N := mat.NewSymDense(4, nil)
+	for i := range n {
+		N.SymRankOne(N, w[i], H.RowView(i))
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(N); !ok {
+		return nil, fmt.Errorf("%w: normal matrix not positive definite", ErrSingularGeometry)
+	}
+
+	lev := make([]float64, n)
+	var q mat.VecDense
+	for i := range n {
+		a := H.RowView(i)
+		if err := chol.SolveVecTo(&q, a); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+		}
+		lev[i] = w[i] * mat.Dot(a, &q)
+	}
+
+	return lev, nil
+}
+
+// GDOPDelta returns the increase of GDOP by removing each satellite seen
+// from the receiver position pos (ECEF, m), i.e., the leave-one-out GDOP
+// minus GDOP with all the satellites. +Inf is set for a satellite without
+// which the geometry is singular, e.g., any satellite of four.
+func GDOPDelta(pos [3]float64, satDatas []SatData) ([]float64, error) {
+	all, err := CalcDOP(pos, satDatas)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(satDatas)
+	delta := make([]float64, n)
+	subset := make([]SatData, n-1)
+	for i := range n {
+		copy(subset, satDatas[:i])
+		copy(subset[i:], satDatas[i+1:])
+
+		dop, err := CalcDOP(pos, subset)
+		if err != nil {
+			delta[i] = math.Inf(1)
+			continue
+		}
+		delta[i] = dop.GDOP - all.GDOP
+	}
+
+	return delta, nil
+}
@@ -0,0 +1,90 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestLeverage checks the leverage against the explicit hat matrix, and the
+// leave-one-out GDOP deltas.
+func TestLeverage(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	satDatas := sc.SatDatas
+	n := len(satDatas)
+
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1. + float64(i)
+	}
+
+	for _, weights := range [][]float64{nil, w} {
+		lev, err := Leverage(site, satDatas, weights)
+		if err != nil {
+			t.Fatalf("Leverage: %v", err)
+		}
+
+		// hat matrix A (A'WA)^-1 A'W
+		A, _, _ := DesignMatrix(site, satDatas)
+		W := mat.NewDiagDense(n, nil)
+		for i := range n {
+			W.SetDiag(i, 1.)
+			if weights != nil {
+				W.SetDiag(i, weights[i])
+			}
+		}
+		var AtW, N, Q, P, hat mat.Dense
+		AtW.Mul(A.T(), W)
+		N.Mul(&AtW, A)
+		if err := Q.Inverse(&N); err != nil {
+			t.Fatalf("Inverse: %v", err)
+		}
+		P.Mul(A, &Q)
+		hat.Mul(&P, &AtW)
+
+		var sum float64
+		for i, h := range lev {
+			sum += h
+			if math.Abs(h-hat.At(i, i)) > 1e-9 || h < 0 || h > 1 {
+				t.Errorf("sat %d: get %f, want %f", i, h, hat.At(i, i))
+			}
+		}
+		if math.Abs(sum-4.) > 1e-9 {
+			t.Errorf("sum of leverage: get %f, want 4", sum)
+		}
+	}
+
+	// all the satellites are essential with four satellites
+	lev, _ := Leverage(site, satDatas[:4], nil)
+	delta, _ := GDOPDelta(site, satDatas[:4])
+	for i := range 4 {
+		if math.Abs(lev[i]-1.) > 1e-9 || !math.IsInf(delta[i], 1) {
+			t.Errorf("sat %d: leverage=%f, delta=%f", i, lev[i], delta[i])
+		}
+	}
+
+	// GDOP increases by removing any satellite
+	delta, err := GDOPDelta(site, satDatas)
+	if err != nil {
+		t.Fatalf("GDOPDelta: %v", err)
+	}
+	for i, d := range delta {
+		if !(d > 0) {
+			t.Errorf("sat %d: delta=%f", i, d)
+		}
+	}
+
+	// diagnostics of Solve
+	sol, err := Solve(satDatas, CalcPosOpts{LeaveOneOutDOP: true})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if len(sol.Leverage) != n || len(sol.GDOPDelta) != n {
+		t.Errorf("leverage=%v, delta=%v", sol.Leverage, sol.GDOPDelta)
+	}
+	if sol, _ := Solve(satDatas, CalcPosOpts{}); sol.GDOPDelta != nil {
+		t.Errorf("delta computed without the option: %v", sol.GDOPDelta)
+	}
+}
@@ -0,0 +1,99 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+)
+
+// SatPosFunc returns the satellite position (m) in the ECEF frame at the
+// transmission time of the i-th satellite of the input, where tau (s) is the
+// signal travel time, i.e., the transmission time is tau before the
+// reception time.
+type SatPosFunc func(i int, tau float64) (x, y, z float64)
+
+// IterOpts defines options for CalcPosIterated.
+type IterOpts struct {
+	// Tol is the convergence threshold (m) of the position change.
+	// 1e-4 m is used if zero.
+	Tol float64
+
+	// MaxIter is the maximum number of iterations. 10 is used if zero.
+	MaxIter int
+
+	// ElevationMask is the elevation cutoff angle (deg). If positive, the
+	// satellites below the mask seen from the latest solution are excluded
+	// after the first fix (see FilterByElevation).
+	ElevationMask float64
+
+	// CalcPosOpts is passed to CalcPosWithOpts at each iteration.
+	CalcPosOpts CalcPosOpts
+}
+
+// CalcPosIterated solves the GNSS equation using Bancroft method iteratively,
+// updating the signal travel times and the satellite positions with the
+// Earth rotation.
+//
+// If satPos is nil, X, Y, Z of satDatas are used as the satellite positions
+// at the transmission time, and only the Earth rotation is updated.
+// Otherwise satPos is called to re-evaluate the satellite positions at the
+// transmission times obtained from the latest solution.
+//
+// The returned tau holds the geometric signal travel time (s) for each
+// satellite at the converged solution, including the satellites excluded by
+// the elevation mask.
+func CalcPosIterated(satDatas []SatData, satPos SatPosFunc, opts IterOpts) (x, y, z, dt float64, tau []float64, err error) {
+	tol := opts.Tol
+	if tol == 0 {
+		tol = 1e-4
+	}
+	maxIter := opts.MaxIter
+	if maxIter == 0 {
+		maxIter = 10
+	}
+
+	// initial travel times
+	tau = make([]float64, len(satDatas))
+	for i, s := range satDatas {
+		tau[i] = s.PR / LightVelocity
+	}
+
+	corrected := make([]SatData, len(satDatas))
+	used := corrected
+	for iter := 0; iter < maxIter; iter++ {
+		for i, s := range satDatas {
+			sx, sy, sz := s.X, s.Y, s.Z
+			if satPos != nil {
+				sx, sy, sz = satPos(i, tau[i])
+			}
+			corrected[i] = s
+			corrected[i].X, corrected[i].Y, corrected[i].Z = RotateSatPos(sx, sy, sz, tau[i])
+		}
+
+		// re-apply the elevation mask with the latest solution
+		if opts.ElevationMask > 0 && iter > 0 {
+			var e error
+			used, _, e = FilterByElevation(corrected, [3]float64{x, y, z}, opts.ElevationMask)
+			if e != nil {
+				return 0., 0., 0., 0., nil, e
+			}
+		}
+
+		x1, y1, z1, dt1, e := CalcPosWithOpts(used, opts.CalcPosOpts)
+		if e != nil {
+			return 0., 0., 0., 0., nil, e
+		}
+
+		// update travel times with the geometric ranges
+		for i, s := range corrected {
+			tau[i] = math.Sqrt(sqr(s.X-x1)+sqr(s.Y-y1)+sqr(s.Z-z1)) / LightVelocity
+		}
+
+		dpos := math.Sqrt(sqr(x1-x) + sqr(y1-y) + sqr(z1-z))
+		x, y, z, dt = x1, y1, z1, dt1
+		if iter > 0 && dpos < tol {
+			return x, y, z, dt, tau, nil
+		}
+	}
+
+	return x, y, z, dt, tau, fmt.Errorf("not converged: maxIter=%d", maxIter)
+}
@@ -0,0 +1,100 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// iterScenario simulates the pseudoranges of the satellites moving at
+// 3.9 km/s for the receiver at rcv with the clock bias dt. The light-time
+// equation
+//
+//	|RotateSatPos(pos_i(-tau), tau) - rcv| = c*tau
+//
+// is solved for the exact travel times, and pos_i(-tau) is the position in
+// the ECEF frame at the transmission time tau before the reception.
+type iterScenario struct {
+	p0, v [][3]float64
+}
+
+func newIterScenario() iterScenario {
+	var sc iterScenario
+	for _, p := range testSatPositions() {
+		// horizontal velocity perpendicular to the Z axis
+		r := math.Hypot(p[0], p[1])
+		sc.p0 = append(sc.p0, p)
+		sc.v = append(sc.v, [3]float64{-3900. * p[1] / r, 3900. * p[0] / r, 0.})
+	}
+	return sc
+}
+
+// pos returns the position of the i-th satellite at tau before the reception.
+func (sc iterScenario) pos(i int, tau float64) (x, y, z float64) {
+	return sc.p0[i][0] - sc.v[i][0]*tau, sc.p0[i][1] - sc.v[i][1]*tau, sc.p0[i][2] - sc.v[i][2]*tau
+}
+
+// satDatas returns the pseudoranges and the exact travel times. The positions
+// of SatData are those at the transmission times if moving, or p0 otherwise.
+func (sc iterScenario) satDatas(rcv [3]float64, dt float64, moving bool) ([]SatData, []float64) {
+	satDatas := make([]SatData, len(sc.p0))
+	taus := make([]float64, len(sc.p0))
+	for i := range sc.p0 {
+		var tau float64
+		var x, y, z float64
+		for range 20 {
+			x, y, z = sc.p0[i][0], sc.p0[i][1], sc.p0[i][2]
+			if moving {
+				x, y, z = sc.pos(i, tau)
+			}
+			xr, yr, zr := RotateSatPos(x, y, z, tau)
+			tau = math.Sqrt(sqr(xr-rcv[0])+sqr(yr-rcv[1])+sqr(zr-rcv[2])) / LightVelocity
+		}
+		satDatas[i] = SatData{X: x, Y: y, Z: z, PR: tau*LightVelocity - dt*LightVelocity}
+		taus[i] = tau
+	}
+	return satDatas, taus
+}
+
+// TestCalcPosIterated checks the convergence to the simulated receiver within
+// 1 um, and the travel times of the light-time equation.
+func TestCalcPosIterated(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	const dt = 2e-4
+	sc := newIterScenario()
+
+	for _, moving := range []bool{false, true} {
+		satDatas, want := sc.satDatas(site, dt, moving)
+		var satPos SatPosFunc
+		if moving {
+			satPos = sc.pos
+		}
+
+		x, y, z, gdt, tau, err := CalcPosIterated(satDatas, satPos, IterOpts{})
+		if err != nil {
+			t.Fatalf("moving=%v: CalcPosIterated: %v", moving, err)
+		}
+
+		if d := math.Sqrt(sqr(x-site[0]) + sqr(y-site[1]) + sqr(z-site[2])); d > 1e-6 || math.Abs(gdt-dt)*LightVelocity > 1e-6 {
+			t.Errorf("moving=%v: position error=%e m, clock error=%e m", moving, d, (gdt-dt)*LightVelocity)
+		}
+		for i := range tau {
+			if math.Abs(tau[i]-want[i])*LightVelocity > 1e-6 {
+				t.Errorf("moving=%v, i=%d: get tau=%.15f, want %.15f", moving, i, tau[i], want[i])
+			}
+		}
+	}
+
+	// the motion of the satellites of ~0.3 km during the travel times
+	// ignored: the position is off by tens of meters
+	satDatas, _ := sc.satDatas(site, dt, true)
+	for i := range satDatas {
+		satDatas[i].X, satDatas[i].Y, satDatas[i].Z = sc.p0[i][0], sc.p0[i][1], sc.p0[i][2]
+	}
+	x, y, z, _, _, err := CalcPosIterated(satDatas, nil, IterOpts{})
+	if err != nil {
+		t.Fatalf("CalcPosIterated: %v", err)
+	}
+	if d := math.Sqrt(sqr(x-site[0]) + sqr(y-site[1]) + sqr(z-site[2])); d < 10. {
+		t.Errorf("motion not affected: d=%e", d)
+	}
+}
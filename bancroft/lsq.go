@@ -0,0 +1,182 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss/coord"
+	"gonum.org/v1/gonum/mat"
+)
+
+// lsqProblem defines the linearized pseudorange equations solved by the
+// Gauss-Newton iteration.
+//
+// The state vector is (x, y, z, b_0, ..., b_{nclk-1}) in meters, where b_k is
+// the clock term (-c*dt, see SatData) of the k-th clock, and the pseudorange
+// of the i-th satellite is modeled as
+//
+//	PR_i = |sat_i - rcv| + b_{clk[i]}
+//
+// If mapping is given, the zenith tropospheric delay ztd (m) is appended to
+// the state, and m(el_i)*ztd is added to the model for the elevation angle
+// el_i of the satellite seen from the receiver.
+type lsqProblem struct {
+	satDatas []SatData
+	clk      []int     // index of the clock term for each satellite
+	nclk     int       // number of the clock terms
+	weights  []float64 // observation weights; all 1 if nil
+
+	// optional pseudo-observation of the ellipsoidal height
+	height *heightObs
+
+	// optional tropospheric mapping function of the elevation (rad)
+	mapping func(el float64) float64
+}
+
+// heightObs is the pseudo-observation of the ellipsoidal height h (m) with
+// the weight w.
+type heightObs struct {
+	h, w float64
+}
+
+// solve iterates from the initial state x0 until the correction is below tol
+// (m), and returns the state and the Cholesky factorization of the normal
+// matrix A'WA at the final iteration, from which the cofactor matrix is
+// obtained by cofactor.
+//
+// If the normal matrix is not positive definite or too ill-conditioned,
+// ErrSingularGeometry is returned with the rank of sqrt(W)A.
+func (p lsqProblem) solve(x0 []float64, maxIter int, tol float64) (state []float64, chol *mat.Cholesky, err error) {
+	nsat := len(p.satDatas)
+	n := nsat
+	if p.height != nil {
+		n++
+	}
+	m := 3 + p.nclk
+	if p.mapping != nil {
+		m++
+	}
+	if n < m {
+		return nil, nil, fmt.Errorf("%w: n=%d, unknowns=%d", ErrTooFewSats, nsat, m)
+	}
+
+	state = append([]float64{}, x0...)
+	A := mat.NewDense(n, m, nil)
+	W := mat.NewDiagDense(n, nil)
+	dy := mat.NewVecDense(n, nil)
+
+	for i := range nsat {
+		w := 1.
+		if p.weights != nil {
+			w = p.weights[i]
+		}
+		W.SetDiag(i, w)
+	}
+	if p.height != nil {
+		W.SetDiag(nsat, p.height.w)
+	}
+
+	for range maxIter {
+		for i, s := range p.satDatas {
+			h, rho := designRow([3]float64{state[0], state[1], state[2]}, s)
+
+			for j := range m {
+				A.Set(i, j, 0.)
+			}
+			A.Set(i, 0, h[0])
+			A.Set(i, 1, h[1])
+			A.Set(i, 2, h[2])
+			A.Set(i, 3+p.clk[i], 1.)
+
+			model := rho + state[3+p.clk[i]]
+			if p.mapping != nil {
+				_, el := coord.AzEl([3]float64{state[0], state[1], state[2]}, [3]float64{s.X, s.Y, s.Z})
+				mf := p.mapping(el)
+				A.Set(i, m-1, mf)
+				model += mf * state[m-1]
+			}
+			dy.SetVec(i, s.PR-model)
+		}
+
+		if p.height != nil {
+			// partials of the height are the ellipsoid normal
+			lat, lon, h := coord.XYZToLLH(state[0], state[1], state[2])
+			up := coord.ENURotation(lat, lon)[2]
+			for j := range m {
+				A.Set(nsat, j, 0.)
+			}
+			A.Set(nsat, 0, up[0])
+			A.Set(nsat, 1, up[1])
+			A.Set(nsat, 2, up[2])
+			dy.SetVec(nsat, p.height.h-h)
+		}
+
+		// normal equation: (A'WA) dx = A'W dy
+		var AtW mat.Dense
+		var b, dx mat.VecDense
+		AtW.Mul(A.T(), W)
+		b.MulVec(&AtW, dy)
+
+		N := mat.NewSymDense(m, nil)
+		for i := range m {
+			for j := i; j < m; j++ {
+				N.SetSym(i, j, mat.Dot(AtW.RowView(i), A.ColView(j)))
+			}
+		}
+
+		chol = new(mat.Cholesky)
+		if ok := chol.Factorize(N); !ok {
+			return nil, nil, fmt.Errorf("%w: normal matrix not positive definite: %s", ErrSingularGeometry, rankInfo(A, W))
+		}
+		if c := chol.Cond(); c >= maxCond {
+			return nil, nil, fmt.Errorf("%w: normal matrix cond=%e: %s", ErrSingularGeometry, c, rankInfo(A, W))
+		}
+		if err = chol.SolveVecTo(&dx, &b); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+		}
+
+		var norm float64
+		for j := range m {
+			state[j] += dx.AtVec(j)
+			norm += sqr(dx.AtVec(j))
+		}
+
+		if math.Sqrt(norm) < tol {
+			return state, chol, nil
+		}
+	}
+
+	return state, chol, fmt.Errorf("not converged: maxIter=%d", maxIter)
+}
+
+// cofactor returns the cofactor matrix (A'WA)^-1 from the factorization
+// returned by solve.
+func cofactor(chol *mat.Cholesky) (*mat.SymDense, error) {
+	var Q mat.SymDense
+	if err := chol.InverseTo(&Q); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+	}
+	return &Q, nil
+}
+
+// rankInfo returns the numerical rank and the condition number of sqrt(W)A
+// by the singular value decomposition, to report the rank deficiency.
+func rankInfo(A *mat.Dense, W *mat.DiagDense) string {
+	var B mat.Dense
+	B.Apply(func(i, j int, v float64) float64 {
+		return math.Sqrt(W.At(i, i)) * v
+	}, A)
+
+	var svd mat.SVD
+	if !svd.Factorize(&B, mat.SVDNone) {
+		return "svd failed"
+	}
+	sv := svd.Values(nil)
+	var rank int
+	for _, v := range sv {
+		if v > sv[0]*1e-12 {
+			rank++
+		}
+	}
+	return fmt.Sprintf("rank=%d/%d, cond=%e", rank, len(sv), svd.Cond())
+}
@@ -0,0 +1,65 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestLSQCholesky checks that the cofactor matrix from the Cholesky
+// factorization agrees with the inverse of the normal matrix.
+func TestLSQCholesky(t *testing.T) {
+	satDatas := testSatDatas()
+	x, y, z, dt, _ := CalcPos(satDatas)
+
+	p := lsqProblem{satDatas: satDatas, clk: make([]int, len(satDatas)), nclk: 1}
+	state, chol, err := p.solve([]float64{x, y, z, -dt * LightVelocity}, 10, 1e-4)
+	if err != nil {
+		t.Fatalf("solve: %v", err)
+	}
+	Q, err := cofactor(chol)
+	if err != nil {
+		t.Fatalf("cofactor: %v", err)
+	}
+
+	// normal matrix at the solution by the general inverse
+	A := mat.NewDense(len(satDatas), 4, nil)
+	for i, s := range satDatas {
+		e := lineOfSight([3]float64{state[0], state[1], state[2]}, s.X, s.Y, s.Z)
+		A.SetRow(i, []float64{-e[0], -e[1], -e[2], 1.})
+	}
+	var N, Qinv mat.Dense
+	N.Mul(A.T(), A)
+	if err := Qinv.Inverse(&N); err != nil {
+		t.Fatalf("Inverse: %v", err)
+	}
+	for i := range 4 {
+		for j := range 4 {
+			if d := Q.At(i, j) - Qinv.At(i, j); math.Abs(d) > 1e-9*math.Abs(Qinv.At(i, i)) {
+				t.Errorf("Q[%d][%d]: get %e, want %e", i, j, Q.At(i, j), Qinv.At(i, j))
+			}
+		}
+	}
+}
+
+// TestLSQSingular checks that the rank deficient geometry is reported.
+func TestLSQSingular(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	// all satellites at the same elevation: the up component and the clock
+	// are not separable
+	sats := make([][3]float64, 6)
+	for i := range sats {
+		az, el := float64(i)*math.Pi/3., math.Pi/6.
+		sats[i] = satOnSky(site, siteRotation(site), az, el, 26560e3)
+	}
+	satDatas := ComputePseudoranges(site, 0, sats, nil)
+
+	p := lsqProblem{satDatas: satDatas, clk: make([]int, len(satDatas)), nclk: 1}
+	_, _, err := p.solve([]float64{site[0] + 10., site[1], site[2], 0.}, 10, 1e-4)
+	if !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+}
@@ -0,0 +1,64 @@
+package bancroft
+
+import (
+	"fmt"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// RcvAntenna provides the phase center offset of the receiver antennas,
+// e.g., antex.Collection.
+type RcvAntenna interface {
+	// RcvPCO returns the phase center offset (m) from the antenna reference
+	// point in the order of north, east, up.
+	RcvPCO(antType string, sys byte, freq int) ([3]float64, error)
+}
+
+// MarkerReduction stores the marker position reduced from the solution and
+// the applied offsets in the local east, north, up frame at the solution.
+type MarkerReduction struct {
+	// Marker is the marker position (ECEF, m).
+	Marker [3]float64
+
+	// PCO is the phase center offset from the antenna reference point (ARP),
+	// and Ecc is the ARP offset from the marker (RINEX "ANTENNA: DELTA H/E/N").
+	// Offset = PCO + Ecc is the total offset of the solution from the marker.
+	// All are east, north, up (m).
+	PCO, Ecc, Offset [3]float64
+}
+
+// ReduceToMarker reduces the solution at the antenna phase center to the
+// marker, by subtracting the PCO of the antenna type antType for the
+// frequency (e.g., 'G' and 1 for "G01") and the eccentricity hen given in
+// the order of RINEX "ANTENNA: DELTA H/E/N" (m), i.e., the antenna height
+// of the ARP above the marker and the east, north eccentricities.
+//
+// The PCO is not applied if ant is nil, e.g., when the solution refers to the
+// ARP.
+func ReduceToMarker(sol Solution, ant RcvAntenna, antType string, sys byte, freq int, hen [3]float64) (red MarkerReduction, err error) {
+	if sol.Err != nil {
+		return red, fmt.Errorf("invalid solution: %w", sol.Err)
+	}
+
+	if ant != nil {
+		neu, err := ant.RcvPCO(antType, sys, freq)
+		if err != nil {
+			return red, err
+		}
+		red.PCO = [3]float64{neu[1], neu[0], neu[2]}
+	}
+
+	// H/E/N -> E/N/U; both the height and the up component are positive
+	// upward, i.e., the ARP is above the marker for positive H.
+	red.Ecc = [3]float64{hen[1], hen[2], hen[0]}
+
+	for k := range 3 {
+		red.Offset[k] = red.PCO[k] + red.Ecc[k]
+	}
+
+	// marker = APC - offset
+	neg := [3]float64{-red.Offset[0], -red.Offset[1], -red.Offset[2]}
+	red.Marker = coord.ENUToECEF(sol.Position, neg)
+
+	return red, nil
+}
@@ -0,0 +1,57 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// testRcvAntenna is a RcvAntenna returning the fixed PCO (north, east, up).
+type testRcvAntenna [3]float64
+
+func (a testRcvAntenna) RcvPCO(antType string, sys byte, freq int) ([3]float64, error) {
+	return a, nil
+}
+
+// TestReduceToMarker checks the marker is below the phase center by the
+// antenna height and the up component of the PCO.
+func TestReduceToMarker(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sol := Solution{Position: site}
+
+	ant := testRcvAntenna{0.001, -0.0004, 0.066}
+	hen := [3]float64{1.5, 0.2, -0.1}
+
+	red, err := ReduceToMarker(sol, ant, "TEST", 'G', 1, hen)
+	if err != nil {
+		t.Fatalf("ReduceToMarker: %v", err)
+	}
+
+	want := [3]float64{0.2 - 0.0004, -0.1 + 0.001, 1.5 + 0.066}
+	for k := range 3 {
+		if math.Abs(red.Offset[k]-want[k]) > 1e-12 {
+			t.Errorf("offset[%d]: get %f, want %f", k, red.Offset[k], want[k])
+		}
+	}
+
+	// the solution seen from the marker is the offset
+	enu := coord.ECEFToENU(red.Marker, site)
+	for k := range 3 {
+		if math.Abs(enu[k]-want[k]) > 1e-6 {
+			t.Errorf("enu[%d]: get %f, want %f", k, enu[k], want[k])
+		}
+	}
+
+	_, _, h0 := sol.LatLonHeight()
+	_, _, h1 := coord.XYZToLLH(red.Marker[0], red.Marker[1], red.Marker[2])
+	if math.Abs(h0-h1-1.566) > 1e-6 {
+		t.Errorf("height difference: get %f, want 1.566", h0-h1)
+	}
+
+	// ARP solution without the PCO
+	red, _ = ReduceToMarker(sol, nil, "", 0, 0, hen)
+	if red.PCO != [3]float64{} || red.Offset != [3]float64{0.2, -0.1, 1.5} {
+		t.Errorf("without PCO: %+v", red)
+	}
+}
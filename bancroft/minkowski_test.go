@@ -0,0 +1,69 @@
+package bancroft
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestMinkowski4D checks the properties of the Minkowski inner product:
+// symmetry, bilinearity, and the signature (+, +, +, -).
+func TestMinkowski4D(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randVec := func() *mat.VecDense {
+		v := mat.NewVecDense(4, nil)
+		for i := range 4 {
+			v.SetVec(i, 2.*rng.Float64()-1.)
+		}
+		return v
+	}
+	mink := func(a, b mat.Vector) float64 {
+		v, err := Minkowski4D(a, b)
+		if err != nil {
+			t.Fatalf("Minkowski4D: %v", err)
+		}
+		return v
+	}
+
+	for range 100 {
+		a, b, c := randVec(), randVec(), randVec()
+		alpha, beta := rng.NormFloat64(), rng.NormFloat64()
+
+		// symmetry
+		if mink(a, b) != mink(b, a) {
+			t.Errorf("not symmetric: a=%v, b=%v", a.RawVector().Data, b.RawVector().Data)
+		}
+
+		// bilinearity: <alpha*a + beta*b, c> = alpha*<a,c> + beta*<b,c>
+		var ab mat.VecDense
+		ab.AddScaledVec(a, beta/alpha, b)
+		ab.ScaleVec(alpha, &ab)
+		if lhs, rhs := mink(&ab, c), alpha*mink(a, c)+beta*mink(b, c); math.Abs(lhs-rhs) > 1e-12 {
+			t.Errorf("not bilinear: lhs=%e, rhs=%e", lhs, rhs)
+		}
+	}
+
+	// signature
+	for i, want := range []float64{1, 1, 1, -1} {
+		e := mat.NewVecDense(4, nil)
+		e.SetVec(i, 1.)
+		if got := mink(e, e); got != want {
+			t.Errorf("signature: i=%d, get %f, want %f", i, got, want)
+		}
+	}
+
+	// views and other vector types
+	m := mat.NewDense(4, 2, []float64{1, 10, 2, 20, 3, 30, 4, 40})
+	col := m.ColView(1) // non-unit increment
+	v := mat.NewVecDense(4, []float64{1, 1, 1, 1})
+	if got := mink(col, v); got != 10+20+30-40 {
+		t.Errorf("column view: get %f, want %f", got, 20.)
+	}
+
+	// invalid length
+	if _, err := Minkowski4D(mat.NewVecDense(3, nil), v); err == nil {
+		t.Errorf("no error for the invalid length")
+	}
+}
@@ -0,0 +1,144 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// MonteCarloOpts defines options for MonteCarlo.
+type MonteCarloOpts struct {
+	// N is the number of the runs. 1000 is used if zero.
+	N int
+
+	// Sigma is the standard deviation (m) of the Gaussian noise of the
+	// pseudoranges, used if Noise is nil.
+	Sigma float64
+
+	// Noise returns the noise (m) of the pseudorange of the i-th satellite,
+	// e.g., to model the elevation dependent noise or the biases.
+	Noise func(i int, rng *rand.Rand) float64
+
+	// Rand is the random number generator. A PCG seeded by (1, 1) is used
+	// if nil.
+	Rand *rand.Rand
+
+	// KeepSamples stores the errors of the runs in MonteCarloResult.Samples.
+	KeepSamples bool
+}
+
+// MonteCarloResult stores the statistics of the position errors of
+// MonteCarlo in the local east, north, up frame at the receiver.
+type MonteCarloResult struct {
+	// NumRuns is the number of the runs, and NumFailed is the number of the
+	// runs in which the solver failed. The statistics are of the others.
+	NumRuns, NumFailed int
+
+	// MeanENU and SigmaENU are the mean and the standard deviation (m) of
+	// the errors.
+	MeanENU, SigmaENU [3]float64
+
+	// P95ENU is the 95th percentile (m) of the absolute errors of the
+	// components, and P95H and P95P are of the horizontal and the 3D errors.
+	P95ENU     [3]float64
+	P95H, P95P float64
+
+	// Samples stores the errors (ENU, m) if MonteCarloOpts.KeepSamples.
+	Samples [][3]float64
+}
+
+// MonteCarlo predicts the accuracy of CalcPos for the geometry of the
+// satellites sats (ECEF, m) seen from the receiver rcv (ECEF, m) with the
+// clock bias dt (s), by solving the pseudoranges of ComputePseudoranges
+// perturbed by the noise for N times.
+func MonteCarlo(rcv [3]float64, dt float64, sats [][3]float64, opts MonteCarloOpts) (res MonteCarloResult, err error) {
+	n := opts.N
+	if n == 0 {
+		n = 1000
+	}
+	rng := opts.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(1, 1))
+	}
+	noise := opts.Noise
+	if noise == nil {
+		if !(opts.Sigma >= 0) {
+			return res, fmt.Errorf("%w: invalid sigma: %v", ErrBadInput, opts.Sigma)
+		}
+		noise = func(int, *rand.Rand) float64 { return opts.Sigma * rng.NormFloat64() }
+	}
+
+	res.NumRuns = n
+	errs := make([][3]float64, 0, n)
+	extra := make([]float64, len(sats))
+	for range n {
+		for i := range extra {
+			extra[i] = noise(i, rng)
+		}
+		x, y, z, _, e := CalcPos(ComputePseudoranges(rcv, dt, sats, extra))
+		if e != nil {
+			res.NumFailed++
+			continue
+		}
+		errs = append(errs, coord.ECEFToENU(rcv, [3]float64{x, y, z}))
+	}
+
+	m := len(errs)
+	if m == 0 {
+		return res, fmt.Errorf("all runs failed: n=%d", n)
+	}
+
+	for _, e := range errs {
+		for k := range 3 {
+			res.MeanENU[k] += e[k] / float64(m)
+		}
+	}
+	if m > 1 {
+		for _, e := range errs {
+			for k := range 3 {
+				res.SigmaENU[k] += sqr(e[k] - res.MeanENU[k])
+			}
+		}
+		for k := range 3 {
+			res.SigmaENU[k] = math.Sqrt(res.SigmaENU[k] / float64(m-1))
+		}
+	}
+
+	v := make([]float64, m)
+	for k := range 3 {
+		for j, e := range errs {
+			v[j] = math.Abs(e[k])
+		}
+		res.P95ENU[k] = percentile(v, 0.95)
+	}
+	for j, e := range errs {
+		v[j] = math.Hypot(e[0], e[1])
+	}
+	res.P95H = percentile(v, 0.95)
+	for j, e := range errs {
+		v[j] = math.Sqrt(sqr(e[0]) + sqr(e[1]) + sqr(e[2]))
+	}
+	res.P95P = percentile(v, 0.95)
+
+	if opts.KeepSamples {
+		res.Samples = errs
+	}
+
+	return res, nil
+}
+
+// percentile returns the p-th quantile (0-1) of v by the linear
+// interpolation of the sorted values. v is sorted in place.
+func percentile(v []float64, p float64) float64 {
+	slices.Sort(v)
+	x := p * float64(len(v)-1)
+	i := int(x)
+	if i >= len(v)-1 {
+		return v[len(v)-1]
+	}
+	a := x - float64(i)
+	return (1-a)*v[i] + a*v[i+1]
+}
@@ -0,0 +1,47 @@
+package bancroft
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// TestMonteCarlo checks that the empirical errors are calibrated with the
+// DOP values, and the same seed gives the same result.
+func TestMonteCarlo(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	sats := make([][3]float64, len(sc.SatDatas))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+	}
+
+	sigma := 2.
+	opts := MonteCarloOpts{N: 4000, Sigma: sigma, Rand: rand.New(rand.NewPCG(5, 5)), KeepSamples: true}
+	res, err := MonteCarlo(site, 1e-4, sats, opts)
+	if err != nil {
+		t.Fatalf("MonteCarlo: %v", err)
+	}
+	if res.NumFailed != 0 || len(res.Samples) != 4000 {
+		t.Errorf("failed=%d, samples=%d", res.NumFailed, len(res.Samples))
+	}
+
+	dop, _ := CalcDOP(site, sc.SatDatas)
+	h := math.Hypot(res.SigmaENU[0], res.SigmaENU[1])
+	if math.Abs(h/(sigma*dop.HDOP)-1.) > 0.05 || math.Abs(res.SigmaENU[2]/(sigma*dop.VDOP)-1.) > 0.05 {
+		t.Errorf("not calibrated: sigma=%v, HDOP=%f, VDOP=%f", res.SigmaENU, dop.HDOP, dop.VDOP)
+	}
+
+	// 95th percentile of the Gaussian is about 1.96 sigma
+	if r := res.P95ENU[2] / res.SigmaENU[2]; math.Abs(r-1.96) > 0.1 {
+		t.Errorf("P95/sigma (up): %f", r)
+	}
+
+	// reproducible
+	opts.Rand = rand.New(rand.NewPCG(5, 5))
+	opts.KeepSamples = false
+	res2, _ := MonteCarlo(site, 1e-4, sats, opts)
+	if res2.SigmaENU != res.SigmaENU || res2.Samples != nil {
+		t.Errorf("not reproducible: %v, %v", res.SigmaENU, res2.SigmaENU)
+	}
+}
@@ -0,0 +1,84 @@
+package bancroft
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// MultiSysResult stores the result of CalcPosMultiSys.
+type MultiSysResult struct {
+	// position (m) and the receiver clock bias (s) of the reference system
+	X, Y, Z, Dt float64
+
+	// RefSys is the reference satellite system
+	RefSys gnss.SatelliteSystem
+
+	// ISB stores the inter-system biases (s) of the other satellite systems
+	// relative to the reference system, i.e., the pseudoranges of the system
+	// are longer by c*ISB than those of the reference system
+	ISB map[gnss.SatelliteSystem]float64
+}
+
+// CalcPosMultiSys solves the position with a receiver clock bias for each
+// satellite system given by SatData.ID.Sys. An error is returned if the ID of
+// any satellite is zero.
+//
+// The receiver clock of refSys is estimated as Dt, and those of the other
+// systems are estimated as the inter-system biases relative to it. At least
+// 4+k satellites are required for k systems other than refSys.
+//
+// The solution of CalcPos with all satellites is used as the initial value of
+// the least squares iteration.
+func CalcPosMultiSys(satDatas []SatData, refSys gnss.SatelliteSystem) (res MultiSysResult, err error) {
+	// satellite systems: refSys first
+	systems := []gnss.SatelliteSystem{refSys}
+	for i, s := range satDatas {
+		if s.ID == (gnss.SatID{}) {
+			return res, fmt.Errorf("no satellite ID: %s", s.Label(i))
+		}
+		if !slices.Contains(systems, s.ID.Sys) {
+			systems = append(systems, s.ID.Sys)
+		}
+	}
+	if !slices.ContainsFunc(satDatas, func(s SatData) bool { return s.ID.Sys == refSys }) {
+		return res, fmt.Errorf("no satellite of the reference system: sys='%c'", refSys)
+	}
+
+	if n, m := len(satDatas), 3+len(systems); n < m {
+		return res, fmt.Errorf("%w: n=%d, required=%d", ErrTooFewSats, n, m)
+	}
+
+	clk := make([]int, len(satDatas))
+	for i, s := range satDatas {
+		clk[i] = slices.Index(systems, s.ID.Sys)
+	}
+
+	// initial solution
+	x, y, z, dt, err := CalcPos(satDatas)
+	if err != nil {
+		return res, err
+	}
+	x0 := make([]float64, 3+len(systems))
+	x0[0], x0[1], x0[2] = x, y, z
+	for k := range systems {
+		x0[3+k] = -dt * LightVelocity
+	}
+
+	p := lsqProblem{satDatas: satDatas, clk: clk, nclk: len(systems)}
+	state, _, err := p.solve(x0, 10, 1e-4)
+	if err != nil {
+		return res, err
+	}
+
+	res.X, res.Y, res.Z = state[0], state[1], state[2]
+	res.Dt = -state[3] / LightVelocity
+	res.RefSys = refSys
+	res.ISB = make(map[gnss.SatelliteSystem]float64)
+	for k, sys := range systems[1:] {
+		res.ISB[sys] = (state[4+k] - state[3]) / LightVelocity
+	}
+
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// TestCalcPosMultiSys checks the inter-system bias is recovered from the
+// simulated pseudoranges.
+func TestCalcPosMultiSys(t *testing.T) {
+	pos := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	const (
+		dt  = 1e-4  // receiver clock (s)
+		isb = 30e-9 // inter-system bias (s)
+	)
+
+	satDatas := ComputePseudoranges(pos, dt, testSatPositions(), nil)
+	for i := range satDatas {
+		s := &satDatas[i]
+		s.ID = gnss.SatID{Sys: gnss.GPS, PRN: i + 1}
+		if i >= 5 {
+			s.ID.Sys = gnss.Galileo
+			s.PR += isb * LightVelocity
+		}
+	}
+
+	res, err := CalcPosMultiSys(satDatas, gnss.GPS)
+	if err != nil {
+		t.Fatalf("CalcPosMultiSys: %v", err)
+	}
+
+	d := math.Sqrt(sqr(res.X-pos[0]) + sqr(res.Y-pos[1]) + sqr(res.Z-pos[2]))
+	if d > 1e-4 || math.Abs(res.Dt-dt) > 1e-12 || math.Abs(res.ISB[gnss.Galileo]-isb) > 1e-12 {
+		t.Errorf("get pos error=%e m, dt=%e, isb=%e, want dt=%e, isb=%e", d, res.Dt, res.ISB[gnss.Galileo], dt, isb)
+	}
+
+	// not enough satellite
+	if _, err := CalcPosMultiSys(satDatas[2:6], gnss.GPS); err == nil {
+		t.Errorf("no error for too few satellites")
+	}
+
+	// no satellite ID
+	satDatas[3].ID = gnss.SatID{}
+	if _, err := CalcPosMultiSys(satDatas, gnss.GPS); err == nil {
+		t.Errorf("no error for the zero ID")
+	}
+}
@@ -0,0 +1,220 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// DefaultEarthRadius is the reference radius (m) used for the root selection
+// when CalcPosOpts.EarthRadius is not given.
+const DefaultEarthRadius = 6378000.
+
+// DefaultHorizonMask is the elevation angle (deg) below which the satellites
+// are excluded by CalcPosOpts.ExcludeBelowHorizon when
+// CalcPosOpts.HorizonMask is not given.
+const DefaultHorizonMask = -5.
+
+// RootSelection defines how one of the two candidate solutions of the
+// Bancroft equation is adopted.
+type RootSelection int
+
+const (
+	// SelectNearSurface adopts the candidate closer to the reference sphere
+	// of radius CalcPosOpts.EarthRadius. This is the default.
+	SelectNearSurface RootSelection = iota
+
+	// SelectMinResidual adopts the candidate with the smaller RMS of the
+	// pseudorange residuals.
+	SelectMinResidual
+)
+
+// CalcPosOpts defines options for CalcPosWithOpts.
+// The zero value gives the same result as CalcPos.
+type CalcPosOpts struct {
+	// EarthRadius is the reference radius (m) for SelectNearSurface.
+	// DefaultEarthRadius is used if zero.
+	EarthRadius float64
+
+	// RootSelection is the strategy to adopt one of the two candidates.
+	RootSelection RootSelection
+
+	// Validate enables the check of the duplicated satellites. Non-finite
+	// values are always rejected.
+	Validate bool
+
+	// Screen enables ScreenSatDatas with the options if not nil.
+	Screen *ScreenOpts
+
+	// SkipRangeCheck disables the check of the pseudoranges against
+	// [MinPseudorange, MaxPseudorange], e.g., for LEO or GEO satellites, or
+	// the pseudoranges with a large receiver clock bias.
+	SkipRangeCheck bool
+
+	// MaxResidual is the threshold (m) of the residual RMS above which the
+	// solution is rejected. The test is disabled if zero.
+	MaxResidual float64
+
+	// ExcludeBelowHorizon enables the check of the elevation angles of the
+	// satellites seen from the solution. The satellites below HorizonMask,
+	// e.g., by an ephemeris error or a mislabeled observation, are removed,
+	// and the position is solved once again without them. It should be
+	// disabled for the spaceborne receivers.
+	ExcludeBelowHorizon bool
+
+	// HorizonMask is the threshold (deg) of ExcludeBelowHorizon, which is
+	// usually negative to allow the errors of the geometry.
+	// DefaultHorizonMask is used if nil.
+	HorizonMask *float64
+
+	// Exclude is the set of the satellite IDs (SatData.ID.String(), e.g.,
+	// "G14") to be removed before solving, e.g., those of
+	// sp3.File.Exclusions. The removed
+	// satellites are listed in Solution.Excluded.
+	Exclude map[string]bool
+
+	// LeaveOneOutDOP enables Solution.GDOPDelta of Solve, which solves the
+	// DOP values for each satellite removed. It is ignored by
+	// CalcPosWithOpts.
+	LeaveOneOutDOP bool
+}
+
+// CalcPosWithOpts solves the GNSS equation using Bancroft method
+// (Bancroft, 1985) with the given options.
+//
+// See Solve for the solution with the diagnostics.
+func CalcPosWithOpts(satDatas []SatData, opts CalcPosOpts) (x, y, z, dt float64, err error) {
+	c, _, _, _, err := calcPosWithOpts(satDatas, opts)
+	if err != nil {
+		return 0., 0., 0., 0., err
+	}
+	return c.X, c.Y, c.Z, c.Dt, nil
+}
+
+// calcPosWithOpts returns the adopted candidate, its root index, the
+// satellites used after the screening, and the labels of the satellites
+// excluded by CalcPosOpts.Exclude or below the horizon.
+func calcPosWithOpts(satDatas []SatData, opts CalcPosOpts) (c Candidate, root int, used []SatData, excluded []string, err error) {
+	return calcPosWithOptsBy(satDatas, opts, CalcPosAll)
+}
+
+// calcPosWithOptsBy is calcPosWithOpts with the solver of the candidates.
+func calcPosWithOptsBy(satDatas []SatData, opts CalcPosOpts, calcPosAll func([]SatData) ([2]Candidate, error)) (c Candidate, root int, used []SatData, excluded []string, err error) {
+	if len(opts.Exclude) > 0 {
+		satDatas, excluded = excludeSatDatas(satDatas, opts.Exclude)
+	}
+
+	if opts.Validate {
+		if err = validateSatDatas(satDatas); err != nil {
+			return c, 0, nil, excluded, err
+		}
+	}
+
+	if err = checkSatDatas(satDatas, !opts.SkipRangeCheck); err != nil {
+		return c, 0, nil, excluded, err
+	}
+
+	if opts.Screen != nil {
+		if satDatas, _, err = ScreenSatDatas(satDatas, *opts.Screen); err != nil {
+			return c, 0, nil, excluded, err
+		}
+	}
+
+	cands, err := calcPosAll(satDatas)
+	if err != nil {
+		return c, 0, nil, excluded, err
+	}
+
+	root = selectRoot(cands, opts)
+	c = cands[root]
+
+	if opts.ExcludeBelowHorizon {
+		if kept, below := belowHorizon(satDatas, c, opts.HorizonMask); len(below) > 0 {
+			excluded = append(excluded, below...)
+			if cands, err = calcPosAll(kept); err != nil {
+				return c, 0, nil, excluded, fmt.Errorf("excluded below the horizon: %v: %w", below, err)
+			}
+			satDatas = kept
+			root = selectRoot(cands, opts)
+			c = cands[root]
+		}
+	}
+
+	if opts.MaxResidual > 0 && !(c.Residual <= opts.MaxResidual) {
+		return c, root, satDatas, excluded, fmt.Errorf("solution rejected: residual=%.3f, threshold=%.3f", c.Residual, opts.MaxResidual)
+	}
+
+	return c, root, satDatas, excluded, nil
+}
+
+// belowHorizon returns the satellites whose elevation angles seen from the
+// candidate are not lower than maskDeg (deg, DefaultHorizonMask if nil), and
+// the labels of the others.
+func belowHorizon(satDatas []SatData, c Candidate, maskDeg *float64) (kept []SatData, excluded []string) {
+	mask := coord.Deg2Rad(DefaultHorizonMask)
+	if maskDeg != nil {
+		mask = coord.Deg2Rad(*maskDeg)
+	}
+
+	pos := [3]float64{c.X, c.Y, c.Z}
+	kept = make([]SatData, 0, len(satDatas))
+	for i, s := range satDatas {
+		if _, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z}); el < mask {
+			excluded = append(excluded, s.Label(i))
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept, excluded
+}
+
+// excludeSatDatas returns the satellites whose IDs are not in the set, and
+// the IDs of the others.
+func excludeSatDatas(satDatas []SatData, exclude map[string]bool) (kept []SatData, excluded []string) {
+	kept = make([]SatData, 0, len(satDatas))
+	for _, s := range satDatas {
+		if !s.ID.IsZero() && exclude[s.ID.String()] {
+			excluded = append(excluded, s.ID.String())
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept, excluded
+}
+
+// selectRoot returns the index of the candidate to be adopted.
+func selectRoot(cands [2]Candidate, opts CalcPosOpts) int {
+	switch opts.RootSelection {
+	case SelectMinResidual:
+		if cands[1].Residual < cands[0].Residual {
+			return 1
+		}
+		return 0
+	default:
+		// the solution closer to the Earth's surface is adopted as the true solution.
+		R := opts.EarthRadius
+		if R == 0 {
+			R = DefaultEarthRadius
+		}
+		c1, c2 := cands[0], cands[1]
+		res1 := math.Abs(R - math.Sqrt(sqr(c1.X)+sqr(c1.Y)+sqr(c1.Z)))
+		res2 := math.Abs(R - math.Sqrt(sqr(c2.X)+sqr(c2.Y)+sqr(c2.Z)))
+		if res2 < res1 {
+			return 1
+		}
+		return 0
+	}
+}
+
+// validateSatDatas checks that no satellite is duplicated.
+func validateSatDatas(satDatas []SatData) error {
+	for i, s := range satDatas {
+		for j := 0; j < i; j++ {
+			if s.X == satDatas[j].X && s.Y == satDatas[j].Y && s.Z == satDatas[j].Z {
+				return fmt.Errorf("%w: duplicated satellite position: sat=%s, %s", ErrBadInput, satDatas[j].Label(j), s.Label(i))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCalcPosWithOpts checks the zero value options and the rejections.
+func TestCalcPosWithOpts(t *testing.T) {
+	satDatas := testSatDatas()
+
+	x0, y0, z0, dt0, _ := CalcPos(satDatas)
+
+	// zero value options reproduce CalcPos
+	x, y, z, dt, err := CalcPosWithOpts(satDatas, CalcPosOpts{})
+	if x != x0 || y != y0 || z != z0 || dt != dt0 || err != nil {
+		t.Errorf("zero value options: get (%f, %f, %f, %e, %v), want (%f, %f, %f, %e)", x, y, z, dt, err, x0, y0, z0, dt0)
+	}
+
+	// the residual test
+	if _, _, _, _, err := CalcPosWithOpts(satDatas, CalcPosOpts{MaxResidual: 1.}); err == nil {
+		t.Errorf("solution with large residual is not rejected")
+	}
+
+	// invalid inputs
+	bad := append([]SatData{}, satDatas...)
+	bad[0].PR = math.NaN()
+	if _, _, _, _, err := CalcPosWithOpts(bad, CalcPosOpts{Validate: true}); err == nil {
+		t.Errorf("NaN input is not rejected")
+	}
+
+	dup := append([]SatData{}, satDatas...)
+	dup = append(dup, satDatas[0])
+	if _, _, _, _, err := CalcPosWithOpts(dup, CalcPosOpts{Validate: true}); err == nil {
+		t.Errorf("duplicated input is not rejected")
+	}
+}
+
+// TestSatDataLabel checks the satellite ID is used in the error messages,
+// and the index is used for the empty ID.
+func TestSatDataLabel(t *testing.T) {
+	satDatas := testSatDatas()
+	satDatas[2].PR = math.NaN()
+	_, _, _, _, err := CalcPosWithOpts(satDatas, CalcPosOpts{Validate: true})
+	if err == nil || !strings.Contains(err.Error(), "G22") {
+		t.Errorf("satellite ID not found in the error: %v", err)
+	}
+
+	satDatas[2].ID = gnss.SatID{}
+	_, _, _, _, err = CalcPosWithOpts(satDatas, CalcPosOpts{Validate: true})
+	if err == nil || !strings.Contains(err.Error(), "#2") {
+		t.Errorf("satellite index not found in the error: %v", err)
+	}
+
+	for i, r := range LabeledResiduals(testSatDatas(), 0, 0, 0, 0) {
+		if r.ID != satIDs[i].String() {
+			t.Errorf("invalid label: get %s, want %s", r.ID, satIDs[i])
+		}
+	}
+}
+
+// TestExcludeBelowHorizon checks that a satellite below the horizon is
+// excluded and the position is solved again.
+func TestExcludeBelowHorizon(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	satDatas := sc.SatDatas
+	for i := range satDatas {
+		satDatas[i].ID = satIDs[i]
+	}
+
+	// a satellite at the elevation -20 deg with an ephemeris error of 300 m
+	el := coord.Deg2Rad(-20.)
+	pos := coord.ENUToECEF(site, [3]float64{0., 22000e3 * math.Cos(el), 22000e3 * math.Sin(el)})
+	bad := SatData{X: pos[0], Y: pos[1], Z: pos[2], PR: ComputePseudorange(site, 0., pos) + 300., ID: gnss.SatID{Sys: gnss.GPS, PRN: 99}}
+	satDatas = append(satDatas, bad)
+
+	sol, err := Solve(satDatas, CalcPosOpts{})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if d := math.Sqrt(sqr(sol.Position[0]-site[0]) + sqr(sol.Position[1]-site[1]) + sqr(sol.Position[2]-site[2])); d < 100. {
+		t.Fatalf("fix not affected: d=%f", d)
+	}
+
+	sol, err = Solve(satDatas, CalcPosOpts{ExcludeBelowHorizon: true})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if len(sol.Excluded) != 1 || sol.Excluded[0] != "G99" || sol.NumSats != 8 {
+		t.Errorf("excluded=%v, nsat=%d", sol.Excluded, sol.NumSats)
+	}
+	if d := math.Sqrt(sqr(sol.Position[0]-site[0]) + sqr(sol.Position[1]-site[1]) + sqr(sol.Position[2]-site[2])); d > 1e-3 {
+		t.Errorf("position error: d=%f", d)
+	}
+
+	// nothing excluded for the mask far below the horizon
+	mask := -90.
+	sol, _ = Solve(satDatas, CalcPosOpts{ExcludeBelowHorizon: true, HorizonMask: &mask})
+	if len(sol.Excluded) != 0 {
+		t.Errorf("excluded=%v, want none", sol.Excluded)
+	}
+
+	// a satellite at -2 deg is kept by the default mask, but excluded by the
+	// mask of 0 deg
+	el = coord.Deg2Rad(-2.)
+	pos = coord.ENUToECEF(site, [3]float64{22000e3 * math.Cos(el), 0., 22000e3 * math.Sin(el)})
+	low := SatData{X: pos[0], Y: pos[1], Z: pos[2], PR: ComputePseudorange(site, 0., pos), ID: gnss.SatID{Sys: gnss.GPS, PRN: 98}}
+	satDatas = append(satDatas[:len(satDatas)-1], low)
+
+	sol, _ = Solve(satDatas, CalcPosOpts{ExcludeBelowHorizon: true})
+	if len(sol.Excluded) != 0 {
+		t.Errorf("default mask: excluded=%v, want none", sol.Excluded)
+	}
+	mask = 0.
+	sol, _ = Solve(satDatas, CalcPosOpts{ExcludeBelowHorizon: true, HorizonMask: &mask})
+	if len(sol.Excluded) != 1 || sol.Excluded[0] != "G98" || sol.NumSats != 8 {
+		t.Errorf("mask=0: excluded=%v, nsat=%d", sol.Excluded, sol.NumSats)
+	}
+}
+
+// TestExclude checks that the satellites in CalcPosOpts.Exclude are removed
+// and listed in Solution.Excluded.
+func TestExclude(t *testing.T) {
+	satDatas := testSatDatas()
+	for i := range satDatas {
+		satDatas[i].ID = satIDs[i]
+	}
+
+	exclude := map[string]bool{satIDs[1].String(): true, satIDs[4].String(): true, "G99": true}
+	sol, err := Solve(satDatas, CalcPosOpts{Exclude: exclude})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+	if !slices.Equal(sol.Excluded, []string{satIDs[1].String(), satIDs[4].String()}) || sol.NumSats != len(satDatas)-2 {
+		t.Errorf("excluded=%v, nsat=%d", sol.Excluded, sol.NumSats)
+	}
+	if slices.Contains(sol.SatIDs, satIDs[1]) || slices.Contains(sol.SatIDs, satIDs[4]) {
+		t.Errorf("excluded satellite used: %v", sol.SatIDs)
+	}
+
+	// too few satellites left
+	for _, id := range satIDs[:len(satDatas)-3] {
+		exclude[id.String()] = true
+	}
+	if _, err := Solve(satDatas, CalcPosOpts{Exclude: exclude}); !errors.Is(err, ErrTooFewSats) {
+		t.Errorf("get err=%v, want %v", err, ErrTooFewSats)
+	}
+}
@@ -0,0 +1,156 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// RAIMOpts defines options for CalcPosRAIM.
+type RAIMOpts struct {
+	// Threshold is the residual RMS (m) above which the solution is regarded
+	// as inconsistent.
+	Threshold float64
+
+	// Pfa is the false alarm probability of the chi-square test performed
+	// on the final solution. The test is disabled if zero.
+	Pfa float64
+
+	// Sigma is the standard deviation (m) of the pseudoranges used for the
+	// weights of the chi-square test.
+	Sigma float64
+
+	// CalcPosOpts is passed to CalcPosWithOpts. MaxResidual is ignored.
+	CalcPosOpts CalcPosOpts
+}
+
+// RAIMResult stores the result of CalcPosRAIM.
+type RAIMResult struct {
+	// the final solution
+	X, Y, Z, Dt float64
+
+	// residual RMS (m) of the final solution
+	RMS float64
+
+	// residual RMS (m) of the solution with all satellites
+	RMSAll float64
+
+	// Detected is true if the fault was detected with all satellites
+	Detected bool
+
+	// indices and IDs (see SatData.ID) of the excluded satellites
+	Excluded    []int
+	ExcludedIDs []gnss.SatID
+
+	// ChiSquare is the result of the chi-square test of the final solution
+	ChiSquare ChiSquareResult
+
+	// Suspect is true if the chi-square test of the final solution failed
+	Suspect bool
+
+	// SubsetRMS stores the residual RMS (m) of the leave-one-out solutions,
+	// where SubsetRMS[i] is for the subset excluding the i-th satellite.
+	// NaN is set if the solution failed. nil if the test was not performed.
+	SubsetRMS []float64
+}
+
+// CalcPosRAIM solves the GNSS equation with a receiver autonomous integrity
+// monitoring (RAIM) check.
+//
+// The solution with all satellites is tested by the residual RMS against
+// opts.Threshold. If the test fails and six or more satellites are available,
+// each leave-one-out subset is solved, and the satellite whose removal gives
+// the smallest residual RMS is excluded.
+//
+// An error is returned with the result if the fault is detected but cannot
+// be excluded.
+func CalcPosRAIM(satDatas []SatData, opts RAIMOpts) (res RAIMResult, err error) {
+	copts := opts.CalcPosOpts
+	copts.MaxResidual = 0
+
+	res.X, res.Y, res.Z, res.Dt, err = CalcPosWithOpts(satDatas, copts)
+	if err != nil {
+		return res, err
+	}
+	res.RMSAll = posResidualRMS(satDatas, res.X, res.Y, res.Z, res.Dt)
+	res.RMS = res.RMSAll
+
+	if res.RMSAll <= opts.Threshold {
+		return res, res.testChiSquare(satDatas, opts)
+	}
+
+	// fault detected
+	res.Detected = true
+	n := len(satDatas)
+	if n < 6 {
+		return res, fmt.Errorf("fault detected but not excludable: n=%d, rms=%.3f", n, res.RMSAll)
+	}
+
+	// leave-one-out test
+	best := -1
+	res.SubsetRMS = make([]float64, n)
+	subset := make([]SatData, n-1)
+	for i := range n {
+		copy(subset, satDatas[:i])
+		copy(subset[i:], satDatas[i+1:])
+
+		x, y, z, dt, e := CalcPosWithOpts(subset, copts)
+		if e != nil {
+			res.SubsetRMS[i] = math.NaN()
+			continue
+		}
+		res.SubsetRMS[i] = posResidualRMS(subset, x, y, z, dt)
+
+		if best < 0 || res.SubsetRMS[i] < res.SubsetRMS[best] {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return res, fmt.Errorf("fault detected but not excluded: rms=%.3f", res.RMSAll)
+	}
+	if res.SubsetRMS[best] > opts.Threshold {
+		return res, fmt.Errorf("fault detected but not excluded: rms=%.3f, best candidate=%s, rms=%.3f", res.RMSAll, satDatas[best].Label(best), res.SubsetRMS[best])
+	}
+
+	// exclude the satellite
+	copy(subset, satDatas[:best])
+	copy(subset[best:], satDatas[best+1:])
+	res.X, res.Y, res.Z, res.Dt, err = CalcPosWithOpts(subset, copts)
+	if err != nil {
+		return res, err
+	}
+	res.RMS = res.SubsetRMS[best]
+	res.Excluded = []int{best}
+	res.ExcludedIDs = []gnss.SatID{satDatas[best].ID}
+
+	return res, res.testChiSquare(subset, opts)
+}
+
+// testChiSquare performs the chi-square test of the solution if enabled.
+func (res *RAIMResult) testChiSquare(satDatas []SatData, opts RAIMOpts) (err error) {
+	if opts.Pfa == 0 {
+		return nil
+	}
+	if !(opts.Sigma > 0) {
+		return fmt.Errorf("invalid sigma for the chi-square test: sigma=%v", opts.Sigma)
+	}
+
+	v := Residuals(satDatas, res.X, res.Y, res.Z, res.Dt)
+	w := make([]float64, len(v))
+	for i := range w {
+		w[i] = 1. / sqr(opts.Sigma)
+	}
+
+	res.ChiSquare, err = ChiSquareTest(v, w, opts.Pfa)
+	res.Suspect = res.ChiSquare.Available && !res.ChiSquare.Pass
+
+	return err
+}
+
+// posResidualRMS returns the RMS of the pseudorange residuals (m) for the
+// position (m) and the clock bias (s).
+func posResidualRMS(satDatas []SatData, x, y, z, dt float64) float64 {
+	return residualRMS(satDatas, []float64{x, y, z, dt * LightVelocity})
+}
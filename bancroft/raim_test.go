@@ -0,0 +1,31 @@
+package bancroft
+
+import "testing"
+
+// TestCalcPosRAIM checks that a satellite with a large pseudorange error is
+// detected and excluded.
+func TestCalcPosRAIM(t *testing.T) {
+	satDatas := testSatDatas()
+	opts := RAIMOpts{Threshold: 50.}
+
+	// no fault
+	res, err := CalcPosRAIM(satDatas, opts)
+	if err != nil || res.Detected {
+		t.Fatalf("fault detected for the clean data: err=%v, res=%+v", err, res)
+	}
+
+	// add a fault
+	const faulty = 3
+	satDatas[faulty].PR += 5000.
+
+	res, err = CalcPosRAIM(satDatas, opts)
+	if err != nil {
+		t.Fatalf("CalcPosRAIM: %v", err)
+	}
+	if !res.Detected || len(res.Excluded) != 1 || res.Excluded[0] != faulty || res.ExcludedIDs[0] != satIDs[3] {
+		t.Errorf("fault not excluded: want=%d, res=%+v", faulty, res)
+	}
+	if res.RMS > opts.Threshold {
+		t.Errorf("too large residual after the exclusion: %f", res.RMS)
+	}
+}
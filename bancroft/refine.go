@@ -0,0 +1,70 @@
+package bancroft
+
+import (
+	"fmt"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// SolveLSQ solves the position by the weighted least squares starting from
+// the solution of CalcPos, and returns the solution with the diagnostics as
+// Solve.
+//
+// The Bancroft solution is the least squares solution of the algebraic
+// equations, which differs from that of the pseudoranges for the
+// inconsistent observations. SolveLSQ iterates the linearized pseudorange
+// equations (Gauss-Newton) to minimize the weighted sum of the squared
+// residuals, where weights are those of the satellites, e.g., by
+// WeightModel.Weights, or nil for the equal weights.
+//
+// DOP is that of the geometry, and WDOP is that of the weights (see
+// CalcWDOP). Cov is the cofactor matrix (A'WA)^-1 of x, y, z and c*dt (m)
+// from the Cholesky factorization of the last iteration, which is the
+// covariance if the weights are 1/sigma^2.
+func SolveLSQ(satDatas []SatData, weights []float64) (Solution, error) {
+	if weights != nil && len(weights) != len(satDatas) {
+		return Solution{}, fmt.Errorf("%w: size mismatch: sats=%d, weights=%d", ErrBadInput, len(satDatas), len(weights))
+	}
+
+	x, y, z, dt, err := CalcPos(satDatas)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	p := lsqProblem{satDatas: satDatas, clk: make([]int, len(satDatas)), nclk: 1, weights: weights}
+	state, chol, err := p.solve([]float64{x, y, z, -dt * LightVelocity}, 10, 1e-4)
+	if err != nil {
+		return Solution{}, err
+	}
+	Q, err := cofactor(chol)
+	if err != nil {
+		return Solution{}, err
+	}
+	// the clock term b = -c*dt is negated to c*dt
+	for i := range 3 {
+		Q.SetSym(i, 3, -Q.At(i, 3))
+	}
+	x, y, z, dt = state[0], state[1], state[2], -state[3]/LightVelocity
+
+	sol := Solution{
+		Position:  [3]float64{x, y, z},
+		ClockBias: dt,
+		NumSats:   len(satDatas),
+		SatIDs:    make([]gnss.SatID, len(satDatas)),
+		Residuals: Residuals(satDatas, x, y, z, dt),
+		RMS:       residualRMS(satDatas, []float64{x, y, z, dt * LightVelocity}),
+		Cov:       Q,
+	}
+	for i, s := range satDatas {
+		sol.SatIDs[i] = s.ID
+	}
+
+	if weights == nil {
+		if dop, err := CalcDOP(sol.Position, satDatas); err == nil {
+			sol.DOP, sol.WDOP = dop, dop
+		}
+	} else if dop, wdop, err := CalcWDOP(sol.Position, satDatas, weights); err == nil {
+		sol.DOP, sol.WDOP = dop, wdop
+	}
+	return sol, nil
+}
@@ -0,0 +1,85 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// TestSolveLSQ checks that the residuals of the solution are orthogonal to
+// the columns of the weighted design matrix, i.e., the solution is the
+// minimum of the weighted sum of the squared residuals, that Cov is the
+// inverse of the normal matrix, and that it fits the pseudoranges no worse
+// than the Bancroft solution.
+func TestSolveLSQ(t *testing.T) {
+	satDatas := testSatDatas()
+	x, y, z, dt, _ := CalcPos(satDatas)
+	rms0 := residualRMS(satDatas, []float64{x, y, z, dt * LightVelocity})
+
+	for _, weights := range [][]float64{nil, {1, 2, 1, 3, 1, 1, 0.5, 1, 2}} {
+		sol, err := SolveLSQ(satDatas, weights)
+		if err != nil {
+			t.Fatalf("SolveLSQ: %v", err)
+		}
+		if sol.NumSats != len(satDatas) || sol.SatIDs[0] != satIDs[0] || sol.DOP.PDOP == 0 {
+			t.Errorf("diagnostics: %+v", sol)
+		}
+
+		// A'Wv = 0 for the rows (-e, 1) of A
+		var g [4]float64
+		for i, s := range satDatas {
+			w := 1.
+			if weights != nil {
+				w = weights[i]
+			}
+			e := lineOfSight(sol.Position, s.X, s.Y, s.Z)
+			for k := range 3 {
+				g[k] -= w * e[k] * sol.Residuals[i]
+			}
+			g[3] += w * sol.Residuals[i]
+		}
+		for k := range 4 {
+			if math.Abs(g[k]) > 1e-4 {
+				t.Errorf("weights=%v: A'Wv=%v", weights, g)
+				break
+			}
+		}
+		// covariance by the general inverse of A'WA for the rows (-e, -1)
+		A := mat.NewDense(len(satDatas), 4, nil)
+		W := mat.NewDiagDense(len(satDatas), nil)
+		for i, s := range satDatas {
+			e := lineOfSight(sol.Position, s.X, s.Y, s.Z)
+			A.SetRow(i, []float64{-e[0], -e[1], -e[2], -1.})
+			W.SetDiag(i, 1.)
+			if weights != nil {
+				W.SetDiag(i, weights[i])
+			}
+		}
+		var AtW, N, Q mat.Dense
+		AtW.Mul(A.T(), W)
+		N.Mul(&AtW, A)
+		if err := Q.Inverse(&N); err != nil {
+			t.Fatalf("Inverse: %v", err)
+		}
+		if sol.Cov == nil {
+			t.Fatalf("weights=%v: no Cov", weights)
+		}
+		for i := range 4 {
+			for j := range 4 {
+				if d := sol.Cov.At(i, j) - Q.At(i, j); math.Abs(d) > 1e-9*math.Abs(Q.At(i, i)) {
+					t.Errorf("weights=%v: Cov[%d][%d]: get %e, want %e", weights, i, j, sol.Cov.At(i, j), Q.At(i, j))
+				}
+			}
+		}
+
+		if weights == nil && sol.RMS > rms0+1e-6 {
+			t.Errorf("RMS: get %f, Bancroft %f", sol.RMS, rms0)
+		}
+	}
+
+	if _, err := SolveLSQ(satDatas, []float64{1}); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
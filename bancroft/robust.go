@@ -0,0 +1,137 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// RobustOpts defines options for CalcPosRobust.
+type RobustOpts struct {
+	// K is the tuning constant of the Huber function in the unit of the
+	// standardized residual. 1.345 is used if zero.
+	K float64
+
+	// Sigma is the standard deviation (m) of the pseudoranges used to
+	// standardize the residuals. If zero, it is estimated from the median
+	// absolute deviation (MAD) of the residuals at each iteration.
+	Sigma float64
+
+	// MaxIter is the maximum number of the reweighting iterations.
+	// 50 is used if zero.
+	MaxIter int
+}
+
+// RobustResult stores the result of CalcPosRobust.
+type RobustResult struct {
+	// position (m) and the receiver clock bias (s)
+	X, Y, Z, Dt float64
+
+	// Weights stores the final weights (0-1] of the satellites; a weight
+	// smaller than 1 means the satellite was down-weighted.
+	Weights []float64
+
+	// Scale is the standard deviation (m) used to standardize the residuals
+	// at the final iteration.
+	Scale float64
+
+	// Iterations is the number of the reweighting iterations
+	Iterations int
+}
+
+// CalcPosRobust solves the position by the iteratively reweighted least
+// squares (IRLS) with the Huber function, starting from the solution of
+// CalcPos.
+//
+// The weight of the i-th satellite is
+//
+//	w_i = 1          (|u_i| <= K)
+//	w_i = K/|u_i|    (|u_i| >  K)
+//
+// where u_i is the standardized residual. If no residual exceeds K, all the
+// weights are 1 and the solution is the ordinary least squares.
+//
+// Note that the Huber function bounds the influence of the residuals but not
+// of the geometry; outliers on high-leverage satellites may not be identified.
+func CalcPosRobust(satDatas []SatData, opts RobustOpts) (res RobustResult, err error) {
+	k := opts.K
+	if k == 0 {
+		k = 1.345
+	}
+	maxIter := opts.MaxIter
+	if maxIter == 0 {
+		maxIter = 50
+	}
+
+	n := len(satDatas)
+	if n < 5 {
+		return res, fmt.Errorf("%w: robust estimation requires more: n=%d", ErrTooFewSats, n)
+	}
+
+	x, y, z, dt, err := CalcPos(satDatas)
+	if err != nil {
+		return res, err
+	}
+	x0 := []float64{x, y, z, -dt * LightVelocity}
+
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1.
+	}
+
+	p := lsqProblem{satDatas: satDatas, clk: make([]int, n), nclk: 1, weights: w}
+	for iter := 1; iter <= maxIter; iter++ {
+		state, _, e := p.solve(x0, 10, 1e-4)
+		if e != nil {
+			return res, e
+		}
+		x0 = state
+		res.Iterations = iter
+
+		// standardized residuals
+		v := Residuals(satDatas, state[0], state[1], state[2], -state[3]/LightVelocity)
+		res.Scale = opts.Sigma
+		if res.Scale == 0 {
+			res.Scale = madScale(v)
+		}
+
+		var change float64
+		for i := range n {
+			wi := 1.
+			if u := math.Abs(v[i]) / res.Scale; u > k {
+				wi = k / u
+			}
+			change = math.Max(change, math.Abs(wi-w[i]))
+			w[i] = wi
+		}
+
+		if change < 1e-6 {
+			break
+		}
+	}
+
+	res.X, res.Y, res.Z, res.Dt = x0[0], x0[1], x0[2], -x0[3]/LightVelocity
+	res.Weights = w
+
+	return res, nil
+}
+
+// madScale returns the standard deviation estimated from the median absolute
+// deviation of v, 1.4826*MAD, with the lower bound of 1e-3 m to avoid the
+// division by zero for the exact data.
+func madScale(v []float64) float64 {
+	a := make([]float64, len(v))
+	for i := range v {
+		a[i] = math.Abs(v[i])
+	}
+	slices.Sort(a)
+
+	var med float64
+	if n := len(a); n%2 == 1 {
+		med = a[n/2]
+	} else {
+		med = 0.5 * (a[n/2-1] + a[n/2])
+	}
+
+	return math.Max(1.4826*med, 1e-3)
+}
@@ -0,0 +1,47 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCalcPosRobust checks the contaminated satellites are down-weighted,
+// and the clean data gives the ordinary least squares.
+func TestCalcPosRobust(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 12, MinElevation: 10., NoiseSigma: 1., Seed: 4})
+
+	// clean data: all weights 1 with the large K
+	res, err := CalcPosRobust(sc.SatDatas, RobustOpts{Sigma: 1., K: 10.})
+	if err != nil {
+		t.Fatalf("CalcPosRobust: %v", err)
+	}
+	for i, w := range res.Weights {
+		if w != 1 {
+			t.Errorf("clean data: satellite %d is down-weighted: w=%f", i, w)
+		}
+	}
+
+	// three contaminated satellites
+	faulty := []int{1, 5, 9}
+	for _, i := range faulty {
+		sc.SatDatas[i].PR += 100.
+	}
+
+	res, err = CalcPosRobust(sc.SatDatas, RobustOpts{Sigma: 1.})
+	if err != nil {
+		t.Fatalf("CalcPosRobust: %v", err)
+	}
+	for _, i := range faulty {
+		if res.Weights[i] > 0.1 {
+			t.Errorf("contaminated satellite %d not down-weighted: w=%f", i, res.Weights[i])
+		}
+	}
+
+	d := math.Sqrt(sqr(res.X-site[0]) + sqr(res.Y-site[1]) + sqr(res.Z-site[2]))
+	x, y, z, _, _ := CalcPos(sc.SatDatas)
+	d0 := math.Sqrt(sqr(x-site[0]) + sqr(y-site[1]) + sqr(z-site[2]))
+	if d > 10. || d > d0 {
+		t.Errorf("robust error=%f m, CalcPos error=%f m", d, d0)
+	}
+}
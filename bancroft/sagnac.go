@@ -0,0 +1,48 @@
+package bancroft
+
+import "math"
+
+// EarthRotationRate is the Earth rotation rate (rad/s) defined in WGS84.
+const EarthRotationRate = 7.2921151467e-5
+
+// RotateSatPos rotates the satellite position (m) in the ECEF frame at the
+// transmission time into the ECEF frame at the reception time, where tau (s)
+// is the signal travel time.
+//
+// During the travel time the Earth rotates by the angle omega_e*tau about the
+// Z axis, so the satellite position is rotated by -omega_e*tau:
+//
+//	x' =  cos(omega_e*tau)*x + sin(omega_e*tau)*y
+//	y' = -sin(omega_e*tau)*x + cos(omega_e*tau)*y
+//	z' =  z
+func RotateSatPos(x, y, z, tau float64) (xr, yr, zr float64) {
+	theta := EarthRotationRate * tau
+	sin, cos := math.Sincos(theta)
+	return cos*x + sin*y, -sin*x + cos*y, z
+}
+
+// SagnacCorrection returns the range correction (m) due to the Earth rotation
+// during the signal travel (the Sagnac effect) for the receiver position
+// (rx, ry, rz) and the satellite position (sx, sy, sz) at the transmission
+// time, both in the ECEF frame (m).
+//
+//	drho = omega_e*(sx*ry - sy*rx)/c
+//
+// The correction is to be added to the geometric range computed with the
+// unrotated satellite position, or equivalently to be subtracted from the
+// pseudorange. It is an alternative to RotateSatPos, and both must not be
+// applied at the same time.
+func SagnacCorrection(rx, ry, rz, sx, sy, sz float64) float64 {
+	return EarthRotationRate * (sx*ry - sy*rx) / LightVelocity
+}
+
+// RotateEarth returns a copy of satDatas whose satellite positions are rotated
+// by RotateSatPos with the travel time approximated by PR/c.
+func RotateEarth(satDatas []SatData) []SatData {
+	rotated := make([]SatData, len(satDatas))
+	for i, s := range satDatas {
+		rotated[i] = s
+		rotated[i].X, rotated[i].Y, rotated[i].Z = RotateSatPos(s.X, s.Y, s.Z, s.PR/LightVelocity)
+	}
+	return rotated
+}
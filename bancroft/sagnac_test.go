@@ -0,0 +1,33 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSagnacCorrection checks the magnitude of the Sagnac correction, and
+// the consistency with RotateSatPos.
+func TestSagnacCorrection(t *testing.T) {
+	// receiver on the equator, and a GPS satellite 60 degrees east of it.
+	const (
+		R = 6378137. // receiver radius (m)
+		r = 26560.e3 // satellite radius (m)
+	)
+	rx, ry, rz := R, 0., 0.
+	sx, sy, sz := r*math.Cos(math.Pi/3), r*math.Sin(math.Pi/3), 0.
+
+	// omega_e*R*r*sin(60deg)/c = 35.685 m (textbook order of ~30 m)
+	want := -35.685
+	got := SagnacCorrection(rx, ry, rz, sx, sy, sz)
+	if math.Abs(got-want) > 0.01 {
+		t.Errorf("SagnacCorrection: get %.3f, want %.3f", got, want)
+	}
+
+	// rotation of the satellite position gives the same range correction
+	rho0 := math.Sqrt(sqr(sx-rx) + sqr(sy-ry) + sqr(sz-rz))
+	xr, yr, zr := RotateSatPos(sx, sy, sz, rho0/LightVelocity)
+	rho1 := math.Sqrt(sqr(xr-rx) + sqr(yr-ry) + sqr(zr-rz))
+	if math.Abs((rho1-rho0)-got) > 0.01 {
+		t.Errorf("RotateSatPos: get %.3f, want %.3f", rho1-rho0, got)
+	}
+}
@@ -0,0 +1,50 @@
+package bancroft
+
+import "time"
+
+// Satellite clock corrections.
+//
+// The pseudorange is modeled as PR = rho - c*dt - c*dts, where dt is the
+// receiver clock bias solved by CalcPos (see SatData) and dts is the satellite
+// clock bias. All the corrections below are returned in meters as c*dts, and
+// are to be ADDED to the pseudorange:
+//
+//	PR' = PR + correction
+//
+// as done in the test of this package (PR = range + C*1e-6*c for the SP3
+// clock C in microseconds).
+
+// BroadcastClock returns the satellite clock bias (s) at the time t computed
+// from the broadcast clock polynomial (af0, af1, af2) referenced to toc.
+// The relativistic correction is not included (see RelativisticCorrection).
+//
+//	dts = af0 + af1*(t-toc) + af2*(t-toc)^2
+func BroadcastClock(af0, af1, af2 float64, toc, t time.Time) float64 {
+	dt := t.Sub(toc).Seconds()
+	return af0 + af1*dt + af2*dt*dt
+}
+
+// BroadcastClockCorrection returns the range correction (m) of the broadcast
+// satellite clock at the time t, to be added to the pseudorange.
+func BroadcastClockCorrection(af0, af1, af2 float64, toc, t time.Time) float64 {
+	return BroadcastClock(af0, af1, af2, toc, t) * LightVelocity
+}
+
+// SP3ClockCorrection returns the range correction (m) of the satellite clock
+// given in microseconds as in SP3 files, to be added to the pseudorange.
+func SP3ClockCorrection(clkMicrosec float64) float64 {
+	return clkMicrosec * 1e-6 * LightVelocity
+}
+
+// RelativisticCorrection returns the range correction (m) of the periodic
+// relativistic effect due to the orbital eccentricity,
+//
+//	c*dtrel = -2*(r.v)/c
+//
+// for the satellite position r (m) and velocity v (m/s) in the ECEF (or
+// inertial) frame, to be added to the pseudorange together with the clock
+// correction. Both the broadcast and the SP3 clocks exclude this term.
+func RelativisticCorrection(pos, vel [3]float64) float64 {
+	rv := pos[0]*vel[0] + pos[1]*vel[1] + pos[2]*vel[2]
+	return -2. * rv / LightVelocity
+}
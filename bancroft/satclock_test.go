@@ -0,0 +1,49 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSatClockCorrection checks the sign and the size of the satellite clock
+// corrections.
+func TestSatClockCorrection(t *testing.T) {
+	// SP3 clock of G14 in the test data: 448.162636 microsec -> +134355.778 m
+	c := SP3ClockCorrection(satPosData[0].C)
+	if want := rangeData[0] + satPosData[0].C*0.000001*LightVelocity; rangeData[0]+c != want {
+		t.Errorf("SP3ClockCorrection: get %f, want %f", rangeData[0]+c, want)
+	}
+	if math.Abs(c-134355.778) > 1e-3 {
+		t.Errorf("SP3ClockCorrection: get %f", c)
+	}
+
+	// broadcast clock
+	toc := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	tt := toc.Add(100 * time.Second)
+	dts := BroadcastClock(1e-4, 1e-11, 1e-18, toc, tt)
+	if want := 1e-4 + 1e-11*100 + 1e-18*1e4; math.Abs(dts-want) > 1e-16 {
+		t.Errorf("BroadcastClock: get %e, want %e", dts, want)
+	}
+	if c := BroadcastClockCorrection(1e-4, 0, 0, toc, tt); math.Abs(c-29979.2458) > 1e-6 {
+		t.Errorf("BroadcastClockCorrection: get %f", c)
+	}
+
+	// relativistic correction: for e=0.01, a=26560 km, the amplitude is
+	// 2*sqrt(GM*a)*e/c = 6.9 m at the eccentric anomaly of 90deg
+	const (
+		GM = 3.986005e14
+		a  = 26560e3
+		e  = 0.01
+	)
+	// position and velocity at E=90deg in the orbital plane
+	n := math.Sqrt(GM / (a * a * a))
+	r := [3]float64{-a * e, a * math.Sqrt(1-e*e), 0.}
+	v := [3]float64{-n * a, 0., 0.}
+
+	got := RelativisticCorrection(r, v)
+	want := -2. * math.Sqrt(GM*a) * e / LightVelocity
+	if math.Abs(got-want) > 1e-6 || math.Abs(got+6.9) > 0.1 {
+		t.Errorf("RelativisticCorrection: get %f, want %f", got, want)
+	}
+}
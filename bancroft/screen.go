@@ -0,0 +1,90 @@
+package bancroft
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// bancroft logger
+var logger = log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)
+
+// DefaultMinSeparation is the default threshold (deg) of the angle between
+// the line-of-sight vectors used by ScreenSatDatas.
+const DefaultMinSeparation = 0.1
+
+// ScreenOpts defines options for ScreenSatDatas.
+type ScreenOpts struct {
+	// MinSeparation is the angle (deg) between the line-of-sight vectors of
+	// two satellites below which they are regarded as collinear.
+	// DefaultMinSeparation is used if zero.
+	MinSeparation float64
+
+	// ApproxPos is the approximate receiver position (ECEF, m) from which
+	// the line-of-sight vectors are computed. The geocenter is used if zero.
+	ApproxPos [3]float64
+
+	// Drop selects the behavior for the duplicated or collinear pair: the
+	// latter satellite of the pair is dropped with a warning if true, and an
+	// error is returned otherwise.
+	Drop bool
+}
+
+// ScreenSatDatas screens the input for the duplicated satellite IDs or
+// positions, and the satellites whose line-of-sight vectors are nearly
+// parallel, which make the equations near-singular.
+//
+// The screened satellites and the indices of the dropped ones are returned.
+// If opts.Drop is false, an error naming the offending pair is returned
+// instead of dropping.
+func ScreenSatDatas(satDatas []SatData, opts ScreenOpts) (kept []SatData, dropped []int, err error) {
+	minSep := opts.MinSeparation
+	if minSep == 0 {
+		minSep = DefaultMinSeparation
+	}
+	cosMin := math.Cos(coord.Deg2Rad(minSep))
+
+	kept = make([]SatData, 0, len(satDatas))
+	keptIdx := make([]int, 0, len(satDatas))
+	los := make([][3]float64, 0, len(satDatas))
+
+	for i, s := range satDatas {
+		e := lineOfSight(opts.ApproxPos, s.X, s.Y, s.Z)
+
+		var reason string
+		var j int
+		for k, sk := range kept {
+			j = keptIdx[k]
+			switch {
+			case !s.ID.IsZero() && s.ID == sk.ID:
+				reason = "duplicated ID"
+			case s.X == sk.X && s.Y == sk.Y && s.Z == sk.Z:
+				reason = "duplicated position"
+			case e[0]*los[k][0]+e[1]*los[k][1]+e[2]*los[k][2] > cosMin:
+				reason = "collinear line of sight"
+			default:
+				continue
+			}
+			break
+		}
+
+		if reason != "" {
+			msg := fmt.Sprintf("%s: sat=%s, %s", reason, satDatas[j].Label(j), s.Label(i))
+			if !opts.Drop {
+				return kept, dropped, fmt.Errorf("%w: %s", ErrSingularGeometry, msg)
+			}
+			logger.Printf("warning: %s, dropped %s\n", msg, s.Label(i))
+			dropped = append(dropped, i)
+			continue
+		}
+
+		kept = append(kept, s)
+		keptIdx = append(keptIdx, i)
+		los = append(los, e)
+	}
+
+	return kept, dropped, nil
+}
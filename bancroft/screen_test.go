@@ -0,0 +1,57 @@
+package bancroft
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// TestScreenSatDatas checks the duplicated and collinear satellites are
+// detected, and dropped with the option.
+func TestScreenSatDatas(t *testing.T) {
+	logger.SetOutput(io.Discard)
+
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	base := testSatDatas()
+
+	// clean data
+	kept, dropped, err := ScreenSatDatas(base, ScreenOpts{ApproxPos: site})
+	if err != nil || len(kept) != len(base) || len(dropped) != 0 {
+		t.Fatalf("clean data: kept=%d, dropped=%v, err=%v", len(kept), dropped, err)
+	}
+
+	// the same satellite merged twice, e.g., from two frequencies
+	dup := append(slices.Clone(base), base[2])
+	dup[len(dup)-1].PR += 3.
+
+	// a satellite 1 km away from G17: ~0.003 deg seen from the site
+	near := base[4]
+	near.ID, near.X = gnss.SatID{Sys: gnss.GPS, PRN: 99}, near.X+1000.
+	col := append(slices.Clone(base), near)
+
+	for _, tt := range []struct {
+		name     string
+		satDatas []SatData
+	}{
+		{"duplicated", dup},
+		{"collinear", col},
+	} {
+		_, _, err := ScreenSatDatas(tt.satDatas, ScreenOpts{ApproxPos: site})
+		if !errors.Is(err, ErrSingularGeometry) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrSingularGeometry)
+		}
+
+		kept, dropped, err := ScreenSatDatas(tt.satDatas, ScreenOpts{ApproxPos: site, Drop: true})
+		if err != nil || len(kept) != len(base) || !slices.Equal(dropped, []int{len(base)}) {
+			t.Errorf("%s: kept=%d, dropped=%v, err=%v", tt.name, len(kept), dropped, err)
+		}
+
+		// through CalcPosWithOpts
+		if _, _, _, _, err := CalcPosWithOpts(tt.satDatas, CalcPosOpts{Screen: &ScreenOpts{ApproxPos: site, Drop: true}}); err != nil {
+			t.Errorf("%s: CalcPosWithOpts: %v", tt.name, err)
+		}
+	}
+}
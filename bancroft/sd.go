@@ -0,0 +1,193 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SDOpts defines options for CalcPosSD.
+type SDOpts struct {
+	// Ref is the ID of the reference satellite (see SatData.ID). The
+	// satellite at the highest elevation seen from the solution of CalcPos is
+	// used if zero.
+	Ref gnss.SatID
+
+	// Sigmas are the standard deviations (m) of the undifferenced
+	// pseudoranges of the satellites. All 1 if nil.
+	Sigmas []float64
+
+	// Tol is the convergence threshold (m) of the position change.
+	// 1e-4 m is used if zero.
+	Tol float64
+
+	// MaxIter is the maximum number of iterations. 10 is used if zero.
+	MaxIter int
+}
+
+// SDResult stores the result of CalcPosSD.
+type SDResult struct {
+	// position (m)
+	X, Y, Z float64
+
+	// Ref is the index of the reference satellite in the input, and RefID
+	// is its ID, which is zero if not given.
+	Ref   int
+	RefID gnss.SatID
+
+	// Residuals are the residuals (m) of the single differences in the order
+	// of the input without the reference satellite.
+	Residuals []float64
+
+	// Iterations is the number of the iterations.
+	Iterations int
+}
+
+// CalcPosSD solves the position from the between-satellite single
+// differences of the pseudoranges, which are free of the receiver clock:
+//
+//	PR_j - PR_ref = |sat_j - rcv| - |sat_ref - rcv|
+//
+// The single differences share the noise of the reference satellite, and
+// are weighted by the inverse of their covariance
+//
+//	C = diag(sigma_j^2) + sigma_ref^2 * 1 1'
+//
+// with which the solution is identical to the undifferenced least squares
+// with the weights 1/sigma^2. The solution of CalcPos is used as the initial
+// value.
+func CalcPosSD(satDatas []SatData, opts SDOpts) (res SDResult, err error) {
+	tol := opts.Tol
+	if tol == 0 {
+		tol = 1e-4
+	}
+	maxIter := opts.MaxIter
+	if maxIter == 0 {
+		maxIter = 10
+	}
+
+	n := len(satDatas)
+	if n < 4 {
+		return res, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	}
+	sigmas := opts.Sigmas
+	if sigmas == nil {
+		sigmas = make([]float64, n)
+		for i := range sigmas {
+			sigmas[i] = 1.
+		}
+	}
+	if len(sigmas) != n {
+		return res, fmt.Errorf("%w: size mismatch: sats=%d, sigmas=%d", ErrBadInput, n, len(sigmas))
+	}
+	for i, s := range sigmas {
+		if !(s > 0) {
+			return res, fmt.Errorf("%w: invalid sigma: sat=%s, sigma=%v", ErrBadInput, satDatas[i].Label(i), s)
+		}
+	}
+
+	x, y, z, _, err := CalcPos(satDatas)
+	if err != nil {
+		return res, err
+	}
+	pos := [3]float64{x, y, z}
+
+	ref, err := sdReference(satDatas, pos, opts.Ref)
+	if err != nil {
+		return res, err
+	}
+	res.Ref, res.RefID = ref, satDatas[ref].ID
+
+	// covariance of the single differences
+	m := n - 1
+	C := mat.NewSymDense(m, nil)
+	for j := range m {
+		for k := j; k < m; k++ {
+			C.SetSym(j, k, sqr(sigmas[ref]))
+		}
+		C.SetSym(j, j, sqr(sigmas[ref])+sqr(sigmas[sdIndex(j, ref)]))
+	}
+	var chol mat.Cholesky
+	if ok := chol.Factorize(C); !ok {
+		return res, fmt.Errorf("%w: covariance not positive definite", ErrBadInput)
+	}
+
+	A := mat.NewDense(m, 3, nil)
+	dy := mat.NewVecDense(m, nil)
+	res.Residuals = make([]float64, m)
+	for iter := 1; iter <= maxIter; iter++ {
+		href, rhoRef := designRow(pos, satDatas[ref])
+		for j := range m {
+			s := satDatas[sdIndex(j, ref)]
+			h, rho := designRow(pos, s)
+			A.SetRow(j, []float64{h[0] - href[0], h[1] - href[1], h[2] - href[2]})
+			dy.SetVec(j, (s.PR-satDatas[ref].PR)-(rho-rhoRef))
+		}
+
+		// normal equation: (A'C^-1 A) dx = A'C^-1 dy
+		var CinvA mat.Dense
+		var Cinvy, b, dx mat.VecDense
+		if err = chol.SolveTo(&CinvA, A); err != nil {
+			return res, fmt.Errorf("%w: %v", ErrBadInput, err)
+		}
+		if err = chol.SolveVecTo(&Cinvy, dy); err != nil {
+			return res, fmt.Errorf("%w: %v", ErrBadInput, err)
+		}
+		var N mat.Dense
+		N.Mul(A.T(), &CinvA)
+		b.MulVec(A.T(), &Cinvy)
+		if err = dx.SolveVec(&N, &b); err != nil {
+			return res, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+		}
+
+		for k := range 3 {
+			pos[k] += dx.AtVec(k)
+		}
+
+		var model mat.VecDense
+		model.MulVec(A, &dx)
+		for j := range m {
+			res.Residuals[j] = dy.AtVec(j) - model.AtVec(j)
+		}
+
+		res.X, res.Y, res.Z = pos[0], pos[1], pos[2]
+		res.Iterations = iter
+		if mat.Norm(&dx, 2) < tol {
+			return res, nil
+		}
+	}
+
+	return res, fmt.Errorf("not converged: maxIter=%d", maxIter)
+}
+
+// sdReference returns the index of the satellite of the ID, or of the
+// satellite at the highest elevation seen from pos if id is zero.
+func sdReference(satDatas []SatData, pos [3]float64, id gnss.SatID) (int, error) {
+	if !id.IsZero() {
+		for i, s := range satDatas {
+			if s.ID == id {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: reference satellite not found: %s", ErrBadInput, id)
+	}
+
+	ref, maxEl := 0, math.Inf(-1)
+	for i, s := range satDatas {
+		if _, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z}); el > maxEl {
+			ref, maxEl = i, el
+		}
+	}
+	return ref, nil
+}
+
+// sdIndex returns the index in the input of the j-th single difference.
+func sdIndex(j, ref int) int {
+	if j < ref {
+		return j
+	}
+	return j + 1
+}
@@ -0,0 +1,69 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCalcPosSD checks the single difference solution agrees with the
+// undifferenced least squares, and the reference satellite is reported.
+func TestCalcPosSD(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, Dt: 3e-4, NumSats: 9, MinElevation: 10., NoiseSigma: 2., Seed: 4})
+	satDatas := sc.SatDatas
+	n := len(satDatas)
+	sigmas := make([]float64, n)
+	w := make([]float64, n)
+	for i := range satDatas {
+		satDatas[i].ID = satIDs[i]
+		sigmas[i] = 1. + 0.2*float64(i)
+		w[i] = 1. / sqr(sigmas[i])
+	}
+
+	// highest satellite
+	ref := 0
+	for i, el := range sc.El {
+		if el > sc.El[ref] {
+			ref = i
+		}
+	}
+
+	for _, opts := range []SDOpts{{}, {Sigmas: sigmas}, {Sigmas: sigmas, Ref: satIDs[2]}} {
+		res, err := CalcPosSD(satDatas, opts)
+		if err != nil {
+			t.Fatalf("CalcPosSD: %v", err)
+		}
+		want := ref
+		if !opts.Ref.IsZero() {
+			want = 2
+		}
+		if res.Ref != want || res.RefID != satIDs[want] || len(res.Residuals) != n-1 {
+			t.Errorf("ref=%d (%s), want %d, residuals=%d", res.Ref, res.RefID, want, len(res.Residuals))
+		}
+
+		// undifferenced least squares with the same weights
+		p := lsqProblem{satDatas: satDatas, clk: make([]int, n), nclk: 1}
+		if opts.Sigmas != nil {
+			p.weights = w
+		}
+		state, _, err := p.solve([]float64{site[0], site[1], site[2], 0.}, 20, 1e-8)
+		if err != nil {
+			t.Fatalf("solve: %v", err)
+		}
+		d := coord.ECEFToENU([3]float64{state[0], state[1], state[2]}, [3]float64{res.X, res.Y, res.Z})
+		if math.Sqrt(d[0]*d[0]+d[1]*d[1]+d[2]*d[2]) > 1e-4 {
+			t.Errorf("opts=%+v: differs from the undifferenced solution: %v", opts, d)
+		}
+	}
+
+	if _, err := CalcPosSD(satDatas, SDOpts{Ref: gnss.SatID{Sys: gnss.GPS, PRN: 99}}); !errors.Is(err, ErrBadInput) {
+		t.Errorf("unknown reference: get err=%v, want %v", err, ErrBadInput)
+	}
+	if _, err := CalcPosSD(satDatas, SDOpts{Sigmas: sigmas[:3]}); !errors.Is(err, ErrBadInput) {
+		t.Errorf("size mismatch: get err=%v, want %v", err, ErrBadInput)
+	}
+}
@@ -0,0 +1,100 @@
+package bancroft
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// EpochData defines the input data of an epoch for CalcPosSeries.
+type EpochData struct {
+	Epoch    time.Time
+	SatDatas []SatData
+}
+
+// CalcPosSeries solves the epochs independently by Solve with opts.
+//
+// The failure of an epoch does not abort the batch; it is recorded in
+// Solution.Err of the epoch. An error is returned only if no epoch was
+// solved.
+func CalcPosSeries(epochs []EpochData, opts CalcPosOpts) ([]Solution, error) {
+	sols := make([]Solution, len(epochs))
+	var nok int
+	for i, ep := range epochs {
+		sols[i] = solveEpoch(ep, opts)
+		if sols[i].Err == nil {
+			nok++
+		}
+	}
+
+	if nok == 0 && len(epochs) > 0 {
+		return sols, fmt.Errorf("no epoch solved: nepoch=%d, first error='%w'", len(epochs), sols[0].Err)
+	}
+
+	return sols, nil
+}
+
+// CalcPosSeriesParallel is the same as CalcPosSeries, but solves the epochs
+// concurrently with the given number of workers. runtime.NumCPU() workers are
+// used if workers <= 0.
+//
+// The solutions are returned in the order of the epochs, and the failure of
+// an epoch does not cancel the others. opts is shared by the workers, and
+// opts.Screen must not be modified during the call.
+func CalcPosSeriesParallel(epochs []EpochData, workers int, opts CalcPosOpts) ([]Solution, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	sols := make([]Solution, len(epochs))
+	idx := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				sols[i] = solveEpoch(epochs[i], opts)
+			}
+		}()
+	}
+	for i := range epochs {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+
+	for _, s := range sols {
+		if s.Err == nil {
+			return sols, nil
+		}
+	}
+	if len(epochs) > 0 {
+		return sols, fmt.Errorf("no epoch solved: nepoch=%d, first error='%w'", len(epochs), sols[0].Err)
+	}
+
+	return sols, nil
+}
+
+// solveEpoch solves an epoch by Solve.
+func solveEpoch(ep EpochData, opts CalcPosOpts) Solution {
+	sol, err := Solve(ep.SatDatas, opts)
+	sol.Epoch = ep.Epoch
+	sol.Err = err
+	return sol
+}
+
+// ClockSamples returns the receiver clock biases of the successful solutions
+// for EstimateClockDrift.
+func ClockSamples(sols []Solution) []ClockSample {
+	samples := make([]ClockSample, 0, len(sols))
+	for _, s := range sols {
+		if s.Err != nil {
+			continue
+		}
+		samples = append(samples, ClockSample{Epoch: s.Epoch, Dt: s.ClockBias})
+	}
+	return samples
+}
@@ -0,0 +1,130 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestCalcPosSeries checks the failed epochs do not abort the batch, and the
+// clock drift is estimated from the series.
+func TestCalcPosSeries(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	const drift = 1e-8
+
+	var epochs []EpochData
+	for i := range 10 {
+		sc := GenerateScenario(ScenarioConfig{Site: site, Dt: 1e-4 + drift*float64(i)*30., NumSats: 7, MinElevation: 10., Seed: uint64(i)})
+		ep := EpochData{Epoch: t0.Add(time.Duration(i) * 30 * time.Second), SatDatas: sc.SatDatas}
+		if i == 3 {
+			// too few satellites
+			ep.SatDatas = ep.SatDatas[:3]
+		}
+		epochs = append(epochs, ep)
+	}
+
+	sols, err := CalcPosSeries(epochs, CalcPosOpts{})
+	if err != nil {
+		t.Fatalf("CalcPosSeries: %v", err)
+	}
+
+	for i, s := range sols {
+		if !s.Epoch.Equal(epochs[i].Epoch) {
+			t.Errorf("epoch %d: invalid epoch %v", i, s.Epoch)
+		}
+		if i == 3 {
+			if s.Err == nil {
+				t.Errorf("epoch %d: error not recorded", i)
+			}
+			continue
+		}
+		d := math.Sqrt(sqr(s.Position[0]-site[0]) + sqr(s.Position[1]-site[1]) + sqr(s.Position[2]-site[2]))
+		if s.Err != nil || d > 1e-4 || s.NumSats != 7 || s.RMS > 1e-4 {
+			t.Errorf("epoch %d: %+v, d=%e", i, s, d)
+		}
+	}
+
+	res, err := EstimateClockDrift(ClockSamples(sols), 0)
+	if err != nil || math.Abs(res.Drift-drift) > 1e-14 {
+		t.Errorf("EstimateClockDrift: %+v, err=%v", res, err)
+	}
+
+	// all epochs failed
+	if _, err := CalcPosSeries(epochs[3:4], CalcPosOpts{}); err == nil {
+		t.Errorf("no error for all failed epochs")
+	}
+}
+
+// TestCalcPosSeriesParallel checks the parallel batch gives the same
+// solutions in the same order as the serial one, with the options passed to
+// each epoch.
+func TestCalcPosSeriesParallel(t *testing.T) {
+	epochs := syntheticEpochs(200)
+	epochs[10].SatDatas = epochs[10].SatDatas[:2]
+	epochs[20].SatDatas = slices.Clone(epochs[20].SatDatas)
+	epochs[20].SatDatas[0].PR += 1000.
+
+	for _, opts := range []CalcPosOpts{{}, {MaxResidual: 1.}} {
+		want, _ := CalcPosSeries(epochs, opts)
+		if rejected := want[20].Err != nil; rejected != (opts.MaxResidual > 0) {
+			t.Errorf("opts=%+v: epoch 20: err=%v", opts, want[20].Err)
+		}
+
+		for _, workers := range []int{0, 1, 3, 16} {
+			got, err := CalcPosSeriesParallel(epochs, workers, opts)
+			if err != nil {
+				t.Fatalf("workers=%d: %v", workers, err)
+			}
+			for i := range want {
+				if got[i].Position != want[i].Position || got[i].ClockBias != want[i].ClockBias || !got[i].Epoch.Equal(want[i].Epoch) || (got[i].Err == nil) != (want[i].Err == nil) {
+					t.Errorf("opts=%+v, workers=%d, epoch %d: get %+v, want %+v", opts, workers, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkCalcPosSeries measures the scaling of the parallel batch with the
+// number of workers up to GOMAXPROCS, for the epochs of a day of 30 s
+// interval.
+func BenchmarkCalcPosSeries(b *testing.B) {
+	epochs := syntheticEpochs(2880)
+
+	b.Run("serial", func(b *testing.B) {
+		for range b.N {
+			CalcPosSeries(epochs, CalcPosOpts{})
+		}
+	})
+	for _, workers := range benchWorkers(runtime.GOMAXPROCS(0)) {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				CalcPosSeriesParallel(epochs, workers, CalcPosOpts{})
+			}
+		})
+	}
+}
+
+// benchWorkers returns the numbers of the workers of the benchmarks, i.e., 1,
+// 2, 4 and maxprocs.
+func benchWorkers(maxprocs int) []int {
+	workers := []int{1, 2, 4, maxprocs}
+	slices.Sort(workers)
+	return slices.Compact(workers)
+}
+
+// syntheticEpochs returns the synthetic epochs of 30 s interval.
+func syntheticEpochs(n int) []EpochData {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	epochs := make([]EpochData, n)
+	for i := range n {
+		sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 9, MinElevation: 10., NoiseSigma: 1., Seed: uint64(i)})
+		epochs[i] = EpochData{Epoch: t0.Add(time.Duration(i) * 30 * time.Second), SatDatas: sc.SatDatas}
+	}
+	return epochs
+}
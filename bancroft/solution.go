@@ -0,0 +1,118 @@
+package bancroft
+
+import (
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Solution stores the solution of an epoch with the diagnostics.
+//
+// New outputs of the solver are added to the struct rather than to the
+// signature of Solve.
+type Solution struct {
+	// Epoch is set by the batch solvers, and is zero for Solve.
+	Epoch time.Time
+
+	// Position is the receiver position (ECEF, m), and ClockBias is the
+	// receiver clock bias (s) as dt of CalcPos.
+	Position  [3]float64
+	ClockBias float64
+
+	// Velocity is the receiver velocity (ECEF, m/s), and ClockDrift is the
+	// receiver clock drift (s/s) as ClockBias. They are estimated by Filter,
+	// and zero for the snapshot solvers.
+	Velocity   [3]float64
+	ClockDrift float64
+
+	// Root is the index of the adopted candidate in the result of CalcPosAll.
+	Root int
+
+	// NumSats is the number of satellites used, and SatIDs stores their IDs
+	// (see SatData.ID), which are zero for those without the ID.
+	NumSats int
+	SatIDs  []gnss.SatID
+
+	// Excluded stores the labels of the satellites excluded from the
+	// solution, e.g., by the innovation gating of Filter.
+	Excluded []string
+
+	// Residuals are the pseudorange residuals (m) of the satellites in the
+	// order of SatIDs, and RMS is their RMS (m).
+	Residuals []float64
+	RMS       float64
+
+	// Leverage is the leverage of the satellites in the order of SatIDs (see
+	// Leverage), and GDOPDelta is the increase of GDOP by removing each of
+	// them (see GDOPDelta), which is computed by Solve only if
+	// CalcPosOpts.LeaveOneOutDOP is set. A satellite with the leverage close
+	// to 1 or the large GDOPDelta dominates the geometry.
+	Leverage  []float64
+	GDOPDelta []float64
+
+	// DOP is the DOP values at the position, and WDOP is the weighted DOP
+	// values for the weights of the solver (see CalcWDOP). WDOP is the same
+	// as DOP for the unweighted solution.
+	DOP, WDOP DOP
+
+	// LowQuality marks the solution of the fallback solver (see
+	// SolveWithFallback), which should be used with care.
+	LowQuality bool
+
+	// Cov is the covariance of the state if estimated, e.g., by Filter in the
+	// order of x, y, z (m), vx, vy, vz (m/s), c*dt (m), c*ddt (m/s), or by
+	// SolveLSQ in the order of x, y, z, c*dt (m). It is nil for the other
+	// snapshot solvers.
+	Cov *mat.SymDense
+
+	// Err is the error of the epoch for the batch solvers; the other fields
+	// except Epoch are invalid if not nil.
+	Err error
+}
+
+// LatLonHeight returns the geodetic latitude, longitude (rad) and the
+// ellipsoidal height (m) of the position on WGS84.
+func (s Solution) LatLonHeight() (lat, lon, h float64) {
+	return coord.XYZToLLH(s.Position[0], s.Position[1], s.Position[2])
+}
+
+// Solve solves the GNSS equation using Bancroft method (Bancroft, 1985) as
+// CalcPosWithOpts, and returns the solution with the diagnostics.
+//
+// If the satellites are screened by opts.Screen, NumSats, SatIDs and
+// Residuals are for the satellites used. The satellites excluded by
+// opts.Exclude or opts.ExcludeBelowHorizon are listed in Excluded.
+func Solve(satDatas []SatData, opts CalcPosOpts) (Solution, error) {
+	c, root, used, excluded, err := calcPosWithOpts(satDatas, opts)
+	if err != nil {
+		return Solution{}, err
+	}
+
+	sol := Solution{
+		Position:  [3]float64{c.X, c.Y, c.Z},
+		ClockBias: c.Dt,
+		Root:      root,
+		NumSats:   len(used),
+		SatIDs:    make([]gnss.SatID, len(used)),
+		Residuals: Residuals(used, c.X, c.Y, c.Z, c.Dt),
+		RMS:       c.Residual,
+		Excluded:  excluded,
+	}
+	for i, s := range used {
+		sol.SatIDs[i] = s.ID
+	}
+
+	// the DOP values and the influence diagnostics are left zero or nil if not
+	// available, e.g., for the singular geometry at the position
+	if dop, err := CalcDOP(sol.Position, used); err == nil {
+		sol.DOP, sol.WDOP = dop, dop
+		sol.Leverage, _ = Leverage(sol.Position, used, nil)
+		if opts.LeaveOneOutDOP {
+			sol.GDOPDelta, _ = GDOPDelta(sol.Position, used)
+		}
+	}
+
+	return sol, nil
+}
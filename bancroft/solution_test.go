@@ -0,0 +1,44 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSolve checks that Solve is consistent with CalcPos and CalcPosAll.
+func TestSolve(t *testing.T) {
+	satDatas := testSatDatas()
+
+	sol, err := Solve(satDatas, CalcPosOpts{})
+	if err != nil {
+		t.Fatalf("Solve: %v", err)
+	}
+
+	x, y, z, dt, _ := CalcPos(satDatas)
+	if sol.Position != [3]float64{x, y, z} || sol.ClockBias != dt {
+		t.Errorf("get %v %v, want %v %v", sol.Position, sol.ClockBias, [3]float64{x, y, z}, dt)
+	}
+
+	cands, _ := CalcPosAll(satDatas)
+	if c := cands[sol.Root]; c.X != x || c.Y != y || c.Z != z {
+		t.Errorf("root=%d does not point the adopted candidate: %+v", sol.Root, cands)
+	}
+
+	if sol.NumSats != len(satDatas) || len(sol.Residuals) != len(satDatas) || len(sol.SatIDs) != len(satDatas) {
+		t.Fatalf("nsat=%d, nres=%d, nid=%d, want %d", sol.NumSats, len(sol.Residuals), len(sol.SatIDs), len(satDatas))
+	}
+	var ss float64
+	for i, v := range sol.Residuals {
+		ss += v * v
+		if sol.SatIDs[i] != satIDs[i] {
+			t.Errorf("SatIDs[%d]=%s, want %s", i, sol.SatIDs[i], satIDs[i])
+		}
+	}
+	if !(sol.DOP.GDOP > 0) || sol.WDOP != sol.DOP {
+		t.Errorf("DOP=%+v, WDOP=%+v", sol.DOP, sol.WDOP)
+	}
+
+	if rms := math.Sqrt(ss / float64(len(satDatas))); math.Abs(rms-sol.RMS) > 1e-6 {
+		t.Errorf("RMS=%f, want %f", sol.RMS, rms)
+	}
+}
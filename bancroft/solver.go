@@ -0,0 +1,151 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/lapack/lapack64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Solver solves the GNSS equation using Bancroft method as CalcPosWithOpts,
+// reusing the working storage across the calls to avoid the allocations in
+// the loops over many epochs.
+//
+// The storage is allocated for the maximum number of the satellites given to
+// NewSolver, and grown only when more satellites are given.
+//
+// A Solver is not safe for concurrent use; use one Solver per goroutine.
+type Solver struct {
+	opts CalcPosOpts
+
+	// A (n x 4) of the Bancroft equations, overwritten by the QR
+	// decomposition, and the scalar factors of the reflectors as mat.QR
+	a   []float64
+	tau []float64
+
+	// identity (n x n) overwritten by the generalized inverse B in the first
+	// 4 rows, and i0, r (eq.6, 7)
+	b, i0, r []float64
+
+	// u, v (eq.10, 11)
+	u, v [4]float64
+
+	// workspaces of LAPACK
+	work  []float64
+	iwork []int
+}
+
+// NewSolver returns a Solver with the options and the storage for up to
+// maxSats satellites.
+func NewSolver(maxSats int, opts CalcPosOpts) *Solver {
+	s := &Solver{opts: opts, tau: make([]float64, 4), iwork: make([]int, 4)}
+	s.grow(max(maxSats, 4))
+	return s
+}
+
+// grow ensures the storage for n satellites.
+func (s *Solver) grow(n int) {
+	if cap(s.a) < 4*n {
+		s.a = make([]float64, 4*n)
+		s.b = make([]float64, n*n)
+		s.i0 = make([]float64, n)
+		s.r = make([]float64, n)
+		s.work = make([]float64, max(s.lwork(n), 12))
+	}
+	s.a = s.a[:4*n]
+	s.b = s.b[:n*n]
+	s.i0 = s.i0[:n]
+	s.r = s.r[:n]
+}
+
+// lwork returns the optimal size of the workspace of the QR decomposition
+// and the multiplication by Q' for n satellites.
+func (s *Solver) lwork(n int) int {
+	var work [1]float64
+	A := blas64.General{Rows: n, Cols: 4, Stride: 4, Data: make([]float64, 4*n)}
+	lapack64.Geqrf(A, s.tau, work[:], -1)
+	lwork := int(work[0])
+
+	B := blas64.General{Rows: n, Cols: n, Stride: n, Data: make([]float64, n*n)}
+	lapack64.Ormqr(blas.Left, blas.Trans, A, s.tau, B, work[:], -1)
+	return max(lwork, int(work[0]))
+}
+
+// CalcPos is the same as CalcPosWithOpts with the options of the Solver.
+// No allocation occurs unless the number of the satellites exceeds the
+// storage, CalcPosOpts.Screen, Exclude or ExcludeBelowHorizon is given, or
+// an error is returned.
+func (s *Solver) CalcPos(satDatas []SatData) (x, y, z, dt float64, err error) {
+	c, _, _, _, err := calcPosWithOptsBy(satDatas, s.opts, s.CalcPosAll)
+	if err != nil {
+		return 0., 0., 0., 0., err
+	}
+	return c.X, c.Y, c.Z, c.Dt, nil
+}
+
+// CalcPosAll is the same as the package function CalcPosAll, but uses the
+// storage of the Solver. The results are identical to those of CalcPosAll,
+// i.e., the same LAPACK routines of gonum are called in the same order as
+// mat.QR does for generalizedInverse.
+func (s *Solver) CalcPosAll(satDatas []SatData) (cands [2]Candidate, err error) {
+	if err = checkSatDatas(satDatas, false); err != nil {
+		return cands, err
+	}
+
+	n := len(satDatas)
+	switch {
+	case n < 4:
+		return cands, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	case n == 4:
+		return calcPosAll4(satDatas)
+	}
+
+	s.grow(n)
+	for i, sd := range satDatas {
+		s.a[4*i], s.a[4*i+1], s.a[4*i+2], s.a[4*i+3] = sd.X, sd.Y, sd.Z, sd.PR
+		s.i0[i] = 1.                                                    // (eq.6)
+		s.r[i] = 0.5 * (sqr(sd.X) + sqr(sd.Y) + sqr(sd.Z) - sqr(sd.PR)) // (eq.7)
+	}
+
+	B, err := s.generalizedInverse(n)
+	if err != nil {
+		return cands, err
+	}
+
+	blas64.Gemv(blas.NoTrans, 1., B, blas64.Vector{N: n, Inc: 1, Data: s.i0}, 0., blas64.Vector{N: 4, Inc: 1, Data: s.u[:]}) // (eq.10)
+	blas64.Gemv(blas.NoTrans, 1., B, blas64.Vector{N: n, Inc: 1, Data: s.r}, 0., blas64.Vector{N: 4, Inc: 1, Data: s.v[:]})  // (eq.11)
+
+	return bancroftCandidates(satDatas, s.u, s.v)
+}
+
+// generalizedInverse is the same as the package function generalizedInverse
+// for A of n satellites in the storage, and returns B (4 x n) in the storage.
+func (s *Solver) generalizedInverse(n int) (B blas64.General, err error) {
+	A := blas64.General{Rows: n, Cols: 4, Stride: 4, Data: s.a}
+	R := blas64.Triangular{N: 4, Stride: 4, Data: s.a, Uplo: blas.Upper, Diag: blas.NonUnit}
+
+	// QR decomposition and its condition number as mat.QR.Factorize
+	lapack64.Geqrf(A, s.tau, s.work, -1)
+	lapack64.Geqrf(A, s.tau, s.work[:int(s.work[0])], int(s.work[0]))
+	cond := 1. / lapack64.Trcon(mat.CondNorm, R, s.work[:12], s.iwork)
+	if !(cond < maxCond) {
+		return B, fmt.Errorf("%w: cond=%e", ErrSingularGeometry, cond)
+	}
+
+	// least squares solution of A*B = I as mat.QR.SolveTo
+	I := blas64.General{Rows: n, Cols: n, Stride: n, Data: s.b}
+	clear(I.Data)
+	for i := range n {
+		I.Data[i*n+i] = 1.
+	}
+	lapack64.Ormqr(blas.Left, blas.Trans, A, s.tau, I, s.work, -1)
+	lapack64.Ormqr(blas.Left, blas.Trans, A, s.tau, I, s.work[:int(s.work[0])], int(s.work[0]))
+	if ok := lapack64.Trtrs(blas.NoTrans, R, I); !ok {
+		return B, fmt.Errorf("%w: %v", ErrSingularGeometry, mat.Condition(math.Inf(1)))
+	}
+
+	return blas64.General{Rows: 4, Cols: n, Stride: n, Data: s.b}, nil
+}
@@ -0,0 +1,141 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// maxExhaustiveSubsets is the maximum number of the subsets evaluated
+// exhaustively by SelectSubset; the greedy heuristic is used above it.
+const maxExhaustiveSubsets = 5000
+
+// SelectSubset selects the k satellites minimizing GDOP at the approximate
+// receiver position approxPos (ECEF, m), and returns their indices in
+// ascending order and the GDOP.
+//
+// All the subsets are evaluated if their number is at most 5000. Otherwise,
+// the greedy heuristic removes the satellite one by one whose removal gives
+// the smallest GDOP, then swaps a selected satellite with an unselected one
+// while the GDOP decreases. The subsets of the singular geometry are never
+// selected; ErrSingularGeometry is returned if all the examined subsets are
+// singular.
+func SelectSubset(satDatas []SatData, approxPos [3]float64, k int) (idx []int, gdop float64, err error) {
+	n := len(satDatas)
+	switch {
+	case k < 4:
+		return nil, 0., fmt.Errorf("%w: k=%d", ErrTooFewSats, k)
+	case k > n:
+		return nil, 0., fmt.Errorf("%w: k=%d, n=%d", ErrTooFewSats, k, n)
+	}
+
+	if binomial(n, k) <= maxExhaustiveSubsets {
+		idx, gdop = selectExhaustive(satDatas, approxPos, k)
+	} else {
+		idx, gdop = selectGreedy(satDatas, approxPos, k)
+	}
+
+	if math.IsInf(gdop, 1) {
+		return nil, gdop, fmt.Errorf("%w: no non-singular subset found: n=%d, k=%d", ErrSingularGeometry, n, k)
+	}
+	return idx, gdop, nil
+}
+
+// subsetGDOP returns the GDOP of the subset, or +Inf for the singular one.
+func subsetGDOP(satDatas []SatData, pos [3]float64, idx []int) float64 {
+	sub := make([]SatData, len(idx))
+	for i, j := range idx {
+		sub[i] = satDatas[j]
+	}
+	dop, err := CalcDOP(pos, sub)
+	if err != nil || math.IsNaN(dop.GDOP) {
+		return math.Inf(1)
+	}
+	return dop.GDOP
+}
+
+// selectExhaustive evaluates all the subsets of size k.
+func selectExhaustive(satDatas []SatData, pos [3]float64, k int) (best []int, bestGDOP float64) {
+	bestGDOP = math.Inf(1)
+
+	// combinations in the lexicographic order
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		if g := subsetGDOP(satDatas, pos, idx); g < bestGDOP {
+			best, bestGDOP = slices.Clone(idx), g
+		}
+
+		i := k - 1
+		for i >= 0 && idx[i] == len(satDatas)-k+i {
+			i--
+		}
+		if i < 0 {
+			return best, bestGDOP
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// selectGreedy selects the subset by the backward elimination followed by
+// the swaps.
+func selectGreedy(satDatas []SatData, pos [3]float64, k int) (idx []int, gdop float64) {
+	idx = make([]int, len(satDatas))
+	for i := range idx {
+		idx[i] = i
+	}
+	gdop = subsetGDOP(satDatas, pos, idx)
+
+	// remove the satellite whose removal gives the smallest GDOP
+	for len(idx) > k {
+		bestJ, bestG := 0, math.Inf(1)
+		for j := range idx {
+			cand := slices.Delete(slices.Clone(idx), j, j+1)
+			if g := subsetGDOP(satDatas, pos, cand); g < bestG {
+				bestJ, bestG = j, g
+			}
+		}
+		idx = slices.Delete(idx, bestJ, bestJ+1)
+		gdop = bestG
+	}
+
+	// swap while the GDOP decreases; the number of the swaps is bounded as
+	// the GDOP strictly decreases among the finite subsets
+	for improved := true; improved; {
+		improved = false
+		for j := range idx {
+			for u := range satDatas {
+				if slices.Contains(idx, u) {
+					continue
+				}
+				cand := slices.Clone(idx)
+				cand[j] = u
+				if g := subsetGDOP(satDatas, pos, cand); g < gdop {
+					idx, gdop, improved = cand, g, true
+				}
+			}
+		}
+	}
+
+	slices.Sort(idx)
+	return idx, gdop
+}
+
+// binomial returns the binomial coefficient C(n, k), saturated at
+// math.MaxInt32 to avoid the overflow.
+func binomial(n, k int) int {
+	k = min(k, n-k)
+	c := 1
+	for i := range k {
+		c = c * (n - i) / (i + 1)
+		if c > math.MaxInt32 {
+			return math.MaxInt32
+		}
+	}
+	return c
+}
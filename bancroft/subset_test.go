@@ -0,0 +1,72 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestSelectSubset checks the exhaustive and the greedy selections.
+func TestSelectSubset(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	// exhaustive: the selected subset is not worse than the first subset
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 10, MinElevation: 10., Seed: 2})
+	idx, gdop, err := SelectSubset(sc.SatDatas, site, 5)
+	if err != nil || len(idx) != 5 {
+		t.Fatalf("SelectSubset: idx=%v, err=%v", idx, err)
+	}
+	if g := subsetGDOP(sc.SatDatas, site, []int{0, 1, 2, 3, 4}); g < gdop {
+		t.Errorf("not optimal: %f < %f", g, gdop)
+	}
+	if g := subsetGDOP(sc.SatDatas, site, idx); g != gdop {
+		t.Errorf("GDOP: get %f, want %f", gdop, g)
+	}
+
+	// greedy: comparable to the exhaustive one
+	_, gGreedy := selectGreedy(sc.SatDatas, site, 5)
+	if gGreedy > 1.2*gdop {
+		t.Errorf("greedy GDOP=%f, exhaustive=%f", gGreedy, gdop)
+	}
+
+	// greedy for the large n
+	sc = GenerateScenario(ScenarioConfig{Site: site, NumSats: 24, MinElevation: 5., Seed: 3})
+	idx, gdop, err = SelectSubset(sc.SatDatas, site, 8)
+	if err != nil || len(idx) != 8 || math.IsInf(gdop, 0) {
+		t.Errorf("greedy: idx=%v, gdop=%f, err=%v", idx, gdop, err)
+	}
+	all, _ := CalcDOP(site, sc.SatDatas)
+	if gdop < all.GDOP {
+		t.Errorf("subset GDOP=%f below that of all=%f", gdop, all.GDOP)
+	}
+
+	if _, _, err := SelectSubset(sc.SatDatas, site, 3); !errors.Is(err, ErrTooFewSats) {
+		t.Errorf("get err=%v, want %v", err, ErrTooFewSats)
+	}
+}
+
+// TestSelectSubsetSingular checks that the singular subsets are avoided.
+func TestSelectSubsetSingular(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	// six satellites at the same elevation and two at the zenith region:
+	// any subset of the coplanar satellites only is singular
+	sats := make([][3]float64, 8)
+	for i := range 6 {
+		sats[i] = satOnSky(site, siteRotation(site), float64(i)*math.Pi/3., math.Pi/6., 26560e3)
+	}
+	sats[6] = satOnSky(site, siteRotation(site), 0.3, 1.4, 26560e3)
+	sats[7] = satOnSky(site, siteRotation(site), 2.3, 1.2, 26560e3)
+	satDatas := ComputePseudoranges(site, 0, sats, nil)
+
+	for _, f := range []func([]SatData, [3]float64, int) ([]int, float64){selectExhaustive, selectGreedy} {
+		idx, gdop := f(satDatas, site, 5)
+		if math.IsInf(gdop, 0) || !(idx[4] >= 6) {
+			t.Errorf("singular subset selected: idx=%v, gdop=%f", idx, gdop)
+		}
+	}
+
+	if _, _, err := SelectSubset(satDatas[:6], site, 5); !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("get err=%v, want %v", err, ErrSingularGeometry)
+	}
+}
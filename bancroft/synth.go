@@ -0,0 +1,118 @@
+package bancroft
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// ScenarioConfig defines the synthetic satellite constellation generated by
+// GenerateScenario.
+type ScenarioConfig struct {
+	// Site is the receiver position (ECEF, m), and Dt is the receiver clock
+	// bias (s).
+	Site [3]float64
+	Dt   float64
+
+	// NumSats is the number of satellites.
+	NumSats int
+
+	// MinElevation is the minimum elevation angle (deg) of the satellites.
+	MinElevation float64
+
+	// Cluster is the half width (deg) of the region in azimuth and elevation
+	// where the satellites are clustered to make a weak geometry.
+	// The satellites are distributed over the sky if zero.
+	Cluster float64
+
+	// NoiseSigma is the standard deviation (m) of the Gaussian noise added to
+	// the pseudoranges.
+	NoiseSigma float64
+
+	// OrbitRadius is the radius (m) of the satellite orbits.
+	// 26560 km (GPS) is used if zero.
+	OrbitRadius float64
+
+	// Seed is the seed of the random number generator.
+	Seed uint64
+}
+
+// Scenario is the synthetic data generated by GenerateScenario.
+type Scenario struct {
+	SatDatas []SatData
+
+	// ground truth
+	Site [3]float64
+	Dt   float64
+
+	// azimuth and elevation angles (rad) of the satellites
+	Az, El []float64
+}
+
+// GenerateScenario generates the satellites seen from the site, and the
+// pseudoranges computed by ComputePseudoranges with the noise.
+// The same seed gives the same scenario.
+func GenerateScenario(cfg ScenarioConfig) Scenario {
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+
+	R := cfg.OrbitRadius
+	if R == 0 {
+		R = 26560e3
+	}
+
+	rot := siteRotation(cfg.Site)
+	elMin := coord.Deg2Rad(cfg.MinElevation)
+
+	// center of the cluster
+	cluster := coord.Deg2Rad(cfg.Cluster)
+	az0 := 2. * math.Pi * rng.Float64()
+	el0 := elMin + cluster + (math.Pi/2-elMin-cluster)*rng.Float64()
+
+	sc := Scenario{Site: cfg.Site, Dt: cfg.Dt}
+	sats := make([][3]float64, cfg.NumSats)
+	extra := make([]float64, cfg.NumSats)
+	for i := range cfg.NumSats {
+		var az, el float64
+		if cluster > 0 {
+			az = az0 + cluster*(2.*rng.Float64()-1.)
+			el = math.Max(elMin, math.Min(math.Pi/2, el0+cluster*(2.*rng.Float64()-1.)))
+		} else {
+			// uniform over the sky above the elevation mask
+			az = 2. * math.Pi * rng.Float64()
+			el = math.Asin(math.Sin(elMin) + (1.-math.Sin(elMin))*rng.Float64())
+		}
+		sc.Az = append(sc.Az, math.Mod(az+2.*math.Pi, 2.*math.Pi))
+		sc.El = append(sc.El, el)
+
+		sats[i] = satOnSky(cfg.Site, rot, az, el, R)
+		extra[i] = cfg.NoiseSigma * rng.NormFloat64()
+	}
+
+	sc.SatDatas = ComputePseudoranges(cfg.Site, cfg.Dt, sats, extra)
+
+	return sc
+}
+
+// satOnSky returns the position (ECEF, m) of the satellite at the azimuth az
+// and the elevation el (rad) seen from the site, on the sphere of the radius
+// R (m). rot is the ENU rotation matrix at the site.
+func satOnSky(site [3]float64, rot [3][3]float64, az, el, R float64) [3]float64 {
+	// line of sight in ECEF
+	sinEl, cosEl := math.Sincos(el)
+	sinAz, cosAz := math.Sincos(az)
+	u := coord.RotateT(rot, [3]float64{cosEl * sinAz, cosEl * cosAz, sinEl})
+
+	// distance to the sphere
+	ru := site[0]*u[0] + site[1]*u[1] + site[2]*u[2]
+	r2 := sqr(site[0]) + sqr(site[1]) + sqr(site[2])
+	d := -ru + math.Sqrt(ru*ru-r2+R*R)
+
+	return [3]float64{site[0] + d*u[0], site[1] + d*u[1], site[2] + d*u[2]}
+}
+
+// siteRotation returns the ENU rotation matrix at the site (ECEF, m).
+func siteRotation(site [3]float64) [3][3]float64 {
+	lat, lon, _ := coord.XYZToLLH(site[0], site[1], site[2])
+	return coord.ENURotation(lat, lon)
+}
@@ -0,0 +1,76 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCalcPosSynthetic solves the synthetic scenarios.
+func TestCalcPosSynthetic(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+	type testCase struct {
+		name   string
+		cfg    ScenarioConfig
+		posTol float64 // m
+		dtTol  float64 // s
+	}
+
+	var tests []testCase
+	for n := 4; n <= 12; n++ {
+		tests = append(tests, testCase{
+			name:   "clean",
+			cfg:    ScenarioConfig{NumSats: n, MinElevation: 10., Seed: uint64(n)},
+			posTol: 1e-4,
+			dtTol:  1e-12,
+		})
+	}
+	tests = append(tests,
+		testCase{"noisy, n=8", ScenarioConfig{NumSats: 8, MinElevation: 10., NoiseSigma: 1., Seed: 1}, 20., 1e-7},
+		testCase{"noisy, n=12", ScenarioConfig{NumSats: 12, MinElevation: 10., NoiseSigma: 1., Seed: 2}, 10., 5e-8},
+		testCase{"clustered, clean", ScenarioConfig{NumSats: 6, MinElevation: 10., Cluster: 15., Seed: 3}, 1e-3, 1e-11},
+		testCase{"clustered, noisy", ScenarioConfig{NumSats: 6, MinElevation: 10., Cluster: 15., NoiseSigma: 0.1, Seed: 4}, 500., 2e-6},
+	)
+
+	for _, tt := range tests {
+		tt.cfg.Site = site
+		tt.cfg.Dt = 2e-4
+		sc := GenerateScenario(tt.cfg)
+
+		x, y, z, dt, err := CalcPos(sc.SatDatas)
+		if err != nil {
+			t.Errorf("%s, n=%d: %v", tt.name, tt.cfg.NumSats, err)
+			continue
+		}
+
+		d := math.Sqrt(sqr(x-site[0]) + sqr(y-site[1]) + sqr(z-site[2]))
+		if d > tt.posTol || math.Abs(dt-sc.Dt) > tt.dtTol {
+			t.Errorf("%s, n=%d: pos error=%e m (tol=%e), dt error=%e s (tol=%e)", tt.name, tt.cfg.NumSats, d, tt.posTol, dt-sc.Dt, tt.dtTol)
+		}
+	}
+}
+
+// TestGenerateScenario checks the generated satellites are above the mask,
+// and the generation is reproducible.
+func TestGenerateScenario(t *testing.T) {
+	cfg := ScenarioConfig{
+		Site:         [3]float64{-3721695.1985, 3545492.6126, 3763541.7139},
+		NumSats:      20,
+		MinElevation: 15.,
+		Seed:         42,
+	}
+	sc1 := GenerateScenario(cfg)
+	sc2 := GenerateScenario(cfg)
+
+	for i, s := range sc1.SatDatas {
+		if s != sc2.SatDatas[i] {
+			t.Errorf("not reproducible: %+v, %+v", s, sc2.SatDatas[i])
+		}
+		if sc1.El[i] < 15.*math.Pi/180.-1e-12 {
+			t.Errorf("satellite below the mask: el=%f", sc1.El[i])
+		}
+		if r := math.Sqrt(sqr(s.X) + sqr(s.Y) + sqr(s.Z)); math.Abs(r-26560e3) > 1e-3 {
+			t.Errorf("invalid orbit radius: %f", r)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TDCPData defines the input data of a satellite for CalcTDCP.
+type TDCPData struct {
+	ID gnss.SatID // satellite ID (optional)
+
+	// Sat1 and Sat2 are the satellite positions (ECEF, m) at the
+	// transmission times of the first and the second epochs.
+	Sat1, Sat2 [3]float64
+
+	// L1 and L2 are the carrier phases (m) of the first and the second
+	// epochs, i.e., the cycles multiplied by the wavelength.
+	L1, L2 float64
+}
+
+// TDCPOpts defines options for CalcTDCP.
+type TDCPOpts struct {
+	// SlipThreshold (m) is the threshold of the residuals above which the
+	// satellite is regarded to have a cycle slip and is excluded.
+	// 0.05 m is used if zero.
+	SlipThreshold float64
+
+	// MaxIter is the maximum number of iterations. 5 is used if zero.
+	MaxIter int
+}
+
+// TDCPResult stores the result of CalcTDCP.
+type TDCPResult struct {
+	// Delta is the change of the receiver position (ECEF, m) from the first
+	// to the second epoch.
+	Delta [3]float64
+
+	// ClockDelta is the change of the receiver clock bias (s) as dt of
+	// CalcPos, i.e., the clock drift multiplied by the interval.
+	ClockDelta float64
+
+	// NumSats is the number of satellites used, and Residuals (m) are
+	// those of the satellites used in the order of the input.
+	NumSats   int
+	Residuals []float64
+
+	// Slips stores the labels (see SatData.Label) of the satellites
+	// excluded by the residuals.
+	Slips []string
+}
+
+// CalcTDCP estimates the change of the receiver position and of the clock
+// bias between two epochs from the time-differenced carrier phases (TDCP),
+// which have millimeter noise and are free of the ambiguities unless a cycle
+// slip occurs.
+//
+// The time-differenced carrier phase is modeled as
+//
+//	L2_i - L1_i = |sat2_i - (pos + delta)| - |sat1_i - pos| + db
+//
+// where pos (ECEF, m) is the approximate receiver position at the first
+// epoch, and db = -c*(dt2-dt1) is the change of the clock term (see
+// SatData). The equations are linearized with the design matrix of the
+// pseudoranges (see DesignMatrix), and the satellite with the largest
+// residual above opts.SlipThreshold is excluded one by one while five or
+// more satellites remain.
+//
+// An error is returned with the result if a residual above the threshold
+// remains.
+func CalcTDCP(pos [3]float64, sats []TDCPData, opts TDCPOpts) (res TDCPResult, err error) {
+	if opts.SlipThreshold == 0 {
+		opts.SlipThreshold = 0.05
+	}
+	if opts.MaxIter == 0 {
+		opts.MaxIter = 5
+	}
+
+	used := make([]int, len(sats))
+	for i := range used {
+		used[i] = i
+	}
+
+	for {
+		state, v, err := solveTDCP(pos, sats, used, opts.MaxIter)
+		if err != nil {
+			return res, err
+		}
+		res.Delta = [3]float64{state[0], state[1], state[2]}
+		res.ClockDelta = -state[3] / LightVelocity
+		res.NumSats = len(used)
+		res.Residuals = v
+
+		worst := 0
+		for k := range v {
+			if math.Abs(v[k]) > math.Abs(v[worst]) {
+				worst = k
+			}
+		}
+		if math.Abs(v[worst]) <= opts.SlipThreshold {
+			return res, nil
+		}
+		if len(used) < 6 {
+			return res, fmt.Errorf("cycle slip not excludable: n=%d, residual=%.3f, sat=%s", len(used), v[worst], tdcpLabel(sats, used[worst]))
+		}
+
+		res.Slips = append(res.Slips, tdcpLabel(sats, used[worst]))
+		used = append(used[:worst], used[worst+1:]...)
+	}
+}
+
+// solveTDCP solves the position change and the clock term (m) by the
+// satellites used, and returns the residuals (m).
+func solveTDCP(pos [3]float64, sats []TDCPData, used []int, maxIter int) (state, v []float64, err error) {
+	n := len(used)
+	if n < 4 {
+		return nil, nil, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	}
+
+	A := mat.NewDense(n, 4, nil)
+	y := mat.NewVecDense(n, nil)
+	state = make([]float64, 4)
+	v = make([]float64, n)
+
+	for range maxIter {
+		pos2 := [3]float64{pos[0] + state[0], pos[1] + state[1], pos[2] + state[2]}
+		for k, i := range used {
+			s := sats[i]
+			h, rho2 := designRow(pos2, SatData{X: s.Sat2[0], Y: s.Sat2[1], Z: s.Sat2[2]})
+			_, rho1 := designRow(pos, SatData{X: s.Sat1[0], Y: s.Sat1[1], Z: s.Sat1[2]})
+			A.SetRow(k, []float64{h[0], h[1], h[2], 1.})
+			y.SetVec(k, (s.L2-s.L1)-(rho2-rho1+state[3]))
+		}
+
+		var dx mat.VecDense
+		if err = dx.SolveVec(A, y); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrSingularGeometry, err)
+		}
+
+		var norm float64
+		for j := range 4 {
+			state[j] += dx.AtVec(j)
+			norm += sqr(dx.AtVec(j))
+		}
+
+		// residuals of the updated state
+		var model mat.VecDense
+		model.MulVec(A, &dx)
+		for k := range n {
+			v[k] = y.AtVec(k) - model.AtVec(k)
+		}
+
+		if math.Sqrt(norm) < 1e-6 {
+			return state, v, nil
+		}
+	}
+
+	return state, v, fmt.Errorf("not converged: maxIter=%d", maxIter)
+}
+
+// tdcpLabel returns the label of the i-th satellite.
+func tdcpLabel(sats []TDCPData, i int) string {
+	return SatData{ID: sats[i].ID}.Label(i)
+}
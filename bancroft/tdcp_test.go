@@ -0,0 +1,70 @@
+package bancroft
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestCalcTDCP checks the position change and the clock change are
+// recovered from the simulated carrier phases, and a cycle slip is excluded.
+func TestCalcTDCP(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+
+	delta := [3]float64{0.8, -0.3, 0.15} // m
+	const dt1, dt2 = 1e-4, 1e-4 + 2e-9   // s
+	pos2 := [3]float64{site[0] + delta[0], site[1] + delta[1], site[2] + delta[2]}
+
+	sats := make([]TDCPData, len(sc.SatDatas))
+	for i, s := range sc.SatDatas {
+		sat1 := [3]float64{s.X, s.Y, s.Z}
+		sat2 := [3]float64{s.X + 2000. - 500.*float64(i), s.Y + 300.*float64(i), s.Z - 1000.}
+		ambiguity := 0.19 * float64(1000+17*i) // m
+		sats[i] = TDCPData{
+			ID:   satIDs[i],
+			Sat1: sat1,
+			Sat2: sat2,
+			L1:   ComputePseudorange(site, dt1, sat1) + ambiguity,
+			L2:   ComputePseudorange(pos2, dt2, sat2) + ambiguity,
+		}
+	}
+
+	check := func(res TDCPResult) {
+		t.Helper()
+		for k := range 3 {
+			if math.Abs(res.Delta[k]-delta[k]) > 1e-4 {
+				t.Errorf("delta: get %v, want %v", res.Delta, delta)
+				break
+			}
+		}
+		if math.Abs(res.ClockDelta-(dt2-dt1))*LightVelocity > 1e-4 {
+			t.Errorf("clock delta: get %e, want %e", res.ClockDelta, dt2-dt1)
+		}
+	}
+
+	res, err := CalcTDCP(site, sats, TDCPOpts{})
+	if err != nil {
+		t.Fatalf("CalcTDCP: %v", err)
+	}
+	check(res)
+	if res.NumSats != len(sats) || len(res.Slips) != 0 {
+		t.Errorf("nsat=%d, slips=%v", res.NumSats, res.Slips)
+	}
+
+	// a cycle slip of two cycles of L1
+	sats[3].L2 += 2 * 0.1903
+	res, err = CalcTDCP(site, sats, TDCPOpts{})
+	if err != nil {
+		t.Fatalf("CalcTDCP: %v", err)
+	}
+	check(res)
+	if res.NumSats != len(sats)-1 || !slices.Equal(res.Slips, []string{satIDs[3].String()}) {
+		t.Errorf("nsat=%d, slips=%v", res.NumSats, res.Slips)
+	}
+
+	// not excludable with five satellites
+	if _, err := CalcTDCP(site, sats[:5], TDCPOpts{}); err == nil {
+		t.Errorf("no error for the slip with five satellites")
+	}
+}
@@ -0,0 +1,74 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SatVelData defines the input data for CalcVel.
+// X, Y, Z (m) and VX, VY, VZ (m/s) are the satellite position and velocity in
+// the ECEF frame, and RangeRate (m/s) is the pseudorange rate.
+type SatVelData struct {
+	X, Y, Z    float64 // satellite position (m)
+	VX, VY, VZ float64 // satellite velocity (m/s)
+	RangeRate  float64 // pseudorange rate (m/s)
+}
+
+// DopplerToRangeRate converts the Doppler shift (Hz) into the pseudorange
+// rate (m/s) for the carrier frequency freq (Hz).
+//
+// The Doppler shift is positive for an approaching satellite, while the range
+// rate is negative, i.e., RangeRate = -Doppler*c/freq.
+func DopplerToRangeRate(doppler, freq float64) float64 {
+	return -doppler * LightVelocity / freq
+}
+
+// CalcVel estimates the receiver velocity (m/s) and the clock drift (s/s) from
+// the pseudorange rates at the receiver position pos (ECEF, m).
+//
+// The pseudorange rate is modeled as
+//
+//	RangeRate_i = e_i . (vsat_i - vrcv) - c*ddt
+//
+// where e_i is the line-of-sight unit vector from the receiver to the
+// satellite. The sign of the clock drift ddt follows dt of CalcPos (see
+// SatData). The residuals (m/s) are RangeRate minus the modeled values.
+func CalcVel(pos [3]float64, satDatas []SatVelData) (vx, vy, vz, ddt float64, residuals []float64, err error) {
+	n := len(satDatas)
+	if n < 4 {
+		return 0., 0., 0., 0., nil, fmt.Errorf("%w: n=%d", ErrTooFewSats, n)
+	}
+
+	A := mat.NewDense(n, 4, nil)
+	b := mat.NewVecDense(n, nil)
+	for i, s := range satDatas {
+		// same design matrix as the position: d(rho)/d(rcv) = -e
+		h, _ := designRow(pos, SatData{X: s.X, Y: s.Y, Z: s.Z})
+		A.SetRow(i, []float64{h[0], h[1], h[2], 1.})
+		b.SetVec(i, s.RangeRate+(h[0]*s.VX+h[1]*s.VY+h[2]*s.VZ))
+	}
+
+	var sol mat.VecDense
+	if err = sol.SolveVec(A, b); err != nil {
+		return 0., 0., 0., 0., nil, err
+	}
+
+	var model mat.VecDense
+	model.MulVec(A, &sol)
+	residuals = make([]float64, n)
+	for i := range n {
+		residuals[i] = b.AtVec(i) - model.AtVec(i)
+	}
+
+	return sol.AtVec(0), sol.AtVec(1), sol.AtVec(2), -sol.AtVec(3) / LightVelocity, residuals, nil
+}
+
+// lineOfSight returns the unit vector from the receiver position pos to the
+// satellite position (sx, sy, sz).
+func lineOfSight(pos [3]float64, sx, sy, sz float64) (e [3]float64) {
+	dx, dy, dz := sx-pos[0], sy-pos[1], sz-pos[2]
+	rho := math.Sqrt(dx*dx + dy*dy + dz*dz)
+	return [3]float64{dx / rho, dy / rho, dz / rho}
+}
@@ -0,0 +1,42 @@
+package bancroft
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCalcVel checks the velocity and the clock drift are recovered from the
+// simulated range rates.
+func TestCalcVel(t *testing.T) {
+	pos := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	vrcv := [3]float64{10., -5., 2.} // m/s
+	const ddt = 1e-8                 // s/s
+
+	satDatas := testSatDatas()
+	sv := make([]SatVelData, len(satDatas))
+	for i, s := range satDatas {
+		// arbitrary satellite velocities of ~3 km/s
+		sv[i] = SatVelData{X: s.X, Y: s.Y, Z: s.Z, VX: 3000. - float64(i)*500., VY: float64(i) * 300., VZ: -1000.}
+
+		e := lineOfSight(pos, s.X, s.Y, s.Z)
+		sv[i].RangeRate = e[0]*(sv[i].VX-vrcv[0]) + e[1]*(sv[i].VY-vrcv[1]) + e[2]*(sv[i].VZ-vrcv[2]) - ddt*LightVelocity
+	}
+
+	vx, vy, vz, ddt1, res, err := CalcVel(pos, sv)
+	if err != nil {
+		t.Fatalf("CalcVel: %v", err)
+	}
+	if math.Abs(vx-vrcv[0]) > 1e-6 || math.Abs(vy-vrcv[1]) > 1e-6 || math.Abs(vz-vrcv[2]) > 1e-6 || math.Abs(ddt1-ddt) > 1e-15 {
+		t.Errorf("get (%f, %f, %f, %e), want (%f, %f, %f, %e)", vx, vy, vz, ddt1, vrcv[0], vrcv[1], vrcv[2], ddt)
+	}
+	for i, v := range res {
+		if math.Abs(v) > 1e-6 {
+			t.Errorf("non-zero residual: i=%d, v=%e", i, v)
+		}
+	}
+
+	// Doppler of an approaching satellite gives a negative range rate
+	if rr := DopplerToRangeRate(1000., 1575.42e6); rr >= 0 || math.Abs(rr+190.29) > 0.01 {
+		t.Errorf("DopplerToRangeRate: get %f, want %f", rr, -190.29)
+	}
+}
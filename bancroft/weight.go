@@ -0,0 +1,71 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// DefaultElevA and DefaultElevB are the coefficients (m) of the elevation
+// model of WeightModel used if both are zero.
+const (
+	DefaultElevA = 0.3
+	DefaultElevB = 0.3
+)
+
+// WeightModel defines the noise model of the pseudoranges for the weights
+// 1/sigma^2 of the observations, e.g., for CalcWDOP and ChiSquareTest.
+//
+// The elevation model is
+//
+//	sigma^2 = ElevA^2 + ElevB^2/sin^2(el)
+//
+// and the carrier-to-noise model for C/N0 in dB-Hz is
+//
+//	sigma^2 = CN0A + CN0B*10^(-C/N0/10)
+//
+// If the C/N0 model is enabled, the larger of the two is adopted for the
+// observations with SatData.CN0, and the elevation model is used for those
+// without it.
+type WeightModel struct {
+	// ElevA and ElevB are the coefficients (m) of the elevation model.
+	// DefaultElevA and DefaultElevB are used if both are zero.
+	ElevA, ElevB float64
+
+	// CN0A (m^2) and CN0B (m^2 Hz) are the coefficients of the C/N0 model.
+	// The model is disabled if both are zero.
+	CN0A, CN0B float64
+}
+
+// Variance returns sigma^2 (m^2) of the pseudorange observed at the
+// elevation angle el (rad) with the C/N0 cn0 (dB-Hz, 0 if unknown).
+func (m WeightModel) Variance(el, cn0 float64) float64 {
+	a, b := m.ElevA, m.ElevB
+	if a == 0 && b == 0 {
+		a, b = DefaultElevA, DefaultElevB
+	}
+	v := sqr(a) + sqr(b)/sqr(math.Sin(el))
+
+	if cn0 > 0 && (m.CN0A != 0 || m.CN0B != 0) {
+		v = math.Max(v, m.CN0A+m.CN0B*math.Pow(10., -cn0/10.))
+	}
+	return v
+}
+
+// Weights returns the weights 1/sigma^2 (1/m^2) of the satellites by the
+// model, with the elevation angles seen from pos (ECEF, m).
+//
+// ErrBadInput is returned if a satellite is at or below the horizon, where
+// the elevation model is not defined.
+func (m WeightModel) Weights(satDatas []SatData, pos [3]float64) ([]float64, error) {
+	w := make([]float64, len(satDatas))
+	for i, s := range satDatas {
+		_, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z})
+		if !(el > 0) {
+			return nil, fmt.Errorf("%w: satellite below the horizon: sat=%s, el=%.1f", ErrBadInput, s.Label(i), coord.Rad2Deg(el))
+		}
+		w[i] = 1. / m.Variance(el, s.CN0)
+	}
+	return w, nil
+}
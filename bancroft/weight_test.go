@@ -0,0 +1,61 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestWeightModel checks the variances of the elevation and C/N0 models, and
+// the fallback to the elevation model without C/N0.
+func TestWeightModel(t *testing.T) {
+	m := WeightModel{CN0A: 0.1, CN0B: 1e4}
+	zenith := math.Pi / 2.
+	el30 := math.Pi / 6.
+
+	for _, tt := range []struct {
+		el, cn0 float64
+		want    float64
+	}{
+		{zenith, 0., 0.18},      // no C/N0
+		{el30, 0., 0.09 + 0.36}, // no C/N0
+		{zenith, 30., 10.1},     // C/N0 model is larger
+		{zenith, 50., 0.2},      // C/N0 model is larger
+		{el30, 50., 0.45},       // elevation model is larger
+	} {
+		if got := m.Variance(tt.el, tt.cn0); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("el=%f, cn0=%f: get %f, want %f", tt.el, tt.cn0, got, tt.want)
+		}
+	}
+
+	// C/N0 ignored without the C/N0 model
+	if got := (WeightModel{}).Variance(zenith, 30.); math.Abs(got-0.18) > 1e-12 {
+		t.Errorf("C/N0 model disabled: get %f, want %f", got, 0.18)
+	}
+
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 8, MinElevation: 10., Seed: 1})
+	satDatas := sc.SatDatas
+	satDatas[0].CN0 = 25.
+	satDatas[1].CN0 = 50.
+
+	w, err := m.Weights(satDatas, site)
+	if err != nil {
+		t.Fatalf("Weights: %v", err)
+	}
+	for i, el := range sc.El {
+		want := 1. / m.Variance(el, satDatas[i].CN0)
+		if math.Abs(w[i]-want) > 1e-12*want {
+			t.Errorf("sat %d: get %f, want %f", i, w[i], want)
+		}
+	}
+	if w[0] >= 1./m.Variance(sc.El[0], 0.) {
+		t.Errorf("low C/N0 not down-weighted: w=%f", w[0])
+	}
+
+	// satellite below the horizon
+	satDatas[2].X, satDatas[2].Y, satDatas[2].Z = -satDatas[2].X, -satDatas[2].Y, -satDatas[2].Z
+	if _, err := m.Weights(satDatas, site); !errors.Is(err, ErrBadInput) {
+		t.Errorf("get err=%v, want %v", err, ErrBadInput)
+	}
+}
@@ -0,0 +1,128 @@
+package bancroft
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// ZTDOpts defines options for CalcPosZTD.
+type ZTDOpts struct {
+	// Mapping is the tropospheric mapping function of the elevation angle
+	// (rad). The wet mapping function of Model is used if nil.
+	Mapping func(el float64) float64
+
+	// Model is the mapping functions of tropo, e.g., tropo.MappingGMF, whose
+	// wet mapping function is evaluated at the epoch Epoch and the initial
+	// solution if Mapping is nil. tropo.SimpleMapping is used if zero.
+	Model tropo.Mapping
+	Epoch time.Time
+
+	// Sigma is the standard deviation (m) of the pseudoranges for the formal
+	// sigma of ZTD. 1 m is used if zero.
+	Sigma float64
+
+	// MaxCorrelation is the maximum absolute correlation of ZTD with the
+	// clock or the height above which ZTD is regarded as inseparable.
+	// 0.995 is used if zero.
+	MaxCorrelation float64
+}
+
+// ZTDResult stores the result of CalcPosZTD.
+type ZTDResult struct {
+	// position (m) and the receiver clock bias (s)
+	X, Y, Z, Dt float64
+
+	// ZTD is the zenith tropospheric delay (m), and ZTDSigma is its formal
+	// standard deviation (m).
+	ZTD, ZTDSigma float64
+
+	// CorrClock and CorrHeight are the correlation coefficients of ZTD with
+	// the clock bias and with the height.
+	CorrClock, CorrHeight float64
+
+	// Residuals are the pseudorange residuals (m) of the satellites.
+	Residuals []float64
+}
+
+// CalcPosZTD solves the position with the zenith tropospheric delay (ZTD) as
+// an additional unknown, i.e., the pseudoranges must not be corrected for
+// the troposphere. The pseudorange is modeled as
+//
+//	PR_i = |sat_i - rcv| - c*dt + m(el_i)*ZTD
+//
+// where m is opts.Mapping, or the wet mapping function of opts.Model. At
+// least five satellites are required, and the solution of CalcPos is used as
+// the initial value.
+//
+// ZTD is separated from the clock bias and the height only by the variety
+// of the elevation angles. ErrSingularGeometry is returned with the result
+// if the correlation of ZTD with either exceeds opts.MaxCorrelation, e.g.,
+// when all the satellites are at similar elevations.
+func CalcPosZTD(satDatas []SatData, opts ZTDOpts) (res ZTDResult, err error) {
+	if opts.Sigma == 0 {
+		opts.Sigma = 1.
+	}
+	if opts.MaxCorrelation == 0 {
+		opts.MaxCorrelation = 0.995
+	}
+
+	n := len(satDatas)
+	if n < 5 {
+		return res, fmt.Errorf("%w: n=%d, required=5", ErrTooFewSats, n)
+	}
+
+	x, y, z, dt, err := CalcPos(satDatas)
+	if err != nil {
+		return res, err
+	}
+	if opts.Mapping == nil {
+		lat, lon, h := coord.XYZToLLH(x, y, z)
+		opts.Mapping = opts.Model.Wet(opts.Epoch, lat, lon, h)
+	}
+
+	p := lsqProblem{satDatas: satDatas, clk: make([]int, n), nclk: 1, mapping: opts.Mapping}
+	state, chol, err := p.solve([]float64{x, y, z, -dt * LightVelocity, 0.}, 20, 1e-4)
+	if err != nil {
+		return res, fmt.Errorf("ZTD not estimable: %w", err)
+	}
+	Q, err := cofactor(chol)
+	if err != nil {
+		return res, err
+	}
+
+	res.X, res.Y, res.Z = state[0], state[1], state[2]
+	res.Dt = -state[3] / LightVelocity
+	res.ZTD = state[4]
+	res.ZTDSigma = opts.Sigma * math.Sqrt(Q.At(4, 4))
+
+	res.Residuals = make([]float64, n)
+	pos := [3]float64{res.X, res.Y, res.Z}
+	for i, s := range satDatas {
+		_, el := coord.AzEl(pos, [3]float64{s.X, s.Y, s.Z})
+		res.Residuals[i] = s.PR - (ComputePseudorange(pos, res.Dt, [3]float64{s.X, s.Y, s.Z}) + opts.Mapping(el)*res.ZTD)
+	}
+
+	// correlations with the clock term and the height
+	lat, lon, _ := coord.XYZToLLH(res.X, res.Y, res.Z)
+	up := coord.ENURotation(lat, lon)[2]
+	var varUp, covUp float64
+	for i := range 3 {
+		covUp += up[i] * Q.At(i, 4)
+		for j := range 3 {
+			varUp += up[i] * Q.At(i, j) * up[j]
+		}
+	}
+	// the clock term b = -c*dt is negated to the clock bias dt
+	res.CorrClock = -Q.At(3, 4) / math.Sqrt(Q.At(3, 3)*Q.At(4, 4))
+	res.CorrHeight = covUp / math.Sqrt(varUp*Q.At(4, 4))
+
+	if math.Abs(res.CorrClock) > opts.MaxCorrelation || math.Abs(res.CorrHeight) > opts.MaxCorrelation {
+		return res, fmt.Errorf("%w: ZTD not separable: correlation with clock=%.4f, height=%.4f", ErrSingularGeometry, res.CorrClock, res.CorrHeight)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package bancroft
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// TestCalcPosZTD checks the zenith delay is recovered with the position, and
+// the inseparable geometry is rejected.
+func TestCalcPosZTD(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	const ztd = 2.4 // m
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 10, MinElevation: 5., Seed: 2})
+
+	sats := make([][3]float64, len(sc.SatDatas))
+	delays := make([]float64, len(sats))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+		delays[i] = tropo.SimpleMapping(sc.El[i]) * ztd
+	}
+	satDatas := ComputePseudoranges(site, 1e-4, sats, delays)
+
+	res, err := CalcPosZTD(satDatas, ZTDOpts{})
+	if err != nil {
+		t.Fatalf("CalcPosZTD: %v", err)
+	}
+	if math.Abs(res.ZTD-ztd) > 1e-3 {
+		t.Errorf("ZTD: get %f, want %f", res.ZTD, ztd)
+	}
+	if d := math.Sqrt(sqr(res.X-site[0]) + sqr(res.Y-site[1]) + sqr(res.Z-site[2])); d > 1e-3 {
+		t.Errorf("position error: %f", d)
+	}
+	if math.Abs(res.Dt-1e-4)*LightVelocity > 1e-3 {
+		t.Errorf("clock: get %e, want %e", res.Dt, 1e-4)
+	}
+	if !(res.ZTDSigma > 0) || math.Abs(res.CorrClock) >= 1 || math.Abs(res.CorrHeight) >= 1 {
+		t.Errorf("sigma=%f, corr clock=%f, height=%f", res.ZTDSigma, res.CorrClock, res.CorrHeight)
+	}
+
+	// the formal sigma scales with the sigma of the pseudoranges
+	res2, _ := CalcPosZTD(satDatas, ZTDOpts{Sigma: 2.})
+	if math.Abs(res2.ZTDSigma-2.*res.ZTDSigma) > 1e-9 {
+		t.Errorf("sigma: get %f, want %f", res2.ZTDSigma, 2.*res.ZTDSigma)
+	}
+
+	// satellites at similar elevations
+	similar := make([][3]float64, 6)
+	for i := range similar {
+		az := coord.Deg2Rad(60. * float64(i))
+		el := coord.Deg2Rad(40. + 0.2*float64(i))
+		r := 22000e3
+		similar[i] = coord.ENUToECEF(site, [3]float64{r * math.Cos(el) * math.Sin(az), r * math.Cos(el) * math.Cos(az), r * math.Sin(el)})
+	}
+	if _, err := CalcPosZTD(ComputePseudoranges(site, 0., similar, nil), ZTDOpts{}); !errors.Is(err, ErrSingularGeometry) {
+		t.Errorf("similar elevations: get err=%v, want %v", err, ErrSingularGeometry)
+	}
+
+	if _, err := CalcPosZTD(satDatas[:4], ZTDOpts{}); !errors.Is(err, ErrTooFewSats) {
+		t.Errorf("four satellites: get err=%v, want %v", err, ErrTooFewSats)
+	}
+}
+
+// TestCalcPosZTDModel checks the zenith delay mapped by the wet mapping
+// function of GMF is recovered with the model selected.
+func TestCalcPosZTDModel(t *testing.T) {
+	site := [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+	lat, lon, h := coord.XYZToLLH(site[0], site[1], site[2])
+	epoch := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	const ztd = 2.4 // m
+	sc := GenerateScenario(ScenarioConfig{Site: site, NumSats: 10, MinElevation: 5., Seed: 2})
+
+	sats := make([][3]float64, len(sc.SatDatas))
+	delays := make([]float64, len(sats))
+	for i, s := range sc.SatDatas {
+		sats[i] = [3]float64{s.X, s.Y, s.Z}
+		_, mw := tropo.GMF(epoch, lat, lon, h, sc.El[i])
+		delays[i] = mw * ztd
+	}
+	satDatas := ComputePseudoranges(site, 1e-4, sats, delays)
+
+	res, err := CalcPosZTD(satDatas, ZTDOpts{Model: tropo.MappingGMF, Epoch: epoch})
+	if err != nil {
+		t.Fatalf("CalcPosZTD: %v", err)
+	}
+	if math.Abs(res.ZTD-ztd) > 1e-3 {
+		t.Errorf("ZTD: get %f, want %f", res.ZTD, ztd)
+	}
+	if d := math.Sqrt(sqr(res.X-site[0]) + sqr(res.Y-site[1]) + sqr(res.Z-site[2])); d > 1e-2 {
+		t.Errorf("position error: %f", d)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Package clk reads the RINEX clock files of the precise clock products.
+
+The clocks are converted into seconds, and the epochs are in the time system
+of the file (see Header.TimeSystem), represented as time.Time in UTC without
+the leap seconds, as the other packages of the module.
+*/
+package clk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+var (
+	// ErrFormat is returned when the file is not a valid RINEX clock file.
+	// The errors of the parser are wrapped with the line number.
+	ErrFormat = errors.New("invalid rinex clock format")
+
+	// ErrNoClock is returned when the clock cannot be interpolated at the
+	// epoch, e.g., for the satellite not in the file, the epoch out of the
+	// file, or the samples too far from the epoch.
+	ErrNoClock = errors.New("no valid clock")
+)
+
+// Header stores the header of a RINEX clock file.
+type Header struct {
+	// Version is the format version, e.g., 3.0, and SatSystem is the
+	// satellite system, e.g., 'G' or 'M' for the mixed file.
+	Version   float64
+	SatSystem byte
+
+	// TimeSystem is, e.g., "GPS", and LeapSeconds is the number of the leap
+	// seconds if given.
+	TimeSystem  string
+	LeapSeconds int
+
+	// AC is the 3-character analysis center, e.g., "IGS", and ACName is its
+	// full name.
+	AC     string
+	ACName string
+
+	// Types is the types of the solutions in the file, e.g., "AS" for the
+	// satellites and "AR" for the receivers.
+	Types []string
+
+	// Frame is the terrestrial reference frame of the stations, and Stations
+	// is the stations of the solution.
+	Frame    string
+	Stations []Station
+
+	// Sats is the list of the satellites of the solution, e.g., "G01".
+	Sats []string
+
+	// Comments stores the comment lines.
+	Comments []string
+}
+
+// Station is a station of the solution.
+type Station struct {
+	Name   string
+	Number string     // DOMES number
+	Pos    [3]float64 // position (ECEF, m)
+}
+
+// Record is the clock bias of a satellite or a receiver at an epoch.
+type Record struct {
+	Epoch time.Time
+	Bias  float64 // clock bias (s)
+
+	// Sigma is the standard deviation (s) of Bias if HasSigma.
+	Sigma    float64
+	HasSigma bool
+}
+
+// File stores the contents of a RINEX clock file.
+type File struct {
+	Header Header
+
+	// Sats and Stations store the "AS" and the "AR" records of the
+	// satellites and the receivers in the order of the epochs.
+	Sats     map[string][]Record
+	Stations map[string][]Record
+
+	// Interval is the smallest sampling interval of the satellite clocks.
+	Interval time.Duration
+}
+
+// ClockOpts defines options for File.Clock.
+type ClockOpts struct {
+	// MaxGap is the maximum distance of the bracketing samples from the
+	// epoch. File.Interval is used if zero, i.e., the epoch adjacent to a
+	// missing sample is rejected.
+	MaxGap time.Duration
+}
+
+// Clock returns the clock bias (s) of the satellite id at the epoch t
+// interpolated linearly between the bracketing samples. The clock is not
+// extrapolated, and ErrNoClock is returned if either of the bracketing
+// samples is farther than opts.MaxGap from t.
+//
+// The sign is that of the file, i.e., the correction of the pseudorange is
+// c*Clock to be added (see ClockMeters).
+func (f *File) Clock(id string, t time.Time, opts ClockOpts) (float64, error) {
+	recs, ok := f.Sats[id]
+	if !ok {
+		return 0., fmt.Errorf("%w: satellite not found: %s", ErrNoClock, id)
+	}
+	return interpolate(id, recs, t, opts.MaxGap, f.Interval)
+}
+
+// ClockMeters returns the clock of Clock in meters, i.e., c*Clock, which is
+// added to the pseudorange as the satellite clock correction.
+func (f *File) ClockMeters(id string, t time.Time, opts ClockOpts) (float64, error) {
+	clk, err := f.Clock(id, t, opts)
+	return clk * lightVelocity, err
+}
+
+// interpolate interpolates the records of id linearly at t.
+func interpolate(id string, recs []Record, t time.Time, maxGap, interval time.Duration) (float64, error) {
+	if maxGap == 0 {
+		maxGap = interval
+	}
+
+	k := sort.Search(len(recs), func(i int) bool { return !recs[i].Epoch.Before(t) })
+	if k < len(recs) && recs[k].Epoch.Equal(t) {
+		return recs[k].Bias, nil
+	}
+	if k == 0 || k == len(recs) {
+		return 0., fmt.Errorf("%w: %s: no sample around %v", ErrNoClock, id, t)
+	}
+
+	r0, r1 := recs[k-1], recs[k]
+	if gp, gn := t.Sub(r0.Epoch), r1.Epoch.Sub(t); gp > maxGap || gn > maxGap {
+		return 0., fmt.Errorf("%w: %s: samples too far from %v: before=%v, after=%v, limit=%v", ErrNoClock, id, t, gp, gn, maxGap)
+	}
+
+	a := t.Sub(r0.Epoch).Seconds() / r1.Epoch.Sub(r0.Epoch).Seconds()
+	return r0.Bias + a*(r1.Bias-r0.Bias), nil
+}
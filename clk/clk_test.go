@@ -0,0 +1,57 @@
+package clk
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestClock checks the linear interpolation and the rejection of the gaps.
+func TestClock(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	// sample
+	if clk, err := f.Clock("G14", t0, ClockOpts{}); err != nil || clk != f.Sats["G14"][0].Bias {
+		t.Errorf("G14: clk=%e, err=%v", clk, err)
+	}
+
+	// middle of the samples
+	r0, r1 := f.Sats["G02"][1], f.Sats["G02"][2]
+	clk, err := f.Clock("G02", t0.Add(40*time.Second), ClockOpts{})
+	if want := r0.Bias + (r1.Bias-r0.Bias)/3; err != nil || math.Abs(clk-want) > 1e-18 {
+		t.Errorf("G02: get %e, want %e, err=%v", clk, want, err)
+	}
+	m, err := f.ClockMeters("G02", t0.Add(40*time.Second), ClockOpts{})
+	if err != nil || math.Abs(m-clk*lightVelocity) > 1e-9 {
+		t.Errorf("G02: get %f m, want %f m, err=%v", m, clk*lightVelocity, err)
+	}
+
+	// the missing sample of G03 at 60 s
+	if _, err := f.Clock("G03", t0.Add(45*time.Second), ClockOpts{}); !errors.Is(err, ErrNoClock) {
+		t.Errorf("get err=%v, want %v", err, ErrNoClock)
+	}
+	r0, r1 = f.Sats["G03"][1], f.Sats["G03"][2]
+	clk, err = f.Clock("G03", t0.Add(45*time.Second), ClockOpts{MaxGap: time.Minute})
+	if want := r0.Bias + (r1.Bias-r0.Bias)/4; err != nil || math.Abs(clk-want) > 1e-18 {
+		t.Errorf("G03: get %e, want %e, err=%v", clk, want, err)
+	}
+
+	// out of the file, and unknown satellite
+	for _, tt := range []struct {
+		id string
+		t  time.Time
+	}{
+		{"G14", t0.Add(-time.Second)},
+		{"G14", t0.Add(121 * time.Second)},
+		{"G01", t0},
+	} {
+		if _, err := f.Clock(tt.id, tt.t, ClockOpts{}); !errors.Is(err, ErrNoClock) {
+			t.Errorf("%s at %v: get err=%v, want %v", tt.id, tt.t, err, ErrNoClock)
+		}
+	}
+}
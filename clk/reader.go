@@ -0,0 +1,264 @@
+package clk
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ReadFile reads the RINEX clock file of the name.
+func ReadFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a RINEX clock file of the versions 2.00 to 3.04 from r.
+//
+// The "AS" and "AR" records are stored, and the other types are skipped.
+// The errors are wrapped with the line number, and the format errors are
+// tested by errors.Is with ErrFormat.
+func Parse(r io.Reader) (*File, error) {
+	p := parser{s: mscanner.NewScanner(r)}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return f, nil
+}
+
+// parser holds the state of Parse.
+type parser struct {
+	s *mscanner.Scanner
+	f File
+
+	line string // current line padded to 80 columns
+	off  int    // offset of the columns after the name, 5 for 3.04
+}
+
+// next reads the next line, and returns false at the end of the input.
+func (p *parser) next() bool {
+	if !p.s.Scan() {
+		return false
+	}
+	p.line = fmt.Sprintf("%-80s", strings.TrimRight(p.s.Text(), "\r"))
+	return true
+}
+
+func (p *parser) parse() (*File, error) {
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+	if err := p.parseData(); err != nil {
+		return nil, err
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	p.f.Interval = interval(p.f.Sats)
+	return &p.f, nil
+}
+
+// parseHeader parses the header lines until "END OF HEADER".
+func (p *parser) parseHeader() (err error) {
+	h := &p.f.Header
+
+	if !p.next() || label(p.line) != "RINEX VERSION / TYPE" {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(p.line))
+	}
+	if h.Version, err = field.Atof(p.line[:9]); err != nil {
+		return fmt.Errorf("%w: version: %v", ErrFormat, err)
+	}
+	if p.line[20] != 'C' {
+		return fmt.Errorf("%w: file type: '%c'", ErrFormat, p.line[20])
+	}
+	h.SatSystem = p.line[40]
+	if h.Version >= 3.04 {
+		p.off = 5
+	}
+
+	var nsat, nsta int
+	for p.next() {
+		l := p.line
+		switch label(l) {
+		case "TIME SYSTEM ID":
+			h.TimeSystem = strings.TrimSpace(l[3:6])
+		case "LEAP SECONDS":
+			if h.LeapSeconds, err = field.Atoi(l[:6]); err != nil {
+				return fmt.Errorf("%w: leap seconds: %v", ErrFormat, err)
+			}
+		case "ANALYSIS CENTER":
+			h.AC = strings.TrimSpace(l[:3])
+			h.ACName = strings.TrimSpace(l[5:60])
+		case "# / TYPES OF DATA":
+			n, err := field.Atoi(l[:6])
+			if err != nil {
+				return fmt.Errorf("%w: number of types: %v", ErrFormat, err)
+			}
+			for k := 0; k < n && k < 9; k++ {
+				h.Types = append(h.Types, strings.TrimSpace(l[10+6*k:12+6*k]))
+			}
+		case "# OF SOLN STA / TRF":
+			if nsta, err = field.Atoi(l[:6]); err != nil {
+				return fmt.Errorf("%w: number of stations: %v", ErrFormat, err)
+			}
+			h.Frame = strings.TrimSpace(l[10:60])
+		case "SOLN STA NAME / NUM":
+			sta, err := p.parseStation(l)
+			if err != nil {
+				return err
+			}
+			h.Stations = append(h.Stations, sta)
+		case "# OF SOLN SATS":
+			if nsat, err = field.Atoi(l[:6]); err != nil {
+				return fmt.Errorf("%w: number of satellites: %v", ErrFormat, err)
+			}
+		case "PRN LIST":
+			for _, id := range strings.Fields(l[:60]) {
+				h.Sats = append(h.Sats, id)
+			}
+		case "COMMENT":
+			h.Comments = append(h.Comments, strings.TrimRight(l[:60], " "))
+		case "END OF HEADER":
+			if len(h.Sats) != nsat {
+				return fmt.Errorf("%w: number of satellites: declared=%d, listed=%d", ErrFormat, nsat, len(h.Sats))
+			}
+			if len(h.Stations) != nsta {
+				return fmt.Errorf("%w: number of stations: declared=%d, listed=%d", ErrFormat, nsta, len(h.Stations))
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no end of header", ErrFormat)
+}
+
+// parseStation parses a station line:
+//
+//	ALGO 40104M002            918129502        -4354426        4602867SOLN STA NAME / NUM
+//
+// where the name has nine characters for the version 3.04.
+func (p *parser) parseStation(l string) (sta Station, err error) {
+	sta.Name = strings.TrimSpace(l[:4+p.off])
+	sta.Number = strings.TrimSpace(l[5+p.off : 25+p.off])
+	for c := range 3 {
+		// I11 (mm) separated by 1X
+		from := 25 + p.off + 12*c
+		v, err := field.AtoiBlank(l[from : from+11])
+		if err != nil {
+			return sta, fmt.Errorf("%w: station %s: position: %v", ErrFormat, sta.Name, err)
+		}
+		sta.Pos[c] = float64(v) / 1e3
+	}
+	return sta, nil
+}
+
+// parseData parses the data records:
+//
+//	AS G14  2024 07 14 00 00  0.000000  2    4.481626360000E-04  1.234000000000E-11
+//
+// where the name has nine characters for the version 3.04, and more than two
+// values are continued to the next line.
+func (p *parser) parseData() error {
+	f := &p.f
+	f.Sats = make(map[string][]Record, len(f.Header.Sats))
+	f.Stations = make(map[string][]Record, len(f.Header.Stations))
+
+	for p.next() {
+		l := p.line
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		typ := l[:2]
+		name := strings.TrimSpace(l[3 : 7+p.off])
+		t, err := parseEpoch(l[8+p.off : 34+p.off])
+		if err != nil {
+			return err
+		}
+		n, err := field.Atoi(l[34+p.off : 37+p.off])
+		if err != nil || n < 1 || n > 6 {
+			return fmt.Errorf("%w: number of values: '%s'", ErrFormat, strings.TrimSpace(l[34+p.off:37+p.off]))
+		}
+
+		// two values in the first line, and four in the continuation line
+		vals := make([]float64, n)
+		from := 39 + p.off
+		for k := range n {
+			if k == 2 {
+				if !p.next() {
+					return fmt.Errorf("%w: continuation line not found", ErrFormat)
+				}
+				l, from = p.line, 0
+			}
+			if vals[k], err = field.Atof(l[from : from+19]); err != nil {
+				return fmt.Errorf("%w: value of %s: %v", ErrFormat, name, err)
+			}
+			from += 19
+		}
+
+		var recs map[string][]Record
+		switch typ {
+		case "AS":
+			recs = f.Sats
+		case "AR":
+			recs = f.Stations
+		case "CR", "DR", "MS":
+			continue
+		default:
+			return fmt.Errorf("%w: unknown data type: '%s'", ErrFormat, typ)
+		}
+
+		rec := Record{Epoch: t, Bias: vals[0]}
+		if n > 1 {
+			rec.Sigma, rec.HasSigma = vals[1], true
+		}
+		if prev := recs[name]; len(prev) > 0 && !t.After(prev[len(prev)-1].Epoch) {
+			return fmt.Errorf("%w: epoch of %s %s not increasing: %v", ErrFormat, typ, name, t)
+		}
+		recs[name] = append(recs[name], rec)
+	}
+
+	return nil
+}
+
+// label returns the header label of the line. The station line of the
+// version 3.04 is longer than 60 columns.
+func label(l string) string {
+	if strings.HasSuffix(strings.TrimSpace(l), "SOLN STA NAME / NUM") {
+		return "SOLN STA NAME / NUM"
+	}
+	return strings.TrimSpace(l[60:])
+}
+
+// interval returns the smallest interval of the records, or zero if no
+// satellite has two records.
+func interval(sats map[string][]Record) time.Duration {
+	var dt time.Duration
+	for _, recs := range sats {
+		for k := 1; k < len(recs); k++ {
+			if d := recs[k].Epoch.Sub(recs[k-1].Epoch); dt == 0 || d < dt {
+				dt = d
+			}
+		}
+	}
+	return dt
+}
+
+// parseEpoch parses an epoch of the year, month, day, hour, minute and
+// seconds.
+func parseEpoch(s string) (time.Time, error) {
+	t, err := field.Epoch(s)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,174 @@
+package clk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFile is the hand-made fixture in the format of the IGS final clock,
+// where the satellite clocks at 00:00 are those of IGR and the other records
+// are synthetic.
+const testFile = "testdata/synt23230.clk"
+
+// readTestFile returns the lines of the fixture.
+func readTestFile(t *testing.T) []string {
+	t.Helper()
+	b, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+}
+
+// TestReadFile checks the header and the records of the fixture.
+func TestReadFile(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	h := f.Header
+	if h.Version != 3 || h.SatSystem != 'G' || h.TimeSystem != "GPS" || h.LeapSeconds != 18 {
+		t.Errorf("header: %+v", h)
+	}
+	if h.AC != "SYN" || h.ACName != "SYNTHETIC, NOT BY IGS-ACC" || !slices.Equal(h.Types, []string{"AR", "AS"}) || len(h.Comments) != 1 {
+		t.Errorf("header: %+v", h)
+	}
+	want := []string{"G02", "G03", "G04", "G06", "G14", "G17", "G19", "G21", "G22"}
+	if !slices.Equal(h.Sats, want) {
+		t.Errorf("satellites: get %v, want %v", h.Sats, want)
+	}
+	algo := Station{Name: "ALGO", Number: "40104M002", Pos: [3]float64{918129.502, -4354426.289, 4602867.219}}
+	if h.Frame != "IGb20" || len(h.Stations) != 2 || h.Stations[0] != algo || h.Stations[1].Name != "NRC1" {
+		t.Errorf("stations: %s %+v", h.Frame, h.Stations)
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if f.Interval != 30*time.Second || len(f.Sats) != 9 || len(f.Sats["G03"]) != 4 || len(f.Sats["G14"]) != 5 {
+		t.Fatalf("interval=%v, satellites=%d", f.Interval, len(f.Sats))
+	}
+	r := f.Sats["G14"][0]
+	if !r.Epoch.Equal(t0) || math.Abs(r.Bias-448.162636e-6) > 1e-18 || !r.HasSigma || r.Sigma != 1.5e-11 {
+		t.Errorf("G14: %+v", r)
+	}
+	if r := f.Sats["G02"][0]; math.Abs(r.Bias+399.560198e-6) > 1e-18 {
+		t.Errorf("G02: %+v", r)
+	}
+
+	// four values with the continuation line, and the bias only
+	if r := f.Sats["G14"][3]; !r.Epoch.Equal(t0.Add(90*time.Second)) || r.Sigma != 2.5e-11 {
+		t.Errorf("G14: %+v", r)
+	}
+	if r := f.Sats["G22"][4]; r.HasSigma || r.Sigma != 0 {
+		t.Errorf("G22: %+v", r)
+	}
+
+	if recs := f.Stations["ALGO"]; len(recs) != 2 || recs[0].Bias != -1.6435e-8 || !recs[1].Epoch.Equal(t0.Add(30*time.Second)) {
+		t.Errorf("ALGO: %+v", recs)
+	}
+}
+
+// testIGSPattern is the IGS rapid clocks of 2024-07-14 (day 196), which are
+// not distributed with the module. TestReadFileIGS is skipped unless the
+// decompressed file is put in testdata, e.g., from CDDIS or BKG.
+const testIGSPattern = "testdata/IGS0OPSRAP_20241960000_01D_*_CLK.CLK"
+
+// TestReadFileIGS checks the AR and AS records of the IGS rapid clocks, where
+// the satellite clocks at 00:00 are the clocks published in IGR23230.SP3 of
+// the sp3 fixture within its resolution of 1 ps.
+func TestReadFileIGS(t *testing.T) {
+	m, _ := filepath.Glob(testIGSPattern)
+	if len(m) == 0 {
+		t.Skipf("no IGS product in testdata: %s", testIGSPattern)
+	}
+	f, err := ReadFile(m[0])
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if h := f.Header; h.TimeSystem != "GPS" || !slices.Contains(h.Types, "AR") || !slices.Contains(h.Types, "AS") {
+		t.Errorf("header: %+v", h)
+	}
+	if len(f.Stations) == 0 || len(f.Stations) != len(f.Header.Stations) {
+		t.Errorf("%d stations of the records, %d of the header", len(f.Stations), len(f.Header.Stations))
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for id, us := range map[string]float64{
+		"G02": -399.560198,
+		"G03": 456.857028,
+		"G04": 403.210910,
+		"G06": 163.114980,
+		"G14": 448.162636,
+		"G17": 678.028776,
+		"G19": 510.053160,
+		"G21": 109.105768,
+		"G22": -39.250385,
+	} {
+		clk, err := f.Clock(id, t0, ClockOpts{})
+		if err != nil || math.Abs(clk-us*1e-6) > 1e-12 {
+			t.Errorf("%s: get %.12e, want %.12e, err=%v", id, clk, us*1e-6, err)
+		}
+	}
+}
+
+// TestParse304 checks the names of nine characters of the version 3.04.
+func TestParse304(t *testing.T) {
+	hdr := func(s, label string) string { return fmt.Sprintf("%-60s%s", s, label) }
+	src := strings.Join([]string{
+		hdr("     3.04           C                   M", "RINEX VERSION / TYPE"),
+		hdr("     1    AS", "# / TYPES OF DATA"),
+		hdr("     1    IGS20", "# OF SOLN STA / TRF"),
+		"ALGO00CAN 40104M002             918129502 -4354426289  4602867219SOLN STA NAME / NUM",
+		hdr("     2", "# OF SOLN SATS"),
+		hdr("G01 E11", "PRN LIST"),
+		hdr("", "END OF HEADER"),
+		"AS G01       2024 07 14 00 00  0.000000  1  -1.234567890123E-04",
+		"AS E11       2024 07 14 00 00  0.000000  2   2.345678901234E-04-1.000000000000E-11",
+	}, "\n")
+	f, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if sta := f.Header.Stations[0]; sta.Name != "ALGO00CAN" || sta.Number != "40104M002" || sta.Pos[2] != 4602867.219 {
+		t.Errorf("station: %+v", sta)
+	}
+	if r := f.Sats["G01"][0]; r.Bias != -1.234567890123e-4 || r.HasSigma {
+		t.Errorf("G01: %+v", r)
+	}
+	if r := f.Sats["E11"][0]; r.Bias != 2.345678901234e-4 || r.Sigma != -1e-11 {
+		t.Errorf("E11: %+v", r)
+	}
+}
+
+// TestParseError checks the format errors with the line numbers.
+func TestParseError(t *testing.T) {
+	orig := readTestFile(t)
+	for _, tt := range []struct {
+		name string
+		mod  func(lines []string) []string
+		line string
+	}{
+		{"empty", func([]string) []string { return nil }, "line 0:"},
+		{"file type", func(l []string) []string { l[0] = strings.Replace(l[0], "C", "O", 1); return l }, "line 1:"},
+		{"number of satellites", func(l []string) []string { l[10] = "     8" + l[10][6:]; return l }, "line 13:"},
+		{"no end of header", func(l []string) []string { return l[:12] }, "line 12:"},
+		{"epoch", func(l []string) []string { l[13] = strings.Replace(l[13], "07 14", "07 xx", 1); return l }, "line 14:"},
+		{"value", func(l []string) []string { l[13] = l[13][:45] + "x" + l[13][46:]; return l }, "line 14:"},
+		{"data type", func(l []string) []string { l[13] = "XX" + l[13][2:]; return l }, "line 14:"},
+		{"not increasing", func(l []string) []string { l[26], l[15] = l[15], l[26]; return l }, "line 27:"},
+		{"no continuation", func(l []string) []string { return l[:48] }, "line 48:"},
+	} {
+		lines := tt.mod(slices.Clone(orig))
+		_, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: err=%v, want %s", tt.name, err, tt.line)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package coord
+
+import (
+	"math"
+	"time"
+)
+
+// OmegaEarth is the nominal rotation rate of the Earth (rad/s) of IERS, i.e.,
+// the rate of GMST in the sidereal day. The variation of the length of day
+// (about 1e-8 relative) is neglected.
+const OmegaEarth = 7.292115146706979e-5
+
+// j2000Unix is the Unix time of J2000.0 (2000-01-01 12:00).
+const j2000Unix = 946728000
+
+// EOP is the Earth orientation parameters of the transformation between ECEF
+// and ECI. The zero value neglects them, where the errors are up to 0.9 s of
+// UT1 (420 m on the equator, 1.7 km at the GNSS altitude) and 0.5 arcsec of
+// the pole (15 m on the surface, 65 m at the GNSS altitude).
+type EOP struct {
+	DUT1   float64 // UT1-UTC (s)
+	Xp, Yp float64 // pole coordinates (rad)
+}
+
+// GMST returns the Greenwich mean sidereal time (rad) in [0, 2pi) of the
+// IAU 1982 model at the epoch ut1 in UT1.
+func GMST(ut1 time.Time) float64 {
+	d := float64(ut1.Unix()-j2000Unix)/86400 + float64(ut1.Nanosecond())*1e-9/86400
+	T := d / 36525
+
+	// 67310.54841 s is 24110.54841 s of the model at 0h plus 12h of J2000.0,
+	// and 3155760000 s is the solar seconds of the Julian century
+	s := 67310.54841 + (3155760000+8640184.812866)*T + 0.093104*T*T - 6.2e-6*T*T*T
+	s = math.Mod(s, 86400)
+	if s < 0 {
+		s += 86400
+	}
+	return s * 2 * math.Pi / 86400
+}
+
+// ECEFToECI returns the position (m) and the velocity (m/s) in the inertial
+// frame of those in ECEF at the epoch utc in UTC.
+//
+// The inertial frame is that of the true equator of date (the pole of the
+// polar motion) and the x-axis at GMST from the Greenwich meridian, i.e., the
+// true-of-date frame rotated by the equation of the equinoxes (up to 1.2 s of
+// time, 18 arcsec). The precession and the nutation are neglected, and the
+// frame is not J2000 nor GCRF: it is intended for the orbit work over the
+// short periods, e.g., the comparison of the orbits and the Sagnac effect,
+// where the transformation and its inverse are consistent within a few
+// micrometers. The velocity includes the term of the rotation, i.e., omega x r.
+func ECEFToECI(utc time.Time, eop EOP, pos, vel [3]float64) (posI, velI [3]float64) {
+	W := polarMotion(eop)
+	r, v := Rotate(W, pos), Rotate(W, vel)
+
+	v[0] -= OmegaEarth * r[1]
+	v[1] += OmegaEarth * r[0]
+
+	R := earthRotation(utc, eop)
+	return RotateT(R, r), RotateT(R, v)
+}
+
+// ECIToECEF returns the position (m) and the velocity (m/s) in ECEF of those
+// in the inertial frame of ECEFToECI at the epoch utc in UTC, i.e., the
+// inverse of ECEFToECI.
+func ECIToECEF(utc time.Time, eop EOP, pos, vel [3]float64) (posE, velE [3]float64) {
+	R := earthRotation(utc, eop)
+	r, v := Rotate(R, pos), Rotate(R, vel)
+
+	v[0] += OmegaEarth * r[1]
+	v[1] -= OmegaEarth * r[0]
+
+	W := polarMotion(eop)
+	return RotateT(W, r), RotateT(W, v)
+}
+
+// earthRotation returns the rotation about the z-axis by GMST from the
+// inertial frame to the pseudo Earth-fixed frame.
+func earthRotation(utc time.Time, eop EOP) [3][3]float64 {
+	ut1 := utc.Add(time.Duration(math.Round(eop.DUT1 * 1e9)))
+	sin, cos := math.Sincos(GMST(ut1))
+	return [3][3]float64{
+		{cos, sin, 0},
+		{-sin, cos, 0},
+		{0, 0, 1},
+	}
+}
+
+// polarMotion returns the rotation from ECEF to the pseudo Earth-fixed frame
+// of the pole of date, i.e., W = R2(xp) R1(yp) of IERS Conventions neglecting
+// the TIO locator s'.
+func polarMotion(eop EOP) [3][3]float64 {
+	sx, cx := math.Sincos(eop.Xp)
+	sy, cy := math.Sincos(eop.Yp)
+	return [3][3]float64{
+		{cx, sx * sy, -sx * cy},
+		{0, cy, sy},
+		{sx, -cx * sy, cx * cy},
+	}
+}
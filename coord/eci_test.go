@@ -0,0 +1,89 @@
+package coord
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestGMST checks GMST of Example 3-5 of Vallado, Fundamentals of
+// Astrodynamics and Applications (4th ed.), 1992-08-20 12:14 UT1.
+func TestGMST(t *testing.T) {
+	ut1 := time.Date(1992, 8, 20, 12, 14, 0, 0, time.UTC)
+	if g := Rad2Deg(GMST(ut1)); math.Abs(g-152.578787810) > 1e-6 {
+		t.Errorf("get %.9f deg, want 152.578787810", g)
+	}
+}
+
+// TestECEFToECI checks the transformation of Example 3-15 of Vallado, where
+// the true-of-date position is the inertial one rotated by the equation of
+// the equinoxes (-0.0031289 deg). The agreement within 2 cm is limited by the
+// digits of the equation of the equinoxes.
+func TestECEFToECI(t *testing.T) {
+	utc := time.Date(2004, 4, 6, 7, 51, 28, 386009000, time.UTC)
+	arcsec := math.Pi / 180 / 3600
+	eop := EOP{DUT1: -0.4399619, Xp: -0.140682 * arcsec, Yp: 0.333309 * arcsec}
+	pos := [3]float64{-1033479.3830, 7901295.2754, 6380356.5958}
+	vel := [3]float64{-3225.636520, -2872.451450, 5531.924446}
+
+	posI, velI := ECEFToECI(utc, eop, pos, vel)
+
+	sin, cos := math.Sincos(Deg2Rad(-0.0031289))
+	tod := [3]float64{cos*posI[0] - sin*posI[1], sin*posI[0] + cos*posI[1], posI[2]}
+	want := [3]float64{5094514.7804, 6127366.4612, 6380344.5328}
+	for c := range 3 {
+		if math.Abs(tod[c]-want[c]) > 0.02 {
+			t.Errorf("position: get %.4f, want %.4f", tod, want)
+			break
+		}
+	}
+
+	// the round trip
+	p, v := ECIToECEF(utc, eop, posI, velI)
+	for c := range 3 {
+		if math.Abs(p[c]-pos[c]) > 1e-6 || math.Abs(v[c]-vel[c]) > 1e-9 {
+			t.Errorf("round trip: get %v %v, want %v %v", p, v, pos, vel)
+			break
+		}
+	}
+}
+
+// TestECIVelocity checks the velocities against the differences of the
+// positions, i.e., the term of the rotation of the Earth.
+func TestECIVelocity(t *testing.T) {
+	utc := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	eop := EOP{DUT1: -0.01, Xp: 1e-6, Yp: 2e-6}
+	const h = 0.5
+	step := time.Duration(h * 1e9)
+
+	// a fixed point on the surface, and a satellite
+	for _, tt := range []struct {
+		pos, vel [3]float64
+	}{
+		{[3]float64{-3957199.0, 3310199.0, 3737711.0}, [3]float64{}},
+		{[3]float64{15600e3, 7540e3, 20140e3}, [3]float64{-1500, 2800, 100}},
+	} {
+		// the position in ECEF moving with the velocity
+		posI, velI := ECEFToECI(utc, eop, tt.pos, tt.vel)
+		p0, _ := ECEFToECI(utc.Add(-step), eop, add(tt.pos, tt.vel, -h), tt.vel)
+		p1, _ := ECEFToECI(utc.Add(step), eop, add(tt.pos, tt.vel, h), tt.vel)
+		for c := range 3 {
+			if v := (p1[c] - p0[c]) / (2 * h); math.Abs(v-velI[c]) > 1e-3 {
+				t.Errorf("%v: velocity[%d]: get %.6f, want %.6f", tt.pos, c, velI[c], v)
+			}
+		}
+
+		pe, ve := ECIToECEF(utc, eop, posI, velI)
+		for c := range 3 {
+			if math.Abs(pe[c]-tt.pos[c]) > 1e-6 || math.Abs(ve[c]-tt.vel[c]) > 1e-9 {
+				t.Errorf("round trip: get %v %v, want %v %v", pe, ve, tt.pos, tt.vel)
+				break
+			}
+		}
+	}
+}
+
+// add returns p+v*dt.
+func add(p, v [3]float64, dt float64) [3]float64 {
+	return [3]float64{p[0] + v[0]*dt, p[1] + v[1]*dt, p[2] + v[2]*dt}
+}
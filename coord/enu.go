@@ -0,0 +1,77 @@
+package coord
+
+import "math"
+
+// ENURotation returns the rotation matrix from ECEF to the local east, north,
+// up frame at the geodetic latitude and longitude (rad).
+//
+//	    | -sin(lon)           cos(lon)          0        |
+//	R = | -sin(lat)cos(lon)  -sin(lat)sin(lon)  cos(lat) |
+//	    |  cos(lat)cos(lon)   cos(lat)sin(lon)  sin(lat) |
+func ENURotation(lat, lon float64) (R [3][3]float64) {
+	sinLat, cosLat := math.Sincos(lat)
+	sinLon, cosLon := math.Sincos(lon)
+
+	R[0] = [3]float64{-sinLon, cosLon, 0.}
+	R[1] = [3]float64{-sinLat * cosLon, -sinLat * sinLon, cosLat}
+	R[2] = [3]float64{cosLat * cosLon, cosLat * sinLon, sinLat}
+
+	return R
+}
+
+// ECEFToENU returns the east, north, up coordinates (m) of the point relative
+// to the origin, where both are the ECEF positions (m). The local frame is
+// defined at the geodetic latitude and longitude of the origin on WGS84.
+func ECEFToENU(origin, point [3]float64) (enu [3]float64) {
+	lat, lon, _ := XYZToLLH(origin[0], origin[1], origin[2])
+	d := [3]float64{point[0] - origin[0], point[1] - origin[1], point[2] - origin[2]}
+	return Rotate(ENURotation(lat, lon), d)
+}
+
+// ENUToECEF returns the ECEF position (m) of the point given by the east,
+// north, up coordinates (m) relative to the origin. This is the inverse of
+// ECEFToENU.
+func ENUToECEF(origin, enu [3]float64) (point [3]float64) {
+	lat, lon, _ := XYZToLLH(origin[0], origin[1], origin[2])
+	d := RotateT(ENURotation(lat, lon), enu)
+	return [3]float64{origin[0] + d[0], origin[1] + d[1], origin[2] + d[2]}
+}
+
+// AzEl returns the azimuth and elevation (rad) of the satellite seen from the
+// receiver, where both are the ECEF positions (m).
+// The azimuth is measured clockwise from the north in [0, 2*pi), and the
+// elevation is in [-pi/2, pi/2]. The azimuth is undefined at the zenith.
+func AzEl(rcv, sat [3]float64) (az, el float64) {
+	return ENUToAzEl(ECEFToENU(rcv, sat))
+}
+
+// ENUToAzEl returns the azimuth and elevation (rad) of the direction given by
+// the east, north, up vector. See AzEl for the ranges.
+func ENUToAzEl(enu [3]float64) (az, el float64) {
+	az = math.Atan2(enu[0], enu[1])
+	if az < 0 {
+		az += 2. * math.Pi
+	}
+	if az >= 2.*math.Pi {
+		// -0 rounds up to 2*pi
+		az = 0.
+	}
+	el = math.Atan2(enu[2], math.Hypot(enu[0], enu[1]))
+	return az, el
+}
+
+// Rotate returns R*v.
+func Rotate(R [3][3]float64, v [3]float64) (w [3]float64) {
+	for i := range 3 {
+		w[i] = R[i][0]*v[0] + R[i][1]*v[1] + R[i][2]*v[2]
+	}
+	return w
+}
+
+// RotateT returns R'*v.
+func RotateT(R [3][3]float64, v [3]float64) (w [3]float64) {
+	for i := range 3 {
+		w[i] = R[0][i]*v[0] + R[1][i]*v[1] + R[2][i]*v[2]
+	}
+	return w
+}
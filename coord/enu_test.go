@@ -0,0 +1,48 @@
+package coord
+
+import (
+	"math"
+	"testing"
+)
+
+// TestAzEl checks the azimuth and elevation for the points placed in the
+// cardinal directions from the origin.
+func TestAzEl(t *testing.T) {
+	lat, lon := Deg2Rad(36.), Deg2Rad(140.)
+	x, y, z := LLHToXYZ(lat, lon, 0.)
+	rcv := [3]float64{x, y, z}
+
+	tests := []struct {
+		name   string
+		enu    [3]float64
+		az, el float64 // deg
+	}{
+		{"north", [3]float64{0., 1000., 0.}, 0., 0.},
+		{"east", [3]float64{1000., 0., 0.}, 90., 0.},
+		{"south, 45deg", [3]float64{0., -1000., 1000.}, 180., 45.},
+		{"west", [3]float64{-1000., 0., 0.}, 270., 0.},
+		{"zenith", [3]float64{0., 0., 20000e3}, 0., 90.},
+		{"below", [3]float64{0., 1000., -1000.}, 0., -45.},
+	}
+
+	for _, tt := range tests {
+		sat := ENUToECEF(rcv, tt.enu)
+		az, el := AzEl(rcv, sat)
+		if tt.el == 90. {
+			// azimuth is undefined at the zenith
+			az = 0.
+		}
+		daz := math.Remainder(Rad2Deg(az)-tt.az, 360.)
+		if math.Abs(daz) > 1e-9 || math.Abs(Rad2Deg(el)-tt.el) > 1e-9 {
+			t.Errorf("%s: get (%f, %f), want (%f, %f)", tt.name, Rad2Deg(az), Rad2Deg(el), tt.az, tt.el)
+		}
+
+		enu := ECEFToENU(rcv, sat)
+		for i := range 3 {
+			if math.Abs(enu[i]-tt.enu[i]) > 1e-6 {
+				t.Errorf("%s: ECEFToENU get %v, want %v", tt.name, enu, tt.enu)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+package coord provides coordinate conversions used in GNSS positioning, such
+as ECEF <-> geodetic (latitude, longitude, ellipsoidal height).
+
+Angles are in radians and lengths are in meters unless otherwise noted.
+*/
+package coord
+
+import "math"
+
+// Ellipsoid defines a reference ellipsoid by the semi-major axis A (m) and
+// the flattening F.
+type Ellipsoid struct {
+	A float64 // semi-major axis (m)
+	F float64 // flattening
+}
+
+var (
+	// WGS84 is the ellipsoid of World Geodetic System 1984.
+	WGS84 = Ellipsoid{A: 6378137., F: 1. / 298.257223563}
+
+	// GRS80 is the ellipsoid of Geodetic Reference System 1980.
+	GRS80 = Ellipsoid{A: 6378137., F: 1. / 298.257222101}
+)
+
+// E2 returns the square of the first eccentricity.
+func (e Ellipsoid) E2() float64 {
+	return e.F * (2. - e.F)
+}
+
+// B returns the semi-minor axis (m).
+func (e Ellipsoid) B() float64 {
+	return e.A * (1. - e.F)
+}
+
+// XYZToLLH converts the ECEF position (m) into the geodetic latitude, longitude
+// (rad) and the ellipsoidal height (m) on the WGS84 ellipsoid.
+func XYZToLLH(x, y, z float64) (lat, lon, h float64) {
+	return WGS84.XYZToLLH(x, y, z)
+}
+
+// LLHToXYZ converts the geodetic latitude, longitude (rad) and the ellipsoidal
+// height (m) on the WGS84 ellipsoid into the ECEF position (m).
+func LLHToXYZ(lat, lon, h float64) (x, y, z float64) {
+	return WGS84.LLHToXYZ(lat, lon, h)
+}
+
+// XYZToLLH converts the ECEF position (m) into the geodetic latitude, longitude
+// (rad) and the ellipsoidal height (m) on the ellipsoid.
+//
+// The latitude is obtained by iterating
+//
+//	lat = atan2(z + e2*N*sin(lat), p)
+//
+// where p = sqrt(x^2 + y^2), which is regular at both the poles (p = 0) and
+// the equator (z = 0). The longitude is set to 0 at the poles.
+func (e Ellipsoid) XYZToLLH(x, y, z float64) (lat, lon, h float64) {
+	e2 := e.E2()
+	p := math.Hypot(x, y)
+
+	if p == 0 && z == 0 {
+		// center of the Earth
+		return 0., 0., -e.A
+	}
+
+	lon = 0.
+	if p > 0 {
+		lon = math.Atan2(y, x)
+	}
+
+	lat = math.Atan2(z, p*(1.-e2))
+	var N, sinLat float64
+	for range 10 {
+		sinLat = math.Sin(lat)
+		N = e.A / math.Sqrt(1.-e2*sinLat*sinLat)
+		lat1 := math.Atan2(z+e2*N*sinLat, p)
+		if math.Abs(lat1-lat) < 1e-14 {
+			lat = lat1
+			break
+		}
+		lat = lat1
+	}
+
+	sinLat, cosLat := math.Sincos(lat)
+	N = e.A / math.Sqrt(1.-e2*sinLat*sinLat)
+	h = p*cosLat + (z+e2*N*sinLat)*sinLat - N
+
+	return lat, lon, h
+}
+
+// LLHToXYZ converts the geodetic latitude, longitude (rad) and the ellipsoidal
+// height (m) on the ellipsoid into the ECEF position (m).
+func (e Ellipsoid) LLHToXYZ(lat, lon, h float64) (x, y, z float64) {
+	e2 := e.E2()
+	sinLat, cosLat := math.Sincos(lat)
+	sinLon, cosLon := math.Sincos(lon)
+	N := e.A / math.Sqrt(1.-e2*sinLat*sinLat)
+
+	x = (N + h) * cosLat * cosLon
+	y = (N + h) * cosLat * sinLon
+	z = (N*(1.-e2) + h) * sinLat
+
+	return x, y, z
+}
+
+// Deg2Rad converts degrees into radians.
+func Deg2Rad(deg float64) float64 {
+	return deg * math.Pi / 180.
+}
+
+// Rad2Deg converts radians into degrees.
+func Rad2Deg(rad float64) float64 {
+	return rad * 180. / math.Pi
+}
@@ -0,0 +1,56 @@
+package coord
+
+import (
+	"math"
+	"testing"
+)
+
+// TestXYZToLLH checks the conversion at the benchmark points within 1e-11 deg
+// (~2e-13 rad) and 0.1 mm.
+func TestXYZToLLH(t *testing.T) {
+	tests := []struct {
+		name             string
+		x, y, z          float64 // m
+		lat, lon, height float64 // deg, deg, m
+	}{
+		{"equator, prime meridian", 6378137., 0., 0., 0., 0., 0.},
+		{"equator, 90E", 0., 6378137., 0., 0., 90., 0.},
+		{"equator, 180", -6378137., 0., 0., 0., 180., 0.},
+		{"north pole", 0., 0., 6356752.314245179, 90., 0., 0.},
+		{"south pole, 1000 m", 0., 0., -6357752.314245179, -90., 0., 1000.},
+		{"equator, GPS altitude", 26560000., 0., 0., 0., 0., 20181863.},
+
+		// APPROX POSITION XYZ of GEONET 0255 (rinex/testdata); the reference is
+		// computed independently by iterating tan(lat) in 60-digit decimal
+		// arithmetic, as no published geodetic coordinates were available
+		{"GEONET 0255", -3721695.1985, 3545492.6126, 3763541.7139, 36.394456781688532, 136.388939863771318, 47.571160388},
+	}
+
+	for _, tt := range tests {
+		lat, lon, h := XYZToLLH(tt.x, tt.y, tt.z)
+		if math.Abs(Rad2Deg(lat)-tt.lat) > 1e-11 || math.Abs(Rad2Deg(lon)-tt.lon) > 1e-11 || math.Abs(h-tt.height) > 1e-4 {
+			t.Errorf("%s: get (%.12f, %.12f, %.4f), want (%.12f, %.12f, %.4f)", tt.name, Rad2Deg(lat), Rad2Deg(lon), h, tt.lat, tt.lon, tt.height)
+		}
+	}
+}
+
+// TestRoundTrip checks XYZToLLH(LLHToXYZ()) reproduces the input within
+// sub-millimeter.
+func TestRoundTrip(t *testing.T) {
+	for _, ell := range []Ellipsoid{WGS84, GRS80} {
+		for lat := -90.; lat <= 90.; lat += 7.5 {
+			for lon := -180.; lon < 180.; lon += 30. {
+				for _, h := range []float64{-400., 0., 3776., 20200e3} {
+					x, y, z := ell.LLHToXYZ(Deg2Rad(lat), Deg2Rad(lon), h)
+					lat1, lon1, h1 := ell.XYZToLLH(x, y, z)
+					x1, y1, z1 := ell.LLHToXYZ(lat1, lon1, h1)
+
+					d := math.Sqrt((x1-x)*(x1-x) + (y1-y)*(y1-y) + (z1-z)*(z1-z))
+					if d > 1e-4 || math.Abs(h1-h) > 1e-4 {
+						t.Errorf("round trip: lat=%f, lon=%f, h=%f, d=%e, dh=%e", lat, lon, h, d, h1-h)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package coord
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrDegenerateOrbit is returned if the radial, along-track, cross-track
+// frame is undefined, i.e., the position or the velocity is near zero or they
+// are near parallel.
+var ErrDegenerateOrbit = errors.New("degenerate orbit for RAC frame")
+
+// RACRotation returns the rotation matrix from ECEF to the radial,
+// along-track, cross-track frame of the satellite at the position (m) and
+// the velocity (m/s) in ECEF.
+//
+// The radial axis is along the position, the cross-track axis is along the
+// angular momentum r x v, and the along-track axis completes the
+// right-handed frame, i.e., near the velocity. The velocity is converted into
+// the inertial one at the epoch by adding omega x r, so that the frame is that
+// of the orbital plane, e.g., of the geostationary satellites.
+//
+//	    | eR |   eR = r / |r|
+//	R = | eA |,  eC = r x v / |r x v|
+//	    | eC |   eA = eC x eR
+func RACRotation(pos, vel [3]float64) (R [3][3]float64, err error) {
+	v := [3]float64{vel[0] - OmegaEarth*pos[1], vel[1] + OmegaEarth*pos[0], vel[2]}
+	h := cross(pos, v)
+
+	r, nv, nh := norm(pos), norm(v), norm(h)
+	if r < 1 || nv < 1e-3 || nh < 1e-6*r*nv {
+		return R, ErrDegenerateOrbit
+	}
+
+	for k := range 3 {
+		R[0][k] = pos[k] / r
+		R[2][k] = h[k] / nh
+	}
+	R[1] = cross(R[2], R[0])
+	return R, nil
+}
+
+// ECEFToRAC returns the radial, along-track, cross-track components of the
+// vector d (ECEF), e.g., the difference of the satellite positions (m), in the
+// frame of the satellite at pos and vel (see RACRotation).
+func ECEFToRAC(pos, vel, d [3]float64) (rac [3]float64, err error) {
+	R, err := RACRotation(pos, vel)
+	if err != nil {
+		return rac, err
+	}
+	return Rotate(R, d), nil
+}
+
+// RACToECEF returns the vector in ECEF of the radial, along-track,
+// cross-track components rac in the frame of the satellite at pos and vel.
+// This is the inverse of ECEFToRAC.
+func RACToECEF(pos, vel, rac [3]float64) (d [3]float64, err error) {
+	R, err := RACRotation(pos, vel)
+	if err != nil {
+		return d, err
+	}
+	return RotateT(R, rac), nil
+}
+
+// RotateCov returns R*C*R', e.g., the covariance matrix C in ECEF into the
+// RAC frame by the rotation of RACRotation.
+func RotateCov(R, C [3][3]float64) (D [3][3]float64) {
+	for i := range 3 {
+		for j := range 3 {
+			for k := range 3 {
+				for l := range 3 {
+					D[i][j] += R[i][k] * C[k][l] * R[j][l]
+				}
+			}
+		}
+	}
+	return D
+}
+
+// RotateCovT returns R'*C*R, i.e., the inverse of RotateCov.
+func RotateCovT(R, C [3][3]float64) (D [3][3]float64) {
+	for i := range 3 {
+		for j := range 3 {
+			for k := range 3 {
+				for l := range 3 {
+					D[i][j] += R[k][i] * C[k][l] * R[l][j]
+				}
+			}
+		}
+	}
+	return D
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func norm(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
@@ -0,0 +1,94 @@
+package coord
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestRAC checks the axes of the frame of a circular orbit and the inverse.
+func TestRAC(t *testing.T) {
+	// inclined circular orbit at the ascending node on the x-axis
+	a, inc := 26560e3, Deg2Rad(55)
+	v := math.Sqrt(3.986004418e14 / a)
+	pos := [3]float64{a, 0, 0}
+	velI := [3]float64{0, v * math.Cos(inc), v * math.Sin(inc)}
+	vel := [3]float64{velI[0] + OmegaEarth*pos[1], velI[1] - OmegaEarth*pos[0], velI[2]}
+
+	for _, tt := range []struct {
+		d, want [3]float64
+	}{
+		{[3]float64{1, 0, 0}, [3]float64{1, 0, 0}},
+		{[3]float64{0, math.Cos(inc), math.Sin(inc)}, [3]float64{0, 1, 0}},
+		{[3]float64{0, -math.Sin(inc), math.Cos(inc)}, [3]float64{0, 0, 1}},
+	} {
+		rac, err := ECEFToRAC(pos, vel, tt.d)
+		if err != nil {
+			t.Fatalf("ECEFToRAC: %v", err)
+		}
+		for k := range 3 {
+			if math.Abs(rac[k]-tt.want[k]) > 1e-12 {
+				t.Errorf("ECEFToRAC(%v): get %v, want %v", tt.d, rac, tt.want)
+				break
+			}
+		}
+		d, err := RACToECEF(pos, vel, rac)
+		if err != nil {
+			t.Fatalf("RACToECEF: %v", err)
+		}
+		for k := range 3 {
+			if math.Abs(d[k]-tt.d[k]) > 1e-12 {
+				t.Errorf("RACToECEF(%v): get %v, want %v", rac, d, tt.d)
+				break
+			}
+		}
+	}
+}
+
+// TestRACCovariance checks the rotation of a covariance matrix and the
+// inverse.
+func TestRACCovariance(t *testing.T) {
+	R, err := RACRotation([3]float64{0, 0, 7000e3}, [3]float64{7500, 0, 0})
+	if err != nil {
+		t.Fatalf("RACRotation: %v", err)
+	}
+	C := [3][3]float64{{4, 1, 0}, {1, 9, 2}, {0, 2, 1}}
+
+	// radial is z, and the trace is invariant
+	D := RotateCov(R, C)
+	if math.Abs(D[0][0]-1) > 1e-12 || math.Abs(D[0][0]+D[1][1]+D[2][2]-14) > 1e-12 {
+		t.Errorf("RotateCov: get %v", D)
+	}
+	E := RotateCovT(R, D)
+	for i := range 3 {
+		for j := range 3 {
+			if math.Abs(E[i][j]-C[i][j]) > 1e-12 {
+				t.Fatalf("RotateCovT: get %v, want %v", E, C)
+			}
+		}
+	}
+}
+
+// TestRACDegenerate checks the errors of the undefined frames.
+func TestRACDegenerate(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		pos, vel [3]float64
+	}{
+		{"zero position", [3]float64{}, [3]float64{3000, 0, 0}},
+		{"zero velocity", [3]float64{0, 0, 26560e3}, [3]float64{}},
+		{"parallel", [3]float64{0, 0, 26560e3}, [3]float64{0, 0, 3000}},
+	} {
+		if _, err := RACRotation(tt.pos, tt.vel); !errors.Is(err, ErrDegenerateOrbit) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrDegenerateOrbit)
+		}
+		if _, err := ECEFToRAC(tt.pos, tt.vel, [3]float64{1, 0, 0}); !errors.Is(err, ErrDegenerateOrbit) {
+			t.Errorf("%s: ECEFToRAC: get err=%v", tt.name, err)
+		}
+	}
+
+	// geostationary, whose velocity in ECEF is zero
+	if _, err := RACRotation([3]float64{42164e3, 0, 0}, [3]float64{}); err != nil {
+		t.Errorf("geostationary: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package coord
+
+import (
+	"math"
+	"time"
+)
+
+// AU is the astronomical unit (m) of IAU 2012.
+const AU = 149597870700.
+
+// SunPositionECEF returns the position of the Sun (ECEF, m) at the epoch t in
+// UTC, e.g., for the attitude of the satellites and the solid Earth tide.
+//
+// The model is the low-precision formulas of the Astronomical Almanac, whose
+// accuracy is about 0.01 deg of the direction and 1e-4 of the distance from
+// 1950 to 2050, rotated into ECEF by GMST without the polar motion. The
+// differences of UT1 and TT from UTC are neglected, which are below 0.005 deg.
+func SunPositionECEF(t time.Time) [3]float64 {
+	return Rotate(earthRotation(t, EOP{}), sunPositionECI(t))
+}
+
+// sunPositionECI returns the position of the Sun (m) in the inertial frame of
+// the mean equator and equinox of date at the epoch t.
+func sunPositionECI(t time.Time) [3]float64 {
+	n := float64(t.Unix()-j2000Unix)/86400 + float64(t.Nanosecond())*1e-9/86400
+
+	// mean longitude and mean anomaly, and the ecliptic longitude including
+	// the aberration
+	L := Deg2Rad(280.460 + 0.9856474*n)
+	g := Deg2Rad(357.528 + 0.9856003*n)
+	lambda := L + Deg2Rad(1.915*math.Sin(g)+0.020*math.Sin(2*g))
+
+	eps := Deg2Rad(23.439 - 4e-7*n)
+	r := (1.00014 - 0.01671*math.Cos(g) - 0.00014*math.Cos(2*g)) * AU
+
+	sl, cl := math.Sincos(lambda)
+	se, ce := math.Sincos(eps)
+	return [3]float64{r * cl, r * ce * sl, r * se * sl}
+}
@@ -0,0 +1,59 @@
+package coord
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// meeus is the epoch and the position of the Sun of Example 25.a of Meeus,
+// Astronomical Algorithms (2nd ed.), 1992-10-13 0h TD: the apparent RA
+// 13h13m31.4s, declination -7d47m01s and the distance 0.99760775 AU.
+func meeus() (time.Time, [3]float64) {
+	ra := Deg2Rad((13 + 13/60. + 31.4/3600) * 15)
+	dec := Deg2Rad(-(7 + 47/60. + 1/3600.))
+	r := 0.99760775 * AU
+	return time.Date(1992, 10, 12, 23, 59, 1, 0, time.UTC), [3]float64{
+		r * math.Cos(dec) * math.Cos(ra),
+		r * math.Cos(dec) * math.Sin(ra),
+		r * math.Sin(dec),
+	}
+}
+
+// TestSunPositionECI checks the inertial position against the reference of
+// meeus within the accuracy of the model.
+func TestSunPositionECI(t *testing.T) {
+	epoch, want := meeus()
+	checkSun(t, sunPositionECI(epoch), want)
+}
+
+// TestSunPositionECEF checks the position against the reference of meeus
+// rotated by GMST, whose difference from the apparent sidereal time is below
+// 0.005 deg.
+func TestSunPositionECEF(t *testing.T) {
+	epoch, pos := meeus()
+	g := GMST(epoch)
+	want := [3]float64{
+		math.Cos(g)*pos[0] + math.Sin(g)*pos[1],
+		-math.Sin(g)*pos[0] + math.Cos(g)*pos[1],
+		pos[2],
+	}
+	checkSun(t, SunPositionECEF(epoch), want)
+}
+
+// checkSun checks the direction within 0.01 deg and the distance within
+// 1e-4.
+func checkSun(t *testing.T, get, want [3]float64) {
+	t.Helper()
+	g, w := math.Sqrt(dot(get, get)), math.Sqrt(dot(want, want))
+	if a := Rad2Deg(math.Acos(math.Min(dot(get, want)/g/w, 1))); a > 0.01 {
+		t.Errorf("direction: get %.4f deg from the reference", a)
+	}
+	if d := math.Abs(g/w - 1); d > 1e-4 {
+		t.Errorf("distance: get %.0f m, want %.0f m", g, w)
+	}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
@@ -0,0 +1,134 @@
+package gnsstime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// The calendar conversions are of the readings of time.Time, i.e., the date
+// and the time of day in the location UTC, in any time scale. The GPS weeks
+// and days of the products are of those in GPS time.
+
+// mjdUnix is the MJD of the Unix epoch.
+const mjdUnix = 40587
+
+// secPerDay is the number of the seconds of a day.
+const secPerDay = 86400
+
+// MJD returns the modified Julian date of t, whose precision is about 10
+// microseconds in the GPS era.
+func MJD(t time.Time) float64 {
+	day, sod := mjdDay(t)
+	return float64(day) + sod/secPerDay
+}
+
+// MJDDay returns the integer modified Julian date of the day of t.
+func MJDDay(t time.Time) int {
+	day, _ := mjdDay(t)
+	return day
+}
+
+// mjdDay returns the integer MJD and the seconds of the day of t.
+func mjdDay(t time.Time) (day int, sod float64) {
+	u := t.Unix()
+	d := u / secPerDay
+	if u < 0 && u%secPerDay != 0 {
+		d--
+	}
+	return mjdUnix + int(d), float64(u-d*secPerDay) + float64(t.Nanosecond())*1e-9
+}
+
+// FromMJD returns the epoch of the modified Julian date mjd, which is rounded
+// to microseconds.
+func FromMJD(mjd float64) time.Time {
+	day := math.Floor(mjd)
+	us := math.Round((mjd - day) * secPerDay * 1e6)
+	return FromMJDDay(int(day)).Add(time.Duration(us) * time.Microsecond)
+}
+
+// FromMJDDay returns the start of the day of the integer modified Julian
+// date.
+func FromMJDDay(day int) time.Time {
+	return time.Unix(int64(day-mjdUnix)*secPerDay, 0).UTC()
+}
+
+// DOY returns the year and the day of year (1-366) of t.
+func DOY(t time.Time) (year, doy int) {
+	t = t.UTC()
+	return t.Year(), t.YearDay()
+}
+
+// FromDOY returns the start of the day of the year and the day of year.
+func FromDOY(year, doy int) time.Time {
+	return time.Date(year, 1, doy, 0, 0, 0, 0, time.UTC)
+}
+
+// GPSWeekDay returns the GPS week and the day of week (0 for Sunday) of the
+// reading t in GPS time.
+func GPSWeekDay(t time.Time) (week, dow int) {
+	day := MJDDay(t) - MJDDay(gpsEpoch)
+	week = day / 7
+	if day < 0 && day%7 != 0 {
+		week--
+	}
+	return week, day - 7*week
+}
+
+// FromGPSWeekDay returns the start of the day of the GPS week and the day of
+// week in GPS time.
+func FromGPSWeekDay(week, dow int) time.Time {
+	return gpsEpoch.AddDate(0, 0, 7*week+dow)
+}
+
+// LegacyProductName returns the name of the product of the analysis center
+// ac, e.g., "igr", of the day of t in GPS time with the extension ext, e.g.,
+// "igr23230.sp3".
+func LegacyProductName(ac string, t time.Time, ext string) string {
+	week, dow := GPSWeekDay(t)
+	return fmt.Sprintf("%s%04d%d.%s", ac, week, dow, ext)
+}
+
+// Product is the long name of the IGS products, e.g.,
+// "IGS0OPSRAP_20241960000_01D_15M_ORB.SP3".
+type Product struct {
+	Center   string        // analysis center, e.g., "IGS"
+	Version  int           // version (0-9)
+	Campaign string        // project or campaign, e.g., "OPS" or "MGX"
+	Type     string        // solution type, e.g., "FIN", "RAP" or "ULT"
+	Start    time.Time     // start epoch in GPS time
+	Span     time.Duration // intended period of the data, e.g., a day
+	Sampling time.Duration // sampling interval, zero if not applicable
+	Content  string        // content type, e.g., "ORB", "CLK" or "ERP"
+	Format   string        // format extension, e.g., "SP3" or "CLK"
+}
+
+// String returns the file name of the product.
+func (p Product) String() string {
+	start := p.Start.UTC()
+	year, doy := DOY(start)
+	return fmt.Sprintf("%s%d%s%s_%04d%03d%02d%02d_%s_%s_%s.%s",
+		p.Center, p.Version, p.Campaign, p.Type,
+		year, doy, start.Hour(), start.Minute(),
+		periodCode(p.Span), periodCode(p.Sampling), p.Content, strings.ToUpper(p.Format))
+}
+
+// periodCode returns the period of the long names, e.g., "01D" and "15M", of
+// the largest unit of the two digits, or "00U" for zero.
+func periodCode(d time.Duration) string {
+	for _, u := range []struct {
+		d    time.Duration
+		code byte
+	}{
+		{24 * time.Hour, 'D'},
+		{time.Hour, 'H'},
+		{time.Minute, 'M'},
+		{time.Second, 'S'},
+	} {
+		if d > 0 && d%u.d == 0 && d/u.d < 100 {
+			return fmt.Sprintf("%02d%c", d/u.d, u.code)
+		}
+	}
+	return "00U"
+}
@@ -0,0 +1,91 @@
+package gnsstime
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCalendar checks the known correspondences of the dates.
+func TestCalendar(t *testing.T) {
+	for _, tt := range []struct {
+		date      time.Time
+		mjd       int
+		year, doy int
+		week, dow int
+	}{
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), 44244, 1980, 6, 0, 0},
+		{time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), 44239, 1980, 1, -1, 2},
+		{time.Date(1999, 8, 22, 0, 0, 0, 0, time.UTC), 51412, 1999, 234, 1024, 0},
+		{time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 51544, 2000, 1, 1042, 6},
+		{time.Date(2000, 12, 31, 0, 0, 0, 0, time.UTC), 51909, 2000, 366, 1095, 0},
+		{time.Date(2019, 4, 7, 0, 0, 0, 0, time.UTC), 58580, 2019, 97, 2048, 0},
+		{time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), 60309, 2023, 365, 2295, 0},
+		{time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), 60505, 2024, 196, 2323, 0},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), 60675, 2024, 366, 2347, 2},
+	} {
+		// any time of the day
+		for _, tod := range []time.Duration{0, 12 * time.Hour, 24*time.Hour - 1} {
+			d := tt.date.Add(tod)
+			if m := MJDDay(d); m != tt.mjd {
+				t.Errorf("%v: MJDDay: get %d, want %d", d, m, tt.mjd)
+			}
+			if y, doy := DOY(d); y != tt.year || doy != tt.doy {
+				t.Errorf("%v: DOY: get %d %d, want %d %d", d, y, doy, tt.year, tt.doy)
+			}
+			if w, dow := GPSWeekDay(d); w != tt.week || dow != tt.dow {
+				t.Errorf("%v: GPSWeekDay: get %d %d, want %d %d", d, w, dow, tt.week, tt.dow)
+			}
+		}
+		if d := FromMJDDay(tt.mjd); !d.Equal(tt.date) {
+			t.Errorf("FromMJDDay(%d): %v", tt.mjd, d)
+		}
+		if d := FromDOY(tt.year, tt.doy); !d.Equal(tt.date) {
+			t.Errorf("FromDOY(%d, %d): %v", tt.year, tt.doy, d)
+		}
+		if d := FromGPSWeekDay(tt.week, tt.dow); !d.Equal(tt.date) {
+			t.Errorf("FromGPSWeekDay(%d, %d): %v", tt.week, tt.dow, d)
+		}
+	}
+
+	// J2000.0
+	j2000 := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+	if m := MJD(j2000); m != 51544.5 {
+		t.Errorf("MJD: %v", m)
+	}
+	if d := FromMJD(51544.5); !d.Equal(j2000) {
+		t.Errorf("FromMJD: %v", d)
+	}
+	ep := time.Date(2024, 7, 14, 23, 59, 59, 123456000, time.UTC)
+	if d := FromMJD(MJD(ep)); d.Sub(ep).Abs() > 10*time.Microsecond {
+		t.Errorf("round trip: %v", d)
+	}
+	if m := MJD(time.Date(1858, 11, 16, 18, 0, 0, 0, time.UTC)); m != -0.25 {
+		t.Errorf("before the origin: %v", m)
+	}
+}
+
+// TestProductName checks the names of the IGS products.
+func TestProductName(t *testing.T) {
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if n := LegacyProductName("igr", t0.Add(6*time.Hour), "sp3"); n != "igr23230.sp3" {
+		t.Errorf("legacy: %s", n)
+	}
+	if n := LegacyProductName("igs", t0.AddDate(0, 0, 6), "clk_30s"); n != "igs23236.clk_30s" {
+		t.Errorf("legacy: %s", n)
+	}
+
+	for _, tt := range []struct {
+		p    Product
+		want string
+	}{
+		{Product{"IGS", 0, "OPS", "RAP", t0, 24 * time.Hour, 15 * time.Minute, "ORB", "sp3"}, "IGS0OPSRAP_20241960000_01D_15M_ORB.SP3"},
+		{Product{"COD", 0, "MGX", "FIN", t0, 24 * time.Hour, 30 * time.Second, "CLK", "CLK"}, "COD0MGXFIN_20241960000_01D_30S_CLK.CLK"},
+		{Product{"IGS", 0, "OPS", "ULT", t0.Add(18 * time.Hour), 48 * time.Hour, 15 * time.Minute, "ORB", "SP3"}, "IGS0OPSULT_20241961800_02D_15M_ORB.SP3"},
+		{Product{"IGS", 0, "OPS", "FIN", t0, 7 * 24 * time.Hour, 24 * time.Hour, "ERP", "ERP"}, "IGS0OPSFIN_20241960000_07D_01D_ERP.ERP"},
+		{Product{"IGS", 0, "OPS", "FIN", t0, 24 * time.Hour, 0, "ATT", "OBX"}, "IGS0OPSFIN_20241960000_01D_00U_ATT.OBX"},
+	} {
+		if n := tt.p.String(); n != tt.want {
+			t.Errorf("get %s, want %s", n, tt.want)
+		}
+	}
+}
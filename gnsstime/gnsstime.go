@@ -0,0 +1,225 @@
+/*
+Package gnsstime converts the epochs between the time scales of GNSS.
+
+An epoch of a time scale is represented by its own type, e.g., GPST, which
+holds the calendar reading of the scale as time.Time in UTC without the leap
+seconds, i.e., the convention of the other packages of the module. The plain
+time.Time is regarded as UTC, and the epochs of different scales are converted
+explicitly, e.g., ToGPST and GPST.UTC, so that mixing the scales is a compile
+error.
+
+The scales are GPS time (GPST), BeiDou time (BDT = GPST - 14 s) and GLONASS
+time (UTC(SU) + 3 h). Galileo system time is aligned to GPST within a few tens
+of nanoseconds and is regarded as GPST.
+
+The leap seconds are those of the table of IERS embedded in the package, and
+the entries announced after the release are registered by AddLeapSeconds or
+LoadLeapSecondsList. The epochs after the expiration of the table are
+converted by the last entry, and are reported by Stale.
+*/
+package gnsstime
+
+import (
+	"math"
+	"time"
+)
+
+// the origins of the weeks in their scales
+var (
+	gpsEpoch = time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC)
+	bdsEpoch = time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// BDTOffset is the difference of GPS time from BDT.
+const BDTOffset = 14 * time.Second
+
+// GLONASSOffset is the difference of GLONASS time from UTC.
+const GLONASSOffset = 3 * time.Hour
+
+// week is the duration of a week.
+const week = 7 * 24 * time.Hour
+
+// layout is the format of the epochs for String.
+const layout = "2006-01-02 15:04:05.999999999"
+
+// GPST is an epoch in GPS time.
+type GPST struct {
+	t time.Time
+}
+
+// NewGPST returns the epoch of the reading t in GPS time, e.g., the epochs of
+// the RINEX observation files. t is converted to the location UTC.
+func NewGPST(t time.Time) GPST {
+	return GPST{t.UTC()}
+}
+
+// ToGPST returns the epoch in GPS time of the epoch utc in UTC.
+func ToGPST(utc time.Time) GPST {
+	return GPST{utc.UTC().Add(time.Duration(LeapSeconds(utc)) * time.Second)}
+}
+
+// FromGPST returns the epoch in UTC of the epoch g in GPS time. The epochs in
+// the inserted leap seconds, which are not represented by time.Time, are
+// those of the start of the next second.
+func FromGPST(g GPST) time.Time {
+	u := g.t.Add(-time.Duration(LeapSeconds(g.t)) * time.Second)
+	if n := LeapSeconds(u); g.t.Sub(u) != time.Duration(n)*time.Second {
+		u = g.t.Add(-time.Duration(n) * time.Second)
+	}
+	return u
+}
+
+// GPSTFromWeek returns the epoch of the full GPS week and the time of week (s).
+// The truncated weeks are to be resolved by ResolveWeek.
+func GPSTFromWeek(w int, tow float64) GPST {
+	return GPST{weekTime(gpsEpoch, w, tow)}
+}
+
+// Time returns the reading in GPS time.
+func (g GPST) Time() time.Time { return g.t }
+
+// UTC returns the epoch in UTC, i.e., FromGPST.
+func (g GPST) UTC() time.Time { return FromGPST(g) }
+
+// BDT returns the epoch in BDT.
+func (g GPST) BDT() BDT { return BDT{g.t.Add(-BDTOffset)} }
+
+// GLONASST returns the epoch in GLONASS time.
+func (g GPST) GLONASST() GLONASST { return ToGLONASST(g.UTC()) }
+
+// Week returns the GPS week and the time of week (s).
+func (g GPST) Week() (w int, tow float64) { return weekOf(gpsEpoch, g.t) }
+
+// Add returns the epoch g+d.
+func (g GPST) Add(d time.Duration) GPST { return GPST{g.t.Add(d)} }
+
+// Sub returns the duration g-u.
+func (g GPST) Sub(u GPST) time.Duration { return g.t.Sub(u.t) }
+
+// Before reports whether g is before u.
+func (g GPST) Before(u GPST) bool { return g.t.Before(u.t) }
+
+// After reports whether g is after u.
+func (g GPST) After(u GPST) bool { return g.t.After(u.t) }
+
+// Equal reports whether g and u are the same epoch.
+func (g GPST) Equal(u GPST) bool { return g.t.Equal(u.t) }
+
+// IsZero reports whether g is the zero value.
+func (g GPST) IsZero() bool { return g.t.IsZero() }
+
+// String returns the reading with the scale, e.g., "2024-07-14 00:00:18 GPST".
+func (g GPST) String() string { return g.t.Format(layout) + " GPST" }
+
+// BDT is an epoch in BeiDou time.
+type BDT struct {
+	t time.Time
+}
+
+// NewBDT returns the epoch of the reading t in BDT. t is converted to the
+// location UTC.
+func NewBDT(t time.Time) BDT {
+	return BDT{t.UTC()}
+}
+
+// BDTFromWeek returns the epoch of the BeiDou week and the seconds of week.
+func BDTFromWeek(w int, sow float64) BDT {
+	return BDT{weekTime(bdsEpoch, w, sow)}
+}
+
+// Time returns the reading in BDT.
+func (b BDT) Time() time.Time { return b.t }
+
+// GPST returns the epoch in GPS time.
+func (b BDT) GPST() GPST { return GPST{b.t.Add(BDTOffset)} }
+
+// UTC returns the epoch in UTC.
+func (b BDT) UTC() time.Time { return b.GPST().UTC() }
+
+// Week returns the BeiDou week and the seconds of week.
+func (b BDT) Week() (w int, sow float64) { return weekOf(bdsEpoch, b.t) }
+
+// Add returns the epoch b+d.
+func (b BDT) Add(d time.Duration) BDT { return BDT{b.t.Add(d)} }
+
+// Sub returns the duration b-u.
+func (b BDT) Sub(u BDT) time.Duration { return b.t.Sub(u.t) }
+
+// Before reports whether b is before u.
+func (b BDT) Before(u BDT) bool { return b.t.Before(u.t) }
+
+// After reports whether b is after u.
+func (b BDT) After(u BDT) bool { return b.t.After(u.t) }
+
+// Equal reports whether b and u are the same epoch.
+func (b BDT) Equal(u BDT) bool { return b.t.Equal(u.t) }
+
+// IsZero reports whether b is the zero value.
+func (b BDT) IsZero() bool { return b.t.IsZero() }
+
+// String returns the reading with the scale, e.g., "2024-07-14 00:00:04 BDT".
+func (b BDT) String() string { return b.t.Format(layout) + " BDT" }
+
+// GLONASST is an epoch in GLONASS time, i.e., UTC(SU) + 3 h, which has the
+// leap seconds of UTC.
+type GLONASST struct {
+	t time.Time
+}
+
+// NewGLONASST returns the epoch of the reading t in GLONASS time. t is
+// converted to the location UTC.
+func NewGLONASST(t time.Time) GLONASST {
+	return GLONASST{t.UTC()}
+}
+
+// ToGLONASST returns the epoch in GLONASS time of the epoch utc in UTC.
+func ToGLONASST(utc time.Time) GLONASST {
+	return GLONASST{utc.UTC().Add(GLONASSOffset)}
+}
+
+// Time returns the reading in GLONASS time.
+func (r GLONASST) Time() time.Time { return r.t }
+
+// UTC returns the epoch in UTC.
+func (r GLONASST) UTC() time.Time { return r.t.Add(-GLONASSOffset) }
+
+// GPST returns the epoch in GPS time.
+func (r GLONASST) GPST() GPST { return ToGPST(r.UTC()) }
+
+// Add returns the epoch r+d.
+func (r GLONASST) Add(d time.Duration) GLONASST { return GLONASST{r.t.Add(d)} }
+
+// Sub returns the duration r-u.
+func (r GLONASST) Sub(u GLONASST) time.Duration { return r.t.Sub(u.t) }
+
+// Before reports whether r is before u.
+func (r GLONASST) Before(u GLONASST) bool { return r.t.Before(u.t) }
+
+// After reports whether r is after u.
+func (r GLONASST) After(u GLONASST) bool { return r.t.After(u.t) }
+
+// Equal reports whether r and u are the same epoch.
+func (r GLONASST) Equal(u GLONASST) bool { return r.t.Equal(u.t) }
+
+// IsZero reports whether r is the zero value.
+func (r GLONASST) IsZero() bool { return r.t.IsZero() }
+
+// String returns the reading with the scale, e.g., "2024-07-14 03:00:00
+// GLONASST".
+func (r GLONASST) String() string { return r.t.Format(layout) + " GLONASST" }
+
+// weekTime returns the epoch of the week from the origin and the seconds of
+// the week, which is rounded to nanoseconds.
+func weekTime(origin time.Time, w int, sow float64) time.Time {
+	return origin.Add(time.Duration(w) * week).Add(time.Duration(math.Round(sow * 1e9)))
+}
+
+// weekOf returns the week from the origin and the seconds of the week of t.
+func weekOf(origin, t time.Time) (w int, sow float64) {
+	d := t.Sub(origin)
+	w = int(d / week)
+	if d < 0 && d%week != 0 {
+		w--
+	}
+	return w, (d - time.Duration(w)*week).Seconds()
+}
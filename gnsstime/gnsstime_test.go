@@ -0,0 +1,98 @@
+package gnsstime
+
+import (
+	"testing"
+	"time"
+)
+
+// TestGPST checks the conversions of GPS time and UTC across the leap second
+// of 2017.
+func TestGPST(t *testing.T) {
+	for _, tt := range []struct {
+		utc, gpst time.Time
+	}{
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(2017, 1, 1, 0, 0, 16, 0, time.UTC)},
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2017, 1, 1, 0, 0, 18, 0, time.UTC)},
+		{time.Date(2024, 7, 14, 0, 0, 0, 500, time.UTC), time.Date(2024, 7, 14, 0, 0, 18, 500, time.UTC)},
+	} {
+		g := ToGPST(tt.utc)
+		if !g.Time().Equal(tt.gpst) {
+			t.Errorf("ToGPST(%v): get %v, want %v", tt.utc, g, tt.gpst)
+		}
+		if u := FromGPST(g); !u.Equal(tt.utc) {
+			t.Errorf("FromGPST(%v): get %v, want %v", g, u, tt.utc)
+		}
+	}
+
+	// the inserted leap second is of the next second
+	if u := NewGPST(time.Date(2017, 1, 1, 0, 0, 17, 0, time.UTC)).UTC(); !u.Equal(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("leap second: %v", u)
+	}
+
+	// in the other locations
+	jst := time.FixedZone("JST", 9*3600)
+	if g := ToGPST(time.Date(2024, 7, 14, 9, 0, 0, 0, jst)); g.String() != "2024-07-14 00:00:18 GPST" {
+		t.Errorf("JST: %v", g)
+	}
+}
+
+// TestWeek checks the GPS weeks including the rollovers of 1999 and 2019.
+func TestWeek(t *testing.T) {
+	for _, tt := range []struct {
+		gpst time.Time
+		week int
+		tow  float64
+	}{
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), 0, 0},
+		{time.Date(1999, 8, 22, 0, 0, 0, 0, time.UTC), 1024, 0},
+		{time.Date(1999, 8, 21, 23, 59, 59, 0, time.UTC), 1023, 604799},
+		{time.Date(2019, 4, 7, 0, 0, 0, 0, time.UTC), 2048, 0},
+		{time.Date(2024, 7, 14, 0, 0, 18, 0, time.UTC), 2323, 18},
+		{time.Date(2024, 7, 13, 12, 0, 0, 250e6, time.UTC), 2322, 561600.25},
+		{time.Date(1980, 1, 5, 0, 0, 0, 0, time.UTC), -1, 518400},
+	} {
+		g := NewGPST(tt.gpst)
+		if w, tow := g.Week(); w != tt.week || tow != tt.tow {
+			t.Errorf("%v: get %d %v, want %d %v", g, w, tow, tt.week, tt.tow)
+		}
+		if e := GPSTFromWeek(tt.week, tt.tow); !e.Equal(g) {
+			t.Errorf("GPSTFromWeek(%d, %v): get %v, want %v", tt.week, tt.tow, e, g)
+		}
+	}
+}
+
+// TestBDT checks the conversions of BDT.
+func TestBDT(t *testing.T) {
+	g := NewGPST(time.Date(2006, 1, 1, 0, 0, 14, 0, time.UTC))
+	b := g.BDT()
+	if w, sow := b.Week(); w != 0 || sow != 0 || b.String() != "2006-01-01 00:00:00 BDT" {
+		t.Errorf("origin: %v, week %d %v", b, w, sow)
+	}
+	if !b.GPST().Equal(g) || !b.UTC().Equal(time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GPST %v, UTC %v", b.GPST(), b.UTC())
+	}
+
+	b = BDTFromWeek(967, 86400+3600)
+	if b.GPST().Time() != time.Date(2024, 7, 15, 1, 0, 14, 0, time.UTC) {
+		t.Errorf("BDTFromWeek: %v", b.GPST())
+	}
+	if d := b.Sub(NewBDT(time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC))); d != time.Hour {
+		t.Errorf("Sub: %v", d)
+	}
+}
+
+// TestGLONASST checks the conversions of GLONASS time.
+func TestGLONASST(t *testing.T) {
+	utc := time.Date(2024, 7, 13, 22, 0, 0, 0, time.UTC)
+	r := ToGLONASST(utc)
+	if r.String() != "2024-07-14 01:00:00 GLONASST" || !r.UTC().Equal(utc) {
+		t.Errorf("ToGLONASST: %v", r)
+	}
+	if g := r.GPST(); !g.Equal(ToGPST(utc)) || !g.GLONASST().Equal(r) {
+		t.Errorf("GPST: %v", g)
+	}
+	if !r.Add(time.Hour).After(r) || !r.Before(r.Add(1)) || r.IsZero() {
+		t.Errorf("comparison")
+	}
+}
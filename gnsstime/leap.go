@@ -0,0 +1,192 @@
+package gnsstime
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLeapSeconds is returned for the invalid entries of the leap seconds.
+var ErrLeapSeconds = errors.New("invalid leap seconds")
+
+// gpsTAI is the difference of TAI from GPS time (s).
+const gpsTAI = 19
+
+// ntpEpoch is the origin of the timestamps of leap-seconds.list.
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// LeapSecond is an entry of the table of the leap seconds, i.e., the value of
+// TAI-UTC (s) since the epoch in UTC.
+type LeapSecond struct {
+	Since  time.Time
+	TAIUTC int
+}
+
+// leapSecondsList is leap-seconds.list of IERS as released, whose hash "#h"
+// is that of the release, to be replaced by the new release every six months.
+//
+//go:embed leap-seconds.list
+var leapSecondsList []byte
+
+// leapTable is the table of the leap seconds in the order of Since, and the
+// expiration date of the table.
+var leapTable struct {
+	sync.RWMutex
+	entries []LeapSecond
+	expires time.Time
+}
+
+func init() {
+	entries, expires, err := ParseLeapSecondsList(bytes.NewReader(leapSecondsList))
+	if err != nil {
+		panic(fmt.Sprintf("gnsstime: embedded leap-seconds.list: %v", err))
+	}
+	leapTable.entries, leapTable.expires = entries, expires
+}
+
+// TAIUTC returns TAI-UTC (s) at the epoch utc in UTC, which is zero before
+// 1972, where UTC had the fractional offsets.
+func TAIUTC(utc time.Time) int {
+	leapTable.RLock()
+	defer leapTable.RUnlock()
+
+	es := leapTable.entries
+	for k := len(es) - 1; k >= 0; k-- {
+		if !utc.Before(es[k].Since) {
+			return es[k].TAIUTC
+		}
+	}
+	return 0
+}
+
+// LeapSeconds returns the difference of GPS time from UTC (s) at the epoch
+// utc in UTC, i.e., TAI-UTC - 19, which is valid since the GPS epoch.
+//
+// The epochs after Expires are of the last entry of the table, which may be
+// stale (see Stale).
+func LeapSeconds(utc time.Time) int {
+	return TAIUTC(utc) - gpsTAI
+}
+
+// Expires returns the expiration date of the table, after which a new leap
+// second may have been introduced.
+func Expires() time.Time {
+	leapTable.RLock()
+	defer leapTable.RUnlock()
+	return leapTable.expires
+}
+
+// Stale reports whether the epoch utc in UTC is after the expiration date of
+// the table, i.e., the leap seconds at utc may not be those of the table.
+func Stale(utc time.Time) bool {
+	return utc.After(Expires())
+}
+
+// LeapSecondTable returns the entries of the table.
+func LeapSecondTable() []LeapSecond {
+	leapTable.RLock()
+	defer leapTable.RUnlock()
+	return append([]LeapSecond(nil), leapTable.entries...)
+}
+
+// AddLeapSeconds registers the entries of the leap seconds in addition to the
+// table, and extends the expiration date to expires if later. The entries at
+// the start of a day in UTC are accepted, and those of the epochs in the table
+// should have the same values.
+func AddLeapSeconds(expires time.Time, entries ...LeapSecond) error {
+	for _, e := range entries {
+		if s := e.Since.UTC(); s.Hour() != 0 || s.Minute() != 0 || s.Second() != 0 || s.Nanosecond() != 0 {
+			return fmt.Errorf("%w: not at the start of a day: %v", ErrLeapSeconds, e.Since)
+		}
+	}
+
+	leapTable.Lock()
+	defer leapTable.Unlock()
+
+	es := append([]LeapSecond(nil), leapTable.entries...)
+	for _, e := range entries {
+		e.Since = e.Since.UTC()
+		k := sort.Search(len(es), func(i int) bool { return !es[i].Since.Before(e.Since) })
+		switch {
+		case k < len(es) && es[k].Since.Equal(e.Since):
+			if es[k].TAIUTC != e.TAIUTC {
+				return fmt.Errorf("%w: TAI-UTC since %v: %d, want %d", ErrLeapSeconds, e.Since, e.TAIUTC, es[k].TAIUTC)
+			}
+		default:
+			es = append(es[:k], append([]LeapSecond{e}, es[k:]...)...)
+		}
+	}
+
+	leapTable.entries = es
+	if expires.After(leapTable.expires) {
+		leapTable.expires = expires
+	}
+	return nil
+}
+
+// LoadLeapSecondsList registers the entries and the expiration date of a
+// leap-seconds.list of IERS, e.g., /usr/share/zoneinfo/leap-seconds.list.
+func LoadLeapSecondsList(r io.Reader) error {
+	entries, expires, err := ParseLeapSecondsList(r)
+	if err != nil {
+		return err
+	}
+	return AddLeapSeconds(expires, entries...)
+}
+
+// ParseLeapSecondsList parses a leap-seconds.list of IERS, i.e., the lines of
+// the NTP timestamp (s since 1900) of the epoch and the value of TAI-UTC, and
+// the expiration date of the line "#@". The other comments including the hash
+// are ignored.
+func ParseLeapSecondsList(r io.Reader) (entries []LeapSecond, expires time.Time, err error) {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		l := s.Text()
+		if strings.HasPrefix(l, "#@") {
+			ts, err := strconv.ParseInt(strings.TrimSpace(l[2:]), 10, 64)
+			if err != nil {
+				return nil, expires, fmt.Errorf("%w: line %d: expiration: %v", ErrLeapSeconds, n, err)
+			}
+			expires = ntpEpoch.Add(time.Duration(ts) * time.Second)
+			continue
+		}
+		if i := strings.IndexByte(l, '#'); i >= 0 {
+			l = l[:i]
+		}
+		fs := strings.Fields(l)
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) != 2 {
+			return nil, expires, fmt.Errorf("%w: line %d: '%s'", ErrLeapSeconds, n, l)
+		}
+		ts, err := strconv.ParseInt(fs[0], 10, 64)
+		if err != nil {
+			return nil, expires, fmt.Errorf("%w: line %d: timestamp: %v", ErrLeapSeconds, n, err)
+		}
+		v, err := strconv.Atoi(fs[1])
+		if err != nil {
+			return nil, expires, fmt.Errorf("%w: line %d: TAI-UTC: %v", ErrLeapSeconds, n, err)
+		}
+		since := ntpEpoch.Add(time.Duration(ts) * time.Second)
+		if k := len(entries); k > 0 && !since.After(entries[k-1].Since) {
+			return nil, expires, fmt.Errorf("%w: line %d: not in the order of the epochs", ErrLeapSeconds, n)
+		}
+		entries = append(entries, LeapSecond{since, v})
+	}
+	if err := s.Err(); err != nil {
+		return nil, expires, err
+	}
+	if len(entries) == 0 {
+		return nil, expires, fmt.Errorf("%w: no entries", ErrLeapSeconds)
+	}
+	return entries, expires, nil
+}
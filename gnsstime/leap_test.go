@@ -0,0 +1,104 @@
+package gnsstime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLeapSeconds checks the table of the leap seconds.
+func TestLeapSeconds(t *testing.T) {
+	for _, tt := range []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC), 17},
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 18},
+		{time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), 18},
+	} {
+		if n := LeapSeconds(tt.t); n != tt.want {
+			t.Errorf("%v: get %d, want %d", tt.t, n, tt.want)
+		}
+	}
+}
+
+// TestTAIUTC checks TAI-UTC of the embedded table and its expiration.
+func TestTAIUTC(t *testing.T) {
+	for _, tt := range []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(1971, 12, 31, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), 19},
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 37},
+	} {
+		if n := TAIUTC(tt.t); n != tt.want {
+			t.Errorf("%v: get %d, want %d", tt.t, n, tt.want)
+		}
+	}
+
+	if exp := Expires(); !exp.Equal(time.Date(2026, 6, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expires: %v", exp)
+	}
+	if Stale(time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)) || !Stale(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Stale")
+	}
+	// still converted by the last entry
+	if n := LeapSeconds(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)); n != 18 {
+		t.Errorf("after the expiration: %d", n)
+	}
+	if es := LeapSecondTable(); len(es) != 28 || es[27].TAIUTC != 37 {
+		t.Errorf("LeapSecondTable: %+v", es)
+	}
+}
+
+// TestAddLeapSeconds checks the registration of a hypothetical leap second
+// and the validation of the entries.
+func TestAddLeapSeconds(t *testing.T) {
+	entries, expires := LeapSecondTable(), Expires()
+	t.Cleanup(func() {
+		leapTable.entries, leapTable.expires = entries, expires
+	})
+
+	// a hypothetical leap second of 2028 expiring on 2028-06-28
+	list := `#@	4054752000
+3692217600	37	# 1 Jan 2017
+4039286400	38	# 1 Jan 2028
+`
+	es, exp, err := ParseLeapSecondsList(strings.NewReader(list))
+	if err != nil || len(es) != 2 || !es[1].Since.Equal(time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)) || !exp.Equal(time.Date(2028, 6, 28, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ParseLeapSecondsList: %+v %v (err=%v)", es, exp, err)
+	}
+	since := es[1].Since
+	if err := LoadLeapSecondsList(strings.NewReader(list)); err != nil {
+		t.Fatalf("LoadLeapSecondsList: %v", err)
+	}
+	if n := LeapSeconds(since); n != 19 || LeapSeconds(since.Add(-time.Second)) != 18 || !Expires().Equal(exp) {
+		t.Errorf("registered: %d, expires %v", n, Expires())
+	}
+	g := ToGPST(since)
+	if g.Sub(NewGPST(since)) != 19*time.Second || !FromGPST(g).Equal(since) {
+		t.Errorf("ToGPST: %v", g)
+	}
+
+	for _, tt := range []struct {
+		name string
+		e    LeapSecond
+	}{
+		{"not at the start of a day", LeapSecond{time.Date(2029, 1, 1, 12, 0, 0, 0, time.UTC), 39}},
+		{"inconsistent", LeapSecond{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 36}},
+	} {
+		if err := AddLeapSeconds(time.Time{}, tt.e); !errors.Is(err, ErrLeapSeconds) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrLeapSeconds)
+		}
+	}
+	for _, in := range []string{"", "3692217600\n", "3692217600 37\n3644697600 36\n", "#@ x\n3692217600 37\n"} {
+		if _, _, err := ParseLeapSecondsList(strings.NewReader(in)); !errors.Is(err, ErrLeapSeconds) {
+			t.Errorf("'%s': get err=%v, want %v", in, err, ErrLeapSeconds)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package gnsstime
+
+import "math"
+
+// WeekRollover is the modulus of the 10-bit GPS weeks of the legacy
+// navigation messages and the receivers.
+const WeekRollover = 1024
+
+// ResolveWeek returns the full GPS week of the truncated week, i.e., the
+// week modulo WeekRollover, nearest to the week of the reference epoch ref.
+// The week out of [0, WeekRollover) is regarded as a full week and returned
+// unchanged.
+func ResolveWeek(week int, ref GPST) int {
+	if week < 0 || week >= WeekRollover {
+		return week
+	}
+	rw, _ := ref.Week()
+	n := math.Round(float64(rw-week) / WeekRollover)
+	return week + int(n)*WeekRollover
+}
+
+// ResolveWeekNotBefore returns the earliest full GPS week of the truncated
+// week not before the week of the epoch notBefore, e.g., the date of the
+// release of a receiver. The week out of [0, WeekRollover) is returned
+// unchanged as ResolveWeek.
+func ResolveWeekNotBefore(week int, notBefore GPST) int {
+	if week < 0 || week >= WeekRollover {
+		return week
+	}
+	nw, _ := notBefore.Week()
+	n := math.Ceil(float64(nw-week) / WeekRollover)
+	return week + int(n)*WeekRollover
+}
@@ -0,0 +1,53 @@
+package gnsstime
+
+import (
+	"testing"
+	"time"
+)
+
+// TestResolveWeek checks the truncated weeks around the rollovers of 1999 and
+// 2019.
+func TestResolveWeek(t *testing.T) {
+	date := func(y, m, d int) GPST { return NewGPST(time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)) }
+
+	for _, tt := range []struct {
+		week int
+		ref  GPST
+		want int
+	}{
+		{1023, date(1999, 8, 20), 1023},
+		{0, date(1999, 8, 20), 1024},
+		{1023, date(1999, 8, 25), 1023},
+		{0, date(1999, 8, 25), 1024},
+		{1023, date(2019, 4, 5), 2047},
+		{0, date(2019, 4, 5), 2048},
+		{1023, date(2019, 4, 10), 2047},
+		{0, date(2019, 4, 10), 2048},
+		{275, date(2024, 7, 14), 2323},
+		{156, date(1983, 1, 2), 156},
+		{2323, date(1999, 1, 1), 2323}, // full week
+	} {
+		if w := ResolveWeek(tt.week, tt.ref); w != tt.want {
+			t.Errorf("ResolveWeek(%d, %v): get %d, want %d", tt.week, tt.ref, w, tt.want)
+		}
+	}
+
+	// week 156 is of 1982, 2002 and 2022
+	for _, tt := range []struct {
+		notBefore GPST
+		want      int
+	}{
+		{date(1980, 1, 6), 156},
+		{date(1983, 1, 8), 156},
+		{date(1983, 1, 9), 1180},
+		{date(1999, 8, 22), 1180},
+		{date(2019, 4, 7), 2204},
+	} {
+		if w := ResolveWeekNotBefore(156, tt.notBefore); w != tt.want {
+			t.Errorf("ResolveWeekNotBefore(156, %v): get %d, want %d", tt.notBefore, w, tt.want)
+		}
+	}
+	if y := GPSTFromWeek(ResolveWeekNotBefore(156, date(2019, 4, 7)), 0).Time().Year(); y != 2022 {
+		t.Errorf("year: %d", y)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Package field provides the parsers of the fields of the text formats shared
+by the readers of the module, e.g., RINEX, SP3 and RINEX clock.
+
+The errors are not wrapped by the sentinel errors of the readers, which are
+to be wrapped by the callers, e.g., ErrFormat of the package.
+*/
+package field
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// Epoch parses an epoch of the year, month, day, hour, minute and seconds
+// separated by the spaces, e.g., "2024  7 14  0  0  0.00000000". The
+// two-digit years are those of 1980-2079.
+func Epoch(s string) (time.Time, error) {
+	fs := strings.Fields(s)
+	if len(fs) != 6 {
+		return time.Time{}, fmt.Errorf("epoch: '%s'", strings.TrimSpace(s))
+	}
+	var v [5]int
+	for k := range v {
+		n, err := strconv.Atoi(fs[k])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("epoch: %v", err)
+		}
+		v[k] = n
+	}
+	sec, err := strconv.ParseFloat(fs[5], 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("epoch: %v", err)
+	}
+
+	switch {
+	case v[0] < 80:
+		v[0] += 2000
+	case v[0] < 100:
+		v[0] += 1900
+	}
+	t := time.Date(v[0], time.Month(v[1]), v[2], v[3], v[4], 0, 0, time.UTC)
+	return t.Add(time.Duration(math.Round(sec * 1e9))), nil
+}
+
+// SatID parses the satellite ID of three columns, e.g., "G01", or of two
+// columns of the number, e.g., " 1" of RINEX 2 navigation files. The blank
+// system is that of the file sys, which is GPS if blank, and the blank of
+// the number is zero.
+func SatID(s string, sys byte) (gnss.SatID, error) {
+	if len(s) == 2 {
+		s = " " + s
+	}
+	if len(s) == 3 && s[0] == ' ' {
+		if sys == ' ' || sys == 0 {
+			sys = 'G'
+		}
+		s = string(sys) + s[1:]
+	}
+	return gnss.ParseSatID(s)
+}
+
+// Atoi parses an integer field with the spaces.
+func Atoi(s string) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(s))
+}
+
+// AtoiBlank parses an integer field, which is zero if blank.
+func AtoiBlank(s string) (int, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return Atoi(s)
+}
+
+// Atof parses a float field with the spaces. The exponent of "D" is
+// accepted, e.g., "0.123456789012D-04".
+func Atof(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Map(func(r rune) rune {
+		if r == 'D' || r == 'd' {
+			return 'E'
+		}
+		return r
+	}, strings.TrimSpace(s)), 64)
+}
+
+// AtofBlank parses a float field, which is zero if blank.
+func AtofBlank(s string) (float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return Atof(s)
+}
@@ -0,0 +1,80 @@
+package field
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// TestEpoch checks the epochs of the four-digit and the two-digit years.
+func TestEpoch(t *testing.T) {
+	for s, want := range map[string]time.Time{
+		"2024  7 14  0  0  0.00000000": time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC),
+		"24  7 14  1  2 30.5":          time.Date(2024, 7, 14, 1, 2, 30, 5e8, time.UTC),
+		"99 12 31 23 59 59.9999999":    time.Date(1999, 12, 31, 23, 59, 59, 999999900, time.UTC),
+	} {
+		if got, err := Epoch(s); err != nil || !got.Equal(want) {
+			t.Errorf("'%s': get %v (err=%v), want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"2024  7 14  0  0", "2024  7 14  0 x0  0.0", "2024  7 14  0  0  0.0x"} {
+		if _, err := Epoch(s); err == nil {
+			t.Errorf("'%s': no error", s)
+		}
+	}
+}
+
+// TestSatID checks the blank system and the blank of the number.
+func TestSatID(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		sys  byte
+		want gnss.SatID
+	}{
+		{"G01", 'M', gnss.SatID{Sys: gnss.GPS, PRN: 1}},
+		{"E 5", 'M', gnss.SatID{Sys: gnss.Galileo, PRN: 5}},
+		{" 12", 'R', gnss.SatID{Sys: gnss.GLONASS, PRN: 12}},
+		{" 12", ' ', gnss.SatID{Sys: gnss.GPS, PRN: 12}},
+		{" 3", 'E', gnss.SatID{Sys: gnss.Galileo, PRN: 3}},
+	} {
+		if got, err := SatID(tt.s, tt.sys); err != nil || got != tt.want {
+			t.Errorf("'%s' of %c: get %v (err=%v), want %v", tt.s, tt.sys, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"G00", "X01", "G1x", "G  "} {
+		if _, err := SatID(s, 'G'); err == nil {
+			t.Errorf("'%s': no error", s)
+		}
+	}
+}
+
+// TestAtof checks the exponents of "D" and "E".
+func TestAtof(t *testing.T) {
+	for s, want := range map[string]float64{
+		"0.123456789012D-04":  0.123456789012e-04,
+		" -.123456789012d+01": -1.23456789012,
+		"1.5E+00":             1.5,
+		"  7":                 7,
+	} {
+		if v, err := Atof(s); err != nil || v != want {
+			t.Errorf("'%s': get %v (err=%v), want %v", s, v, err, want)
+		}
+	}
+	if _, err := Atof("0.1X-04"); err == nil {
+		t.Errorf("no error for an invalid value")
+	}
+}
+
+// TestBlank checks the blank fields are zero.
+func TestBlank(t *testing.T) {
+	if n, err := AtoiBlank("   "); n != 0 || err != nil {
+		t.Errorf("AtoiBlank: get %d, err=%v", n, err)
+	}
+	if v, err := AtofBlank(""); v != 0 || err != nil {
+		t.Errorf("AtofBlank: get %v, err=%v", v, err)
+	}
+	if _, err := Atoi("  "); err == nil {
+		t.Errorf("Atoi: no error for blank")
+	}
+}
@@ -0,0 +1,139 @@
+package iono
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrNoTEC is returned when TEC is not available at the pierce point, i.e.,
+// out of the epochs or the grids of the maps, or missing.
+var ErrNoTEC = errors.New("no tec of the maps")
+
+// tecDelay is the coefficient of the group delay (m) of TEC (TECU) on the
+// frequency f (Hz), i.e., the delay is tecDelay*TEC/f^2.
+const tecDelay = 40.3e16
+
+// earthRotation is the angular rate of the rotation of the maps with the
+// sun (rad/s).
+const earthRotation = 2 * math.Pi / 86400
+
+// IONEXOpts is the options of the evaluation of the maps.
+type IONEXOpts struct {
+	// Rotate interpolates the maps in time rotated by the earth rotation
+	// about the sun, i.e., in the solar-fixed frame, as recommended for the
+	// maps of the intervals of hours (Schaer et al., 1998), or the maps are
+	// interpolated at the same longitudes if false.
+	Rotate bool
+}
+
+// PiercePoint returns the geodetic latitude and the longitude (rad) of the
+// ionospheric pierce point of the satellite at the azimuth az and the
+// elevation el seen from the receiver at lat and lon on the single layer of
+// the height h above the sphere of the radius re (m), and the zenith angle
+// (rad) of the line of sight at the pierce point.
+func PiercePoint(lat, lon, az, el, re, h float64) (plat, plon, zp float64) {
+	z := math.Pi/2 - el
+	zp = math.Asin(re / (re + h) * math.Sin(z))
+	a := z - zp
+
+	sinLat, cosLat := math.Sincos(lat)
+	sinA, cosA := math.Sincos(a)
+	sinAz, cosAz := math.Sincos(az)
+	plat = math.Asin(sinLat*cosA + cosLat*sinA*cosAz)
+	plon = lon + math.Asin(sinA*sinAz/math.Cos(plat))
+	return plat, plon, zp
+}
+
+// VTEC returns the vertical TEC (TECU) at the latitude and the longitude
+// (rad) at the epoch t in UT, interpolated bilinearly in the grids of the
+// maps and linearly between the epochs of the maps. ErrNoTEC is returned
+// for t out of the maps, the position out of the grids, and the missing
+// values of the grids around the position.
+func (x *IONEX) VTEC(t time.Time, lat, lon float64, opts IONEXOpts) (float64, error) {
+	n := len(x.Maps)
+	if n == 0 || t.Before(x.Maps[0].Epoch) || t.After(x.Maps[n-1].Epoch) {
+		return 0, fmt.Errorf("%w: epoch out of the maps: %v", ErrNoTEC, t)
+	}
+	if n == 1 {
+		return x.interpolate(x.Maps[0], lat, lon)
+	}
+	i := 0
+	for i+1 < n-1 && !t.Before(x.Maps[i+1].Epoch) {
+		i++
+	}
+
+	m0, m1 := x.Maps[i], x.Maps[i+1]
+	dt0, dt1 := t.Sub(m0.Epoch).Seconds(), m1.Epoch.Sub(t).Seconds()
+	lon0, lon1 := lon, lon
+	if opts.Rotate {
+		lon0 += earthRotation * dt0
+		lon1 -= earthRotation * dt1
+	}
+	e0, err := x.interpolate(m0, lat, lon0)
+	if err != nil {
+		return 0, err
+	}
+	e1, err := x.interpolate(m1, lat, lon1)
+	if err != nil {
+		return 0, err
+	}
+	return (dt1*e0 + dt0*e1) / (dt0 + dt1), nil
+}
+
+// interpolate returns the value of the map at the latitude and the
+// longitude (rad) interpolated bilinearly.
+func (x *IONEX) interpolate(m Map, lat, lon float64) (float64, error) {
+	latDeg, lonDeg := lat*180/math.Pi, lon*180/math.Pi
+	if x.Lon2-x.Lon1 >= 360 {
+		lonDeg = x.Lon1 + math.Mod(math.Mod(lonDeg-x.Lon1, 360)+360, 360)
+	}
+
+	i, p, ok := cell((latDeg-x.Lat1)/x.DLat, x.nlat())
+	j, q, ok2 := cell((lonDeg-x.Lon1)/x.DLon, x.nlon())
+	if !ok || !ok2 {
+		return 0, fmt.Errorf("%w: out of the grids: lat=%.3f, lon=%.3f", ErrNoTEC, latDeg, lonDeg)
+	}
+
+	v := m.Values
+	e := (1-p)*(1-q)*v[i][j] + p*(1-q)*v[i+1][j] + (1-p)*q*v[i][j+1] + p*q*v[i+1][j+1]
+	if math.IsNaN(e) {
+		return 0, fmt.Errorf("%w: missing value at lat=%.3f, lon=%.3f of %v", ErrNoTEC, latDeg, lonDeg, m.Epoch)
+	}
+	return e, nil
+}
+
+// cell returns the index of the grid before the fractional index u of the n
+// grids and the fraction of u in the cell, and false if out of the grids.
+func cell(u float64, n int) (int, float64, bool) {
+	const eps = 1e-9
+	if u < -eps || u > float64(n-1)+eps || n < 2 {
+		return 0, 0, false
+	}
+	k := min(int(math.Floor(u)), n-2)
+	k = max(k, 0)
+	return k, u - float64(k), true
+}
+
+// Delay returns the slant ionospheric delay (m) on the frequency f (Hz) of
+// the satellite at the azimuth az and the elevation el seen from the
+// receiver at the geodetic latitude lat and the longitude lon at the epoch t
+// in UT, i.e., 40.3e16*VTEC/f^2 at the pierce point of the single layer of
+// the maps scaled by the mapping function 1/cos(z') of the zenith angle z'
+// at the pierce point (COSZ of IONEX). Zero is returned for a satellite
+// below the horizon.
+//
+// Unlike the other models of the package, the delay is that of f rather
+// than GPS L1.
+func (x *IONEX) Delay(t time.Time, lat, lon, az, el, f float64, opts IONEXOpts) (float64, error) {
+	if el <= 0 {
+		return 0, nil
+	}
+	plat, plon, zp := PiercePoint(lat, lon, az, el, x.BaseRadius, x.Height)
+	vtec, err := x.VTEC(t, plat, plon, opts)
+	if err != nil {
+		return 0, err
+	}
+	return tecDelay * vtec / (f * f) / math.Cos(zp), nil
+}
@@ -0,0 +1,311 @@
+package iono
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const deg = math.Pi / 180
+
+// TestPiercePoint checks the pierce points at the zenith and toward the
+// north, where the earth-centered angle is z - z'.
+func TestPiercePoint(t *testing.T) {
+	re, h := 6371e3, 450e3
+	lat, lon := 35*deg, 140*deg
+	if plat, plon, zp := PiercePoint(lat, lon, 1, math.Pi/2, re, h); math.Abs(plat-lat) > 1e-12 || math.Abs(plon-lon) > 1e-12 || math.Abs(zp) > 1e-12 {
+		t.Errorf("zenith: get %v %v %v", plat, plon, zp)
+	}
+
+	el := 30 * deg
+	zp := math.Asin(re / (re + h) * math.Cos(el))
+	a := math.Pi/2 - el - zp
+	plat, plon, gzp := PiercePoint(lat, lon, 0, el, re, h)
+	if math.Abs(plat-lat-a) > 1e-12 || math.Abs(plon-lon) > 1e-12 || math.Abs(gzp-zp) > 1e-12 {
+		t.Errorf("north: get %v %v %v, want %v %v %v", plat, plon, gzp, lat+a, lon, zp)
+	}
+	// about 6 deg of the earth-centered angle at 30 deg elevation
+	if a < 5.5*deg || a > 6.5*deg {
+		t.Errorf("earth-centered angle: %v deg", a/deg)
+	}
+}
+
+// TestVTEC checks the values interpolated in the grids and the epochs of the
+// linear TEC of the fixture, and the errors.
+func TestVTEC(t *testing.T) {
+	x, err := ReadIONEX(testIONEXFile)
+	if err != nil {
+		t.Fatalf("ReadIONEX: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		min      int
+		lat, lon float64
+		want     float64
+	}{
+		{0, 35, 140, testTEC(0, 35, 140)},
+		{0, 33.3, 131.7, testTEC(0, 33.3, 131.7)},
+		{0, 30, 150, testTEC(0, 30, 150)},
+		{180, 33.3, 131.7, testTEC(1.5, 33.3, 131.7)},
+		{120, 36, 147, testTEC(1, 36, 147)},
+		{240, 31, 149, testTEC(2, 31, 149)},
+	} {
+		get, err := x.VTEC(t0.Add(time.Duration(tt.min)*time.Minute), tt.lat*deg, tt.lon*deg, IONEXOpts{})
+		if err != nil || math.Abs(get-tt.want) > 1e-9 {
+			t.Errorf("%d min (%.1f, %.1f): get %v (err=%v), want %v", tt.min, tt.lat, tt.lon, get, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		min      int
+		lat, lon float64
+	}{
+		{"missing", 60, 39, 148},
+		{"latitude", 0, 41, 140},
+		{"longitude", 0, 35, 129},
+		{"before", -1, 35, 140},
+		{"after", 241, 35, 140},
+	} {
+		if _, err := x.VTEC(t0.Add(time.Duration(tt.min)*time.Minute), tt.lat*deg, tt.lon*deg, IONEXOpts{}); !errors.Is(err, ErrNoTEC) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrNoTEC)
+		}
+	}
+}
+
+// testGlobalTEC returns TEC of the map k of the global maps of testGlobal
+// at the grids of lat and lon (deg).
+func testGlobalTEC(k int, lat, lon float64) float64 {
+	return 10 + 5*float64(k) + 0.1*lat + 5*math.Sin(lon*deg)
+}
+
+// testGlobal returns the global maps at 0 and 2 h of 2024-07-14 of
+// testGlobalTEC.
+func testGlobal() *IONEX {
+	x := &IONEX{
+		BaseRadius: 6371e3, Height: 450e3,
+		Lat1: 87.5, Lat2: -87.5, DLat: -2.5, Lon1: -180, Lon2: 180, DLon: 5,
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for k := range 2 {
+		m := Map{Epoch: t0.Add(time.Duration(k) * 2 * time.Hour)}
+		for i := range x.nlat() {
+			row := make([]float64, x.nlon())
+			for j := range row {
+				row[j] = testGlobalTEC(k, x.Lat1+float64(i)*x.DLat, x.Lon1+float64(j)*x.DLon)
+			}
+			m.Values = append(m.Values, row)
+		}
+		x.Maps = append(x.Maps, m)
+	}
+	return x
+}
+
+// TestVTECRotate checks the rotated maps at 20 min, whose longitudes are
+// shifted by +5 and -25 deg of the grids, and the longitudes across 180
+// deg.
+func TestVTECRotate(t *testing.T) {
+	x := testGlobal()
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	at := t0.Add(20 * time.Minute)
+	for _, tt := range []struct {
+		lat, lon float64
+		rotate   bool
+		want     float64
+	}{
+		{35, 140, true, (5*testGlobalTEC(0, 35, 145) + testGlobalTEC(1, 35, 115)) / 6},
+		{35, 140, false, (5*testGlobalTEC(0, 35, 140) + testGlobalTEC(1, 35, 140)) / 6},
+		{-50, 175, true, (5*testGlobalTEC(0, -50, 180) + testGlobalTEC(1, -50, 150)) / 6},
+		{-50, -165, true, (5*testGlobalTEC(0, -50, -160) + testGlobalTEC(1, -50, 170)) / 6},
+		{-50, 535, true, (5*testGlobalTEC(0, -50, 180) + testGlobalTEC(1, -50, 150)) / 6},
+	} {
+		get, err := x.VTEC(at, tt.lat*deg, tt.lon*deg, IONEXOpts{Rotate: tt.rotate})
+		if err != nil || math.Abs(get-tt.want) > 1e-9 {
+			t.Errorf("(%.1f, %.1f) rotate=%v: get %v (err=%v), want %v", tt.lat, tt.lon, tt.rotate, get, err, tt.want)
+		}
+	}
+
+	// between the grids of 175 and 180 deg of the first map
+	want := 0.6*testGlobalTEC(0, 60, 175) + 0.4*testGlobalTEC(0, 60, 180)
+	if get, err := x.VTEC(t0, 60*deg, -183*deg, IONEXOpts{}); err != nil || math.Abs(get-want) > 1e-9 {
+		t.Errorf("across 180 deg: get %v (err=%v), want %v", get, err, want)
+	}
+}
+
+// TestVTECExample checks a worked example of the interpolation of the IONEX
+// specification (Schaer et al., 1998), i.e., eq. (3) of the bilinear
+// interpolation in a cell and eq. (1) of the maps rotated by
+// lambda' = lambda + (t - T_i), computed by hand. No published IONEX product
+// is in the tree, so the values are those of the equations rather than of
+// an IGS map. At 00:40, 142 deg east is rotated to 152 deg of the map of
+// 00:00 and 122 deg of the map of 02:00, i.e., p = 0.4 in the cells of the
+// longitudes, and 33.5 deg north is q = 0.4 from 32.5 deg:
+//
+//	E0 = 0.36*25 + 0.24*27 + 0.24*31 + 0.16*28 = 27.40
+//	E1 = 0.36*37 + 0.24*35 + 0.24*40 + 0.16*46 = 38.68
+//	E  = (2*E0 + E1)/3 = 31.16
+//
+// The other grids are 50 TECU, i.e., the cells of the wrong longitudes or
+// the maps not rotated are detected.
+func TestVTECExample(t *testing.T) {
+	x := &IONEX{
+		BaseRadius: 6371e3, Height: 450e3,
+		Lat1: 35, Lat2: 32.5, DLat: -2.5, Lon1: 120, Lon2: 160, DLon: 5,
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for k, grids := range []map[[2]int]float64{
+		{{0, 6}: 31, {0, 7}: 28, {1, 6}: 25, {1, 7}: 27},
+		{{0, 0}: 40, {0, 1}: 46, {1, 0}: 37, {1, 1}: 35},
+	} {
+		m := Map{Epoch: t0.Add(time.Duration(k) * 2 * time.Hour)}
+		for i := range x.nlat() {
+			row := make([]float64, x.nlon())
+			for j := range row {
+				row[j] = 50
+				if v, ok := grids[[2]int{i, j}]; ok {
+					row[j] = v
+				}
+			}
+			m.Values = append(m.Values, row)
+		}
+		x.Maps = append(x.Maps, m)
+	}
+
+	at := t0.Add(40 * time.Minute)
+	get, err := x.VTEC(at, 33.5*deg, 142*deg, IONEXOpts{Rotate: true})
+	if err != nil || math.Abs(get-31.16) > 1e-9 {
+		t.Errorf("get %v (err=%v), want 31.16", get, err)
+	}
+	if get, err := x.VTEC(at, 33.5*deg, 142*deg, IONEXOpts{}); err != nil || math.Abs(get-50) > 1e-9 {
+		t.Errorf("not rotated: get %v (err=%v), want 50", get, err)
+	}
+}
+
+// TestDelay checks the delays of 1 TECU, about 0.162 m on L1 at the zenith,
+// and the slant delays of the mapping function of the pierce points.
+func TestDelay(t *testing.T) {
+	x := testGlobal()
+	for i := range x.Maps {
+		for _, row := range x.Maps[i].Values {
+			for j := range row {
+				row[j] = 1
+			}
+		}
+	}
+	t0 := time.Date(2024, 7, 14, 1, 0, 0, 0, time.UTC)
+	lat, lon := 35*deg, 140*deg
+
+	d, err := x.Delay(t0, lat, lon, 0, math.Pi/2, freqL1, IONEXOpts{Rotate: true})
+	if err != nil || math.Abs(d-0.16237) > 1e-5 {
+		t.Errorf("zenith: get %.5f m (err=%v), want 0.16237 m", d, err)
+	}
+	d2, _ := x.Delay(t0, lat, lon, 0, math.Pi/2, 1227.60e6, IONEXOpts{})
+	if math.Abs(d2/d-Scale(1227.60e6)) > 1e-12 {
+		t.Errorf("L2/L1: get %v, want %v", d2/d, Scale(1227.60e6))
+	}
+
+	el := 10 * deg
+	_, _, zp := PiercePoint(lat, lon, 1, el, x.BaseRadius, x.Height)
+	ds, err := x.Delay(t0, lat, lon, 1, el, freqL1, IONEXOpts{})
+	if err != nil || math.Abs(ds-d/math.Cos(zp)) > 1e-12 || ds/d < 2.5 || ds/d > 3.5 {
+		t.Errorf("slant: get %v m (err=%v), want %v", ds, err, d/math.Cos(zp))
+	}
+	if d, err := x.Delay(t0, lat, lon, 1, -0.1, freqL1, IONEXOpts{}); d != 0 || err != nil {
+		t.Errorf("below the horizon: get %v (err=%v)", d, err)
+	}
+}
+
+// testIGSPattern is the IGS final GIM of 2024-07-14 (day 196), which is not
+// distributed with the module. TestVTECIGS is skipped unless the
+// decompressed file is put in testdata, e.g., from CDDIS.
+const testIGSPattern = "testdata/IGS0OPSFIN_20241960000_01D_*_GIM.INX"
+
+// TestVTECIGS checks VTEC of the IGS GIM at the grids around Japan at the
+// epochs of the maps against the values read from the text of the file
+// independently of ParseIONEX, and the range of VTEC at GEONET 0255 during
+// the day.
+func TestVTECIGS(t *testing.T) {
+	m, _ := filepath.Glob(testIGSPattern)
+	if len(m) == 0 {
+		t.Skipf("no IGS product in testdata: %s", testIGSPattern)
+	}
+	x, err := ReadIONEX(m[0])
+	if err != nil {
+		t.Fatalf("ReadIONEX: %v", err)
+	}
+	b, err := os.ReadFile(m[0])
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+
+	for k, mp := range x.Maps {
+		for _, g := range [][2]float64{{37.5, 135}, {35, 140}, {32.5, 130}} {
+			want := textTEC(t, lines, k, g[0], g[1])
+			get, err := x.VTEC(mp.Epoch, g[0]*deg, g[1]*deg, IONEXOpts{})
+			if err != nil || math.Abs(get-want) > 1e-9 {
+				t.Errorf("map %d at %.1f %.1f: get %v (err=%v), want %v", k+1, g[0], g[1], get, err, want)
+			}
+		}
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for h := range 24 {
+		ep := t0.Add(time.Duration(h) * time.Hour)
+		if v, err := x.VTEC(ep, 36.394457*deg, 136.388940*deg, IONEXOpts{Rotate: true}); err != nil || v <= 0 || v > 150 {
+			t.Errorf("0255 %v: %v TECU (err=%v)", ep, v, err)
+		}
+	}
+}
+
+// textTEC returns TEC (TECU) of the grid at lat and lon (deg) of the k-th
+// TEC map of the lines of an IONEX file.
+func textTEC(t *testing.T, lines []string, k int, lat, lon float64) float64 {
+	t.Helper()
+	// the exponent of the header, or of the map if given in the map
+	hexp, exp := -1, -1
+	var n int
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		label := ""
+		if len(l) > 60 {
+			label = strings.TrimSpace(l[60:])
+		}
+		switch label {
+		case "EXPONENT":
+			exp, _ = strconv.Atoi(strings.TrimSpace(l[:60]))
+			if n == 0 {
+				hexp = exp
+			}
+		case "START OF TEC MAP":
+			n++
+			exp = hexp
+		case "START OF RMS MAP", "START OF HEIGHT MAP":
+			n = -1
+		case "LAT/LON1/LON2/DLON/H":
+			if n != k+1 {
+				continue
+			}
+			lat0, _ := strconv.ParseFloat(strings.TrimSpace(l[2:8]), 64)
+			lon1, _ := strconv.ParseFloat(strings.TrimSpace(l[8:14]), 64)
+			dlon, _ := strconv.ParseFloat(strings.TrimSpace(l[20:26]), 64)
+			if lat0 != lat {
+				continue
+			}
+			// 16 values of 5 characters per line
+			j := int(math.Round((lon - lon1) / dlon))
+			v, err := strconv.Atoi(strings.TrimSpace(lines[i+1+j/16][5*(j%16) : 5*(j%16)+5]))
+			if err != nil {
+				t.Fatalf("map %d at %.1f %.1f: %v", k+1, lat, lon, err)
+			}
+			return float64(v) * math.Pow10(exp)
+		}
+	}
+	t.Fatalf("map %d at %.1f %.1f: not found", k+1, lat, lon)
+	return 0
+}
@@ -0,0 +1,333 @@
+package iono
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ErrFormat is returned when the file is not a valid IONEX file. The errors
+// of ParseIONEX are wrapped with the line number.
+var ErrFormat = errors.New("invalid ionex format")
+
+// missingTEC is the value of the missing TEC of IONEX.
+const missingTEC = 9999
+
+// valuesPerLine is the number of the values in a line of a map.
+const valuesPerLine = 16
+
+// IONEX stores the contents of an IONEX file of the 2D maps, e.g., the
+// global ionosphere maps of IGS.
+type IONEX struct {
+	Version float64
+	System  string // e.g., "GPS" or "MIX"
+
+	// First and Last are the epochs of the first and the last maps in UT,
+	// and Interval is the interval of the maps.
+	First, Last time.Time
+	Interval    time.Duration
+
+	// MappingFunction is the mapping function of the header, e.g., "COSZ",
+	// and ElevationCutoff is the minimum elevation (deg) of the data.
+	MappingFunction string
+	ElevationCutoff float64
+
+	// BaseRadius is the mean earth radius (m), and Height is the height of
+	// the single layer (m), i.e., HGT1.
+	BaseRadius, Height float64
+
+	// Lat1, Lat2, DLat and Lon1, Lon2, DLon are the grids of the maps (deg),
+	// e.g., 87.5, -87.5, -2.5 and -180, 180, 5 of the global maps.
+	Lat1, Lat2, DLat float64
+	Lon1, Lon2, DLon float64
+
+	// Maps and RMS are the TEC maps and the RMS maps in the order of the
+	// epochs, whose values are in TECU with the exponents applied.
+	Maps, RMS []Map
+}
+
+// Map is a map of an epoch.
+type Map struct {
+	// Epoch is the epoch of the map in UT.
+	Epoch time.Time
+
+	// Values is the values (TECU) indexed by the latitude and the longitude
+	// of the grids, which are NaN if missing (9999).
+	Values [][]float64
+}
+
+// ReadIONEX reads the IONEX file of the name.
+func ReadIONEX(name string) (*IONEX, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseIONEX(f)
+}
+
+// ParseIONEX reads an IONEX file of the version 1 from r. The auxiliary data,
+// e.g., the DCBs, and the height maps are skipped, and the 3D maps are not
+// supported. The format errors are tested by errors.Is with ErrFormat.
+func ParseIONEX(r io.Reader) (*IONEX, error) {
+	p := ionexParser{s: mscanner.NewScanner(r)}
+	x, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return x, nil
+}
+
+// ionexParser holds the state of ParseIONEX.
+type ionexParser struct {
+	s    *mscanner.Scanner
+	x    IONEX
+	line string // current line padded to 80 columns
+
+	exponent int
+}
+
+// next reads the next line, and returns false at the end of the input.
+func (p *ionexParser) next() bool {
+	if !p.s.Scan() {
+		return false
+	}
+	p.line = fmt.Sprintf("%-80s", strings.TrimRight(p.s.Text(), "\r"))
+	return true
+}
+
+// label returns the label of the line.
+func (p *ionexParser) label() string {
+	return strings.TrimSpace(p.line[60:])
+}
+
+func (p *ionexParser) parse() (*IONEX, error) {
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+	for p.next() {
+		var err error
+		switch p.label() {
+		case "START OF TEC MAP":
+			err = p.parseMap(&p.x.Maps, "END OF TEC MAP")
+		case "START OF RMS MAP":
+			err = p.parseMap(&p.x.RMS, "END OF RMS MAP")
+		case "START OF HEIGHT MAP":
+			err = p.skip("END OF HEIGHT MAP")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.label() == "END OF FILE" {
+			break
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.x.Maps) == 0 {
+		return nil, fmt.Errorf("%w: no TEC map", ErrFormat)
+	}
+	return &p.x, nil
+}
+
+// parseHeader parses the header lines until END OF HEADER.
+func (p *ionexParser) parseHeader() (err error) {
+	x := &p.x
+	if !p.next() || p.label() != "IONEX VERSION / TYPE" {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(p.line))
+	}
+	if x.Version, err = field.Atof(p.line[:8]); err != nil || x.Version < 1 || x.Version >= 2 {
+		return fmt.Errorf("%w: version: '%s'", ErrFormat, strings.TrimSpace(p.line[:8]))
+	}
+	if p.line[20] != 'I' {
+		return fmt.Errorf("%w: file type: '%c'", ErrFormat, p.line[20])
+	}
+	x.System = strings.TrimSpace(p.line[40:43])
+
+	p.exponent = -1
+	var hgt [3]float64
+	for p.next() {
+		switch p.label() {
+		case "EPOCH OF FIRST MAP":
+			x.First, err = epoch(p.line)
+		case "EPOCH OF LAST MAP":
+			x.Last, err = epoch(p.line)
+		case "INTERVAL":
+			var n int
+			n, err = field.Atoi(p.line[:6])
+			x.Interval = time.Duration(n) * time.Second
+		case "MAPPING FUNCTION":
+			x.MappingFunction = strings.TrimSpace(p.line[2:6])
+		case "ELEVATION CUTOFF":
+			x.ElevationCutoff, err = field.Atof(p.line[:8])
+		case "BASE RADIUS":
+			x.BaseRadius, err = field.Atof(p.line[:8])
+			x.BaseRadius *= 1e3
+		case "MAP DIMENSION":
+			var n int
+			if n, err = field.Atoi(p.line[:6]); err == nil && n != 2 {
+				return fmt.Errorf("%w: unsupported map dimension: %d", ErrFormat, n)
+			}
+		case "HGT1 / HGT2 / DHGT":
+			hgt, err = grid(p.line)
+			x.Height = hgt[0] * 1e3
+		case "LAT1 / LAT2 / DLAT":
+			var g [3]float64
+			g, err = grid(p.line)
+			x.Lat1, x.Lat2, x.DLat = g[0], g[1], g[2]
+		case "LON1 / LON2 / DLON":
+			var g [3]float64
+			g, err = grid(p.line)
+			x.Lon1, x.Lon2, x.DLon = g[0], g[1], g[2]
+		case "EXPONENT":
+			p.exponent, err = field.Atoi(p.line[:6])
+		case "START OF AUX DATA":
+			err = p.skip("END OF AUX DATA")
+		case "END OF HEADER":
+			return p.checkHeader(hgt)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrFormat, strings.ToLower(p.label()), err)
+		}
+	}
+	return fmt.Errorf("%w: no END OF HEADER", ErrFormat)
+}
+
+// checkHeader checks the grids of the header.
+func (p *ionexParser) checkHeader(hgt [3]float64) error {
+	x := &p.x
+	switch {
+	case hgt[2] != 0 || hgt[0] != hgt[1]:
+		return fmt.Errorf("%w: heights of the 3D maps: %v", ErrFormat, hgt)
+	case x.BaseRadius <= 0 || x.Height <= 0:
+		return fmt.Errorf("%w: base radius and height: %g, %g", ErrFormat, x.BaseRadius, x.Height)
+	case x.DLat == 0 || x.DLon == 0 || math.Signbit(x.Lat2-x.Lat1) != math.Signbit(x.DLat) || x.Lon2 <= x.Lon1 || x.DLon < 0:
+		return fmt.Errorf("%w: grids: lat=%g/%g/%g, lon=%g/%g/%g", ErrFormat, x.Lat1, x.Lat2, x.DLat, x.Lon1, x.Lon2, x.DLon)
+	}
+	return nil
+}
+
+// nlat and nlon return the numbers of the grids of the latitudes and the
+// longitudes.
+func (x *IONEX) nlat() int { return int(math.Round((x.Lat2-x.Lat1)/x.DLat)) + 1 }
+func (x *IONEX) nlon() int { return int(math.Round((x.Lon2-x.Lon1)/x.DLon)) + 1 }
+
+// parseMap parses a map until the label end into maps.
+func (p *ionexParser) parseMap(maps *[]Map, end string) (err error) {
+	x := &p.x
+	m := Map{Values: make([][]float64, x.nlat())}
+	exp := p.exponent
+	for p.next() {
+		switch p.label() {
+		case "EPOCH OF CURRENT MAP":
+			if m.Epoch, err = epoch(p.line); err != nil {
+				return fmt.Errorf("%w: epoch of map: %v", ErrFormat, err)
+			}
+		case "EXPONENT":
+			if exp, err = field.Atoi(p.line[:6]); err != nil {
+				return fmt.Errorf("%w: exponent: %v", ErrFormat, err)
+			}
+		case "LAT/LON1/LON2/DLON/H":
+			g, err := floats(p.line[2:32], 6)
+			if err != nil {
+				return fmt.Errorf("%w: latitude band: %v", ErrFormat, err)
+			}
+			i := int(math.Round((g[0] - x.Lat1) / x.DLat))
+			if i < 0 || i >= len(m.Values) || g[1] != x.Lon1 || g[2] != x.Lon2 || g[3] != x.DLon {
+				return fmt.Errorf("%w: latitude band: '%s'", ErrFormat, strings.TrimSpace(p.line[:60]))
+			}
+			if m.Values[i], err = p.parseBand(x.nlon(), exp); err != nil {
+				return err
+			}
+		case end:
+			if m.Epoch.IsZero() {
+				return fmt.Errorf("%w: map without epoch", ErrFormat)
+			}
+			for i, v := range m.Values {
+				if v == nil {
+					return fmt.Errorf("%w: map of %v: missing latitude band %g", ErrFormat, m.Epoch, x.Lat1+float64(i)*x.DLat)
+				}
+			}
+			*maps = append(*maps, m)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no %s", ErrFormat, end)
+}
+
+// parseBand parses the n values of a latitude band of 16I5 of the exponent.
+func (p *ionexParser) parseBand(n, exp int) ([]float64, error) {
+	scale := math.Pow10(exp)
+	vs := make([]float64, 0, n)
+	for len(vs) < n {
+		if !p.next() {
+			return nil, fmt.Errorf("%w: latitude band: unexpected end", ErrFormat)
+		}
+		for k := 0; k < valuesPerLine && len(vs) < n; k++ {
+			v, err := field.Atoi(p.line[5*k : 5*k+5])
+			if err != nil {
+				return nil, fmt.Errorf("%w: value: %v", ErrFormat, err)
+			}
+			if v == missingTEC {
+				vs = append(vs, math.NaN())
+				continue
+			}
+			vs = append(vs, float64(v)*scale)
+		}
+	}
+	return vs, nil
+}
+
+// skip skips the lines until the label end.
+func (p *ionexParser) skip(end string) error {
+	for p.next() {
+		if p.label() == end {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no %s", ErrFormat, end)
+}
+
+// epoch parses an epoch of 6I6.
+func epoch(l string) (time.Time, error) {
+	var v [6]int
+	for k := range v {
+		n, err := field.Atoi(l[6*k : 6*k+6])
+		if err != nil {
+			return time.Time{}, err
+		}
+		v[k] = n
+	}
+	return time.Date(v[0], time.Month(v[1]), v[2], v[3], v[4], v[5], 0, time.UTC), nil
+}
+
+// grid parses the grid of 2X,3F6.1.
+func grid(l string) ([3]float64, error) {
+	g, err := floats(l[2:20], 6)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	return [3]float64{g[0], g[1], g[2]}, nil
+}
+
+// floats parses the floats of the width w.
+func floats(s string, w int) ([]float64, error) {
+	var vs []float64
+	for k := 0; k+w <= len(s); k += w {
+		v, err := field.Atof(s[k : k+w])
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
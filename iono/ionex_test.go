@@ -0,0 +1,94 @@
+package iono
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the synthetic regional maps of 40 to 30 deg north and 130 to 150 deg east
+// at 0, 2 and 4 h of 2024-07-14, whose TEC (TECU) is
+// 20 + 5*k + 0.4*(lat-30) + 0.2*(lon-130) of the map k, where the value of
+// 40 deg north and 150 deg east of the second map is missing, and the third
+// map is of the exponent -2
+const testIONEXFile = "testdata/synt1960.24i"
+
+// testTEC returns TEC of the map k of the fixture at lat and lon (deg).
+func testTEC(k, lat, lon float64) float64 {
+	return 20 + 5*k + 0.4*(lat-30) + 0.2*(lon-130)
+}
+
+// TestParseIONEX checks the header and the maps of the fixture.
+func TestParseIONEX(t *testing.T) {
+	x, err := ReadIONEX(testIONEXFile)
+	if err != nil {
+		t.Fatalf("ReadIONEX: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	switch {
+	case x.Version != 1 || x.System != "GPS" || x.MappingFunction != "COSZ":
+		t.Errorf("version: %v %s %s", x.Version, x.System, x.MappingFunction)
+	case !x.First.Equal(t0) || !x.Last.Equal(t0.Add(4*time.Hour)) || x.Interval != 2*time.Hour:
+		t.Errorf("epochs: %v %v %v", x.First, x.Last, x.Interval)
+	case x.BaseRadius != 6371e3 || x.Height != 450e3:
+		t.Errorf("radius and height: %v %v", x.BaseRadius, x.Height)
+	case x.Lat1 != 40 || x.Lat2 != 30 || x.DLat != -2.5 || x.Lon1 != 130 || x.Lon2 != 150 || x.DLon != 5:
+		t.Errorf("grids: %+v", x)
+	}
+	if len(x.Maps) != 3 || len(x.RMS) != 1 {
+		t.Fatalf("number of maps: %d %d", len(x.Maps), len(x.RMS))
+	}
+
+	for k, m := range x.Maps {
+		if !m.Epoch.Equal(t0.Add(time.Duration(k) * 2 * time.Hour)) {
+			t.Errorf("map %d: epoch %v", k, m.Epoch)
+		}
+		for i, row := range m.Values {
+			for j, v := range row {
+				lat, lon := 40-2.5*float64(i), 130+5*float64(j)
+				want := testTEC(float64(k), lat, lon)
+				if k == 1 && i == 0 && j == 4 {
+					want = math.NaN()
+				}
+				if math.Abs(v-want) > 1e-9 || math.IsNaN(v) != math.IsNaN(want) {
+					t.Errorf("map %d (%.1f, %.1f): get %v, want %v", k, lat, lon, v, want)
+				}
+			}
+		}
+	}
+	if v := x.RMS[0].Values[4][4]; math.Abs(v-2) > 1e-9 {
+		t.Errorf("rms: get %v, want 2", v)
+	}
+}
+
+// TestParseIONEXErrors checks the files modified.
+func TestParseIONEXErrors(t *testing.T) {
+	data, err := os.ReadFile(testIONEXFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		old, new string
+	}{
+		{"file type", "     1.0            I", "     1.0            O"},
+		{"version", "     1.0            I", "     2.0            I"},
+		{"3D maps", "   450.0 450.0   0.0", "   450.0 800.0  50.0"},
+		{"grids", "    40.0  30.0  -2.5", "    40.0  30.0   2.5"},
+		{"latitude band", "    37.5 130.0 150.0", "    37.5 130.0 145.0"},
+		{"value", "  230  240  250  260  270", "  230  240  2x0  260  270"},
+		{"no header end", "END OF HEADER", "END OF HEADERS"},
+		{"no map end", "     3                                                      END OF TEC MAP", ""},
+	} {
+		s := strings.Replace(string(data), tt.old, tt.new, 1)
+		if s == string(data) {
+			t.Fatalf("%s: not modified", tt.name)
+		}
+		if _, err := ParseIONEX(strings.NewReader(s)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrFormat)
+		}
+	}
+}
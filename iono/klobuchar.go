@@ -0,0 +1,80 @@
+/*
+Package iono provides ionospheric delay models for GNSS pseudoranges.
+
+Angles are in radians, and the delays are in meters of the group delay on
+GPS L1, which is to be subtracted from the pseudorange. The delay on another
+frequency f is scaled by (f1/f)^2 (see Scale).
+
+The global ionosphere maps of IONEX are read by ParseIONEX, and evaluated
+for the delays of the frequencies by IONEX.Delay.
+*/
+package iono
+
+import (
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/nav"
+)
+
+const (
+	lightVelocity = 299792458. // speed of light (m/s)
+	freqL1        = 1575.42e6  // GPS L1 (Hz)
+)
+
+// Scale returns the ratio of the ionospheric group delay on the frequency
+// f (Hz) to that on GPS L1, i.e., (f1/f)^2.
+func Scale(f float64) float64 {
+	return (freqL1 / f) * (freqL1 / f)
+}
+
+// Klobuchar returns the ionospheric delay (m) on GPS L1 by the Klobuchar
+// model of the coefficients broadcast by GPS (IS-GPS-200 20.3.3.5.2.5) for
+// the satellite at the azimuth az and the elevation el seen from the
+// receiver at the geodetic latitude lat and the longitude lon, at the epoch t
+// in GPS time.
+//
+// The model is evaluated in the semi-circles as the ICD, with the delay of 5
+// ns at night. Zero is returned for a satellite below the horizon.
+func Klobuchar(k nav.Klobuchar, t time.Time, lat, lon, az, el float64) float64 {
+	if el <= 0 {
+		return 0.
+	}
+	e := el / math.Pi
+
+	// earth-centered angle, and the latitude and the longitude of the
+	// ionospheric pierce point (semi-circles)
+	psi := 0.0137/(e+0.11) - 0.022
+	phi := lat/math.Pi + psi*math.Cos(az)
+	phi = math.Max(-0.416, math.Min(0.416, phi))
+	lam := lon/math.Pi + psi*math.Sin(az)/math.Cos(phi*math.Pi)
+
+	// geomagnetic latitude of the pierce point
+	phim := phi + 0.064*math.Cos((lam-1.617)*math.Pi)
+
+	// local time (s)
+	sod := float64(t.Hour()*3600+t.Minute()*60+t.Second()) + float64(t.Nanosecond())*1e-9
+	lt := math.Mod(4.32e4*lam+sod, 86400.)
+	if lt < 0 {
+		lt += 86400.
+	}
+
+	// slant factor
+	f := 1. + 16.*math.Pow(0.53-e, 3)
+
+	var amp, per float64
+	for n := 3; n >= 0; n-- {
+		amp = amp*phim + k.Alpha[n]
+		per = per*phim + k.Beta[n]
+	}
+	amp = math.Max(amp, 0.)
+	per = math.Max(per, 72000.)
+
+	x := 2. * math.Pi * (lt - 50400.) / per
+	delay := 5e-9
+	if math.Abs(x) < 1.57 {
+		x2 := x * x
+		delay += amp * (1. - x2/2. + x2*x2/24.)
+	}
+	return f * delay * lightVelocity
+}
@@ -0,0 +1,63 @@
+package iono
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/nav"
+)
+
+// the coefficients of the header of the navigation file brdc1960.24n
+var testKlobuchar = nav.Klobuchar{
+	Alpha: [4]float64{1.1176e-08, 1.4901e-08, -5.9605e-08, -1.1921e-07},
+	Beta:  [4]float64{9.0112e+04, 1.1469e+05, -6.5536e+04, -5.2429e+05},
+}
+
+// TestKlobucharZenith checks the delays at the zenith at night and at the
+// peak of 14h local time, where the delays are F*5 ns and F*(5 ns + A1) for
+// the slant factor F = 1 + 16*(0.53-0.5)^3 and the amplitude A1 of the
+// constant alpha.
+func TestKlobucharZenith(t *testing.T) {
+	k := nav.Klobuchar{Alpha: [4]float64{1e-8}, Beta: [4]float64{86400.}}
+	f := 1. + 16.*math.Pow(0.03, 3)
+
+	for _, tt := range []struct {
+		hour int
+		want float64
+	}{
+		{2, f * 5e-9 * lightVelocity},
+		{14, f * 15e-9 * lightVelocity},
+	} {
+		epoch := time.Date(2024, 7, 14, tt.hour, 0, 0, 0, time.UTC)
+		if get := Klobuchar(k, epoch, 0, 0, 0, math.Pi/2); math.Abs(get-tt.want) > 1e-6 {
+			t.Errorf("%dh: get %.6f m, want %.6f m", tt.hour, get, tt.want)
+		}
+	}
+}
+
+// TestKlobuchar checks the delays of the broadcast coefficients increase
+// toward the horizon, and zero below the horizon.
+func TestKlobuchar(t *testing.T) {
+	epoch := time.Date(2024, 7, 14, 3, 0, 0, 0, time.UTC) // noon in Japan
+	lat, lon := 36.4*math.Pi/180., 136.4*math.Pi/180.
+
+	prev := 0.
+	for _, deg := range []float64{90, 60, 30, 15, 5} {
+		d := Klobuchar(testKlobuchar, epoch, lat, lon, math.Pi, deg*math.Pi/180.)
+		if d <= prev || d > 50 {
+			t.Errorf("el=%.0f: get %.3f m, previous %.3f m", deg, d, prev)
+		}
+		prev = d
+	}
+	if d := Klobuchar(testKlobuchar, epoch, lat, lon, 0, -0.1); d != 0 {
+		t.Errorf("below the horizon: get %f", d)
+	}
+}
+
+// TestScale checks the ratio of L2 to L1.
+func TestScale(t *testing.T) {
+	if s := Scale(1227.60e6); math.Abs(s-1.646944) > 1e-6 {
+		t.Errorf("get %f, want 1.646944", s)
+	}
+}
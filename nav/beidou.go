@@ -0,0 +1,44 @@
+package nav
+
+import (
+	"math"
+	"strconv"
+)
+
+// geoInclination is the inclination (rad) of the reference plane of the
+// BeiDou GEO satellites to the equator, i.e., -5 degrees.
+var geoInclination = -5. * math.Pi / 180.
+
+// IsGEO reports whether the satellite is a GEO satellite of BeiDou, i.e.,
+// C01-C05 and C59-C63, whose position is computed by the GEO algorithm of
+// the ICD.
+func (e *Ephemeris) IsGEO() bool {
+	if e.ID[0] != 'C' {
+		return false
+	}
+	n, _ := strconv.Atoi(e.ID[1:])
+	return n <= 5 || n >= 59
+}
+
+// geoRotate returns the position and the velocity of a BeiDou GEO satellite
+// in the Earth-fixed frame from those computed in the inertial frame of the
+// reference plane, i.e., rotated by -5 degrees about the x-axis and by the
+// rotation of the Earth omegaE*tk about the z-axis.
+func geoRotate(pos, vel [3]float64, omegaE, tk float64) (p, v [3]float64) {
+	sinf, cosf := math.Sincos(geoInclination)
+	sinz, cosz := math.Sincos(omegaE * tk)
+
+	rx := func(a [3]float64) [3]float64 {
+		return [3]float64{a[0], cosf*a[1] + sinf*a[2], -sinf*a[1] + cosf*a[2]}
+	}
+	rz := func(a [3]float64) [3]float64 {
+		return [3]float64{cosz*a[0] + sinz*a[1], -sinz*a[0] + cosz*a[1], a[2]}
+	}
+
+	q, dq := rx(pos), rx(vel)
+	p = rz(q)
+	v = rz(dq)
+	v[0] += omegaE * (-sinz*q[0] + cosz*q[1])
+	v[1] += omegaE * (-cosz*q[0] - sinz*q[1])
+	return p, v
+}
@@ -0,0 +1,137 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestReadBDS checks the BeiDou records and their epochs in GPS time. The
+// records of C01 and C11 are synthetic, not those broadcast, and check the
+// parsing and the conventions only.
+func TestReadBDS(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	c01 := f.Ephs["C01"]
+	if len(c01) != 1 || len(f.Ephs["C11"]) != 1 {
+		t.Fatalf("C01=%+v, C11=%+v", c01, f.Ephs["C11"])
+	}
+
+	e := c01[0]
+	if e.IODE != 1 || e.IODC != 1 || e.Week != 967 || e.URA != 2 || e.TGD != -5.8e-9 || e.TGD2 != -1.06e-8 || e.TransTime != 6 {
+		t.Errorf("C01: %+v", e)
+	}
+
+	// 00:00:00 of BDT
+	bdt := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	gpst := bdt.Add(14 * time.Second)
+	if !e.Toc.Equal(bdt) || !e.TOC().Equal(gpst) || !e.TOE().Equal(gpst) {
+		t.Errorf("epochs: toc=%v, TOC=%v, TOE=%v", e.Toc, e.TOC(), e.TOE())
+	}
+}
+
+// TestIsGEO checks the GEO satellites of BeiDou.
+func TestIsGEO(t *testing.T) {
+	for id, want := range map[string]bool{
+		"C01": true, "C05": true, "C59": true, "C62": true,
+		"C06": false, "C11": false, "C58": false, "G01": false, "E05": false,
+	} {
+		e := Ephemeris{ID: id}
+		if e.IsGEO() != want {
+			t.Errorf("%s: get %t, want %t", id, !want, want)
+		}
+	}
+}
+
+// TestBDSPosition checks the positions of the GEO and the MEO satellites at
+// the epochs around the week of BDT, and their orbit radii. The records are
+// synthetic; TestBDSPositionIGS checks the real ones against the MGEX orbits.
+func TestBDSPosition(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		id     string
+		radius float64 // nominal orbit radius (m)
+	}{
+		{"C01", 42164e3},
+		{"C11", 27906e3},
+	} {
+		for _, dt := range []time.Duration{
+			-100 * time.Minute, // previous week of BDT
+			0,
+			14 * time.Second, // toe
+			53 * time.Minute,
+			2 * time.Hour,
+		} {
+			ep := t0.Add(dt)
+			s, err := f.SatellitePosition(tt.id, ep)
+			if err != nil {
+				t.Fatalf("%s %v: %v", tt.id, dt, err)
+			}
+			if r := dist(s.Pos, [3]float64{}); math.Abs(r-tt.radius) > 50e3 {
+				t.Errorf("%s %v: radius=%.3f km, want %.0f km", tt.id, dt, r/1e3, tt.radius/1e3)
+			}
+		}
+	}
+
+	// the MEO algorithm for the GEO satellite, and the 14 seconds ignored
+	ep := t0.Add(time.Hour)
+	geo := f.Ephs["C01"][0]
+	meo := geo
+	meo.ID = "C31"
+	if d := dist(geo.State(ep).Pos, meo.State(ep).Pos); d < 100e3 {
+		t.Errorf("GEO by the MEO algorithm: d=%.3f m", d)
+	}
+	c11 := &f.Ephs["C11"][0]
+	if d := dist(c11.State(ep).Pos, c11.State(ep.Add(-14*time.Second)).Pos); d < 30 {
+		t.Errorf("14 s: d=%.3f m", d)
+	}
+}
+
+// TestGEOVelocity checks the velocity of the GEO satellite against the
+// difference of the positions.
+func TestGEOVelocity(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	e := &f.Ephs["C01"][0]
+
+	ep := time.Date(2024, 7, 14, 1, 10, 0, 0, time.UTC)
+	s := e.State(ep)
+	s0, s1 := e.State(ep.Add(-500*time.Millisecond)), e.State(ep.Add(500*time.Millisecond))
+	for c := range 3 {
+		if v := s1.Pos[c] - s0.Pos[c]; math.Abs(v-s.Vel[c]) > 1e-4 {
+			t.Errorf("velocity[%d]: get %.6f, want %.6f", c, s.Vel[c], v)
+		}
+	}
+	// nearly stationary
+	if v := math.Sqrt(sqr(s.Vel[0]) + sqr(s.Vel[1]) + sqr(s.Vel[2])); v > 50 {
+		t.Errorf("speed: %.3f m/s", v)
+	}
+}
+
+// TestBDSGroupDelay checks the group delays of BeiDou.
+func TestBDSGroupDelay(t *testing.T) {
+	e := Ephemeris{ID: "C11", TGD: 1.14e-8, TGD2: -2.9e-9}
+	for band, want := range map[byte]float64{'2': e.TGD, '7': e.TGD2, '6': 0} {
+		if d, err := e.GroupDelay(band); err != nil || d != want {
+			t.Errorf("%c: get %e (err=%v), want %e", band, d, err, want)
+		}
+	}
+	if _, err := e.GroupDelay('1'); !errors.Is(err, ErrSignal) {
+		t.Errorf("get err=%v, want %v", err, ErrSignal)
+	}
+}
+
+// dist returns the distance of the positions.
+func dist(a, b [3]float64) float64 {
+	return math.Sqrt(sqr(a[0]-b[0]) + sqr(a[1]-b[1]) + sqr(a[2]-b[2]))
+}
@@ -0,0 +1,54 @@
+package nav
+
+import "time"
+
+// The bits of Ephemeris.DataSources of the Galileo records.
+const (
+	SourceINAVE1B  = 1 << 0 // I/NAV E1-B
+	SourceFNAV     = 1 << 1 // F/NAV E5a-I
+	SourceINAVE5b  = 1 << 2 // I/NAV E5b-I
+	SourceClockE5a = 1 << 8 // clock and BGD for E5a,E1
+	SourceClockE5b = 1 << 9 // clock and BGD for E5b,E1
+)
+
+// GalMessage is the type of the Galileo navigation message.
+type GalMessage int
+
+const (
+	// AnyMessage accepts both of the messages.
+	AnyMessage GalMessage = iota
+
+	// INAV is the I/NAV message of E1-B and E5b-I, whose clock is of the
+	// E1/E5b ionosphere-free combination.
+	INAV
+
+	// FNAV is the F/NAV message of E5a-I, whose clock is of the E1/E5a
+	// ionosphere-free combination.
+	FNAV
+)
+
+// IsINAV reports whether the ephemeris is of the Galileo I/NAV message.
+func (e *Ephemeris) IsINAV() bool {
+	return e.DataSources&(SourceINAVE1B|SourceINAVE5b) != 0
+}
+
+// IsFNAV reports whether the ephemeris is of the Galileo F/NAV message.
+func (e *Ephemeris) IsFNAV() bool {
+	return e.DataSources&SourceFNAV != 0
+}
+
+// SelectGal returns the Galileo ephemeris of the satellite id valid at the
+// epoch t of the message msg, which should be FNAV for the signals of E5a and
+// INAV for those of E1 and E5b. The selection is that of Select.
+func (f *File) SelectGal(id string, t time.Time, msg GalMessage) (*Ephemeris, error) {
+	return f.selectBy(id, t, func(e *Ephemeris) bool {
+		switch msg {
+		case INAV:
+			return e.IsINAV()
+		case FNAV:
+			return e.IsFNAV()
+		default:
+			return true
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReadGal checks the I/NAV and the F/NAV records of E11 in the fixture,
+// whose clocks are consistent for E1. The records are synthetic, not those
+// broadcast, and check the parsing and the conventions only.
+func TestReadGal(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	e11 := f.Ephs["E11"]
+	if len(e11) != 2 {
+		t.Fatalf("E11: %+v", e11)
+	}
+
+	inav, fnav := e11[0], e11[1]
+	if !inav.IsINAV() || inav.IsFNAV() || inav.DataSources != 517 || !fnav.IsFNAV() || fnav.IsINAV() || fnav.DataSources != 258 {
+		t.Errorf("data sources: %d, %d", inav.DataSources, fnav.DataSources)
+	}
+	if inav.IODE != 69 || inav.Week != 2323 || inav.URA != 3.12 || inav.TransTime != 605 || inav.FitInterval != 0 || inav.TGD != 0 {
+		t.Errorf("I/NAV: %+v", inav)
+	}
+	if inav.BGDE5a != -2.328306436539e-10 || inav.BGDE5b != -2.561137080193e-09 || fnav.BGDE5a != inav.BGDE5a || fnav.BGDE5b != 0 {
+		t.Errorf("BGD: I/NAV=%e %e, F/NAV=%e %e", inav.BGDE5a, inav.BGDE5b, fnav.BGDE5a, fnav.BGDE5b)
+	}
+	if inav.SqrtA != 5440.603729248 || inav.Omega != fnav.Omega || inav.M0 != fnav.M0 {
+		t.Errorf("orbit: I/NAV=%+v, F/NAV=%+v", inav, fnav)
+	}
+}
+
+// TestGroupDelay checks the group delays of the signals.
+func TestGroupDelay(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	g02 := &f.Ephs["G02"][0]
+	inav, fnav := &f.Ephs["E11"][0], &f.Ephs["E11"][1]
+
+	gamma := (77. / 60.) * (77. / 60.)
+	for _, tt := range []struct {
+		eph  *Ephemeris
+		band byte
+		want float64
+	}{
+		{g02, '1', g02.TGD},
+		{g02, '2', gamma * g02.TGD},
+		{inav, '1', inav.BGDE5b},
+		{inav, '7', (1575.42 / 1207.14) * (1575.42 / 1207.14) * inav.BGDE5b},
+		{fnav, '1', fnav.BGDE5a},
+		{fnav, '5', (154. / 115.) * (154. / 115.) * fnav.BGDE5a},
+	} {
+		if d, err := tt.eph.GroupDelay(tt.band); err != nil || math.Abs(d-tt.want) > 1e-22 {
+			t.Errorf("%s %c: get %e (err=%v), want %e", tt.eph.ID, tt.band, d, err, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		eph  *Ephemeris
+		band byte
+	}{
+		{g02, '5'}, {inav, '5'}, {fnav, '7'},
+	} {
+		if _, err := tt.eph.GroupDelay(tt.band); !errors.Is(err, ErrSignal) {
+			t.Errorf("%s %c: get err=%v, want %v", tt.eph.ID, tt.band, err, ErrSignal)
+		}
+	}
+
+	// the clocks of E1 agree between the messages
+	ep := time.Date(2024, 7, 14, 0, 30, 0, 0, time.UTC)
+	di, _ := inav.GroupDelay('1')
+	df, _ := fnav.GroupDelay('1')
+	if d := (inav.State(ep).Clock - di) - (fnav.State(ep).Clock - df); math.Abs(d) > 1e-15 {
+		t.Errorf("E1 clocks: d=%e", d)
+	}
+}
+
+// TestSelectGal checks the selection of the messages, and that the F/NAV and
+// the I/NAV ephemerides of the synthetic records give the same orbit.
+// TestSelectGalIGS checks the real ones against the MGEX orbits.
+func TestSelectGal(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		msg  GalMessage
+		want int
+	}{
+		{INAV, 517}, {FNAV, 258}, {AnyMessage, 258},
+	} {
+		e, err := f.SelectGal("E11", t0, tt.msg)
+		if err != nil || e.DataSources != tt.want {
+			t.Errorf("message %d: get %+v (err=%v), want %d", tt.msg, e, err, tt.want)
+		}
+	}
+
+	for _, dt := range []time.Duration{-100 * time.Minute, 0, 37 * time.Minute, 2 * time.Hour} {
+		ep := t0.Add(dt)
+		fnav, err := f.SelectGal("E11", ep, FNAV)
+		if err != nil {
+			t.Fatalf("%v: %v", dt, err)
+		}
+		inav, err := f.SelectGal("E11", ep, INAV)
+		if err != nil {
+			t.Fatalf("%v: %v", dt, err)
+		}
+		if d := dist(SatellitePosition(fnav, ep).Pos, SatellitePosition(inav, ep).Pos); d > 1e-3 {
+			t.Errorf("%v: F/NAV and I/NAV: d=%.4f m", dt, d)
+		}
+	}
+
+	// no F/NAV
+	var lines []string
+	for _, l := range readTestLines(t, testFile3) {
+		lines = append(lines, strings.Replace(l, "2.580000000000E+02", "5.170000000000E+02", 1))
+	}
+	f2, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := f2.SelectGal("E11", t0, FNAV); !errors.Is(err, ErrNoEphemeris) {
+		t.Errorf("no F/NAV: get err=%v, want %v", err, ErrNoEphemeris)
+	}
+}
@@ -0,0 +1,131 @@
+package nav
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// GloFitInterval is the fit interval (h) of the GLONASS ephemerides, i.e.,
+// an ephemeris is valid within 30 minutes from its epoch.
+const GloFitInterval = 1.
+
+// the constants of the GLONASS ICD (PZ-90)
+const (
+	gmGLO     = 398600.4418e9 // gravitational constant (m^3/s^2)
+	aeGLO     = 6378136.      // semi-major axis of the Earth (m)
+	j2GLO     = 1082625.75e-9 // second zonal harmonic
+	omegaEGLO = 7.292115e-5   // rotation rate of the Earth (rad/s)
+)
+
+// gloStep is the step (s) of the Runge-Kutta integration.
+const gloStep = 60.
+
+// State returns the state of the satellite at the epoch t by the 4th-order
+// Runge-Kutta integration of the equations of motion of the ICD, i.e., the
+// central force with the J2 term in the rotating frame, and the lunisolar
+// acceleration of the ephemeris held constant.
+//
+// The relativistic correction is included in the broadcast clock, and
+// SatState.Relativity is zero.
+func (e *GloEphemeris) State(t time.Time) SatState {
+	dt := t.Sub(e.TOE()).Seconds()
+
+	x := [6]float64{e.Pos[0], e.Pos[1], e.Pos[2], e.Vel[0], e.Vel[1], e.Vel[2]}
+	for rem := dt; rem != 0; {
+		h := math.Copysign(math.Min(gloStep, math.Abs(rem)), rem)
+		x = rk4(x, e.Acc, h)
+		rem -= h
+	}
+
+	return SatState{
+		Pos:   [3]float64{x[0], x[1], x[2]},
+		Vel:   [3]float64{x[3], x[4], x[5]},
+		Clock: -e.TauN + e.GammaN*dt,
+		Drift: e.GammaN,
+	}
+}
+
+// rk4 advances the state x of the position and the velocity by the step h (s)
+// with the acceleration acc.
+func rk4(x [6]float64, acc [3]float64, h float64) [6]float64 {
+	add := func(x, k [6]float64, a float64) (y [6]float64) {
+		for i := range y {
+			y[i] = x[i] + a*k[i]
+		}
+		return y
+	}
+
+	k1 := gloDeriv(x, acc)
+	k2 := gloDeriv(add(x, k1, h/2), acc)
+	k3 := gloDeriv(add(x, k2, h/2), acc)
+	k4 := gloDeriv(add(x, k3, h), acc)
+	for i := range x {
+		x[i] += h / 6 * (k1[i] + 2*k2[i] + 2*k3[i] + k4[i])
+	}
+	return x
+}
+
+// gloDeriv returns the derivatives of the state x.
+func gloDeriv(x [6]float64, acc [3]float64) [6]float64 {
+	r2 := x[0]*x[0] + x[1]*x[1] + x[2]*x[2]
+	r := math.Sqrt(r2)
+	a := gmGLO / (r2 * r)
+	c := 1.5 * j2GLO * gmGLO * aeGLO * aeGLO / (r2 * r2 * r)
+	z2 := 5 * x[2] * x[2] / r2
+	w2 := omegaEGLO * omegaEGLO
+
+	return [6]float64{
+		x[3], x[4], x[5],
+		-a*x[0] - c*x[0]*(1-z2) + w2*x[0] + 2*omegaEGLO*x[4] + acc[0],
+		-a*x[1] - c*x[1]*(1-z2) + w2*x[1] - 2*omegaEGLO*x[3] + acc[1],
+		-a*x[2] - c*x[2]*(3-z2) + acc[2],
+	}
+}
+
+// SelectGlo returns the GLONASS ephemeris of the satellite id valid at the
+// epoch t in GPS time, i.e., that of the nearest epoch within the half of
+// GloFitInterval. The tie is resolved by the last record in the file.
+func (f *File) SelectGlo(id string, t time.Time) (*GloEphemeris, error) {
+	ephs, ok := f.Glo[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w: %s", ErrNoEphemeris, ErrNoData, id)
+	}
+
+	var best *GloEphemeris
+	var bestDt float64
+	for k := range ephs {
+		e := &ephs[k]
+		dt := math.Abs(t.Sub(e.TOE()).Seconds())
+		if dt > GloFitInterval*3600/2 {
+			continue
+		}
+		if best == nil || dt <= bestDt {
+			best, bestDt = e, dt
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %w: %s at %v", ErrNoEphemeris, ErrOutOfFit, id, t)
+	}
+	return best, nil
+}
+
+// leapSecondsAt returns the difference of GPS time from UTC (s) at the epoch
+// t in UTC.
+func leapSecondsAt(t time.Time) int {
+	return gnsstime.LeapSeconds(t)
+}
+
+// gloURAs is the user range accuracy (m) of the accuracy index F_T of the ICD.
+var gloURAs = [...]float64{1, 2, 2.5, 4, 5, 7, 10, 12, 14, 16, 32, 64, 128, 256, 512}
+
+// gloURA returns the user range accuracy (m) of the index F_T, or zero for the
+// index not defined.
+func gloURA(ft int) float64 {
+	if ft < 0 || ft >= len(gloURAs) {
+		return 0
+	}
+	return gloURAs[ft]
+}
@@ -0,0 +1,196 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFileGlo is the GLONASS ephemerides of R05 at 00:15 and 00:45 UTC, where
+// the latter is the former integrated by 30 minutes with the step of 0.5 s.
+const testFileGlo = "testdata/brdc1960_excerpt.24g"
+
+// TestReadGlo checks the GLONASS ephemerides of the versions 2, 3.04 and
+// 3.05.
+func TestReadGlo(t *testing.T) {
+	f2, err := ReadFile(testFileGlo)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if f2.Header.SatSystem != 'R' || f2.Header.LeapSeconds != 18 || len(f2.Ephs) != 0 || len(f2.Glo["R05"]) != 2 {
+		t.Fatalf("header=%+v, glo=%+v", f2.Header, f2.Glo)
+	}
+
+	e := f2.Glo["R05"][0]
+	want := GloEphemeris{
+		ID:          "R05",
+		Toc:         time.Date(2024, 7, 14, 0, 15, 0, 0, time.UTC),
+		TauN:        -0.253319740295e-04,
+		GammaN:      0.909494701773e-12,
+		FrameTime:   870,
+		Pos:         [3]float64{6405285.15625, -19084047.8516, 16178433.1055},
+		Vel:         [3]float64{-302.479743957, -2262.94517517, -2688.45748901},
+		Acc:         [3]float64{0.279396772385e-05, 0, -0.186264514923e-05},
+		FreqNum:     1,
+		LeapSeconds: 18,
+	}
+	for c := range 3 {
+		if math.Abs(e.Pos[c]-want.Pos[c]) > 1e-3 || math.Abs(e.Vel[c]-want.Vel[c]) > 1e-8 || math.Abs(e.Acc[c]-want.Acc[c]) > 1e-16 {
+			t.Errorf("state: get %+v, want %+v", e, want)
+		}
+	}
+	e.Pos, e.Vel, e.Acc = want.Pos, want.Vel, want.Acc
+	if e != want {
+		t.Errorf("get %+v, want %+v", e, want)
+	}
+	if toe := e.TOE(); !toe.Equal(want.Toc.Add(18 * time.Second)) {
+		t.Errorf("TOE: %v", toe)
+	}
+
+	// the version 3.05 has the fourth line
+	lines := readTestLines(t, testFile3)
+	var lines305 []string
+	for k, l := range lines {
+		switch {
+		case k == 0:
+			l = "     3.05" + l[9:]
+		case k >= 3 && strings.HasPrefix(lines[k-3], "R05"):
+			lines305 = append(lines305, l, "     0.000000000000E+00 2.793967723846E-09 2.000000000000E+00 0.000000000000E+00")
+			continue
+		}
+		lines305 = append(lines305, l)
+	}
+
+	for _, in := range []string{strings.Join(lines, "\n"), strings.Join(lines305, "\n")} {
+		f3, err := Parse(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if len(f3.Ephs) != 12 || len(f3.Glo) != 1 {
+			t.Fatalf("version %.2f: ephs=%d, glo=%d", f3.Header.Version, len(f3.Ephs), len(f3.Glo))
+		}
+		for k, e3 := range f3.Glo["R05"] {
+			e2 := f2.Glo["R05"][k]
+			for c := range 3 {
+				if math.Abs(e2.Pos[c]-e3.Pos[c]) > 1e-4 || math.Abs(e2.Vel[c]-e3.Vel[c]) > 1e-8 {
+					t.Errorf("version %.2f: R05[%d]: v2=%+v, v3=%+v", f3.Header.Version, k, e2, e3)
+				}
+			}
+			if e2.Toc != e3.Toc || e2.FreqNum != e3.FreqNum || e2.LeapSeconds != e3.LeapSeconds || math.Abs(e2.TauN-e3.TauN) > 1e-16 {
+				t.Errorf("version %.2f: R05[%d]: v2=%+v, v3=%+v", f3.Header.Version, k, e2, e3)
+			}
+		}
+	}
+}
+
+// TestGloState checks the integration against the reference of the step of
+// 0.5 s, and the continuity of the two ephemerides.
+func TestGloState(t *testing.T) {
+	f, err := ReadFile(testFileGlo)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	r05 := f.Glo["R05"]
+	e := &r05[0]
+	toe := e.TOE()
+
+	if s := SatellitePosition(e, toe); s.Pos != e.Pos || s.Vel != e.Vel || s.Clock != -e.TauN || s.Relativity != 0 {
+		t.Errorf("at toe: %+v", s)
+	}
+
+	for _, tt := range []struct {
+		dt       float64
+		pos, vel [3]float64
+	}{
+		{600, [3]float64{6146117.1332, -20368749.9953, 14500085.5303}, [3]float64{-557.4366, -2012.9515, -2902.4608}},
+		{-900, [3]float64{6490939.0586, -16905284.3994, 18439392.9273}, [3]float64{118.8963, -2563.5747, -2328.1938}},
+		{1500, [3]float64{5490007.1754, -21986186.7029, 11758240.5065}, [3]float64{-889.5635, -1567.7222, -3181.9881}},
+	} {
+		s := e.State(toe.Add(time.Duration(tt.dt * 1e9)))
+		for c := range 3 {
+			if math.Abs(s.Pos[c]-tt.pos[c]) > 0.01 || math.Abs(s.Vel[c]-tt.vel[c]) > 1e-4 {
+				t.Errorf("dt=%.0f: get %v %v, want %v %v", tt.dt, s.Pos, s.Vel, tt.pos, tt.vel)
+				break
+			}
+		}
+		if want := -e.TauN + e.GammaN*tt.dt; s.Clock != want || s.Drift != e.GammaN {
+			t.Errorf("dt=%.0f: clock: get %e, want %e", tt.dt, s.Clock, want)
+		}
+	}
+
+	// both ephemerides at 00:30
+	ep := toe.Add(15 * time.Minute)
+	s0, s1 := r05[0].State(ep), r05[1].State(ep)
+	for c := range 3 {
+		if math.Abs(s0.Pos[c]-s1.Pos[c]) > 0.01 || math.Abs(s0.Clock-s1.Clock) > 1e-15 {
+			t.Errorf("continuity: %+v, %+v", s0, s1)
+			break
+		}
+	}
+}
+
+// TestSelectGlo checks the selection of the GLONASS ephemerides in GPS time.
+func TestSelectGlo(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	r05 := f.Glo["R05"]
+	toe := r05[0].TOE()
+
+	for _, tt := range []struct {
+		dt   time.Duration
+		want int // index of the ephemeris, -1 for the error
+	}{
+		{-30 * time.Minute, 0},
+		{14 * time.Minute, 0},
+		{16 * time.Minute, 1},
+		{60 * time.Minute, 1},
+		{-31 * time.Minute, -1},
+		{61 * time.Minute, -1},
+	} {
+		e, err := f.SelectGlo("R05", toe.Add(tt.dt))
+		if tt.want < 0 {
+			if !errors.Is(err, ErrNoEphemeris) {
+				t.Errorf("%v: get err=%v, want %v", tt.dt, err, ErrNoEphemeris)
+			}
+			continue
+		}
+		if err != nil || e != &r05[tt.want] {
+			t.Errorf("%v: get %+v (err=%v), want %d", tt.dt, e, err, tt.want)
+		}
+	}
+
+	if _, err := f.SelectGlo("R01", toe); !errors.Is(err, ErrNoEphemeris) {
+		t.Errorf("R01: get err=%v, want %v", err, ErrNoEphemeris)
+	}
+	if s, err := f.SatellitePosition("R05", toe); err != nil || s.Pos != r05[0].Pos {
+		t.Errorf("SatellitePosition: get %+v (err=%v)", s, err)
+	}
+}
+
+// TestLeapSeconds checks the table of the leap seconds.
+func TestLeapSeconds(t *testing.T) {
+	for _, tt := range []struct {
+		t    time.Time
+		want int
+	}{
+		{time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC), 17},
+		{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 18},
+		{time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), 18},
+	} {
+		if n := leapSecondsAt(tt.t); n != tt.want {
+			t.Errorf("%v: get %d, want %d", tt.t, n, tt.want)
+		}
+	}
+
+	// not given by the header
+	in := strings.Replace(strings.Join(readTestLines(t, testFileGlo), "\n"), "    18                                                      LEAP SECONDS", "", 1)
+	f, err := Parse(strings.NewReader(in))
+	if err != nil || f.Header.LeapSeconds != 0 || f.Glo["R05"][0].LeapSeconds != 18 {
+		t.Errorf("get %+v (err=%v)", f, err)
+	}
+}
@@ -0,0 +1,117 @@
+package nav
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Healthy reports whether the satellite is healthy, i.e., all the bits of
+// Health are zero, which are those of the SV health of GPS, the signal health
+// and the data validity of Galileo, and SatH1 of BeiDou.
+func (e *Ephemeris) Healthy() bool {
+	return e.Health == 0
+}
+
+// Accuracy returns URA, which is SISA of Galileo. It is negative for the
+// Galileo satellites of no accuracy prediction available (NAPA).
+func (e *Ephemeris) Accuracy() float64 {
+	return e.URA
+}
+
+// Healthy reports whether the satellite is healthy, i.e., Health is zero.
+func (e *GloEphemeris) Healthy() bool {
+	return e.Health == 0
+}
+
+// Accuracy returns URA, which is zero before the version 3.05.
+func (e *GloEphemeris) Accuracy() float64 {
+	return e.URA
+}
+
+// SelectOpts is the options of SelectUsable.
+type SelectOpts struct {
+	// MaxURA is the maximum user range accuracy (m) of the usable
+	// ephemerides, or zero for no limit. The ephemerides of the negative
+	// accuracy, i.e., NAPA of Galileo, are not usable if limited, and those of
+	// no accuracy given are usable.
+	MaxURA float64
+
+	// FitInterval is the fit interval (h) overriding those of the
+	// ephemerides if positive, e.g., 2 to reject the ephemerides older than
+	// an hour.
+	FitInterval float64
+}
+
+// Usable reports whether the ephemeris is healthy and of the accuracy within
+// MaxURA.
+func (o SelectOpts) Usable(eph Broadcast) bool {
+	if !eph.Healthy() {
+		return false
+	}
+	acc := eph.Accuracy()
+	return o.MaxURA <= 0 || (acc >= 0 && acc <= o.MaxURA)
+}
+
+// SelectUsable returns the usable ephemeris of the satellite id at the epoch
+// t in GPS time, i.e., the one of the nearest Toe within the half of the fit
+// interval among those accepted by opts.Usable. The tie is resolved as Select.
+//
+// The fit intervals are those of Select and SelectGlo unless overridden by
+// opts.FitInterval. The error wraps ErrNoEphemeris and the reason, i.e.,
+// ErrNoData, ErrOutOfFit or ErrUnhealthy.
+func (f *File) SelectUsable(id string, t time.Time, opts SelectOpts) (Broadcast, error) {
+	// the candidates of the fit interval (h) and the transmission time
+	type candidate struct {
+		eph   Broadcast
+		fit   float64
+		trans float64
+	}
+	var cands []candidate
+	if strings.HasPrefix(id, "R") {
+		for k := range f.Glo[id] {
+			cands = append(cands, candidate{&f.Glo[id][k], GloFitInterval, 0})
+		}
+	} else {
+		for k := range f.Ephs[id] {
+			e := &f.Ephs[id][k]
+			fit := e.FitInterval
+			if fit == 0 {
+				fit = DefaultFitInterval
+			}
+			cands = append(cands, candidate{e, fit, e.TransTime})
+		}
+	}
+	if len(cands) == 0 {
+		return nil, fmt.Errorf("%w: %w: %s", ErrNoEphemeris, ErrNoData, id)
+	}
+
+	var best *candidate
+	var bestDt float64
+	var inFit int
+	for k := range cands {
+		c := &cands[k]
+		if opts.FitInterval > 0 {
+			c.fit = opts.FitInterval
+		}
+		dt := math.Abs(t.Sub(c.eph.TOE()).Seconds())
+		if dt > c.fit*3600/2 {
+			continue
+		}
+		inFit++
+		if !opts.Usable(c.eph) {
+			continue
+		}
+		if best == nil || dt < bestDt || (dt == bestDt && c.trans >= best.trans) {
+			best, bestDt = c, dt
+		}
+	}
+	switch {
+	case inFit == 0:
+		return nil, fmt.Errorf("%w: %w: %s at %v", ErrNoEphemeris, ErrOutOfFit, id, t)
+	case best == nil:
+		return nil, fmt.Errorf("%w: %w: %s at %v: %d ephemerides unhealthy or of too large URA", ErrNoEphemeris, ErrUnhealthy, id, t, inFit)
+	}
+	return best.eph, nil
+}
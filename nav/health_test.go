@@ -0,0 +1,70 @@
+package nav
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSelectUsable checks the selection by the health and the accuracy, and
+// the reasons of the errors.
+func TestSelectUsable(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	// the second ephemeris of G14 and R05 unhealthy, and E11 of NAPA
+	g14, r05 := f.Ephs["G14"], f.Glo["R05"]
+	g14[1].Health = 1
+	r05[1].Health = 1
+	for k := range f.Ephs["E11"] {
+		f.Ephs["E11"][k].URA = -1
+	}
+	if !g14[0].Healthy() || g14[1].Healthy() || !r05[0].Healthy() || r05[1].Healthy() {
+		t.Errorf("Healthy: %+v, %+v", g14, r05)
+	}
+
+	for _, tt := range []struct {
+		name string
+		id   string
+		dt   time.Duration
+		opts SelectOpts
+		want Broadcast
+		err  error
+	}{
+		{"healthy", "G14", 0, SelectOpts{}, &g14[0], nil},
+		{"fallback", "G14", 61 * time.Minute, SelectOpts{}, &g14[0], nil},
+		{"unhealthy", "G14", 3 * time.Hour, SelectOpts{}, nil, ErrUnhealthy},
+		{"URA", "G02", 0, SelectOpts{MaxURA: 2}, &f.Ephs["G02"][0], nil},
+		{"too large URA", "G02", 0, SelectOpts{MaxURA: 1.5}, nil, ErrUnhealthy},
+		{"NAPA", "E11", 0, SelectOpts{MaxURA: 10}, nil, ErrUnhealthy},
+		{"NAPA not limited", "E11", 0, SelectOpts{}, &f.Ephs["E11"][1], nil},
+		{"fit interval", "G02", 31 * time.Minute, SelectOpts{FitInterval: 1}, nil, ErrOutOfFit},
+		{"out of fit", "G02", 3 * time.Hour, SelectOpts{}, nil, ErrOutOfFit},
+		{"no data", "G01", 0, SelectOpts{}, nil, ErrNoData},
+		{"GLONASS", "R05", 15 * time.Minute, SelectOpts{}, &r05[0], nil},
+		{"GLONASS unhealthy", "R05", 58 * time.Minute, SelectOpts{}, nil, ErrUnhealthy},
+		{"GLONASS no data", "R01", 0, SelectOpts{}, nil, ErrNoData},
+	} {
+		eph, err := f.SelectUsable(tt.id, t0.Add(tt.dt), tt.opts)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) || !errors.Is(err, ErrNoEphemeris) {
+				t.Errorf("%s: get err=%v, want %v", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || eph != tt.want {
+			t.Errorf("%s: get %+v (err=%v), want %+v", tt.name, eph, err, tt.want)
+		}
+	}
+
+	// the reasons of Select and SelectGlo
+	if _, err := f.Select("G01", t0); !errors.Is(err, ErrNoData) {
+		t.Errorf("Select: get err=%v, want %v", err, ErrNoData)
+	}
+	if _, err := f.SelectGlo("R05", t0.Add(2*time.Hour)); !errors.Is(err, ErrOutOfFit) {
+		t.Errorf("SelectGlo: get err=%v, want %v", err, ErrOutOfFit)
+	}
+}
@@ -0,0 +1,148 @@
+package nav
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/sp3"
+)
+
+// The products of IGS of 2024-07-14 (day 196) for the acceptance tests,
+// which are not distributed with the module. The tests are skipped unless
+// the decompressed files are put in testdata, e.g., from CDDIS or BKG:
+//
+//	BRDC00IGS_R_20241960000_01D_MN.rnx       broadcast ephemerides
+//	IGS0OPSRAP_20241960000_01D_15M_ORB.SP3   rapid orbits of GPS
+//	WUM0MGXFIN_20241960000_01D_05M_ORB.SP3   final MGEX orbits, with the
+//	                                         BeiDou GEO satellites
+//
+// The precise orbits are of the center of mass, and the broadcast ones of the
+// antenna phase center, whose offsets are up to ~2.5 m.
+const (
+	igsNavPattern   = "testdata/BRDC00IGS_R_20241960000_01D_MN.rnx"
+	igsRapidPattern = "testdata/IGS0OPSRAP_20241960000_01D_*_ORB.SP3"
+	igsMGEXPattern  = "testdata/???0MGXFIN_20241960000_01D_*_ORB.SP3"
+)
+
+// readIGS returns the broadcast ephemerides and the precise orbit of the
+// pattern, or skips the test if either is not in testdata.
+func readIGS(t *testing.T, orbitPattern string) (*File, *sp3.File) {
+	t.Helper()
+	var names [2]string
+	for i, pattern := range []string{igsNavPattern, orbitPattern} {
+		m, _ := filepath.Glob(pattern)
+		if len(m) == 0 {
+			t.Skipf("no IGS product in testdata: %s", pattern)
+		}
+		names[i] = m[0]
+	}
+
+	brdc, err := ReadFile(names[0])
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	orb, err := sp3.ReadFile(names[1])
+	if err != nil {
+		t.Fatalf("sp3.ReadFile: %v", err)
+	}
+	if ts := orb.Header.TimeSystem; ts != "GPS" {
+		t.Fatalf("%s: time system %s, want GPS", names[1], ts)
+	}
+	return brdc, orb
+}
+
+// compareIGS checks the positions of the ephemerides given by sel against
+// the precise orbit at the hourly epochs of the day within tol (m), and
+// returns the number of the epochs compared.
+func compareIGS(t *testing.T, orb *sp3.File, id string, sel func(t time.Time) (Broadcast, error), tol float64) (n int) {
+	t.Helper()
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for h := range 24 {
+		ep := t0.Add(time.Duration(h) * time.Hour)
+		eph, err := sel(ep)
+		if err != nil {
+			continue
+		}
+		pos, err := orb.Position(id, ep, sp3.OrbitOpts{})
+		if err != nil {
+			continue
+		}
+		s := eph.State(ep)
+		if d := dist(s.Pos, pos); !(d < tol) {
+			t.Errorf("%s %v: d=%.3f m, get %v, want %v", id, ep, d, s.Pos, pos)
+		}
+		n++
+	}
+	return n
+}
+
+// TestSatellitePositionIGS checks the positions of the GPS satellites of the
+// BRDC file against the IGS rapid orbits within 5 m.
+func TestSatellitePositionIGS(t *testing.T) {
+	brdc, orb := readIGS(t, igsRapidPattern)
+
+	var n int
+	for _, id := range orb.Header.Sats {
+		if !strings.HasPrefix(id, "G") {
+			continue
+		}
+		n += compareIGS(t, orb, id, func(ep time.Time) (Broadcast, error) {
+			return brdc.SelectUsable(id, ep, SelectOpts{})
+		}, 5)
+	}
+	if n == 0 {
+		t.Errorf("no epoch compared")
+	}
+}
+
+// TestBDSPositionIGS checks the positions of the BeiDou GEO and MEO
+// satellites of the BRDC file against the MGEX orbits, within 10 m for GEO,
+// whose broadcast and precise orbits are both less accurate along the track,
+// and 5 m for MEO.
+func TestBDSPositionIGS(t *testing.T) {
+	brdc, orb := readIGS(t, igsMGEXPattern)
+
+	var ngeo, nmeo int
+	for _, id := range orb.Header.Sats {
+		ephs := brdc.Ephs[id]
+		if !strings.HasPrefix(id, "C") || len(ephs) == 0 {
+			continue
+		}
+		sel := func(ep time.Time) (Broadcast, error) {
+			return brdc.SelectUsable(id, ep, SelectOpts{})
+		}
+		switch {
+		case ephs[0].IsGEO():
+			ngeo += compareIGS(t, orb, id, sel, 10)
+		case ephs[0].SqrtA < 6000: // MEO of a=27900 km, not IGSO of 42200 km
+			nmeo += compareIGS(t, orb, id, sel, 5)
+		}
+	}
+	if ngeo == 0 || nmeo == 0 {
+		t.Errorf("no epoch compared: GEO=%d, MEO=%d", ngeo, nmeo)
+	}
+}
+
+// TestSelectGalIGS checks the positions of the F/NAV and the I/NAV
+// ephemerides of the Galileo satellites of the BRDC file against the MGEX
+// orbits within 5 m.
+func TestSelectGalIGS(t *testing.T) {
+	brdc, orb := readIGS(t, igsMGEXPattern)
+
+	for _, msg := range []GalMessage{FNAV, INAV} {
+		var n int
+		for _, id := range orb.Header.Sats {
+			if !strings.HasPrefix(id, "E") {
+				continue
+			}
+			n += compareIGS(t, orb, id, func(ep time.Time) (Broadcast, error) {
+				return brdc.SelectGal(id, ep, msg)
+			}, 5)
+		}
+		if n == 0 {
+			t.Errorf("message %d: no epoch compared", msg)
+		}
+	}
+}
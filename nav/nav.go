@@ -0,0 +1,198 @@
+/*
+Package nav reads the RINEX navigation files of the broadcast ephemerides.
+
+The epochs are in the time system of the satellite system, e.g., GPS time for
+the GPS ephemerides, and are represented as time.Time in UTC without the leap
+seconds, as the other packages of the module.
+*/
+package nav
+
+import (
+	"errors"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// ErrFormat is returned when the file is not a valid RINEX navigation file.
+// The errors of the parser are wrapped with the line number.
+var ErrFormat = errors.New("invalid rinex navigation format")
+
+// Klobuchar is the coefficients of the Klobuchar ionospheric model broadcast
+// by GPS, in seconds and the powers of the semi-circles.
+type Klobuchar struct {
+	Alpha [4]float64
+	Beta  [4]float64
+}
+
+// Header stores the header of a RINEX navigation file.
+type Header struct {
+	// Version is the format version, e.g., 3.04, and SatSystem is the
+	// satellite system, e.g., 'G' or 'M' for the mixed file. SatSystem is 'G'
+	// and 'R' for the GPS and the GLONASS navigation files of the version 2.
+	Version   float64
+	SatSystem byte
+
+	// Iono is the GPS ionospheric coefficients if HasIono, i.e., "ION
+	// ALPHA" and "ION BETA" of the version 2, "IONOSPHERIC CORR" of GPSA
+	// and GPSB of the version 3, or the first ION record of GPS LNAV of the
+	// version 4.
+	Iono    Klobuchar
+	HasIono bool
+
+	// LeapSeconds is the number of the leap seconds if given.
+	LeapSeconds int
+
+	// Comments stores the comment lines.
+	Comments []string
+}
+
+// Ephemeris is a broadcast ephemeris of the Keplerian elements of GPS,
+// Galileo or BeiDou.
+//
+// The angles are in radians, and the times of the week are the seconds from
+// the start of Week. The Galileo weeks are aligned to those of GPS as in
+// RINEX, and the offset of the Galileo system time from GPS time (a few tens
+// of nanoseconds) is ignored. The weeks and the epochs of BeiDou are in BDT,
+// which is 14 seconds behind GPS time.
+type Ephemeris struct {
+	ID  string    // satellite, e.g., "G01"
+	Toc time.Time // epoch of the clock in the time system of the satellite
+
+	// clock polynomial (s, s/s, s/s^2)
+	Af0, Af1, Af2 float64
+
+	IODE int // issue of data, ephemeris (IODnav of Galileo, AODE of BeiDou)
+	IODC int // issue of data, clock (AODC of BeiDou)
+
+	// orbit
+	Toe      float64 // time of the ephemeris (s of week)
+	Week     int     // continuous week number of Toe
+	SqrtA    float64 // square root of the semi-major axis (m^1/2)
+	E        float64 // eccentricity
+	I0       float64 // inclination at Toe
+	Omega0   float64 // longitude of the ascending node at the start of the week
+	Omega    float64 // argument of perigee
+	M0       float64 // mean anomaly at Toe
+	DeltaN   float64 // mean motion difference (rad/s)
+	OmegaDot float64 // rate of the right ascension (rad/s)
+	IDOT     float64 // rate of the inclination (rad/s)
+
+	// harmonic corrections of the argument of latitude and the inclination
+	// (rad), and the radius (m)
+	Cuc, Cus float64
+	Cic, Cis float64
+	Crc, Crs float64
+
+	URA    float64 // user range accuracy (SISA of Galileo) (m)
+	Health int     // SV health, zero if healthy
+	TGD    float64 // group delay differential (TGD1 B1/B3 of BeiDou) (s)
+	TGD2   float64 // group delay differential TGD2 B2/B3 of BeiDou (s)
+
+	// DataSources is the data sources of Galileo (see SourceINAVE1B), and
+	// BGDE5a and BGDE5b are the broadcast group delays E5a/E1 and E5b/E1 (s).
+	DataSources int
+	BGDE5a      float64
+	BGDE5b      float64
+
+	TransTime   float64 // transmission time of the message (s of week)
+	FitInterval float64 // fit interval (h), zero if unknown
+}
+
+// Sat returns the satellite ID.
+func (e *Ephemeris) Sat() string {
+	return e.ID
+}
+
+// TOE returns the time of the ephemeris in GPS time.
+func (e *Ephemeris) TOE() time.Time {
+	if e.ID[0] == 'C' {
+		return gnsstime.BDTFromWeek(e.Week, e.Toe).GPST().Time()
+	}
+	return gnsstime.GPSTFromWeek(e.Week, e.Toe).Time()
+}
+
+// TOC returns the epoch of the clock in GPS time.
+func (e *Ephemeris) TOC() time.Time {
+	if e.ID[0] == 'C' {
+		return gnsstime.NewBDT(e.Toc).GPST().Time()
+	}
+	return e.Toc
+}
+
+// GloEphemeris is a GLONASS broadcast ephemeris of the state vector in PZ-90.
+//
+// PZ-90.11 and WGS84 (G1762) agree within a few centimeters, and no
+// transformation is applied, i.e., the positions are regarded as those of the
+// frame of the GPS ephemerides.
+type GloEphemeris struct {
+	ID  string    // satellite, e.g., "R01"
+	Toc time.Time // epoch of the ephemeris tb in UTC
+
+	// TauN is the clock bias (s), i.e., the negative of the clock
+	// correction, and GammaN is the relative frequency bias.
+	TauN   float64
+	GammaN float64
+
+	FrameTime float64 // message frame time (s of the UTC week)
+
+	// position (m), velocity (m/s) and lunisolar acceleration (m/s^2) in
+	// the Earth-fixed frame at Toc
+	Pos, Vel, Acc [3]float64
+
+	Health  int     // health, zero if healthy
+	FreqNum int     // frequency channel number (-7 to 13)
+	Age     int     // age of the operational information (day)
+	URA     float64 // user range accuracy (m) since the version 3.05, or zero
+
+	// LeapSeconds is the difference of GPS time from UTC (s) at Toc, given
+	// by the header or by the table of the leap seconds if not given.
+	LeapSeconds int
+}
+
+// Sat returns the satellite ID.
+func (e *GloEphemeris) Sat() string {
+	return e.ID
+}
+
+// TOE returns the epoch of the ephemeris in GPS time.
+func (e *GloEphemeris) TOE() time.Time {
+	return e.Toc.Add(time.Duration(e.LeapSeconds) * time.Second)
+}
+
+// Broadcast is a broadcast ephemeris of a satellite, i.e., *Ephemeris or
+// *GloEphemeris.
+type Broadcast interface {
+	// Sat returns the satellite ID, e.g., "G01".
+	Sat() string
+
+	// TOE returns the epoch of the ephemeris in GPS time.
+	TOE() time.Time
+
+	// State returns the state of the satellite at the transmission epoch t
+	// in GPS time.
+	State(t time.Time) SatState
+
+	// Healthy reports whether the satellite is healthy by the ephemeris.
+	Healthy() bool
+
+	// Accuracy returns the user range accuracy (m), or zero if not given.
+	Accuracy() float64
+}
+
+// File stores the contents of a RINEX navigation file.
+type File struct {
+	Header Header
+
+	// Ephs stores the Keplerian ephemerides of each satellite, e.g., "G01",
+	// and Glo stores the GLONASS ephemerides, in the order of Toc. All the
+	// records are retained, including those of the same issue of data.
+	Ephs map[string][]Ephemeris
+	Glo  map[string][]GloEphemeris
+
+	// TimeOffsets, EarthOrientations and Iono store the STO, EOP and ION
+	// records of the version 4 in the order of the file.
+	TimeOffsets       []TimeOffset
+	EarthOrientations []EarthOrientation
+	Iono              []IonoParams
+}
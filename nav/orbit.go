@@ -0,0 +1,296 @@
+package nav
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrNoEphemeris is returned when no ephemeris is valid at the epoch, e.g.,
+// for the satellite not in the file, or the epoch out of the fit intervals.
+var ErrNoEphemeris = errors.New("no valid ephemeris")
+
+// The reasons of ErrNoEphemeris, which are wrapped together with
+// ErrNoEphemeris and can be tested by errors.Is.
+var (
+	// ErrNoData is returned for the satellite without the ephemerides.
+	ErrNoData = errors.New("satellite not found")
+
+	// ErrOutOfFit is returned when the epoch is out of the fit intervals of
+	// all the ephemerides of the satellite.
+	ErrOutOfFit = errors.New("out of the fit intervals")
+
+	// ErrUnhealthy is returned by SelectUsable when all the ephemerides
+	// within the fit intervals are unhealthy or of too large URA.
+	ErrUnhealthy = errors.New("no usable ephemeris")
+)
+
+// DefaultFitInterval is the fit interval (h) of the ephemerides whose
+// Ephemeris.FitInterval is not given.
+const DefaultFitInterval = 4.
+
+// the constants of the GPS ICD (IS-GPS-200)
+const (
+	gmGPS     = 3.986005e14      // gravitational constant (m^3/s^2)
+	omegaEGPS = 7.2921151467e-5  // rotation rate of the Earth (rad/s)
+	relF      = -4.442807633e-10 // relativistic constant F (s/m^1/2)
+)
+
+// the constants of Galileo (OS SIS ICD) and BeiDou (BDS-SIS-ICD)
+const (
+	gmGAL     = 3.986004418e14
+	omegaEGAL = 7.2921151467e-5
+	gmBDS     = 3.986004418e14
+	omegaEBDS = 7.292115e-5
+)
+
+// keplerTol and keplerMaxIter define the iteration of the Kepler equation.
+const (
+	keplerTol     = 1e-14
+	keplerMaxIter = 30
+)
+
+// SatState is the state of a satellite computed from an ephemeris.
+type SatState struct {
+	Pos [3]float64 // position (ECEF, m)
+	Vel [3]float64 // velocity (ECEF, m/s)
+
+	// Clock is the clock bias (s) including the relativistic correction
+	// Relativity, and Drift is its rate (s/s). The sign is that of the
+	// precise products, i.e., the correction of the pseudorange is c*Clock
+	// to be added. The group delay (Ephemeris.TGD) is not applied.
+	Clock float64
+	Drift float64
+
+	// Relativity is the periodic relativistic correction (s) included in
+	// Clock, which is not in the clocks of the precise products.
+	Relativity float64
+}
+
+// ErrSignal is returned by Ephemeris.GroupDelay for the signal not supported
+// by the ephemeris.
+var ErrSignal = errors.New("signal not supported by the ephemeris")
+
+// the carrier frequencies (Hz)
+const (
+	freqL1  = 1575.42e6 // L1, E1
+	freqL2  = 1227.60e6 // L2
+	freqE5a = 1176.45e6 // L5, E5a
+	freqE5b = 1207.14e6 // E5b
+)
+
+// GroupDelay returns the group delay (s) of the signal of the band, i.e., the
+// second character of the RINEX 3 observation codes, e.g., '1' for L1 and E1.
+// It is to be subtracted from SatState.Clock for the single-frequency users.
+//
+// The clock of GPS is that of the L1/L2 ionosphere-free combination, and those
+// of Galileo are of E1/E5b for I/NAV and E1/E5a for F/NAV (see DataSources),
+// for which the delays of L1 and L2, E1 and E5b, and E1 and E5a are given.
+// The clock of BeiDou is that of B3I, and the delays of B1I ('2', TGD1), B2I
+// ('7', TGD2) and B3I ('6', zero) are given.
+func (e *Ephemeris) GroupDelay(band byte) (float64, error) {
+	var delay, freq float64
+	switch e.ID[0] {
+	case 'G':
+		switch band {
+		case '1':
+			return e.TGD, nil
+		case '2':
+			delay, freq = e.TGD, freqL2
+		}
+	case 'C':
+		switch band {
+		case '2':
+			return e.TGD, nil
+		case '7':
+			return e.TGD2, nil
+		case '6':
+			return 0., nil
+		}
+		return 0., fmt.Errorf("%w: %s: band %c", ErrSignal, e.ID, band)
+	case 'E':
+		switch {
+		case e.DataSources&SourceClockE5b != 0 || (e.DataSources&SourceClockE5a == 0 && e.IsINAV()):
+			switch band {
+			case '1':
+				return e.BGDE5b, nil
+			case '7':
+				delay, freq = e.BGDE5b, freqE5b
+			}
+		default:
+			switch band {
+			case '1':
+				return e.BGDE5a, nil
+			case '5':
+				delay, freq = e.BGDE5a, freqE5a
+			}
+		}
+	}
+	if freq == 0 {
+		return 0., fmt.Errorf("%w: %s: band %c", ErrSignal, e.ID, band)
+	}
+	return delay * (freqL1 / freq) * (freqL1 / freq), nil
+}
+
+// constants returns the gravitational constant and the rotation rate of the
+// Earth of the satellite system.
+func constants(sys byte) (gm, omegaE float64) {
+	switch sys {
+	case 'E':
+		return gmGAL, omegaEGAL
+	case 'C':
+		return gmBDS, omegaEBDS
+	default:
+		return gmGPS, omegaEGPS
+	}
+}
+
+// SatellitePosition returns the state of the satellite at the transmission
+// epoch t in GPS time by the ephemeris.
+//
+// The position is in the frame at t, i.e., the rotation of the Earth during
+// the signal travel time is not corrected.
+func SatellitePosition(eph Broadcast, t time.Time) SatState {
+	return eph.State(t)
+}
+
+// State returns the state of the satellite at the epoch t by the algorithm of
+// the ICD from the Keplerian elements.
+func (eph *Ephemeris) State(t time.Time) SatState {
+	gm, omegaE := constants(eph.ID[0])
+
+	a := eph.SqrtA * eph.SqrtA
+	n := math.Sqrt(gm/(a*a*a)) + eph.DeltaN
+	tk := t.Sub(eph.TOE()).Seconds()
+
+	// eccentric anomaly by the Newton iteration
+	e := eph.E
+	M := eph.M0 + n*tk
+	E := M
+	for range keplerMaxIter {
+		dE := (M - E + e*math.Sin(E)) / (1 - e*math.Cos(E))
+		E += dE
+		if math.Abs(dE) < keplerTol {
+			break
+		}
+	}
+	sinE, cosE := math.Sincos(E)
+	dEdt := n / (1 - e*cosE)
+
+	// argument of latitude, radius and inclination with the corrections
+	nu := math.Atan2(math.Sqrt(1-e*e)*sinE, cosE-e)
+	dnudt := math.Sqrt(1-e*e) * dEdt / (1 - e*cosE)
+	phi := nu + eph.Omega
+	sin2p, cos2p := math.Sincos(2 * phi)
+
+	u := phi + eph.Cus*sin2p + eph.Cuc*cos2p
+	r := a*(1-e*cosE) + eph.Crs*sin2p + eph.Crc*cos2p
+	i := eph.I0 + eph.Cis*sin2p + eph.Cic*cos2p + eph.IDOT*tk
+
+	dudt := dnudt * (1 + 2*(eph.Cus*cos2p-eph.Cuc*sin2p))
+	drdt := a*e*sinE*dEdt + 2*dnudt*(eph.Crs*cos2p-eph.Crc*sin2p)
+	didt := eph.IDOT + 2*dnudt*(eph.Cis*cos2p-eph.Cic*sin2p)
+
+	// position in the orbital plane
+	sinu, cosu := math.Sincos(u)
+	xp, yp := r*cosu, r*sinu
+	dxp := drdt*cosu - r*sinu*dudt
+	dyp := drdt*sinu + r*cosu*dudt
+
+	// longitude of the ascending node in the rotating frame, or in the
+	// inertial frame for the GEO satellites of BeiDou
+	geo := eph.IsGEO()
+	dOdt := eph.OmegaDot - omegaE
+	if geo {
+		dOdt = eph.OmegaDot
+	}
+	O := eph.Omega0 + dOdt*tk - omegaE*eph.Toe
+	sinO, cosO := math.Sincos(O)
+	sini, cosi := math.Sincos(i)
+
+	var s SatState
+	s.Pos = [3]float64{
+		xp*cosO - yp*cosi*sinO,
+		xp*sinO + yp*cosi*cosO,
+		yp * sini,
+	}
+	s.Vel = [3]float64{
+		dxp*cosO - dyp*cosi*sinO + yp*sini*sinO*didt - s.Pos[1]*dOdt,
+		dxp*sinO + dyp*cosi*cosO - yp*sini*cosO*didt + s.Pos[0]*dOdt,
+		dyp*sini + yp*cosi*didt,
+	}
+	if geo {
+		s.Pos, s.Vel = geoRotate(s.Pos, s.Vel, omegaE, tk)
+	}
+
+	dt := t.Sub(eph.TOC()).Seconds()
+	s.Relativity = relF * e * eph.SqrtA * sinE
+	s.Clock = eph.Af0 + eph.Af1*dt + eph.Af2*dt*dt + s.Relativity
+	s.Drift = eph.Af1 + 2*eph.Af2*dt + relF*e*eph.SqrtA*cosE*dEdt
+
+	return s
+}
+
+// Select returns the ephemeris of the satellite id valid at the epoch t.
+//
+// The ephemerides whose Toe is within the half of the fit interval
+// (DefaultFitInterval if not given) from t are the candidates, and the one of
+// the nearest Toe is adopted. The tie, e.g., of the records uploaded with a
+// new issue of data, is resolved by the latest transmission time, and then by
+// the last record in the file.
+//
+// The messages of Galileo are not distinguished; see SelectGal.
+func (f *File) Select(id string, t time.Time) (*Ephemeris, error) {
+	return f.selectBy(id, t, nil)
+}
+
+// selectBy is Select of the ephemerides accepted by the filter if not nil.
+func (f *File) selectBy(id string, t time.Time, accept func(*Ephemeris) bool) (*Ephemeris, error) {
+	ephs, ok := f.Ephs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w: %s", ErrNoEphemeris, ErrNoData, id)
+	}
+
+	var best *Ephemeris
+	var bestDt float64
+	for k := range ephs {
+		e := &ephs[k]
+		if accept != nil && !accept(e) {
+			continue
+		}
+		fit := e.FitInterval
+		if fit == 0 {
+			fit = DefaultFitInterval
+		}
+		dt := math.Abs(t.Sub(e.TOE()).Seconds())
+		if dt > fit*3600/2 {
+			continue
+		}
+		if best == nil || dt < bestDt || (dt == bestDt && e.TransTime >= best.TransTime) {
+			best, bestDt = e, dt
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %w: %s at %v", ErrNoEphemeris, ErrOutOfFit, id, t)
+	}
+	return best, nil
+}
+
+// SatellitePosition returns the state of the satellite id at the
+// transmission epoch t by the ephemeris of Select, or of SelectGlo for the
+// GLONASS satellites.
+func (f *File) SatellitePosition(id string, t time.Time) (SatState, error) {
+	var eph Broadcast
+	var err error
+	if strings.HasPrefix(id, "R") {
+		eph, err = f.SelectGlo(id, t)
+	} else {
+		eph, err = f.Select(id, t)
+	}
+	if err != nil {
+		return SatState{}, err
+	}
+	return eph.State(t), nil
+}
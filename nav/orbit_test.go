@@ -0,0 +1,162 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSatellitePosition checks the fit intervals, the radii against the
+// elements and the continuity over the start of the GPS week at 00:00.
+// TestSatellitePositionIGS checks the positions against the IGS orbits.
+func TestSatellitePosition(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for _, dt := range []time.Duration{
+		-100 * time.Minute, // previous week
+		0,
+		7*time.Minute + 30*time.Second,
+		71 * time.Minute,
+		170 * time.Minute, // second ephemeris of G14
+	} {
+		ep := t0.Add(dt)
+		for id := range f.Ephs {
+			s, err := f.SatellitePosition(id, ep)
+			if dt > 2*time.Hour && id != "G14" {
+				if !errors.Is(err, ErrNoEphemeris) {
+					t.Errorf("%s %v: get err=%v, want %v", id, dt, err, ErrNoEphemeris)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s %v: %v", id, dt, err)
+				continue
+			}
+
+			// between the perigee and the apogee, with the perturbations of
+			// a few kilometers
+			eph, _ := f.Select(id, ep)
+			a := eph.SqrtA * eph.SqrtA
+			if r := math.Sqrt(sqr(s.Pos[0]) + sqr(s.Pos[1]) + sqr(s.Pos[2])); r < a*(1-eph.E)-5e3 || r > a*(1+eph.E)+5e3 {
+				t.Errorf("%s %v: radius: %.3f km, a=%.3f km, e=%.4f", id, dt, r/1e3, a/1e3, eph.E)
+			}
+			if r := s.Relativity * 299792458.; math.Abs(r) > 20 {
+				t.Errorf("%s %v: relativity: %.3f m", id, dt, r)
+			}
+		}
+	}
+
+	// the satellites move by ~4 km in a second over the start of the week
+	for id := range f.Ephs {
+		s0, err := f.SatellitePosition(id, t0.Add(-time.Second))
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		s1, err := f.SatellitePosition(id, t0)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if d := dist(s0.Pos, s1.Pos); d > 5e3 {
+			t.Errorf("%s: d=%.3f m over the week", id, d)
+		}
+	}
+}
+
+// TestSatelliteVelocity checks the velocities and the clock drifts against
+// the differences of the positions and the clocks.
+func TestSatelliteVelocity(t *testing.T) {
+	f, err := ReadFile(testFile2)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	ep := time.Date(2024, 7, 14, 0, 40, 0, 0, time.UTC)
+	const h = 0.5 // half of the step (s)
+	step := time.Duration(h * 1e9)
+	for id, ephs := range f.Ephs {
+		eph := &ephs[0]
+		s := SatellitePosition(eph, ep)
+		s0 := SatellitePosition(eph, ep.Add(-step))
+		s1 := SatellitePosition(eph, ep.Add(step))
+		for c := range 3 {
+			if v := (s1.Pos[c] - s0.Pos[c]) / (2 * h); math.Abs(v-s.Vel[c]) > 1e-4 {
+				t.Errorf("%s: velocity[%d]: get %.6f, want %.6f", id, c, s.Vel[c], v)
+			}
+		}
+		if d := (s1.Clock - s0.Clock) / (2 * h); math.Abs(d-s.Drift) > 1e-16 {
+			t.Errorf("%s: drift: get %e, want %e", id, s.Drift, d)
+		}
+
+		// speed of the MEO satellites in ECEF
+		if v := math.Sqrt(sqr(s.Vel[0]) + sqr(s.Vel[1]) + sqr(s.Vel[2])); v < 2500 || v > 4000 {
+			t.Errorf("%s: speed: %.3f m/s", id, v)
+		}
+	}
+}
+
+// TestSelect checks the selection of the ephemerides.
+func TestSelect(t *testing.T) {
+	f, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	g14 := f.Ephs["G14"]
+
+	for _, tt := range []struct {
+		dt   time.Duration
+		want int // index of the ephemeris, -1 for the error
+	}{
+		{-2 * time.Hour, 0},
+		{59 * time.Minute, 0},
+		{61 * time.Minute, 1},
+		{4 * time.Hour, 1},
+		{-121 * time.Minute, -1},
+		{241 * time.Minute, -1},
+	} {
+		e, err := f.Select("G14", t0.Add(tt.dt))
+		if tt.want < 0 {
+			if !errors.Is(err, ErrNoEphemeris) {
+				t.Errorf("%v: get err=%v, want %v", tt.dt, err, ErrNoEphemeris)
+			}
+			continue
+		}
+		if err != nil || e != &g14[tt.want] {
+			t.Errorf("%v: get %+v (err=%v), want %d", tt.dt, e, err, tt.want)
+		}
+	}
+
+	// the two ephemerides give the same orbit
+	ep := t0.Add(time.Hour)
+	s0, s1 := SatellitePosition(&g14[0], ep), SatellitePosition(&g14[1], ep)
+	if d := math.Sqrt(sqr(s0.Pos[0]-s1.Pos[0]) + sqr(s0.Pos[1]-s1.Pos[1]) + sqr(s0.Pos[2]-s1.Pos[2])); d > 1e-3 {
+		t.Errorf("G14: d=%e", d)
+	}
+
+	if _, err := f.Select("G01", t0); !errors.Is(err, ErrNoEphemeris) {
+		t.Errorf("G01: get err=%v, want %v", err, ErrNoEphemeris)
+	}
+
+	// the same Toe of a new issue, and the fit interval given
+	e0 := g14[0]
+	e1 := e0
+	e1.IODE, e1.TransTime = e0.IODE+1, e0.TransTime+60
+	e2 := e0
+	e2.FitInterval = 6
+	f2 := File{Ephs: map[string][]Ephemeris{"G14": {e1, e0}, "G15": {e2}}}
+	if e, err := f2.Select("G14", t0); err != nil || e.IODE != e1.IODE {
+		t.Errorf("new issue: get %+v (err=%v)", e, err)
+	}
+	if _, err := f2.Select("G15", t0.Add(-179*time.Minute)); err != nil {
+		t.Errorf("fit interval: %v", err)
+	}
+}
+
+func sqr(x float64) float64 {
+	return x * x
+}
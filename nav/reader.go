@@ -0,0 +1,396 @@
+package nav
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ReadFile reads the RINEX navigation file of the name.
+func ReadFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a RINEX navigation file of the versions 2 to 4 from r, i.e.,
+// the GPS and the GLONASS files of the version 2, or the files of the versions
+// 3 and 4 including the mixed files.
+//
+// The ephemerides of GPS, Galileo, BeiDou and GLONASS are stored, and the
+// records of the other systems are skipped. The records of the version 4 are
+// read by parseData4. The errors are wrapped with the
+// line number, and the format errors are tested by errors.Is with ErrFormat.
+func Parse(r io.Reader) (*File, error) {
+	p := parser{s: mscanner.NewScanner(r)}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return f, nil
+}
+
+// parser holds the state of Parse.
+type parser struct {
+	s *mscanner.Scanner
+	f File
+
+	line   string // current line padded to 80 columns
+	unread bool   // line to be returned again by next
+	v3     bool   // version 3 layout, also of the records of the version 4
+	v4     bool   // version 4 framing of the records
+}
+
+// next reads the next line, and returns false at the end of the input.
+func (p *parser) next() bool {
+	if p.unread {
+		p.unread = false
+		return true
+	}
+	if !p.s.Scan() {
+		return false
+	}
+	p.line = fmt.Sprintf("%-80s", strings.TrimRight(p.s.Text(), "\r"))
+	return true
+}
+
+func (p *parser) parse() (*File, error) {
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+	parseData := p.parseData
+	if p.v4 {
+		parseData = p.parseData4
+	}
+	if err := parseData(); err != nil {
+		return nil, err
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	for _, ephs := range p.f.Ephs {
+		sort.SliceStable(ephs, func(i, j int) bool { return ephs[i].Toc.Before(ephs[j].Toc) })
+	}
+	for _, ephs := range p.f.Glo {
+		sort.SliceStable(ephs, func(i, j int) bool { return ephs[i].Toc.Before(ephs[j].Toc) })
+	}
+	return &p.f, nil
+}
+
+// parseHeader parses the header lines until "END OF HEADER".
+func (p *parser) parseHeader() (err error) {
+	h := &p.f.Header
+
+	if !p.next() || label(p.line) != "RINEX VERSION / TYPE" {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(p.line))
+	}
+	if h.Version, err = field.Atof(p.line[:9]); err != nil {
+		return fmt.Errorf("%w: version: %v", ErrFormat, err)
+	}
+	switch typ := p.line[20]; {
+	case h.Version >= 2 && h.Version < 3 && typ == 'N':
+		h.SatSystem = 'G'
+	case h.Version >= 2 && h.Version < 3 && typ == 'G':
+		h.SatSystem = 'R'
+	case typ != 'N':
+		return fmt.Errorf("%w: file type: '%c'", ErrFormat, typ)
+	case h.Version >= 3 && h.Version < 4:
+		p.v3 = true
+		if h.SatSystem = p.line[40]; h.SatSystem == ' ' {
+			h.SatSystem = 'G'
+		}
+	case h.Version >= 4 && h.Version < 5:
+		p.v3, p.v4 = true, true
+		h.SatSystem = p.line[40]
+	default:
+		return fmt.Errorf("%w: unsupported version: %.2f", ErrFormat, h.Version)
+	}
+
+	var alpha, beta bool
+	for p.next() {
+		l := p.line
+		switch label(l) {
+		case "ION ALPHA":
+			if h.Iono.Alpha, err = parseIono(l[2:]); err != nil {
+				return err
+			}
+			alpha = true
+		case "ION BETA":
+			if h.Iono.Beta, err = parseIono(l[2:]); err != nil {
+				return err
+			}
+			beta = true
+		case "IONOSPHERIC CORR":
+			switch l[:4] {
+			case "GPSA":
+				if h.Iono.Alpha, err = parseIono(l[5:]); err != nil {
+					return err
+				}
+				alpha = true
+			case "GPSB":
+				if h.Iono.Beta, err = parseIono(l[5:]); err != nil {
+					return err
+				}
+				beta = true
+			}
+		case "LEAP SECONDS":
+			if h.LeapSeconds, err = field.Atoi(l[:6]); err != nil {
+				return fmt.Errorf("%w: leap seconds: %v", ErrFormat, err)
+			}
+		case "COMMENT":
+			h.Comments = append(h.Comments, strings.TrimRight(l[:60], " "))
+		case "END OF HEADER":
+			if alpha != beta {
+				return fmt.Errorf("%w: ionospheric alpha=%t, beta=%t", ErrFormat, alpha, beta)
+			}
+			h.HasIono = alpha
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no end of header", ErrFormat)
+}
+
+// parseIono parses the four coefficients of D12.4.
+func parseIono(s string) (v [4]float64, err error) {
+	for k := range v {
+		if v[k], err = field.AtofBlank(s[12*k : 12*k+12]); err != nil {
+			return v, fmt.Errorf("%w: ionospheric coefficient: %v", ErrFormat, err)
+		}
+	}
+	return v, nil
+}
+
+// record is an ephemeris record of the values in the order of the file, i.e.,
+// the three of the clock followed by those of the orbit lines.
+type record struct {
+	id  string
+	toc time.Time
+	v   []float64
+}
+
+// orbitLines returns the number of the orbit lines of the system, where the
+// GLONASS records have the fourth line since the version 3.05.
+func (p *parser) orbitLines(sys byte) int {
+	switch {
+	case sys == 'R' && p.f.Header.Version >= 3.05:
+		return 4
+	case sys == 'R' || sys == 'S':
+		return 3
+	default:
+		return 7
+	}
+}
+
+// parseData parses the ephemeris records:
+//
+//	14 24  7 14  0  0  0.0 4.481626360000D-04 1.234000000000D-12 0.000000000000D+00
+//	    1.230000000000D+02 ...
+//
+// of the version 2, or
+//
+//	G14 2024 07 14 00 00 00 4.481626360000E-04 1.234000000000E-12 0.000000000000E+00
+//	     1.230000000000E+02 ...
+//
+// of the version 3, followed by the orbit lines of four values.
+func (p *parser) parseData() error {
+	p.f.Ephs = make(map[string][]Ephemeris)
+	p.f.Glo = make(map[string][]GloEphemeris)
+
+	for p.next() {
+		if strings.TrimSpace(p.line) == "" {
+			continue
+		}
+		rec, err := p.parseRecord()
+		if err != nil {
+			return err
+		}
+		p.store(rec)
+	}
+
+	return nil
+}
+
+// store stores the ephemeris of the record by the system.
+func (p *parser) store(rec record) {
+	switch rec.id[0] {
+	case 'G':
+		p.f.Ephs[rec.id] = append(p.f.Ephs[rec.id], gpsEphemeris(rec))
+	case 'E':
+		p.f.Ephs[rec.id] = append(p.f.Ephs[rec.id], galEphemeris(rec))
+	case 'C':
+		p.f.Ephs[rec.id] = append(p.f.Ephs[rec.id], bdsEphemeris(rec))
+	case 'R':
+		leap := p.f.Header.LeapSeconds
+		if leap == 0 {
+			leap = leapSecondsAt(rec.toc)
+		}
+		p.f.Glo[rec.id] = append(p.f.Glo[rec.id], gloEphemeris(rec, leap))
+	}
+}
+
+// parseRecord parses a record starting at the current line.
+func (p *parser) parseRecord() (rec record, err error) {
+	l := p.line
+
+	// the lengths of the ID, and the offsets of the orbit values
+	idLen, from := 2, 3
+	if p.v3 {
+		idLen, from = 3, 4
+	}
+	if rec.id, err = satID(l[:idLen], p.f.Header.SatSystem); err != nil {
+		return rec, err
+	}
+	if rec.toc, err = parseEpoch(l[idLen : 20+idLen]); err != nil {
+		return rec, err
+	}
+
+	n := p.orbitLines(rec.id[0])
+	rec.v = make([]float64, 3+4*n)
+	for k := range 3 {
+		col := 20 + idLen + 19*k
+		if rec.v[k], err = field.AtofBlank(l[col : col+19]); err != nil {
+			return rec, fmt.Errorf("%w: %s: clock: %v", ErrFormat, rec.id, err)
+		}
+	}
+	for i := range n {
+		if !p.next() {
+			return rec, fmt.Errorf("%w: %s: orbit line %d not found", ErrFormat, rec.id, i+1)
+		}
+		for k := range 4 {
+			col := from + 19*k
+			if rec.v[3+4*i+k], err = field.AtofBlank(p.line[col : col+19]); err != nil {
+				return rec, fmt.Errorf("%w: %s: orbit line %d: %v", ErrFormat, rec.id, i+1, err)
+			}
+		}
+	}
+
+	return rec, nil
+}
+
+// gpsEphemeris returns the GPS ephemeris of the record. The week modulo
+// 1024 written by some programs is resolved by the epoch of the clock.
+func gpsEphemeris(rec record) Ephemeris {
+	v := rec.v
+	return Ephemeris{
+		ID:  rec.id,
+		Toc: rec.toc,
+		Af0: v[0], Af1: v[1], Af2: v[2],
+
+		IODE: int(v[3]), Crs: v[4], DeltaN: v[5], M0: v[6],
+		Cuc: v[7], E: v[8], Cus: v[9], SqrtA: v[10],
+		Toe: v[11], Cic: v[12], Omega0: v[13], Cis: v[14],
+		I0: v[15], Crc: v[16], Omega: v[17], OmegaDot: v[18],
+		IDOT: v[19], Week: resolveWeek(v[21], rec.toc),
+		URA: v[23], Health: int(v[24]), TGD: v[25], IODC: int(v[26]),
+		TransTime: v[27], FitInterval: v[28],
+	}
+}
+
+// galEphemeris returns the Galileo ephemeris of the record, whose week is
+// resolved as that of GPS.
+func galEphemeris(rec record) Ephemeris {
+	v := rec.v
+	return Ephemeris{
+		ID:  rec.id,
+		Toc: rec.toc,
+		Af0: v[0], Af1: v[1], Af2: v[2],
+
+		IODE: int(v[3]), Crs: v[4], DeltaN: v[5], M0: v[6],
+		Cuc: v[7], E: v[8], Cus: v[9], SqrtA: v[10],
+		Toe: v[11], Cic: v[12], Omega0: v[13], Cis: v[14],
+		I0: v[15], Crc: v[16], Omega: v[17], OmegaDot: v[18],
+		IDOT: v[19], DataSources: int(v[20]), Week: resolveWeek(v[21], rec.toc),
+		URA: v[23], Health: int(v[24]), BGDE5a: v[25], BGDE5b: v[26],
+		TransTime: v[27],
+	}
+}
+
+// bdsEphemeris returns the BeiDou ephemeris of the record.
+func bdsEphemeris(rec record) Ephemeris {
+	v := rec.v
+	return Ephemeris{
+		ID:  rec.id,
+		Toc: rec.toc,
+		Af0: v[0], Af1: v[1], Af2: v[2],
+
+		IODE: int(v[3]), Crs: v[4], DeltaN: v[5], M0: v[6],
+		Cuc: v[7], E: v[8], Cus: v[9], SqrtA: v[10],
+		Toe: v[11], Cic: v[12], Omega0: v[13], Cis: v[14],
+		I0: v[15], Crc: v[16], Omega: v[17], OmegaDot: v[18],
+		IDOT: v[19], Week: int(v[21]),
+		URA: v[23], Health: int(v[24]), TGD: v[25], TGD2: v[26],
+		TransTime: v[27], IODC: int(v[28]),
+	}
+}
+
+// gloEphemeris returns the GLONASS ephemeris of the record, where the state
+// vector is in kilometers. Only the accuracy index of the fourth line of the
+// version 3.05 is stored.
+func gloEphemeris(rec record, leap int) GloEphemeris {
+	v := rec.v
+	var ura float64
+	if len(v) > 17 {
+		ura = gloURA(int(v[17]))
+	}
+	return GloEphemeris{
+		ID:        rec.id,
+		Toc:       rec.toc,
+		TauN:      -v[0],
+		GammaN:    v[1],
+		FrameTime: v[2],
+
+		Pos: [3]float64{v[3] * 1e3, v[7] * 1e3, v[11] * 1e3},
+		Vel: [3]float64{v[4] * 1e3, v[8] * 1e3, v[12] * 1e3},
+		Acc: [3]float64{v[5] * 1e3, v[9] * 1e3, v[13] * 1e3},
+
+		Health:  int(v[6]),
+		FreqNum: int(v[10]),
+		Age:     int(v[14]),
+		URA:     ura,
+
+		LeapSeconds: leap,
+	}
+}
+
+// resolveWeek returns the full GPS week of the week field nearest to the
+// epoch toc in GPS time.
+func resolveWeek(week float64, toc time.Time) int {
+	return gnsstime.ResolveWeek(int(week), gnsstime.NewGPST(toc))
+}
+
+// label returns the header label of the line.
+func label(l string) string {
+	return strings.TrimSpace(l[60:])
+}
+
+// satID returns the satellite ID in the form of "G01". The blank system is
+// that of the file sys, and the blank of the number is zero.
+func satID(s string, sys byte) (string, error) {
+	id, err := field.SatID(s, sys)
+	if err != nil {
+		return "", fmt.Errorf("%w: satellite: %v", ErrFormat, err)
+	}
+	return id.String(), nil
+}
+
+// parseEpoch parses an epoch of the year, month, day, hour, minute and
+// seconds. The two-digit years are those of 1980-2079.
+func parseEpoch(s string) (time.Time, error) {
+	t, err := field.Epoch(s)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return t, nil
+}
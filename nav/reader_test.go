@@ -0,0 +1,190 @@
+package nav
+
+import (
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The fixtures are the GPS ephemerides of the satellites in the SP3 fixture
+// of the bancroft tests in the layouts of the versions 2 and 3, where the
+// latter has the GLONASS records of testFileGlo, the Galileo records of E11,
+// and the BeiDou records of C01 (GEO) and C11 (MEO). G14 has the second
+// ephemeris of the next issue at 02:00.
+const (
+	testFile2 = "testdata/brdc1960_excerpt.24n"
+	testFile3 = "testdata/brdc1960_excerpt.rnx"
+)
+
+// readTestLines returns the lines of the fixture.
+func readTestLines(t *testing.T, name string) []string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+}
+
+// TestReadFile checks the header and the ephemerides of the fixtures.
+func TestReadFile(t *testing.T) {
+	iono := Klobuchar{
+		Alpha: [4]float64{0.1118e-07, 0.1490e-07, -0.5960e-07, -0.1192e-06},
+		Beta:  [4]float64{0.9011e+05, 0.1147e+06, -0.6554e+05, -0.5243e+06},
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name    string
+		version float64
+		sys     byte
+		neph    int
+	}{
+		{testFile2, 2.11, 'G', 9},
+		{testFile3, 3.04, 'M', 12},
+	} {
+		f, err := ReadFile(tt.name)
+		if err != nil {
+			t.Fatalf("%s: ReadFile: %v", tt.name, err)
+		}
+
+		h := f.Header
+		if h.Version != tt.version || h.SatSystem != tt.sys || h.LeapSeconds != 18 || len(h.Comments) != 1 || !h.HasIono {
+			t.Errorf("%s: header: %+v", tt.name, h)
+		}
+		for k := range 4 {
+			if math.Abs(h.Iono.Alpha[k]-iono.Alpha[k]) > 1e-3*math.Abs(iono.Alpha[k]) || math.Abs(h.Iono.Beta[k]-iono.Beta[k]) > 1e-3*math.Abs(iono.Beta[k]) {
+				t.Errorf("%s: iono: get %+v, want %+v", tt.name, h.Iono, iono)
+			}
+		}
+
+		if len(f.Ephs) != tt.neph || len(f.Ephs["G14"]) != 2 || len(f.Ephs["G02"]) != 1 {
+			t.Fatalf("%s: ephemerides: %d", tt.name, len(f.Ephs))
+		}
+		if _, ok := f.Ephs["R05"]; ok {
+			t.Errorf("%s: GLONASS record stored as Keplerian", tt.name)
+		}
+
+		e := f.Ephs["G02"][0]
+		if e.ID != "G02" || !e.Toc.Equal(t0) || e.Af0 != -0.399560198e-03 || e.Af2 != 0 {
+			t.Errorf("%s: clock: %+v", tt.name, e)
+		}
+		if e.IODE != 178 || e.IODC != 178 || e.Week != 2323 || e.Toe != 0 || e.Health != 0 || e.URA != 2 || e.FitInterval != 4 || e.TransTime != -18 {
+			t.Errorf("%s: G02: %+v", tt.name, e)
+		}
+		for _, v := range []struct {
+			name      string
+			get, want float64
+		}{
+			{"crs", e.Crs, -0.756815174748e+02},
+			{"dn", e.DeltaN, 0.508595374504e-08},
+			{"m0", e.M0, -0.163578238648e+01},
+			{"cuc", e.Cuc, -0.343499299002e-06},
+			{"e", e.E, 0.927704101905e-02},
+			{"cus", e.Cus, 0.784127306227e-07},
+			{"sqrtA", e.SqrtA, 0.519244697195e+04},
+			{"cic", e.Cic, 0.238172780836e-08},
+			{"omega0", e.Omega0, 0.273569590501e+01},
+			{"cis", e.Cis, 0.259765440434e-07},
+			{"i0", e.I0, 0.958268799096e+00},
+			{"crc", e.Crc, 0.238107724327e+03},
+			{"omega", e.Omega, 0.266539370869e+01},
+			{"omegaDot", e.OmegaDot, -0.840587654377e-08},
+			{"idot", e.IDOT, -0.196598737375e-09},
+			{"tgd", e.TGD, 0.176395708424e-08},
+		} {
+			if math.Abs(v.get-v.want) > 1e-11*math.Abs(v.want) {
+				t.Errorf("%s: %s: get %.12e, want %.12e", tt.name, v.name, v.get, v.want)
+			}
+		}
+
+		// in the order of Toc
+		g14 := f.Ephs["G14"]
+		if !g14[0].Toc.Equal(t0) || !g14[1].Toc.Equal(t0.Add(2*time.Hour)) || g14[1].Toe != 7200 || g14[1].IODE != g14[0].IODE+1 {
+			t.Errorf("%s: G14: %+v", tt.name, g14)
+		}
+		if toe := g14[1].TOE(); !toe.Equal(t0.Add(2 * time.Hour)) {
+			t.Errorf("%s: TOE: %v", tt.name, toe)
+		}
+	}
+}
+
+// TestVersions checks the versions 2 and 3 give the same ephemerides.
+func TestVersions(t *testing.T) {
+	f2, err := ReadFile(testFile2)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	f3, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	for id, ephs := range f2.Ephs {
+		for k, e2 := range ephs {
+			e3 := f3.Ephs[id][k]
+			v2, v3 := reflect.ValueOf(e2), reflect.ValueOf(e3)
+			for i := range v2.NumField() {
+				a, ok := v2.Field(i).Interface().(float64)
+				if !ok {
+					continue
+				}
+				b := v3.Field(i).Float()
+				if math.Abs(a-b) > 1e-11*math.Abs(a) {
+					t.Errorf("%s[%d]: %s: v2=%.12e, v3=%.12e", id, k, v2.Type().Field(i).Name, a, b)
+				}
+			}
+			if e2.ID != e3.ID || !e2.Toc.Equal(e3.Toc) || e2.IODE != e3.IODE || e2.Week != e3.Week {
+				t.Errorf("%s[%d]: v2=%+v, v3=%+v", id, k, e2, e3)
+			}
+		}
+	}
+}
+
+// TestWeekRollover checks the week modulo 1024 of the version 2.
+func TestWeekRollover(t *testing.T) {
+	lines := readTestLines(t, testFile2)
+	// lines[13] is the line of the week of G02
+	lines[13] = strings.Replace(lines[13], "0.232300000000D+04", "0.275000000000D+03", 1)
+	f, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if e := f.Ephs["G02"][0]; e.Week != 2323 || !e.TOE().Equal(time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("G02: week %d, TOE %v", e.Week, e.TOE())
+	}
+}
+
+// TestParseError checks the format errors with the line numbers.
+func TestParseError(t *testing.T) {
+	lines := readTestLines(t, testFile3)
+
+	// lines[9] is the first record
+	for _, tt := range []struct {
+		name string
+		edit func([]string) []string
+		line string
+	}{
+		{"empty", func([]string) []string { return nil }, "line 0:"},
+		{"file type", func(l []string) []string { l[0] = l[0][:20] + "O" + l[0][21:]; return l }, "line 1:"},
+		{"version", func(l []string) []string { l[0] = "     5.00" + l[0][9:]; return l }, "line 1:"},
+		{"iono", func(l []string) []string { l[4] = l[4][:5] + "  1.1176X-08" + l[4][17:]; return l }, "line 5:"},
+		{"alpha only", func(l []string) []string { return append(l[:5], l[6:]...) }, "line 8:"},
+		{"no end of header", func(l []string) []string { return l[:8] }, "line 8:"},
+		{"satellite", func(l []string) []string { l[9] = "G00" + l[9][3:]; return l }, "line 10:"},
+		{"epoch", func(l []string) []string { l[9] = strings.Replace(l[9], "07 14", "07 xx", 1); return l }, "line 10:"},
+		{"clock", func(l []string) []string { l[9] = l[9][:30] + "x" + l[9][31:]; return l }, "line 10:"},
+		{"orbit", func(l []string) []string { l[11] = l[11][:10] + "x" + l[11][11:]; return l }, "line 12:"},
+		{"truncated", func(l []string) []string { return l[:14] }, "line 14:"},
+	} {
+		in := strings.Join(tt.edit(append([]string{}, lines...)), "\n")
+		_, err := Parse(strings.NewReader(in))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: get err=%v, want %s %v", tt.name, err, tt.line, ErrFormat)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package nav
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+)
+
+// nav logger
+var logger = log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)
+
+// TimeOffset is a system time offset (STO) record of the version 4, i.e., the
+// polynomial of the offset of a time system from another, e.g., GPS time from
+// UTC, broadcast by a satellite.
+type TimeOffset struct {
+	Sat     string // satellite, e.g., "G01"
+	Message string // message type, e.g., "LNAV"
+
+	// Epoch is the reference epoch of the polynomial in the time system of
+	// the satellite, and TransTime is the transmission time (s of week).
+	Epoch     time.Time
+	TransTime float64
+
+	Code string // time offset code, e.g., "GPUT" for GPS time - UTC
+	SBAS string // SBAS identifier if any, e.g., "WAAS"
+	UTC  string // UTC identifier if any, e.g., "UTC(USNO)"
+
+	// polynomial of the offset (s, s/s, s/s^2)
+	A0, A1, A2 float64
+}
+
+// Offset returns the offset (s) at the epoch t in the time system of Epoch.
+func (o *TimeOffset) Offset(t time.Time) float64 {
+	dt := t.Sub(o.Epoch).Seconds()
+	return o.A0 + o.A1*dt + o.A2*dt*dt
+}
+
+// EarthOrientation is an Earth orientation parameter (EOP) record of the
+// version 4.
+type EarthOrientation struct {
+	Sat     string // satellite, e.g., "G01"
+	Message string // message type, e.g., "CNVX"
+
+	// Epoch is the reference epoch in the time system of the satellite, and
+	// TransTime is the transmission time (s of week).
+	Epoch     time.Time
+	TransTime float64
+
+	// the pole coordinates (arcsec, arcsec/day, arcsec/day^2) and UT1-UTC
+	// (s, s/day, s/day^2), and their rates
+	Xp, Yp [3]float64
+	DUT1   [3]float64
+}
+
+// IonoModel is the ionospheric model of an ION record.
+type IonoModel int
+
+// the ionospheric models
+const (
+	KlobucharModel IonoModel = iota // GPS, QZSS, NavIC and BeiDou D1/D2
+	NeQuickGModel                   // Galileo
+	BDGIMModel                      // BeiDou CNAV
+)
+
+// IonoParams is an ionospheric model parameter (ION) record of the version 4.
+// The coefficients of Model are given.
+type IonoParams struct {
+	Sat     string    // satellite, e.g., "G01"
+	Message string    // message type, e.g., "LNAV"
+	Epoch   time.Time // transmission epoch in the time system of the satellite
+
+	Model IonoModel
+
+	// Klobuchar is the coefficients of KlobucharModel, and Region is the
+	// region code of QZSS, i.e., zero for the wide area and one for Japan.
+	Klobuchar Klobuchar
+	Region    int
+
+	// NeQuickG is the effective ionisation level coefficients ai0, ai1 and
+	// ai2 (sfu, sfu/deg, sfu/deg^2), and Flags is the ionospheric disturbance
+	// flags of NeQuickGModel.
+	NeQuickG [3]float64
+	Flags    int
+
+	// BDGIM is the coefficients alpha1 to alpha9 (TECu) of BDGIMModel.
+	BDGIM [9]float64
+}
+
+// parseData4 parses the records of the version 4 started by the line of the
+// record type, the satellite and the message type:
+//
+//	> EPH G14 LNAV
+//	G14 2024 07 14 00 00 00 4.481626360000E-04 1.234000000000E-12 0.000000000000E+00
+//	     1.230000000000E+02 ...
+//
+// where the ephemeris (EPH) records are those of the version 3. The records of
+// the unknown types and the ephemerides of the messages not supported, e.g.,
+// CNAV, are skipped with a warning, and the lines after those of a record are
+// skipped until the next record.
+func (p *parser) parseData4() error {
+	p.f.Ephs = make(map[string][]Ephemeris)
+	p.f.Glo = make(map[string][]GloEphemeris)
+
+	for p.next() {
+		l := p.line
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if l[0] != '>' {
+			return fmt.Errorf("%w: record type not found: '%s'", ErrFormat, strings.TrimSpace(l))
+		}
+		typ, msg := strings.TrimSpace(l[2:5]), strings.TrimSpace(l[10:14])
+		sat, err := satID(l[6:9], p.f.Header.SatSystem)
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		case "EPH":
+			err = p.parseEph(sat, msg)
+		case "STO":
+			err = p.parseTimeOffset(sat, msg)
+		case "EOP":
+			err = p.parseEOP(sat, msg)
+		case "ION":
+			err = p.parseIonoParams(sat, msg)
+		default:
+			logger.Printf("warning: line %d: unknown record type skipped: '%s'\n", p.s.LineNumber(), strings.TrimSpace(l))
+		}
+		if err != nil {
+			return err
+		}
+		p.skipRecord()
+	}
+
+	return nil
+}
+
+// skipRecord skips the lines until the next record.
+func (p *parser) skipRecord() {
+	for p.next() {
+		if p.line[0] == '>' {
+			p.unread = true
+			return
+		}
+	}
+}
+
+// ephMessages is the message types of the ephemerides stored for each system.
+var ephMessages = map[byte][]string{
+	'G': {"LNAV"},
+	'E': {"INAV", "FNAV"},
+	'C': {"D1", "D2"},
+	'R': {"FDMA"},
+}
+
+// parseEph parses an EPH record of the satellite sat and the message type msg.
+// The records of the systems other than those of ephMessages are skipped as
+// in the version 3.
+func (p *parser) parseEph(sat, msg string) error {
+	msgs, ok := ephMessages[sat[0]]
+	if !ok {
+		return nil
+	}
+	if !slices.Contains(msgs, msg) {
+		logger.Printf("warning: line %d: %s: message type not supported: '%s'\n", p.s.LineNumber(), sat, msg)
+		return nil
+	}
+
+	if err := p.recordLine(sat, "EPH", 0); err != nil {
+		return err
+	}
+	if id := p.line[:3]; id != sat {
+		return fmt.Errorf("%w: satellite of the record: '%s', want %s", ErrFormat, id, sat)
+	}
+	rec, err := p.parseRecord()
+	if err != nil {
+		return err
+	}
+	p.store(rec)
+	return nil
+}
+
+// parseTimeOffset parses an STO record of the epoch, the time offset code,
+// and the identifiers of SBAS and UTC, followed by the line of the
+// transmission time and the polynomial.
+func (p *parser) parseTimeOffset(sat, msg string) (err error) {
+	o := TimeOffset{Sat: sat, Message: msg}
+	if err := p.recordLine(sat, "STO", 0); err != nil {
+		return err
+	}
+	l := p.line
+	if o.Epoch, err = parseEpoch(l[4:23]); err != nil {
+		return err
+	}
+	o.Code = strings.TrimSpace(l[24:42])
+	o.SBAS = strings.TrimSpace(l[43:61])
+	o.UTC = strings.TrimSpace(l[62:80])
+
+	if err := p.recordLine(sat, "STO", 1); err != nil {
+		return err
+	}
+	v, err := recordValues(p.line, 4, 4)
+	if err != nil {
+		return fmt.Errorf("%w: %s: STO: %v", ErrFormat, sat, err)
+	}
+	o.TransTime, o.A0, o.A1, o.A2 = v[0], v[1], v[2], v[3]
+
+	p.f.TimeOffsets = append(p.f.TimeOffsets, o)
+	return nil
+}
+
+// parseEOP parses an EOP record of the pole coordinates x and y, and the
+// transmission time and UT1-UTC in the three lines.
+func (p *parser) parseEOP(sat, msg string) (err error) {
+	e := EarthOrientation{Sat: sat, Message: msg}
+	for k, v := range []*[3]float64{&e.Xp, &e.Yp, &e.DUT1} {
+		if err := p.recordLine(sat, "EOP", k); err != nil {
+			return err
+		}
+		switch k {
+		case 0:
+			if e.Epoch, err = parseEpoch(p.line[4:23]); err != nil {
+				return err
+			}
+		case 2:
+			if e.TransTime, err = field.AtofBlank(p.line[4:23]); err != nil {
+				return fmt.Errorf("%w: %s: EOP: %v", ErrFormat, sat, err)
+			}
+		}
+		w, err := recordValues(p.line, 23, 3)
+		if err != nil {
+			return fmt.Errorf("%w: %s: EOP: %v", ErrFormat, sat, err)
+		}
+		copy(v[:], w)
+	}
+
+	p.f.EarthOrientations = append(p.f.EarthOrientations, e)
+	return nil
+}
+
+// parseIonoParams parses an ION record, whose model is that of the system and
+// the message type.
+//
+// The first of the GPS LNAV records is also stored in the header as the
+// ionospheric coefficients of the version 3.
+func (p *parser) parseIonoParams(sat, msg string) (err error) {
+	ion := IonoParams{Sat: sat, Message: msg}
+	n := 3
+	switch {
+	case sat[0] == 'E':
+		ion.Model, n = NeQuickGModel, 2
+	case sat[0] == 'C' && msg == "CNVX":
+		ion.Model = BDGIMModel
+	}
+
+	// the three values of the first line after the epoch, and the four of
+	// the following lines
+	var v []float64
+	for k := range n {
+		if err := p.recordLine(sat, "ION", k); err != nil {
+			return err
+		}
+		col, m := 4, 4
+		if k == 0 {
+			if ion.Epoch, err = parseEpoch(p.line[4:23]); err != nil {
+				return err
+			}
+			col, m = 23, 3
+		}
+		w, err := recordValues(p.line, col, m)
+		if err != nil {
+			return fmt.Errorf("%w: %s: ION: %v", ErrFormat, sat, err)
+		}
+		v = append(v, w...)
+	}
+
+	switch ion.Model {
+	case KlobucharModel:
+		copy(ion.Klobuchar.Alpha[:], v[:4])
+		copy(ion.Klobuchar.Beta[:], v[4:8])
+		ion.Region = int(v[8])
+		if h := &p.f.Header; sat[0] == 'G' && msg == "LNAV" && !h.HasIono {
+			h.Iono, h.HasIono = ion.Klobuchar, true
+		}
+	case NeQuickGModel:
+		copy(ion.NeQuickG[:], v[:3])
+		ion.Flags = int(v[3])
+	case BDGIMModel:
+		copy(ion.BDGIM[:], v[:9])
+	}
+
+	p.f.Iono = append(p.f.Iono, ion)
+	return nil
+}
+
+// recordLine reads the line k of the record of the type typ.
+func (p *parser) recordLine(sat, typ string, k int) error {
+	if !p.next() || p.line[0] == '>' {
+		return fmt.Errorf("%w: %s: %s: line %d not found", ErrFormat, sat, typ, k+1)
+	}
+	return nil
+}
+
+// recordValues parses the n values of D19.12 from the column of the line.
+func recordValues(l string, col, n int) ([]float64, error) {
+	v := make([]float64, n)
+	for k := range v {
+		var err error
+		if v[k], err = field.AtofBlank(l[col+19*k : col+19*k+19]); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
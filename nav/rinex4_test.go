@@ -0,0 +1,129 @@
+package nav
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFile4 is testFile3 in the layout of the version 4 with the STO, EOP and
+// ION records, a CNAV ephemeris of G02, and a record of an unknown type.
+const testFile4 = "testdata/brdc1960_excerpt_v4.rnx"
+
+// captureLog redirects the logger to the returned buffer during the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w := logger.Writer()
+	logger.SetOutput(&buf)
+	t.Cleanup(func() { logger.SetOutput(w) })
+	return &buf
+}
+
+// TestRead4 checks the records of the version 4 against those of the version
+// 3.
+func TestRead4(t *testing.T) {
+	buf := captureLog(t)
+	f4, err := ReadFile(testFile4)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	f3, err := ReadFile(testFile3)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	h := f4.Header
+	if h.Version != 4 || h.SatSystem != 'M' || h.LeapSeconds != 18 || !h.HasIono || h.Iono != f3.Header.Iono {
+		t.Errorf("header: %+v", h)
+	}
+	if !reflect.DeepEqual(f4.Ephs, f3.Ephs) {
+		t.Errorf("ephemerides: get %+v, want %+v", f4.Ephs, f3.Ephs)
+	}
+	// the accuracy index of the fourth line is 2
+	for k := range f4.Glo["R05"] {
+		e := &f4.Glo["R05"][k]
+		if e.URA != 2.5 {
+			t.Errorf("R05: URA: %v", e.URA)
+		}
+		e.URA = 0
+	}
+	if !reflect.DeepEqual(f4.Glo, f3.Glo) {
+		t.Errorf("GLONASS: get %+v, want %+v", f4.Glo, f3.Glo)
+	}
+	if e11 := f4.Ephs["E11"]; len(e11) != 2 || !e11[0].IsINAV() || !e11[1].IsFNAV() {
+		t.Errorf("E11: %+v", e11)
+	}
+
+	// the warnings of the CNAV record and the unknown type
+	for _, s := range []string{"G02: message type not supported: 'CNAV'", "unknown record type skipped: '> XYZ G02 LNAV'"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("warning '%s' not found: %s", s, buf)
+		}
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if len(f4.TimeOffsets) != 1 {
+		t.Fatalf("STO: %+v", f4.TimeOffsets)
+	}
+	o := f4.TimeOffsets[0]
+	if o.Sat != "G02" || o.Message != "LNAV" || !o.Epoch.Equal(t0) || o.Code != "GPUT" || o.SBAS != "" || o.UTC != "UTC(USNO)" || o.TransTime != 503808 || o.A0 != -1.8626451492e-09 || o.A1 != -1.776356839e-15 {
+		t.Errorf("STO: %+v", o)
+	}
+	if d := o.Offset(t0.Add(time.Hour)) - (o.A0 + o.A1*3600); math.Abs(d) > 1e-20 {
+		t.Errorf("STO: offset: d=%e", d)
+	}
+
+	if len(f4.EarthOrientations) != 1 {
+		t.Fatalf("EOP: %+v", f4.EarthOrientations)
+	}
+	e := f4.EarthOrientations[0]
+	if e.Message != "CNVX" || !e.Epoch.Equal(t0) || e.TransTime != 518400 || e.Xp != [3]float64{0.123456789, 1.5e-3, 0} || e.Yp != [3]float64{0.398765432, -2.5e-3, 0} || e.DUT1 != [3]float64{-0.02345678, -1.2e-4, 0} {
+		t.Errorf("EOP: %+v", e)
+	}
+
+	if len(f4.Iono) != 3 {
+		t.Fatalf("ION: %+v", f4.Iono)
+	}
+	if ion := f4.Iono[0]; ion.Sat != "G02" || ion.Model != KlobucharModel || ion.Klobuchar != f3.Header.Iono || ion.Region != 0 {
+		t.Errorf("ION G02: %+v", ion)
+	}
+	if ion := f4.Iono[1]; ion.Sat != "E11" || ion.Model != NeQuickGModel || ion.NeQuickG != [3]float64{80.75, 3.9062e-3, 5.249e-3} || ion.Flags != 0 {
+		t.Errorf("ION E11: %+v", ion)
+	}
+	if ion := f4.Iono[2]; ion.Sat != "C11" || ion.Model != BDGIMModel || ion.BDGIM != [9]float64{1.1, 2.2, 3.3, 4.4, 5.5, 6.6, 7.7, 8.8, 9.9} {
+		t.Errorf("ION C11: %+v", ion)
+	}
+}
+
+// TestParseError4 checks the format errors of the records of the version 4.
+func TestParseError4(t *testing.T) {
+	lines := readTestLines(t, testFile4)
+	captureLog(t)
+
+	// lines[5] is the STO record, lines[8] is the EOP record, and lines[23]
+	// is the EPH record of G02
+	for _, tt := range []struct {
+		name string
+		edit func([]string) []string
+		line string
+	}{
+		{"no record type", func(l []string) []string { return append(l[:5], l[6:]...) }, "line 6:"},
+		{"satellite", func(l []string) []string { l[5] = "> STO G00 LNAV"; return l }, "line 6:"},
+		{"STO epoch", func(l []string) []string { l[6] = strings.Replace(l[6], "07 14", "07 xx", 1); return l }, "line 7:"},
+		{"STO truncated", func(l []string) []string { return append(l[:7], l[8:]...) }, "line 8:"},
+		{"EOP value", func(l []string) []string { l[10] = l[10][:30] + "x" + l[10][31:]; return l }, "line 11:"},
+		{"EPH satellite", func(l []string) []string { l[24] = "G03" + l[24][3:]; return l }, "line 25:"},
+		{"EPH orbit", func(l []string) []string { l[26] = l[26][:10] + "x" + l[26][11:]; return l }, "line 27:"},
+	} {
+		in := strings.Join(tt.edit(append([]string{}, lines...)), "\n")
+		_, err := Parse(strings.NewReader(in))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: get err=%v, want %s %v", tt.name, err, tt.line, ErrFormat)
+		}
+	}
+}
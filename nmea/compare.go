@@ -0,0 +1,58 @@
+package nmea
+
+import (
+	"sort"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+)
+
+// Match is a solution matched with the record of the nearest epoch.
+type Match struct {
+	// Solution and Record are the indices of the arguments of Compare.
+	Solution, Record int
+
+	// Offset is the epoch of the record minus that of the solution.
+	Offset time.Duration
+
+	// Error is the error of the solution against the position of the record
+	// (see bancroft.EvalSolution).
+	Error bancroft.PosError
+}
+
+// Compare matches the solutions with the valid records of the heights
+// within the tolerance tol of the epochs, and returns the errors of the
+// solutions against the records, e.g., of the log of the receiver. The
+// records must be in the order of the epochs, and the solutions of Err and
+// those without the records are skipped.
+func Compare(sols []bancroft.Solution, recs []*Record, tol time.Duration) []Match {
+	var idx []int
+	var epochs []time.Time
+	for i, r := range recs {
+		if r.Valid() && r.HasHeight {
+			idx = append(idx, i)
+			epochs = append(epochs, r.GPST())
+		}
+	}
+
+	var ms []Match
+	for i, s := range sols {
+		if s.Err != nil {
+			continue
+		}
+		k := sort.Search(len(epochs), func(k int) bool { return !epochs[k].Before(s.Epoch) })
+		if k > 0 && (k == len(epochs) || s.Epoch.Sub(epochs[k-1]) < epochs[k].Sub(s.Epoch)) {
+			k--
+		}
+		if k == len(epochs) {
+			continue
+		}
+		off := epochs[k].Sub(s.Epoch)
+		if off < -tol || off > tol {
+			continue
+		}
+		r := recs[idx[k]]
+		ms = append(ms, Match{i, idx[k], off, bancroft.EvalSolution(s, r.Position())})
+	}
+	return ms
+}
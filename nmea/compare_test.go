@@ -0,0 +1,59 @@
+package nmea
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestCompare checks the errors of the solutions offset from the records.
+func TestCompare(t *testing.T) {
+	var recs []*Record
+	for k := range 5 {
+		f := testFix(35.7, 139.5, 60, 0, 0)
+		f.Epoch = testEpoch.Add(time.Duration(k) * time.Second)
+		lat, lon, h := f.LatLonHeight()
+		recs = append(recs, &Record{
+			Time: f.Epoch.Add(-18 * time.Second), Lat: lat, Lon: lon, Height: h,
+			HasPosition: true, HasHeight: k != 3, Quality: SPS,
+		})
+	}
+	ref := recs[0].Position()
+	offset := [3]float64{1, -2, 3}
+	sol := func(dt time.Duration) bancroft.Solution {
+		return bancroft.Solution{Epoch: testEpoch.Add(dt), Position: coord.ENUToECEF(ref, offset)}
+	}
+
+	sols := []bancroft.Solution{
+		sol(10 * time.Millisecond),           // record 0
+		sol(1990 * time.Millisecond),         // record 2
+		sol(3 * time.Second),                 // record 2 or 4 without the height of 3, out of tol
+		sol(4*time.Second - time.Nanosecond), // record 4
+		sol(time.Second),                     // Err
+		sol(-time.Second),                    // before the records
+	}
+	sols[4].Err = errors.New("failed")
+
+	ms := Compare(sols, recs, 20*time.Millisecond)
+	if len(ms) != 3 {
+		t.Fatalf("number of matches: %d", len(ms))
+	}
+	for k, want := range [][2]int{{0, 0}, {1, 2}, {3, 4}} {
+		m := ms[k]
+		if m.Solution != want[0] || m.Record != want[1] {
+			t.Errorf("match %d: get %d %d, want %v", k, m.Solution, m.Record, want)
+		}
+		for i := range 3 {
+			if math.Abs(m.Error.ENU[i]-offset[i]) > 1e-6 {
+				t.Errorf("match %d: get %v, want %v", k, m.Error.ENU, offset)
+			}
+		}
+	}
+	if ms[1].Offset != 10*time.Millisecond {
+		t.Errorf("offset: get %v, want 10ms", ms[1].Offset)
+	}
+}
@@ -0,0 +1,497 @@
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+var (
+	// ErrFormat is returned when a sentence is not a valid NMEA sentence.
+	ErrFormat = errors.New("invalid nmea format")
+
+	// ErrChecksum is returned when the checksum of a sentence is missing or
+	// does not match.
+	ErrChecksum = errors.New("nmea checksum mismatch")
+
+	// ErrNoFix is the error of the solutions of the records without a fix.
+	ErrNoFix = errors.New("no fix")
+)
+
+// Sentence is a sentence whose checksum is verified.
+type Sentence struct {
+	// Talker is the talker ID, e.g., "GP" or "GN", and Type is the sentence
+	// type, e.g., "GGA". Talker is empty for the proprietary sentences,
+	// whose Type is the address without 'P', e.g., "UBX".
+	Talker, Type string
+
+	// Fields is the fields after the address.
+	Fields []string
+}
+
+// ParseSentence parses the sentence of the line. The characters before '$',
+// e.g., the timestamps of the loggers, and the trailing spaces are ignored.
+// The checksum is required, and the errors are tested by errors.Is with
+// ErrChecksum or ErrFormat.
+func ParseSentence(line string) (Sentence, error) {
+	i := strings.IndexByte(line, '$')
+	if i < 0 {
+		return Sentence{}, fmt.Errorf("%w: no '$': '%s'", ErrFormat, line)
+	}
+	line = strings.TrimRight(line[i+1:], " \r\n")
+
+	body, sum, ok := strings.Cut(line, "*")
+	if !ok || len(sum) != 2 {
+		return Sentence{}, fmt.Errorf("%w: no checksum: '%s'", ErrChecksum, line)
+	}
+	c, err := strconv.ParseUint(sum, 16, 8)
+	if err != nil {
+		return Sentence{}, fmt.Errorf("%w: checksum: '%s'", ErrChecksum, sum)
+	}
+	if byte(c) != checksum(body) {
+		return Sentence{}, fmt.Errorf("%w: %02X, want %02X", ErrChecksum, c, checksum(body))
+	}
+
+	fields := strings.Split(body, ",")
+	addr := fields[0]
+	switch {
+	case len(addr) > 1 && addr[0] == 'P':
+		return Sentence{Type: addr[1:], Fields: fields[1:]}, nil
+	case len(addr) != 5:
+		return Sentence{}, fmt.Errorf("%w: address: '%s'", ErrFormat, addr)
+	}
+	return Sentence{Talker: addr[:2], Type: addr[2:], Fields: fields[1:]}, nil
+}
+
+// Record is the fix of an epoch given by the GGA, RMC and GSA sentences of
+// the same time. The fields of the sentences not given are zero.
+type Record struct {
+	// Time is the epoch in UTC (see GPST).
+	Time time.Time
+
+	// Lat, Lon and Height are the geodetic latitude, longitude (rad) and the
+	// ellipsoidal height (m), i.e., the altitude of GGA plus the geoid
+	// height. The altitude is regarded as the ellipsoidal height if the geoid
+	// height is empty. HasHeight is false if the epoch has no GGA.
+	Lat, Lon, Height float64
+	HasPosition      bool
+	HasHeight        bool
+
+	// Quality is the fix quality of GGA, or that of the mode indicator of
+	// RMC if the epoch has no GGA.
+	Quality Quality
+
+	// NumSats and HDOP are those of GGA.
+	NumSats int
+	HDOP    float64
+
+	// FixType (1: no fix, 2: 2D, 3: 3D), PDOP, VDOP and PRNs are those of
+	// GSA, where PRNs are those of all the GSA of the epoch, e.g., of each
+	// satellite system of "GNGSA".
+	FixType    int
+	PDOP, VDOP float64
+	PRNs       []int
+
+	// Speed (m/s) and Course (rad, clockwise from the true north) are those
+	// of RMC.
+	Speed, Course float64
+	HasVelocity   bool
+
+	tod     time.Duration // time of the day of the sentences
+	date    time.Time     // date of RMC
+	hasGGA  bool
+	rmcMode Quality
+}
+
+// Valid returns whether the record has a fix.
+func (r *Record) Valid() bool {
+	return r.HasPosition && r.Quality != NoFix
+}
+
+// GPST returns the epoch of the record in GPS time as the solutions.
+func (r *Record) GPST() time.Time {
+	return gnsstime.ToGPST(r.Time).Time()
+}
+
+// Position returns the position (ECEF, m) of the record.
+func (r *Record) Position() [3]float64 {
+	x, y, z := coord.LLHToXYZ(r.Lat, r.Lon, r.Height)
+	return [3]float64{x, y, z}
+}
+
+// Solution returns the solution of the record, e.g., as the reference of
+// bancroft.EvalSolution, whose Err is ErrNoFix if !r.Valid(). The velocity
+// is the horizontal velocity of RMC.
+func (r *Record) Solution() bancroft.Solution {
+	sol := bancroft.Solution{Epoch: r.GPST()}
+	if !r.Valid() {
+		sol.Err = ErrNoFix
+		return sol
+	}
+	sol.Position = r.Position()
+	sol.NumSats = r.NumSats
+	sol.DOP = bancroft.DOP{PDOP: r.PDOP, HDOP: r.HDOP, VDOP: r.VDOP}
+	if r.HasVelocity {
+		sin, cos := math.Sincos(r.Course)
+		enu := [3]float64{r.Speed * sin, r.Speed * cos, 0}
+		sol.Velocity = coord.RotateT(coord.ENURotation(r.Lat, r.Lon), enu)
+	}
+	return sol
+}
+
+// Stats is the numbers of the sentences read by Reader.
+type Stats struct {
+	// Sentences is the number of the sentences of the valid checksums, and
+	// BadChecksums is the number of the lines skipped by ErrChecksum.
+	Sentences, BadChecksums int
+
+	// Malformed is the number of the sentences skipped by ErrFormat or the
+	// invalid fields, and Ignored is that of the other types, e.g., GSV.
+	Malformed, Ignored int
+}
+
+// Reader reads the records of the GGA, RMC and GSA sentences of a log, e.g.,
+// of a receiver. The sentences of the bad checksums and the malformed ones
+// are counted and skipped, and the lines without '$' are ignored.
+//
+// The sentences of an epoch are those of the same time of the day until the
+// next time, and GSA belongs to the epoch of the sentence before. The date is
+// that of RMC, or that of the epoch before (Date for the first) with the
+// rollover of the day for the logs without RMC.
+type Reader struct {
+	// Date is the date of the epochs before the first RMC.
+	Date time.Time
+
+	Stats
+
+	s    *mscanner.Scanner
+	cur  *Record
+	last time.Time // time of the last record
+}
+
+// NewReader returns the reader of the sentences of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{s: mscanner.NewScanner(r)}
+}
+
+// Next returns the next record, and io.EOF at the end.
+func (r *Reader) Next() (*Record, error) {
+	for r.s.Scan() {
+		line := r.s.Text()
+		if !strings.Contains(line, "$") {
+			continue
+		}
+		s, err := ParseSentence(line)
+		if errors.Is(err, ErrChecksum) {
+			r.BadChecksums++
+			continue
+		}
+		if err != nil {
+			r.Malformed++
+			continue
+		}
+		r.Sentences++
+
+		var done *Record
+		switch s.Type {
+		case "GGA":
+			done, err = r.gga(s.Fields)
+		case "RMC":
+			done, err = r.rmc(s.Fields)
+		case "GSA":
+			err = r.gsa(s.Fields)
+		default:
+			r.Ignored++
+		}
+		if err != nil {
+			r.Malformed++
+		}
+		if done != nil {
+			return done, nil
+		}
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.s.LineNumber(), err)
+	}
+	if r.cur != nil {
+		done := r.finish()
+		r.cur = nil
+		return done, nil
+	}
+	return nil, io.EOF
+}
+
+// record returns the record of the time of the day, and the record before
+// if finished.
+func (r *Reader) record(tod time.Duration) (rec, done *Record) {
+	if r.cur != nil && r.cur.tod != tod {
+		done = r.finish()
+		r.cur = nil
+	}
+	if r.cur == nil {
+		r.cur = &Record{tod: tod}
+	}
+	return r.cur, done
+}
+
+// finish sets the time of the current record.
+func (r *Reader) finish() *Record {
+	rec := r.cur
+	if !rec.date.IsZero() {
+		r.Date = rec.date
+	}
+	rec.Time = r.Date.Add(rec.tod)
+	if rec.date.IsZero() && !r.last.IsZero() && rec.Time.Before(r.last.Add(-12*time.Hour)) {
+		r.Date = r.Date.AddDate(0, 0, 1)
+		rec.Time = r.Date.Add(rec.tod)
+	}
+	r.last = rec.Time
+
+	if !rec.hasGGA {
+		rec.Quality = rec.rmcMode
+	}
+	return rec
+}
+
+// gga parses the fields of GGA.
+func (r *Reader) gga(f []string) (*Record, error) {
+	if len(f) < 11 {
+		return nil, fmt.Errorf("%w: GGA: number of fields: %d", ErrFormat, len(f))
+	}
+	tod, err := timeOfDay(f[0])
+	if err != nil {
+		return nil, err
+	}
+	lat, lon, ok, err := latLon(f[1:5])
+	if err != nil {
+		return nil, err
+	}
+	q, err := atoi(f[5])
+	if err != nil {
+		return nil, err
+	}
+	nsat, err := atoi(f[6])
+	if err != nil {
+		return nil, err
+	}
+	hdop, err := atof(f[7])
+	if err != nil {
+		return nil, err
+	}
+	alt, err := atof(f[8])
+	if err != nil {
+		return nil, err
+	}
+	sep, err := atof(f[10])
+	if err != nil {
+		return nil, err
+	}
+
+	rec, done := r.record(tod)
+	rec.hasGGA = true
+	rec.Quality = Quality(q)
+	rec.NumSats, rec.HDOP = nsat, hdop
+	if ok {
+		rec.Lat, rec.Lon, rec.Height = lat, lon, alt+sep
+		rec.HasPosition, rec.HasHeight = true, f[8] != ""
+	}
+	return done, nil
+}
+
+// rmc parses the fields of RMC.
+func (r *Reader) rmc(f []string) (*Record, error) {
+	if len(f) < 9 {
+		return nil, fmt.Errorf("%w: RMC: number of fields: %d", ErrFormat, len(f))
+	}
+	tod, err := timeOfDay(f[0])
+	if err != nil {
+		return nil, err
+	}
+	lat, lon, ok, err := latLon(f[2:6])
+	if err != nil {
+		return nil, err
+	}
+	speed, err := atof(f[6])
+	if err != nil {
+		return nil, err
+	}
+	course, err := atof(f[7])
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("020106", f[8])
+	if err != nil {
+		return nil, fmt.Errorf("%w: date: '%s'", ErrFormat, f[8])
+	}
+
+	mode := SPS
+	if len(f) > 11 && f[11] != "" {
+		mode = NoFix
+		for q, m := range modes {
+			if f[11][0] == m {
+				mode = q
+			}
+		}
+	}
+	if f[1] != "A" {
+		mode = NoFix
+	}
+
+	rec, done := r.record(tod)
+	rec.date, rec.rmcMode = date, mode
+	if ok && !rec.HasPosition {
+		rec.Lat, rec.Lon, rec.HasPosition = lat, lon, true
+	}
+	if f[6] != "" {
+		rec.Speed, rec.Course, rec.HasVelocity = speed*knot, coord.Deg2Rad(course), true
+	}
+	return done, nil
+}
+
+// gsa parses the fields of GSA into the current record.
+func (r *Reader) gsa(f []string) error {
+	if len(f) < 17 {
+		return fmt.Errorf("%w: GSA: number of fields: %d", ErrFormat, len(f))
+	}
+	if r.cur == nil {
+		r.Ignored++
+		return nil
+	}
+	fix, err := atoi(f[1])
+	if err != nil {
+		return err
+	}
+	var prns []int
+	for _, s := range f[2:14] {
+		if s == "" {
+			continue
+		}
+		prn, err := atoi(s)
+		if err != nil {
+			return err
+		}
+		prns = append(prns, prn)
+	}
+	var dop [3]float64
+	for k := range dop {
+		if dop[k], err = atof(f[14+k]); err != nil {
+			return err
+		}
+	}
+
+	rec := r.cur
+	rec.FixType = fix
+	rec.PRNs = append(rec.PRNs, prns...)
+	rec.PDOP, rec.VDOP = dop[0], dop[2]
+	if !rec.hasGGA {
+		rec.HDOP = dop[1]
+	}
+	return nil
+}
+
+// timeOfDay parses the time of the day of "hhmmss.ss".
+func timeOfDay(s string) (time.Duration, error) {
+	if len(s) < 6 {
+		return 0, fmt.Errorf("%w: time: '%s'", ErrFormat, s)
+	}
+	h, err1 := strconv.Atoi(s[0:2])
+	m, err2 := strconv.Atoi(s[2:4])
+	sec, err3 := strconv.ParseFloat(s[4:], 64)
+	if err := errors.Join(err1, err2, err3); err != nil || h > 23 || m > 59 || sec >= 61 {
+		return 0, fmt.Errorf("%w: time: '%s'", ErrFormat, s)
+	}
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	return d + time.Duration(math.Round(sec*1e3))*time.Millisecond, nil
+}
+
+// latLon parses the fields of the latitude and the longitude, e.g.,
+// "4807.038,N,01131.000,E", into the angles (rad), and returns false if
+// empty.
+func latLon(f []string) (lat, lon float64, ok bool, err error) {
+	if f[0] == "" && f[2] == "" {
+		return 0, 0, false, nil
+	}
+	if lat, err = angle(f[0], f[1], "NS"); err != nil {
+		return 0, 0, false, err
+	}
+	if lon, err = angle(f[2], f[3], "EW"); err != nil {
+		return 0, 0, false, err
+	}
+	return lat, lon, true, nil
+}
+
+// angle returns the angle (rad) of the degrees and the minutes "dddmm.mmmm"
+// of the hemisphere hem of the positive and the negative signs.
+func angle(s, hem, signs string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || len(hem) != 1 || !strings.Contains(signs, hem) {
+		return 0, fmt.Errorf("%w: angle: '%s,%s'", ErrFormat, s, hem)
+	}
+	d := math.Floor(v / 100)
+	a := d + (v-100*d)/60
+	if hem[0] == signs[1] {
+		a = -a
+	}
+	return coord.Deg2Rad(a), nil
+}
+
+// atoi parses the integer field, which is zero if empty.
+func atoi(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: '%s'", ErrFormat, s)
+	}
+	return n, nil
+}
+
+// atof parses the float field, which is zero if empty.
+func atof(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: '%s'", ErrFormat, s)
+	}
+	return v, nil
+}
+
+// ReadFile reads the records of the NMEA log of the name.
+func ReadFile(name string) ([]*Record, Stats, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads all the records of the NMEA log from r by Reader.
+func Parse(r io.Reader) ([]*Record, Stats, error) {
+	nr := NewReader(r)
+	var recs []*Record
+	for {
+		rec, err := nr.Next()
+		if errors.Is(err, io.EOF) {
+			return recs, nr.Stats, nil
+		}
+		if err != nil {
+			return nil, nr.Stats, err
+		}
+		recs = append(recs, rec)
+	}
+}
@@ -0,0 +1,160 @@
+package nmea
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestParseSentence checks the sentences with the prefixes and the errors.
+func TestParseSentence(t *testing.T) {
+	gga := "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"
+	for _, tt := range []struct {
+		line         string
+		talker, typ  string
+		nfields      int
+		err          error
+		errorMessage string
+	}{
+		{gga, "GP", "GGA", 14, nil, ""},
+		{"2023-03-23 12:35:19.123 " + gga + " \r", "GP", "GGA", 14, nil, ""},
+		{sentence("PUBX,00,123519.00"), "", "UBX", 2, nil, ""},
+		{strings.Replace(gga, "545.4", "545.5", 1), "", "", 0, ErrChecksum, "mismatch"},
+		{strings.TrimSuffix(gga, "*47"), "", "", 0, ErrChecksum, "no checksum"},
+		{"$GPGGA,123519*4", "", "", 0, ErrChecksum, "no checksum"},
+		{sentence("GPGGAX,123519"), "", "", 0, ErrFormat, "address"},
+		{"GPGGA,123519*47", "", "", 0, ErrFormat, "no '$'"},
+	} {
+		s, err := ParseSentence(tt.line)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) || !strings.Contains(err.Error(), tt.errorMessage) {
+				t.Errorf("%q: get err=%v, want %v", tt.line, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if s.Talker != tt.talker || s.Type != tt.typ || len(s.Fields) != tt.nfields {
+			t.Errorf("%q: get %s %s %d, want %s %s %d", tt.line, s.Talker, s.Type, len(s.Fields), tt.talker, tt.typ, tt.nfields)
+		}
+	}
+}
+
+// TestReader checks the records of the log of the encoded fixes with the
+// sentences skipped.
+func TestReader(t *testing.T) {
+	var b strings.Builder
+	fixes := make([]Fix, 3)
+	for k := range fixes {
+		fixes[k] = testFix(-33.5+float64(k)*1e-5, -70.25, 100, 3, -4)
+		fixes[k].Epoch = testEpoch.Add(time.Duration(k) * time.Second)
+		fixes[k].Quality = DGNSS
+		if err := Encode(&b, "GN", fixes[k]); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		b.WriteString(sentence("GNGSA,A,3,05,12,,,,,,,,,,,1.8,0.9,1.5,1") + "\r\n")
+		b.WriteString(sentence("GNGSA,A,3,71,,,,,,,,,,,,1.8,0.9,1.5,3") + "\r\n")
+	}
+	log := b.String()
+
+	// the corrupted, the malformed, the ignored and the garbage lines
+	lines := strings.Split(log, "\r\n")
+	lines[0] = "[12:35:19.003] " + lines[0]
+	lines[5] = strings.Replace(lines[5], ",S,", ",N,", 1)
+	lines = slices.Insert(lines, 2,
+		sentence("GPGSV,1,1,01,05,45,120,40"),
+		sentence("GNGGA,1235xx.00,,,,,0,00,,,,,,,"),
+		"garbage",
+		"$GNGGA,123520.00,,,,,0,00,,,,,,,",
+	)
+	log = strings.Join(lines, "\n")
+
+	recs, stats, err := Parse(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if stats != (Stats{Sentences: 13, BadChecksums: 2, Malformed: 1, Ignored: 1}) {
+		t.Errorf("stats: %+v", stats)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("number of records: %d", len(recs))
+	}
+
+	for k, r := range recs {
+		f := fixes[k]
+		lat, lon, h := f.LatLonHeight()
+		if !r.GPST().Equal(f.Epoch) || !r.Time.Equal(f.Epoch.Add(-18*time.Second)) {
+			t.Errorf("record %d: time: get %v, want %v", k, r.GPST(), f.Epoch)
+		}
+		// the minutes of 5 decimals are 1e-5/60 deg
+		if math.Abs(r.Lat-lat) > coord.Deg2Rad(1e-7) || math.Abs(r.Lon-lon) > coord.Deg2Rad(1e-7) || math.Abs(r.Height-h) > 1e-3 {
+			t.Errorf("record %d: position: get %v %v %v, want %v %v %v", k, r.Lat, r.Lon, r.Height, lat, lon, h)
+		}
+		if r.Quality != DGNSS || r.NumSats != 8 || r.FixType != 3 || r.PDOP != 1.8 || r.HDOP != 0.9 || r.VDOP != 1.5 {
+			t.Errorf("record %d: %+v", k, r)
+		}
+		if !slices.Equal(r.PRNs, []int{5, 12, 71}) {
+			t.Errorf("record %d: PRNs: %v", k, r.PRNs)
+		}
+		if k == 1 {
+			// RMC of the bad checksum
+			if r.HasVelocity {
+				t.Errorf("record %d: velocity of the bad RMC", k)
+			}
+			continue
+		}
+		course := coord.Deg2Rad(180 - coord.Rad2Deg(math.Atan(3./4)))
+		if !r.HasVelocity || math.Abs(r.Speed-5) > 1e-3 || math.Abs(r.Course-course) > 1e-3 {
+			t.Errorf("record %d: velocity: get %v %v, want 5 %v", k, r.Speed, r.Course, course)
+		}
+		sol := r.Solution()
+		if e := coord.Rotate(coord.ENURotation(lat, lon), sol.Velocity); math.Abs(e[0]-3) > 1e-2 || math.Abs(e[1]+4) > 1e-2 {
+			t.Errorf("record %d: velocity of the solution: %v", k, e)
+		}
+	}
+}
+
+// TestReaderDate checks the dates of the log of GGA only and of RMC only.
+func TestReaderDate(t *testing.T) {
+	log := strings.Join([]string{
+		sentence("GPGGA,235959.00,3600.00000,N,14000.00000,E,1,05,1.2,10.0,M,,M,,"),
+		sentence("GPGGA,000000.00,3600.00000,N,14000.00000,E,1,05,1.2,10.0,M,,M,,"),
+		sentence("GPGGA,000001.00,,,,,0,00,,,,,,,"),
+		sentence("GPRMC,000002.00,A,3600.00000,N,14000.00000,E,,,010124,,,D"),
+		sentence("GPRMC,000003.00,V,,,,,,,010124,,,N"),
+	}, "\n")
+	r := NewReader(strings.NewReader(log))
+	r.Date = time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	t0 := time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)
+	for k, want := range []struct {
+		valid, height bool
+		quality       Quality
+	}{
+		{true, true, SPS}, {true, true, SPS}, {false, false, NoFix}, {true, false, DGNSS}, {false, false, NoFix},
+	} {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", k, err)
+		}
+		if tt := t0.Add(time.Duration(k) * time.Second); !rec.Time.Equal(tt) {
+			t.Errorf("record %d: time: get %v, want %v", k, rec.Time, tt)
+		}
+		if rec.Valid() != want.valid || rec.HasHeight != want.height || rec.Quality != want.quality {
+			t.Errorf("record %d: get %v %v %v, want %+v", k, rec.Valid(), rec.HasHeight, rec.Quality, want)
+		}
+		if sol := rec.Solution(); (sol.Err == nil) != want.valid || (!want.valid && !errors.Is(sol.Err, ErrNoFix)) {
+			t.Errorf("record %d: err=%v", k, sol.Err)
+		}
+	}
+	if _, err := r.Next(); err == nil {
+		t.Errorf("no error at the end")
+	}
+}
@@ -0,0 +1,129 @@
+package nmea
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// DefaultTalker is the talker ID of the sentences if not given.
+const DefaultTalker = "GP"
+
+// knot is the speed of a knot (m/s).
+const knot = 1852. / 3600.
+
+// GGA returns the GGA sentence of the fix without CRLF, of the talker ID,
+// e.g., "GN" for the multi-GNSS solutions, or DefaultTalker if empty.
+// The position fields are empty for the fix of Err.
+func GGA(talker string, f Fix) string {
+	fields := []string{talkerID(talker) + "GGA", utcTime(f.Epoch).Format("150405.00")}
+	if f.Err != nil {
+		fields = append(fields, "", "", "", "", "0", "00", "", "", "", "", "", "", "")
+		return sentence(strings.Join(fields, ","))
+	}
+
+	lat, lon, h := f.LatLonHeight()
+	fields = append(fields, latitude(lat), longitude(lon),
+		fmt.Sprintf("%d", f.Quality),
+		fmt.Sprintf("%02d", f.NumSats),
+		fmt.Sprintf("%.1f", f.DOP.HDOP),
+		fmt.Sprintf("%.3f", h-f.GeoidHeight), "M",
+		fmt.Sprintf("%.3f", f.GeoidHeight), "M",
+		"", "") // age and station of the differential corrections
+	return sentence(strings.Join(fields, ","))
+}
+
+// RMC returns the RMC sentence of the fix without CRLF, of the talker ID as
+// GGA. The speed (knots) and the course over ground (deg, clockwise from the
+// true north) are empty if !f.HasVelocity, and the magnetic variation is
+// always empty.
+func RMC(talker string, f Fix) string {
+	t := utcTime(f.Epoch)
+	fields := []string{talkerID(talker) + "RMC", t.Format("150405.00")}
+	if f.Err != nil {
+		fields = append(fields, "V", "", "", "", "", "", "", t.Format("020106"), "", "", "N")
+		return sentence(strings.Join(fields, ","))
+	}
+
+	lat, lon, _ := f.LatLonHeight()
+	status := "A"
+	if f.Quality == NoFix {
+		status = "V"
+	}
+	fields = append(fields, status)
+	fields = append(fields, latitude(lat), longitude(lon))
+
+	speed, course := "", ""
+	if f.HasVelocity {
+		v := coord.Rotate(coord.ENURotation(lat, lon), f.Velocity)
+		speed = fmt.Sprintf("%.3f", math.Hypot(v[0], v[1])/knot)
+		c := coord.Rad2Deg(math.Atan2(v[0], v[1]))
+		if c < 0 {
+			c += 360
+		}
+		if c >= 359.95 {
+			c = 0
+		}
+		course = fmt.Sprintf("%.1f", c)
+	}
+	mode := modes[f.Quality]
+	if mode == 0 {
+		mode = 'N'
+	}
+	fields = append(fields, speed, course, t.Format("020106"), "", "", string(mode))
+	return sentence(strings.Join(fields, ","))
+}
+
+// Encode writes the GGA and the RMC sentences of the fix to w with CRLF, of
+// the talker ID as GGA.
+func Encode(w io.Writer, talker string, f Fix) error {
+	_, err := fmt.Fprintf(w, "%s\r\n%s\r\n", GGA(talker, f), RMC(talker, f))
+	return err
+}
+
+// talkerID returns the talker ID, or DefaultTalker if empty.
+func talkerID(talker string) string {
+	if talker == "" {
+		return DefaultTalker
+	}
+	return talker
+}
+
+// utcTime returns the UTC of the GPS time t rounded to the hundredths of a
+// second of the sentences.
+func utcTime(t time.Time) time.Time {
+	return gnsstime.NewGPST(t).UTC().Round(10 * time.Millisecond)
+}
+
+// latitude returns the fields of the latitude (rad) as "ddmm.mmmmm,N".
+func latitude(lat float64) string {
+	d, m := degMin(lat)
+	ns := "N"
+	if lat < 0 {
+		ns = "S"
+	}
+	return fmt.Sprintf("%02d%08.5f,%s", d, m, ns)
+}
+
+// longitude returns the fields of the longitude (rad) as "dddmm.mmmmm,E".
+func longitude(lon float64) string {
+	d, m := degMin(lon)
+	ew := "E"
+	if lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%03d%08.5f,%s", d, m, ew)
+}
+
+// degMin returns the degrees and the minutes of the absolute angle (rad),
+// where the minutes are rounded to 5 decimals with the carry to the degrees,
+// i.e., 59.999999' is 1 degree and 0 minutes rather than 60 minutes.
+func degMin(rad float64) (int, float64) {
+	n := int64(math.Round(math.Abs(coord.Rad2Deg(rad)) * 60e5))
+	return int(n / 60e5), float64(n%60e5) / 1e5
+}
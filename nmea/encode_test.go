@@ -0,0 +1,111 @@
+package nmea
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// testEpoch is 2023-03-23 12:35:19 UTC in GPS time.
+var testEpoch = time.Date(2023, 3, 23, 12, 35, 37, 0, time.UTC)
+
+// testFix returns the fix at the latitude and the longitude (deg) and the
+// ellipsoidal height (m) with the velocity of the east and the north (m/s).
+func testFix(lat, lon, h, ve, vn float64) Fix {
+	lat, lon = coord.Deg2Rad(lat), coord.Deg2Rad(lon)
+	x, y, z := coord.LLHToXYZ(lat, lon, h)
+	v := coord.RotateT(coord.ENURotation(lat, lon), [3]float64{ve, vn, 0})
+	return Fix{
+		Solution: bancroft.Solution{
+			Epoch:    testEpoch,
+			Position: [3]float64{x, y, z},
+			Velocity: v,
+			NumSats:  8,
+			DOP:      bancroft.DOP{HDOP: 0.94},
+		},
+		Quality:     SPS,
+		HasVelocity: true,
+		GeoidHeight: 46.9,
+	}
+}
+
+// TestChecksum checks the checksums of the classic examples of NMEA.
+func TestChecksum(t *testing.T) {
+	for _, s := range []string{
+		"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47",
+		"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A",
+	} {
+		body, _, _ := strings.Cut(s[1:], "*")
+		if get := sentence(body); get != s {
+			t.Errorf("get %s, want %s", get, s)
+		}
+	}
+}
+
+// TestEncode checks the sentences of the fixes against the known-good
+// sentences.
+func TestEncode(t *testing.T) {
+	southWest := testFix(-33.5, -70.25, 100, 0, 0)
+	southWest.Quality = RTKFixed
+	southWest.HasVelocity = false
+	southWest.GeoidHeight = 0
+
+	invalid := testFix(0, 0, 0, 0, 0)
+	invalid.Err = errors.New("too few satellites")
+
+	for _, tt := range []struct {
+		name     string
+		fix      Fix
+		gga, rmc string
+	}{
+		{
+			"north east",
+			testFix(48+7.038/60, 11+31./60, 592.3, 5, 5),
+			"$GPGGA,123519.00,4807.03800,N,01131.00000,E,1,08,0.9,545.400,M,46.900,M,,*69",
+			"$GPRMC,123519.00,A,4807.03800,N,01131.00000,E,13.745,45.0,230323,,,A*57",
+		},
+		{
+			"south west without velocity",
+			southWest,
+			"$GPGGA,123519.00,3330.00000,S,07015.00000,W,4,08,0.9,100.000,M,0.000,M,,*5B",
+			"$GPRMC,123519.00,A,3330.00000,S,07015.00000,W,,,230323,,,R*4C",
+		},
+		{
+			"rounding to 60 minutes",
+			testFix(35+59.9999999/60, 139+59.9999999/60, 46.9, 0, -1),
+			"$GPGGA,123519.00,3600.00000,N,14000.00000,E,1,08,0.9,0.000,M,46.900,M,,*6B",
+			"$GPRMC,123519.00,A,3600.00000,N,14000.00000,E,1.944,180.0,230323,,,A*51",
+		},
+		{
+			"invalid",
+			invalid,
+			"$GPGGA,123519.00,,,,,0,00,,,,,,,*45",
+			"$GPRMC,123519.00,V,,,,,,,230323,,,N*73",
+		},
+	} {
+		if get := GGA("", tt.fix); get != tt.gga {
+			t.Errorf("%s: get %s, want %s", tt.name, get, tt.gga)
+		}
+		if get := RMC("", tt.fix); get != tt.rmc {
+			t.Errorf("%s: get %s, want %s", tt.name, get, tt.rmc)
+		}
+	}
+}
+
+// TestEncodeWriter checks the sentences written with the talker ID.
+func TestEncodeWriter(t *testing.T) {
+	f := testFix(48+7.038/60, 11+31./60, 592.3, 5, 5)
+	var b bytes.Buffer
+	if err := Encode(&b, "GN", f); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := GGA("GN", f) + "\r\n" + RMC("GN", f) + "\r\n"
+	if get := b.String(); get != want || !strings.HasPrefix(get, "$GNGGA,") {
+		t.Errorf("get %q, want %q", get, want)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Package nmea writes the position solutions as the NMEA 0183 sentences GGA
+and RMC for the downstream consumers, e.g., chart plotters, and reads the
+GGA, RMC and GSA sentences of the logs, e.g., of the receivers, to compare
+with the solutions (see Compare).
+
+The epochs of the solutions are in GPS time as the other packages of the
+module, and are converted from and to UTC of the sentences.
+*/
+package nmea
+
+import (
+	"fmt"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+)
+
+// Quality is the fix quality indicator of GGA.
+type Quality int
+
+// The fix qualities of GGA.
+const (
+	NoFix      Quality = iota // fix not available or invalid
+	SPS                       // standard positioning service
+	DGNSS                     // differential GNSS
+	PPS                       // precise positioning service, or PPP
+	RTKFixed                  // RTK with the fixed integers
+	RTKFloat                  // RTK with the float ambiguities
+	Estimated                 // dead reckoning
+	Manual                    // manual input
+	Simulation                // simulator
+)
+
+// modes is the mode indicators of RMC of the qualities.
+var modes = map[Quality]byte{
+	NoFix: 'N', SPS: 'A', DGNSS: 'D', PPS: 'P', RTKFixed: 'R', RTKFloat: 'F',
+	Estimated: 'E', Manual: 'M', Simulation: 'S',
+}
+
+// Fix is a solution with the fields of the sentences not in the solution.
+type Fix struct {
+	bancroft.Solution
+
+	// Quality is the fix quality, where the solutions of Err are NoFix
+	// regardless of it.
+	Quality Quality
+
+	// HasVelocity is whether Solution.Velocity is valid, i.e., of the
+	// speed and the course of RMC.
+	HasVelocity bool
+
+	// GeoidHeight is the height of the geoid above the ellipsoid (m), i.e.,
+	// the altitude of GGA is the ellipsoidal height minus it. The altitude is
+	// the ellipsoidal height if zero.
+	GeoidHeight float64
+}
+
+// checksum returns the checksum of the sentence s without '$' and '*', i.e.,
+// the XOR of the characters.
+func checksum(s string) byte {
+	var c byte
+	for i := 0; i < len(s); i++ {
+		c ^= s[i]
+	}
+	return c
+}
+
+// sentence returns the sentence of the fields with '$' and the checksum.
+func sentence(body string) string {
+	return fmt.Sprintf("$%s*%02X", body, checksum(body))
+}
@@ -0,0 +1,327 @@
+package ntrip
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// The defaults of the options of Client.
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultGGAInterval = 10 * time.Second
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 2 * time.Minute
+)
+
+// State is the state of the connection of Client.Stream.
+type State int
+
+// The states of the connection.
+const (
+	StateConnecting   State = iota // connecting to the caster
+	StateConnected                 // receiving the stream
+	StateDisconnected              // disconnected, and reconnecting or stopped
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Client is a client of a caster. The zero values of the options are the
+// defaults.
+type Client struct {
+	// Caster is the URL of the caster, e.g., "http://caster.example:2101",
+	// where the port is 2101 if not given, and "https" is connected with
+	// TLS of TLSConfig.
+	Caster    string
+	TLSConfig *tls.Config
+
+	// User and Password are those of the basic authentication, which is
+	// not sent if User is empty.
+	User, Password string
+
+	// Version is the NTRIP version, i.e., 1 or 2 (default), and UserAgent
+	// is the user agent, or DefaultUserAgent if empty.
+	Version   int
+	UserAgent string
+
+	// Timeout is the timeout of the connection, the response and the data
+	// of the stream, after which the connection is closed, or
+	// DefaultTimeout if zero.
+	Timeout time.Duration
+
+	// GGA returns the GGA sentence without CRLF sent to the caster, e.g.,
+	// nmea.GGA of the latest solution, on the connection and every
+	// GGAInterval (default DefaultGGAInterval), which is required by the
+	// mountpoints of the virtual reference stations. The empty sentences
+	// are not sent, and nothing is sent if GGA is nil. GGA is called in the
+	// goroutines of the connections.
+	GGA         func() string
+	GGAInterval time.Duration
+
+	// Backoff is the first interval of the reconnections of Stream, which
+	// is doubled up to MaxBackoff after the connections failed, or
+	// DefaultBackoff and DefaultMaxBackoff if zero.
+	Backoff, MaxBackoff time.Duration
+
+	// OnState is called by Stream on the changes of the state of the
+	// connection with the error of the disconnection, if not nil.
+	OnState func(s State, err error)
+}
+
+// Sourcetable returns the sourcetable of the caster.
+func (c *Client) Sourcetable(ctx context.Context) (*Sourcetable, error) {
+	conn, resp, err := c.open(ctx, "/")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := resp.check(); err != nil {
+		return nil, err
+	}
+	if !resp.sourcetable {
+		return nil, fmt.Errorf("%w: not a sourcetable", ErrResponse)
+	}
+	conn.nc.SetDeadline(time.Now().Add(c.timeout()))
+	return ParseSourcetable(resp.body)
+}
+
+// Open returns the connection of the stream of the mountpoint, which is
+// closed by the cancellation of ctx. The GGA sentences are sent until the
+// connection is closed. ErrAuth and ErrMountpoint are returned for the
+// rejected requests.
+func (c *Client) Open(ctx context.Context, mountpoint string) (*Conn, error) {
+	conn, resp, err := c.open(ctx, "/"+mountpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.check(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.sourcetable {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %s", ErrMountpoint, mountpoint)
+	}
+	conn.body = resp.body
+	conn.nc.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// Stream copies the stream of the mountpoint to w until ctx is done, and
+// returns the error of ctx. The connections lost, failed and timed out are
+// reconnected after the backoff, while ErrAuth, ErrMountpoint and the
+// errors of w are returned immediately.
+func (c *Client) Stream(ctx context.Context, mountpoint string, w io.Writer) error {
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	maxBackoff := c.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	wait := backoff
+	for {
+		c.state(StateConnecting, nil)
+		conn, err := c.Open(ctx, mountpoint)
+		if err == nil {
+			c.state(StateConnected, nil)
+			var n int64
+			var werr error
+			n, err, werr = copyStream(w, conn)
+			conn.Close()
+			if werr != nil {
+				c.state(StateDisconnected, werr)
+				return werr
+			}
+			if n > 0 {
+				wait = backoff
+			}
+		}
+		if ctx.Err() != nil {
+			c.state(StateDisconnected, ctx.Err())
+			return ctx.Err()
+		}
+		c.state(StateDisconnected, err)
+		if errors.Is(err, ErrAuth) || errors.Is(err, ErrMountpoint) {
+			return err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		wait = min(2*wait, maxBackoff)
+	}
+}
+
+// copyStream copies the stream of the connection to w, and returns the
+// number of the bytes copied, the error of the connection, e.g., io.EOF of
+// the connection closed by the caster, and the error of w.
+func copyStream(w io.Writer, conn *Conn) (n int64, err, werr error) {
+	buf := make([]byte, 4096)
+	for {
+		m, err := conn.Read(buf)
+		if m > 0 {
+			if _, werr := w.Write(buf[:m]); werr != nil {
+				return n, nil, werr
+			}
+			n += int64(m)
+		}
+		if err != nil {
+			return n, err, nil
+		}
+	}
+}
+
+// state calls OnState.
+func (c *Client) state(s State, err error) {
+	if c.OnState != nil {
+		c.OnState(s, err)
+	}
+}
+
+// timeout returns the timeout of the connections.
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// open connects to the caster, sends the request of the path and the first
+// GGA, and reads the response header.
+func (c *Client) open(ctx context.Context, path string) (*Conn, *response, error) {
+	u, err := url.Parse(c.Caster)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "2101")
+	}
+
+	d := net.Dialer{Timeout: c.timeout()}
+	nc, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Scheme == "https" {
+		cfg := &tls.Config{}
+		if c.TLSConfig != nil {
+			cfg = c.TLSConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tc := tls.Client(nc, cfg)
+		nc.SetDeadline(time.Now().Add(c.timeout()))
+		if err := tc.HandshakeContext(ctx); err != nil {
+			nc.Close()
+			return nil, nil, err
+		}
+		nc = tc
+	}
+
+	conn := &Conn{c: c, nc: nc, done: make(chan struct{})}
+	conn.stop = context.AfterFunc(ctx, func() { nc.Close() })
+	agent := c.UserAgent
+	if agent == "" {
+		agent = DefaultUserAgent
+	}
+	nc.SetDeadline(time.Now().Add(c.timeout()))
+	if _, err := nc.Write(request(u.Host, path, c.Version, agent, c.User, c.Password)); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if path != "/" && c.GGA != nil {
+		conn.wg.Add(1)
+		go conn.sendGGA()
+	}
+	resp, err := readResponse(bufio.NewReader(nc))
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, resp, nil
+}
+
+// Conn is a connection of the stream of a mountpoint.
+type Conn struct {
+	c    *Client
+	nc   net.Conn
+	body io.Reader
+	stop func() bool
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Read reads the data of the stream, where the error is returned after the
+// timeout of the client without any data.
+func (c *Conn) Read(p []byte) (int, error) {
+	c.nc.SetReadDeadline(time.Now().Add(c.c.timeout()))
+	return c.body.Read(p)
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	var err error
+	c.once.Do(func() {
+		c.stop()
+		close(c.done)
+		err = c.nc.Close()
+		c.wg.Wait()
+	})
+	return err
+}
+
+// sendGGA sends the GGA sentences every interval until the connection is
+// closed.
+func (c *Conn) sendGGA() {
+	defer c.wg.Done()
+	interval := c.c.GGAInterval
+	if interval <= 0 {
+		interval = DefaultGGAInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if s := c.c.GGA(); s != "" {
+			c.nc.SetWriteDeadline(time.Now().Add(c.c.timeout()))
+			_, err := io.WriteString(c.nc, s+"\r\n")
+			if err != nil {
+				return
+			}
+		}
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package ntrip
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testGGA = "$GPGGA,000000.00,3542.6000,N,13948.6000,E,1,08,1.0,10.000,M,40.000,M,,*4B"
+
+// mockCaster is a caster of the mountpoint MOUNT0 of the user "user" and
+// the password "pass" on the local host.
+type mockCaster struct {
+	t       *testing.T
+	ln      net.Listener
+	version int
+
+	// data returns the data of the stream of the connection n (from 0) of
+	// the mountpoint, after which the connection is closed, or false to
+	// stall until the client closes the connection.
+	data func(n int) ([]byte, bool)
+
+	mu       sync.Mutex
+	requests []*http.Request
+	streams  int
+	gga      map[int][]string // GGA of the connections of the stream
+}
+
+// newMockCaster returns the caster of the responses of the version.
+func newMockCaster(t *testing.T, version int, data func(n int) ([]byte, bool)) *mockCaster {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	m := &mockCaster{t: t, ln: ln, version: version, data: data, gga: map[int][]string{}}
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		ln.Close()
+		wg.Wait()
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				m.serve(conn)
+			}()
+		}
+	}()
+	return m
+}
+
+// url returns the URL of the caster.
+func (m *mockCaster) url() string { return "http://" + m.ln.Addr().String() }
+
+func (m *mockCaster) serve(conn net.Conn) {
+	br := bufio.NewReader(conn)
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	m.mu.Unlock()
+
+	table, _ := os.ReadFile(testSourcetableFile)
+	v1table := "SOURCETABLE 200 OK\r\nContent-Type: text/plain\r\n\r\n" + string(table)
+	user, pass, _ := req.BasicAuth()
+	switch {
+	case req.URL.Path == "/":
+		if m.version == 1 {
+			io.WriteString(conn, v1table)
+		} else {
+			io.WriteString(conn, "HTTP/1.1 200 OK\r\nNtrip-Version: Ntrip/2.0\r\nContent-Type: gnss/sourcetable\r\n\r\n"+string(table))
+		}
+		return
+	case user != "user" || pass != "pass":
+		io.WriteString(conn, "HTTP/1.1 401 Unauthorized\r\nWWW-Authenticate: Basic realm=\"/MOUNT0\"\r\n\r\n")
+		return
+	case req.URL.Path != "/MOUNT0" && m.version == 1:
+		io.WriteString(conn, v1table)
+		return
+	case req.URL.Path != "/MOUNT0":
+		io.WriteString(conn, "HTTP/1.1 404 Not Found\r\n\r\n")
+		return
+	}
+
+	m.mu.Lock()
+	n := m.streams
+	m.streams++
+	m.mu.Unlock()
+
+	// the GGA sentences until the connection closed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			l, err := br.ReadString('\n')
+			if err != nil {
+				return
+			}
+			m.mu.Lock()
+			m.gga[n] = append(m.gga[n], strings.TrimRight(l, "\r\n"))
+			m.mu.Unlock()
+		}
+	}()
+
+	data, ok := m.data(n)
+	var w io.Writer = conn
+	if m.version == 1 {
+		io.WriteString(conn, "ICY 200 OK\r\n")
+	} else {
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\nNtrip-Version: Ntrip/2.0\r\nContent-Type: gnss/data\r\nTransfer-Encoding: chunked\r\n\r\n")
+		cw := httputil.NewChunkedWriter(conn)
+		defer cw.Close()
+		w = cw
+	}
+	for k := 0; k < len(data); k += 4 {
+		w.Write(data[k:min(k+4, len(data))])
+	}
+	if !ok {
+		<-closed
+	}
+}
+
+// testWriter is the writer of the stream, which calls done when the data
+// received ends with the suffix.
+type testWriter struct {
+	mu     sync.Mutex
+	b      bytes.Buffer
+	suffix string
+	done   func()
+	err    error
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.b.Write(p)
+	if w.suffix != "" && strings.HasSuffix(w.b.String(), w.suffix) {
+		w.done()
+	}
+	return len(p), nil
+}
+
+// testStates records the states of the callback.
+type testStates struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (s *testStates) on(st State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		st := fmt.Sprintf("%v(%v)", st, err)
+		s.states = append(s.states, st)
+		return
+	}
+	s.states = append(s.states, st.String())
+}
+
+// TestSourcetable checks the sourcetables of the casters of NTRIP 1 and 2,
+// and the requests.
+func TestSourcetable(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		m := newMockCaster(t, version, nil)
+		c := &Client{Caster: m.url(), Version: version}
+		s, err := c.Sourcetable(context.Background())
+		if err != nil {
+			t.Fatalf("version %d: Sourcetable: %v", version, err)
+		}
+		if len(s.Streams) != 4 || s.Streams[0].Mountpoint != "TKYO0" {
+			t.Errorf("version %d: streams %v", version, s.Streams)
+		}
+
+		req := m.requests[0]
+		if get := req.Header.Get("Ntrip-Version"); (get == "Ntrip/2.0") != (version == 2) {
+			t.Errorf("version %d: Ntrip-Version %q", version, get)
+		}
+		if get := req.Header.Get("User-Agent"); get != DefaultUserAgent {
+			t.Errorf("version %d: User-Agent %q", version, get)
+		}
+		if _, _, ok := req.BasicAuth(); ok {
+			t.Errorf("version %d: authorization sent without the user", version)
+		}
+	}
+}
+
+// TestStreamErrors checks the errors of the authentication and the
+// mountpoints returned without the reconnections.
+func TestStreamErrors(t *testing.T) {
+	for _, tt := range []struct {
+		version         int
+		password, mount string
+		err             error
+	}{
+		{2, "wrong", "MOUNT0", ErrAuth},
+		{1, "wrong", "MOUNT0", ErrAuth},
+		{2, "pass", "NONE", ErrMountpoint},
+		{1, "pass", "NONE", ErrMountpoint},
+	} {
+		m := newMockCaster(t, tt.version, nil)
+		var states testStates
+		c := &Client{Caster: m.url(), Version: tt.version, User: "user", Password: tt.password, Backoff: time.Millisecond, OnState: states.on}
+		err := c.Stream(context.Background(), tt.mount, io.Discard)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("version %d %s: get err=%v, want %v", tt.version, tt.mount, err, tt.err)
+		}
+		if len(m.requests) != 1 || len(states.states) != 2 || states.states[0] != "connecting" || !strings.HasPrefix(states.states[1], "disconnected(") {
+			t.Errorf("version %d %s: %d requests, states %v", tt.version, tt.mount, len(m.requests), states.states)
+		}
+	}
+}
+
+// TestStreamReconnect checks the stream of the connections closed by the
+// caster, the GGA sentences of the connections and the states, where the
+// last connection stalls until canceled.
+func TestStreamReconnect(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		m := newMockCaster(t, version, func(n int) ([]byte, bool) {
+			if n < 3 {
+				return []byte(fmt.Sprintf("\xD3frame%d", n)), true
+			}
+			return []byte("\xD3last"), false
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &testWriter{suffix: "last", done: cancel}
+		var states testStates
+		c := &Client{
+			Caster: m.url(), Version: version, User: "user", Password: "pass",
+			GGA: func() string { return testGGA }, GGAInterval: 10 * time.Millisecond,
+			Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond, OnState: states.on,
+		}
+		done := make(chan error)
+		go func() { done <- c.Stream(ctx, "MOUNT0", w) }()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("version %d: get err=%v, want %v", version, err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("version %d: not canceled", version)
+		}
+
+		if get, want := w.b.String(), "\xD3frame0\xD3frame1\xD3frame2\xD3last"; get != want {
+			t.Errorf("version %d: get %q, want %q", version, get, want)
+		}
+		// the GGA sentences of the last connection recorded until closed
+		var gga []string
+		for deadline := time.Now().Add(time.Second); len(gga) == 0 && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			m.mu.Lock()
+			gga = m.gga[3]
+			m.mu.Unlock()
+		}
+		if len(gga) == 0 || gga[0] != testGGA {
+			t.Errorf("version %d: GGA %q", version, gga)
+		}
+		m.mu.Lock()
+		if m.streams != 4 {
+			t.Errorf("version %d: get %d connections, want 4", version, m.streams)
+		}
+		m.mu.Unlock()
+
+		want := strings.Repeat("connecting connected disconnected(EOF) ", 3) + "connecting connected disconnected(context canceled)"
+		if get := strings.Join(states.states, " "); get != want {
+			t.Errorf("version %d: states\nget  %s\nwant %s", version, get, want)
+		}
+	}
+}
+
+// TestStreamTimeout checks the reconnection of the stream stalled, and the
+// cancellation during the backoff.
+func TestStreamTimeout(t *testing.T) {
+	m := newMockCaster(t, 2, func(int) ([]byte, bool) { return nil, false })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var errs []error
+	c := &Client{
+		Caster: m.url(), User: "user", Password: "pass", Timeout: 50 * time.Millisecond, Backoff: 10 * time.Millisecond,
+		OnState: func(s State, err error) {
+			if s != StateDisconnected {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if errs = append(errs, err); len(errs) == 2 {
+				cancel()
+			}
+		},
+	}
+	start := time.Now()
+	if err := c.Stream(ctx, "MOUNT0", io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("get err=%v, want %v", err, context.Canceled)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("canceled after %v", d)
+	}
+	var ne net.Error
+	if len(errs) != 2 || !errors.As(errs[0], &ne) || !ne.Timeout() {
+		t.Errorf("errors: %v", errs)
+	}
+}
+
+// TestStreamWriter checks the error of the writer returned.
+func TestStreamWriter(t *testing.T) {
+	m := newMockCaster(t, 2, func(int) ([]byte, bool) { return []byte("data"), false })
+	werr := errors.New("write failed")
+	c := &Client{Caster: m.url(), User: "user", Password: "pass"}
+	if err := c.Stream(context.Background(), "MOUNT0", &testWriter{err: werr}); !errors.Is(err, werr) {
+		t.Errorf("get err=%v, want %v", err, werr)
+	}
+}
+
+// TestState checks the names of the states.
+func TestState(t *testing.T) {
+	for s, want := range map[State]string{StateConnecting: "connecting", StateConnected: "connected", StateDisconnected: "disconnected", 5: "State(5)"} {
+		if get := s.String(); get != want {
+			t.Errorf("get %s, want %s", get, want)
+		}
+	}
+}
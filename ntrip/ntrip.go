@@ -0,0 +1,141 @@
+/*
+Package ntrip is a client of the NTRIP casters, which reads the sourcetables
+and the data streams of the mountpoints, e.g., of RTCM 3 for the real-time
+processing.
+
+Client.Stream copies the stream of a mountpoint to an io.Writer, reconnecting
+with the backoff after the connection lost, and sends the GGA sentences to
+the caster for the mountpoints of the virtual reference stations. The stream
+is decoded by rtcm3.Decoder through a pipe, e.g.,
+
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(c.Stream(ctx, "MOUNT0", pw)) }()
+	d := rtcm3.NewDecoder(pr)
+
+Both NTRIP 1 of the responses "ICY 200 OK" and NTRIP 2 of HTTP/1.1 with the
+chunked transfer encoding are supported, and the casters of the URLs of
+"https" are connected with TLS.
+*/
+package ntrip
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httputil"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrFormat is returned when the sourcetable is invalid.
+	ErrFormat = errors.New("invalid ntrip sourcetable")
+
+	// ErrAuth is returned when the caster rejects the user, i.e., of the
+	// status 401 or 403.
+	ErrAuth = errors.New("ntrip authentication failed")
+
+	// ErrMountpoint is returned when the mountpoint is not found, i.e., of
+	// the status 404 or the sourcetable returned for the mountpoint.
+	ErrMountpoint = errors.New("ntrip mountpoint not found")
+
+	// ErrResponse is returned for the other responses of the caster.
+	ErrResponse = errors.New("invalid ntrip response")
+)
+
+// DefaultUserAgent is the user agent of the requests if not given, which
+// starts with "NTRIP" as required by the casters.
+const DefaultUserAgent = "NTRIP github.com/satoshi-pes/gnss"
+
+// request returns the request of the path, e.g., "/MOUNT0" of a mountpoint
+// or "/" of the sourcetable, of the version 1 or 2.
+func request(host, path string, version int, agent, user, password string) []byte {
+	var b strings.Builder
+	if version == 1 {
+		fmt.Fprintf(&b, "GET %s HTTP/1.0\r\n", path)
+	} else {
+		fmt.Fprintf(&b, "GET %s HTTP/1.1\r\nHost: %s\r\nNtrip-Version: Ntrip/2.0\r\n", path, host)
+	}
+	fmt.Fprintf(&b, "User-Agent: %s\r\n", agent)
+	if user != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+		fmt.Fprintf(&b, "Authorization: Basic %s\r\n", auth)
+	}
+	if version != 1 {
+		b.WriteString("Connection: close\r\n")
+	}
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// response is a response of a caster.
+type response struct {
+	// code is the status code, and sourcetable is true for the response of
+	// the sourcetable.
+	code        int
+	sourcetable bool
+
+	// body is the body of the response after the header.
+	body io.Reader
+}
+
+// readResponse reads the response header from r, i.e., "ICY 200 OK" of the
+// stream of NTRIP 1 without the header fields, "SOURCETABLE 200 OK" of the
+// sourcetable of NTRIP 1, and the responses of HTTP/1.x of NTRIP 2.
+func readResponse(r *bufio.Reader) (*response, error) {
+	tp := textproto.NewReader(r)
+	status, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("%w: status line: %v", ErrResponse, err)
+	}
+
+	switch {
+	case strings.HasPrefix(status, "ICY 200"):
+		return &response{code: 200, body: r}, nil
+	case strings.HasPrefix(status, "SOURCETABLE 200"):
+		if _, err := tp.ReadMIMEHeader(); err != nil {
+			return nil, fmt.Errorf("%w: header: %v", ErrResponse, err)
+		}
+		return &response{code: 200, sourcetable: true, body: r}, nil
+	case !strings.HasPrefix(status, "HTTP/1."):
+		return nil, fmt.Errorf("%w: status line: '%s'", ErrResponse, status)
+	}
+
+	f := strings.Fields(status)
+	if len(f) < 2 {
+		return nil, fmt.Errorf("%w: status line: '%s'", ErrResponse, status)
+	}
+	code, err := strconv.Atoi(f[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: status line: '%s'", ErrResponse, status)
+	}
+	h, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrResponse, err)
+	}
+	resp := &response{
+		code:        code,
+		sourcetable: strings.HasPrefix(h.Get("Content-Type"), "gnss/sourcetable"),
+		body:        r,
+	}
+	if strings.EqualFold(h.Get("Transfer-Encoding"), "chunked") {
+		resp.body = httputil.NewChunkedReader(r)
+	}
+	return resp, nil
+}
+
+// check returns the error of the status code.
+func (r *response) check() error {
+	switch r.code {
+	case 200:
+		return nil
+	case 401, 403:
+		return fmt.Errorf("%w: status %d", ErrAuth, r.code)
+	case 404:
+		return fmt.Errorf("%w: status %d", ErrMountpoint, r.code)
+	}
+	return fmt.Errorf("%w: status %d", ErrResponse, r.code)
+}
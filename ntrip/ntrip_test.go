@@ -0,0 +1,67 @@
+package ntrip
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestRequest checks the requests of the versions with and without the
+// authentication.
+func TestRequest(t *testing.T) {
+	for _, tt := range []struct {
+		version    int
+		user, pass string
+		want       string
+	}{
+		{1, "user", "pass", "GET /MOUNT0 HTTP/1.0\r\nUser-Agent: NTRIP test\r\nAuthorization: Basic dXNlcjpwYXNz\r\n\r\n"},
+		{2, "", "", "GET /MOUNT0 HTTP/1.1\r\nHost: caster.example:2101\r\nNtrip-Version: Ntrip/2.0\r\nUser-Agent: NTRIP test\r\nConnection: close\r\n\r\n"},
+	} {
+		if get := string(request("caster.example:2101", "/MOUNT0", tt.version, "NTRIP test", tt.user, tt.pass)); get != tt.want {
+			t.Errorf("version %d: get %q, want %q", tt.version, get, tt.want)
+		}
+	}
+}
+
+// TestReadResponse checks the responses of NTRIP 1 and 2, the body of the
+// chunked encoding and the errors of the status.
+func TestReadResponse(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		resp        string
+		code        int
+		sourcetable bool
+		body        string
+		err         error
+	}{
+		{"ICY", "ICY 200 OK\r\ndata", 200, false, "data", nil},
+		{"SOURCETABLE", "SOURCETABLE 200 OK\r\nServer: test\r\nContent-Type: text/plain\r\n\r\nSTR;", 200, true, "STR;", nil},
+		{"HTTP", "HTTP/1.1 200 OK\r\nContent-Type: gnss/sourcetable\r\n\r\nSTR;", 200, true, "STR;", nil},
+		{"chunked", "HTTP/1.1 200 OK\r\nContent-Type: gnss/data\r\nTransfer-Encoding: chunked\r\n\r\n4\r\ndata\r\n3\r\n123\r\n0\r\n\r\n", 200, false, "data123", nil},
+		{"unauthorized", "HTTP/1.1 401 Unauthorized\r\n\r\n", 401, false, "", ErrAuth},
+		{"forbidden", "HTTP/1.0 403 Forbidden\r\n\r\n", 403, false, "", ErrAuth},
+		{"not found", "HTTP/1.1 404 Not Found\r\n\r\n", 404, false, "", ErrMountpoint},
+		{"server error", "HTTP/1.1 503 Service Unavailable\r\n\r\n", 503, false, "", ErrResponse},
+	} {
+		r, err := readResponse(bufio.NewReader(strings.NewReader(tt.resp)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		body, _ := io.ReadAll(r.body)
+		if r.code != tt.code || r.sourcetable != tt.sourcetable || string(body) != tt.body {
+			t.Errorf("%s: get %d %v %q, want %d %v %q", tt.name, r.code, r.sourcetable, body, tt.code, tt.sourcetable, tt.body)
+		}
+		if err := r.check(); !errors.Is(err, tt.err) || (tt.err == nil) != (err == nil) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	for _, resp := range []string{"", "RTSP/1.0 200 OK\r\n\r\n", "HTTP/1.1 OK\r\n\r\n", "HTTP/1.1 200 OK\r\nbad header\r\n\r\n"} {
+		if _, err := readResponse(bufio.NewReader(strings.NewReader(resp))); !errors.Is(err, ErrResponse) {
+			t.Errorf("%q: get err=%v, want %v", resp, err, ErrResponse)
+		}
+	}
+}
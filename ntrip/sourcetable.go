@@ -0,0 +1,266 @@
+package ntrip
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// Sourcetable is the sourcetable of a caster, i.e., the records of the
+// casters, the networks and the streams.
+type Sourcetable struct {
+	Casters  []*Caster
+	Networks []*Network
+	Streams  []*Stream
+}
+
+// Stream is a STR record of a sourcetable.
+type Stream struct {
+	// Mountpoint is the mountpoint, Identifier is the source identifier,
+	// e.g., the city name, Format is the data format, e.g., "RTCM 3.3", and
+	// FormatDetails is the message types, e.g., "1005(10),1077(1)".
+	Mountpoint    string
+	Identifier    string
+	Format        string
+	FormatDetails string
+
+	// Carrier is the phase information, i.e., 0 of none, 1 of L1 and 2 of
+	// L1 and L2, and NavSystem is the satellite systems, e.g., "GPS+GLO".
+	Carrier   int
+	NavSystem string
+
+	// Network is the network, and Country is the ISO 3166 country code.
+	Network string
+	Country string
+
+	// Lat and Lon are the approximate latitude and longitude (deg).
+	Lat, Lon float64
+
+	// NMEA is true if the stream requires the GGA sentences of the client,
+	// e.g., of the virtual reference stations, and Solution is true for the
+	// network solutions, or false for the single base.
+	NMEA     bool
+	Solution bool
+
+	// Generator is the generator of the stream, Compression is the
+	// compression or the encryption, Authentication is "N" (none), "B"
+	// (basic) or "D" (digest), and Fee is true if charged.
+	Generator      string
+	Compression    string
+	Authentication string
+	Fee            bool
+
+	// Bitrate is the bit rate (bps), and Misc is the rest of the fields.
+	Bitrate int
+	Misc    string
+}
+
+// Caster is a CAS record of a sourcetable.
+type Caster struct {
+	Host       string
+	Port       int
+	Identifier string
+	Operator   string
+
+	// NMEA is true if the caster accepts the GGA sentences.
+	NMEA bool
+
+	// Country is the ISO 3166 country code, and Lat and Lon are the
+	// approximate latitude and longitude (deg).
+	Country  string
+	Lat, Lon float64
+
+	// FallbackHost and FallbackPort are the fallback caster, and Misc is the
+	// rest of the fields.
+	FallbackHost string
+	FallbackPort int
+	Misc         string
+}
+
+// Network is a NET record of a sourcetable.
+type Network struct {
+	Identifier     string
+	Operator       string
+	Authentication string
+	Fee            bool
+
+	// WebNet, WebStr and WebReg are the web addresses of the information of
+	// the network, the streams and the registration, and Misc is the rest of
+	// the fields.
+	WebNet, WebStr, WebReg string
+	Misc                   string
+}
+
+// Stream returns the stream of the mountpoint, and false if not found.
+func (s *Sourcetable) Stream(mountpoint string) (*Stream, bool) {
+	for _, st := range s.Streams {
+		if st.Mountpoint == mountpoint {
+			return st, true
+		}
+	}
+	return nil, false
+}
+
+// Nearest returns the stream nearest to the geodetic latitude and the
+// longitude (rad) of the streams accepted by the filter, e.g., of the format
+// "RTCM 3", or of all the streams if the filter is nil. nil is returned if
+// no stream is accepted.
+func (s *Sourcetable) Nearest(lat, lon float64, filter func(*Stream) bool) *Stream {
+	var nearest *Stream
+	best := math.Inf(1)
+	for _, st := range s.Streams {
+		if filter != nil && !filter(st) {
+			continue
+		}
+		if d := centralAngle(lat, lon, st.Lat*math.Pi/180, st.Lon*math.Pi/180); d < best {
+			nearest, best = st, d
+		}
+	}
+	return nearest
+}
+
+// centralAngle returns the central angle (rad) between the points on the
+// sphere of the latitudes and the longitudes (rad), i.e., of the haversine.
+func centralAngle(lat1, lon1, lat2, lon2 float64) float64 {
+	a := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lon2-lon1)/2), 2)
+	return 2 * math.Asin(math.Sqrt(min(a, 1)))
+}
+
+// ParseSourcetable reads a sourcetable from r until ENDSOURCETABLE, i.e.,
+// the body of the response of the caster. The records of the unknown types
+// and the comments are skipped. The errors are wrapped with the line number,
+// and the format errors are tested by errors.Is with ErrFormat.
+func ParseSourcetable(r io.Reader) (*Sourcetable, error) {
+	s := mscanner.NewScanner(r)
+	t, err := parseSourcetable(s)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", s.LineNumber(), err)
+	}
+	return t, nil
+}
+
+func parseSourcetable(s *mscanner.Scanner) (*Sourcetable, error) {
+	t := &Sourcetable{}
+	for s.Scan() {
+		l := strings.TrimRight(s.Text(), "\r")
+		f := strings.Split(l, ";")
+		var err error
+		switch f[0] {
+		case "ENDSOURCETABLE":
+			return t, nil
+		case "STR":
+			var st *Stream
+			if st, err = parseStream(f); err == nil {
+				t.Streams = append(t.Streams, st)
+			}
+		case "CAS":
+			var c *Caster
+			if c, err = parseCaster(f); err == nil {
+				t.Casters = append(t.Casters, c)
+			}
+		case "NET":
+			var n *Network
+			if n, err = parseNetwork(f); err == nil {
+				t.Networks = append(t.Networks, n)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s record: %v: '%s'", ErrFormat, f[0], err, l)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: no ENDSOURCETABLE", ErrFormat)
+}
+
+// parseStream parses the fields of a STR record.
+func parseStream(f []string) (*Stream, error) {
+	if len(f) < 18 {
+		return nil, fmt.Errorf("%d fields", len(f))
+	}
+	st := &Stream{
+		Mountpoint: f[1], Identifier: f[2], Format: f[3], FormatDetails: f[4],
+		NavSystem: f[6], Network: f[7], Country: f[8],
+		NMEA: f[11] == "1", Solution: f[12] == "1",
+		Generator: f[13], Compression: f[14], Authentication: f[15], Fee: f[16] == "Y",
+		Misc: rest(f, 18),
+	}
+	if st.Mountpoint == "" {
+		return nil, fmt.Errorf("empty mountpoint")
+	}
+	var err error
+	if st.Carrier, err = field.AtoiBlank(f[5]); err != nil {
+		return nil, fmt.Errorf("carrier: %v", err)
+	}
+	if st.Lat, st.Lon, err = latLon(f[9], f[10]); err != nil {
+		return nil, err
+	}
+	if st.Bitrate, err = field.AtoiBlank(f[17]); err != nil {
+		return nil, fmt.Errorf("bitrate: %v", err)
+	}
+	return st, nil
+}
+
+// parseCaster parses the fields of a CAS record.
+func parseCaster(f []string) (*Caster, error) {
+	if len(f) < 9 {
+		return nil, fmt.Errorf("%d fields", len(f))
+	}
+	misc := rest(f, 11)
+	f = append(f, "", "")
+	c := &Caster{
+		Host: f[1], Identifier: f[3], Operator: f[4], NMEA: f[5] == "1", Country: f[6],
+		FallbackHost: f[9], Misc: misc,
+	}
+	var err error
+	if c.Port, err = field.AtoiBlank(f[2]); err != nil {
+		return nil, fmt.Errorf("port: %v", err)
+	}
+	if c.Lat, c.Lon, err = latLon(f[7], f[8]); err != nil {
+		return nil, err
+	}
+	if c.FallbackPort, err = field.AtoiBlank(f[10]); err != nil {
+		return nil, fmt.Errorf("fallback port: %v", err)
+	}
+	return c, nil
+}
+
+// parseNetwork parses the fields of a NET record.
+func parseNetwork(f []string) (*Network, error) {
+	if len(f) < 5 {
+		return nil, fmt.Errorf("%d fields", len(f))
+	}
+	misc := rest(f, 8)
+	f = append(f, "", "", "")
+	return &Network{
+		Identifier: f[1], Operator: f[2], Authentication: f[3], Fee: f[4] == "Y",
+		WebNet: f[5], WebStr: f[6], WebReg: f[7], Misc: misc,
+	}, nil
+}
+
+// rest returns the fields from the index i joined by ';', e.g., the
+// miscellaneous information including ';'.
+func rest(f []string, i int) string {
+	if i >= len(f) {
+		return ""
+	}
+	return strings.Join(f[i:], ";")
+}
+
+// latLon parses the latitude and the longitude (deg).
+func latLon(lat, lon string) (float64, float64, error) {
+	la, err := field.AtofBlank(lat)
+	if err != nil {
+		return 0, 0, fmt.Errorf("latitude: %v", err)
+	}
+	lo, err := field.AtofBlank(lon)
+	if err != nil {
+		return 0, 0, fmt.Errorf("longitude: %v", err)
+	}
+	return la, lo, nil
+}
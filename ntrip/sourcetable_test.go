@@ -0,0 +1,117 @@
+package ntrip
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSourcetableFile = "testdata/sourcetable.txt"
+
+// testSourcetable returns the sourcetable of the fixture.
+func testSourcetable(t *testing.T) *Sourcetable {
+	t.Helper()
+	f, err := os.Open(testSourcetableFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	s, err := ParseSourcetable(f)
+	if err != nil {
+		t.Fatalf("ParseSourcetable: %v", err)
+	}
+	return s
+}
+
+// TestParseSourcetable checks the records of the fixture.
+func TestParseSourcetable(t *testing.T) {
+	s := testSourcetable(t)
+	if len(s.Casters) != 1 || len(s.Networks) != 1 || len(s.Streams) != 4 {
+		t.Fatalf("records: get %d %d %d, want 1 1 4", len(s.Casters), len(s.Networks), len(s.Streams))
+	}
+
+	st, ok := s.Stream("TKYO0")
+	switch {
+	case !ok:
+		t.Fatalf("TKYO0 not found")
+	case st.Identifier != "Tokyo" || st.Format != "RTCM 3.3" || st.FormatDetails != "1005(10),1077(1),1087(1),1097(1)":
+		t.Errorf("TKYO0: %+v", st)
+	case st.Carrier != 2 || st.NavSystem != "GPS+GLO+GAL" || st.Network != "EXNET" || st.Country != "JPN":
+		t.Errorf("TKYO0: %+v", st)
+	case st.Lat != 35.71 || st.Lon != 139.81 || st.NMEA || st.Solution || st.Authentication != "B" || st.Fee || st.Bitrate != 9600 || st.Misc != "":
+		t.Errorf("TKYO0: %+v", st)
+	}
+	if st, _ := s.Stream("OSKA0"); st.Misc != "misc;with;semicolons" {
+		t.Errorf("OSKA0: misc %q", st.Misc)
+	}
+	if st, _ := s.Stream("VRS3"); !st.NMEA || !st.Solution || !st.Fee {
+		t.Errorf("VRS3: %+v", st)
+	}
+	if _, ok := s.Stream("NONE"); ok {
+		t.Errorf("NONE found")
+	}
+
+	c := s.Casters[0]
+	if c.Host != "caster.example" || c.Port != 2101 || c.Identifier != "EXAMPLE" || c.FallbackHost != "fallback.example" || c.FallbackPort != 2102 || c.Misc != "http://caster.example/" {
+		t.Errorf("caster: %+v", c)
+	}
+	n := s.Networks[0]
+	if n.Identifier != "EXNET" || n.Authentication != "B" || n.Fee || n.WebReg != "http://caster.example/reg" || n.Misc != "none" {
+		t.Errorf("network: %+v", n)
+	}
+}
+
+// TestNearest checks the streams nearest to the positions with and without
+// the filter.
+func TestNearest(t *testing.T) {
+	s := testSourcetable(t)
+	deg := math.Pi / 180
+	rtcm3 := func(st *Stream) bool { return strings.HasPrefix(st.Format, "RTCM 3") && !st.NMEA }
+	for _, tt := range []struct {
+		lat, lon float64
+		filter   func(*Stream) bool
+		want     string
+	}{
+		{35.6, 139.7, nil, "TKYO0"},
+		{34.9, 135.8, rtcm3, "OSKA0"},
+		{36.1, 138.1, nil, "VRS3"},
+		{36.1, 138.1, rtcm3, "TKYO0"},
+		{43.0, 141.3, nil, "RAW0"},
+		{43.0, 141.3, rtcm3, "TKYO0"},
+	} {
+		if get := s.Nearest(tt.lat*deg, tt.lon*deg, tt.filter); get == nil || get.Mountpoint != tt.want {
+			t.Errorf("(%.1f, %.1f): get %v, want %s", tt.lat, tt.lon, get, tt.want)
+		}
+	}
+	if get := s.Nearest(0, 0, func(*Stream) bool { return false }); get != nil {
+		t.Errorf("none accepted: get %v", get)
+	}
+}
+
+// TestParseSourcetableErrors checks the sourcetables modified.
+func TestParseSourcetableErrors(t *testing.T) {
+	data, err := os.ReadFile(testSourcetableFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		old, new string
+	}{
+		{"no end", "ENDSOURCETABLE\n", ""},
+		{"fields", ";none;B;N;9600;\n", ";none\n"},
+		{"latitude", ";35.71;139.81;", ";35,71;139.81;"},
+		{"bitrate", ";9600;\nSTR;OSKA0", ";96k;\nSTR;OSKA0"},
+		{"port", "caster.example;2101;", "caster.example;port;"},
+	} {
+		s := strings.Replace(string(data), tt.old, tt.new, 1)
+		if s == string(data) {
+			t.Fatalf("%s: not modified", tt.name)
+		}
+		if _, err := ParseSourcetable(strings.NewReader(s)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrFormat)
+		}
+	}
+}
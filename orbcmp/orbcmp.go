@@ -0,0 +1,369 @@
+/*
+Package orbcmp compares the broadcast orbits and clocks with the precise ones,
+e.g., to monitor the quality of the broadcast ephemerides.
+
+The differences are those of the broadcast minus the precise at the epochs of
+the SP3 file, where the orbits are in the radial, along-track, cross-track
+frame of coord.RACRotation and the clocks are in meters. The broadcast clocks
+are compared without the periodic relativistic correction, which is not in
+the precise clocks, and the group delays are not applied. The datum of the
+precise clocks, which is common to all the satellites at each epoch, is not
+removed (see Stats.ClockSTD).
+*/
+package orbcmp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/sp3"
+)
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+// ErrNoPrecise is returned for the satellites or the epochs without the
+// precise position.
+var ErrNoPrecise = errors.New("no precise orbit")
+
+// ErrNoPCO is returned for the satellites without the phase center offset of
+// the frequencies.
+var ErrNoPCO = errors.New("no phase center offset")
+
+// ErrTimeSystem is returned for the SP3 files of the unsupported time
+// systems.
+var ErrTimeSystem = errors.New("unsupported time system")
+
+// SatAntenna provides the phase center offsets of the satellite antennas,
+// e.g., antex.Collection.
+type SatAntenna interface {
+	// SatPCO returns the phase center offset (m) in the satellite body frame
+	// of the ANTEX frequency number freq, e.g., 1 for "G01".
+	SatPCO(id gnss.SatID, t time.Time, freq int) ([3]float64, error)
+}
+
+// Opts is the options of Compare.
+type Opts struct {
+	// Select is the criteria of the usable ephemerides.
+	Select nav.SelectOpts
+
+	// Antenna is the satellite antennas to refer the precise positions of
+	// the center of mass to the antenna phase center of the broadcast
+	// orbits, or nil to compare the center of mass.
+	Antenna SatAntenna
+
+	// Freqs is the ANTEX frequency numbers of the ionosphere-free
+	// combination of the phase center of each satellite system, e.g.,
+	// {1, 2} for 'G'. DefaultFreqs is used if nil.
+	Freqs map[byte][2]int
+}
+
+// DefaultFreqs is the ANTEX frequency numbers of the ionosphere-free
+// combinations of the broadcast clocks of each satellite system, i.e., L1/L2
+// of GPS and GLONASS, E1/E5a of Galileo (F/NAV) and B1I/B3I of BeiDou.
+var DefaultFreqs = map[byte][2]int{
+	'G': {1, 2},
+	'R': {1, 2},
+	'E': {1, 5},
+	'C': {2, 6},
+	'J': {1, 2},
+}
+
+// carriers is the carrier frequencies (Hz) of the ANTEX frequency numbers,
+// where those of GLONASS are of the channel 0.
+var carriers = map[byte]map[int]float64{
+	'G': {1: 1575.42e6, 2: 1227.60e6, 5: 1176.45e6},
+	'J': {1: 1575.42e6, 2: 1227.60e6, 5: 1176.45e6, 6: 1278.75e6},
+	'R': {1: 1602e6, 2: 1246e6},
+	'E': {1: 1575.42e6, 5: 1176.45e6, 6: 1278.75e6, 7: 1207.14e6, 8: 1191.795e6},
+	'C': {1: 1575.42e6, 2: 1561.098e6, 5: 1176.45e6, 6: 1268.52e6, 7: 1207.14e6, 8: 1191.795e6},
+}
+
+// Diff is the difference of the broadcast from the precise of a satellite at
+// an epoch.
+type Diff struct {
+	Epoch time.Time  // epoch in GPS time
+	RAC   [3]float64 // radial, along-track, cross-track (m)
+
+	// Clock is the difference of the clocks (m), valid if HasClock, i.e.,
+	// the precise clock is given.
+	Clock    float64
+	HasClock bool
+}
+
+// Stats is the statistics of the differences.
+type Stats struct {
+	Day time.Time // start of the day in GPS time
+
+	// N is the number of the differences, and NClock is that of those with
+	// the clocks.
+	N, NClock int
+
+	// RMS is the RMS of the radial, along-track and cross-track (m), and
+	// RMS3D is that of the 3D distances.
+	RMS   [3]float64
+	RMS3D float64
+
+	// ClockRMS is the RMS of the clock differences (m), and ClockSTD is the
+	// standard deviation, i.e., without the mean of the day, which absorbs
+	// the constant difference of the datum of the clocks.
+	ClockRMS, ClockSTD float64
+}
+
+// SatReport is the comparison of a satellite.
+type SatReport struct {
+	Sat string
+
+	// Diffs is the differences in the order of the epochs, and Daily is the
+	// statistics of each day.
+	Diffs []Diff
+	Daily []Stats
+
+	// Skipped is the epochs skipped in the order of the epochs.
+	Skipped []Skip
+}
+
+// Skip is an epoch skipped and the reason, which wraps, e.g.,
+// nav.ErrUnhealthy, nav.ErrNoData, ErrNoPrecise or ErrNoPCO.
+type Skip struct {
+	Epoch time.Time // epoch in GPS time
+	Err   error
+}
+
+// Compare returns the reports of the satellites of either of the broadcast
+// ephemerides brdc and the precise orbits prec in the order of the IDs.
+//
+// The differences are computed at the epochs of prec by the ephemerides of
+// brdc.SelectUsable, and the epochs without the usable ephemeris or the
+// precise position are skipped and reported. The satellites only in brdc are
+// reported with ErrNoPrecise. The velocity of the RAC frame is that of the
+// broadcast orbit.
+//
+// The precise positions are referred to the antenna phase center by the
+// PCO of opts.Antenna in the nominal yaw attitude, where the satellites
+// without the PCO are skipped. The difference of the reference frames, e.g.,
+// of WGS84 and IGS, is not considered.
+func Compare(brdc *nav.File, prec *sp3.File, opts Opts) ([]SatReport, error) {
+	epochs := make([]time.Time, len(prec.Epochs))
+	for k, t := range prec.Epochs {
+		g, err := gpsTime(t, prec.Header.TimeSystem)
+		if err != nil {
+			return nil, err
+		}
+		epochs[k] = g
+	}
+	freqs := opts.Freqs
+	if freqs == nil {
+		freqs = DefaultFreqs
+	}
+
+	ids := map[string]bool{}
+	for id := range prec.Records {
+		ids[id] = true
+	}
+	for id := range brdc.Ephs {
+		ids[id] = true
+	}
+	for id := range brdc.Glo {
+		ids[id] = true
+	}
+
+	reports := make([]SatReport, 0, len(ids))
+	for id := range ids {
+		rep := SatReport{Sat: id}
+		recs := prec.Records[id]
+		for k, t := range epochs {
+			if recs == nil {
+				rep.Skipped = append(rep.Skipped, Skip{t, fmt.Errorf("%w: %s", ErrNoPrecise, id)})
+				continue
+			}
+			d, err := compare(brdc, id, t, recs[k], opts, freqs)
+			if err != nil {
+				rep.Skipped = append(rep.Skipped, Skip{t, err})
+				continue
+			}
+			rep.Diffs = append(rep.Diffs, d)
+		}
+		rep.Daily = dailyStats(rep.Diffs)
+		reports = append(reports, rep)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Sat < reports[j].Sat })
+	return reports, nil
+}
+
+// compare returns the difference of the satellite id at the epoch t in GPS
+// time of the precise record rec.
+func compare(brdc *nav.File, id string, t time.Time, rec sp3.Record, opts Opts, freqs map[byte][2]int) (Diff, error) {
+	if !rec.HasPos {
+		return Diff{}, fmt.Errorf("%w: %s at %v", ErrNoPrecise, id, t)
+	}
+	eph, err := brdc.SelectUsable(id, t, opts.Select)
+	if err != nil {
+		return Diff{}, err
+	}
+	s := eph.State(t)
+
+	pos := rec.Pos
+	if opts.Antenna != nil {
+		pco, err := ifPCO(opts.Antenna, id, t, freqs)
+		if err != nil {
+			return Diff{}, err
+		}
+		ex, ey, ez, err := attitude(pos, coord.SunPositionECEF(t))
+		if err != nil {
+			return Diff{}, fmt.Errorf("%s at %v: %w", id, t, err)
+		}
+		for k := range 3 {
+			pos[k] += pco[0]*ex[k] + pco[1]*ey[k] + pco[2]*ez[k]
+		}
+	}
+
+	rac, err := coord.ECEFToRAC(s.Pos, s.Vel, [3]float64{s.Pos[0] - pos[0], s.Pos[1] - pos[1], s.Pos[2] - pos[2]})
+	if err != nil {
+		return Diff{}, fmt.Errorf("%s at %v: %w", id, t, err)
+	}
+	d := Diff{Epoch: t, RAC: rac}
+	if rec.HasClock {
+		d.Clock = (s.Clock - s.Relativity - rec.Clock) * lightVelocity
+		d.HasClock = true
+	}
+	return d, nil
+}
+
+// gpsTime returns the epoch in GPS time of the epoch t of the SP3 file in the
+// time system ts.
+func gpsTime(t time.Time, ts string) (time.Time, error) {
+	switch ts {
+	case "", "GPS", "GAL", "QZS":
+		return t, nil
+	case "BDT":
+		return gnsstime.NewBDT(t).GPST().Time(), nil
+	case "UTC":
+		return gnsstime.ToGPST(t).Time(), nil
+	}
+	return t, fmt.Errorf("%w: %s", ErrTimeSystem, ts)
+}
+
+// ifPCO returns the PCO (m) of the ionosphere-free combination of the
+// frequencies of the satellite system.
+func ifPCO(ant SatAntenna, id string, t time.Time, freqs map[byte][2]int) (pco [3]float64, err error) {
+	fs, ok := freqs[id[0]]
+	if !ok {
+		return pco, fmt.Errorf("%w: no frequencies of the system: %s", ErrNoPCO, id)
+	}
+	f1, ok1 := carriers[id[0]][fs[0]]
+	f2, ok2 := carriers[id[0]][fs[1]]
+	if !ok1 || !ok2 {
+		return pco, fmt.Errorf("%w: unknown frequencies %v: %s", ErrNoPCO, fs, id)
+	}
+	sat, err := gnss.ParseSatID(id)
+	if err != nil {
+		return pco, fmt.Errorf("%w: %w", ErrNoPCO, err)
+	}
+	p1, err := ant.SatPCO(sat, t, fs[0])
+	if err != nil {
+		return pco, fmt.Errorf("%w: %w", ErrNoPCO, err)
+	}
+	p2, err := ant.SatPCO(sat, t, fs[1])
+	if err != nil {
+		return pco, fmt.Errorf("%w: %w", ErrNoPCO, err)
+	}
+
+	c1, c2 := f1*f1/(f1*f1-f2*f2), -f2*f2/(f1*f1-f2*f2)
+	for k := range 3 {
+		pco[k] = c1*p1[k] + c2*p2[k]
+	}
+	return pco, nil
+}
+
+// attitude returns the unit vectors of the satellite body frame in ECEF of
+// the nominal yaw attitude, i.e., the z-axis to the geocenter, the y-axis
+// normal to the plane of the Sun and the satellite, and the x-axis completing
+// the right-handed frame.
+func attitude(sat, sun [3]float64) (ex, ey, ez [3]float64, err error) {
+	r := math.Sqrt(dot(sat, sat))
+	es := [3]float64{sun[0] - sat[0], sun[1] - sat[1], sun[2] - sat[2]}
+	rs := math.Sqrt(dot(es, es))
+	if r == 0 || rs == 0 {
+		return ex, ey, ez, errors.New("attitude undefined")
+	}
+	for k := range 3 {
+		ez[k] = -sat[k] / r
+		es[k] /= rs
+	}
+	ey = cross(ez, es)
+	ny := math.Sqrt(dot(ey, ey))
+	if ny == 0 {
+		return ex, ey, ez, errors.New("attitude undefined for the Sun position")
+	}
+	for k := range 3 {
+		ey[k] /= ny
+	}
+	return cross(ey, ez), ey, ez, nil
+}
+
+// dailyStats returns the statistics of the differences of each day in GPS
+// time in the order of the days.
+func dailyStats(diffs []Diff) []Stats {
+	var stats []Stats
+	for i := 0; i < len(diffs); {
+		day := diffs[i].Epoch.Truncate(24 * time.Hour)
+		j := i
+		for j < len(diffs) && diffs[j].Epoch.Truncate(24*time.Hour).Equal(day) {
+			j++
+		}
+		s := stats1(diffs[i:j])
+		s.Day = day
+		stats = append(stats, s)
+		i = j
+	}
+	return stats
+}
+
+// stats1 returns the statistics of the differences.
+func stats1(diffs []Diff) (s Stats) {
+	var sum, sum2 float64
+	for _, d := range diffs {
+		s.N++
+		for k := range 3 {
+			s.RMS[k] += d.RAC[k] * d.RAC[k]
+		}
+		if d.HasClock {
+			s.NClock++
+			sum += d.Clock
+			sum2 += d.Clock * d.Clock
+		}
+	}
+	if s.N > 0 {
+		for k := range 3 {
+			s.RMS3D += s.RMS[k]
+			s.RMS[k] = math.Sqrt(s.RMS[k] / float64(s.N))
+		}
+		s.RMS3D = math.Sqrt(s.RMS3D / float64(s.N))
+	}
+	if n := float64(s.NClock); n > 0 {
+		mean := sum / n
+		s.ClockRMS = math.Sqrt(sum2 / n)
+		s.ClockSTD = math.Sqrt(math.Max(sum2/n-mean*mean, 0))
+	}
+	return s
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
@@ -0,0 +1,251 @@
+package orbcmp
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/sp3"
+)
+
+// testNavFile is the broadcast ephemerides of the fixtures of nav.
+const testNavFile = "../nav/testdata/brdc1960_excerpt.rnx"
+
+// readFiles returns the broadcast ephemerides and the precise orbit of their
+// positions and clocks at 15 minutes from 22:00 to 04:00, so that the
+// differences are zero within the fit intervals. The ephemerides of the
+// nearest TOE are used out of them.
+func readFiles(t *testing.T) (*nav.File, *sp3.File) {
+	t.Helper()
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("nav.ReadFile: %v", err)
+	}
+
+	t0 := time.Date(2024, 7, 13, 22, 0, 0, 0, time.UTC)
+	prec := &sp3.File{
+		Header:  sp3.Header{Start: t0, NumEpochs: 25, Interval: 15 * time.Minute, TimeSystem: "GPS"},
+		Records: map[string][]sp3.Record{},
+	}
+	for id := range brdc.Ephs {
+		prec.Header.Sats = append(prec.Header.Sats, id)
+	}
+	slices.Sort(prec.Header.Sats)
+	for k := range prec.Header.NumEpochs {
+		ep := t0.Add(time.Duration(k) * prec.Header.Interval)
+		prec.Epochs = append(prec.Epochs, ep)
+		for _, id := range prec.Header.Sats {
+			eph, err := brdc.SelectUsable(id, ep, nav.SelectOpts{})
+			if err != nil {
+				eph = nearestEph(brdc.Ephs[id], ep)
+			}
+			// the precise clocks exclude the relativistic correction
+			s := nav.SatellitePosition(eph, ep)
+			prec.Records[id] = append(prec.Records[id], sp3.Record{Pos: s.Pos, Clock: s.Clock - s.Relativity, HasPos: true, HasClock: true})
+		}
+	}
+	return brdc, prec
+}
+
+// nearestEph returns the ephemeris of the nearest TOE to t.
+func nearestEph(ephs []nav.Ephemeris, t time.Time) *nav.Ephemeris {
+	var eph *nav.Ephemeris
+	for i := range ephs {
+		if eph == nil || absDuration(ephs[i].TOE().Sub(t)) < absDuration(eph.TOE().Sub(t)) {
+			eph = &ephs[i]
+		}
+	}
+	return eph
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// skippedBy returns the number of the epochs skipped by the error.
+func skippedBy(r SatReport, target error) int {
+	var n int
+	for _, s := range r.Skipped {
+		if errors.Is(s.Err, target) {
+			n++
+		}
+	}
+	return n
+}
+
+// report returns the report of the satellite id.
+func report(t *testing.T, reps []SatReport, id string) SatReport {
+	t.Helper()
+	for _, r := range reps {
+		if r.Sat == id {
+			return r
+		}
+	}
+	t.Fatalf("%s: not reported", id)
+	return SatReport{}
+}
+
+// TestCompare checks the differences of the fixtures and the reports of the
+// skipped epochs and satellites.
+func TestCompare(t *testing.T) {
+	brdc, prec := readFiles(t)
+
+	// the second ephemeris of G14 unhealthy, G19 without the precise orbit
+	// and G02 without the precise clock at 00:00
+	brdc.Ephs["G14"][1].Health = 1
+	delete(prec.Records, "G19")
+	k := prec.EpochIndex(time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC))
+	prec.Records["G02"][k].HasClock = false
+
+	reps, err := Compare(brdc, prec, Opts{})
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	for k := 1; k < len(reps); k++ {
+		if reps[k-1].Sat >= reps[k].Sat {
+			t.Errorf("not in the order: %s, %s", reps[k-1].Sat, reps[k].Sat)
+		}
+	}
+
+	for _, r := range reps {
+		for _, d := range r.Diffs {
+			if math.Abs(d.RAC[0]) > 0.01 || math.Abs(d.RAC[1]) > 0.01 || math.Abs(d.RAC[2]) > 0.01 {
+				t.Errorf("%s %v: RAC=%v", r.Sat, d.Epoch, d.RAC)
+			}
+			if d.HasClock && math.Abs(d.Clock) > 0.01 {
+				t.Errorf("%s %v: clock=%.4f m", r.Sat, d.Epoch, d.Clock)
+			}
+		}
+		for _, s := range r.Daily {
+			if s.RMS3D > 0.01 || s.ClockRMS > 0.01 || s.ClockSTD > s.ClockRMS {
+				t.Errorf("%s: stats=%+v", r.Sat, s)
+			}
+		}
+	}
+
+	// two days of the epochs from 22:00 to 04:00
+	g02 := report(t, reps, "G02")
+	if len(g02.Daily) != 2 || g02.Daily[0].Day != time.Date(2024, 7, 13, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("G02: daily=%+v", g02.Daily)
+	}
+	if s := g02.Daily[1]; s.N != s.NClock+1 {
+		t.Errorf("G02: N=%d, NClock=%d", s.N, s.NClock)
+	}
+	if n := g02.Daily[0].N + g02.Daily[1].N + len(g02.Skipped); n != len(prec.Epochs) {
+		t.Errorf("G02: %d epochs, want %d", n, len(prec.Epochs))
+	}
+	if n := skippedBy(g02, nav.ErrOutOfFit); n == 0 || n != len(g02.Skipped) {
+		t.Errorf("G02: skipped=%+v", g02.Skipped)
+	}
+
+	for _, tt := range []struct {
+		id  string
+		err error
+	}{
+		{"G19", ErrNoPrecise},
+		{"R05", ErrNoPrecise},
+	} {
+		r := report(t, reps, tt.id)
+		if len(r.Diffs) != 0 || skippedBy(r, tt.err) != len(prec.Epochs) {
+			t.Errorf("%s: %+v", tt.id, r)
+		}
+	}
+	if r := report(t, reps, "G14"); skippedBy(r, nav.ErrUnhealthy) == 0 {
+		t.Errorf("G14: skipped=%+v", r.Skipped)
+	}
+}
+
+// TestCompareOffset checks the signs of the RAC components and the clock by
+// the offsets of the precise orbit.
+func TestCompareOffset(t *testing.T) {
+	brdc, prec := readFiles(t)
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	k := prec.EpochIndex(t0)
+
+	// the precise position 1 m lower and 1 ns later
+	s, err := brdc.SatellitePosition("G02", t0)
+	if err != nil {
+		t.Fatalf("SatellitePosition: %v", err)
+	}
+	rec := &prec.Records["G02"][k]
+	r := math.Sqrt(dot(s.Pos, s.Pos))
+	for c := range 3 {
+		rec.Pos[c] -= s.Pos[c] / r
+	}
+	rec.Clock += 1e-9
+
+	reps, err := Compare(brdc, prec, Opts{})
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	for _, d := range report(t, reps, "G02").Diffs {
+		if !d.Epoch.Equal(t0) {
+			continue
+		}
+		if math.Abs(d.RAC[0]-1) > 0.01 || math.Abs(d.RAC[1]) > 0.01 || math.Abs(d.RAC[2]) > 0.01 {
+			t.Errorf("RAC: get %v, want [1 0 0]", d.RAC)
+		}
+		if math.Abs(d.Clock+0.2998) > 0.01 {
+			t.Errorf("clock: get %.4f m, want -0.2998", d.Clock)
+		}
+		return
+	}
+	t.Errorf("G02: no difference at %v", t0)
+}
+
+// testSatAntenna is a SatAntenna of the z offsets (m) of the frequencies of
+// the satellites in the map.
+type testSatAntenna map[gnss.SatID][2]float64
+
+func (a testSatAntenna) SatPCO(id gnss.SatID, t time.Time, freq int) ([3]float64, error) {
+	z, ok := a[id]
+	if !ok {
+		return [3]float64{}, errors.New("not found")
+	}
+	if freq == 1 {
+		return [3]float64{0, 0, z[0]}, nil
+	}
+	return [3]float64{0, 0, z[1]}, nil
+}
+
+// TestCompareAntenna checks the ionosphere-free PCO along the radial
+// direction and the satellites without the PCO.
+func TestCompareAntenna(t *testing.T) {
+	brdc, prec := readFiles(t)
+
+	// the PCO of the ionosphere-free combination of 1 m and 2 m of L1 and
+	// L2 is 2.5457*1 - 1.5457*2 = -0.5457 m, i.e., 0.5457 m higher
+	ant := testSatAntenna{{Sys: gnss.GPS, PRN: 2}: {1, 2}}
+	reps, err := Compare(brdc, prec, Opts{Antenna: ant})
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	g02 := report(t, reps, "G02")
+	if len(g02.Diffs) == 0 {
+		t.Fatalf("G02: no differences: %+v", g02.Skipped)
+	}
+	for _, d := range g02.Diffs {
+		if math.Abs(d.RAC[0]+0.5457) > 0.01 || math.Abs(d.RAC[1]) > 0.01 || math.Abs(d.RAC[2]) > 0.01 {
+			t.Errorf("G02 %v: RAC=%v", d.Epoch, d.RAC)
+		}
+	}
+	if r := report(t, reps, "G03"); len(r.Diffs) != 0 || skippedBy(r, ErrNoPCO) != len(g02.Diffs) {
+		t.Errorf("G03: %+v", r)
+	}
+}
+
+// TestCompareTimeSystem checks the error of the unsupported time system.
+func TestCompareTimeSystem(t *testing.T) {
+	brdc, prec := readFiles(t)
+	prec.Header.TimeSystem = "TAI"
+	if _, err := Compare(brdc, prec, Opts{}); !errors.Is(err, ErrTimeSystem) {
+		t.Errorf("get err=%v, want %v", err, ErrTimeSystem)
+	}
+}
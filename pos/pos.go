@@ -0,0 +1,51 @@
+/*
+Package pos writes the solutions in the solution format of RTKLIB (.pos),
+e.g., to be plotted by rtkplot or read by the scripts of the format.
+
+The epochs are in GPS time as the solutions, and the positions are on
+WGS84 with the ellipsoidal heights.
+*/
+package pos
+
+// Quality is the solution quality flag Q of RTKLIB.
+type Quality int
+
+// The qualities of RTKLIB.
+const (
+	QFix    Quality = 1 // fixed ambiguities
+	QFloat  Quality = 2 // float ambiguities
+	QSBAS   Quality = 3 // SBAS
+	QDGPS   Quality = 4 // DGPS
+	QSingle Quality = 5 // single point positioning
+	QPPP    Quality = 6 // precise point positioning
+)
+
+// TimeFormat is the format of the epochs.
+type TimeFormat int
+
+// The formats of the epochs.
+const (
+	Calendar TimeFormat = iota // yyyy/mm/dd hh:mm:ss.sss
+	WeekTOW                    // GPS week and the time of week (s)
+)
+
+// Options is the options of Encode.
+type Options struct {
+	// Program is the program of the header, or "github.com/satoshi-pes/gnss"
+	// if empty, and Inputs is the input files of the header.
+	Program string
+	Inputs  []string
+
+	// Mode is the positioning mode of the header, e.g., "Single" or
+	// "PPP Kinematic" of RTKLIB, or "Single" if empty.
+	Mode string
+
+	// Quality is the quality flag of the solutions, or QSingle if zero.
+	Quality Quality
+
+	TimeFormat TimeFormat
+
+	// ECEF writes the positions as x/y/z of ECEF rather than
+	// latitude/longitude/height.
+	ECEF bool
+}
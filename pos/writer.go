@@ -0,0 +1,156 @@
+package pos
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// defaultProgram is the program of the header if not given.
+const defaultProgram = "github.com/satoshi-pes/gnss"
+
+// WriteFile writes the solutions to the file of the name by Encode.
+func WriteFile(name string, sols []bancroft.Solution, opts Options) error {
+	fp, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := Encode(fp, sols, opts); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// Encode writes the solutions to w in the solution format of RTKLIB with
+// the header of the program, the inputs, the first and the last epochs and
+// the positioning mode, as the output of rtkpost with the options output.
+// The solutions of Err are skipped as those without the solutions of
+// RTKLIB, and the epochs are rounded to 1 ms.
+//
+// The standard deviations and the correlations (the signed square roots of
+// the covariances) are those of the position of Solution.Cov in ENU or in
+// ECEF, and are zero for the solutions without Cov, e.g., of the snapshot
+// solvers. The age and the ratio are zero.
+func Encode(w io.Writer, sols []bancroft.Solution, opts Options) error {
+	var first, last time.Time
+	for _, s := range sols {
+		if s.Err != nil {
+			continue
+		}
+		if first.IsZero() {
+			first = s.Epoch
+		}
+		last = s.Epoch
+	}
+	if first.IsZero() {
+		return fmt.Errorf("no solution")
+	}
+	if opts.Program == "" {
+		opts.Program = defaultProgram
+	}
+	if opts.Mode == "" {
+		opts.Mode = "Single"
+	}
+	if opts.Quality == 0 {
+		opts.Quality = QSingle
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%% program   : %s\n", opts.Program)
+	for _, in := range opts.Inputs {
+		fmt.Fprintf(bw, "%% inp file  : %s\n", in)
+	}
+	for _, e := range []struct {
+		label string
+		t     time.Time
+	}{{"obs start", first}, {"obs end  ", last}} {
+		t := e.t.Round(100 * time.Millisecond)
+		wk, tow := gnsstime.NewGPST(t).Week()
+		fmt.Fprintf(bw, "%% %s : %s GPST (week%04d %8.1fs)\n", e.label, t.Format("2006/01/02 15:04:05.0"), wk, tow)
+	}
+	fmt.Fprintf(bw, "%% pos mode  : %s\n%%\n", opts.Mode)
+	writeHeader(bw, opts)
+
+	for _, s := range sols {
+		if s.Err != nil {
+			continue
+		}
+		writeSolution(bw, s, opts)
+	}
+	return bw.Flush()
+}
+
+// writeHeader writes the lines of the legend and of the columns.
+func writeHeader(w io.Writer, opts Options) {
+	width := 20
+	if opts.TimeFormat == WeekTOW {
+		width = 12
+	}
+	if opts.ECEF {
+		fmt.Fprintf(w, "%% (x/y/z-ecef=WGS84,Q=1:fix,2:float,3:sbas,4:dgps,5:single,6:ppp,ns=# of satellites)\n")
+		fmt.Fprintf(w, "%%  %-*s %14s %14s %14s %3s %3s %8s %8s %8s %8s %8s %8s %6s %6s\n", width, "GPST",
+			"x-ecef(m)", "y-ecef(m)", "z-ecef(m)", "Q", "ns",
+			"sdx(m)", "sdy(m)", "sdz(m)", "sdxy(m)", "sdyz(m)", "sdzx(m)", "age(s)", "ratio")
+		return
+	}
+	fmt.Fprintf(w, "%% (lat/lon/height=WGS84/ellipsoidal,Q=1:fix,2:float,3:sbas,4:dgps,5:single,6:ppp,ns=# of satellites)\n")
+	fmt.Fprintf(w, "%%  %-*s %14s %14s %10s %3s %3s %8s %8s %8s %8s %8s %8s %6s %6s\n", width, "GPST",
+		"latitude(deg)", "longitude(deg)", "height(m)", "Q", "ns",
+		"sdn(m)", "sde(m)", "sdu(m)", "sdne(m)", "sdeu(m)", "sdun(m)", "age(s)", "ratio")
+}
+
+// writeSolution writes the line of the solution.
+func writeSolution(w io.Writer, s bancroft.Solution, opts Options) {
+	t := s.Epoch.Round(time.Millisecond)
+	if opts.TimeFormat == WeekTOW {
+		wk, tow := gnsstime.NewGPST(t).Week()
+		fmt.Fprintf(w, "%4d %10.3f", wk, tow)
+	} else {
+		fmt.Fprint(w, t.Format("2006/01/02 15:04:05.000"))
+	}
+
+	var C [3][3]float64
+	if s.Cov != nil {
+		for i := range 3 {
+			for j := range 3 {
+				C[i][j] = s.Cov.At(i, j)
+			}
+		}
+	}
+	// the standard deviations and the correlations of x, y, z, xy, yz, zx,
+	// or of n, e, u, ne, eu, un as RTKLIB
+	sd := [6]float64{
+		math.Sqrt(C[0][0]), math.Sqrt(C[1][1]), math.Sqrt(C[2][2]),
+		sqvar(C[0][1]), sqvar(C[1][2]), sqvar(C[2][0]),
+	}
+	if opts.ECEF {
+		p := s.Position
+		fmt.Fprintf(w, " %14.4f %14.4f %14.4f", p[0], p[1], p[2])
+	} else {
+		lat, lon, h := s.LatLonHeight()
+		Q := coord.RotateCov(coord.ENURotation(lat, lon), C)
+		sd = [6]float64{
+			math.Sqrt(Q[1][1]), math.Sqrt(Q[0][0]), math.Sqrt(Q[2][2]),
+			sqvar(Q[1][0]), sqvar(Q[0][2]), sqvar(Q[2][1]),
+		}
+		fmt.Fprintf(w, " %14.9f %14.9f %10.4f", coord.Rad2Deg(lat), coord.Rad2Deg(lon), h)
+	}
+	fmt.Fprintf(w, " %3d %3d %8.4f %8.4f %8.4f %8.4f %8.4f %8.4f %6.2f %6.1f\n",
+		opts.Quality, s.NumSats, sd[0], sd[1], sd[2], sd[3], sd[4], sd[5], 0., 0.)
+}
+
+// sqvar returns the signed square root of the covariance.
+func sqvar(c float64) float64 {
+	if c < 0 {
+		return -math.Sqrt(-c)
+	}
+	return math.Sqrt(c)
+}
@@ -0,0 +1,258 @@
+package pos
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+	"gonum.org/v1/gonum/mat"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// testSolutions returns the solutions at 35.71 deg, 139.81 deg and 50 m every
+// 30 s from 2024-07-13 23:59:30 GPST with the covariance of the second, where
+// the third is failed.
+func testSolutions() []bancroft.Solution {
+	lat, lon := coord.Deg2Rad(35.71), coord.Deg2Rad(139.81)
+	x, y, z := coord.LLHToXYZ(lat, lon, 50)
+	t0 := time.Date(2024, 7, 13, 23, 59, 30, 0, time.UTC)
+
+	// the covariance of sigma (n, e, u) = (1, 2, 3) m with the correlation of
+	// e and u, and of the velocity and the clock
+	R := coord.ENURotation(lat, lon)
+	C := coord.RotateCovT(R, [3][3]float64{{4, 0, 1.5}, {0, 1, 0}, {1.5, 0, 9}})
+	cov := mat.NewSymDense(8, nil)
+	for i := range 3 {
+		for j := i; j < 3; j++ {
+			cov.SetSym(i, j, C[i][j])
+		}
+	}
+	for i := 3; i < 8; i++ {
+		cov.SetSym(i, i, 0.01)
+	}
+
+	var sols []bancroft.Solution
+	for k := range 4 {
+		s := bancroft.Solution{
+			Epoch:    t0.Add(time.Duration(k)*30*time.Second + 400*time.Microsecond),
+			Position: coord.ENUToECEF([3]float64{x, y, z}, [3]float64{float64(k), -float64(k), 0.1 * float64(k)}),
+			NumSats:  7 + k,
+		}
+		switch k {
+		case 1:
+			s.Cov = cov
+		case 2:
+			s.Err = errors.New("too few satellites")
+		}
+		sols = append(sols, s)
+	}
+	return sols
+}
+
+// TestEncode checks the files against the regression files of the writer
+// (run with -update to regenerate), and the columns of the lines.
+// TestEncodeRTKPOST checks the layout against the outputs of rtkpost.
+func TestEncode(t *testing.T) {
+	for _, tt := range []struct {
+		golden  string
+		opts    Options
+		columns int
+	}{
+		{"testdata/llh.pos", Options{Inputs: []string{"0227196a.24o", "brdc1960.24n"}}, 15},
+		{"testdata/ecef_tow.pos", Options{Mode: "PPP Kinematic", Quality: QPPP, TimeFormat: WeekTOW, ECEF: true}, 15},
+	} {
+		var b bytes.Buffer
+		if err := Encode(&b, testSolutions(), tt.opts); err != nil {
+			t.Fatalf("%s: Encode: %v", tt.golden, err)
+		}
+		if *update {
+			if err := os.WriteFile(tt.golden, b.Bytes(), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+		}
+		want, err := os.ReadFile(tt.golden)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if get := b.String(); get != string(want) {
+			t.Errorf("%s: get\n%s\nwant\n%s", tt.golden, get, want)
+		}
+
+		var n int
+		for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+			if strings.HasPrefix(l, "%") {
+				continue
+			}
+			n++
+			if f := strings.Fields(l); len(f) != tt.columns {
+				t.Errorf("%s: number of columns: get %d, want %d: %s", tt.golden, len(f), tt.columns, l)
+			}
+		}
+		if n != 3 {
+			t.Errorf("%s: number of solutions: get %d, want 3", tt.golden, n)
+		}
+	}
+}
+
+// testRTKPOSTPattern is the outputs of rtkpost of RTKLIB 2.4.3, which are not
+// distributed with the module, in the latitude/longitude of degrees or in
+// x/y/z of ECEF, and the times of the calendar or of the week and the TOW.
+const testRTKPOSTPattern = "testdata/rtkpost_*.pos"
+
+// TestEncodeRTKPOST checks the lines of the solutions of the outputs of
+// rtkpost are written from the solutions read from them, with the lines of
+// the legend and the columns, or skips the test if there are no outputs.
+func TestEncodeRTKPOST(t *testing.T) {
+	names, _ := filepath.Glob(testRTKPOSTPattern)
+	if len(names) == 0 {
+		t.Skipf("no output of rtkpost in testdata: %s", testRTKPOSTPattern)
+	}
+	for _, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var want []string
+		for _, l := range strings.Split(strings.TrimRight(string(b), "\r\n"), "\n") {
+			if l = strings.TrimRight(l, "\r"); !strings.HasPrefix(l, "%") || strings.HasPrefix(l, "% (") || strings.HasPrefix(l, "%  GPST") {
+				want = append(want, l)
+			}
+		}
+		if len(want) < 3 {
+			t.Fatalf("%s: no solution", name)
+		}
+		opts := Options{ECEF: strings.Contains(want[1], "x-ecef"), TimeFormat: WeekTOW}
+		if strings.Contains(want[2], "/") {
+			opts.TimeFormat = Calendar
+		}
+
+		var sols []bancroft.Solution
+		for _, l := range want[2:] {
+			s, q, err := parseRTKPOST(l, opts)
+			if err != nil {
+				t.Fatalf("%s: %v: %s", name, err, l)
+			}
+			opts.Quality = q
+			sols = append(sols, s)
+		}
+
+		var buf bytes.Buffer
+		if err := Encode(&buf, sols, opts); err != nil {
+			t.Fatalf("%s: Encode: %v", name, err)
+		}
+		get := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		get = get[len(get)-len(want):]
+		for i := range want {
+			if get[i] != want[i] {
+				t.Errorf("%s: line %d:\nget  '%s'\nwant '%s'", name, i+1, get[i], want[i])
+			}
+		}
+	}
+}
+
+// parseRTKPOST returns the solution and the quality of the line of the
+// solution of rtkpost, where the standard deviations are converted to the
+// covariance of the position.
+func parseRTKPOST(l string, opts Options) (bancroft.Solution, Quality, error) {
+	var s bancroft.Solution
+	f := strings.Fields(l)
+	if len(f) != 15 {
+		return s, 0, errors.New("number of columns")
+	}
+	// the position, Q, ns, the standard deviations, the age and the ratio
+	var v [13]float64
+	for i := range v {
+		var err error
+		if v[i], err = strconv.ParseFloat(f[i+2], 64); err != nil {
+			return s, 0, err
+		}
+	}
+	if opts.TimeFormat == WeekTOW {
+		wk, err := strconv.Atoi(f[0])
+		if err != nil {
+			return s, 0, err
+		}
+		tow, err := strconv.ParseFloat(f[1], 64)
+		if err != nil {
+			return s, 0, err
+		}
+		s.Epoch = gnsstime.GPSTFromWeek(wk, tow).Time()
+	} else {
+		t, err := time.Parse("2006/01/02 15:04:05.000", f[0]+" "+f[1])
+		if err != nil {
+			return s, 0, err
+		}
+		s.Epoch = t
+	}
+
+	sq := func(x float64) float64 { return x * math.Abs(x) }
+	C := [3][3]float64{
+		{sq(v[5]), sq(v[8]), sq(v[10])},
+		{sq(v[8]), sq(v[6]), sq(v[9])},
+		{sq(v[10]), sq(v[9]), sq(v[7])},
+	}
+	if opts.ECEF {
+		s.Position = [3]float64{v[0], v[1], v[2]}
+	} else {
+		lat, lon := coord.Deg2Rad(v[0]), coord.Deg2Rad(v[1])
+		x, y, z := coord.LLHToXYZ(lat, lon, v[2])
+		s.Position = [3]float64{x, y, z}
+		// n, e, u, ne, eu, un to the covariance of e, n, u in ECEF
+		Q := [3][3]float64{
+			{sq(v[6]), sq(v[8]), sq(v[9])},
+			{sq(v[8]), sq(v[5]), sq(v[10])},
+			{sq(v[9]), sq(v[10]), sq(v[7])},
+		}
+		C = coord.RotateCovT(coord.ENURotation(lat, lon), Q)
+	}
+	s.Cov = mat.NewSymDense(3, []float64{
+		C[0][0], C[0][1], C[0][2],
+		C[1][0], C[1][1], C[1][2],
+		C[2][0], C[2][1], C[2][2],
+	})
+	s.NumSats = int(v[4])
+	return s, Quality(v[3]), nil
+}
+
+// TestEncodeCov checks the standard deviations of the covariance in ENU.
+func TestEncodeCov(t *testing.T) {
+	var b bytes.Buffer
+	if err := Encode(&b, testSolutions()[1:2], Options{}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	f := strings.Fields(lines[len(lines)-1])
+	// the variances of n, e, u and the covariances of ne, eu, un, which are
+	// compared as the squares of the columns of the signed square roots
+	for k, want := range []float64{1, 4, 9, 0, 1.5, 0} {
+		v, err := strconv.ParseFloat(f[7+k], 64)
+		if err != nil || math.Abs(v*math.Abs(v)-want) > 1e-3 {
+			t.Errorf("column %d: get %s, want %.4f", 7+k, f[7+k], want)
+		}
+	}
+}
+
+// TestWriteFile checks the file and the solutions without any valid one.
+func TestWriteFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.pos")
+	if err := WriteFile(name, testSolutions(), Options{}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if data, err := os.ReadFile(name); err != nil || !strings.HasPrefix(string(data), "% program   : github.com/satoshi-pes/gnss\n") {
+		t.Errorf("file: %v %.40q", err, data)
+	}
+	if err := WriteFile(name, testSolutions()[2:3], Options{}); err == nil {
+		t.Errorf("no error of no solution")
+	}
+}
@@ -0,0 +1,189 @@
+package rinex
+
+import (
+	"slices"
+	"strings"
+)
+
+// CodeMap maps the observation codes of RINEX 2 (e.g., "P2") to those of
+// RINEX 3 (e.g., "C2W") keyed by the satellite systems. The entries override
+// those of DefaultCodeMap, i.e., a CodeMap may set only the codes of the
+// policy to be changed, e.g., CodeMap{'G': {"C1": "C1W"}} for the receivers
+// reporting P1 as C1, and the empty code removes the mapping.
+type CodeMap map[byte]map[string]string
+
+// DefaultCodeMap is the mapping of the codes of RINEX 2.11 and the BeiDou
+// and QZSS codes of the later drafts, following the usage of the IGS.
+//
+// The RINEX 2 codes do not have the attributes, and the mapping is
+// ambiguous in the following cases:
+//
+//   - C1 of GPS is C1C, while some receivers report P1 as C1 (C1W).
+//   - P1 and P2 of GPS are C1W and C2W, i.e., the semi-codeless tracking of
+//     P(Y), rather than C1P and C2P of the unencrypted P code.
+//   - L1 of GPS is L1C, while it may be tracked with P(Y) (L1W), and L2 is
+//     L2W, while it may be tracked with L2C (L2L, L2S or L2X).
+//   - C2 of GPS is C2X of L2C, while it is C2S or C2L of a single channel.
+//   - L2 of GLONASS is L2P, while it may be tracked with C/A (L2C).
+//   - The codes of the band 5 of GPS and QZSS and the bands of Galileo are
+//     those of the combined channels (X).
+//
+// Conversely, the RINEX 3 codes of the same band and type are converted to
+// the same RINEX 2 code (see Code2), e.g., C1C and C1X of GPS to C1.
+var DefaultCodeMap = CodeMap{
+	'G': {
+		"C1": "C1C", "P1": "C1W", "L1": "L1C", "D1": "D1C", "S1": "S1C",
+		"C2": "C2X", "P2": "C2W", "L2": "L2W", "D2": "D2W", "S2": "S2W",
+		"C5": "C5X", "L5": "L5X", "D5": "D5X", "S5": "S5X",
+	},
+	'R': {
+		"C1": "C1C", "P1": "C1P", "L1": "L1C", "D1": "D1C", "S1": "S1C",
+		"C2": "C2C", "P2": "C2P", "L2": "L2P", "D2": "D2P", "S2": "S2P",
+	},
+	'E': {
+		"C1": "C1X", "L1": "L1X", "D1": "D1X", "S1": "S1X",
+		"C5": "C5X", "L5": "L5X", "D5": "D5X", "S5": "S5X",
+		"C6": "C6X", "L6": "L6X", "D6": "D6X", "S6": "S6X",
+		"C7": "C7X", "L7": "L7X", "D7": "D7X", "S7": "S7X",
+		"C8": "C8X", "L8": "L8X", "D8": "D8X", "S8": "S8X",
+	},
+	'S': {
+		"C1": "C1C", "L1": "L1C", "D1": "D1C", "S1": "S1C",
+		"C5": "C5I", "L5": "L5I", "D5": "D5I", "S5": "S5I",
+	},
+	'J': {
+		"C1": "C1C", "L1": "L1C", "D1": "D1C", "S1": "S1C",
+		"C2": "C2X", "L2": "L2X", "D2": "D2X", "S2": "S2X",
+		"C5": "C5X", "L5": "L5X", "D5": "D5X", "S5": "S5X",
+	},
+	'C': {
+		"C2": "C2I", "L2": "L2I", "D2": "D2I", "S2": "S2I",
+		"C7": "C7I", "L7": "L7I", "D7": "D7I", "S7": "S7I",
+		"C6": "C6I", "L6": "L6I", "D6": "D6I", "S6": "S6I",
+	},
+}
+
+// Code3 returns the RINEX 3 code of the RINEX 2 code of the satellite
+// system sys, and false if not mapped.
+func (m CodeMap) Code3(sys byte, code string) (string, bool) {
+	if c, ok := m[sys][code]; ok {
+		return c, c != ""
+	}
+	c, ok := DefaultCodeMap[sys][code]
+	return c, ok
+}
+
+// Code2 returns the RINEX 2 code of the RINEX 3 code of the satellite
+// system sys, i.e., the code mapped to it by m, or the type and the band of
+// the code otherwise, where the pseudoranges of P(Y) of GPS and P of GLONASS
+// (the attributes 'P', 'W' and 'Y') are "P" and the others are "C".
+func (m CodeMap) Code2(sys byte, code string) (string, bool) {
+	if len(code) != 3 {
+		return "", false
+	}
+	for _, c := range m.codes2(sys) {
+		if c3, _ := m.Code3(sys, c); c3 == code {
+			return c, true
+		}
+	}
+	if code[0] == 'C' && (sys == 'G' || sys == 'R') && strings.IndexByte("PWY", code[2]) >= 0 {
+		return "P" + code[1:2], true
+	}
+	return code[:2], true
+}
+
+// codes2 returns the RINEX 2 codes of the system of m and DefaultCodeMap in
+// the order of the codes.
+func (m CodeMap) codes2(sys byte) []string {
+	var codes []string
+	for c := range DefaultCodeMap[sys] {
+		codes = append(codes, c)
+	}
+	for c := range m[sys] {
+		if _, ok := DefaultCodeMap[sys][c]; !ok {
+			codes = append(codes, c)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// systems returns the satellite systems of m and DefaultCodeMap in order.
+func (m CodeMap) systems() []byte {
+	var systems []byte
+	for sys := range DefaultCodeMap {
+		systems = append(systems, sys)
+	}
+	for sys := range m {
+		if _, ok := DefaultCodeMap[sys]; !ok {
+			systems = append(systems, sys)
+		}
+	}
+	slices.Sort(systems)
+	return systems
+}
+
+// NormalizeHeader converts the types of RINEX 2 of the header to those of
+// RINEX 3 of the satellite systems of the file, i.e., all the systems of m
+// for the mixed files. The codes not mapped are removed, and the version is
+// not changed.
+func (m CodeMap) NormalizeHeader(h *ObsHeader) {
+	types, ok := h.Types[' ']
+	if !ok {
+		return
+	}
+	systems := []byte{h.SatSystem}
+	switch h.SatSystem {
+	case ' ':
+		systems = []byte{'G'}
+	case 'M':
+		systems = m.systems()
+	}
+
+	delete(h.Types, ' ')
+	for _, sys := range systems {
+		var codes []string
+		for _, c := range types {
+			if c3, ok := m.Code3(sys, c); ok {
+				codes = append(codes, c3)
+			}
+		}
+		if len(codes) > 0 {
+			h.Types[sys] = codes
+		}
+	}
+}
+
+// NormalizeEpoch converts the RINEX 2 codes of the observations of the
+// epoch to those of RINEX 3. The codes not mapped are removed, and the
+// RINEX 3 codes are not changed. If the codes are mapped to the same code,
+// the first of the codes in order is used.
+func (m CodeMap) NormalizeEpoch(e *Epoch) {
+	for id, obs := range e.Obs {
+		var codes []string
+		for c := range obs {
+			if len(c) == 2 {
+				codes = append(codes, c)
+			}
+		}
+		slices.Sort(codes)
+		for _, c := range codes {
+			o := obs[c]
+			delete(obs, c)
+			c3, ok := m.Code3(id[0], c)
+			if _, dup := obs[c3]; !ok || dup {
+				continue
+			}
+			obs[c3] = o
+		}
+	}
+}
+
+// Normalize converts the codes of RINEX 2 of the header and the epochs of
+// the file to those of RINEX 3 by NormalizeHeader and NormalizeEpoch.
+func (m CodeMap) Normalize(f *ObsFile) {
+	m.NormalizeHeader(&f.Header)
+	for _, e := range f.Epochs {
+		m.NormalizeEpoch(e)
+	}
+}
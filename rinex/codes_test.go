@@ -0,0 +1,134 @@
+package rinex
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCodeRoundTrip checks the codes of RINEX 2 converted to RINEX 3 and
+// back, and those of RINEX 3 of the default map converted to RINEX 2 and
+// back.
+func TestCodeRoundTrip(t *testing.T) {
+	var m CodeMap
+	for sys, codes := range DefaultCodeMap {
+		for c2, c3 := range codes {
+			if get, ok := m.Code3(sys, c2); !ok || get != c3 {
+				t.Errorf("%c %s: get %s, want %s", sys, c2, get, c3)
+			}
+			if get, ok := m.Code2(sys, c3); !ok || get != c2 {
+				t.Errorf("%c %s: get %s, want %s", sys, c3, get, c2)
+			}
+		}
+	}
+}
+
+// TestCodeAmbiguities checks the RINEX 3 codes not in the map, which are
+// converted to the RINEX 2 codes of the same band and type, and the policy
+// overriding the defaults.
+func TestCodeAmbiguities(t *testing.T) {
+	policy := CodeMap{'G': {"C1": "C1W", "P1": "C1P", "C5": ""}, 'I': {"C5": "C5A"}}
+	for _, tt := range []struct {
+		m      CodeMap
+		sys    byte
+		c3, c2 string
+	}{
+		{nil, 'G', "C1X", "C1"},
+		{nil, 'G', "C1P", "P1"},
+		{nil, 'G', "L1W", "L1"},
+		{nil, 'G', "C2L", "C2"},
+		{nil, 'G', "C2Y", "P2"},
+		{nil, 'G', "C5Q", "C5"},
+		{nil, 'R', "L2C", "L2"},
+		{nil, 'E', "C1C", "C1"},
+		{nil, 'E', "C1P", "C1"},
+		{nil, 'C', "C2X", "C2"},
+		{policy, 'G', "C1W", "C1"},
+		{policy, 'G', "C1C", "C1"},
+		{policy, 'G', "C1P", "P1"},
+		{policy, 'G', "C2W", "P2"},
+		{policy, 'I', "C5A", "C5"},
+	} {
+		if get, ok := tt.m.Code2(tt.sys, tt.c3); !ok || get != tt.c2 {
+			t.Errorf("%c %s: get %s, want %s", tt.sys, tt.c3, get, tt.c2)
+		}
+	}
+
+	for _, tt := range []struct {
+		sys    byte
+		c2, c3 string
+		ok     bool
+	}{
+		{'G', "C1", "C1W", true},
+		{'G', "P1", "C1P", true},
+		{'G', "P2", "C2W", true},
+		{'G', "C5", "", false},
+		{'I', "C5", "C5A", true},
+		{'R', "C5", "", false},
+		{'X', "C1", "", false},
+	} {
+		if get, ok := policy.Code3(tt.sys, tt.c2); ok != tt.ok || get != tt.c3 {
+			t.Errorf("%c %s: get %s %t, want %s", tt.sys, tt.c2, get, ok, tt.c3)
+		}
+	}
+	if c, ok := policy.Code2('G', "C1"); ok {
+		t.Errorf("code of RINEX 2: %s", c)
+	}
+}
+
+// TestNormalize checks the fixture of RINEX 2 normalized to the codes of
+// RINEX 3, whose pseudoranges of C/A are the same as those of the fixture
+// of RINEX 3.
+func TestNormalize(t *testing.T) {
+	f2, err := ReadObsFile(testObs2File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	f3, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	DefaultCodeMap.Normalize(f2)
+
+	h := f2.Header
+	if _, ok := h.Types[' ']; ok || len(h.Types) != len(DefaultCodeMap) {
+		t.Errorf("types: %v", h.Types)
+	}
+	gps := []string{"C1C", "L1C", "L2W", "C2W", "C2X", "C5X", "L5X", "D1C", "D2W", "S1C", "S2W"}
+	glo := []string{"C1C", "L1C", "L2P", "C2P", "C2C", "D1C", "D2P", "S1C", "S2P"}
+	if !slices.Equal(h.ObsTypes('G'), gps) || !slices.Equal(h.ObsTypes('R'), glo) {
+		t.Errorf("types: %v", h.Types)
+	}
+
+	e2, e3 := f2.Epochs[0], f3.Epochs[0]
+	for _, id := range e2.Sats {
+		obs := e2.Obs[id]
+		for c := range obs {
+			if !slices.Contains(h.ObsTypes(id[0]), c) {
+				t.Errorf("%s: %s not in the types", id, c)
+			}
+		}
+		if id[0] != 'G' {
+			continue
+		}
+		v2, ok2 := e2.Value(id, "C1C")
+		v3, ok3 := e3.Value(id, "C1C")
+		if !ok2 || !ok3 || v2 != v3 {
+			t.Errorf("%s: C1C=%f, %f", id, v2, v3)
+		}
+	}
+
+	// the codes of RINEX 3 are not changed, and the codes mapped to the same
+	// code or not mapped are removed
+	e := &Epoch{Obs: map[string]SatObs{
+		"G01": {"C1": {Value: 1}, "C1C": {Value: 2}, "P1": {Value: 3}, "X1": {Value: 4}},
+		"R01": {"C5": {Value: 5}},
+	}}
+	DefaultCodeMap.NormalizeEpoch(e)
+	if g := e.Obs["G01"]; len(g) != 2 || g["C1C"].Value != 2 || g["C1W"].Value != 3 || len(e.Obs["R01"]) != 0 {
+		t.Errorf("observations: %+v", e.Obs)
+	}
+	CodeMap{'G': {"P1": "C1C"}}.NormalizeEpoch(e)
+	if g := e.Obs["G01"]; len(g) != 2 {
+		t.Errorf("observations normalized twice: %+v", g)
+	}
+}
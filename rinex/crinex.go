@@ -0,0 +1,408 @@
+package rinex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// crinexLabel is the label of the first line of the Hatanaka-compressed
+// (CRINEX) files.
+const crinexLabel = "CRINEX VERS   / TYPE"
+
+// maxArcOrder is the maximum order of the differences of CRINEX.
+const maxArcOrder = 9
+
+// Decompress decompresses the Hatanaka-compressed observation file of CRINEX
+// 1 (RINEX 2) or CRINEX 3 (RINEX 3 and 4) from r, and writes the RINEX text
+// to w, which is the same as that of CRX2RNX.
+//
+// The errors are wrapped with the line number of the compressed file, and
+// the format errors are tested by errors.Is with ErrFormat.
+func Decompress(w io.Writer, r io.Reader) error {
+	_, err := io.Copy(w, NewDecompressReader(r))
+	return err
+}
+
+// NewDecompressReader returns the reader of the RINEX text decompressed from
+// the CRINEX file of r epoch by epoch. See Decompress.
+func NewDecompressReader(r io.Reader) io.Reader {
+	return &crinexDecoder{s: mscanner.NewScanner(r)}
+}
+
+// crinexDecoder holds the state of the decompression.
+type crinexDecoder struct {
+	s   *mscanner.Scanner
+	out bytes.Buffer // decompressed text not read
+	err error        // error to be returned after out
+
+	header bool                  // header is decompressed
+	v3     bool                  // CRINEX 3
+	h      ObsReader             // header of the RINEX file to get the types
+	epoch  string                // epoch line of CRINEX, which is the reference of the next one
+	clock  diffArc               // receiver clock offset
+	sats   map[string]*crinexSat // satellites of the previous epoch
+}
+
+// Read decompresses the header or an epoch into the buffer as needed.
+func (d *crinexDecoder) Read(p []byte) (int, error) {
+	for d.out.Len() == 0 && d.err == nil {
+		d.err = d.step()
+		if d.err != nil && d.err != io.EOF {
+			d.err = fmt.Errorf("line %d: %w", d.s.LineNumber(), d.err)
+		}
+	}
+	if d.out.Len() > 0 {
+		return d.out.Read(p)
+	}
+	return 0, d.err
+}
+
+// step decompresses the header, or the next epoch. io.EOF is returned at
+// the end of the file.
+func (d *crinexDecoder) step() error {
+	if !d.header {
+		d.header = true
+		d.sats = make(map[string]*crinexSat)
+		return d.decodeHeader()
+	}
+
+	l, ok := d.next()
+	if !ok {
+		if err := d.s.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	return d.decodeEpoch(l)
+}
+
+// crinexSat is the state of a satellite in CRINEX.
+type crinexSat struct {
+	arcs  []diffArc
+	flags []byte // LLI and SSI of the observations
+}
+
+// diffArc restores the values from the differences of CRINEX.
+type diffArc struct {
+	valid         bool
+	order, maxOrd int
+	u             [maxArcOrder + 1]int64 // value and the differences
+}
+
+// decode restores the value from the field, which is either of the
+// initialization "n&value" with the order n or the difference.
+func (a *diffArc) decode(s string) error {
+	if len(s) >= 2 && s[1] == '&' {
+		n := int(s[0] - '0')
+		if n < 0 || n > maxArcOrder {
+			return fmt.Errorf("%w: order of the arc: '%s'", ErrFormat, s)
+		}
+		v, err := strconv.ParseInt(s[2:], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: field: %v", ErrFormat, err)
+		}
+		*a = diffArc{valid: true, maxOrd: n}
+		a.u[0] = v
+		return nil
+	}
+	if !a.valid {
+		return fmt.Errorf("%w: arc not initialized: '%s'", ErrFormat, s)
+	}
+
+	d, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: field: %v", ErrFormat, err)
+	}
+	if a.order < a.maxOrd {
+		a.order++
+	}
+	a.u[a.order] = d
+	for k := a.order; k > 0; k-- {
+		a.u[k-1] += a.u[k]
+	}
+	return nil
+}
+
+// next reads the next line without the padding.
+func (d *crinexDecoder) next() (string, bool) {
+	if !d.s.Scan() {
+		return "", false
+	}
+	return strings.TrimRight(d.s.Text(), "\r"), true
+}
+
+// put writes a line of RINEX without the trailing spaces.
+func (d *crinexDecoder) put(l string) {
+	d.out.WriteString(strings.TrimRight(l, " "))
+	d.out.WriteByte('\n')
+}
+
+// decodeHeader reads the two lines of CRINEX, and copies the header of RINEX
+// getting the observation types.
+func (d *crinexDecoder) decodeHeader() error {
+	l, ok := d.next()
+	if !ok || !strings.HasPrefix(fmt.Sprintf("%-80s", l)[60:], crinexLabel) {
+		return fmt.Errorf("%w: first line of crinex: '%s'", ErrFormat, l)
+	}
+	switch v := strings.TrimSpace(l[:20]); v {
+	case "1.0":
+	case "3.0":
+		d.v3 = true
+	default:
+		return fmt.Errorf("%w: unsupported crinex version: %s", ErrFormat, v)
+	}
+	if _, ok := d.next(); !ok {
+		return fmt.Errorf("%w: crinex program line not found", ErrFormat)
+	}
+
+	d.h = ObsReader{v3: d.v3, ntypes: make(map[byte]int)}
+	d.h.Header.Types = make(map[byte][]string)
+	for {
+		l, ok := d.next()
+		if !ok {
+			return fmt.Errorf("%w: no end of header", ErrFormat)
+		}
+		d.put(l)
+
+		l = fmt.Sprintf("%-80s", l)
+		switch label(l) {
+		case "# / TYPES OF OBSERV":
+			if err := d.h.parseTypes2(l); err != nil {
+				return err
+			}
+		case "SYS / # / OBS TYPES":
+			if err := d.h.parseTypes3(l); err != nil {
+				return err
+			}
+		case "END OF HEADER":
+			return d.h.checkHeader()
+		}
+	}
+}
+
+// decodeEpoch restores an epoch from the epoch line l, and the following
+// clock and data lines.
+//
+// The epoch line is initialized with the first character '&' for CRINEX 1
+// or '>' for CRINEX 3, and is otherwise the text difference from the previous
+// one. The satellite list is appended to the epoch line.
+func (d *crinexDecoder) decodeEpoch(l string) error {
+	init := byte('&')
+	if d.v3 {
+		init = '>'
+	}
+	if len(l) > 0 && l[0] == init {
+		if !d.v3 {
+			l = " " + l[1:]
+		}
+	} else {
+		if d.epoch == "" {
+			return fmt.Errorf("%w: epoch not initialized", ErrFormat)
+		}
+		l = repairText(d.epoch, l)
+	}
+
+	// columns of the flag, the number of the satellites and the list
+	cflag, csat := 28, 32
+	if d.v3 {
+		cflag, csat = 31, 41
+	}
+	pl := fmt.Sprintf("%-*s", csat, l)
+	flag, err := field.Atoi(pl[cflag : cflag+1])
+	if err != nil {
+		return fmt.Errorf("%w: epoch flag: '%c'", ErrFormat, pl[cflag])
+	}
+	nsat, err := field.Atoi(pl[cflag+1 : cflag+4])
+	if err != nil {
+		return fmt.Errorf("%w: number of satellites: %v", ErrFormat, err)
+	}
+
+	if flag > 1 && flag != 6 {
+		// the event lines are copied as they are
+		d.put(l)
+		for range nsat {
+			el, ok := d.next()
+			if !ok {
+				return fmt.Errorf("%w: event record not found", ErrFormat)
+			}
+			d.put(el)
+		}
+		return nil
+	}
+	d.epoch = l
+
+	if len(pl) < csat+3*nsat {
+		return fmt.Errorf("%w: satellite list: n=%d, '%s'", ErrFormat, nsat, pl[csat:])
+	}
+	sats := make([]string, nsat)
+	for k := range sats {
+		sats[k] = pl[csat+3*k : csat+3*k+3]
+	}
+
+	// receiver clock offset
+	cl, ok := d.next()
+	if !ok {
+		return fmt.Errorf("%w: clock line not found", ErrFormat)
+	}
+	if cl == "" {
+		d.clock.valid = false
+	} else if err := d.clock.decode(cl); err != nil {
+		return fmt.Errorf("clock: %w", err)
+	}
+	d.putEpoch(pl[:cflag+4], sats)
+
+	prev := d.sats
+	d.sats = make(map[string]*crinexSat, nsat)
+	for _, id := range sats {
+		sys := id[0]
+		if sys == ' ' {
+			sys = 'G'
+		}
+		if !d.v3 {
+			sys = ' '
+		}
+		ntypes := len(d.h.Header.Types[sys])
+		if ntypes == 0 {
+			return fmt.Errorf("%w: no observation type of %s", ErrFormat, id)
+		}
+
+		sat, ok := prev[id]
+		if !ok {
+			sat = &crinexSat{arcs: make([]diffArc, ntypes), flags: []byte(strings.Repeat(" ", 2*ntypes))}
+		}
+		d.sats[id] = sat
+
+		dl, ok := d.next()
+		if !ok {
+			return fmt.Errorf("%w: data of %s not found", ErrFormat, id)
+		}
+		if err := sat.decode(dl); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		d.putData(id, sat)
+	}
+	return nil
+}
+
+// decode restores the values and the flags from the data line, where the
+// fields of the observations are separated by a space, the blank field is
+// the missing observation, and the text difference of the flags follows.
+func (sat *crinexSat) decode(l string) error {
+	p := 0
+	for k := range sat.arcs {
+		switch {
+		case p >= len(l):
+			// the rest of the fields are blank
+			sat.arcs[k].valid = false
+			continue
+		case l[p] == ' ':
+			sat.arcs[k].valid = false
+			p++
+			continue
+		}
+
+		e := strings.IndexByte(l[p:], ' ')
+		if e < 0 {
+			e = len(l) - p
+		}
+		if err := sat.arcs[k].decode(l[p : p+e]); err != nil {
+			return err
+		}
+		p += e + 1
+	}
+
+	if p < len(l) {
+		sat.flags = []byte(repairText(string(sat.flags), l[p:]))
+	}
+	return nil
+}
+
+// putEpoch writes the epoch line of RINEX with the satellites and the clock.
+func (d *crinexDecoder) putEpoch(head string, sats []string) {
+	if d.v3 {
+		l := head
+		if d.clock.valid {
+			l = fmt.Sprintf("%-41s%15s", head, formatInt(d.clock.u[0], 12))
+		}
+		d.put(l)
+		return
+	}
+
+	var lines []string
+	for k := 0; k < len(sats); k += satsPerLine2 {
+		lines = append(lines, strings.Join(sats[k:min(k+satsPerLine2, len(sats))], ""))
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	l := head + lines[0]
+	if d.clock.valid {
+		l = fmt.Sprintf("%-68s%12s", l, formatInt(d.clock.u[0], 9))
+	}
+	d.put(l)
+	for _, s := range lines[1:] {
+		d.put(strings.Repeat(" ", 32) + s)
+	}
+}
+
+// putData writes the observations of the satellite, in a line for RINEX 3
+// and five in a line for RINEX 2.
+func (d *crinexDecoder) putData(id string, sat *crinexSat) {
+	var b strings.Builder
+	if d.v3 {
+		b.WriteString(id)
+	}
+	for k, a := range sat.arcs {
+		if !d.v3 && k > 0 && k%obsPerLine2 == 0 {
+			d.put(b.String())
+			b.Reset()
+		}
+		if a.valid {
+			fmt.Fprintf(&b, "%14s", formatInt(a.u[0], 3))
+		} else {
+			b.WriteString(strings.Repeat(" ", 14))
+		}
+		b.Write(sat.flags[2*k : 2*k+2])
+	}
+	d.put(b.String())
+}
+
+// repairText restores the text from the text difference diff of the
+// reference ref, where a space is the unchanged character, '&' is the
+// space, and the others are the new characters.
+func repairText(ref, diff string) string {
+	b := []byte(ref)
+	for len(b) < len(diff) {
+		b = append(b, ' ')
+	}
+	for i := range len(diff) {
+		switch diff[i] {
+		case ' ':
+		case '&':
+			b[i] = ' '
+		default:
+			b[i] = diff[i]
+		}
+	}
+	return string(b)
+}
+
+// formatInt formats the integer v of the value v*10^-prec with prec
+// decimals, e.g., -0.123 for v=-123 and prec=3.
+func formatInt(v int64, prec int) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	s := strconv.FormatInt(v, 10)
+	if len(s) <= prec {
+		s = strings.Repeat("0", prec-len(s)+1) + s
+	}
+	return sign + s[:len(s)-prec] + "." + s[len(s)-prec:]
+}
@@ -0,0 +1,212 @@
+package rinex
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestDecompress checks the CRINEX 1 and 3 fixtures are decompressed into
+// the RINEX fixtures byte by byte.
+//
+// The fixtures are compressed by hand following the format of RNX2CRX, not
+// by the tool itself, which is labeled in their CRINEX PROG / DATE lines.
+// TestDecompressRNX2CRX checks the outputs of the tools.
+func TestDecompress(t *testing.T) {
+	for _, tt := range []struct {
+		crx, rnx string
+	}{
+		{"testdata/02551960_excerpt.24d", testObs2File},
+		{"testdata/02551960_excerpt.crx", testObs3File},
+	} {
+		r, err := os.Open(tt.crx)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer r.Close()
+
+		var b bytes.Buffer
+		if err := Decompress(&b, r); err != nil {
+			t.Fatalf("%s: Decompress: %v", tt.crx, err)
+		}
+		want, err := os.ReadFile(tt.rnx)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		get := strings.Split(b.String(), "\n")
+		for i, l := range strings.Split(string(want), "\n") {
+			if i >= len(get) || get[i] != l {
+				t.Fatalf("%s: line %d:\nget  '%s'\nwant '%s'", tt.crx, i+1, get[min(i, len(get)-1)], l)
+			}
+		}
+		if !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("%s: size: get %d, want %d", tt.crx, b.Len(), len(want))
+		}
+	}
+}
+
+// The files of RNX2CRX for TestDecompressRNX2CRX, which are not distributed
+// with the module, with the outputs of CRX2RNX in the same names of the
+// extensions .rnx and .??o, e.g., the daily files of GEONET or IGS:
+//
+//	02551960.24d                            CRINEX 1
+//	TSK200JPN_R_20241960000_01D_30S_MO.crx  CRINEX 3
+var testRNX2CRXPatterns = []string{
+	"testdata/????[0-9][0-9][0-9]0.[0-9][0-9]d",
+	"testdata/*_MO.crx",
+}
+
+// TestDecompressRNX2CRX checks the files compressed by RNX2CRX are
+// decompressed into the outputs of CRX2RNX byte by byte, or skips the test
+// if there are no such files in testdata.
+func TestDecompressRNX2CRX(t *testing.T) {
+	var crxs []string
+	for _, pattern := range testRNX2CRXPatterns {
+		m, _ := filepath.Glob(pattern)
+		crxs = append(crxs, m...)
+	}
+	if len(crxs) == 0 {
+		t.Skip("no file of RNX2CRX in testdata")
+	}
+
+	for _, crx := range crxs {
+		rnx := strings.TrimSuffix(crx, ".crx") + ".rnx"
+		if strings.HasSuffix(crx, "d") {
+			rnx = crx[:len(crx)-1] + "o"
+		}
+		want, err := os.ReadFile(rnx)
+		if err != nil {
+			t.Fatalf("%s: ReadFile: %v", crx, err)
+		}
+		r, err := os.Open(crx)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer r.Close()
+
+		var b bytes.Buffer
+		if err := Decompress(&b, r); err != nil {
+			t.Fatalf("%s: Decompress: %v", crx, err)
+		}
+		if !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("%s: differs from %s", crx, rnx)
+		}
+	}
+}
+
+// TestReadObsFileCRINEX checks the compressed files are read as the RINEX
+// files.
+func TestReadObsFileCRINEX(t *testing.T) {
+	for _, tt := range []struct {
+		crx, rnx string
+	}{
+		{"testdata/02551960_excerpt.24d", testObs2File},
+		{"testdata/02551960_excerpt.crx", testObs3File},
+	} {
+		f, err := ReadObsFile(tt.crx)
+		if err != nil {
+			t.Fatalf("%s: ReadObsFile: %v", tt.crx, err)
+		}
+		want, err := ReadObsFile(tt.rnx)
+		if err != nil {
+			t.Fatalf("%s: ReadObsFile: %v", tt.rnx, err)
+		}
+		if !reflect.DeepEqual(f, want) {
+			t.Errorf("%s: contents differ from %s", tt.crx, tt.rnx)
+		}
+	}
+}
+
+// TestDiffArc checks the values are restored from the differences of the
+// orders up to the arc order.
+func TestDiffArc(t *testing.T) {
+	values := []int64{100, 103, 109, 120, 136, 160, 159}
+	fields := []string{"3&100", "3", "3", "2", "0", "3", "-33"}
+
+	var a diffArc
+	for i, f := range fields {
+		if err := a.decode(f); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if a.u[0] != values[i] {
+			t.Errorf("epoch %d: get %d, want %d", i, a.u[0], values[i])
+		}
+	}
+
+	// reset of the arc
+	a = diffArc{}
+	if err := a.decode("5"); !errors.Is(err, ErrFormat) {
+		t.Errorf("get err=%v, want %v", err, ErrFormat)
+	}
+}
+
+// TestRepairText checks the text differences of the epoch lines and flags.
+func TestRepairText(t *testing.T) {
+	for _, tt := range []struct {
+		ref, diff, want string
+	}{
+		{"abc", "", "abc"},
+		{"abc", " x", "axc"},
+		{"abc", "  &", "ab "},
+		{"abc", "   de", "abcde"},
+		{"", " 1", " 1"},
+	} {
+		if get := repairText(tt.ref, tt.diff); get != tt.want {
+			t.Errorf("repairText(%q, %q): get %q, want %q", tt.ref, tt.diff, get, tt.want)
+		}
+	}
+}
+
+// TestFormatInt checks the decimal point is restored.
+func TestFormatInt(t *testing.T) {
+	for _, tt := range []struct {
+		v    int64
+		prec int
+		want string
+	}{
+		{20969460336, 3, "20969460.336"},
+		{-2606827, 3, "-2606.827"},
+		{-123, 3, "-0.123"},
+		{5, 3, "0.005"},
+		{0, 3, "0.000"},
+		{123456, 9, "0.000123456"},
+		{-12345678, 12, "-0.000012345678"},
+	} {
+		if get := formatInt(tt.v, tt.prec); get != tt.want {
+			t.Errorf("formatInt(%d, %d): get %s, want %s", tt.v, tt.prec, get, tt.want)
+		}
+	}
+}
+
+// TestDecompressError checks the format errors with the line numbers of the
+// compressed file.
+func TestDecompressError(t *testing.T) {
+	b, err := os.ReadFile("testdata/02551960_excerpt.24d")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	orig := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	for _, tt := range []struct {
+		name string
+		mod  func(l []string) []string
+		line string
+	}{
+		{"not crinex", func(l []string) []string { return l[2:] }, "line 1:"},
+		{"version", func(l []string) []string { l[0] = "2.0" + l[0][3:]; return l }, "line 1:"},
+		{"no end of header", func(l []string) []string { return l[:10] }, "line 10:"},
+		{"epoch not initialized", func(l []string) []string { l[18] = " " + l[18][1:]; return l }, "line 19:"},
+		{"arc not initialized", func(l []string) []string { l[20] = "5" + l[20][13:]; return l }, "line 21:"},
+		{"field", func(l []string) []string { l[20] = "3&x" + l[20][3:]; return l }, "line 21:"},
+		{"data", func(l []string) []string { return l[:25] }, "line 25:"},
+	} {
+		err := Decompress(&bytes.Buffer{}, strings.NewReader(strings.Join(tt.mod(slices.Clone(orig)), "\n")))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: err=%v, want %s", tt.name, err, tt.line)
+		}
+	}
+}
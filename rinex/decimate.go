@@ -0,0 +1,85 @@
+package rinex
+
+import "time"
+
+// EpochReader is the interface of the readers of the epochs, e.g.,
+// ObsReader and Decimator, whose Next returns io.EOF at the end.
+type EpochReader interface {
+	Next() (*Epoch, error)
+}
+
+// DecimateOpts is the options of Decimator.
+type DecimateOpts struct {
+	// Interval is the interval of the epochs kept, which should divide a
+	// day, e.g., 30 s.
+	Interval time.Duration
+
+	// Tolerance is the maximum distance of the epochs kept from the
+	// multiples of Interval, e.g., a few milliseconds for the receivers
+	// whose epochs are offset by the clock, or zero for the exact multiples.
+	Tolerance time.Duration
+
+	// Snap rounds the epochs kept to the multiples. The observations are not
+	// corrected for the offsets, i.e., the positions are biased by the
+	// satellite motions during the offsets, e.g., a few meters per
+	// millisecond.
+	Snap bool
+}
+
+// Decimator reads the epochs of the reader whose seconds of the day are the
+// multiples of the interval within the tolerance. The events are read
+// regardless of the epochs, and the epoch nearest to a multiple is not
+// searched, i.e., the first epoch within the tolerance is kept.
+type Decimator struct {
+	r    EpochReader
+	opts DecimateOpts
+	last time.Time // last multiple kept
+
+	// Kept and Dropped are the numbers of the epochs of the observations
+	// read and skipped.
+	Kept, Dropped int
+}
+
+// NewDecimator returns the decimator of the epochs of the reader r.
+func NewDecimator(r EpochReader, opts DecimateOpts) *Decimator {
+	return &Decimator{r: r, opts: opts}
+}
+
+// Next returns the next epoch kept, and io.EOF at the end.
+func (d *Decimator) Next() (*Epoch, error) {
+	for {
+		e, err := d.r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e.IsEvent() {
+			return e, nil
+		}
+		if d.opts.Interval <= 0 {
+			d.Kept++
+			return e, nil
+		}
+
+		slot, ok := d.slot(e.Time)
+		if !ok || slot.Equal(d.last) {
+			d.Dropped++
+			continue
+		}
+		d.last = slot
+		d.Kept++
+		if d.opts.Snap {
+			e.Time = slot
+		}
+		return e, nil
+	}
+}
+
+// slot returns the multiple of the interval of the day nearest to t, and
+// whether t is within the tolerance.
+func (d *Decimator) slot(t time.Time) (time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	sod := t.Sub(day)
+	slot := day.Add((sod + d.opts.Interval/2) / d.opts.Interval * d.opts.Interval)
+	off := t.Sub(slot)
+	return slot, off >= -d.opts.Tolerance && off <= d.opts.Tolerance
+}
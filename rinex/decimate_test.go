@@ -0,0 +1,115 @@
+package rinex
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// testEpochs is an EpochReader of the epochs.
+type testEpochs []*Epoch
+
+func (r *testEpochs) Next() (*Epoch, error) {
+	if len(*r) == 0 {
+		return nil, io.EOF
+	}
+	e := (*r)[0]
+	*r = (*r)[1:]
+	return e, nil
+}
+
+// readAll returns the epochs of the reader.
+func readAll(t *testing.T, r EpochReader) []*Epoch {
+	t.Helper()
+	var epochs []*Epoch
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return epochs
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		epochs = append(epochs, e)
+	}
+}
+
+// TestDecimator checks the epochs of 1 Hz offset by the receiver clock
+// decimated to 30 s.
+func TestDecimator(t *testing.T) {
+	t0 := time.Date(2024, 7, 13, 23, 59, 0, 0, time.UTC)
+	offsets := []time.Duration{time.Millisecond / 2, -time.Millisecond, 3 * time.Millisecond, 0}
+	newEpochs := func() *testEpochs {
+		var epochs testEpochs
+		for s := range 150 {
+			epochs = append(epochs, &Epoch{Time: t0.Add(time.Duration(s)*time.Second + offsets[s%4])})
+			if s == 40 {
+				epochs = append(epochs, &Epoch{Flag: FlagHeader})
+			}
+		}
+		return &epochs
+	}
+
+	for _, tt := range []struct {
+		opts    DecimateOpts
+		seconds []int // seconds from t0 of the epochs read, where 40 is the event
+	}{
+		// the offsets of 3 ms at 30 s and 90 s
+		{DecimateOpts{Interval: 30 * time.Second, Tolerance: 2 * time.Millisecond}, []int{0, 40, 60, 120}},
+		{DecimateOpts{Interval: 30 * time.Second, Tolerance: 5 * time.Millisecond}, []int{0, 30, 40, 60, 90, 120}},
+		{DecimateOpts{Interval: time.Minute, Tolerance: 5 * time.Millisecond, Snap: true}, []int{0, 40, 60, 120}},
+		{DecimateOpts{Interval: 30 * time.Second}, []int{40}},
+		// the first epochs within the tolerance, i.e., before the multiples
+		{DecimateOpts{Interval: time.Minute, Tolerance: 1500 * time.Millisecond}, []int{0, 40, 59, 119}},
+	} {
+		d := NewDecimator(newEpochs(), tt.opts)
+		epochs := readAll(t, d)
+		if len(epochs) != len(tt.seconds) || d.Kept+d.Dropped != 150 || d.Kept != len(tt.seconds)-1 {
+			t.Errorf("%+v: kept=%d, dropped=%d, epochs=%d", tt.opts, d.Kept, d.Dropped, len(epochs))
+			continue
+		}
+		for k, e := range epochs {
+			if tt.seconds[k] == 40 {
+				if !e.IsEvent() {
+					t.Errorf("%+v: %d: %+v", tt.opts, k, e)
+				}
+				continue
+			}
+			s := tt.seconds[k]
+			want := t0.Add(time.Duration(s)*time.Second + offsets[s%4])
+			if tt.opts.Snap {
+				want = t0.Add(time.Duration(s) * time.Second)
+			}
+			if !e.Time.Equal(want) {
+				t.Errorf("%+v: %d: get %v, want %v", tt.opts, k, e.Time, want)
+			}
+		}
+	}
+
+	// no interval
+	d := NewDecimator(newEpochs(), DecimateOpts{})
+	if epochs := readAll(t, d); len(epochs) != 151 || d.Kept != 150 {
+		t.Errorf("no interval: epochs=%d, kept=%d", len(epochs), d.Kept)
+	}
+}
+
+// TestDecimatorObsReader checks the decimator of the reader of the fixture.
+func TestDecimatorObsReader(t *testing.T) {
+	f, err := os.Open(testObs3File)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	or, err := NewObsReader(f)
+	if err != nil {
+		t.Fatalf("NewObsReader: %v", err)
+	}
+
+	d := NewDecimator(or, DecimateOpts{Interval: time.Minute})
+	epochs := readAll(t, d)
+	if len(epochs) != 2 || epochs[0].Time.Second() != 0 || !epochs[1].IsEvent() || d.Kept != 1 || d.Dropped != 1 {
+		t.Errorf("epochs=%d, kept=%d, dropped=%d", len(epochs), d.Kept, d.Dropped)
+	}
+}
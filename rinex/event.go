@@ -0,0 +1,82 @@
+package rinex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+)
+
+// The epoch flags of RINEX.
+const (
+	FlagOK           = 0 // observations
+	FlagPowerFailure = 1 // observations after a power failure
+	FlagMoving       = 2 // start of the moving antenna
+	FlagOccupation   = 3 // new site occupation of the kinematic surveys
+	FlagHeader       = 4 // header lines follow
+	FlagExternal     = 5 // external event
+	FlagCycleSlip    = 6 // cycle slip records
+)
+
+// IsEvent returns whether the epoch is an event, i.e., of the flags 2 to
+// 5 without the observations.
+func (e *Epoch) IsEvent() bool {
+	return e.Flag >= FlagMoving && e.Flag <= FlagExternal
+}
+
+// readEvent reads the n header lines following the event line into e, and
+// applies them to the header. The types redefined replace those of the
+// satellite systems.
+func (r *ObsReader) readEvent(e *Epoch, n int) error {
+	for range n {
+		if !r.r.next() {
+			return fmt.Errorf("%w: event record not found", ErrFormat)
+		}
+		l := r.r.line
+		e.HeaderLines = append(e.HeaderLines, strings.TrimRight(l, " "))
+
+		switch label(l) {
+		case "SYS / # / OBS TYPES":
+			if l[0] != ' ' {
+				delete(r.Header.Types, l[0])
+				delete(r.ntypes, l[0])
+			}
+		case "# / TYPES OF OBSERV":
+			if strings.TrimSpace(l[:6]) != "" {
+				delete(r.Header.Types, ' ')
+			}
+		}
+		if err := r.parseHeaderLine(l); err != nil {
+			return err
+		}
+	}
+	return r.checkHeader()
+}
+
+// readSplicedHeader reads the header of the file concatenated to the file,
+// whose first line is l, as an event of the header lines. The version must
+// be the same as the file.
+func (r *ObsReader) readSplicedHeader(l string) (*Epoch, error) {
+	v, err := field.Atof(l[:9])
+	if err != nil {
+		return nil, fmt.Errorf("%w: version: %v", ErrFormat, err)
+	}
+	if (v >= 3) != r.v3 || l[20] != 'O' {
+		return nil, fmt.Errorf("%w: spliced file of version %.2f and type '%c'", ErrFormat, v, l[20])
+	}
+
+	e := &Epoch{Flag: FlagHeader}
+	r.Header.Types = make(map[byte][]string)
+	r.ntypes = make(map[byte]int)
+	for r.r.next() {
+		l := r.r.line
+		if label(l) == "END OF HEADER" {
+			return e, r.checkHeader()
+		}
+		e.HeaderLines = append(e.HeaderLines, strings.TrimRight(l, " "))
+		if err := r.parseHeaderLine(l); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no end of header of the spliced file", ErrFormat)
+}
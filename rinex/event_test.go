@@ -0,0 +1,162 @@
+package rinex
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readEpochs returns the epochs of the lines by ObsReader, and the headers
+// of the reader after the epochs.
+func readEpochs(t *testing.T, lines []string) ([]*Epoch, []ObsHeader) {
+	t.Helper()
+	or, err := NewObsReader(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("NewObsReader: %v", err)
+	}
+	var epochs []*Epoch
+	var headers []ObsHeader
+	for {
+		e, err := or.Next()
+		if errors.Is(err, io.EOF) {
+			return epochs, headers
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		epochs = append(epochs, e)
+		headers = append(headers, or.Header)
+	}
+}
+
+// TestEvents checks the header lines of the events of the fixtures, which
+// are applied to the header of the reader.
+func TestEvents(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		lines int
+	}{
+		{testObs2File, 2},
+		{testObs3File, 1},
+	} {
+		epochs, headers := readEpochs(t, readTestLines(t, tt.name))
+		e := epochs[1]
+		if !e.IsEvent() || e.Flag != FlagHeader || len(e.HeaderLines) != tt.lines ||
+			e.HeaderLines[0] != "ANTENNA CHECKED BY THE OPERATOR                             COMMENT" {
+			t.Errorf("%s: event: %+v", tt.name, e)
+		}
+		if len(headers[0].Comments) != 1 || len(headers[1].Comments) != 2 || headers[1].Comments[1] != "ANTENNA CHECKED BY THE OPERATOR" {
+			t.Errorf("%s: comments: %q", tt.name, headers[1].Comments)
+		}
+		if epochs[0].IsEvent() || epochs[2].IsEvent() {
+			t.Errorf("%s: flags %d %d", tt.name, epochs[0].Flag, epochs[2].Flag)
+		}
+	}
+}
+
+// TestEventAntenna checks the antenna and the types changed by an event
+// of RINEX 3.
+func TestEventAntenna(t *testing.T) {
+	lines := readTestLines(t, testObs3File)
+	lines = slices.Replace(lines, 38, 40,
+		">                              4  4",
+		"1441041254          TRM57971.00     NONE                    ANT # / TYPE",
+		"        0.1000        0.0000        0.0000                  ANTENNA: DELTA H/E/N",
+		"G    2 C1C L1C                                              SYS / # / OBS TYPES",
+		"UNKNOWN LABEL OF AN EVENT                                   UNKNOWN")
+	epochs, headers := readEpochs(t, lines)
+	if len(epochs) != 3 || len(epochs[1].HeaderLines) != 4 {
+		t.Fatalf("epochs: %+v", epochs)
+	}
+
+	if h := headers[0]; h.ANTEXType() != "TRM59800.80     GSI" || h.AntDelta[0] != 0 {
+		t.Errorf("antenna before the event: %s %v", h.ANTEXType(), h.AntDelta)
+	}
+	h := headers[1]
+	if h.ANTEXType() != "TRM57971.00     NONE" || h.AntDelta[0] != 0.1 || len(h.Others["UNKNOWN"]) != 1 {
+		t.Errorf("antenna after the event: %s %v %q", h.ANTEXType(), h.AntDelta, h.Others)
+	}
+	if !slices.Equal(h.ObsTypes('G'), []string{"C1C", "L1C"}) || len(h.ObsTypes('E')) != 12 {
+		t.Errorf("types: %v", h.Types)
+	}
+
+	// the observations of the types redefined
+	e := epochs[2]
+	if obs := e.Obs["G14"]; len(obs) != 2 || obs["C1C"].Value != 20975285.793 {
+		t.Errorf("G14: %+v", obs)
+	}
+	if obs := e.Obs["E11"]; len(obs) == 2 {
+		t.Errorf("E11: %+v", obs)
+	}
+}
+
+// TestCycleSlip checks the observations of the cycle slip records.
+func TestCycleSlip(t *testing.T) {
+	lines := readTestLines(t, testObs3File)
+	lines[40] = lines[40][:31] + "6" + lines[40][32:]
+	epochs, _ := readEpochs(t, lines)
+	if e := epochs[2]; e.IsEvent() || e.Flag != FlagCycleSlip || e.Time.IsZero() || len(e.Sats) != 14 {
+		t.Errorf("cycle slips: flag=%d, time=%v, sats=%v", e.Flag, e.Time, e.Sats)
+	}
+}
+
+// TestSplicedFiles checks the files concatenated are read in order with the
+// header of the second file as an event.
+func TestSplicedFiles(t *testing.T) {
+	for _, name := range []string{testObs2File, testObs3File} {
+		lines := readTestLines(t, name)
+		second := slices.Clone(lines)
+		for k, l := range second {
+			if strings.HasSuffix(l, "ANT # / TYPE") {
+				second[k] = "1441041254          TRM57971.00     NONE                    ANT # / TYPE"
+			}
+		}
+		epochs, headers := readEpochs(t, append(lines, second...))
+		if len(epochs) != 7 {
+			t.Fatalf("%s: number of epochs: %d", name, len(epochs))
+		}
+
+		e := epochs[3]
+		if e.Flag != FlagHeader || !e.Time.IsZero() || len(e.HeaderLines) != slices.Index(lines, "                                                            END OF HEADER")-1 {
+			t.Errorf("%s: spliced header: %+v", name, e)
+		}
+		if headers[2].AntName != "TRM59800.80" || headers[3].AntName != "TRM57971.00" {
+			t.Errorf("%s: antennas: %s %s", name, headers[2].AntName, headers[3].AntName)
+		}
+		for k := range 3 {
+			if !epochs[k].Time.Equal(epochs[k+4].Time) || len(epochs[k].Sats) != len(epochs[k+4].Sats) {
+				t.Errorf("%s: epoch %d: %v %v", name, k, epochs[k].Time, epochs[k+4].Time)
+			}
+		}
+	}
+}
+
+// TestEventErrors checks the errors of the events and the spliced files.
+func TestEventErrors(t *testing.T) {
+	lines2 := readTestLines(t, testObs2File)
+	lines3 := readTestLines(t, testObs3File)
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		line  string
+	}{
+		{"flag", slices.Replace(slices.Clone(lines3), 20, 21, "> 2024 07 14 00 00  0.0000000  7 17"), "line 21:"},
+		{"header line", slices.Replace(slices.Clone(lines3), 39, 40, "    30.0x0                                                  INTERVAL"), "line 40:"},
+		{"types", slices.Replace(slices.Clone(lines3), 39, 40, "G    2 C1C                                                  SYS / # / OBS TYPES"), "line 40:"},
+		{"version of the spliced file", append(slices.Clone(lines3), lines2...), "line 56:"},
+		{"end of the spliced header", append(slices.Clone(lines3), lines3[:5]...), "line 60:"},
+	} {
+		or, err := NewObsReader(strings.NewReader(strings.Join(tt.lines, "\n")))
+		if err != nil {
+			t.Fatalf("%s: NewObsReader: %v", tt.name, err)
+		}
+		for err == nil {
+			_, err = or.Next()
+		}
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: err=%v, want %s", tt.name, err, tt.line)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package rinex
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrIncompatible is returned by MergeObs when the files cannot be merged.
+var ErrIncompatible = errors.New("incompatible observation files")
+
+// MergeOpts is the options of MergeObs.
+type MergeOpts struct {
+	// ReconcileTypes merges the different types of the files into the union
+	// of the types of each satellite system, in the order of the files.
+	// The files of the different types are incompatible if false.
+	ReconcileTypes bool
+
+	// Interval is the nominal interval of the epochs for the gaps. The
+	// interval of the header of the first file is used if zero, or the
+	// smallest interval of the epochs if not given.
+	Interval time.Duration
+}
+
+// Gap is the epochs missing between the epochs of Start and End.
+type Gap struct {
+	Start, End time.Time
+
+	// Missing is the number of the epochs missing by the interval.
+	Missing int
+}
+
+// Overlap is the common time span of the epochs of two files, of the
+// indices of the arguments of MergeObs.
+type Overlap struct {
+	Files      [2]int
+	Start, End time.Time
+}
+
+// MergeReport is the diagnostics of MergeObs.
+type MergeReport struct {
+	// Duplicates is the epochs of the same times as the epochs before,
+	// which are removed.
+	Duplicates []time.Time
+
+	Overlaps []Overlap
+	Gaps     []Gap
+}
+
+// MergeObs merges the observation files, e.g., the hourly files of a day,
+// into a file of the epochs in the order of the times. The gaps of the
+// epochs longer than 1.5 intervals are preserved and reported, the epochs of
+// the same times as those before in the order of the files are removed and
+// reported, and the overlaps of the files are reported. The events are kept
+// after the epochs they follow in the files.
+//
+// The files must have the same marker name, antenna, time system and types
+// unless opts.ReconcileTypes. The RINEX 2 files should be normalized to the
+// RINEX 3 codes by CodeMap.Normalize before merged with the RINEX 3 files.
+// The header of the merged file is that of the first file, with the types
+// reconciled, the first and the last epochs, and the comments of all the
+// files. The epochs are shared with the files.
+func MergeObs(files []*ObsFile, opts MergeOpts) (*ObsFile, *MergeReport, error) {
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("%w: no file", ErrIncompatible)
+	}
+
+	h0 := files[0].Header
+	m := &ObsFile{Header: h0}
+	m.Header.Types = make(map[byte][]string)
+	m.Header.Comments = nil
+	for i, f := range files {
+		h := f.Header
+		switch {
+		case h.MarkerName != h0.MarkerName:
+			return nil, nil, fmt.Errorf("%w: file %d: marker: %s, want %s", ErrIncompatible, i, h.MarkerName, h0.MarkerName)
+		case h.AntType != h0.AntType || h.AntNumber != h0.AntNumber:
+			return nil, nil, fmt.Errorf("%w: file %d: antenna: %s %s, want %s %s", ErrIncompatible, i, h.AntType, h.AntNumber, h0.AntType, h0.AntNumber)
+		case h.AntDelta != h0.AntDelta:
+			return nil, nil, fmt.Errorf("%w: file %d: antenna delta: %v, want %v", ErrIncompatible, i, h.AntDelta, h0.AntDelta)
+		case h.TimeSystem != h0.TimeSystem:
+			return nil, nil, fmt.Errorf("%w: file %d: time system: %s, want %s", ErrIncompatible, i, h.TimeSystem, h0.TimeSystem)
+		}
+		for sys, types := range h.Types {
+			if !opts.ReconcileTypes && !slices.Equal(types, h0.Types[sys]) {
+				return nil, nil, fmt.Errorf("%w: file %d: types of '%c': %v, want %v", ErrIncompatible, i, sys, types, h0.Types[sys])
+			}
+			for _, c := range types {
+				if !slices.Contains(m.Header.Types[sys], c) {
+					m.Header.Types[sys] = append(m.Header.Types[sys], c)
+				}
+			}
+		}
+		if !opts.ReconcileTypes && len(h.Types) != len(h0.Types) {
+			return nil, nil, fmt.Errorf("%w: file %d: types of the systems", ErrIncompatible, i)
+		}
+		for _, c := range h.Comments {
+			if !slices.Contains(m.Header.Comments, c) {
+				m.Header.Comments = append(m.Header.Comments, c)
+			}
+		}
+	}
+
+	// the epochs of the files with the times of the events, which are
+	// those of the epochs before
+	type entry struct {
+		t    time.Time
+		file int
+		e    *Epoch
+	}
+	var entries []entry
+	for i, f := range files {
+		var t time.Time
+		for _, e := range f.Epochs {
+			if !e.IsEvent() || !e.Time.IsZero() {
+				t = e.Time
+			}
+			entries = append(entries, entry{t, i, e})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int { return a.t.Compare(b.t) })
+
+	rep := &MergeReport{}
+	var epochs []time.Time
+	for _, en := range entries {
+		e := en.e
+		if !e.IsEvent() {
+			if n := len(epochs); n > 0 && e.Time.Equal(epochs[n-1]) {
+				rep.Duplicates = append(rep.Duplicates, e.Time)
+				continue
+			}
+			epochs = append(epochs, e.Time)
+		}
+		m.Epochs = append(m.Epochs, e)
+	}
+	rep.Overlaps = overlaps(files)
+
+	if len(epochs) > 0 {
+		m.Header.FirstObs = epochs[0]
+		m.Header.LastObs = epochs[len(epochs)-1]
+	}
+	interval := opts.Interval
+	if interval == 0 {
+		interval = h0.Interval
+	}
+	if interval == 0 {
+		for k := 1; k < len(epochs); k++ {
+			if dt := epochs[k].Sub(epochs[k-1]); interval == 0 || dt < interval {
+				interval = dt
+			}
+		}
+	}
+	for k := 1; k < len(epochs) && interval > 0; k++ {
+		if dt := epochs[k].Sub(epochs[k-1]); dt > interval*3/2 {
+			n := int((dt+interval/2)/interval) - 1
+			rep.Gaps = append(rep.Gaps, Gap{epochs[k-1], epochs[k], n})
+		}
+	}
+	return m, rep, nil
+}
+
+// overlaps returns the overlaps of the time spans of the epochs of the
+// files.
+func overlaps(files []*ObsFile) []Overlap {
+	spans := make([][2]time.Time, len(files))
+	for i, f := range files {
+		for _, e := range f.Epochs {
+			if e.IsEvent() {
+				continue
+			}
+			if spans[i][0].IsZero() || e.Time.Before(spans[i][0]) {
+				spans[i][0] = e.Time
+			}
+			if e.Time.After(spans[i][1]) {
+				spans[i][1] = e.Time
+			}
+		}
+	}
+
+	var ovs []Overlap
+	for i := range files {
+		for j := i + 1; j < len(files); j++ {
+			a, b := spans[i], spans[j]
+			if a[0].IsZero() || b[0].IsZero() || a[1].Before(b[0]) || b[1].Before(a[0]) {
+				continue
+			}
+			start, end := a[0], a[1]
+			if b[0].After(start) {
+				start = b[0]
+			}
+			if b[1].Before(end) {
+				end = b[1]
+			}
+			ovs = append(ovs, Overlap{[2]int{i, j}, start, end})
+		}
+	}
+	return ovs
+}
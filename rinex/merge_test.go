@@ -0,0 +1,127 @@
+package rinex
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testMergeFile returns the file of the header of the fixture with the
+// epochs of the observations of the first epoch of the fixture every 30 s
+// from the minutes from to the minutes to of 2024-07-14.
+func testMergeFile(t *testing.T, from, to int) *ObsFile {
+	t.Helper()
+	f, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	g := &ObsFile{Header: f.Header}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for s := 60 * from; s <= 60*to; s += 30 {
+		e := *f.Epochs[0]
+		e.Time = t0.Add(time.Duration(s) * time.Second)
+		g.Epochs = append(g.Epochs, &e)
+	}
+	return g
+}
+
+// TestMergeObs checks the files of a repeated boundary epoch and a gap given
+// out of order.
+func TestMergeObs(t *testing.T) {
+	f1, f2, f3 := testMergeFile(t, 0, 5), testMergeFile(t, 5, 10), testMergeFile(t, 12, 15)
+	f2.Epochs = slices.Insert(f2.Epochs, 1, &Epoch{Flag: FlagHeader, HeaderLines: []string{"EVENT"}})
+	f3.Header.Comments = append(slices.Clone(f3.Header.Comments), "THIRD FILE")
+
+	m, rep, err := MergeObs([]*ObsFile{f3, f1, f2}, MergeOpts{})
+	if err != nil {
+		t.Fatalf("MergeObs: %v", err)
+	}
+	if len(m.Epochs) != 11+10+7+1 {
+		t.Fatalf("number of epochs: %d", len(m.Epochs))
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+
+	var times []time.Time
+	for k, e := range m.Epochs {
+		if e.IsEvent() {
+			if k != 11 {
+				t.Errorf("event at %d", k)
+			}
+			continue
+		}
+		if n := len(times); n > 0 && !e.Time.After(times[n-1]) {
+			t.Errorf("epoch %d: %v after %v", k, e.Time, times[n-1])
+		}
+		times = append(times, e.Time)
+	}
+	if !m.Header.FirstObs.Equal(at(0)) || !m.Header.LastObs.Equal(at(900)) {
+		t.Errorf("first=%v, last=%v", m.Header.FirstObs, m.Header.LastObs)
+	}
+	if len(m.Header.Comments) != 2 || m.Header.Comments[1] != "THIRD FILE" {
+		t.Errorf("comments: %q", m.Header.Comments)
+	}
+
+	if !slices.EqualFunc(rep.Duplicates, []time.Time{at(300)}, time.Time.Equal) {
+		t.Errorf("duplicates: %v", rep.Duplicates)
+	}
+	if len(rep.Overlaps) != 1 || rep.Overlaps[0].Files != [2]int{1, 2} || !rep.Overlaps[0].Start.Equal(at(300)) || !rep.Overlaps[0].End.Equal(at(300)) {
+		t.Errorf("overlaps: %+v", rep.Overlaps)
+	}
+	if len(rep.Gaps) != 1 || !rep.Gaps[0].Start.Equal(at(600)) || !rep.Gaps[0].End.Equal(at(720)) || rep.Gaps[0].Missing != 3 {
+		t.Errorf("gaps: %+v", rep.Gaps)
+	}
+
+	// the interval of the epochs and the option
+	f1.Header.Interval = 0
+	if _, rep, _ = MergeObs([]*ObsFile{f1, f3}, MergeOpts{}); len(rep.Gaps) != 1 || rep.Gaps[0].Missing != 13 {
+		t.Errorf("gaps: %+v", rep.Gaps)
+	}
+	if _, rep, _ = MergeObs([]*ObsFile{f1, f3}, MergeOpts{Interval: time.Minute}); len(rep.Gaps) != 1 || rep.Gaps[0].Missing != 6 {
+		t.Errorf("gaps: %+v", rep.Gaps)
+	}
+}
+
+// TestMergeObsTypes checks the files of the different types.
+func TestMergeObsTypes(t *testing.T) {
+	f1, f2 := testMergeFile(t, 0, 1), testMergeFile(t, 1, 2)
+	f2.Header.Types = map[byte][]string{'G': {"C1C", "C1W"}, 'J': {"C1C"}}
+	if _, _, err := MergeObs([]*ObsFile{f1, f2}, MergeOpts{}); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("get err=%v, want %v", err, ErrIncompatible)
+	}
+
+	m, _, err := MergeObs([]*ObsFile{f1, f2}, MergeOpts{ReconcileTypes: true})
+	if err != nil {
+		t.Fatalf("MergeObs: %v", err)
+	}
+	gps := append(slices.Clone(f1.Header.Types['G']), "C1W")
+	if !slices.Equal(m.Header.Types['G'], gps) || !slices.Equal(m.Header.Types['J'], []string{"C1C"}) || len(m.Header.Types) != 5 {
+		t.Errorf("types: %v", m.Header.Types)
+	}
+	if len(f1.Header.Types['G']) != 14 {
+		t.Errorf("types of the file changed: %v", f1.Header.Types['G'])
+	}
+}
+
+// TestMergeObsErrors checks the headers of the files incompatible.
+func TestMergeObsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		mod  func(h *ObsHeader)
+	}{
+		{"marker", func(h *ObsHeader) { h.MarkerName = "0256" }},
+		{"antenna", func(h *ObsHeader) { h.AntType = "TRM57971.00     NONE" }},
+		{"antenna delta", func(h *ObsHeader) { h.AntDelta[0] = 0.1 }},
+		{"time system", func(h *ObsHeader) { h.TimeSystem = "GLO" }},
+	} {
+		f1, f2 := testMergeFile(t, 0, 1), testMergeFile(t, 1, 2)
+		tt.mod(&f2.Header)
+		if _, _, err := MergeObs([]*ObsFile{f1, f2}, MergeOpts{ReconcileTypes: true}); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrIncompatible)
+		}
+	}
+	if _, _, err := MergeObs(nil, MergeOpts{}); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("no file: get err=%v, want %v", err, ErrIncompatible)
+	}
+}
@@ -0,0 +1,304 @@
+package rinex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ErrNoMet is returned when the meteorological data cannot be interpolated
+// at the epoch, e.g., out of the file or in a gap of the data.
+var ErrNoMet = errors.New("no meteorological data")
+
+// MetSensor is a meteorological sensor of the header.
+type MetSensor struct {
+	// Type is the observation type of the sensor, e.g., "PR", and Model,
+	// SensorType and Accuracy are those of SENSOR MOD/TYPE/ACC.
+	Type       string
+	Model      string
+	SensorType string
+	Accuracy   float64
+
+	// Pos is the approximate position (ECEF, m) and Height is the ellipsoidal
+	// height (m) of the sensor of SENSOR POS XYZ/H if HasPos.
+	Pos    [3]float64
+	Height float64
+	HasPos bool
+}
+
+// MetHeader stores the header of a RINEX meteorological file.
+type MetHeader struct {
+	Version float64
+
+	MarkerName   string
+	MarkerNumber string
+
+	// Types is the observation types, e.g., "PR" (hPa), "TD" (deg C) and
+	// "HR" (%), and Sensors is the sensors of the types.
+	Types   []string
+	Sensors []MetSensor
+
+	Comments []string
+}
+
+// Sensor returns the sensor of the observation type, and false if not
+// given.
+func (h *MetHeader) Sensor(typ string) (MetSensor, bool) {
+	for _, s := range h.Sensors {
+		if s.Type == typ {
+			return s, true
+		}
+	}
+	return MetSensor{}, false
+}
+
+// MetEpoch stores the observations of an epoch keyed by the observation
+// types. The blank fields are not stored.
+type MetEpoch struct {
+	Time   time.Time
+	Values map[string]float64
+}
+
+// MetFile stores the contents of a RINEX meteorological file.
+type MetFile struct {
+	Header MetHeader
+	Epochs []MetEpoch
+}
+
+// ReadMetFile reads the RINEX meteorological file of the name.
+func ReadMetFile(name string) (*MetFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseMet(f)
+}
+
+// ParseMet reads a RINEX meteorological file of the versions 2, 3 or 4 from
+// r. The epochs must be in the increasing order.
+//
+// The errors are wrapped with the line number, and the format errors are
+// tested by errors.Is with ErrFormat.
+func ParseMet(r io.Reader) (*MetFile, error) {
+	p := metParser{r: lineReader{s: mscanner.NewScanner(r)}}
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.r.s.LineNumber(), err)
+	}
+	return &p.f, nil
+}
+
+// metParser holds the state of ParseMet.
+type metParser struct {
+	r lineReader
+	f MetFile
+
+	ntypes int
+	v3     bool // RINEX 3 or 4
+}
+
+// the numbers of the values of the first and the continuation lines of an
+// epoch
+const (
+	metValuesFirst = 8
+	metValuesCont  = 10
+)
+
+func (p *metParser) parse() error {
+	if err := p.parseHeader(); err != nil {
+		return err
+	}
+	for p.r.next() {
+		if strings.TrimSpace(p.r.line) == "" {
+			continue
+		}
+		e, err := p.parseEpoch()
+		if err != nil {
+			return err
+		}
+		if n := len(p.f.Epochs); n > 0 && !e.Time.After(p.f.Epochs[n-1].Time) {
+			return fmt.Errorf("%w: epoch not increasing: %v", ErrFormat, e.Time)
+		}
+		p.f.Epochs = append(p.f.Epochs, e)
+	}
+	return p.r.s.Err()
+}
+
+// parseHeader parses the header lines until "END OF HEADER".
+func (p *metParser) parseHeader() (err error) {
+	h := &p.f.Header
+	if !p.r.next() || label(p.r.line) != "RINEX VERSION / TYPE" {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(p.r.line))
+	}
+	if h.Version, err = field.Atof(p.r.line[:9]); err != nil {
+		return fmt.Errorf("%w: version: %v", ErrFormat, err)
+	}
+	if p.r.line[20] != 'M' {
+		return fmt.Errorf("%w: file type: '%c'", ErrFormat, p.r.line[20])
+	}
+	switch {
+	case h.Version < 2 || h.Version >= 5:
+		return fmt.Errorf("%w: unsupported version: %.2f", ErrFormat, h.Version)
+	case h.Version >= 3:
+		p.v3 = true
+	}
+
+	for p.r.next() {
+		l := p.r.line
+		switch label(l) {
+		case "MARKER NAME":
+			h.MarkerName = strings.TrimSpace(l[:60])
+		case "MARKER NUMBER":
+			h.MarkerNumber = strings.TrimSpace(l[:20])
+		case "# / TYPES OF OBSERV":
+			if err := p.parseTypes(l); err != nil {
+				return err
+			}
+		case "SENSOR MOD/TYPE/ACC":
+			// A20,A20,6X,F7.1,4X,A2
+			acc, err := field.AtofBlank(l[46:53])
+			if err != nil {
+				return fmt.Errorf("%w: sensor accuracy: %v", ErrFormat, err)
+			}
+			s := p.sensor(strings.TrimSpace(l[57:59]))
+			s.Model = strings.TrimSpace(l[:20])
+			s.SensorType = strings.TrimSpace(l[20:40])
+			s.Accuracy = acc
+		case "SENSOR POS XYZ/H":
+			// 3F14.4,1F14.4,1X,A2
+			var v [4]float64
+			for k := range v {
+				if v[k], err = field.Atof(l[14*k : 14*k+14]); err != nil {
+					return fmt.Errorf("%w: sensor position: %v", ErrFormat, err)
+				}
+			}
+			s := p.sensor(strings.TrimSpace(l[57:59]))
+			s.Pos, s.Height, s.HasPos = [3]float64{v[0], v[1], v[2]}, v[3], true
+		case "COMMENT":
+			h.Comments = append(h.Comments, strings.TrimRight(l[:60], " "))
+		case "END OF HEADER":
+			if p.ntypes == 0 || len(h.Types) != p.ntypes {
+				return fmt.Errorf("%w: number of types: declared=%d, listed=%d", ErrFormat, p.ntypes, len(h.Types))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no end of header", ErrFormat)
+}
+
+// parseTypes parses a line of the types of I6,9(4X,A2), where the number is
+// blank in the continuation lines.
+func (p *metParser) parseTypes(l string) error {
+	if strings.TrimSpace(l[:6]) != "" {
+		n, err := field.Atoi(l[:6])
+		if err != nil || n < 1 {
+			return fmt.Errorf("%w: number of types: '%s'", ErrFormat, strings.TrimSpace(l[:6]))
+		}
+		p.ntypes = n
+	}
+	h := &p.f.Header
+	for k := 0; k < 9 && len(h.Types) < p.ntypes; k++ {
+		typ := strings.TrimSpace(l[10+6*k : 12+6*k])
+		if typ == "" {
+			return fmt.Errorf("%w: blank type", ErrFormat)
+		}
+		h.Types = append(h.Types, typ)
+	}
+	return nil
+}
+
+// sensor returns the sensor of the type of the header, which is added if
+// new.
+func (p *metParser) sensor(typ string) *MetSensor {
+	h := &p.f.Header
+	for k := range h.Sensors {
+		if h.Sensors[k].Type == typ {
+			return &h.Sensors[k]
+		}
+	}
+	h.Sensors = append(h.Sensors, MetSensor{Type: typ})
+	return &h.Sensors[len(h.Sensors)-1]
+}
+
+// parseEpoch parses an epoch of 1X,I2.2,5(1X,I2) for the version 2 and
+// 1X,I4,5(1X,I2) for the versions 3 and 4 followed by the values of F7.1,
+// where more than 8 values are continued to the lines of 4X,10F7.1:
+//
+//	24  7 14  0  0  0 1007.3   24.1   82.0
+func (p *metParser) parseEpoch() (e MetEpoch, err error) {
+	w := 18
+	if p.v3 {
+		w = 21
+	}
+	l := p.r.line
+	if e.Time, err = parseEpoch(l[:w]); err != nil {
+		return e, err
+	}
+	e.Values = make(map[string]float64, len(p.f.Header.Types))
+
+	from := w
+	for k, typ := range p.f.Header.Types {
+		if k >= metValuesFirst && (k-metValuesFirst)%metValuesCont == 0 {
+			if !p.r.next() {
+				return e, fmt.Errorf("%w: continuation line not found", ErrFormat)
+			}
+			l, from = p.r.line, 4
+		}
+		s := l[from : from+7]
+		from += 7
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		v, err := field.Atof(s)
+		if err != nil {
+			return e, fmt.Errorf("%w: %s of %v: %v", ErrFormat, typ, e.Time, err)
+		}
+		e.Values[typ] = v
+	}
+	return e, nil
+}
+
+// Interpolate returns the value of the observation type at the epoch t
+// interpolated linearly between the epochs of the value before and after t.
+// ErrNoMet is returned if t is out of the epochs of the value, or the
+// interval of the epochs exceeds maxGap.
+func (f *MetFile) Interpolate(typ string, t time.Time, maxGap time.Duration) (float64, error) {
+	i := sort.Search(len(f.Epochs), func(i int) bool { return !f.Epochs[i].Time.Before(t) })
+
+	// the epoch of the value at or after t, and before t
+	next := -1
+	for k := i; k < len(f.Epochs); k++ {
+		if _, ok := f.Epochs[k].Values[typ]; ok {
+			next = k
+			break
+		}
+	}
+	if next >= 0 && f.Epochs[next].Time.Equal(t) {
+		return f.Epochs[next].Values[typ], nil
+	}
+	prev := -1
+	for k := i - 1; k >= 0; k-- {
+		if _, ok := f.Epochs[k].Values[typ]; ok {
+			prev = k
+			break
+		}
+	}
+	if prev < 0 || next < 0 {
+		return 0, fmt.Errorf("%w: %s out of the epochs: %v", ErrNoMet, typ, t)
+	}
+
+	e0, e1 := f.Epochs[prev], f.Epochs[next]
+	if gap := e1.Time.Sub(e0.Time); gap > maxGap {
+		return 0, fmt.Errorf("%w: %s: gap of %v at %v", ErrNoMet, typ, gap, t)
+	}
+	p := float64(t.Sub(e0.Time)) / float64(e1.Time.Sub(e0.Time))
+	return (1-p)*e0.Values[typ] + p*e1.Values[typ], nil
+}
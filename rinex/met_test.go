@@ -0,0 +1,150 @@
+package rinex
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMetFile is the synthetic RINEX 2.11 meteorological file co-located
+// with 0255 every 5 min, where HR of 00:10 is blank, and the data of 00:20
+// to 01:25 are missing.
+const testMetFile = "testdata/02551960_excerpt.24m"
+
+// TestReadMetFile checks the header and the epochs of the fixture.
+func TestReadMetFile(t *testing.T) {
+	f, err := ReadMetFile(testMetFile)
+	if err != nil {
+		t.Fatalf("ReadMetFile: %v", err)
+	}
+
+	h := f.Header
+	if h.Version != 2.11 || h.MarkerName != "0255" || h.MarkerNumber != "21755S001" || strings.Join(h.Types, " ") != "PR TD HR" || len(h.Comments) != 1 {
+		t.Errorf("header: %+v", h)
+	}
+	pr, ok := h.Sensor("PR")
+	want := MetSensor{Type: "PR", Model: "VAISALA", SensorType: "PTB330", Accuracy: 0.1, Pos: [3]float64{-3721695, 3545492, 3763541}, Height: 40, HasPos: true}
+	if !ok || pr != want {
+		t.Errorf("PR sensor: get %+v, want %+v", pr, want)
+	}
+	if hr, ok := h.Sensor("HR"); !ok || hr.Accuracy != 1 || hr.HasPos {
+		t.Errorf("HR sensor: %+v", hr)
+	}
+	if _, ok := h.Sensor("WS"); ok {
+		t.Errorf("WS sensor found")
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if len(f.Epochs) != 6 || !f.Epochs[0].Time.Equal(t0) || !f.Epochs[5].Time.Equal(t0.Add(95*time.Minute)) {
+		t.Fatalf("epochs: %+v", f.Epochs)
+	}
+	if v := f.Epochs[0].Values; v["PR"] != 1007.3 || v["TD"] != 24.1 || v["HR"] != 82 {
+		t.Errorf("first epoch: %v", v)
+	}
+	if v := f.Epochs[2].Values; len(v) != 2 {
+		t.Errorf("blank HR: %v", v)
+	}
+}
+
+// TestParseMet3 checks the epochs of RINEX 3 continued to the next line,
+// where RI is blank.
+func TestParseMet3(t *testing.T) {
+	types := strings.Fields("PR TD HR ZW ZD ZT WD WS RI HI")
+	s := "     3.04           METEOROLOGICAL DATA                     RINEX VERSION / TYPE\n" +
+		"    10    PR    TD    HR    ZW    ZD    ZT    WD    WS    RI# / TYPES OF OBSERV\n" +
+		"          HI                                                # / TYPES OF OBSERV\n" +
+		"                                                            END OF HEADER\n" +
+		" 2024  7 14  0  0  0 1007.3   24.1   82.0    0.2    2.3    2.5  180.0    3.5\n" +
+		"              12.0\n"
+	f, err := ParseMet(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("ParseMet: %v", err)
+	}
+	if strings.Join(f.Header.Types, " ") != strings.Join(types, " ") || len(f.Epochs) != 1 {
+		t.Fatalf("%+v", f)
+	}
+	v := f.Epochs[0].Values
+	if !f.Epochs[0].Time.Equal(time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)) || v["WS"] != 3.5 || v["HI"] != 12 || len(v) != 9 {
+		t.Errorf("epoch: %v %v", f.Epochs[0].Time, v)
+	}
+}
+
+// TestParseMetErrors checks the files modified.
+func TestParseMetErrors(t *testing.T) {
+	data, err := os.ReadFile(testMetFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		old, new string
+	}{
+		{"file type", "METEOROLOGICAL DATA", "OBSERVATION DATA   "},
+		{"version", "     2.11", "     1.00"},
+		{"number of types", "     3    PR", "     4    PR"},
+		{"sensor position", "       40.0000 PR", "       4x.0000 PR"},
+		{"no header end", "END OF HEADER", "END OF HEAD"},
+		{"value", "1007.4   24.0", "1007.4   2x.0"},
+		{"epoch", " 24  7 14  0  5  0", " 24  7 14  0  x  0"},
+		{"order", " 24  7 14  1 30  0", " 24  7 14  0 15  0"},
+	} {
+		s := strings.Replace(string(data), tt.old, tt.new, 1)
+		if s == string(data) {
+			t.Fatalf("%s: not modified", tt.name)
+		}
+		if _, err := ParseMet(strings.NewReader(s)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrFormat)
+		}
+	}
+}
+
+// TestInterpolate checks the values interpolated, skipping the blank value,
+// and the gaps of the data.
+func TestInterpolate(t *testing.T) {
+	f, err := ReadMetFile(testMetFile)
+	if err != nil {
+		t.Fatalf("ReadMetFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		typ  string
+		min  float64
+		want float64
+	}{
+		{"PR", 0, 1007.3},
+		{"PR", 2.5, 1007.35},
+		{"TD", 14, 23.82},
+		{"HR", 10, 83},
+		{"HR", 12.5, 83.25},
+		{"PR", 95, 1008.3},
+	} {
+		at := t0.Add(time.Duration(tt.min * float64(time.Minute)))
+		if get, err := f.Interpolate(tt.typ, at, 10*time.Minute); err != nil || math.Abs(get-tt.want) > 1e-9 {
+			t.Errorf("%s at %v min: get %v (err=%v), want %v", tt.typ, tt.min, get, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		typ    string
+		min    float64
+		maxGap time.Duration
+	}{
+		{"gap", "PR", 30, 30 * time.Minute},
+		{"blank", "HR", 10, 5 * time.Minute},
+		{"before", "PR", -1, time.Hour},
+		{"after", "PR", 96, time.Hour},
+		{"type", "WS", 5, time.Hour},
+	} {
+		at := t0.Add(time.Duration(tt.min * float64(time.Minute)))
+		if _, err := f.Interpolate(tt.typ, at, tt.maxGap); !errors.Is(err, ErrNoMet) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrNoMet)
+		}
+	}
+	if _, err := f.Interpolate("PR", t0.Add(30*time.Minute), 2*time.Hour); err != nil {
+		t.Errorf("gap within maxGap: %v", err)
+	}
+}
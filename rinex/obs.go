@@ -0,0 +1,346 @@
+package rinex
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// Obs is an observation value with the loss of lock indicator (LLI) and the
+// signal strength indicator (SSI), which are zero if blank.
+type Obs struct {
+	Value float64
+	LLI   int
+	SSI   int
+}
+
+// SatObs stores the observations of a satellite keyed by the observation
+// codes of the file, e.g., "C1" for RINEX 2 and "C1C" for RINEX 3 and 4. The
+// blank fields are not stored.
+type SatObs map[string]Obs
+
+// Epoch stores the observations of an epoch.
+type Epoch struct {
+	Time time.Time
+
+	// Flag is the epoch flag, where 0 is OK, 1 is the power failure, and the
+	// others are the events (see FlagMoving and the others).
+	Flag int
+
+	// ClockOffset is the receiver clock offset (s) if HasClockOffset.
+	ClockOffset    float64
+	HasClockOffset bool
+
+	// Sats is the satellites in the order of the file, and Obs stores the
+	// observations of the satellites.
+	Sats []string
+	Obs  map[string]SatObs
+
+	// HeaderLines is the header lines of the events of the flags 2 to 5,
+	// which are applied to ObsReader.Header, e.g., the antenna changed.
+	HeaderLines []string
+}
+
+// Value returns the observation value of the code of the satellite id, and
+// false if not observed.
+func (e *Epoch) Value(id, code string) (float64, bool) {
+	o, ok := e.Obs[id][code]
+	return o.Value, ok
+}
+
+// ObsHeader stores the header of a RINEX observation file.
+type ObsHeader struct {
+	// Version is the format version, e.g., 2.11, and SatSystem is the
+	// satellite system, e.g., 'G' or 'M' for the mixed file.
+	Version   float64
+	SatSystem byte
+
+	MarkerName string
+
+	// ApproxPos is the approximate marker position (ECEF, m), and AntDelta
+	// is the antenna height and the eccentricities (m) of the east and the
+	// north from the marker.
+	ApproxPos [3]float64
+	AntDelta  [3]float64
+
+	// AntType is the antenna type including the radome as in the file,
+	// e.g., "TRM59800.80     GSI", and AntName and AntRadome are the name and
+	// the radome of the type, where the radome is blank if not given (see
+	// ANTEXType).
+	AntNumber string
+	AntType   string
+	AntName   string
+	AntRadome string
+
+	RecNumber  string
+	RecType    string
+	RecVersion string
+
+	// Interval is the observation interval, or zero if not given.
+	Interval time.Duration
+
+	// Types stores the observation codes keyed by the satellite systems.
+	// The codes of RINEX 2 are common to the systems, and are stored with
+	// the key ' '. See ObsTypes.
+	Types map[byte][]string
+
+	// FirstObs and LastObs are the epochs of the first and the last
+	// observations, where LastObs is zero if not given, and TimeSystem is the
+	// time system of the epochs, e.g., "GPS".
+	FirstObs   time.Time
+	LastObs    time.Time
+	TimeSystem string
+
+	Comments []string
+
+	// Others stores the lines of the other labels keyed by the labels in the
+	// order of the file, including the continuation lines.
+	Others map[string][]string
+}
+
+// ANTEXType returns the antenna type in the form of ANTEX, i.e., the name
+// padded to 16 characters and the radome, which is "NONE" if blank.
+func (h *ObsHeader) ANTEXType() string {
+	radome := h.AntRadome
+	if radome == "" {
+		radome = "NONE"
+	}
+	return fmt.Sprintf("%-16s%s", h.AntName, radome)
+}
+
+// ObsTypes returns the observation codes of the satellite system sys.
+func (h *ObsHeader) ObsTypes(sys byte) []string {
+	if types, ok := h.Types[sys]; ok {
+		return types
+	}
+	return h.Types[' ']
+}
+
+// ObsReader reads the epochs of a RINEX observation file one by one.
+type ObsReader struct {
+	// Header is the header of the file, which is updated by the header lines
+	// of the events read.
+	Header ObsHeader
+
+	r      lineReader
+	v3     bool         // RINEX 3 or 4
+	ntypes map[byte]int // declared numbers of the types
+	sys    byte         // system of the last types line of RINEX 3
+}
+
+// NewObsReader reads the header of a RINEX observation file of the versions
+// 2, 3 or 4 from r, and returns the reader of the epochs. The epochs are read
+// into the same Epoch regardless of the version. The Hatanaka-compressed
+// files (CRINEX) are detected and decompressed by NewDecompressReader.
+//
+// The errors are wrapped with the line number of RINEX, and the format
+// errors are tested by errors.Is with ErrFormat.
+func NewObsReader(r io.Reader) (*ObsReader, error) {
+	br := bufio.NewReader(r)
+	if first, _ := br.Peek(80); strings.Contains(string(first), crinexLabel) {
+		r = NewDecompressReader(br)
+	} else {
+		r = br
+	}
+
+	or := &ObsReader{r: lineReader{s: mscanner.NewScanner(r)}}
+	if err := or.parseHeader(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", or.r.s.LineNumber(), err)
+	}
+	return or, nil
+}
+
+// Next returns the next epoch, and io.EOF at the end of the file.
+func (r *ObsReader) Next() (*Epoch, error) {
+	var e *Epoch
+	var err error
+	if r.v3 {
+		e, err = r.readEpoch3()
+	} else {
+		e, err = r.readEpoch2()
+	}
+	if err == nil && e == nil {
+		if err = r.r.s.Err(); err == nil {
+			return nil, io.EOF
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.r.s.LineNumber(), err)
+	}
+	return e, nil
+}
+
+// parseHeader parses the header. The observation types are parsed by the
+// version of the first line.
+func (r *ObsReader) parseHeader() (err error) {
+	h := &r.Header
+	if !r.r.next() || label(r.r.line) != "RINEX VERSION / TYPE" {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(r.r.line))
+	}
+	if h.Version, err = field.Atof(r.r.line[:9]); err != nil {
+		return fmt.Errorf("%w: version: %v", ErrFormat, err)
+	}
+	if r.r.line[20] != 'O' {
+		return fmt.Errorf("%w: file type: '%c'", ErrFormat, r.r.line[20])
+	}
+	h.SatSystem = r.r.line[40]
+
+	switch {
+	case h.Version < 2 || h.Version >= 5:
+		return fmt.Errorf("%w: unsupported version: %.2f", ErrFormat, h.Version)
+	case h.Version >= 3:
+		r.v3 = true
+	}
+
+	h.Types = make(map[byte][]string)
+	h.Others = make(map[string][]string)
+	r.ntypes = make(map[byte]int)
+	for r.r.next() {
+		if label(r.r.line) == "END OF HEADER" {
+			return r.checkHeader()
+		}
+		if err := r.parseHeaderLine(r.r.line); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: no end of header", ErrFormat)
+}
+
+// parseHeaderLine parses a header line other than the first and the last
+// lines, which is also used for the header lines of the events.
+func (r *ObsReader) parseHeaderLine(l string) (err error) {
+	h := &r.Header
+	switch label(l) {
+	case "MARKER NAME":
+		h.MarkerName = strings.TrimSpace(l[:60])
+	case "APPROX POSITION XYZ":
+		if h.ApproxPos, err = parse3F14(l); err != nil {
+			return fmt.Errorf("%w: approx position: %v", ErrFormat, err)
+		}
+	case "ANTENNA: DELTA H/E/N":
+		if h.AntDelta, err = parse3F14(l); err != nil {
+			return fmt.Errorf("%w: antenna delta: %v", ErrFormat, err)
+		}
+	case "ANT # / TYPE":
+		h.AntNumber = strings.TrimSpace(l[:20])
+		h.AntType = strings.TrimSpace(l[20:40])
+		h.AntName = strings.TrimSpace(l[20:36])
+		h.AntRadome = strings.TrimSpace(l[36:40])
+	case "REC # / TYPE / VERS":
+		h.RecNumber = strings.TrimSpace(l[:20])
+		h.RecType = strings.TrimSpace(l[20:40])
+		h.RecVersion = strings.TrimSpace(l[40:60])
+	case "INTERVAL":
+		v, err := field.Atof(l[:10])
+		if err != nil {
+			return fmt.Errorf("%w: interval: %v", ErrFormat, err)
+		}
+		h.Interval = time.Duration(math.Round(v * 1e9))
+	case "# / TYPES OF OBSERV":
+		if r.v3 {
+			return fmt.Errorf("%w: '%s' of version %.2f", ErrFormat, label(l), h.Version)
+		}
+		if err = r.parseTypes2(l); err != nil {
+			return err
+		}
+	case "SYS / # / OBS TYPES":
+		if !r.v3 {
+			return fmt.Errorf("%w: '%s' of version %.2f", ErrFormat, label(l), h.Version)
+		}
+		if err = r.parseTypes3(l); err != nil {
+			return err
+		}
+	case "TIME OF FIRST OBS":
+		if h.FirstObs, err = parseEpoch(l[:43]); err != nil {
+			return err
+		}
+		h.TimeSystem = strings.TrimSpace(l[48:51])
+	case "TIME OF LAST OBS":
+		if h.LastObs, err = parseEpoch(l[:43]); err != nil {
+			return err
+		}
+		switch ts := strings.TrimSpace(l[48:51]); {
+		case h.TimeSystem == "":
+			h.TimeSystem = ts
+		case ts != "" && ts != h.TimeSystem:
+			return fmt.Errorf("%w: time systems of the first and the last obs: %s, %s", ErrFormat, h.TimeSystem, ts)
+		}
+	case "COMMENT":
+		h.Comments = append(h.Comments, strings.TrimRight(l[:60], " "))
+	case "":
+		// blank lines
+	default:
+		h.Others[label(l)] = append(h.Others[label(l)], strings.TrimRight(l, " "))
+	}
+	return nil
+}
+
+// timeSystems is the default time systems of the satellite systems.
+var timeSystems = map[byte]string{
+	' ': "GPS", 'G': "GPS", 'R': "GLO", 'E': "GAL", 'C': "BDT", 'J': "QZS", 'I': "IRN",
+}
+
+// checkHeader checks the numbers of the types, and sets the default time
+// system.
+func (r *ObsReader) checkHeader() error {
+	h := &r.Header
+	if len(r.ntypes) == 0 {
+		return fmt.Errorf("%w: no observation type", ErrFormat)
+	}
+	for sys, n := range r.ntypes {
+		if len(h.Types[sys]) != n {
+			return fmt.Errorf("%w: number of types of '%c': declared=%d, listed=%d", ErrFormat, sys, n, len(h.Types[sys]))
+		}
+	}
+	if h.TimeSystem == "" {
+		h.TimeSystem = timeSystems[h.SatSystem]
+	}
+	return nil
+}
+
+// ObsFile stores the contents of a RINEX observation file.
+type ObsFile struct {
+	Header ObsHeader
+	Epochs []*Epoch
+}
+
+// ReadObsFile reads the RINEX observation file of the name.
+func ReadObsFile(name string) (*ObsFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseObs(f)
+}
+
+// ParseObs reads all the epochs of a RINEX observation file from r by
+// ObsReader.
+func ParseObs(r io.Reader) (*ObsFile, error) {
+	or, err := NewObsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ObsFile{Header: or.Header}
+	for {
+		e, err := or.Next()
+		if errors.Is(err, io.EOF) {
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.Epochs = append(f.Epochs, e)
+	}
+}
@@ -0,0 +1,146 @@
+package rinex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+)
+
+// obsPerLine2 is the number of the observations in a line of RINEX 2.
+const obsPerLine2 = 5
+
+// satsPerLine2 is the number of the satellites in an epoch line of RINEX 2.
+const satsPerLine2 = 12
+
+// parseTypes2 parses a "# / TYPES OF OBSERV" line, which is continued with
+// the blank number of the types.
+func (r *ObsReader) parseTypes2(l string) (err error) {
+	h := &r.Header
+	if n := strings.TrimSpace(l[:6]); n != "" {
+		if r.ntypes[' '], err = field.Atoi(n); err != nil {
+			return fmt.Errorf("%w: number of types: %v", ErrFormat, err)
+		}
+	}
+	for k := 0; k < 9 && len(h.Types[' ']) < r.ntypes[' ']; k++ {
+		h.Types[' '] = append(h.Types[' '], strings.TrimSpace(l[10+6*k:12+6*k]))
+	}
+	return nil
+}
+
+// readEpoch2 reads an epoch of RINEX 2, and returns nil at the end of the
+// file:
+//
+//	24  7 14  0  0  0.0000000  0 14G14G04G22G06G17G03G21G19G02R01R02R08
+//	                               R09R10
+//
+// The header lines of the events (the flags 2 to 5) and of the files
+// concatenated are read into the events of the epochs (see readEvent).
+func (r *ObsReader) readEpoch2() (*Epoch, error) {
+	for r.r.next() {
+		l := r.r.line
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if label(l) == "RINEX VERSION / TYPE" {
+			return r.readSplicedHeader(l)
+		}
+
+		e := &Epoch{}
+		flag, err := field.Atoi(l[28:29])
+		if err != nil || flag > FlagCycleSlip {
+			return nil, fmt.Errorf("%w: epoch flag: '%c'", ErrFormat, l[28])
+		}
+		e.Flag = flag
+		nsat, err := field.Atoi(l[29:32])
+		if err != nil {
+			return nil, fmt.Errorf("%w: number of satellites: %v", ErrFormat, err)
+		}
+		if strings.TrimSpace(l[:26]) != "" || flag <= FlagPowerFailure || flag == FlagCycleSlip {
+			if e.Time, err = parseEpoch(l[:26]); err != nil {
+				return nil, err
+			}
+		}
+
+		if e.IsEvent() {
+			// nsat is the number of the following header lines
+			return e, r.readEvent(e, nsat)
+		}
+
+		if s := strings.TrimSpace(l[68:80]); s != "" {
+			if e.ClockOffset, err = field.Atof(s); err != nil {
+				return nil, fmt.Errorf("%w: clock offset: %v", ErrFormat, err)
+			}
+			e.HasClockOffset = true
+		}
+
+		// satellites continued to the next lines
+		for k := 0; k < nsat; k++ {
+			if k > 0 && k%satsPerLine2 == 0 {
+				if !r.r.next() {
+					return nil, fmt.Errorf("%w: satellite list not found", ErrFormat)
+				}
+				l = r.r.line
+			}
+			c := 32 + 3*(k%satsPerLine2)
+			id, err := satID(l[c:c+3], r.Header.SatSystem)
+			if err != nil {
+				return nil, err
+			}
+			e.Sats = append(e.Sats, id)
+		}
+
+		types := r.Header.ObsTypes(' ')
+		e.Obs = make(map[string]SatObs, nsat)
+		for _, id := range e.Sats {
+			obs := make(SatObs, len(types))
+			for k, code := range types {
+				if k%obsPerLine2 == 0 {
+					if !r.r.next() {
+						return nil, fmt.Errorf("%w: observations of %s not found", ErrFormat, id)
+					}
+				}
+				c := 16 * (k % obsPerLine2)
+				o, ok, err := parseObs(r.r.line[c : c+16])
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s of %s: %v", ErrFormat, code, id, err)
+				}
+				if ok {
+					obs[code] = o
+				}
+			}
+			e.Obs[id] = obs
+		}
+		return e, nil
+	}
+
+	return nil, nil
+}
+
+// parseObs parses an observation field of F14.3, I1 (LLI) and I1 (SSI), and
+// returns false if the value is blank.
+func parseObs(s string) (o Obs, ok bool, err error) {
+	if strings.TrimSpace(s[:14]) == "" {
+		return o, false, nil
+	}
+	if o.Value, err = field.Atof(s[:14]); err != nil {
+		return o, false, err
+	}
+	if o.LLI, err = field.AtoiBlank(s[14:15]); err != nil {
+		return o, false, err
+	}
+	if o.SSI, err = field.AtoiBlank(s[15:16]); err != nil {
+		return o, false, err
+	}
+	return o, true, nil
+}
+
+// parse3F14 parses the three values of F14.4 of a header line.
+func parse3F14(l string) (v [3]float64, err error) {
+	for c := range 3 {
+		if v[c], err = field.AtofBlank(l[14*c : 14*c+14]); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
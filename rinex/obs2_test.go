@@ -0,0 +1,145 @@
+package rinex
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testObs2File is the hand-made RINEX 2.11 observation file of the GEONET
+// site 0255, where C1 of GPS at 00:00 are the pseudoranges used in the
+// bancroft tests and the other observations are synthetic.
+const testObs2File = "testdata/02551960_excerpt.24o"
+
+// readTestLines returns the lines of the fixture.
+func readTestLines(t *testing.T, name string) []string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+}
+
+// TestReadObsFile2 checks the header and the epochs of the fixture.
+func TestReadObsFile2(t *testing.T) {
+	f, err := ReadObsFile(testObs2File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+
+	h := f.Header
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if h.Version != 2.11 || h.SatSystem != 'M' || h.MarkerName != "0255" || !h.FirstObs.Equal(t0) || h.TimeSystem != "GPS" {
+		t.Errorf("header: %+v", h)
+	}
+	if h.ApproxPos != [3]float64{-3721695.1985, 3545492.6126, 3763541.7139} || h.AntDelta != [3]float64{} {
+		t.Errorf("positions: %v %v", h.ApproxPos, h.AntDelta)
+	}
+	if h.AntNumber != "1441041254" || h.AntType != "TRM59800.80     GSI" {
+		t.Errorf("antenna: '%s' '%s'", h.AntNumber, h.AntType)
+	}
+	types := []string{"C1", "L1", "L2", "P2", "C2", "C5", "L5", "D1", "D2", "S1", "S2"}
+	if !slices.Equal(h.ObsTypes('G'), types) || !slices.Equal(h.ObsTypes('R'), types) {
+		t.Errorf("types: %v", h.Types)
+	}
+
+	if len(f.Epochs) != 3 {
+		t.Fatalf("number of epochs: %d", len(f.Epochs))
+	}
+
+	// the pseudoranges of the bancroft tests
+	e := f.Epochs[0]
+	ids := []string{"G14", "G04", "G22", "G06", "G17", "G03", "G21", "G19", "G02", "R01", "R02", "R08", "R09", "R10"}
+	if !e.Time.Equal(t0) || e.Flag != 0 || e.HasClockOffset || !slices.Equal(e.Sats, ids) || len(e.Obs) != len(ids) {
+		t.Fatalf("epoch: %v, flag=%d, sats=%v", e.Time, e.Flag, e.Sats)
+	}
+	pr := []float64{20969460.336, 24172308.906, 21337486.820, 22889983.813, 20931837.352, 20333361.633, 24731230.883, 22113357.180, 23285127.484}
+	for i, want := range pr {
+		if v, ok := e.Value(ids[i], "C1"); !ok || v != want {
+			t.Errorf("C1 of %s: get %f, want %f", ids[i], v, want)
+		}
+	}
+
+	// blank fields, the signal strength, and the values of the continued lines
+	if o := e.Obs["G14"]; o["L1"] != (Obs{Value: 109221593.806, SSI: 6}) || o["S2"].Value != 36.941 || o["D1"].Value != -2606.827 {
+		t.Errorf("G14: %+v", o)
+	}
+	if _, ok := e.Value("G14", "C5"); ok {
+		t.Errorf("blank C5 of G14 stored")
+	}
+	if o := e.Obs["G04"]; len(o) != len(types) || o["L5"] != (Obs{Value: 94857332.310, SSI: 7}) {
+		t.Errorf("G04: %+v", o)
+	}
+
+	// event of the flag 4 with the header lines
+	if e := f.Epochs[1]; e.Flag != 4 || !e.Time.IsZero() || len(e.Sats) != 0 {
+		t.Errorf("event: %+v", e)
+	}
+
+	// clock offset and loss of lock
+	e = f.Epochs[2]
+	if !e.Time.Equal(t0.Add(30*time.Second)) || len(e.Sats) != 9 || !e.HasClockOffset || e.ClockOffset != 0.000123456 {
+		t.Errorf("epoch: %v, sats=%v, clock=%v", e.Time, e.Sats, e.ClockOffset)
+	}
+	if o := e.Obs["G22"]["L1"]; o.LLI != 1 || o.SSI == 0 {
+		t.Errorf("L1 of G22: %+v", o)
+	}
+}
+
+// TestObsReader2 checks the epochs are read one by one until io.EOF.
+func TestObsReader2(t *testing.T) {
+	r, err := os.Open(testObs2File)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	or, err := NewObsReader(r)
+	if err != nil {
+		t.Fatalf("NewObsReader: %v", err)
+	}
+	var n int
+	for {
+		_, err := or.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("Next: %v", err)
+			}
+			break
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("number of epochs: %d", n)
+	}
+}
+
+// TestParseObs2Error checks the format errors with the line numbers.
+func TestParseObs2Error(t *testing.T) {
+	orig := readTestLines(t, testObs2File)
+	for _, tt := range []struct {
+		name string
+		mod  func(l []string) []string
+		line string
+	}{
+		{"file type", func(l []string) []string { l[0] = strings.Replace(l[0], "OBSERVATION", "NAVIGATION ", 1); return l }, "line 1:"},
+		{"number of types", func(l []string) []string { return slices.Delete(l, 12, 13) }, "line 15:"},
+		{"no end of header", func(l []string) []string { return l[:15] }, "line 15:"},
+		{"epoch", func(l []string) []string { l[16] = " 24  x" + l[16][6:]; return l }, "line 17:"},
+		{"satellite", func(l []string) []string { l[16] = l[16][:32] + "GXX" + l[16][35:]; return l }, "line 17:"},
+		{"satellite list", func(l []string) []string { return l[:17] }, "line 17:"},
+		{"value", func(l []string) []string { l[18] = "x" + l[18][1:]; return l }, "line 19:"},
+		{"observations", func(l []string) []string { return l[:30] }, "line 30:"},
+		{"event", func(l []string) []string { return l[:61] }, "line 61:"},
+	} {
+		_, err := ParseObs(strings.NewReader(strings.Join(tt.mod(slices.Clone(orig)), "\n")))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: err=%v, want %s", tt.name, err, tt.line)
+		}
+	}
+}
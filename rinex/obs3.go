@@ -0,0 +1,129 @@
+package rinex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+)
+
+// typesPerLine3 is the number of the types in a "SYS / # / OBS TYPES" line.
+const typesPerLine3 = 13
+
+// parseTypes3 parses a "SYS / # / OBS TYPES" line, which is continued with
+// the blank system:
+//
+//	G   14 C1C L1C D1C S1C C2W L2W D2W S2W C2L L2L C5Q L5Q D5Q  SYS / # / OBS TYPES
+//	       S5Q                                                  SYS / # / OBS TYPES
+func (r *ObsReader) parseTypes3(l string) (err error) {
+	h := &r.Header
+	if l[0] != ' ' {
+		r.sys = l[0]
+		if _, ok := r.ntypes[r.sys]; ok {
+			return fmt.Errorf("%w: types of '%c' duplicated", ErrFormat, r.sys)
+		}
+		if r.ntypes[r.sys], err = field.Atoi(l[3:6]); err != nil {
+			return fmt.Errorf("%w: number of types of '%c': %v", ErrFormat, r.sys, err)
+		}
+	}
+	if r.sys == 0 {
+		return fmt.Errorf("%w: system of the types not found", ErrFormat)
+	}
+
+	for k := 0; k < typesPerLine3 && len(h.Types[r.sys]) < r.ntypes[r.sys]; k++ {
+		code := strings.TrimSpace(l[7+4*k : 10+4*k])
+		if len(code) != 3 {
+			return fmt.Errorf("%w: type of '%c': '%s'", ErrFormat, r.sys, code)
+		}
+		h.Types[r.sys] = append(h.Types[r.sys], code)
+	}
+	return nil
+}
+
+// readEpoch3 reads an epoch of RINEX 3 or 4, and returns nil at the end of
+// the file:
+//
+//	> 2024 07 14 00 00  0.0000000  0 17
+//	G14  20969460.336 6 109221593.806 6     -2606.827          41.112 ...
+//
+// The header lines of the events (the flags 2 to 5) and of the files
+// concatenated are read into the events of the epochs (see readEvent).
+func (r *ObsReader) readEpoch3() (*Epoch, error) {
+	for r.r.next() {
+		l := r.r.line
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if label(l) == "RINEX VERSION / TYPE" {
+			return r.readSplicedHeader(l)
+		}
+		if l[0] != '>' {
+			return nil, fmt.Errorf("%w: epoch line not found: '%s'", ErrFormat, strings.TrimSpace(l))
+		}
+
+		e := &Epoch{}
+		flag, err := field.Atoi(l[31:32])
+		if err != nil || flag > FlagCycleSlip {
+			return nil, fmt.Errorf("%w: epoch flag: '%c'", ErrFormat, l[31])
+		}
+		e.Flag = flag
+		nsat, err := field.Atoi(l[32:35])
+		if err != nil {
+			return nil, fmt.Errorf("%w: number of satellites: %v", ErrFormat, err)
+		}
+		if strings.TrimSpace(l[2:29]) != "" || flag <= FlagPowerFailure || flag == FlagCycleSlip {
+			if e.Time, err = parseEpoch(l[2:29]); err != nil {
+				return nil, err
+			}
+		}
+
+		if e.IsEvent() {
+			// nsat is the number of the following header lines
+			return e, r.readEvent(e, nsat)
+		}
+
+		if s := strings.TrimSpace(l[41:56]); s != "" {
+			if e.ClockOffset, err = field.Atof(s); err != nil {
+				return nil, fmt.Errorf("%w: clock offset: %v", ErrFormat, err)
+			}
+			e.HasClockOffset = true
+		}
+
+		e.Obs = make(map[string]SatObs, nsat)
+		for range nsat {
+			if !r.r.next() {
+				return nil, fmt.Errorf("%w: observations not found", ErrFormat)
+			}
+			id, err := satID(r.r.line[:3], r.Header.SatSystem)
+			if err != nil {
+				return nil, err
+			}
+			types, ok := r.Header.Types[id[0]]
+			if !ok {
+				return nil, fmt.Errorf("%w: no observation type of %s", ErrFormat, id)
+			}
+			if _, ok := e.Obs[id]; ok {
+				return nil, fmt.Errorf("%w: satellite duplicated: %s", ErrFormat, id)
+			}
+
+			// the line is as long as the observations
+			l := fmt.Sprintf("%-*s", 3+16*len(types), r.r.line)
+			obs := make(SatObs, len(types))
+			for k, code := range types {
+				c := 3 + 16*k
+				o, ok, err := parseObs(l[c : c+16])
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s of %s: %v", ErrFormat, code, id, err)
+				}
+				if ok {
+					obs[code] = o
+				}
+			}
+			e.Sats = append(e.Sats, id)
+			e.Obs[id] = obs
+		}
+		return e, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,197 @@
+package rinex
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testObs3File is the hand-made RINEX 3.04 observation file of the GEONET
+// site 0255 with GPS, GLONASS, Galileo and BeiDou, where C1C of GPS at 00:00
+// are real and the other observations are synthetic.
+const testObs3File = "testdata/02551960_excerpt.rnx"
+
+// TestReadObsFile3 checks the header and the epochs of the fixture.
+func TestReadObsFile3(t *testing.T) {
+	f, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+
+	h := f.Header
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if h.Version != 3.04 || h.SatSystem != 'M' || h.MarkerName != "0255" || !h.FirstObs.Equal(t0) || h.TimeSystem != "GPS" {
+		t.Errorf("header: %+v", h)
+	}
+	if h.ApproxPos != [3]float64{-3721695.1985, 3545492.6126, 3763541.7139} || h.AntType != "TRM59800.80     GSI" {
+		t.Errorf("header: %+v", h)
+	}
+
+	// continued types of GPS
+	gps := strings.Fields("C1C L1C D1C S1C C2W L2W D2W S2W C2L L2L C5Q L5Q D5Q S5Q")
+	if !slices.Equal(h.ObsTypes('G'), gps) || len(h.ObsTypes('R')) != 8 || len(h.ObsTypes('E')) != 12 || len(h.ObsTypes('C')) != 12 {
+		t.Errorf("types: %v", h.Types)
+	}
+	if h.ObsTypes('J') != nil {
+		t.Errorf("types of QZSS: %v", h.ObsTypes('J'))
+	}
+
+	if len(f.Epochs) != 3 {
+		t.Fatalf("number of epochs: %d", len(f.Epochs))
+	}
+
+	// all the systems in the first epoch
+	e := f.Epochs[0]
+	if !e.Time.Equal(t0) || e.Flag != 0 || e.HasClockOffset || len(e.Sats) != 17 || e.Sats[0] != "G14" || e.Sats[16] != "C30" {
+		t.Fatalf("epoch: %v, flag=%d, sats=%v", e.Time, e.Flag, e.Sats)
+	}
+	systems := make(map[byte]int)
+	for _, id := range e.Sats {
+		systems[id[0]]++
+	}
+	if systems['G'] != 9 || systems['R'] != 2 || systems['E'] != 3 || systems['C'] != 3 {
+		t.Errorf("systems: %v", systems)
+	}
+	if o := e.Obs["G14"]; len(o) != len(gps)-4 || o["C1C"] != (Obs{Value: 20969460.336, SSI: 5}) || o["S1C"].Value != 35.018 {
+		t.Errorf("G14: %+v", o)
+	}
+	if _, ok := e.Value("G14", "C5Q"); ok {
+		t.Errorf("blank C5Q of G14 stored")
+	}
+	if _, ok := e.Value("G04", "S5Q"); !ok {
+		t.Errorf("last value of G04 not stored")
+	}
+	if _, ok := e.Value("C20", "C6I"); ok {
+		t.Errorf("blank C6I of C20 stored")
+	}
+
+	// event of the flag 4 with the header lines
+	if e := f.Epochs[1]; e.Flag != 4 || !e.Time.IsZero() || len(e.Sats) != 0 {
+		t.Errorf("event: %+v", e)
+	}
+
+	// clock offset and loss of lock
+	e = f.Epochs[2]
+	if !e.Time.Equal(t0.Add(30*time.Second)) || len(e.Sats) != 14 || !e.HasClockOffset || e.ClockOffset != -0.000012345678 {
+		t.Errorf("epoch: %v, sats=%v, clock=%v", e.Time, e.Sats, e.ClockOffset)
+	}
+	if o := e.Obs["E11"]["L1X"]; o.LLI != 1 {
+		t.Errorf("L1X of E11: %+v", o)
+	}
+}
+
+// TestObsVersions checks the RINEX 2 and 3 fixtures give the same
+// pseudoranges in the same data model.
+func TestObsVersions(t *testing.T) {
+	f2, err := ReadObsFile(testObs2File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	f3, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	e2, e3 := f2.Epochs[0], f3.Epochs[0]
+	for _, id := range e2.Sats {
+		if id[0] != 'G' {
+			continue
+		}
+		v2, ok2 := e2.Value(id, "C1")
+		v3, ok3 := e3.Value(id, "C1C")
+		if !ok2 || !ok3 || v2 != v3 {
+			t.Errorf("%s: C1=%f, C1C=%f", id, v2, v3)
+		}
+	}
+}
+
+// TestObsHeader checks the metadata of the header of the fixture and those
+// of the lines inserted.
+func TestObsHeader(t *testing.T) {
+	f, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	h := f.Header
+	if h.AntName != "TRM59800.80" || h.AntRadome != "GSI" || h.ANTEXType() != "TRM59800.80     GSI" {
+		t.Errorf("antenna: '%s' '%s' '%s'", h.AntName, h.AntRadome, h.ANTEXType())
+	}
+	if h.RecNumber != "3326579" || h.RecType != "TRIMBLE ALLOY" || h.RecVersion != "6.15" {
+		t.Errorf("receiver: '%s' '%s' '%s'", h.RecNumber, h.RecType, h.RecVersion)
+	}
+	if h.Interval != 30*time.Second || !h.LastObs.IsZero() {
+		t.Errorf("interval=%v, last=%v", h.Interval, h.LastObs)
+	}
+	if !slices.Equal(h.Others["SIGNAL STRENGTH UNIT"], []string{"    DBHZ                                                    SIGNAL STRENGTH UNIT"}) {
+		t.Errorf("others: %q", h.Others)
+	}
+	if _, ok := h.Others["COMMENT"]; ok {
+		t.Errorf("others: %q", h.Others)
+	}
+
+	// the antenna without the radome, the last epoch and the continued
+	// lines of an unknown label
+	lines := readTestLines(t, testObs3File)
+	lines[7] = "1441041254          TRM57971.00                             ANT # / TYPE"
+	lines = slices.Insert(lines, 18,
+		"  2024     7    14     0     1    0.0000000                 TIME OF LAST OBS",
+		"  3 R01  1 R02 -4 R08  6                                    GLONASS SLOT / FRQ #",
+		"COMMENT BETWEEN THE CONTINUED LINES                         COMMENT",
+		"    R09 -2                                                  GLONASS SLOT / FRQ #")
+	f, err = ParseObs(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("ParseObs: %v", err)
+	}
+	h = f.Header
+	if h.AntName != "TRM57971.00" || h.AntRadome != "" || h.ANTEXType() != "TRM57971.00     NONE" {
+		t.Errorf("antenna: '%s' '%s' '%s'", h.AntName, h.AntRadome, h.ANTEXType())
+	}
+	if want := time.Date(2024, 7, 14, 0, 1, 0, 0, time.UTC); !h.LastObs.Equal(want) || h.TimeSystem != "GPS" {
+		t.Errorf("last=%v %s", h.LastObs, h.TimeSystem)
+	}
+	if slot := h.Others["GLONASS SLOT / FRQ #"]; len(slot) != 2 || !strings.HasPrefix(slot[1], "    R09 -2") {
+		t.Errorf("slots: %q", slot)
+	}
+	if len(h.Comments) != 2 {
+		t.Errorf("comments: %q", h.Comments)
+	}
+	if len(f.Epochs) != 3 {
+		t.Errorf("number of epochs: %d", len(f.Epochs))
+	}
+}
+
+// TestParseObs3Error checks the format errors with the line numbers.
+func TestParseObs3Error(t *testing.T) {
+	orig := readTestLines(t, testObs3File)
+	for _, tt := range []struct {
+		name string
+		mod  func(l []string) []string
+		line string
+	}{
+		{"version", func(l []string) []string { l[0] = "     5.00" + l[0][9:]; return l }, "line 1:"},
+		{"types of version 2", func(l []string) []string {
+			l[11] = strings.Replace(l[11], "SYS / # / OBS TYPES", "# / TYPES OF OBSERV", 1)
+			return l
+		}, "line 12:"},
+		{"continued types", func(l []string) []string { return slices.Delete(l, 11, 12) }, "line 19:"},
+		{"duplicated types", func(l []string) []string { l[12] = l[13]; return l }, "line 14:"},
+		{"type", func(l []string) []string { l[12] = l[12][:7] + " C1" + l[12][10:]; return l }, "line 13:"},
+		{"interval", func(l []string) []string { l[16] = "    30.0x0" + l[16][10:]; return l }, "line 17:"},
+		{"time system of the last obs", func(l []string) []string {
+			return slices.Insert(l, 18, "  2024     7    14     0     1    0.0000000     GLO         TIME OF LAST OBS")
+		}, "line 19:"},
+		{"epoch line", func(l []string) []string { l[20] = "*" + l[20][1:]; return l }, "line 21:"},
+		{"epoch", func(l []string) []string { l[20] = "> 2024 xx" + l[20][9:]; return l }, "line 21:"},
+		{"satellite", func(l []string) []string { l[21] = "GXX" + l[21][3:]; return l }, "line 22:"},
+		{"system", func(l []string) []string { l[21] = "J01" + l[21][3:]; return l }, "line 22:"},
+		{"duplicated satellite", func(l []string) []string { l[22] = l[21]; return l }, "line 23:"},
+		{"value", func(l []string) []string { l[21] = l[21][:5] + "x" + l[21][6:]; return l }, "line 22:"},
+		{"observations", func(l []string) []string { return l[:30] }, "line 30:"},
+	} {
+		_, err := ParseObs(strings.NewReader(strings.Join(tt.mod(slices.Clone(orig)), "\n")))
+		if !errors.Is(err, ErrFormat) || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%s: err=%v, want %s", tt.name, err, tt.line)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Package rinex reads the RINEX observation files, including those compressed
+by the Hatanaka method (CRINEX), and the meteorological files.
+
+The epochs are in the time system of the file (see ObsHeader.TimeSystem),
+and are represented as time.Time in UTC without the leap seconds, as the
+other packages of the module.
+*/
+package rinex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ErrFormat is returned when the file is not a valid RINEX file. The errors
+// of the readers are wrapped with the line number.
+var ErrFormat = errors.New("invalid rinex format")
+
+// lineReader reads the lines of a RINEX file.
+type lineReader struct {
+	s *mscanner.Scanner
+
+	line    string // current line padded to 80 columns
+	pending bool   // line is read but not processed
+}
+
+// next reads the next line, and returns false at the end of the input.
+func (r *lineReader) next() bool {
+	if r.pending {
+		r.pending = false
+		return true
+	}
+	if !r.s.Scan() {
+		return false
+	}
+	r.line = fmt.Sprintf("%-80s", strings.TrimRight(r.s.Text(), "\r"))
+	return true
+}
+
+// label returns the header label of the line.
+func label(l string) string {
+	return strings.TrimSpace(l[60:])
+}
+
+// satID returns the satellite ID in the form of "G01". The blank system is
+// that of the file sys (GPS if blank), and the blank of the number is zero.
+func satID(s string, sys byte) (string, error) {
+	id, err := field.SatID(s, sys)
+	if err != nil {
+		return "", fmt.Errorf("%w: satellite: %v", ErrFormat, err)
+	}
+	return id.String(), nil
+}
+
+// parseEpoch parses an epoch of the year, month, day, hour, minute and
+// seconds. The two-digit years are those of 1980-2079.
+func parseEpoch(s string) (time.Time, error) {
+	t, err := field.Epoch(s)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package rinex
+
+// Priority stores the attributes (the tracking modes, i.e., the third
+// characters of the RINEX 3 codes) in the order of the priority keyed by the
+// satellite systems and the bands, e.g., "CWPY" of 'G' and '1' selects C1C
+// before C1W. The entries override those of DefaultPriority, i.e., a
+// Priority may set only the systems and the bands to be changed.
+type Priority map[byte]map[byte]string
+
+// DefaultPriority is the priority of the attributes similar to the usage
+// of the IGS products: the civil codes first on L1 of GPS and GLONASS as the
+// broadcast clocks, the P(Y) codes on L2, and the pilot and the combined
+// channels before the data channels.
+var DefaultPriority = Priority{
+	'G': {'1': "CWPYSLXM", '2': "WPYCSLXDM", '5': "QXI"},
+	'R': {'1': "CP", '2': "PC", '3': "QXI", '4': "ABX", '6': "ABX"},
+	'E': {'1': "CXBAZ", '5': "QXI", '6': "CXBAZ", '7': "QXI", '8': "QXI"},
+	'J': {'1': "CXSLZBE", '2': "XLS", '5': "QXIDPZ", '6': "XLSEZ"},
+	'C': {'1': "PXD", '2': "IXQ", '5': "PXD", '6': "IXQA", '7': "IXQZDP", '8': "PXD"},
+	'I': {'1': "PXD", '5': "ABCX", '9': "ABCX"},
+	'S': {'1': "C", '5': "IQX"},
+}
+
+// Attributes returns the attributes of the band of the satellite system sys
+// in the order of the priority, those of DefaultPriority if not in p.
+func (p Priority) Attributes(sys, band byte) string {
+	if a, ok := p[sys][band]; ok {
+		return a
+	}
+	return DefaultPriority[sys][band]
+}
+
+// Signal stores the observations of a signal, i.e., those of the codes of
+// the same band and attribute. The observations not in the epoch are zero.
+type Signal struct {
+	// Code is the pseudorange code, e.g., "C1C".
+	Code string
+
+	Pseudorange Obs
+	Phase       Obs
+	Doppler     Obs
+	SNR         Obs
+}
+
+// Select returns the signal of the band (e.g., '1') of the satellite id of
+// the first attribute of the priority p whose pseudorange is observed, and
+// false if none. The attributes not in p are not selected, and the codes of
+// RINEX 2 are not matched (see CodeMap.NormalizeEpoch).
+func (e *Epoch) Select(id string, band byte, p Priority) (Signal, bool) {
+	obs := e.Obs[id]
+	if len(id) == 0 || obs == nil {
+		return Signal{}, false
+	}
+	for _, a := range []byte(p.Attributes(id[0], band)) {
+		code := string([]byte{'C', band, a})
+		if o, ok := obs[code]; ok && o.Value != 0 {
+			return Signal{
+				Code:        code,
+				Pseudorange: o,
+				Phase:       obs["L"+code[1:]],
+				Doppler:     obs["D"+code[1:]],
+				SNR:         obs["S"+code[1:]],
+			}, true
+		}
+	}
+	return Signal{}, false
+}
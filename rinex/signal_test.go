@@ -0,0 +1,91 @@
+package rinex
+
+import "testing"
+
+// TestSelect checks the signals of the first epoch of the fixture by the
+// default and the overridden priorities.
+func TestSelect(t *testing.T) {
+	f, err := ReadObsFile(testObs3File)
+	if err != nil {
+		t.Fatalf("ReadObsFile: %v", err)
+	}
+	e := f.Epochs[0]
+
+	// L2 of GPS only in the band overridden
+	p := Priority{'G': {'2': "LW"}}
+	for _, tt := range []struct {
+		id   string
+		band byte
+		p    Priority
+		code string
+		want Signal
+	}{
+		{"G04", '1', nil, "C1C", Signal{
+			Pseudorange: Obs{24172308.906, 0, 8},
+			Phase:       Obs{126819701.608, 0, 8},
+			Doppler:     Obs{462.618, 0, 0},
+			SNR:         Obs{43.358, 0, 0},
+		}},
+		{"G04", '2', nil, "C2W", Signal{
+			Pseudorange: Obs{24172309.332, 0, 8},
+			Phase:       Obs{99698501.067, 0, 8},
+			Doppler:     Obs{360.481, 0, 0},
+			SNR:         Obs{47.478, 0, 0},
+		}},
+		{"G04", '2', p, "C2L", Signal{
+			Pseudorange: Obs{24172309.595, 0, 8},
+			Phase:       Obs{98217148.625, 0, 8},
+		}},
+		{"G04", '1', p, "C1C", Signal{}},
+		{"G22", '2', p, "C2W", Signal{}},
+		{"E11", '5', nil, "C5X", Signal{}},
+		{"C29", '6', nil, "C6I", Signal{}},
+	} {
+		s, ok := e.Select(tt.id, tt.band, tt.p)
+		if !ok || s.Code != tt.code {
+			t.Errorf("%s %c: get %+v, want %s", tt.id, tt.band, s, tt.code)
+			continue
+		}
+		tt.want.Code = tt.code
+		if tt.want.Pseudorange.Value != 0 && s != tt.want {
+			t.Errorf("%s %c: get %+v, want %+v", tt.id, tt.band, s, tt.want)
+		}
+	}
+
+	// bands not observed, attributes not in the priority and satellites
+	// not in the epoch
+	for _, tt := range []struct {
+		id   string
+		band byte
+		p    Priority
+	}{
+		{"G22", '5', nil},
+		{"G04", '5', Priority{'G': {'5': "IX"}}},
+		{"R01", '1', Priority{'R': {'1': ""}}},
+		{"G05", '1', nil},
+		{"", '1', nil},
+	} {
+		if s, ok := e.Select(tt.id, tt.band, tt.p); ok {
+			t.Errorf("%s %c: get %+v", tt.id, tt.band, s)
+		}
+	}
+}
+
+// TestPriorityAttributes checks the overrides of the default priority.
+func TestPriorityAttributes(t *testing.T) {
+	p := Priority{'G': {'1': "WC"}, 'X': {'1': "A"}}
+	for _, tt := range []struct {
+		sys, band byte
+		want      string
+	}{
+		{'G', '1', "WC"},
+		{'G', '2', DefaultPriority['G']['2']},
+		{'E', '1', DefaultPriority['E']['1']},
+		{'X', '1', "A"},
+		{'X', '2', ""},
+	} {
+		if get := p.Attributes(tt.sys, tt.band); get != tt.want {
+			t.Errorf("%c %c: get '%s', want '%s'", tt.sys, tt.band, get, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package rtcm3
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+	"github.com/satoshi-pes/gnss/rinex"
+)
+
+// week and day are the periods of the epoch times of MSM.
+const (
+	week = 7 * 24 * time.Hour
+	day  = 24 * time.Hour
+)
+
+// Decoder decodes the messages of a stream into the epochs of the
+// observations. The messages of an epoch, i.e., of the same epoch time
+// until that without the multiple message bit, are merged into an epoch.
+type Decoder struct {
+	// Time is the approximate epoch in GPS time resolving the weeks and the
+	// days of the epoch times of MSM, which is updated by the epochs
+	// decoded. The current time is used if zero.
+	Time time.Time
+
+	// GLONASSChannels is the frequency channel numbers (-7 to 6) of GLONASS
+	// keyed by the slot numbers for the phases and the Dopplers of MSM4 and
+	// MSM6, which are updated by the extended information of MSM5 and MSM7.
+	GLONASSChannels map[int]int
+
+	// Station is the reference station of the last message 1005 or 1006, or
+	// nil.
+	Station *Station
+
+	r     *Reader
+	stats Stats
+
+	pending *rinex.Epoch
+	ready   []*rinex.Epoch
+	locks   map[lockKey]int
+}
+
+// lockKey is the key of the lock time indicators of the signals.
+type lockKey struct {
+	sat, code string
+	level     int
+}
+
+// NewDecoder returns the decoder of the stream r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := newDecoder()
+	d.r = NewReader(r)
+	return d
+}
+
+// newDecoder returns the decoder without the reader.
+func newDecoder() *Decoder {
+	return &Decoder{
+		GLONASSChannels: map[int]int{},
+		stats:           Stats{Messages: map[int]int{}},
+		locks:           map[lockKey]int{},
+	}
+}
+
+// Stats returns the counters of the frames and the messages.
+func (d *Decoder) Stats() Stats {
+	s := d.stats
+	if d.r != nil {
+		s.Frames, s.BadCRC, s.Skipped = d.r.Stats.Frames, d.r.Stats.BadCRC, d.r.Stats.Skipped
+	}
+	s.Messages = make(map[int]int, len(d.stats.Messages))
+	for k, v := range d.stats.Messages {
+		s.Messages[k] = v
+	}
+	return s
+}
+
+// Next returns the next epoch of the stream, and io.EOF after the last
+// epoch, which is returned at the end of the stream even if the multiple
+// message bit is set. The messages failed to decode are skipped and counted
+// in Stats.
+func (d *Decoder) Next() (*rinex.Epoch, error) {
+	for {
+		if e := d.Epoch(); e != nil {
+			return e, nil
+		}
+		f, err := d.r.Next()
+		if err == io.EOF {
+			d.Flush()
+			if e := d.Epoch(); e != nil {
+				return e, nil
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.Decode(f)
+	}
+}
+
+// Decode decodes the frame, and the epochs completed are returned by Epoch.
+// ErrUnsupported is returned for the messages not decoded, and ErrFormat
+// for the invalid contents.
+func (d *Decoder) Decode(f Frame) error {
+	err := d.decode(f)
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		d.stats.Unsupported++
+	case err != nil:
+		d.stats.Errors++
+	default:
+		d.stats.Messages[f.Type]++
+	}
+	return err
+}
+
+func (d *Decoder) decode(f Frame) error {
+	switch f.Type {
+	case 1005, 1006:
+		s, err := DecodeStation(f.Payload)
+		if err != nil {
+			return err
+		}
+		d.Station = s
+		return nil
+	}
+	m, err := DecodeMSM(f.Payload)
+	if err != nil {
+		return err
+	}
+	d.add(m)
+	return nil
+}
+
+// Epoch returns the next epoch completed, or nil if none.
+func (d *Decoder) Epoch() *rinex.Epoch {
+	if len(d.ready) == 0 {
+		return nil
+	}
+	e := d.ready[0]
+	d.ready = d.ready[1:]
+	return e
+}
+
+// Flush completes the epoch of the messages decoded, e.g., at the end of
+// the stream.
+func (d *Decoder) Flush() {
+	if d.pending != nil {
+		d.ready = append(d.ready, d.pending)
+		d.pending = nil
+	}
+}
+
+// add adds the observations of the message to the epoch.
+func (d *Decoder) add(m *MSM) {
+	t := d.epochTime(m)
+	if d.pending != nil && !d.pending.Time.Equal(t) {
+		d.Flush()
+	}
+	if d.pending == nil {
+		d.pending = &rinex.Epoch{Time: t, Obs: map[string]rinex.SatObs{}}
+	}
+	d.Time = t
+
+	if m.Sys == 'R' && m.ExtInfo != nil {
+		for k, sat := range m.Sats {
+			if info := m.ExtInfo[k]; info <= 13 {
+				d.GLONASSChannels[sat] = info - 7
+			}
+		}
+	}
+
+	e := d.pending
+	for _, c := range m.Cells {
+		code := msmSignals[m.Sys][c.Signal]
+		if code == "" {
+			continue
+		}
+		id := fmt.Sprintf("%c%02d", m.Sys, c.Sat)
+		freq := frequency(m.Sys, code, 0)
+		if m.Sys == 'R' {
+			k, ok := d.GLONASSChannels[c.Sat]
+			freq = frequency(m.Sys, code, k)
+			if !ok {
+				freq = 0
+			}
+		}
+
+		obs := e.Obs[id]
+		if obs == nil {
+			obs = rinex.SatObs{}
+			e.Obs[id] = obs
+			e.Sats = append(e.Sats, id)
+		}
+		ssi := 0
+		if c.CNR > 0 {
+			ssi = min(max(int(c.CNR/6), 1), 9)
+			obs["S"+code] = rinex.Obs{Value: c.CNR}
+		}
+		if !math.IsNaN(c.Pseudorange) {
+			obs["C"+code] = rinex.Obs{Value: c.Pseudorange, SSI: ssi}
+		}
+		if freq == 0 {
+			continue
+		}
+		lambda := lightVelocity / freq
+		if !math.IsNaN(c.PhaseRange) {
+			key := lockKey{id, code, m.Level}
+			lli := 0
+			if lock, ok := d.locks[key]; ok && c.Lock < lock {
+				lli |= 1
+			}
+			d.locks[key] = c.Lock
+			if c.HalfCycle {
+				lli |= 2
+			}
+			obs["L"+code] = rinex.Obs{Value: c.PhaseRange / lambda, LLI: lli, SSI: ssi}
+		}
+		if !math.IsNaN(c.PhaseRangeRate) {
+			obs["D"+code] = rinex.Obs{Value: -c.PhaseRangeRate / lambda}
+		}
+	}
+
+	if !m.Multiple {
+		d.Flush()
+	}
+}
+
+// epochTime returns the epoch in GPS time of the epoch time of the message
+// nearest to the approximate epoch.
+func (d *Decoder) epochTime(m *MSM) time.Time {
+	ref := d.Time
+	if ref.IsZero() {
+		ref = gnsstime.ToGPST(time.Now()).Time()
+	}
+	g := gnsstime.NewGPST(ref)
+
+	switch m.Sys {
+	case 'R':
+		// the time of day in the Moscow time without the day of week
+		tod := time.Duration(m.Epoch&(1<<27-1)) * time.Millisecond
+		r := g.GLONASST().Time()
+		t := nearest(r.Truncate(day).Add(tod), r, day)
+		return gnsstime.NewGLONASST(t).GPST().Time()
+	case 'C':
+		w, _ := g.BDT().Week()
+		t := gnsstime.BDTFromWeek(w, float64(m.Epoch)*1e-3).GPST().Time()
+		return nearest(t, ref, week)
+	}
+	w, _ := g.Week()
+	t := gnsstime.GPSTFromWeek(w, float64(m.Epoch)*1e-3).Time()
+	return nearest(t, ref, week)
+}
+
+// nearest returns t shifted by the period nearest to ref.
+func nearest(t, ref time.Time, period time.Duration) time.Time {
+	if d := t.Sub(ref); d > period/2 {
+		return t.Add(-period)
+	} else if d < -period/2 {
+		return t.Add(period)
+	}
+	return t
+}
@@ -0,0 +1,193 @@
+package rtcm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// testEpoch is the epoch of the test messages, i.e., TOW 345600 s of the
+// GPS week 2323.
+var testEpoch = time.Date(2024, 7, 18, 0, 0, 0, 0, time.UTC)
+
+// testSysMSM returns testMSM of the level of the system at the epoch time.
+func testSysMSM(sys byte, level int, epoch uint32, multiple bool) *MSM {
+	m := testMSM(level)
+	for typ, s := range msmSystems {
+		if s == sys {
+			m.Type = typ*10 + level
+		}
+	}
+	m.Sys, m.Epoch, m.Multiple = sys, epoch, multiple
+	if sys == 'R' {
+		m.Signals = []int{2, 8}
+		m.Cells[1].Signal = 8
+		if m.ExtInfo != nil {
+			m.ExtInfo = []int{1 + 7, 15} // R05 of the channel 1, R12 unknown
+		}
+	}
+	return m
+}
+
+// TestDecoder checks the epochs of the messages of GPS, GLONASS and Galileo
+// merged, the epoch of a single message, the observations, the loss of
+// lock and the counters.
+func TestDecoder(t *testing.T) {
+	// 2024-07-18 02:59:42 in the Moscow time of Thursday
+	glo := uint32(4<<27 | (2*3600+59*60+42)*1000)
+
+	var b bytes.Buffer
+	b.Write(testFrame(t, testStationFrame))
+	b.Write(frame(encodeMSM(testSysMSM('G', 7, 345600000, true))))
+	b.Write(frame(encodeMSM(testSysMSM('R', 7, glo, true))))
+	b.Write(frame([]byte{0x3F, 0xB0, 0x00})) // 1019 not decoded
+	b.Write(frame(encodeMSM(testSysMSM('E', 7, 345600000, false))))
+	next := testSysMSM('G', 7, 345601000, true)
+	next.Cells[0].Lock = 10 // loss of lock of G05 L1C
+	b.Write(frame(encodeMSM(next)))
+	b.Write(frame(encodeMSM(testSysMSM('C', 4, 345601000-14000, false))))
+	b.Write(frame(encodeMSM(testSysMSM('G', 4, 345602000, true))))
+	b.Write(frame(encodeMSM(testSysMSM('G', 5, 345602000, true))[:30]))
+
+	d := NewDecoder(&b)
+	d.Time = testEpoch.Add(-time.Hour)
+	e1, err := d.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	e2, err := d.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	e3, err := d.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if _, err := d.Next(); err != io.EOF {
+		t.Fatalf("Next: get err=%v, want %v", err, io.EOF)
+	}
+
+	for _, tt := range []struct {
+		name string
+		t    time.Time
+		sats []string
+		get  []string
+		at   time.Time
+	}{
+		{"first", testEpoch, []string{"G05", "G12", "R05", "R12", "E05", "E12"}, e1.Sats, e1.Time},
+		{"second", testEpoch.Add(time.Second), []string{"G05", "G12", "C05", "C12"}, e2.Sats, e2.Time},
+		{"flushed", testEpoch.Add(2 * time.Second), []string{"G05", "G12"}, e3.Sats, e3.Time},
+	} {
+		if !tt.at.Equal(tt.t) || len(tt.get) != len(tt.sats) {
+			t.Errorf("%s: get %v %v, want %v %v", tt.name, tt.at, tt.get, tt.t, tt.sats)
+			continue
+		}
+		for k := range tt.sats {
+			if tt.get[k] != tt.sats[k] {
+				t.Errorf("%s: get %v, want %v", tt.name, tt.get, tt.sats)
+				break
+			}
+		}
+	}
+
+	// the observations of the cells decoded
+	m, _ := DecodeMSM(encodeMSM(testSysMSM('G', 7, 345600000, true)))
+	c := m.Cells[0]
+	lambda1, lambda2 := lightVelocity/1575.42e6, lightVelocity/1227.60e6
+	for _, tt := range []struct {
+		id, code string
+		value    float64
+		lli, ssi int
+	}{
+		{"G05", "C1C", c.Pseudorange, 0, 7},
+		{"G05", "L1C", c.PhaseRange / lambda1, 0, 7},
+		{"G05", "D1C", -c.PhaseRangeRate / lambda1, 0, 0},
+		{"G05", "S1C", c.CNR, 0, 0},
+		{"G05", "L2W", m.Cells[1].PhaseRange / lambda2, 2, 6},
+		{"R05", "L1C", m.Cells[0].PhaseRange / (lightVelocity / 1602.5625e6), 0, 7},
+		{"R05", "L2C", m.Cells[1].PhaseRange / (lightVelocity / 1246.4375e6), 2, 6},
+		{"E05", "C6B", m.Cells[1].Pseudorange, 0, 6},
+	} {
+		o, ok := e1.Obs[tt.id][tt.code]
+		if !ok || math.Abs(o.Value-tt.value) > 1e-6 || o.LLI != tt.lli || o.SSI != tt.ssi {
+			t.Errorf("%s %s: get %+v (%v), want %v %d %d", tt.id, tt.code, o, ok, tt.value, tt.lli, tt.ssi)
+		}
+	}
+	for _, tt := range []struct{ id, code string }{
+		{"G12", "L1C"}, // invalid phase
+		{"G12", "C2W"}, // not observed
+		{"R12", "L1C"}, // unknown channel
+		{"R12", "D1C"},
+	} {
+		if o, ok := e1.Obs[tt.id][tt.code]; ok {
+			t.Errorf("%s %s: get %+v, want none", tt.id, tt.code, o)
+		}
+	}
+	if _, ok := e1.Obs["R12"]["C1C"]; !ok {
+		t.Errorf("R12 C1C: none")
+	}
+	if o := e2.Obs["G05"]["L1C"]; o.LLI != 1 {
+		t.Errorf("loss of lock: get LLI %d, want 1", o.LLI)
+	}
+	if o := e2.Obs["G05"]["L2W"]; o.LLI != 2 {
+		t.Errorf("no loss of lock: get LLI %d, want 2", o.LLI)
+	}
+	if _, ok := e2.Obs["C05"]["D2I"]; ok {
+		t.Errorf("Doppler of MSM4")
+	}
+
+	if d.Station == nil || d.Station.ID != 2003 || d.GLONASSChannels[5] != 1 {
+		t.Errorf("station and channels: %+v %v", d.Station, d.GLONASSChannels)
+	}
+	s := d.Stats()
+	if s.Frames != 9 || s.Unsupported != 1 || s.Errors != 1 || s.Messages[1005] != 1 || s.Messages[1077] != 2 || s.Messages[1074] != 1 || s.Messages[1124] != 1 {
+		t.Errorf("stats: get %+v", s)
+	}
+}
+
+// TestDecoderTime checks the epochs across the week and the day of GLONASS
+// of the approximate epochs.
+func TestDecoderTime(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		ref   time.Time
+		sys   byte
+		epoch uint32
+		want  time.Time
+	}{
+		{"next week", time.Date(2024, 7, 13, 23, 59, 59, 0, time.UTC), 'G', 1000, time.Date(2024, 7, 14, 0, 0, 1, 0, time.UTC)},
+		{"previous week", time.Date(2024, 7, 14, 0, 0, 1, 0, time.UTC), 'E', 604799000, time.Date(2024, 7, 13, 23, 59, 59, 0, time.UTC)},
+		{"BDT", time.Date(2024, 7, 14, 0, 0, 1, 0, time.UTC), 'C', 604786000, time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)},
+		// the Moscow midnight of 21:00 UTC and 21:00:18 GPST
+		{"next day", time.Date(2024, 7, 13, 21, 0, 10, 0, time.UTC), 'R', 0<<27 | 5000, time.Date(2024, 7, 13, 21, 0, 23, 0, time.UTC)},
+		{"previous day", time.Date(2024, 7, 13, 21, 0, 30, 0, time.UTC), 'R', 6<<27 | (86400-5)*1000, time.Date(2024, 7, 13, 21, 0, 13, 0, time.UTC)},
+	} {
+		d := newDecoder()
+		d.Time = tt.ref
+		if get := d.epochTime(&MSM{Sys: tt.sys, Epoch: tt.epoch}); !get.Equal(tt.want) {
+			t.Errorf("%s: get %v, want %v", tt.name, get, tt.want)
+		}
+	}
+}
+
+// TestDecoderDecode checks the errors of the frames decoded directly.
+func TestDecoderDecode(t *testing.T) {
+	d := newDecoder()
+	d.Time = testEpoch
+	if err := d.Decode(Frame{Type: 1019, Payload: []byte{0x3F, 0xB0}}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("1019: get err=%v, want %v", err, ErrUnsupported)
+	}
+	if err := d.Decode(Frame{Type: 1005, Payload: []byte{0x3E, 0xD0}}); !errors.Is(err, ErrFormat) {
+		t.Errorf("1005: get err=%v, want %v", err, ErrFormat)
+	}
+	if err := d.Decode(Frame{Type: 1077, Payload: encodeMSM(testSysMSM('G', 7, 345600000, true))}); err != nil || d.Epoch() != nil {
+		t.Errorf("multiple: err=%v", err)
+	}
+	d.Flush()
+	if e := d.Epoch(); e == nil || !e.Time.Equal(testEpoch) || d.Epoch() != nil {
+		t.Errorf("flushed: get %v", e)
+	}
+}
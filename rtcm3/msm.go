@@ -0,0 +1,216 @@
+package rtcm3
+
+import (
+	"fmt"
+	"math"
+)
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+// rangeMs is the range of the light of a millisecond (m).
+const rangeMs = lightVelocity * 1e-3
+
+// msmSystems is the satellite systems of the MSM message numbers divided by
+// 10, e.g., 107 of 1071-1077 of GPS.
+var msmSystems = map[int]byte{107: 'G', 108: 'R', 109: 'E', 111: 'J', 112: 'C'}
+
+// MSM is a multiple signal message of a satellite system.
+type MSM struct {
+	// Type is the message number, e.g., 1077, Sys is the satellite system,
+	// e.g., 'G', and Level is 4 to 7 of MSM4 to MSM7.
+	Type  int
+	Sys   byte
+	Level int
+
+	StationID int
+
+	// Epoch is the epoch time of the header, i.e., TOW (ms) of GPS, Galileo
+	// and QZSS, TOW (ms) in BDT of BeiDou, and the day of week (3 bits) and
+	// the time of day (27 bits, ms) in the Moscow time of GLONASS.
+	Epoch uint32
+
+	// Multiple is true if more messages of the epoch follow, and IODS is the
+	// issue of data station.
+	Multiple bool
+	IODS     int
+
+	// ClockSteering, ExternalClock, Smoothing and SmoothingInterval are the
+	// indicators of the header.
+	ClockSteering     int
+	ExternalClock     int
+	Smoothing         bool
+	SmoothingInterval int
+
+	// Sats is the satellite numbers (1-64) of the satellite mask, and Signals
+	// is the signal IDs (1-32) of the signal mask.
+	Sats    []int
+	Signals []int
+
+	// ExtInfo is the extended satellite information of Sats of MSM5 and
+	// MSM7, e.g., the frequency channel number plus 7 of GLONASS, or nil.
+	ExtInfo []int
+
+	// Cells is the observations of the cells of the cell mask in the order
+	// of Sats and then Signals.
+	Cells []Cell
+}
+
+// Cell is the observations of a signal of a satellite of MSM.
+type Cell struct {
+	// Sat and Signal are the satellite number and the signal ID.
+	Sat, Signal int
+
+	// Pseudorange and PhaseRange are the full ranges (m) of the rough
+	// ranges of the satellite and the fine ranges of the signal, and
+	// PhaseRangeRate is the full phase range rate (m/s) of MSM5 and MSM7,
+	// which are NaN if invalid or not given.
+	Pseudorange    float64
+	PhaseRange     float64
+	PhaseRangeRate float64
+
+	// Lock is the lock time indicator of 4 bits of MSM4 and MSM5, or the
+	// extended lock time indicator of 10 bits of MSM6 and MSM7, and
+	// HalfCycle is the half-cycle ambiguity indicator.
+	Lock      int
+	HalfCycle bool
+
+	// CNR is the carrier to noise ratio (dB-Hz), or zero if not given.
+	CNR float64
+}
+
+// msmFields is the widths (bits) of the fields of MSM of the levels, i.e.,
+// the fine pseudoranges, the fine phase ranges, the lock time indicators and
+// CNR, and their scales (ms, ms, dB-Hz).
+var msmFields = map[int]struct {
+	pr, cp, lock, cnr          int
+	prScale, cpScale, cnrScale float64
+	ext                        bool // extended info and the rates
+}{
+	4: {15, 22, 4, 6, 0x1p-24, 0x1p-29, 1, false},
+	5: {15, 22, 4, 6, 0x1p-24, 0x1p-29, 1, true},
+	6: {20, 24, 10, 10, 0x1p-29, 0x1p-31, 0x1p-4, false},
+	7: {20, 24, 10, 10, 0x1p-29, 0x1p-31, 0x1p-4, true},
+}
+
+// DecodeMSM decodes the payload of MSM4, MSM5, MSM6 or MSM7 of GPS, GLONASS,
+// Galileo, QZSS or BeiDou. ErrUnsupported is returned for the other messages,
+// and ErrFormat for the invalid contents.
+func DecodeMSM(payload []byte) (*MSM, error) {
+	typ := messageType(payload)
+	sys, ok := msmSystems[typ/10]
+	f, ok2 := msmFields[typ%10]
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupported, typ)
+	}
+
+	r := bitReader{b: payload, i: 12}
+	m := &MSM{Type: typ, Sys: sys, Level: typ % 10}
+	m.StationID = int(r.u(12))
+	m.Epoch = uint32(r.u(30))
+	m.Multiple = r.u(1) == 1
+	m.IODS = int(r.u(3))
+	r.u(7) // reserved
+	m.ClockSteering = int(r.u(2))
+	m.ExternalClock = int(r.u(2))
+	m.Smoothing = r.u(1) == 1
+	m.SmoothingInterval = int(r.u(3))
+	for k := 1; k <= 64; k++ {
+		if r.u(1) == 1 {
+			m.Sats = append(m.Sats, k)
+		}
+	}
+	for k := 1; k <= 32; k++ {
+		if r.u(1) == 1 {
+			m.Signals = append(m.Signals, k)
+		}
+	}
+	if n := len(m.Sats) * len(m.Signals); n > 64 {
+		return nil, fmt.Errorf("%w: %d: %d cells of %d satellites and %d signals", ErrFormat, typ, n, len(m.Sats), len(m.Signals))
+	}
+	for _, sat := range m.Sats {
+		for _, sig := range m.Signals {
+			if r.u(1) == 1 {
+				m.Cells = append(m.Cells, Cell{Sat: sat, Signal: sig})
+			}
+		}
+	}
+	if r.short {
+		return nil, fmt.Errorf("%w: %d: header too short", ErrFormat, typ)
+	}
+
+	// the satellite data, i.e., the rough ranges (ms) and the rough phase
+	// range rates (m/s)
+	nsat := len(m.Sats)
+	rough := make([]float64, nsat)
+	rate := make([]float64, nsat)
+	for k := range rough {
+		if v := r.u(8); v == 255 {
+			rough[k] = math.NaN()
+		} else {
+			rough[k] = float64(v)
+		}
+	}
+	if f.ext {
+		m.ExtInfo = make([]int, nsat)
+		for k := range m.ExtInfo {
+			m.ExtInfo[k] = int(r.u(4))
+		}
+	}
+	for k := range rough {
+		rough[k] += float64(r.u(10)) * 0x1p-10
+	}
+	for k := range rate {
+		rate[k] = math.NaN()
+		if f.ext {
+			if v := r.s(14); v != -8192 {
+				rate[k] = float64(v)
+			}
+		}
+	}
+
+	// the signal data
+	sat := make(map[int]int, nsat) // index of the satellite numbers
+	for k, s := range m.Sats {
+		sat[s] = k
+	}
+	for k := range m.Cells {
+		c := &m.Cells[k]
+		c.Pseudorange = (rough[sat[c.Sat]] + fine(r.s(f.pr), f.pr, f.prScale)) * rangeMs
+	}
+	for k := range m.Cells {
+		c := &m.Cells[k]
+		c.PhaseRange = (rough[sat[c.Sat]] + fine(r.s(f.cp), f.cp, f.cpScale)) * rangeMs
+	}
+	for k := range m.Cells {
+		m.Cells[k].Lock = int(r.u(f.lock))
+	}
+	for k := range m.Cells {
+		m.Cells[k].HalfCycle = r.u(1) == 1
+	}
+	for k := range m.Cells {
+		m.Cells[k].CNR = float64(r.u(f.cnr)) * f.cnrScale
+	}
+	for k := range m.Cells {
+		c := &m.Cells[k]
+		c.PhaseRangeRate = math.NaN()
+		if f.ext {
+			if v := r.s(15); v != -16384 {
+				c.PhaseRangeRate = rate[sat[c.Sat]] + float64(v)*1e-4
+			}
+		}
+	}
+	if r.short {
+		return nil, fmt.Errorf("%w: %d: %d bytes too short for %d cells", ErrFormat, typ, len(payload), len(m.Cells))
+	}
+	return m, nil
+}
+
+// fine returns the fine value v of n bits of the scale, or NaN for the
+// invalid value, i.e., the minimum of the n bits.
+func fine(v int64, n int, scale float64) float64 {
+	if v == -1<<(n-1) {
+		return math.NaN()
+	}
+	return float64(v) * scale
+}
@@ -0,0 +1,241 @@
+package rtcm3
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// encodeMSM returns the payload of the message m of the fields of
+// RTCM 10403.3, whose rough ranges and rates are those of the first cells of
+// the satellites, and the NaN values are encoded as invalid.
+func encodeMSM(m *MSM) []byte {
+	f := msmFields[m.Level]
+	var w bitWriter
+	w.u(12, uint64(m.Type))
+	w.u(12, uint64(m.StationID))
+	w.u(30, uint64(m.Epoch))
+	w.u(1, b2u(m.Multiple))
+	w.u(3, uint64(m.IODS))
+	w.u(7, 0)
+	w.u(2, uint64(m.ClockSteering))
+	w.u(2, uint64(m.ExternalClock))
+	w.u(1, b2u(m.Smoothing))
+	w.u(3, uint64(m.SmoothingInterval))
+	sats, sigs := map[int]bool{}, map[int]bool{}
+	for _, s := range m.Sats {
+		sats[s] = true
+	}
+	for _, s := range m.Signals {
+		sigs[s] = true
+	}
+	for k := 1; k <= 64; k++ {
+		w.u(1, b2u(sats[k]))
+	}
+	for k := 1; k <= 32; k++ {
+		w.u(1, b2u(sigs[k]))
+	}
+	cells := map[[2]int]bool{}
+	for _, c := range m.Cells {
+		cells[[2]int{c.Sat, c.Signal}] = true
+	}
+	for _, s := range m.Sats {
+		for _, g := range m.Signals {
+			w.u(1, b2u(cells[[2]int{s, g}]))
+		}
+	}
+
+	// the rough values of the first cells of the satellites
+	rough := map[int]float64{}
+	rate := map[int]float64{}
+	for _, c := range m.Cells {
+		if _, ok := rough[c.Sat]; !ok {
+			rough[c.Sat] = math.Round(c.Pseudorange/rangeMs*1024) / 1024
+			rate[c.Sat] = math.Round(c.PhaseRangeRate)
+		}
+	}
+	for _, s := range m.Sats {
+		if math.IsNaN(rough[s]) {
+			w.u(8, 255)
+		} else {
+			w.u(8, uint64(rough[s]))
+		}
+	}
+	if f.ext {
+		for k := range m.Sats {
+			w.u(4, uint64(m.ExtInfo[k]))
+		}
+	}
+	for _, s := range m.Sats {
+		if math.IsNaN(rough[s]) {
+			w.u(10, 0)
+		} else {
+			w.u(10, uint64(math.Mod(rough[s], 1)*1024))
+		}
+	}
+	if f.ext {
+		for _, s := range m.Sats {
+			if math.IsNaN(rate[s]) {
+				w.s(14, -8192)
+			} else {
+				w.s(14, int64(rate[s]))
+			}
+		}
+	}
+
+	fine := func(v, rough, scale float64, n int) {
+		if math.IsNaN(v) || math.IsNaN(rough) {
+			w.s(n, -1<<(n-1))
+			return
+		}
+		w.s(n, int64(math.Round((v/rangeMs-rough)/scale)))
+	}
+	for _, c := range m.Cells {
+		fine(c.Pseudorange, rough[c.Sat], f.prScale, f.pr)
+	}
+	for _, c := range m.Cells {
+		fine(c.PhaseRange, rough[c.Sat], f.cpScale, f.cp)
+	}
+	for _, c := range m.Cells {
+		w.u(f.lock, uint64(c.Lock))
+	}
+	for _, c := range m.Cells {
+		w.u(1, b2u(c.HalfCycle))
+	}
+	for _, c := range m.Cells {
+		w.u(f.cnr, uint64(math.Round(c.CNR/f.cnrScale)))
+	}
+	if f.ext {
+		for _, c := range m.Cells {
+			if math.IsNaN(c.PhaseRangeRate) {
+				w.s(15, -16384)
+			} else {
+				w.s(15, int64(math.Round((c.PhaseRangeRate-rate[c.Sat])/1e-4)))
+			}
+		}
+	}
+	return w.b
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// testMSM returns the message of the level of GPS G05 and G12 of L1C and
+// L2W, where L2W of G12 is not observed, and the phase of L1C of G12 is
+// invalid.
+func testMSM(level int) *MSM {
+	nan := math.NaN()
+	m := &MSM{
+		Type: 1070 + level, Sys: 'G', Level: level, StationID: 2003,
+		Epoch: 345600000, Multiple: true, IODS: 3, ClockSteering: 1, Smoothing: true, SmoothingInterval: 2,
+		Sats: []int{5, 12}, Signals: []int{2, 10},
+		Cells: []Cell{
+			{Sat: 5, Signal: 2, Pseudorange: 21234567.123, PhaseRange: 21234568.456, PhaseRangeRate: -512.3456, Lock: 9, CNR: 45},
+			{Sat: 5, Signal: 10, Pseudorange: 21234569.789, PhaseRange: 21234570.012, PhaseRangeRate: -512.3321, Lock: 7, HalfCycle: true, CNR: 38},
+			{Sat: 12, Signal: 2, Pseudorange: 24876543.21, PhaseRange: nan, PhaseRangeRate: 301.2, Lock: 0, CNR: 31},
+		},
+	}
+	if msmFields[level].ext {
+		m.ExtInfo = []int{0, 0}
+	} else {
+		for k := range m.Cells {
+			m.Cells[k].PhaseRangeRate = nan
+		}
+	}
+	if level >= 6 {
+		m.Cells[0].CNR = 45.3125
+		m.Cells[0].Lock = 600
+	}
+	return m
+}
+
+// TestDecodeMSM checks the messages of the levels decoded of the fields
+// encoded within the resolutions of the fields.
+func TestDecodeMSM(t *testing.T) {
+	for level := 4; level <= 7; level++ {
+		want := testMSM(level)
+		m, err := DecodeMSM(encodeMSM(want))
+		if err != nil {
+			t.Fatalf("MSM%d: %v", level, err)
+		}
+		switch {
+		case m.Type != want.Type || m.Sys != 'G' || m.Level != level || m.StationID != 2003 || m.Epoch != 345600000:
+			t.Errorf("MSM%d: header %+v", level, m)
+		case !m.Multiple || m.IODS != 3 || m.ClockSteering != 1 || m.ExternalClock != 0 || !m.Smoothing || m.SmoothingInterval != 2:
+			t.Errorf("MSM%d: indicators %+v", level, m)
+		case len(m.Sats) != 2 || m.Sats[1] != 12 || len(m.Signals) != 2 || m.Signals[1] != 10:
+			t.Errorf("MSM%d: masks %v %v", level, m.Sats, m.Signals)
+		case (m.ExtInfo != nil) != (level%2 == 1):
+			t.Errorf("MSM%d: ExtInfo %v", level, m.ExtInfo)
+		}
+		if len(m.Cells) != len(want.Cells) {
+			t.Fatalf("MSM%d: number of cells: get %d, want %d", level, len(m.Cells), len(want.Cells))
+		}
+
+		// the resolutions of the fine ranges (m)
+		tol := 0x1p-24 * rangeMs
+		if level >= 6 {
+			tol = 0x1p-29 * rangeMs
+		}
+		for k, c := range m.Cells {
+			w := want.Cells[k]
+			switch {
+			case c.Sat != w.Sat || c.Signal != w.Signal || c.Lock != w.Lock || c.HalfCycle != w.HalfCycle || c.CNR != w.CNR:
+				t.Errorf("MSM%d cell %d: get %+v, want %+v", level, k, c, w)
+			case !near(c.Pseudorange, w.Pseudorange, tol) || !near(c.PhaseRange, w.PhaseRange, tol) || !near(c.PhaseRangeRate, w.PhaseRangeRate, 1e-4):
+				t.Errorf("MSM%d cell %d: get %.4f %.4f %.4f, want %.4f %.4f %.4f", level, k,
+					c.Pseudorange, c.PhaseRange, c.PhaseRangeRate, w.Pseudorange, w.PhaseRange, w.PhaseRangeRate)
+			}
+		}
+	}
+}
+
+// near reports whether a and b are within tol or both NaN.
+func near(a, b, tol float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= tol
+}
+
+// TestDecodeMSMInvalid checks the invalid rough range, the messages
+// truncated and too many cells, and the other messages.
+func TestDecodeMSMInvalid(t *testing.T) {
+	m := testMSM(7)
+	m.Cells[2].Pseudorange = math.NaN()
+	get, err := DecodeMSM(encodeMSM(m))
+	if err != nil {
+		t.Fatalf("DecodeMSM: %v", err)
+	}
+	if c := get.Cells[2]; !math.IsNaN(c.Pseudorange) || !math.IsNaN(c.PhaseRange) || math.IsNaN(get.Cells[0].Pseudorange) {
+		t.Errorf("invalid rough range: get %+v", c)
+	}
+
+	b := encodeMSM(testMSM(4))
+	if _, err := DecodeMSM(b[:len(b)-2]); !errors.Is(err, ErrFormat) {
+		t.Errorf("truncated: get err=%v, want %v", err, ErrFormat)
+	}
+	if _, err := DecodeMSM(b[:20]); !errors.Is(err, ErrFormat) {
+		t.Errorf("header truncated: get err=%v, want %v", err, ErrFormat)
+	}
+
+	many := &MSM{Type: 1074, Sys: 'G', Level: 4}
+	for k := 1; k <= 13; k++ {
+		many.Sats = append(many.Sats, k)
+	}
+	many.Signals = []int{2, 3, 4, 8, 9}
+	if _, err := DecodeMSM(encodeMSM(many)); !errors.Is(err, ErrFormat) {
+		t.Errorf("65 cells: get err=%v, want %v", err, ErrFormat)
+	}
+
+	for _, typ := range []int{1073, 1104, 1005, 1019} {
+		b := []byte{byte(typ >> 4), byte(typ << 4), 0, 0}
+		if _, err := DecodeMSM(b); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%d: get err=%v, want %v", typ, err, ErrUnsupported)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package rtcm3
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Stats is the counters of the stream for the monitoring.
+type Stats struct {
+	// Frames is the number of the frames of the valid CRC, and BadCRC is
+	// that of the invalid CRC, which are skipped.
+	Frames int
+	BadCRC int
+
+	// Skipped is the number of the bytes skipped to resynchronize on the
+	// preamble, including those of the frames of the invalid CRC.
+	Skipped int
+
+	// Messages is the numbers of the messages decoded keyed by the message
+	// numbers, Unsupported is the number of the frames of the messages not
+	// decoded, and Errors is that of the messages failed to decode.
+	Messages    map[int]int
+	Unsupported int
+	Errors      int
+}
+
+// Reader reads the transport frames of a stream, resynchronizing on the
+// preamble after the garbage bytes and the frames of the invalid CRC.
+type Reader struct {
+	// Stats is the counters of the frames read.
+	Stats Stats
+
+	r *bufio.Reader
+}
+
+// NewReader returns the reader of the frames of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 2*(3+maxPayload+3)), Stats: Stats{Messages: map[int]int{}}}
+}
+
+// Next returns the next frame of the valid CRC, and io.EOF at the end of the
+// stream, where the incomplete frame at the end is skipped. The other errors
+// are those of the underlying reader.
+func (r *Reader) Next() (Frame, error) {
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return Frame{}, err
+		}
+		if b != preamble {
+			r.Stats.Skipped++
+			continue
+		}
+
+		// the header of the 6 reserved bits and the 10 bits of the length
+		h, err := r.r.Peek(2)
+		if err != nil {
+			return Frame{}, r.end(len(h), err)
+		}
+		if h[0]&0xFC != 0 {
+			r.Stats.Skipped++
+			continue
+		}
+		n := int(h[0]&0x03)<<8 | int(h[1])
+		data, err := r.r.Peek(2 + n + 3)
+		if err != nil {
+			return Frame{}, r.end(len(data), err)
+		}
+
+		frame := append([]byte{preamble}, data[:2+n]...)
+		crc := uint32(data[2+n])<<16 | uint32(data[3+n])<<8 | uint32(data[4+n])
+		if crc24q(frame) != crc {
+			// resynchronize from the byte after the preamble
+			r.Stats.BadCRC++
+			r.Stats.Skipped++
+			continue
+		}
+		r.r.Discard(2 + n + 3)
+		r.Stats.Frames++
+		payload := frame[3:]
+		return Frame{Type: messageType(payload), Payload: payload}, nil
+	}
+}
+
+// end returns the error at the end of the stream in a frame, whose preamble
+// and the n bytes peeked are skipped.
+func (r *Reader) end(n int, err error) error {
+	r.r.Discard(n)
+	r.Stats.Skipped += 1 + n
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	return err
+}
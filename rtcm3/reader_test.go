@@ -0,0 +1,52 @@
+package rtcm3
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// TestReader checks the frames read among the garbage bytes, the frame of
+// the invalid CRC and the frame truncated at the end, and the counters.
+func TestReader(t *testing.T) {
+	station := testFrame(t, testStationFrame)
+	msm := frame(encodeMSM(testMSM(7)))
+	bad := append([]byte(nil), msm...)
+	bad[10] ^= 0x01
+
+	var b bytes.Buffer
+	b.Write([]byte{0x00, 0xD3, 0xFF, 0x12}) // garbage with a false preamble
+	b.Write(station)
+	b.Write(bad)
+	b.Write(msm)
+	b.Write(frame(nil))
+	b.Write(station[:10])
+
+	r := NewReader(&b)
+	var types []int
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		types = append(types, f.Type)
+	}
+	if len(types) != 3 || types[0] != 1005 || types[1] != 1077 || types[2] != 0 {
+		t.Errorf("types: get %v, want [1005 1077 0]", types)
+	}
+	if s := r.Stats; s.Frames != 3 || s.BadCRC != 1 || s.Skipped != 4+len(bad)+10 {
+		t.Errorf("stats: get %+v, want 3 frames, 1 bad CRC, %d skipped", s, 4+len(bad)+10)
+	}
+}
+
+// TestReaderPayload checks the payload of the frame.
+func TestReaderPayload(t *testing.T) {
+	payload := encodeMSM(testMSM(4))
+	f, err := NewReader(bytes.NewReader(frame(payload))).Next()
+	if err != nil || f.Type != 1074 || !bytes.Equal(f.Payload, payload) {
+		t.Errorf("get %d %x (err=%v), want 1074 %x", f.Type, f.Payload, err, payload)
+	}
+}
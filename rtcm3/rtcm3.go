@@ -0,0 +1,99 @@
+/*
+Package rtcm3 decodes the RTCM 3 messages of the real-time streams, i.e.,
+the transport frames and the multiple signal messages (MSM4 to MSM7) of GPS,
+GLONASS, Galileo, QZSS and BeiDou into the epochs of the observations of the
+rinex package with the observation codes of RINEX 3, and the station
+positions of the messages 1005 and 1006.
+
+The epochs are in GPS time, represented as time.Time in UTC without the leap
+seconds as the other packages of the module. The pseudoranges and the phase
+ranges are in meters in MSM, and are converted to the observations of RINEX
+in meters, cycles, Hz and dB-Hz by Decoder.
+*/
+package rtcm3
+
+import (
+	"errors"
+)
+
+var (
+	// ErrFormat is returned for the messages of the invalid contents, e.g.,
+	// shorter than the fields.
+	ErrFormat = errors.New("invalid rtcm3 message")
+
+	// ErrUnsupported is returned for the messages not decoded.
+	ErrUnsupported = errors.New("unsupported rtcm3 message")
+)
+
+// preamble is the first byte of a frame.
+const preamble = 0xD3
+
+// maxPayload is the maximum length of the payload of a frame (bytes).
+const maxPayload = 1023
+
+// Frame is a transport frame of a message.
+type Frame struct {
+	// Type is the message number, i.e., the first 12 bits of the payload,
+	// or zero for the empty payload.
+	Type int
+
+	// Payload is the message without the header and the CRC.
+	Payload []byte
+}
+
+// crc24q returns CRC-24Q of the data, i.e., of the polynomial 0x1864CFB
+// without the inversion.
+func crc24q(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for range 8 {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864CFB
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+// bitReader reads the big-endian bit fields of a message. The fields past
+// the end are zero, and short is set.
+type bitReader struct {
+	b     []byte
+	i     int // position (bits)
+	short bool
+}
+
+// u returns the unsigned field of n bits (n <= 64).
+func (r *bitReader) u(n int) uint64 {
+	if r.i+n > 8*len(r.b) {
+		r.short = true
+		r.i += n
+		return 0
+	}
+	var v uint64
+	for k := 0; k < n; k++ {
+		i := r.i + k
+		v = v<<1 | uint64(r.b[i/8]>>(7-i%8)&1)
+	}
+	r.i += n
+	return v
+}
+
+// s returns the signed field of n bits in the two's complement.
+func (r *bitReader) s(n int) int64 {
+	v := r.u(n)
+	if n < 64 && v&(1<<(n-1)) != 0 {
+		return int64(v) - 1<<n
+	}
+	return int64(v)
+}
+
+// messageType returns the message number of the payload.
+func messageType(payload []byte) int {
+	if len(payload) < 2 {
+		return 0
+	}
+	return int(payload[0])<<4 | int(payload[1])>>4
+}
@@ -0,0 +1,98 @@
+package rtcm3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// testStationFrame is the frame of the message 1005 of the example of RTCM
+// 10403.3, i.e., the station 2003 at (1114104.5999, -4850729.7108,
+// 3975521.4643) m.
+const testStationFrame = "D300133ED7D30202980EDEEF34B4BD62AC0941986F33360B98"
+
+// testFrame returns the bytes of the hex string.
+func testFrame(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	return b
+}
+
+// bitWriter writes the big-endian bit fields of the test messages.
+type bitWriter struct {
+	b []byte
+	n int // length (bits)
+}
+
+// u writes the unsigned field of n bits.
+func (w *bitWriter) u(n int, v uint64) {
+	for k := n - 1; k >= 0; k-- {
+		if w.n%8 == 0 {
+			w.b = append(w.b, 0)
+		}
+		w.b[w.n/8] |= byte(v>>k&1) << (7 - w.n%8)
+		w.n++
+	}
+}
+
+// s writes the signed field of n bits in the two's complement.
+func (w *bitWriter) s(n int, v int64) {
+	w.u(n, uint64(v)&(1<<n-1))
+}
+
+// frame returns the transport frame of the payload.
+func frame(payload []byte) []byte {
+	b := append([]byte{preamble, byte(len(payload) >> 8), byte(len(payload))}, payload...)
+	crc := crc24q(b)
+	return append(b, byte(crc>>16), byte(crc>>8), byte(crc))
+}
+
+// TestCRC24Q checks the check value of CRC-24Q and the CRC of the example
+// frame.
+func TestCRC24Q(t *testing.T) {
+	if get := crc24q([]byte("123456789")); get != 0xCDE703 {
+		t.Errorf("check value: get %06X, want CDE703", get)
+	}
+	b := testFrame(t, testStationFrame)
+	n := len(b) - 3
+	want := uint32(b[n])<<16 | uint32(b[n+1])<<8 | uint32(b[n+2])
+	if get := crc24q(b[:n]); get != want {
+		t.Errorf("example frame: get %06X, want %06X", get, want)
+	}
+}
+
+// TestBitReader checks the fields across the bytes, the signed fields and
+// those past the end.
+func TestBitReader(t *testing.T) {
+	var w bitWriter
+	w.u(12, 1077)
+	w.s(14, -8192)
+	w.s(22, 12345)
+	w.u(38, 1<<37+5)
+	w.s(3, -1)
+
+	r := bitReader{b: w.b}
+	if get := r.u(12); get != 1077 {
+		t.Errorf("u(12): get %d, want 1077", get)
+	}
+	if get := r.s(14); get != -8192 {
+		t.Errorf("s(14): get %d, want -8192", get)
+	}
+	if get := r.s(22); get != 12345 {
+		t.Errorf("s(22): get %d, want 12345", get)
+	}
+	if get := r.u(38); get != 1<<37+5 {
+		t.Errorf("u(38): get %d, want %d", get, uint64(1<<37+5))
+	}
+	if get := r.s(3); get != -1 || r.short {
+		t.Errorf("s(3): get %d (short=%v), want -1", get, r.short)
+	}
+	if get := r.u(8); get != 0 || !r.short {
+		t.Errorf("past the end: get %d (short=%v), want 0 (short)", get, r.short)
+	}
+	if get := messageType(w.b); get != 1077 {
+		t.Errorf("messageType: get %d, want 1077", get)
+	}
+}
@@ -0,0 +1,64 @@
+package rtcm3
+
+// msmSignals is the observation codes of RINEX 3 without the types of the
+// signal IDs 1-32 of MSM of the satellite systems (RTCM 10403.3, tables
+// 3.5-91 to 3.5-108), which are blank if undefined.
+var msmSignals = map[byte][33]string{
+	'G': {
+		2: "1C", 3: "1P", 4: "1W",
+		8: "2C", 9: "2P", 10: "2W", 15: "2S", 16: "2L", 17: "2X",
+		22: "5I", 23: "5Q", 24: "5X",
+		30: "1S", 31: "1L", 32: "1X",
+	},
+	'R': {
+		2: "1C", 3: "1P",
+		8: "2C", 9: "2P",
+	},
+	'E': {
+		2: "1C", 3: "1A", 4: "1B", 5: "1X", 6: "1Z",
+		8: "6C", 9: "6A", 10: "6B", 11: "6X", 12: "6Z",
+		14: "7I", 15: "7Q", 16: "7X",
+		18: "8I", 19: "8Q", 20: "8X",
+		22: "5I", 23: "5Q", 24: "5X",
+	},
+	'J': {
+		2: "1C",
+		9: "6S", 10: "6L", 11: "6X",
+		15: "2S", 16: "2L", 17: "2X",
+		22: "5I", 23: "5Q", 24: "5X",
+		30: "1S", 31: "1L", 32: "1X",
+	},
+	'C': {
+		2: "2I", 3: "2Q", 4: "2X",
+		8: "6I", 9: "6Q", 10: "6X",
+		14: "7I", 15: "7Q", 16: "7X",
+		22: "5D", 23: "5P", 24: "5X",
+		25: "7D",
+		30: "1D", 31: "1P", 32: "1X",
+	},
+}
+
+// carriers is the carrier frequencies (Hz) of the bands, i.e., the first
+// characters of the codes, where those of GLONASS are of the channel 0.
+var carriers = map[byte]map[byte]float64{
+	'G': {'1': 1575.42e6, '2': 1227.60e6, '5': 1176.45e6},
+	'R': {'1': 1602e6, '2': 1246e6},
+	'E': {'1': 1575.42e6, '5': 1176.45e6, '6': 1278.75e6, '7': 1207.14e6, '8': 1191.795e6},
+	'J': {'1': 1575.42e6, '2': 1227.60e6, '5': 1176.45e6, '6': 1278.75e6},
+	'C': {'1': 1575.42e6, '2': 1561.098e6, '5': 1176.45e6, '6': 1268.52e6, '7': 1207.14e6},
+}
+
+// glonassSpacing is the spacings of the frequencies of the channels of the
+// bands of GLONASS (Hz).
+var glonassSpacing = map[byte]float64{'1': 0.5625e6, '2': 0.4375e6}
+
+// frequency returns the carrier frequency (Hz) of the code of the system,
+// where that of GLONASS is of the frequency channel number k, or zero if
+// unknown.
+func frequency(sys byte, code string, k int) float64 {
+	f := carriers[sys][code[0]]
+	if sys == 'R' {
+		f += float64(k) * glonassSpacing[code[0]]
+	}
+	return f
+}
@@ -0,0 +1,43 @@
+package rtcm3
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFrequency checks the frequencies of the codes of the systems and the
+// channels of GLONASS.
+func TestFrequency(t *testing.T) {
+	for _, tt := range []struct {
+		sys    byte
+		signal int
+		k      int
+		code   string
+		want   float64
+	}{
+		{'G', 2, 0, "1C", 1575.42e6},
+		{'G', 16, 0, "2L", 1227.60e6},
+		{'G', 22, 0, "5I", 1176.45e6},
+		{'R', 2, -7, "1C", 1598.0625e6},
+		{'R', 8, 6, "2C", 1248.625e6},
+		{'E', 5, 0, "1X", 1575.42e6},
+		{'E', 16, 0, "7X", 1207.14e6},
+		{'E', 20, 0, "8X", 1191.795e6},
+		{'J', 10, 0, "6L", 1278.75e6},
+		{'C', 2, 0, "2I", 1561.098e6},
+		{'C', 8, 0, "6I", 1268.52e6},
+		{'C', 23, 0, "5P", 1176.45e6},
+	} {
+		code := msmSignals[tt.sys][tt.signal]
+		if code != tt.code {
+			t.Errorf("%c signal %d: get %q, want %q", tt.sys, tt.signal, code, tt.code)
+			continue
+		}
+		if get := frequency(tt.sys, code, tt.k); math.Abs(get-tt.want) > 1e-3 {
+			t.Errorf("%c %s (k=%d): get %v, want %v", tt.sys, code, tt.k, get, tt.want)
+		}
+	}
+	if code := msmSignals['R'][4]; code != "" {
+		t.Errorf("undefined signal: get %q", code)
+	}
+}
@@ -0,0 +1,51 @@
+package rtcm3
+
+import "fmt"
+
+// Station is the reference station of the messages 1005 and 1006.
+type Station struct {
+	// ID is the reference station ID, and ITRFYear is the realization year
+	// of ITRF, or zero if not given.
+	ID       int
+	ITRFYear int
+
+	// GPS, GLONASS and Galileo are the indicators of the systems of the
+	// station.
+	GPS, GLONASS, Galileo bool
+
+	// ARP is the position (ECEF, m) of the antenna reference point, and
+	// Height is the antenna height (m) of 1006, or zero for 1005.
+	ARP    [3]float64
+	Height float64
+}
+
+// DecodeStation decodes the payload of the message 1005 or 1006.
+// ErrUnsupported is returned for the other messages, and ErrFormat for the
+// invalid contents.
+func DecodeStation(payload []byte) (*Station, error) {
+	typ := messageType(payload)
+	if typ != 1005 && typ != 1006 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupported, typ)
+	}
+
+	r := bitReader{b: payload, i: 12}
+	s := &Station{}
+	s.ID = int(r.u(12))
+	s.ITRFYear = int(r.u(6))
+	s.GPS = r.u(1) == 1
+	s.GLONASS = r.u(1) == 1
+	s.Galileo = r.u(1) == 1
+	r.u(1) // reference station indicator
+	s.ARP[0] = float64(r.s(38)) * 1e-4
+	r.u(2) // single receiver oscillator indicator and reserved
+	s.ARP[1] = float64(r.s(38)) * 1e-4
+	r.u(2) // quarter cycle indicator
+	s.ARP[2] = float64(r.s(38)) * 1e-4
+	if typ == 1006 {
+		s.Height = float64(r.u(16)) * 1e-4
+	}
+	if r.short {
+		return nil, fmt.Errorf("%w: %d: too short", ErrFormat, typ)
+	}
+	return s, nil
+}
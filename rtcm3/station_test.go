@@ -0,0 +1,55 @@
+package rtcm3
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestDecodeStation checks the example message 1005, the message 1006 and
+// the errors.
+func TestDecodeStation(t *testing.T) {
+	b := testFrame(t, testStationFrame)
+	s, err := DecodeStation(b[3 : len(b)-3])
+	if err != nil {
+		t.Fatalf("DecodeStation: %v", err)
+	}
+	want := [3]float64{1114104.5999, -4850729.7108, 3975521.4643}
+	for k := range want {
+		if math.Abs(s.ARP[k]-want[k]) > 1e-6 {
+			t.Errorf("ARP[%d]: get %.4f, want %.4f", k, s.ARP[k], want[k])
+		}
+	}
+	if s.ID != 2003 || s.ITRFYear != 0 || !s.GPS || s.GLONASS || s.Galileo || s.Height != 0 {
+		t.Errorf("station: get %+v", s)
+	}
+
+	// 1006 of the antenna height 1.5 m
+	var w bitWriter
+	w.u(12, 1006)
+	w.u(12, 1)
+	w.u(6, 14)
+	w.u(4, 0b1110)
+	w.s(38, -39424839170)
+	w.u(2, 0)
+	w.s(38, 33310123456)
+	w.u(2, 0)
+	w.s(38, 37029981234)
+	w.u(16, 15000)
+	s, err = DecodeStation(w.b)
+	switch {
+	case err != nil:
+		t.Errorf("1006: %v", err)
+	case s.ID != 1 || s.ITRFYear != 14 || !s.GPS || !s.GLONASS || !s.Galileo || s.Height != 1.5:
+		t.Errorf("1006: get %+v", s)
+	case math.Abs(s.ARP[0]+3942483.9170) > 1e-6 || math.Abs(s.ARP[1]-3331012.3456) > 1e-6 || math.Abs(s.ARP[2]-3702998.1234) > 1e-6:
+		t.Errorf("1006: ARP %v", s.ARP)
+	}
+
+	if _, err := DecodeStation(w.b[:20]); !errors.Is(err, ErrFormat) {
+		t.Errorf("short: get err=%v, want %v", err, ErrFormat)
+	}
+	if _, err := DecodeStation([]byte{0x43, 0x50}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("1077: get err=%v, want %v", err, ErrUnsupported)
+	}
+}
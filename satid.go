@@ -0,0 +1,132 @@
+/*
+Package gnss provides the types shared by the packages of the module, e.g.,
+the satellite identifiers.
+*/
+package gnss
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrSatID is returned for the malformed satellite identifiers.
+var ErrSatID = errors.New("invalid satellite ID")
+
+// SatelliteSystem is the satellite system by the letter of RINEX, e.g., 'G'.
+type SatelliteSystem byte
+
+// the satellite systems of RINEX
+const (
+	GPS     SatelliteSystem = 'G'
+	GLONASS SatelliteSystem = 'R'
+	Galileo SatelliteSystem = 'E'
+	BeiDou  SatelliteSystem = 'C'
+	QZSS    SatelliteSystem = 'J'
+	NavIC   SatelliteSystem = 'I'
+	SBAS    SatelliteSystem = 'S'
+)
+
+// Valid reports whether s is one of the satellite systems of RINEX.
+func (s SatelliteSystem) Valid() bool {
+	switch s {
+	case GPS, GLONASS, Galileo, BeiDou, QZSS, NavIC, SBAS:
+		return true
+	}
+	return false
+}
+
+// String returns the letter of the satellite system, e.g., "G".
+func (s SatelliteSystem) String() string {
+	return string(rune(s))
+}
+
+// SatID is the identifier of a satellite, i.e., the satellite system and the
+// PRN (or the slot number of GLONASS) of 1-99. The zero value is the unknown
+// satellite.
+type SatID struct {
+	Sys SatelliteSystem
+	PRN int
+}
+
+// ParseSatID parses the satellite ID of RINEX, e.g., "G01", including the
+// spelling of the older files with the blank, e.g., "G 1".
+func ParseSatID(s string) (SatID, error) {
+	if len(s) != 3 {
+		return SatID{}, fmt.Errorf("%w: '%s'", ErrSatID, s)
+	}
+	sys := SatelliteSystem(s[0])
+	if !sys.Valid() {
+		return SatID{}, fmt.Errorf("%w: unknown system: '%s'", ErrSatID, s)
+	}
+	d := s[1:]
+	if d[0] == ' ' {
+		d = d[1:]
+	}
+	for _, c := range []byte(d) {
+		if c < '0' || c > '9' {
+			return SatID{}, fmt.Errorf("%w: PRN: '%s'", ErrSatID, s)
+		}
+	}
+	prn, _ := strconv.Atoi(d)
+	if prn == 0 {
+		return SatID{}, fmt.Errorf("%w: PRN: '%s'", ErrSatID, s)
+	}
+	return SatID{sys, prn}, nil
+}
+
+// IsZero reports whether id is the zero value, i.e., the unknown satellite.
+func (id SatID) IsZero() bool {
+	return id == SatID{}
+}
+
+// String returns the satellite ID of RINEX 3, e.g., "G01", or "" for the zero
+// value.
+func (id SatID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%c%02d", id.Sys, id.PRN)
+}
+
+// Compare returns -1, 0 or +1 by the order of the satellite systems in the
+// letters and then of the PRNs, e.g., for slices.SortFunc.
+func (id SatID) Compare(other SatID) int {
+	switch {
+	case id.Sys < other.Sys:
+		return -1
+	case id.Sys > other.Sys:
+		return 1
+	case id.PRN < other.PRN:
+		return -1
+	case id.PRN > other.PRN:
+		return 1
+	}
+	return 0
+}
+
+// Less reports whether id is ordered before other (see Compare).
+func (id SatID) Less(other SatID) bool {
+	return id.Compare(other) < 0
+}
+
+// MarshalText returns the satellite ID of String, which is also used for
+// the JSON strings and the keys of the JSON objects.
+func (id SatID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText parses the satellite ID of ParseSatID, or the empty text as
+// the zero value.
+func (id *SatID) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*id = SatID{}
+		return nil
+	}
+	v, err := ParseSatID(string(b))
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
@@ -0,0 +1,96 @@
+package gnss
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestParseSatID checks the spellings of the satellite IDs and the errors of
+// the malformed ones.
+func TestParseSatID(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want SatID
+	}{
+		{"G01", SatID{GPS, 1}},
+		{"G 1", SatID{GPS, 1}},
+		{"R24", SatID{GLONASS, 24}},
+		{"E 5", SatID{Galileo, 5}},
+		{"C60", SatID{BeiDou, 60}},
+		{"J02", SatID{QZSS, 2}},
+		{"S20", SatID{SBAS, 20}},
+		{"I09", SatID{NavIC, 9}},
+	} {
+		get, err := ParseSatID(tt.s)
+		if err != nil || get != tt.want {
+			t.Errorf("'%s': get %+v (err=%v), want %+v", tt.s, get, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "G", "G1", "G001", "X01", "g01", " 01", "G00", "G 0", "G1 ", "G-1", "G+1", "G0A"} {
+		if id, err := ParseSatID(s); !errors.Is(err, ErrSatID) {
+			t.Errorf("'%s': get %+v (err=%v), want %v", s, id, err, ErrSatID)
+		}
+	}
+}
+
+// TestSatIDString checks the round trip of String and the zero value.
+func TestSatIDString(t *testing.T) {
+	for _, s := range []string{"G01", "R24", "C60"} {
+		id, err := ParseSatID(s)
+		if err != nil || id.String() != s {
+			t.Errorf("'%s': get '%s' (err=%v)", s, id, err)
+		}
+	}
+	if id, _ := ParseSatID("G 1"); id.String() != "G01" {
+		t.Errorf("'G 1': get '%s', want 'G01'", id)
+	}
+	if s := (SatID{}).String(); s != "" || !(SatID{}).IsZero() {
+		t.Errorf("zero: get '%s'", s)
+	}
+}
+
+// TestSatIDCompare checks the order of the systems and the PRNs.
+func TestSatIDCompare(t *testing.T) {
+	ids := []SatID{{GPS, 10}, {BeiDou, 1}, {GPS, 2}, {Galileo, 3}, {GLONASS, 1}}
+	slices.SortFunc(ids, SatID.Compare)
+	want := []SatID{{BeiDou, 1}, {Galileo, 3}, {GPS, 2}, {GPS, 10}, {GLONASS, 1}}
+	if !slices.Equal(ids, want) {
+		t.Errorf("get %v, want %v", ids, want)
+	}
+	if !(SatID{GPS, 2}).Less(SatID{GPS, 10}) || (SatID{GPS, 2}).Compare(SatID{GPS, 2}) != 0 {
+		t.Errorf("Less/Compare")
+	}
+}
+
+// TestSatIDJSON checks the JSON strings, the keys of the objects and the
+// errors of the malformed strings.
+func TestSatIDJSON(t *testing.T) {
+	type rec struct {
+		Sat   SatID
+		Zero  SatID
+		Delay map[SatID]float64
+	}
+	in := rec{Sat: SatID{Galileo, 11}, Delay: map[SatID]float64{{GPS, 1}: 1.5}}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `{"Sat":"E11","Zero":"","Delay":{"G01":1.5}}`; string(b) != want {
+		t.Errorf("Marshal: get %s, want %s", b, want)
+	}
+
+	var out rec
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Sat != in.Sat || !out.Zero.IsZero() || out.Delay[SatID{GPS, 1}] != 1.5 {
+		t.Errorf("Unmarshal: get %+v, want %+v", out, in)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Sat":"G1x"}`), &out); !errors.Is(err, ErrSatID) {
+		t.Errorf("Unmarshal: get err=%v, want %v", err, ErrSatID)
+	}
+}
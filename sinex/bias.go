@@ -0,0 +1,185 @@
+package sinex
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoBias is returned when the biases of the satellite and the codes are
+// not in the file, or not valid at the epoch.
+var ErrNoBias = errors.New("no bias")
+
+// ErrUnit is returned for the biases of the units other than ns.
+var ErrUnit = errors.New("unsupported bias unit")
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+// The types of the biases of Bias-SINEX.
+const (
+	OSB = "OSB" // observable-specific signal bias
+	DSB = "DSB" // differential signal bias
+	ISB = "ISB" // ionosphere-free signal bias
+)
+
+// BiasHeader stores the header line of a Bias-SINEX file, i.e.,
+//
+//	%=BIA 1.00 AGY YYYY:DDD:SSSSS AGY YYYY:DDD:SSSSS YYYY:DDD:SSSSS M NNNNNNNN
+type BiasHeader struct {
+	Version float64
+
+	// Agency is the agency creating the file, and Created is the epoch of
+	// the creation.
+	Agency  string
+	Created time.Time
+
+	// DataAgency is the agency providing the data, and Start and End are the
+	// time span of the data.
+	DataAgency string
+	Start, End time.Time
+
+	// Mode is the bias mode, i.e., 'A' of the absolute biases (OSB) or 'R'
+	// of the relative biases (DSB), and NumEstimates is the number of the
+	// biases of the file.
+	Mode         byte
+	NumEstimates int
+
+	// TimeSystem is the time system of the epochs of BIAS/DESCRIPTION, e.g.,
+	// "G" of GPS time (default) or "UTC".
+	TimeSystem string
+}
+
+// BiasFile stores the contents of a Bias-SINEX file.
+type BiasFile struct {
+	Header BiasHeader
+
+	// Biases is the biases of BIAS/SOLUTION in the order of the file.
+	Biases []*Bias
+}
+
+// Bias is a record of BIAS/SOLUTION of the columns
+//
+//	*BIAS SVN_ PRN STATION__ OBS1 OBS2 BIAS_START____ BIAS_END______ UNIT __ESTIMATED_VALUE____ _STD_DEV___
+//
+// The bias is that of the observation, i.e., the observation is corrected by
+// subtracting the bias. The DSB of OBS1 and OBS2 is the difference of the
+// biases of OBS1 and OBS2.
+type Bias struct {
+	// Type is the type of the bias, i.e., OSB, DSB or ISB.
+	Type string
+
+	// SVN and PRN are the satellite, e.g., "G063" and "G01", and Station is
+	// the station of the receiver biases, where PRN is the satellite system,
+	// e.g., "G", for the biases of all the satellites of the system.
+	SVN, PRN string
+	Station  string
+
+	// Obs1 and Obs2 are the observation codes, e.g., "C1C", where Obs2 is
+	// empty for OSB.
+	Obs1, Obs2 string
+
+	// Start and End are the validity interval, which are zero if unbounded.
+	Start, End time.Time
+
+	// Unit is the unit of Value and Sigma, e.g., "ns" of the code biases or
+	// "cyc" of the phase biases.
+	Unit         string
+	Value, Sigma float64
+}
+
+// NsToMeters returns the range (m) of the bias ns (ns).
+func NsToMeters(ns float64) float64 {
+	return ns * 1e-9 * lightVelocity
+}
+
+// MetersToNs returns the bias (ns) of the range m (m).
+func MetersToNs(m float64) float64 {
+	return m / lightVelocity * 1e9
+}
+
+// Meters returns the value of the bias in meters, or ErrUnit for the units
+// other than ns.
+func (b *Bias) Meters() (float64, error) {
+	if b.Unit != "ns" {
+		return 0, fmt.Errorf("%w: %s of %s %s", ErrUnit, b.Unit, b.PRN, b.Obs1)
+	}
+	return NsToMeters(b.Value), nil
+}
+
+// Valid reports whether the epoch t is in the validity interval, i.e.,
+// Start <= t < End.
+func (b *Bias) Valid(t time.Time) bool {
+	return (b.Start.IsZero() || !t.Before(b.Start)) && (b.End.IsZero() || t.Before(b.End))
+}
+
+// Select returns the bias of the type of the satellite, e.g., "G01", and the
+// codes valid at the epoch t, where obs2 is empty for OSB. The biases of the
+// stations are not selected. The bias of the latest start is returned if
+// several are valid.
+func (f *BiasFile) Select(typ, sat, obs1, obs2 string, t time.Time) (*Bias, error) {
+	var best *Bias
+	for _, b := range f.Biases {
+		if b.Type != typ || b.PRN != sat || b.Station != "" || b.Obs1 != obs1 || b.Obs2 != obs2 || !b.Valid(t) {
+			continue
+		}
+		if best == nil || !b.Start.Before(best.Start) {
+			best = b
+		}
+	}
+	if best == nil {
+		if obs2 != "" {
+			obs1 += "-" + obs2
+		}
+		return nil, fmt.Errorf("%w: %s %s of %s at %v", ErrNoBias, typ, obs1, sat, t)
+	}
+	return best, nil
+}
+
+// CodeBias returns the bias (m) of the code obs relative to the code ref of
+// the satellite at the epoch t, i.e., B(obs)-B(ref), by the OSBs of the codes
+// or by the DSB of the codes in either order. Zero is returned if obs is ref.
+func (f *BiasFile) CodeBias(sat, obs, ref string, t time.Time) (float64, error) {
+	if obs == ref {
+		return 0, nil
+	}
+	if b, err := f.Select(OSB, sat, obs, "", t); err == nil {
+		if r, err := f.Select(OSB, sat, ref, "", t); err == nil {
+			return diffMeters(b, r)
+		}
+	}
+	if b, err := f.Select(DSB, sat, obs, ref, t); err == nil {
+		return b.Meters()
+	}
+	b, err := f.Select(DSB, sat, ref, obs, t)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s relative to %s of %s at %v", ErrNoBias, obs, ref, sat, t)
+	}
+	m, err := b.Meters()
+	return -m, err
+}
+
+// diffMeters returns the difference of the biases (m).
+func diffMeters(b, r *Bias) (float64, error) {
+	mb, err := b.Meters()
+	if err != nil {
+		return 0, err
+	}
+	mr, err := r.Meters()
+	if err != nil {
+		return 0, err
+	}
+	return mb - mr, nil
+}
+
+// CorrectCode returns the pseudorange pr (m) of the code obs of the satellite
+// at the epoch t aligned to the code ref, e.g., the reference code of the
+// precise clocks in use such as C1W of GPS, i.e., pr-(B(obs)-B(ref)) of
+// CodeBias.
+func (f *BiasFile) CorrectCode(pr float64, sat, obs, ref string, t time.Time) (float64, error) {
+	b, err := f.CodeBias(sat, obs, ref, t)
+	if err != nil {
+		return pr, err
+	}
+	return pr - b, nil
+}
@@ -0,0 +1,183 @@
+package sinex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestNsToMeters checks the conversions of the biases.
+func TestNsToMeters(t *testing.T) {
+	if m := NsToMeters(1); math.Abs(m-0.299792458) > 1e-12 {
+		t.Errorf("get %v, want 0.299792458", m)
+	}
+	if ns := MetersToNs(NsToMeters(-3.558)); math.Abs(ns+3.558) > 1e-12 {
+		t.Errorf("get %v, want -3.558", ns)
+	}
+	b := Bias{Unit: "cyc", Value: 0.1}
+	if _, err := b.Meters(); !errors.Is(err, ErrUnit) {
+		t.Errorf("get err=%v, want %v", err, ErrUnit)
+	}
+}
+
+// TestSelect checks the biases of the validity intervals.
+func TestSelect(t *testing.T) {
+	f, err := ReadBiasFile(testOSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		t    time.Time
+		want float64
+	}{
+		{t0, -3.558},
+		{t0.Add(24*time.Hour - time.Second), -3.558},
+		{t0.Add(24 * time.Hour), -3.601},
+		{t0.Add(36 * time.Hour), -3.601},
+	} {
+		if b, err := f.Select(OSB, "G01", "C1C", "", tt.t); err != nil || b.Value != tt.want {
+			t.Errorf("%v: get %+v (err=%v), want %v", tt.t, b, err, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		sat, obs string
+		t        time.Time
+	}{
+		{"G01", "C1C", t0.Add(-time.Second)},
+		{"G01", "C1C", t0.Add(48 * time.Hour)},
+		{"G01", "C2L", t0},
+		{"G03", "C1C", t0},
+	} {
+		if _, err := f.Select(OSB, tt.sat, tt.obs, "", tt.t); !errors.Is(err, ErrNoBias) {
+			t.Errorf("%s %s %v: get err=%v, want %v", tt.sat, tt.obs, tt.t, err, ErrNoBias)
+		}
+	}
+
+	// the biases of the stations are not selected
+	f, err = ReadBiasFile(testDSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	if _, err := f.Select(DSB, "G", "C1C", "C1W", t0); !errors.Is(err, ErrNoBias) {
+		t.Errorf("get err=%v, want %v", err, ErrNoBias)
+	}
+}
+
+// TestCodeBias checks the biases of the codes relative to the reference
+// codes by the OSBs and by the DSBs, which agree for the fixtures.
+func TestCodeBias(t *testing.T) {
+	osb, err := ReadBiasFile(testOSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	dsb, err := ReadBiasFile(testDSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 11, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		sat, obs, ref string
+		want          float64 // ns
+	}{
+		{"G01", "C1C", "C1W", 0.565},
+		{"G01", "C1W", "C1C", -0.565},
+		{"G01", "C1W", "C2W", 2.667},
+		{"G02", "C1C", "C1W", -0.797},
+		{"E01", "C1C", "C5Q", 0.887},
+		{"G14", "C1C", "C1C", 0},
+	} {
+		for _, f := range []*BiasFile{osb, dsb} {
+			if tt.sat == "G14" && f == dsb {
+				continue
+			}
+			get, err := f.CodeBias(tt.sat, tt.obs, tt.ref, t0)
+			if err != nil || math.Abs(get-NsToMeters(tt.want)) > 1e-3 {
+				t.Errorf("%s %s-%s of %s: get %.4f m (err=%v), want %.4f m", f.Header.Agency, tt.obs, tt.ref, tt.sat, get, err, NsToMeters(tt.want))
+			}
+		}
+	}
+
+	if _, err := dsb.CodeBias("G14", "C1C", "C1W", t0); !errors.Is(err, ErrNoBias) {
+		t.Errorf("get err=%v, want %v", err, ErrNoBias)
+	}
+	if _, err := osb.CodeBias("G02", "C1C", "C5Q", t0); !errors.Is(err, ErrNoBias) {
+		t.Errorf("get err=%v, want %v", err, ErrNoBias)
+	}
+}
+
+// The code biases of 2024-07-14 (day 196) of CAS and CODE, which are not
+// distributed with the module. TestCodeBiasProducts is skipped unless the
+// decompressed files are put in testdata, e.g., from CDDIS.
+const (
+	testCASPattern  = "testdata/CAS0MGXRAP_20241960000_01D_01D_DCB.BSX"
+	testCODEPattern = "testdata/COD0MGXFIN_20241960000_01D_*_OSB.BIA"
+)
+
+// TestCodeBiasProducts checks the biases of C1C and C2L relative to C1W and
+// C2W of the GPS satellites by the DSBs of CAS against those by the OSBs of
+// CODE, which are estimated independently and agree within 0.5 ns, and the
+// pseudoranges corrected by them.
+func TestCodeBiasProducts(t *testing.T) {
+	var fs [2]*BiasFile
+	for i, pattern := range []string{testCASPattern, testCODEPattern} {
+		m, _ := filepath.Glob(pattern)
+		if len(m) == 0 {
+			t.Skipf("no product in testdata: %s", pattern)
+		}
+		f, err := ReadBiasFile(m[0])
+		if err != nil {
+			t.Fatalf("ReadBiasFile: %v", err)
+		}
+		fs[i] = f
+	}
+	cas, code := fs[0], fs[1]
+	if cas.Header.Mode != 'R' || code.Header.Mode != 'A' {
+		t.Errorf("modes: CAS %c, CODE %c", cas.Header.Mode, code.Header.Mode)
+	}
+
+	t0 := time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC)
+	const pr = 21234567.890
+	var n int
+	for prn := 1; prn <= 32; prn++ {
+		sat := fmt.Sprintf("G%02d", prn)
+		for _, c := range [][2]string{{"C1C", "C1W"}, {"C2L", "C2W"}} {
+			b1, err1 := cas.CodeBias(sat, c[0], c[1], t0)
+			b2, err2 := code.CodeBias(sat, c[0], c[1], t0)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			if d := MetersToNs(b1 - b2); math.Abs(d) > 0.5 {
+				t.Errorf("%s %s-%s: CAS %.3f ns, CODE %.3f ns", sat, c[0], c[1], MetersToNs(b1), MetersToNs(b2))
+			}
+			if get, err := cas.CorrectCode(pr, sat, c[0], c[1], t0); err != nil || get != pr-b1 {
+				t.Errorf("%s %s: get %.4f (err=%v), want %.4f", sat, c[0], get, err, pr-b1)
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		t.Errorf("no bias compared")
+	}
+}
+
+// TestCorrectCode checks the pseudorange of C1C aligned to C1W of the
+// clocks.
+func TestCorrectCode(t *testing.T) {
+	f, err := ReadBiasFile(testOSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 11, 12, 0, 0, 0, time.UTC)
+	const pr = 21234567.890
+	get, err := f.CorrectCode(pr, "G01", "C1C", "C1W", t0)
+	if want := pr - 0.565*0.299792458; err != nil || math.Abs(get-want) > 1e-6 {
+		t.Errorf("get %.4f (err=%v), want %.4f", get, err, want)
+	}
+	if get, err := f.CorrectCode(pr, "G03", "C1C", "C1W", t0); !errors.Is(err, ErrNoBias) || get != pr {
+		t.Errorf("get %.4f (err=%v), want %v", get, err, ErrNoBias)
+	}
+}
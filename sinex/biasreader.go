@@ -0,0 +1,182 @@
+package sinex
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ReadBiasFile reads the Bias-SINEX file of the name.
+func ReadBiasFile(name string) (*BiasFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseBias(f)
+}
+
+// ParseBias reads a Bias-SINEX file of the version 1 from r, e.g., the OSB
+// products of CODE and the DSB products of CAS.
+//
+// The blocks BIAS/DESCRIPTION and BIAS/SOLUTION are parsed, and the other
+// blocks are skipped. The errors are wrapped with the line number, and the
+// format errors are tested by errors.Is with ErrFormat.
+func ParseBias(r io.Reader) (*BiasFile, error) {
+	p := biasParser{s: mscanner.NewScanner(r)}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return f, nil
+}
+
+// biasParser holds the state of ParseBias.
+type biasParser struct {
+	s *mscanner.Scanner
+	f BiasFile
+}
+
+func (p *biasParser) parse() (*BiasFile, error) {
+	if !p.s.Scan() {
+		if err := p.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: empty file", ErrFormat)
+	}
+	if err := p.parseHeader(line(p.s.Text())); err != nil {
+		return nil, err
+	}
+	p.f.Header.TimeSystem = "G"
+
+	for p.s.Scan() {
+		l := line(p.s.Text())
+		switch {
+		case l == "" || l[0] == '*':
+			continue
+		case strings.HasPrefix(l, "%=ENDBIA"):
+			return &p.f, nil
+		case l[0] == '+':
+			if err := p.parseBlock(strings.TrimSpace(l[1:])); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: line out of blocks: '%s'", ErrFormat, l)
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: no %%=ENDBIA", ErrFormat)
+}
+
+// parseHeader parses the header line.
+func (p *biasParser) parseHeader(l string) (err error) {
+	fs := strings.Fields(l)
+	if len(fs) < 9 || fs[0] != "%=BIA" {
+		return fmt.Errorf("%w: bias header line: '%s'", ErrFormat, l)
+	}
+	h := &p.f.Header
+	if h.Version, err = strconv.ParseFloat(fs[1], 64); err != nil || h.Version < 1 || h.Version >= 2 {
+		return fmt.Errorf("%w: version: '%s'", ErrFormat, fs[1])
+	}
+	h.Agency, h.DataAgency = fs[2], fs[4]
+	if h.Created, err = parseEpoch(fs[3]); err != nil {
+		return err
+	}
+	if h.Start, err = parseEpoch(fs[5]); err != nil {
+		return err
+	}
+	if h.End, err = parseEpoch(fs[6]); err != nil {
+		return err
+	}
+	if h.Mode = fs[7][0]; h.Mode != 'A' && h.Mode != 'R' {
+		return fmt.Errorf("%w: bias mode: '%s'", ErrFormat, fs[7])
+	}
+	if h.NumEstimates, err = strconv.Atoi(fs[8]); err != nil {
+		return fmt.Errorf("%w: number of estimates: '%s'", ErrFormat, fs[8])
+	}
+	return nil
+}
+
+// parseBlock parses the lines of the block of the name until its end line.
+func (p *biasParser) parseBlock(name string) error {
+	for p.s.Scan() {
+		l := line(p.s.Text())
+		switch {
+		case l == "" || l[0] == '*':
+			continue
+		case l[0] == '-':
+			if end := strings.TrimSpace(l[1:]); end != name {
+				return fmt.Errorf("%w: end of block %s: '%s'", ErrFormat, name, end)
+			}
+			return nil
+		case l[0] == '+' || l[0] == '%':
+			return fmt.Errorf("%w: no end of block %s", ErrFormat, name)
+		}
+
+		switch name {
+		case "BIAS/DESCRIPTION":
+			if fs := strings.Fields(l); len(fs) == 2 && fs[0] == "TIME_SYSTEM" {
+				p.f.Header.TimeSystem = fs[1]
+			}
+		case "BIAS/SOLUTION":
+			b, err := parseBias(l)
+			if err != nil {
+				return err
+			}
+			p.f.Biases = append(p.f.Biases, b)
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: no end of block %s", ErrFormat, name)
+}
+
+// parseBias parses a line of BIAS/SOLUTION of the fixed columns, where the
+// fields after the unit are separated by the spaces, i.e., the estimated
+// value, the standard deviation and the slopes not used.
+func parseBias(l string) (*Bias, error) {
+	if len(l) < 71 {
+		return nil, fmt.Errorf("%w: bias: '%s'", ErrFormat, l)
+	}
+	b := &Bias{
+		Type:    strings.TrimSpace(l[1:5]),
+		SVN:     strings.TrimSpace(l[6:10]),
+		PRN:     strings.TrimSpace(l[11:14]),
+		Station: strings.TrimSpace(l[15:24]),
+		Obs1:    strings.TrimSpace(l[25:29]),
+		Obs2:    strings.TrimSpace(l[30:34]),
+		Unit:    strings.TrimSpace(l[65:69]),
+	}
+	var err error
+	if b.Start, err = parseEpoch(strings.TrimSpace(l[35:49])); err != nil {
+		return nil, err
+	}
+	if b.End, err = parseEpoch(strings.TrimSpace(l[50:64])); err != nil {
+		return nil, err
+	}
+	if b.Type == "" || b.PRN == "" || b.Obs1 == "" || (b.Type != OSB && b.Obs2 == "") {
+		return nil, fmt.Errorf("%w: bias: '%s'", ErrFormat, l)
+	}
+
+	fs := strings.Fields(l[70:])
+	if len(fs) == 0 {
+		return nil, fmt.Errorf("%w: bias %s %s of %s: no value", ErrFormat, b.Type, b.Obs1, b.PRN)
+	}
+	if b.Value, err = strconv.ParseFloat(fs[0], 64); err != nil {
+		return nil, fmt.Errorf("%w: bias %s %s of %s: %v", ErrFormat, b.Type, b.Obs1, b.PRN, err)
+	}
+	if len(fs) > 1 {
+		if b.Sigma, err = strconv.ParseFloat(fs[1], 64); err != nil {
+			return nil, fmt.Errorf("%w: sigma %s %s of %s: %v", ErrFormat, b.Type, b.Obs1, b.PRN, err)
+		}
+	}
+	return b, nil
+}
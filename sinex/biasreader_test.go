@@ -0,0 +1,93 @@
+package sinex
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the synthetic OSBs and DSBs in the format of the products of CODE and CAS,
+// not the values of the products, where the values of the satellites are
+// consistent between the files, and G01 has the OSB of C1C of the next day
+const (
+	testOSBFile = "testdata/SYN0MGXTST_20241930000_01D_01D_OSB_synthetic.BIA"
+	testDSBFile = "testdata/SYN0MGXTST_20241930000_01D_01D_DCB_synthetic.BSX"
+)
+
+// TestReadBiasFile checks the headers and the biases of the fixtures.
+func TestReadBiasFile(t *testing.T) {
+	f, err := ReadBiasFile(testOSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	h := f.Header
+	t0 := time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)
+	switch {
+	case h.Version != 1 || h.Agency != "SYN" || h.DataAgency != "SYN" || h.Mode != 'A' || h.NumEstimates != 13 || h.TimeSystem != "G":
+		t.Errorf("header: %+v", h)
+	case !h.Created.Equal(time.Date(2024, 7, 19, 15, 23, 33, 0, time.UTC)) || !h.Start.Equal(t0) || !h.End.Equal(t0.Add(24*time.Hour)):
+		t.Errorf("header epochs: %v %v %v", h.Created, h.Start, h.End)
+	}
+	if len(f.Biases) != 13 {
+		t.Fatalf("get %d biases, want 13", len(f.Biases))
+	}
+	want := Bias{
+		Type: OSB, SVN: "G063", PRN: "G01", Obs1: "C1C",
+		Start: t0, End: t0.Add(24 * time.Hour),
+		Unit: "ns", Value: -3.558, Sigma: 0.0031,
+	}
+	if b := f.Biases[0]; *b != want {
+		t.Errorf("get %+v, want %+v", *b, want)
+	}
+	if b := f.Biases[7]; b.SVN != "G077" || b.PRN != "G14" || b.Sigma != 0.0064 {
+		t.Errorf("get %+v", *b)
+	}
+
+	f, err = ReadBiasFile(testDSBFile)
+	if err != nil {
+		t.Fatalf("ReadBiasFile: %v", err)
+	}
+	if f.Header.Mode != 'R' || len(f.Biases) != 5 {
+		t.Fatalf("header: %+v, %d biases", f.Header, len(f.Biases))
+	}
+	if b := f.Biases[1]; b.Type != DSB || b.PRN != "G01" || b.Obs1 != "C1W" || b.Obs2 != "C2W" || b.Value != 2.667 || b.Sigma != 0.008 {
+		t.Errorf("get %+v", *b)
+	}
+	if b := f.Biases[4]; b.SVN != "" || b.PRN != "G" || b.Station != "ALGO00CAN" || b.Value != -1.234 {
+		t.Errorf("station: get %+v", *b)
+	}
+}
+
+// TestParseBiasErrors checks the files modified.
+func TestParseBiasErrors(t *testing.T) {
+	data, err := os.ReadFile(testOSBFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		old, new string
+	}{
+		{"header", "%=BIA 1.00", "%=SNX 1.00"},
+		{"version", "%=BIA 1.00", "%=BIA 2.00"},
+		{"mode", "2024:194:00000 A", "2024:194:00000 X"},
+		{"no block end", "-BIAS/DESCRIPTION\n", ""},
+		{"block end", "-BIAS/SOLUTION", "-BIAS/SOLUTIONS"},
+		{"start", "C1W       2024:193:00000", "C1W       2024:393:00000"},
+		{"value", "-4.1230", "-4.1x30"},
+		{"sigma", "-4.1230      0.0028", "-4.1230      0.00x8"},
+		{"no value", "-4.1230      0.0028", ""},
+		{"no obs", " C1W       2024", "           2024"},
+		{"no end", "%=ENDBIA", ""},
+	} {
+		s := strings.Replace(string(data), tt.old, tt.new, 1)
+		if s == string(data) {
+			t.Fatalf("%s: not modified", tt.name)
+		}
+		if _, err := ParseBias(strings.NewReader(s)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrFormat)
+		}
+	}
+}
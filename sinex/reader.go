@@ -0,0 +1,354 @@
+package sinex
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// ReadFile reads the SINEX file of the name.
+func ReadFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a SINEX file of the version 2 from r.
+//
+// The blocks are delimited by the lines of "+" and "-" followed by the block
+// name, and the unknown blocks are skipped. The errors are wrapped with the
+// line number, and the format errors are tested by errors.Is with ErrFormat.
+func Parse(r io.Reader) (*File, error) {
+	p := parser{s: mscanner.NewScanner(r), sites: map[string]*Site{}, parts: map[*Solution]int{}}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return f, nil
+}
+
+// parser holds the state of Parse.
+type parser struct {
+	s     *mscanner.Scanner
+	f     File
+	sites map[string]*Site // sites of the codes
+
+	// parts is the components of the estimates of the solutions, i.e., the
+	// bits of STAX to VELZ.
+	parts map[*Solution]int
+}
+
+// the bits of the parameter types of the estimates
+const (
+	partPosition = 1<<3 - 1
+	partVelocity = partPosition << 3
+)
+
+var paramTypes = map[string]int{
+	"STAX": 0, "STAY": 1, "STAZ": 2,
+	"VELX": 3, "VELY": 4, "VELZ": 5,
+}
+
+func (p *parser) parse() (*File, error) {
+	if !p.s.Scan() {
+		if err := p.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: empty file", ErrFormat)
+	}
+	if err := p.parseHeader(line(p.s.Text())); err != nil {
+		return nil, err
+	}
+
+	for p.s.Scan() {
+		l := line(p.s.Text())
+		switch {
+		case l == "" || l[0] == '*':
+			continue
+		case strings.HasPrefix(l, "%ENDSNX"):
+			return p.finish()
+		case l[0] == '+':
+			if err := p.parseBlock(strings.TrimSpace(l[1:])); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: line out of blocks: '%s'", ErrFormat, l)
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: no %%ENDSNX", ErrFormat)
+}
+
+// parseHeader parses the header line.
+func (p *parser) parseHeader(l string) (err error) {
+	fs := strings.Fields(l)
+	if len(fs) < 9 || fs[0] != "%=SNX" {
+		return fmt.Errorf("%w: header line: '%s'", ErrFormat, l)
+	}
+	h := &p.f.Header
+	if h.Version, err = strconv.ParseFloat(fs[1], 64); err != nil || h.Version < 1 || h.Version >= 3 {
+		return fmt.Errorf("%w: version: '%s'", ErrFormat, fs[1])
+	}
+	h.Agency, h.DataAgency = fs[2], fs[4]
+	if h.Created, err = parseEpoch(fs[3]); err != nil {
+		return err
+	}
+	if h.Start, err = parseEpoch(fs[5]); err != nil {
+		return err
+	}
+	if h.End, err = parseEpoch(fs[6]); err != nil {
+		return err
+	}
+	h.Technique = fs[7][0]
+	if h.NumEstimates, err = strconv.Atoi(fs[8]); err != nil {
+		return fmt.Errorf("%w: number of estimates: '%s'", ErrFormat, fs[8])
+	}
+	return nil
+}
+
+// parseBlock parses the lines of the block of the name until its end line.
+func (p *parser) parseBlock(name string) error {
+	for p.s.Scan() {
+		l := line(p.s.Text())
+		switch {
+		case l == "" || l[0] == '*':
+			continue
+		case l[0] == '-':
+			if end := strings.TrimSpace(l[1:]); end != name {
+				return fmt.Errorf("%w: end of block %s: '%s'", ErrFormat, name, end)
+			}
+			return nil
+		case l[0] == '+' || l[0] == '%':
+			return fmt.Errorf("%w: no end of block %s", ErrFormat, name)
+		}
+
+		var err error
+		switch name {
+		case "SITE/ID":
+			err = p.parseSiteID(l)
+		case "SOLUTION/EPOCHS":
+			err = p.parseEpochs(l)
+		case "SOLUTION/ESTIMATE":
+			err = p.parseEstimate(l)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: no end of block %s", ErrFormat, name)
+}
+
+// parseSiteID parses a line of SITE/ID:
+//
+//	*CODE PT __DOMES__ T _STATION DESCRIPTION__ APPROX_LON_ APPROX_LAT_ _APP_H_
+//	 TSKB  A 21730S005 P Tsukuba, Japan         140  5 14.9  36  6 20.4    67.3
+func (p *parser) parseSiteID(l string) error {
+	l = fmt.Sprintf("%-75s", l)
+	s := p.site(strings.TrimSpace(l[1:5]))
+	s.Point = strings.TrimSpace(l[6:8])
+	s.DOMES = strings.TrimSpace(l[9:18])
+	s.Description = strings.TrimSpace(l[21:43])
+
+	var err error
+	if s.Lon, err = dms(l[44:55]); err != nil {
+		return fmt.Errorf("%w: site %s: longitude: %v", ErrFormat, s.Code, err)
+	}
+	if s.Lat, err = dms(l[56:67]); err != nil {
+		return fmt.Errorf("%w: site %s: latitude: %v", ErrFormat, s.Code, err)
+	}
+	if s.Height, err = strconv.ParseFloat(strings.TrimSpace(l[68:75]), 64); err != nil {
+		return fmt.Errorf("%w: site %s: height: %v", ErrFormat, s.Code, err)
+	}
+	return nil
+}
+
+// parseEpochs parses a line of SOLUTION/EPOCHS:
+//
+//	*CODE PT SOLN T _DATA_START_ __DATA_END__ _MEAN_EPOCH_
+//	 TSKB  A    1 P 24:193:00000 24:199:86370 24:196:43185
+func (p *parser) parseEpochs(l string) (err error) {
+	fs := strings.Fields(l)
+	if len(fs) != 7 {
+		return fmt.Errorf("%w: solution epochs: '%s'", ErrFormat, l)
+	}
+	sol := p.solution(fs[0], fs[2])
+	if sol.Start, err = parseEpoch(fs[4]); err != nil {
+		return err
+	}
+	if sol.End, err = parseEpoch(fs[5]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseEstimate parses a line of SOLUTION/ESTIMATE:
+//
+//	*INDEX _TYPE_ CODE PT SOLN _REF_EPOCH__ UNIT S __ESTIMATED VALUE____ _STD_DEV___
+//	     1 STAX   TSKB  A    1 24:196:43200 m    2 -3.95719858916580e+06 7.01234e-04
+//
+// where the parameters other than the positions and the velocities are
+// skipped.
+func (p *parser) parseEstimate(l string) error {
+	fs := strings.Fields(l)
+	if len(fs) != 10 {
+		return fmt.Errorf("%w: estimate: '%s'", ErrFormat, l)
+	}
+	k, ok := paramTypes[fs[1]]
+	if !ok {
+		return nil
+	}
+	t, err := parseEpoch(fs[5])
+	if err != nil {
+		return err
+	}
+	v, err := strconv.ParseFloat(fs[8], 64)
+	if err != nil {
+		return fmt.Errorf("%w: estimate %s of %s: %v", ErrFormat, fs[1], fs[2], err)
+	}
+	sigma, err := strconv.ParseFloat(fs[9], 64)
+	if err != nil {
+		return fmt.Errorf("%w: sigma %s of %s: %v", ErrFormat, fs[1], fs[2], err)
+	}
+	if unit := fs[6]; (k < 3 && unit != "m") || (k >= 3 && unit != "m/y") {
+		return fmt.Errorf("%w: unit of %s of %s: '%s'", ErrFormat, fs[1], fs[2], unit)
+	}
+
+	sol := p.solution(fs[2], fs[4])
+	if p.parts[sol]&(1<<k) != 0 {
+		return fmt.Errorf("%w: duplicated %s of %s solution %s", ErrFormat, fs[1], fs[2], fs[4])
+	}
+	if p.parts[sol] != 0 && !sol.Epoch.Equal(t) {
+		return fmt.Errorf("%w: reference epoch of %s of %s: %v, want %v", ErrFormat, fs[1], fs[2], t, sol.Epoch)
+	}
+	p.parts[sol] |= 1 << k
+	sol.Epoch = t
+	if k < 3 {
+		sol.Position[k], sol.Sigma[k] = v, sigma
+	} else {
+		sol.Velocity[k-3], sol.VelocitySigma[k-3] = v, sigma
+	}
+	return nil
+}
+
+// finish checks the estimates of the solutions, and removes the solutions
+// without the estimates, e.g., of SOLUTION/EPOCHS only.
+func (p *parser) finish() (*File, error) {
+	for _, s := range p.f.Sites {
+		var sols []*Solution
+		for _, sol := range s.Solutions {
+			parts := p.parts[sol]
+			switch {
+			case parts == 0:
+				continue
+			case parts&partPosition != partPosition:
+				return nil, fmt.Errorf("%w: incomplete position of %s solution %s", ErrFormat, s.Code, sol.Number)
+			case parts&partVelocity != 0 && parts&partVelocity != partVelocity:
+				return nil, fmt.Errorf("%w: incomplete velocity of %s solution %s", ErrFormat, s.Code, sol.Number)
+			}
+			sol.HasVelocity = parts&partVelocity != 0
+			sols = append(sols, sol)
+		}
+		s.Solutions = sols
+	}
+	return &p.f, nil
+}
+
+// site returns the site of the code, which is added if new.
+func (p *parser) site(code string) *Site {
+	if s, ok := p.sites[code]; ok {
+		return s
+	}
+	s := &Site{Code: code}
+	p.sites[code] = s
+	p.f.Sites = append(p.f.Sites, s)
+	return s
+}
+
+// solution returns the solution of the number of the site of the code, which
+// is added if new.
+func (p *parser) solution(code, number string) *Solution {
+	s := p.site(code)
+	for _, sol := range s.Solutions {
+		if sol.Number == number {
+			return sol
+		}
+	}
+	sol := &Solution{Number: number}
+	s.Solutions = append(s.Solutions, sol)
+	return sol
+}
+
+// line returns the line without the trailing spaces and CR.
+func line(s string) string {
+	return strings.TrimRight(s, " \r")
+}
+
+// parseEpoch parses an epoch of YY:DDD:SSSSS, or YYYY:DDD:SSSSS of the
+// version 2.10 or later, where the years of two digits are of 1951-2050, and
+// 00:000:00000 is the zero time.
+func parseEpoch(s string) (time.Time, error) {
+	fs := strings.Split(s, ":")
+	if len(fs) != 3 {
+		return time.Time{}, fmt.Errorf("%w: epoch: '%s'", ErrFormat, s)
+	}
+	var v [3]int
+	for k, f := range fs {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: epoch: '%s'", ErrFormat, s)
+		}
+		v[k] = n
+	}
+	if v == [3]int{} {
+		return time.Time{}, nil
+	}
+	y := v[0]
+	if len(fs[0]) == 2 {
+		y += 1900
+		if y <= 1950 {
+			y += 100
+		}
+	}
+	if v[1] < 1 || v[1] > 366 || v[2] < 0 || v[2] > 86400 {
+		return time.Time{}, fmt.Errorf("%w: epoch: '%s'", ErrFormat, s)
+	}
+	return gnsstime.FromDOY(y, v[1]).Add(time.Duration(v[2]) * time.Second), nil
+}
+
+// dms parses the angle of the degrees, the minutes and the seconds into the
+// degrees, e.g., "-12 34 56.7".
+func dms(s string) (float64, error) {
+	fs := strings.Fields(s)
+	if len(fs) != 3 {
+		return 0, fmt.Errorf("angle: '%s'", strings.TrimSpace(s))
+	}
+	var v [3]float64
+	for k, f := range fs {
+		x, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return 0, err
+		}
+		v[k] = x
+	}
+	deg := math.Abs(v[0]) + v[1]/60 + v[2]/3600
+	if strings.HasPrefix(fs[0], "-") {
+		deg = -deg
+	}
+	return deg, nil
+}
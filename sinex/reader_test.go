@@ -0,0 +1,125 @@
+package sinex
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFile is the synthetic solution in the format of the IGS weekly
+// solution, where MIZU has two solutions of the velocities separated by a
+// discontinuity, and SANT has no solution.
+const testFile = "testdata/SYN0OPSSNX_20241930000_07D_07D_SOL_synthetic.SNX"
+
+// TestReadFile checks the header, the sites and the solutions of the
+// fixture.
+func TestReadFile(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	h := f.Header
+	t0 := time.Date(2024, 7, 11, 0, 0, 0, 0, time.UTC)
+	switch {
+	case h.Version != 2.02 || h.Agency != "SYN" || h.DataAgency != "SYN" || h.Technique != 'P' || h.NumEstimates != 19:
+		t.Errorf("header: %+v", h)
+	case !h.Created.Equal(time.Date(2024, 7, 19, 12, 0, 10, 0, time.UTC)) || !h.Start.Equal(t0) || !h.End.Equal(t0.Add(7*24*time.Hour-30*time.Second)):
+		t.Errorf("header epochs: %v %v %v", h.Created, h.Start, h.End)
+	}
+
+	if len(f.Sites) != 4 {
+		t.Fatalf("number of sites: get %d, want 4", len(f.Sites))
+	}
+	algo := f.Sites[0]
+	switch {
+	case algo.Code != "ALGO" || algo.Point != "A" || algo.DOMES != "40104M002" || algo.Description != "Algonquin Park, Canada":
+		t.Errorf("ALGO: %+v", algo)
+	case math.Abs(algo.Lon-(281+55./60+43.1/3600)) > 1e-9 || math.Abs(algo.Lat-(45+57./60+20.9/3600)) > 1e-9 || algo.Height != 200.9:
+		t.Errorf("ALGO: approximate position: %v %v %v", algo.Lon, algo.Lat, algo.Height)
+	case len(algo.Solutions) != 1:
+		t.Fatalf("ALGO: %d solutions", len(algo.Solutions))
+	}
+	sol := algo.Solutions[0]
+	want := Solution{
+		Number: "1", Start: t0, End: h.End, Epoch: t0.Add(3*24*time.Hour + 12*time.Hour),
+		Position: [3]float64{918129.502, -4354426.289, 4602867.219},
+		Sigma:    [3]float64{6.8e-4, 1.1e-3, 1.2e-3},
+	}
+	if *sol != want {
+		t.Errorf("ALGO: get %+v, want %+v", *sol, want)
+	}
+
+	if sant := f.Sites[3]; sant.Code != "SANT" || math.Abs(sant.Lat+(33+9./60+1./3600)) > 1e-9 || len(sant.Solutions) != 0 {
+		t.Errorf("SANT: %+v", sant)
+	}
+
+	mizu := f.Sites[2]
+	if len(mizu.Solutions) != 2 {
+		t.Fatalf("MIZU: %d solutions", len(mizu.Solutions))
+	}
+	s2 := mizu.Solutions[1]
+	switch {
+	case s2.Number != "2" || !s2.Start.Equal(time.Date(2011, 3, 11, 5, 46, 0, 0, time.UTC)) || !s2.Epoch.Equal(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)):
+		t.Errorf("MIZU: %+v", s2)
+	case !s2.HasVelocity || s2.Velocity != [3]float64{-0.0305, 0.0152, -0.0081} || s2.VelocitySigma[2] != 1e-4:
+		t.Errorf("MIZU: velocity: %+v", s2)
+	}
+}
+
+// TestParseErrors checks the files modified.
+func TestParseErrors(t *testing.T) {
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, tt := range []struct {
+		name     string
+		old, new string
+	}{
+		{"header", "%=SNX 2.02", "%=SNY 2.02"},
+		{"version", "%=SNX 2.02", "%=SNX 3.00"},
+		{"epoch", "24:201:43210", "24:401:43210"},
+		{"no block end", "-SITE/RECEIVER\n", ""},
+		{"block end", "-SITE/ID", "-SITE/IDS"},
+		{"out of blocks", "-FILE/REFERENCE\n", "-FILE/REFERENCE\n DATA\n"},
+		{"latitude", " 45 57 20.9", " 45 57 2x.9"},
+		{"value", "-4.35442628900000e+06", "-4.35442628900000x+06"},
+		{"unit", "STAY   ALGO  A    1 24:196:43200 m ", "STAY   ALGO  A    1 24:196:43200 mm"},
+		{"reference epoch", "STAY   ALGO  A    1 24:196:43200", "STAY   ALGO  A    1 24:196:43201"},
+		{"duplicated", "STAY   ALGO", "STAX   ALGO"},
+		{"incomplete position", "STAZ   TSKB", "VELZ   TSKB"},
+		{"incomplete velocity", "VELZ   MIZU  A    1 10:001:00000 m/y", "LOD    MIZU  A    1 10:001:00000 m/y"},
+		{"no end", "%ENDSNX", ""},
+	} {
+		s := strings.Replace(string(data), tt.old, tt.new, 1)
+		if s == string(data) {
+			t.Fatalf("%s: not modified", tt.name)
+		}
+		if _, err := Parse(strings.NewReader(s)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: get err=%v, want %v", tt.name, err, ErrFormat)
+		}
+	}
+}
+
+// TestParseEpoch checks the epochs of the two and the four digits of the
+// years.
+func TestParseEpoch(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want time.Time
+	}{
+		{"24:196:43200", time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC)},
+		{"97:001:00030", time.Date(1997, 1, 1, 0, 0, 30, 0, time.UTC)},
+		{"50:365:86400", time.Date(2051, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024:196:43200", time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC)},
+		{"00:000:00000", time.Time{}},
+	} {
+		if get, err := parseEpoch(tt.s); err != nil || !get.Equal(tt.want) {
+			t.Errorf("%s: get %v (err=%v), want %v", tt.s, get, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Package sinex reads the station coordinates of the SINEX files, e.g., the
+weekly and the cumulative solutions of IGS, for the reference positions of
+the validations.
+
+The blocks SITE/ID, SOLUTION/EPOCHS and SOLUTION/ESTIMATE are parsed, and the
+other blocks are skipped. The positions are in ECEF (m), the velocities in
+m/year, and the epochs are represented as time.Time in UTC as the other
+packages of the module.
+
+ParseBias reads the Bias-SINEX files of the code and the phase biases, e.g.,
+the OSB products of CODE and the DSB products of CAS, and BiasFile.CorrectCode
+aligns the pseudoranges to the reference codes of the precise clocks, e.g.,
+C1C to C1W of GPS. The epochs of the validity intervals of the biases are in
+the time system of the file, i.e., GPS time usually.
+*/
+package sinex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrFormat is returned when the file is not a valid SINEX file. The
+	// errors of the parser are wrapped with the line number.
+	ErrFormat = errors.New("invalid sinex format")
+
+	// ErrNoStation is returned for the station not in the file, or without
+	// the solution valid at the epoch.
+	ErrNoStation = errors.New("no station solution")
+)
+
+// year is the length of the year of the velocities.
+const year = 365.25 * 24 * time.Hour
+
+// Header stores the header line of a SINEX file, e.g.,
+//
+//	%=SNX 2.02 IGS 24:200:55413 IGS 24:193:00000 24:199:86370 P 00789 2 S
+type Header struct {
+	Version float64
+
+	// Agency is the agency creating the file, and Created is the epoch of
+	// the creation.
+	Agency  string
+	Created time.Time
+
+	// DataAgency is the agency providing the data, and Start and End are the
+	// time span of the data.
+	DataAgency string
+	Start, End time.Time
+
+	// Technique is the observation technique, e.g., 'P' for GNSS, and
+	// NumEstimates is the number of the estimates of the file.
+	Technique    byte
+	NumEstimates int
+}
+
+// File stores the contents of a SINEX file.
+type File struct {
+	Header Header
+
+	// Sites is the sites in the order of SITE/ID, followed by those only in
+	// the solutions.
+	Sites []*Site
+}
+
+// Site is a site and its solutions.
+type Site struct {
+	// Code is the 4-character site code, Point is the point code, e.g., "A",
+	// and DOMES is the DOMES number.
+	Code, Point, DOMES string
+
+	// Description is the description of the site, and Lon, Lat and Height
+	// are the approximate longitude, latitude (deg) and height (m) of
+	// SITE/ID.
+	Description      string
+	Lon, Lat, Height float64
+
+	// Solutions is the solutions of the site in the order of the solution
+	// numbers, i.e., of the periods separated by the discontinuities.
+	Solutions []*Solution
+}
+
+// Solution is a solution of the position of a site.
+type Solution struct {
+	// Number is the solution number, e.g., "1".
+	Number string
+
+	// Start and End are the time span of the data of SOLUTION/EPOCHS, which
+	// are zero if not given.
+	Start, End time.Time
+
+	// Epoch is the reference epoch of the position, and Position and Sigma
+	// are the position (ECEF, m) of STAX, STAY and STAZ and their standard
+	// deviations.
+	Epoch    time.Time
+	Position [3]float64
+	Sigma    [3]float64
+
+	// Velocity and VelocitySigma are the velocity (ECEF, m/year) of VELX,
+	// VELY and VELZ and their standard deviations if HasVelocity.
+	Velocity      [3]float64
+	VelocitySigma [3]float64
+	HasVelocity   bool
+}
+
+// Site returns the site of the 4-character site code or the 9-character
+// station ID of RINEX 3, e.g., "TSKB" or "TSKB00JPN", which is matched by its
+// first four characters.
+func (f *File) Site(id string) (*Site, error) {
+	code := strings.ToUpper(id)
+	if len(code) == 9 {
+		code = code[:4]
+	}
+	for _, s := range f.Sites {
+		if s.Code == code {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoStation, id)
+}
+
+// Position returns the position (ECEF, m) of the station id at the epoch t
+// (see File.Site and Site.Position).
+func (f *File) Position(id string, t time.Time) ([3]float64, error) {
+	s, err := f.Site(id)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	return s.Position(t)
+}
+
+// Solution returns the solution valid at the epoch t, i.e., of the time span
+// of the data containing t. The solution of the nearest time span is
+// returned if none contains t, and the first solution if the time spans are
+// not given.
+func (s *Site) Solution(t time.Time) (*Solution, error) {
+	if len(s.Solutions) == 0 {
+		return nil, fmt.Errorf("%w: %s: no solution", ErrNoStation, s.Code)
+	}
+	var best *Solution
+	var bestDist time.Duration
+	for _, sol := range s.Solutions {
+		if sol.Start.IsZero() || sol.End.IsZero() {
+			continue
+		}
+		var d time.Duration
+		switch {
+		case t.Before(sol.Start):
+			d = sol.Start.Sub(t)
+		case t.After(sol.End):
+			d = t.Sub(sol.End)
+		}
+		if best == nil || d < bestDist {
+			best, bestDist = sol, d
+		}
+	}
+	if best == nil {
+		best = s.Solutions[0]
+	}
+	return best, nil
+}
+
+// Position returns the position (ECEF, m) of the solution at the epoch t of
+// Site.Solution propagated from the reference epoch by the velocity if
+// given.
+func (s *Site) Position(t time.Time) ([3]float64, error) {
+	sol, err := s.Solution(t)
+	if err != nil {
+		return [3]float64{}, err
+	}
+	return sol.At(t), nil
+}
+
+// At returns the position (ECEF, m) at the epoch t propagated from the
+// reference epoch by the velocity, or the position if without the velocity.
+func (sol *Solution) At(t time.Time) [3]float64 {
+	pos := sol.Position
+	if !sol.HasVelocity {
+		return pos
+	}
+	dt := float64(t.Sub(sol.Epoch)) / float64(year)
+	for k := range 3 {
+		pos[k] += sol.Velocity[k] * dt
+	}
+	return pos
+}
@@ -0,0 +1,80 @@
+package sinex
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSite checks the sites of the 4-character codes and the 9-character
+// IDs.
+func TestSite(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, id := range []string{"TSKB", "tskb", "TSKB00JPN"} {
+		if s, err := f.Site(id); err != nil || s.Code != "TSKB" || s.DOMES != "21730S005" {
+			t.Errorf("%s: get %+v (err=%v)", id, s, err)
+		}
+	}
+	for _, id := range []string{"ABCD", "TSK", "TSKB00JP"} {
+		if _, err := f.Site(id); !errors.Is(err, ErrNoStation) {
+			t.Errorf("%s: get err=%v, want %v", id, err, ErrNoStation)
+		}
+	}
+}
+
+// TestPosition checks the positions without the velocities, propagated by
+// the velocities of the solutions of the epochs, and the site without the
+// solutions.
+func TestPosition(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	// the position of the weekly solution at any epoch
+	want := [3]float64{-3957199.246, 3310199.672, 3737711.680}
+	for _, tm := range []time.Time{time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)} {
+		if get, err := f.Position("TSKB00JPN", tm); err != nil || get != want {
+			t.Errorf("TSKB %v: get %v (err=%v), want %v", tm, get, err, want)
+		}
+	}
+
+	mizu, _ := f.Site("MIZU")
+	for _, tt := range []struct {
+		t   time.Time
+		sol int
+	}{
+		{time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2011, 3, 11, 5, 0, 0, 0, time.UTC), 0},
+		{time.Date(2011, 3, 12, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+	} {
+		sol := mizu.Solutions[tt.sol]
+		dt := tt.t.Sub(sol.Epoch).Hours() / 24 / 365.25
+		get, err := mizu.Position(tt.t)
+		if err != nil {
+			t.Fatalf("MIZU %v: %v", tt.t, err)
+		}
+		for k := range 3 {
+			if w := sol.Position[k] + sol.Velocity[k]*dt; math.Abs(get[k]-w) > 1e-6 {
+				t.Errorf("MIZU %v: get %v, want solution %s", tt.t, get, sol.Number)
+				break
+			}
+		}
+	}
+
+	// 10 years from the reference epoch of the second solution
+	get := mizu.Solutions[1].At(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if d := get[0] - mizu.Solutions[1].Position[0]; math.Abs(d+0.305) > 1e-4 {
+		t.Errorf("10 years: get %v m, want -0.305 m", d)
+	}
+
+	if _, err := f.Position("SANT", time.Now()); !errors.Is(err, ErrNoStation) {
+		t.Errorf("SANT: get err=%v, want %v", err, ErrNoStation)
+	}
+}
@@ -0,0 +1,130 @@
+package sp3
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+// ErrNoClock is returned when the clock cannot be interpolated at the epoch,
+// e.g., for the satellite not in the file, the epoch out of the file, or
+// the valid samples too far from the epoch.
+var ErrNoClock = errors.New("no valid clock")
+
+// ClockMethod is the interpolation method of the clocks.
+type ClockMethod int
+
+const (
+	// ClockLinear interpolates the clock linearly between the valid samples
+	// bracketing the epoch. This is the default.
+	ClockLinear ClockMethod = iota
+
+	// ClockQuadratic interpolates the clock by the quadratic polynomial of
+	// the bracketing samples and the nearer of the next valid samples. The
+	// linear interpolation is used if no such sample is available.
+	ClockQuadratic
+)
+
+// ClockOpts defines options for File.Clock.
+type ClockOpts struct {
+	Method ClockMethod
+
+	// MaxGap is the maximum distance of the bracketing valid samples from the
+	// epoch. Header.Interval is used if zero, i.e., the epoch adjacent to a
+	// missing clock is rejected.
+	MaxGap time.Duration
+}
+
+// Clock returns the clock bias (s) of the satellite id at the epoch t
+// interpolated from the valid clock samples, skipping the missing ones.
+// The clock is not extrapolated, and ErrNoClock is returned if either of
+// the bracketing valid samples is farther than opts.MaxGap from t.
+//
+// The sign is that of SP3, i.e., the correction of the pseudorange is
+// c*Clock to be added (see ClockMeters).
+func (f *File) Clock(id string, t time.Time, opts ClockOpts) (float64, error) {
+	maxGap := opts.MaxGap
+	if maxGap == 0 {
+		maxGap = f.Header.Interval
+	}
+
+	recs, ok := f.Records[id]
+	if !ok {
+		return 0., fmt.Errorf("%w: satellite not found: %s", ErrNoClock, id)
+	}
+
+	// the valid samples before and after t
+	k := sort.Search(len(f.Epochs), func(i int) bool { return !f.Epochs[i].Before(t) })
+	if k < len(f.Epochs) && f.Epochs[k].Equal(t) && recs[k].HasClock {
+		return recs[k].Clock, nil
+	}
+	prev := validClock(recs, k-1, -1)
+	next := validClock(recs, k, 1)
+	if prev < 0 || next < 0 {
+		return 0., fmt.Errorf("%w: %s: no valid sample around %v", ErrNoClock, id, t)
+	}
+	if gp, gn := t.Sub(f.Epochs[prev]), f.Epochs[next].Sub(t); gp > maxGap || gn > maxGap {
+		return 0., fmt.Errorf("%w: %s: valid samples too far from %v: before=%v, after=%v, limit=%v", ErrNoClock, id, t, gp, gn, maxGap)
+	}
+
+	idx := []int{prev, next}
+	if opts.Method == ClockQuadratic {
+		pp, nn := validClock(recs, prev-1, -1), validClock(recs, next+1, 1)
+		switch {
+		case pp >= 0 && (nn < 0 || t.Sub(f.Epochs[pp]) <= f.Epochs[nn].Sub(t)):
+			idx = append(idx, pp)
+		case nn >= 0:
+			idx = append(idx, nn)
+		}
+	}
+
+	// Lagrange interpolation
+	var clk float64
+	for _, i := range idx {
+		l := 1.
+		for _, j := range idx {
+			if j != i {
+				l *= t.Sub(f.Epochs[j]).Seconds() / f.Epochs[i].Sub(f.Epochs[j]).Seconds()
+			}
+		}
+		clk += l * recs[i].Clock
+	}
+	return clk, nil
+}
+
+// ClockMeters returns the clock of Clock in meters, i.e., c*Clock, which is
+// added to the pseudorange as the satellite clock correction.
+func (f *File) ClockMeters(id string, t time.Time, opts ClockOpts) (float64, error) {
+	clk, err := f.Clock(id, t, opts)
+	return clk * lightVelocity, err
+}
+
+// Clocks returns the clocks (s) of all the satellites of the file at the
+// epoch t by Clock, and the IDs of the satellites flagged by ErrNoClock.
+func (f *File) Clocks(t time.Time, opts ClockOpts) (clocks map[string]float64, flagged []string) {
+	clocks = make(map[string]float64, len(f.Header.Sats))
+	for _, id := range f.Header.Sats {
+		clk, err := f.Clock(id, t, opts)
+		if err != nil {
+			flagged = append(flagged, id)
+			continue
+		}
+		clocks[id] = clk
+	}
+	return clocks, flagged
+}
+
+// validClock returns the index of the first valid clock from the index k in
+// the direction dir (1 or -1), or -1 if not found.
+func validClock(recs []Record, k, dir int) int {
+	for ; k >= 0 && k < len(recs); k += dir {
+		if recs[k].HasClock {
+			return k
+		}
+	}
+	return -1
+}
@@ -0,0 +1,87 @@
+package sp3
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testClockFile returns a file of two satellites with the clocks given by
+// the function of the seconds from the first epoch at the interval of 300 s.
+// The clock of G01 is missing at the 4th epoch, and G02 has no clock.
+func testClockFile(clk func(s float64) float64) *File {
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	f := &File{
+		Header:  Header{Start: t0, Interval: 5 * time.Minute, Sats: []string{"G01", "G02"}},
+		Records: map[string][]Record{"G01": nil, "G02": nil},
+	}
+	for k := range 10 {
+		f.Epochs = append(f.Epochs, t0.Add(time.Duration(k)*5*time.Minute))
+		f.Records["G01"] = append(f.Records["G01"], Record{Clock: clk(300. * float64(k)), HasClock: k != 3})
+		f.Records["G02"] = append(f.Records["G02"], Record{})
+	}
+	f.Records["G01"][3].Clock = 0.
+	return f
+}
+
+// TestClock checks the interpolation and the rejection around the missing
+// clock.
+func TestClock(t *testing.T) {
+	quad := func(s float64) float64 { return 1e-4 + 1e-11*s + 1e-16*s*s }
+	f := testClockFile(quad)
+	t0 := f.Epochs[0]
+
+	// at the sample
+	if clk, err := f.Clock("G01", f.Epochs[2], ClockOpts{}); err != nil || clk != quad(600.) {
+		t.Errorf("at the sample: get %e, err=%v, want %e", clk, err, quad(600.))
+	}
+
+	// linear between the samples: the error is the curvature term
+	s := 450.
+	tt := t0.Add(time.Duration(s) * time.Second)
+	lin := (quad(300.) + quad(600.)) / 2.
+	if clk, err := f.Clock("G01", tt, ClockOpts{}); err != nil || math.Abs(clk-lin) > 1e-20 {
+		t.Errorf("linear: get %e, err=%v, want %e", clk, err, lin)
+	}
+
+	// quadratic is exact for the quadratic clock
+	clk, err := f.Clock("G01", tt, ClockOpts{Method: ClockQuadratic})
+	if err != nil || math.Abs(clk-quad(s)) > 1e-19 {
+		t.Errorf("quadratic: get %e, err=%v, want %e", clk, err, quad(s))
+	}
+	m, _ := f.ClockMeters("G01", tt, ClockOpts{Method: ClockQuadratic})
+	if math.Abs(m-clk*299792458.) > 1e-9 {
+		t.Errorf("meters: get %f, want %f", m, clk*299792458.)
+	}
+
+	// adjacent to the missing clock
+	tt = t0.Add(1000 * time.Second)
+	if _, err := f.Clock("G01", tt, ClockOpts{}); !errors.Is(err, ErrNoClock) {
+		t.Errorf("missing clock: get err=%v, want %v", err, ErrNoClock)
+	}
+	clk, err = f.Clock("G01", tt, ClockOpts{MaxGap: 10 * time.Minute})
+	if want := quad(600.) + (quad(1200.)-quad(600.))*400./600.; err != nil || math.Abs(clk-want) > 1e-18 {
+		t.Errorf("over the missing clock: get %e, err=%v, want %e", clk, err, want)
+	}
+
+	// no extrapolation
+	for _, tt := range []time.Time{t0.Add(-time.Second), f.Epochs[9].Add(time.Second)} {
+		if _, err := f.Clock("G01", tt, ClockOpts{MaxGap: time.Hour}); !errors.Is(err, ErrNoClock) {
+			t.Errorf("extrapolation: get err=%v, want %v", err, ErrNoClock)
+		}
+	}
+	if _, err := f.Clock("G03", t0, ClockOpts{}); !errors.Is(err, ErrNoClock) {
+		t.Errorf("no satellite: get err=%v, want %v", err, ErrNoClock)
+	}
+
+	clocks, flagged := f.Clocks(tt, ClockOpts{})
+	if len(clocks) != 0 || !slices.Equal(flagged, []string{"G01", "G02"}) {
+		t.Errorf("clocks=%v, flagged=%v", clocks, flagged)
+	}
+	clocks, flagged = f.Clocks(t0.Add(450*time.Second), ClockOpts{})
+	if len(clocks) != 1 || !slices.Equal(flagged, []string{"G02"}) {
+		t.Errorf("clocks=%v, flagged=%v", clocks, flagged)
+	}
+}
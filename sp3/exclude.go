@@ -0,0 +1,86 @@
+package sp3
+
+import (
+	"sort"
+	"time"
+)
+
+// ExcludeOpts defines the criteria of File.Exclusions. The zero value
+// excludes no satellite.
+type ExcludeOpts struct {
+	// MaxAccuracy is the maximum accuracy exponent of the header (see
+	// Header.Accuracy). The satellites of the larger exponents are excluded
+	// if positive; those of unknown accuracy (0) are not.
+	MaxAccuracy int
+
+	// MaxSdev is the maximum exponent of the standard deviations of the
+	// positions in the records (see Record.PosSdev). The check is disabled
+	// if zero.
+	MaxSdev int
+
+	// MissingClock excludes the satellites without the clock.
+	MissingClock bool
+
+	// Maneuver excludes the satellites with the maneuver flag, and
+	// ClockEvent those with the clock event flag.
+	Maneuver   bool
+	ClockEvent bool
+}
+
+// Exclusions returns the set of the satellites to be excluded at the epoch
+// t by the criteria, which can be given to bancroft.CalcPosOpts.Exclude.
+//
+// The records at t, or at the two epochs bracketing t, are examined, and the
+// satellite is excluded if any of them fails. The satellites without the
+// records at these epochs are excluded as well.
+func (f *File) Exclusions(t time.Time, opts ExcludeOpts) map[string]bool {
+	k := sort.Search(len(f.Epochs), func(i int) bool { return !f.Epochs[i].Before(t) })
+	var idx []int
+	switch {
+	case k < len(f.Epochs) && f.Epochs[k].Equal(t):
+		idx = []int{k}
+	case k > 0 && k < len(f.Epochs):
+		idx = []int{k - 1, k}
+	}
+
+	exclude := make(map[string]bool)
+	for i, id := range f.Header.Sats {
+		if opts.MaxAccuracy > 0 && i < len(f.Header.Accuracy) && f.Header.Accuracy[i] > opts.MaxAccuracy {
+			exclude[id] = true
+			continue
+		}
+		if len(idx) == 0 {
+			exclude[id] = true
+			continue
+		}
+		for _, k := range idx {
+			if excludeRecord(f.Records[id][k], opts) {
+				exclude[id] = true
+				break
+			}
+		}
+	}
+	return exclude
+}
+
+// excludeRecord reports whether the record fails the criteria.
+func excludeRecord(r Record, opts ExcludeOpts) bool {
+	switch {
+	case !r.HasPos:
+		return true
+	case opts.MissingClock && !r.HasClock:
+		return true
+	case opts.Maneuver && r.Maneuver:
+		return true
+	case opts.ClockEvent && r.ClockEvent:
+		return true
+	}
+	if opts.MaxSdev > 0 {
+		for _, s := range r.PosSdev {
+			if s > opts.MaxSdev {
+				return true
+			}
+		}
+	}
+	return false
+}
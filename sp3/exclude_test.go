@@ -0,0 +1,116 @@
+package sp3
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseFlags checks the standard deviations and the flags of the
+// position records.
+func TestParseFlags(t *testing.T) {
+	lines := readTestFile(t)
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "PG14"):
+			lines[i] = l + "  7  8  9 128 EP  MP"
+		case strings.HasPrefix(l, "PG02"):
+			lines[i] = l + "                  M "
+		case strings.HasPrefix(l, "PG03"):
+			lines[i] = l + " xx"
+		}
+	}
+
+	// invalid sdev
+	if _, err := Parse(strings.NewReader(strings.Join(lines, "\n"))); err == nil {
+		t.Errorf("no error for the invalid sdev")
+	}
+
+	for i, l := range lines {
+		if strings.HasPrefix(l, "PG03") {
+			lines[i] = l[:60]
+		}
+	}
+	f, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := f.Records["G14"][0]
+	if r.PosSdev != [3]int{7, 8, 9} || r.ClockSdev != 128 || !r.ClockEvent || !r.ClockPred || !r.Maneuver || !r.OrbitPred {
+		t.Errorf("G14: %+v", r)
+	}
+	r = f.Records["G02"][0]
+	if r.PosSdev != [3]int{} || r.ClockSdev != 0 || r.ClockEvent || r.ClockPred || !r.Maneuver || r.OrbitPred {
+		t.Errorf("G02: %+v", r)
+	}
+
+	compareFiles(t, f, roundTrip(t, f))
+}
+
+// TestExclusions checks the criteria of the excluded satellites.
+func TestExclusions(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := f.Epochs[0]
+
+	f.Header.Accuracy[1] = 12 // G03
+	f.Records["G04"][0].HasClock = false
+	f.Records["G06"][0].Maneuver = true
+	f.Records["G14"][0].ClockEvent = true
+	f.Records["G17"][0].PosSdev = [3]int{5, 20, 5}
+	f.Records["G19"][0].HasPos = false
+
+	for _, tt := range []struct {
+		opts ExcludeOpts
+		want []string
+	}{
+		{ExcludeOpts{}, []string{"G19"}},
+		{ExcludeOpts{MaxAccuracy: 10}, []string{"G03", "G19"}},
+		{ExcludeOpts{MissingClock: true}, []string{"G04", "G19"}},
+		{ExcludeOpts{Maneuver: true, ClockEvent: true}, []string{"G06", "G14", "G19"}},
+		{ExcludeOpts{MaxSdev: 18}, []string{"G17", "G19"}},
+		{ExcludeOpts{MaxAccuracy: 10, MaxSdev: 18, MissingClock: true, Maneuver: true, ClockEvent: true},
+			[]string{"G03", "G04", "G06", "G14", "G17", "G19"}},
+	} {
+		var get []string
+		for id := range f.Exclusions(t0, tt.opts) {
+			get = append(get, id)
+		}
+		if slices.Sort(get); !slices.Equal(get, tt.want) {
+			t.Errorf("%+v: get %v, want %v", tt.opts, get, tt.want)
+		}
+	}
+
+	// all satellites for the epoch out of the file
+	if get := f.Exclusions(t0.Add(time.Hour), ExcludeOpts{}); len(get) != len(f.Header.Sats) {
+		t.Errorf("out of the file: %v", get)
+	}
+}
+
+// TestExclusionsBracket checks the records bracketing the epoch are
+// examined.
+func TestExclusionsBracket(t *testing.T) {
+	f := testOrbitFile(4)
+	t0 := f.Epochs[0]
+	f.Records["G01"][2].Maneuver = true
+
+	opts := ExcludeOpts{Maneuver: true}
+	for _, tt := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{t0, false},
+		{t0.Add(f.Header.Interval / 2), false},
+		{t0.Add(f.Header.Interval * 3 / 2), true},
+		{t0.Add(f.Header.Interval * 2), true},
+		{t0.Add(f.Header.Interval * 5 / 2), true},
+		{t0.Add(f.Header.Interval * 3), false},
+	} {
+		if get := f.Exclusions(tt.t, opts)["G01"]; get != tt.want {
+			t.Errorf("%v: get %v, want %v", tt.t, get, tt.want)
+		}
+	}
+}
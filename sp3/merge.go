@@ -0,0 +1,112 @@
+package sp3
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrIncompatible is returned by Merge when the files cannot be merged.
+var ErrIncompatible = errors.New("incompatible sp3 files")
+
+// Merge concatenates the consecutive files, e.g., of the previous, the
+// current and the next days, to interpolate the orbits and the clocks near
+// the day boundaries without the one-sided windows.
+//
+// The files must be given in the order of the epochs, with the same frame,
+// time system and interval. The last epoch of a file may be repeated as the
+// first epoch of the next one, which is stored once with the record of the
+// former file unless it is missing. Other overlaps are not allowed.
+//
+// The satellites of the merged file are the union of those of the files,
+// and the records of the epochs not covered by the file of a satellite are
+// missing. The accuracy exponent of the satellite is the worst of the files.
+// The other header fields are those of the first file, with the version and
+// the position/velocity flag updated for the merged contents.
+func Merge(files ...*File) (*File, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no file", ErrIncompatible)
+	}
+
+	h0 := files[0].Header
+	for i, f := range files[1:] {
+		h := f.Header
+		switch {
+		case h.Frame != h0.Frame:
+			return nil, fmt.Errorf("%w: file %d: frame: %s, want %s", ErrIncompatible, i+1, h.Frame, h0.Frame)
+		case h.TimeSystem != h0.TimeSystem:
+			return nil, fmt.Errorf("%w: file %d: time system: %s, want %s", ErrIncompatible, i+1, h.TimeSystem, h0.TimeSystem)
+		case h.Interval != h0.Interval:
+			return nil, fmt.Errorf("%w: file %d: interval: %v, want %v", ErrIncompatible, i+1, h.Interval, h0.Interval)
+		}
+	}
+
+	m := &File{Header: h0, Records: make(map[string][]Record)}
+	m.Header.Sats = nil
+	m.Header.Accuracy = nil
+	m.Header.Comments = slices.Clone(h0.Comments)
+
+	for i, f := range files {
+		if len(f.Epochs) == 0 {
+			continue
+		}
+
+		// satellites
+		for j, id := range f.Header.Sats {
+			var acc int
+			if j < len(f.Header.Accuracy) {
+				acc = f.Header.Accuracy[j]
+			}
+			k := slices.Index(m.Header.Sats, id)
+			if k < 0 {
+				m.Header.Sats = append(m.Header.Sats, id)
+				m.Header.Accuracy = append(m.Header.Accuracy, acc)
+				m.Records[id] = make([]Record, len(m.Epochs))
+				continue
+			}
+			m.Header.Accuracy[k] = max(m.Header.Accuracy[k], acc)
+		}
+
+		// epochs
+		first := 0
+		if n := len(m.Epochs); n > 0 {
+			last := m.Epochs[n-1]
+			switch {
+			case f.Epochs[0].Equal(last):
+				// the boundary epoch
+				for id, recs := range f.Records {
+					if r := m.Records[id][n-1]; !r.HasPos && !r.HasClock {
+						m.Records[id][n-1] = recs[0]
+					}
+				}
+				first = 1
+			case f.Epochs[0].Before(last):
+				return nil, fmt.Errorf("%w: file %d: first epoch %v before the last epoch %v", ErrIncompatible, i, f.Epochs[0], last)
+			}
+		}
+
+		for k := first; k < len(f.Epochs); k++ {
+			m.Epochs = append(m.Epochs, f.Epochs[k])
+			for _, id := range m.Header.Sats {
+				var r Record
+				if recs, ok := f.Records[id]; ok {
+					r = recs[k]
+				}
+				m.Records[id] = append(m.Records[id], r)
+			}
+		}
+	}
+
+	if len(m.Epochs) > 0 {
+		m.Header.Start = m.Epochs[0]
+	}
+	m.Header.NumEpochs = len(m.Epochs)
+	if len(m.Header.Sats) > maxSatsC {
+		m.Header.Version = 'd'
+	}
+	if m.HasVelocity() {
+		m.Header.PosVel = 'V'
+	}
+
+	return m, nil
+}
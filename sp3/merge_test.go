@@ -0,0 +1,108 @@
+package sp3
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// splitFile returns the file of the epochs [from, to] of f.
+func splitFile(f *File, from, to int) *File {
+	g := &File{Header: f.Header, Epochs: slices.Clone(f.Epochs[from : to+1]), Records: make(map[string][]Record)}
+	g.Header.Start = g.Epochs[0]
+	g.Header.NumEpochs = len(g.Epochs)
+	for id, recs := range f.Records {
+		g.Records[id] = slices.Clone(recs[from : to+1])
+	}
+	return g
+}
+
+// TestMerge checks the merged file interpolates the orbit across the
+// boundaries as the single file.
+func TestMerge(t *testing.T) {
+	f := testOrbitFile(24)
+	f.Header.Frame, f.Header.TimeSystem, f.Header.Accuracy = "IGb20", "GPS", []int{5}
+
+	// the boundary epoch is repeated in the first two files
+	prev, cur, next := splitFile(f, 0, 8), splitFile(f, 8, 16), splitFile(f, 17, 23)
+	cur.Header.Accuracy = []int{7}
+	m, err := Merge(prev, cur, next)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !slices.EqualFunc(m.Epochs, f.Epochs, time.Time.Equal) || m.Header.NumEpochs != 24 || !m.Header.Start.Equal(f.Epochs[0]) {
+		t.Fatalf("epochs: %v", m.Epochs)
+	}
+	if !slices.Equal(m.Records["G01"], f.Records["G01"]) || !slices.Equal(m.Header.Accuracy, []int{7}) {
+		t.Errorf("records: %+v, accuracy=%v", m.Records["G01"], m.Header.Accuracy)
+	}
+	if m.Header.PosVel != 'V' {
+		t.Errorf("position/velocity flag: %c", m.Header.PosVel)
+	}
+
+	// just before the boundary of cur and next
+	tt := f.Epochs[16].Add(-time.Minute)
+	want, err := f.Position("G01", tt, OrbitOpts{})
+	if err != nil {
+		t.Fatalf("Position: %v", err)
+	}
+	pos, err := m.Position("G01", tt, OrbitOpts{})
+	if err != nil {
+		t.Fatalf("Position: %v", err)
+	}
+	one, _ := cur.Position("G01", tt, OrbitOpts{})
+	if d := math.Sqrt(sqr(pos[0]-want[0]) + sqr(pos[1]-want[1]) + sqr(pos[2]-want[2])); d != 0 {
+		t.Errorf("merged: d=%e", d)
+	}
+	if d := math.Sqrt(sqr(one[0]-want[0]) + sqr(one[1]-want[1]) + sqr(one[2]-want[2])); d == 0 {
+		t.Errorf("one-sided window not degraded")
+	}
+
+	// the missing record of the boundary epoch is filled by the next file
+	prev.Records["G01"][8] = Record{}
+	if m, err = Merge(prev, cur); err != nil || !m.Records["G01"][8].HasPos {
+		t.Errorf("boundary record: %+v, err=%v", m.Records["G01"][8], err)
+	}
+
+	// satellites of one of the files
+	cur.Header.Sats = []string{"G01", "G02"}
+	cur.Header.Accuracy = []int{7, 6}
+	cur.Records["G02"] = slices.Clone(cur.Records["G01"])
+	if m, err = Merge(prev, cur, next); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	g02 := m.Records["G02"]
+	if !slices.Equal(m.Header.Sats, []string{"G01", "G02"}) || len(g02) != 24 || g02[7].HasPos || !g02[8].HasPos || !g02[16].HasPos || g02[17].HasPos {
+		t.Errorf("satellites=%v, G02=%+v", m.Header.Sats, g02)
+	}
+}
+
+// TestMergeError checks the incompatible files are rejected.
+func TestMergeError(t *testing.T) {
+	f := testOrbitFile(24)
+	f.Header.Frame, f.Header.TimeSystem = "IGb20", "GPS"
+
+	for name, files := range map[string][]*File{
+		"overlap":  {splitFile(f, 0, 10), splitFile(f, 8, 16)},
+		"order":    {splitFile(f, 8, 16), splitFile(f, 0, 7)},
+		"no files": nil,
+	} {
+		if _, err := Merge(files...); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("%s: get err=%v, want %v", name, err, ErrIncompatible)
+		}
+	}
+
+	for name, mod := range map[string]func(h *Header){
+		"frame":       func(h *Header) { h.Frame = "IGS14" },
+		"time system": func(h *Header) { h.TimeSystem = "GAL" },
+		"interval":    func(h *Header) { h.Interval = 5 * time.Minute },
+	} {
+		next := splitFile(f, 12, 23)
+		mod(&next.Header)
+		if _, err := Merge(splitFile(f, 0, 11), next); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("%s: get err=%v, want %v", name, err, ErrIncompatible)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sp3
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNoOrbit is returned when the position cannot be interpolated at the
+// epoch, e.g., for the satellite not in the file, the epoch out of the file,
+// or the valid samples too far from the epoch.
+var ErrNoOrbit = errors.New("no valid orbit")
+
+// OrbitOpts defines options for File.Position.
+type OrbitOpts struct {
+	// Points is the number of the samples used for the interpolation.
+	// 10 is used for the Lagrange interpolation, and 5 for the Hermite
+	// interpolation if zero.
+	Points int
+
+	// Hermite enables the Hermite interpolation with the velocities, which
+	// matches the positions and the velocities at the samples. It requires
+	// the velocity records at all the samples used.
+	Hermite bool
+
+	// MaxGap is the maximum distance of the bracketing valid samples from the
+	// epoch. Header.Interval is used if zero.
+	MaxGap time.Duration
+}
+
+// Position returns the position (ECEF, m) of the satellite id at the epoch t
+// interpolated from the valid samples by the Lagrange polynomial, or by the
+// Hermite polynomial if opts.Hermite is set.
+//
+// The window of the samples is centered at t, and is shifted to one side at
+// the ends of the file, where the Lagrange interpolation degrades; the
+// Hermite interpolation is more accurate there with the same number of the
+// samples. The position is not extrapolated, and ErrNoOrbit is returned if
+// either of the bracketing valid samples is farther than opts.MaxGap from t.
+//
+// The position is in the frame of the file, and the rotation of the Earth
+// during the signal travel time is not corrected.
+func (f *File) Position(id string, t time.Time, opts OrbitOpts) ([3]float64, error) {
+	n := opts.Points
+	if n == 0 {
+		n = 10
+		if opts.Hermite {
+			n = 5
+		}
+	}
+	maxGap := opts.MaxGap
+	if maxGap == 0 {
+		maxGap = f.Header.Interval
+	}
+
+	recs, ok := f.Records[id]
+	if !ok {
+		return [3]float64{}, fmt.Errorf("%w: satellite not found: %s", ErrNoOrbit, id)
+	}
+
+	// valid samples
+	valid := make([]int, 0, len(recs))
+	for k, r := range recs {
+		if r.HasPos && (!opts.Hermite || r.HasVel) {
+			valid = append(valid, k)
+		}
+	}
+	if len(valid) < 2 {
+		return [3]float64{}, fmt.Errorf("%w: %s: too few samples: n=%d", ErrNoOrbit, id, len(valid))
+	}
+	n = min(n, len(valid))
+
+	// bracketing samples
+	j := sort.Search(len(valid), func(i int) bool { return !f.Epochs[valid[i]].Before(t) })
+	if j == len(valid) || (j == 0 && !f.Epochs[valid[0]].Equal(t)) {
+		return [3]float64{}, fmt.Errorf("%w: %s: out of the samples: %v", ErrNoOrbit, id, t)
+	}
+	if f.Epochs[valid[j]].Equal(t) {
+		return recs[valid[j]].Pos, nil
+	}
+	if gp, gn := t.Sub(f.Epochs[valid[j-1]]), f.Epochs[valid[j]].Sub(t); gp > maxGap || gn > maxGap {
+		return [3]float64{}, fmt.Errorf("%w: %s: valid samples too far from %v: before=%v, after=%v, limit=%v", ErrNoOrbit, id, t, gp, gn, maxGap)
+	}
+
+	// window centered at t
+	start := min(max(j-n/2, 0), len(valid)-n)
+	window := valid[start : start+n]
+
+	// time in the unit of the interval from t for the conditioning
+	scale := f.Header.Interval.Seconds()
+	if scale == 0 {
+		scale = 1.
+	}
+	x := make([]float64, n)
+	for i, k := range window {
+		x[i] = f.Epochs[k].Sub(t).Seconds() / scale
+	}
+
+	var pos [3]float64
+	y := make([]float64, n)
+	dy := make([]float64, n)
+	for c := range 3 {
+		for i, k := range window {
+			y[i] = recs[k].Pos[c]
+			dy[i] = recs[k].Vel[c] * scale
+		}
+		if opts.Hermite {
+			pos[c] = hermite(x, y, dy, 0.)
+		} else {
+			pos[c] = lagrange(x, y, 0.)
+		}
+	}
+	return pos, nil
+}
+
+// lagrange returns the value at x of the Lagrange polynomial through the
+// points (xs[i], ys[i]).
+func lagrange(xs, ys []float64, x float64) float64 {
+	var v float64
+	for i := range xs {
+		l := 1.
+		for j := range xs {
+			if j != i {
+				l *= (x - xs[j]) / (xs[i] - xs[j])
+			}
+		}
+		v += l * ys[i]
+	}
+	return v
+}
+
+// hermite returns the value at x of the Hermite polynomial through the
+// points (xs[i], ys[i]) with the derivatives dys[i], by the divided
+// differences with the doubled nodes.
+func hermite(xs, ys, dys []float64, x float64) float64 {
+	m := 2 * len(xs)
+	z := make([]float64, m)
+	q := make([]float64, m) // q[i] is the divided difference of the column
+	for i := range xs {
+		z[2*i], z[2*i+1] = xs[i], xs[i]
+		q[2*i], q[2*i+1] = ys[i], ys[i]
+	}
+
+	// coefficients of the Newton form, computed in place from the bottom
+	coef := make([]float64, m)
+	coef[0] = q[0]
+	for j := 1; j < m; j++ {
+		for i := m - 1; i >= j; i-- {
+			if j == 1 && z[i] == z[i-1] {
+				q[i] = dys[i/2]
+			} else {
+				q[i] = (q[i] - q[i-1]) / (z[i] - z[i-j])
+			}
+		}
+		coef[j] = q[j]
+	}
+
+	// Horner's scheme of the Newton form
+	v := coef[m-1]
+	for j := m - 2; j >= 0; j-- {
+		v = v*(x-z[j]) + coef[j]
+	}
+	return v
+}
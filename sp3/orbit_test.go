@@ -0,0 +1,167 @@
+package sp3
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOrbit returns the position and the velocity (ECEF) of a circular
+// orbit of the GPS satellite at s seconds.
+func testOrbit(s float64) (pos, vel [3]float64) {
+	const (
+		r     = 26560e3
+		gm    = 3.986004418e14
+		omega = 7.2921151467e-5
+		incl  = 55. * math.Pi / 180.
+	)
+	n := math.Sqrt(gm / (r * r * r))
+	u, th := n*s, omega*s
+
+	// inertial position and velocity
+	xi := [3]float64{r * math.Cos(u), r * math.Sin(u) * math.Cos(incl), r * math.Sin(u) * math.Sin(incl)}
+	vi := [3]float64{-r * n * math.Sin(u), r * n * math.Cos(u) * math.Cos(incl), r * n * math.Cos(u) * math.Sin(incl)}
+
+	// rotation into ECEF: v = R(vi - omega x xi)
+	c, si := math.Cos(th), math.Sin(th)
+	pos = [3]float64{c*xi[0] + si*xi[1], -si*xi[0] + c*xi[1], xi[2]}
+	vr := [3]float64{vi[0] + omega*xi[1], vi[1] - omega*xi[0], vi[2]}
+	vel = [3]float64{c*vr[0] + si*vr[1], -si*vr[0] + c*vr[1], vr[2]}
+	return pos, vel
+}
+
+// testOrbitFile returns a file of the orbit of testOrbit at the interval of
+// 900 s with the velocities.
+func testOrbitFile(nepoch int) *File {
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	f := &File{
+		Header:  Header{Start: t0, Interval: 15 * time.Minute, Sats: []string{"G01"}},
+		Records: map[string][]Record{"G01": nil},
+	}
+	for k := range nepoch {
+		pos, vel := testOrbit(900. * float64(k))
+		f.Epochs = append(f.Epochs, t0.Add(time.Duration(k)*15*time.Minute))
+		f.Records["G01"] = append(f.Records["G01"], Record{Pos: pos, Vel: vel, HasPos: true, HasVel: true})
+	}
+	return f
+}
+
+// TestPosition checks the interpolation errors of the Lagrange and the
+// Hermite polynomials in the middle and at the end of the file.
+func TestPosition(t *testing.T) {
+	f := testOrbitFile(24)
+	t0 := f.Epochs[0]
+
+	interpErr := func(s float64, opts OrbitOpts) float64 {
+		t.Helper()
+		pos, err := f.Position("G01", t0.Add(time.Duration(s*1e9)), opts)
+		if err != nil {
+			t.Fatalf("Position: %v", err)
+		}
+		want, _ := testOrbit(s)
+		return math.Sqrt(sqr(pos[0]-want[0]) + sqr(pos[1]-want[1]) + sqr(pos[2]-want[2]))
+	}
+
+	// middle of the file
+	mid := 900.*11 + 450.
+	if d := interpErr(mid, OrbitOpts{}); d > 1e-3 {
+		t.Errorf("Lagrange in the middle: %e m", d)
+	}
+	if d := interpErr(mid, OrbitOpts{Hermite: true}); d > 1e-3 {
+		t.Errorf("Hermite in the middle: %e m", d)
+	}
+
+	// at the first interval, the Hermite interpolation is more accurate
+	lag, her := interpErr(450., OrbitOpts{}), interpErr(450., OrbitOpts{Hermite: true})
+	t.Logf("at the end: Lagrange=%e m, Hermite=%e m", lag, her)
+	if her > 1e-3 || her >= lag {
+		t.Errorf("at the end: Lagrange=%e m, Hermite=%e m", lag, her)
+	}
+
+	// at the sample
+	if d := interpErr(900.*3, OrbitOpts{}); d != 0 {
+		t.Errorf("at the sample: %e m", d)
+	}
+
+	// no extrapolation, and the missing sample
+	if _, err := f.Position("G01", t0.Add(-time.Second), OrbitOpts{}); !errors.Is(err, ErrNoOrbit) {
+		t.Errorf("extrapolation: get err=%v, want %v", err, ErrNoOrbit)
+	}
+	f.Records["G01"][5] = Record{}
+	if _, err := f.Position("G01", f.Epochs[5].Add(time.Minute), OrbitOpts{}); !errors.Is(err, ErrNoOrbit) {
+		t.Errorf("missing sample: get err=%v, want %v", err, ErrNoOrbit)
+	}
+	f.Records["G01"][7].HasVel = false
+	if _, err := f.Position("G01", f.Epochs[7].Add(time.Minute), OrbitOpts{Hermite: true}); !errors.Is(err, ErrNoOrbit) {
+		t.Errorf("missing velocity: get err=%v, want %v", err, ErrNoOrbit)
+	}
+}
+
+// TestParseVelocity checks the velocity records of the file with the epochs
+// with and without the velocities.
+func TestParseVelocity(t *testing.T) {
+	lines := readTestFile(t)
+	var b strings.Builder
+	for _, l := range lines {
+		if l == "EOF" {
+			break
+		}
+		if strings.HasPrefix(l, "#cP") {
+			l = "#cV" + l[3:]
+		}
+		b.WriteString(l + "\n")
+		if strings.HasPrefix(l, "PG14") {
+			b.WriteString("VG14  -4525.119283  -4953.880045  26640.224591     -0.077960\n")
+		}
+	}
+	// the second epoch with the velocity of the other satellite
+	b.WriteString("*  2024  7 14  0 15  0.00000000\n")
+	b.WriteString("PG02 -18350.488725  -6421.169947  18706.745770   -399.560198\n")
+	b.WriteString("PG14 -12005.459353  22848.755674   5796.967796    448.162636\n")
+	b.WriteString("PG03 -19010.942887   7137.091598  16892.674725    456.857028\n")
+	b.WriteString("VG03   1000.000000  -2000.000000   3000.000000 999999.999999\n")
+	b.WriteString("EOF\n")
+
+	f, err := Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !f.HasVelocity() || f.Header.PosVel != 'V' {
+		t.Errorf("velocity not found")
+	}
+
+	for _, tt := range []struct {
+		id      string
+		k       int
+		vel     [3]float64
+		hasVel  bool
+		hasRate bool
+	}{
+		{"G14", 0, [3]float64{-452.5119283, -495.3880045, 2664.0224591}, true, true},
+		{"G02", 0, [3]float64{}, false, false},
+		{"G14", 1, [3]float64{}, false, false},
+		{"G03", 1, [3]float64{100., -200., 300.}, true, false},
+	} {
+		r := f.Records[tt.id][tt.k]
+		if r.HasVel != tt.hasVel || r.HasClockRate != tt.hasRate || !r.HasPos {
+			t.Errorf("%s at %d: %+v", tt.id, tt.k, r)
+		}
+		for c := range 3 {
+			if math.Abs(r.Vel[c]-tt.vel[c]) > 1e-9 {
+				t.Errorf("%s at %d: velocity %v, want %v", tt.id, tt.k, r.Vel, tt.vel)
+				break
+			}
+		}
+	}
+	if r := f.Records["G14"][0]; math.Abs(r.ClockRate+0.077960e-10) > 1e-20 {
+		t.Errorf("clock rate: %e", r.ClockRate)
+	}
+
+	if f, _ := ReadFile(testFile); f.HasVelocity() {
+		t.Errorf("velocity found in the file without the velocities")
+	}
+}
+
+func sqr(x float64) float64 { return x * x }
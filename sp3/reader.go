@@ -0,0 +1,387 @@
+package sp3
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/internal/field"
+	mscanner "github.com/satoshi-pes/modscanner"
+)
+
+// maxSatsC is the maximum number of the satellites of SP3-c, which has five
+// lines of the satellites and of the accuracies. SP3-d extends the lines for
+// more satellites.
+const maxSatsC = 85
+
+// satsPerLine is the number of the satellites in a "+ " or "++" line.
+const satsPerLine = 17
+
+// ReadFile reads the SP3 file of the name.
+func ReadFile(name string) (*File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads an SP3-c or SP3-d file from r. The version is detected from
+// the first line.
+//
+// The correlation records are skipped. The errors
+// are wrapped with the line number, and the format errors are tested by
+// errors.Is with ErrFormat.
+func Parse(r io.Reader) (*File, error) {
+	p := parser{s: mscanner.NewScanner(r)}
+	f, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.s.LineNumber(), err)
+	}
+	return f, nil
+}
+
+// parser holds the state of Parse.
+type parser struct {
+	s *mscanner.Scanner
+	f File
+
+	line    string // current line padded to 80 columns
+	pending bool   // line is read but not processed
+	nplus   int    // number of the "+ " lines
+	nacc    int    // number of the "++" lines
+}
+
+// next reads the next line, and returns false at the end of the input.
+func (p *parser) next() bool {
+	if p.pending {
+		p.pending = false
+		return true
+	}
+	if !p.s.Scan() {
+		return false
+	}
+	p.line = fmt.Sprintf("%-80s", strings.TrimRight(p.s.Text(), "\r"))
+	return true
+}
+
+func (p *parser) parse() (*File, error) {
+	if err := p.parseHeader(); err != nil {
+		return nil, err
+	}
+	if err := p.parseData(); err != nil {
+		return nil, err
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	return &p.f, nil
+}
+
+// parseHeader parses the header lines until the first epoch line.
+func (p *parser) parseHeader() error {
+	h := &p.f.Header
+
+	if !p.next() {
+		return fmt.Errorf("%w: empty input", ErrFormat)
+	}
+	if err := p.parseFirstLine(); err != nil {
+		return err
+	}
+
+	if !p.next() || !strings.HasPrefix(p.line, "##") {
+		return fmt.Errorf("%w: second line not found", ErrFormat)
+	}
+	if err := p.parseSecondLine(); err != nil {
+		return err
+	}
+
+	var nsat, nc int
+	for p.next() {
+		switch {
+		case strings.HasPrefix(p.line, "++"):
+			p.nacc++
+			for k := 9; k+3 <= 9+3*satsPerLine && len(h.Accuracy) < nsat; k += 3 {
+				acc, err := field.Atoi(p.line[k : k+3])
+				if err != nil {
+					return fmt.Errorf("%w: accuracy: %v", ErrFormat, err)
+				}
+				h.Accuracy = append(h.Accuracy, acc)
+			}
+		case strings.HasPrefix(p.line, "+"):
+			p.nplus++
+			if p.nplus == 1 {
+				n, err := field.Atoi(p.line[3:6])
+				if err != nil {
+					return fmt.Errorf("%w: number of satellites: %v", ErrFormat, err)
+				}
+				nsat = n
+			}
+			for k := 9; k+3 <= 9+3*satsPerLine && len(h.Sats) < nsat; k += 3 {
+				id, err := satID(p.line[k : k+3])
+				if err != nil {
+					return err
+				}
+				h.Sats = append(h.Sats, id)
+			}
+		case strings.HasPrefix(p.line, "%c"):
+			nc++
+			if nc == 1 {
+				h.FileType = p.line[3]
+				h.TimeSystem = strings.TrimSpace(p.line[9:12])
+			}
+		case strings.HasPrefix(p.line, "%f"), strings.HasPrefix(p.line, "%i"):
+			// base numbers and the unused fields
+		case strings.HasPrefix(p.line, "/*"):
+			h.Comments = append(h.Comments, comment(p.line))
+		case strings.HasPrefix(p.line, "*"):
+			p.pending = true
+			return p.checkHeader(nsat)
+		default:
+			return fmt.Errorf("%w: unknown header line: '%s'", ErrFormat, strings.TrimSpace(p.line))
+		}
+	}
+
+	return fmt.Errorf("%w: no epoch found", ErrFormat)
+}
+
+// checkHeader checks the header against the version.
+func (p *parser) checkHeader(nsat int) error {
+	h := &p.f.Header
+	if len(h.Sats) != nsat {
+		return fmt.Errorf("%w: number of satellites: declared=%d, listed=%d", ErrFormat, nsat, len(h.Sats))
+	}
+	if len(h.Accuracy) != nsat {
+		return fmt.Errorf("%w: number of accuracies: satellites=%d, accuracies=%d", ErrFormat, nsat, len(h.Accuracy))
+	}
+
+	// SP3-c has five lines, and SP3-d has as many lines as needed
+	nlines := 5
+	switch h.Version {
+	case 'c':
+		if nsat > maxSatsC {
+			return fmt.Errorf("%w: too many satellites for SP3-c: n=%d", ErrFormat, nsat)
+		}
+	case 'd':
+		nlines = max(nlines, (nsat+satsPerLine-1)/satsPerLine)
+	}
+	if p.nplus != nlines || p.nacc != nlines {
+		return fmt.Errorf("%w: number of the satellite and accuracy lines: get %d and %d, want %d", ErrFormat, p.nplus, p.nacc, nlines)
+	}
+	return nil
+}
+
+// parseFirstLine parses the first line:
+//
+//	#cP2024  7 14  0  0  0.00000000      96 ORBIT IGb20 HLM  IGS
+func (p *parser) parseFirstLine() (err error) {
+	h := &p.f.Header
+	l := p.line
+	if l[0] != '#' {
+		return fmt.Errorf("%w: first line: '%s'", ErrFormat, strings.TrimSpace(l))
+	}
+	h.Version, h.PosVel = l[1], l[2]
+	if h.Version != 'c' && h.Version != 'd' {
+		return fmt.Errorf("%w: unsupported version: '%c'", ErrFormat, h.Version)
+	}
+	if h.PosVel != 'P' && h.PosVel != 'V' {
+		return fmt.Errorf("%w: position/velocity flag: '%c'", ErrFormat, h.PosVel)
+	}
+
+	if h.Start, err = parseEpoch(l[3:31]); err != nil {
+		return err
+	}
+	if h.NumEpochs, err = field.Atoi(l[32:39]); err != nil {
+		return fmt.Errorf("%w: number of epochs: %v", ErrFormat, err)
+	}
+	h.DataUsed = strings.TrimSpace(l[40:45])
+	h.Frame = strings.TrimSpace(l[46:51])
+	h.OrbitType = strings.TrimSpace(l[52:55])
+	h.Agency = strings.TrimSpace(l[56:60])
+
+	return nil
+}
+
+// parseSecondLine parses the second line:
+//
+//	## 2323      0.00000000   900.00000000 60505 0.0000000000000
+func (p *parser) parseSecondLine() (err error) {
+	h := &p.f.Header
+	l := p.line
+	if h.GPSWeek, err = field.Atoi(l[3:7]); err != nil {
+		return fmt.Errorf("%w: gps week: %v", ErrFormat, err)
+	}
+	if h.SOW, err = field.Atof(l[8:23]); err != nil {
+		return fmt.Errorf("%w: seconds of week: %v", ErrFormat, err)
+	}
+	interval, err := field.Atof(l[24:38])
+	if err != nil {
+		return fmt.Errorf("%w: interval: %v", ErrFormat, err)
+	}
+	h.Interval = time.Duration(math.Round(interval * 1e9))
+	return nil
+}
+
+// parseData parses the epoch and the record lines.
+func (p *parser) parseData() error {
+	f := &p.f
+	f.Records = make(map[string][]Record, len(f.Header.Sats))
+	for _, id := range f.Header.Sats {
+		f.Records[id] = nil
+	}
+
+	for p.next() {
+		l := p.line
+		switch {
+		case strings.HasPrefix(l, "EOF"):
+			return nil
+		case l[0] == '*':
+			t, err := parseEpoch(l[3:31])
+			if err != nil {
+				return err
+			}
+			if n := len(f.Epochs); n > 0 && !t.After(f.Epochs[n-1]) {
+				return fmt.Errorf("%w: epoch not increasing: %v", ErrFormat, t)
+			}
+			f.Epochs = append(f.Epochs, t)
+			for id, recs := range f.Records {
+				f.Records[id] = append(recs, Record{})
+			}
+		case l[0] == 'P':
+			if len(f.Epochs) == 0 {
+				return fmt.Errorf("%w: record before the first epoch", ErrFormat)
+			}
+			id, rec, err := parsePos(l)
+			if err != nil {
+				return err
+			}
+			recs, ok := f.Records[id]
+			if !ok {
+				return fmt.Errorf("%w: satellite not in the header: %s", ErrFormat, id)
+			}
+			recs[len(recs)-1] = rec
+		case l[0] == 'V':
+			// the velocity is stored to the record of the satellite at the
+			// current epoch, whether or not the other satellites have it
+			if len(f.Epochs) == 0 {
+				return fmt.Errorf("%w: record before the first epoch", ErrFormat)
+			}
+			id, err := satID(l[1:4])
+			if err != nil {
+				return err
+			}
+			recs, ok := f.Records[id]
+			if !ok {
+				return fmt.Errorf("%w: satellite not in the header: %s", ErrFormat, id)
+			}
+			if err := parseVel(l, &recs[len(recs)-1]); err != nil {
+				return fmt.Errorf("%w: velocity of %s: %v", ErrFormat, id, err)
+			}
+		case strings.HasPrefix(l, "EP"), strings.HasPrefix(l, "EV"):
+			// correlation records
+		case strings.HasPrefix(l, "/*") && f.Header.Version != 'c':
+			// SP3-d allows the comments after the header
+			f.Header.Comments = append(f.Header.Comments, comment(l))
+		case strings.TrimSpace(l) == "":
+		default:
+			return fmt.Errorf("%w: unknown line: '%s'", ErrFormat, strings.TrimSpace(l))
+		}
+	}
+
+	return nil
+}
+
+// parsePos parses a position record with the optional standard deviations
+// and flags in the columns 61-80:
+//
+//	PG01  -12005.459353  22848.755674   5796.967796    448.162636  7  8  7 128 EP  MP
+func parsePos(l string) (id string, rec Record, err error) {
+	if id, err = satID(l[1:4]); err != nil {
+		return id, rec, err
+	}
+	var v [4]float64
+	for k := range v {
+		if v[k], err = field.Atof(l[4+14*k : 18+14*k]); err != nil {
+			return id, rec, fmt.Errorf("%w: record of %s: %v", ErrFormat, id, err)
+		}
+	}
+
+	if v[0] != 0 || v[1] != 0 || v[2] != 0 {
+		rec.Pos = [3]float64{v[0] * 1e3, v[1] * 1e3, v[2] * 1e3}
+		rec.HasPos = true
+	}
+	if v[3] < BadClock-1e-6 {
+		rec.Clock = v[3] * 1e-6
+		rec.HasClock = true
+	}
+
+	for c, cols := range [][2]int{{61, 63}, {64, 66}, {67, 69}} {
+		if rec.PosSdev[c], err = field.AtoiBlank(l[cols[0]:cols[1]]); err != nil {
+			return id, rec, fmt.Errorf("%w: record of %s: sdev: %v", ErrFormat, id, err)
+		}
+	}
+	if rec.ClockSdev, err = field.AtoiBlank(l[70:73]); err != nil {
+		return id, rec, fmt.Errorf("%w: record of %s: sdev: %v", ErrFormat, id, err)
+	}
+	rec.ClockEvent = l[74] == 'E'
+	rec.ClockPred = l[75] == 'P'
+	rec.Maneuver = l[78] == 'M'
+	rec.OrbitPred = l[79] == 'P'
+
+	return id, rec, nil
+}
+
+// comment returns the text of the comment line. The comment of SP3-c is up
+// to 60 columns, while that of SP3-d is up to 80 columns; the longer ones
+// are accepted for both versions.
+func comment(l string) string {
+	return strings.TrimRight(l[min(3, len(l)):], " ")
+}
+
+// parseVel parses a velocity record into rec:
+//
+//	VG01  -4525.119283  -4953.880045  26640.224591     -0.077960
+//
+// where the velocity is in dm/s and the clock rate is in 1e-4 us/s.
+func parseVel(l string, rec *Record) error {
+	var v [4]float64
+	for k := range v {
+		var err error
+		if v[k], err = field.Atof(l[4+14*k : 18+14*k]); err != nil {
+			return err
+		}
+	}
+
+	rec.Vel = [3]float64{v[0] * 0.1, v[1] * 0.1, v[2] * 0.1}
+	rec.HasVel = true
+	if v[3] < BadClock-1e-6 {
+		rec.ClockRate = v[3] * 1e-10
+		rec.HasClockRate = true
+	}
+	return nil
+}
+
+// parseEpoch parses the epoch in the columns 4-31 of the first line and the
+// epoch lines, e.g., "2024  7 14  0  0  0.00000000".
+func parseEpoch(s string) (time.Time, error) {
+	t, err := field.Epoch(s)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return t, nil
+}
+
+// satID returns the satellite ID in the form of "G01". The blank system
+// of the old files is GPS, and the blank of the number is zero.
+func satID(s string) (string, error) {
+	id, err := field.SatID(s, 'G')
+	if err != nil {
+		return "", fmt.Errorf("%w: satellite ID: %v", ErrFormat, err)
+	}
+	return id.String(), nil
+}
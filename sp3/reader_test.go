@@ -0,0 +1,240 @@
+package sp3
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFile is the fixture of the IGS rapid orbit.
+const testFile = "testdata/igr23230_excerpt.sp3"
+
+// readTestFile returns the lines of the fixture.
+func readTestFile(t *testing.T) []string {
+	t.Helper()
+	b, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+}
+
+// TestReadFile checks the header and the records of the fixture.
+func TestReadFile(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	h := f.Header
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	if h.Version != 'c' || h.PosVel != 'P' || !h.Start.Equal(t0) || h.NumEpochs != 1 {
+		t.Errorf("first line: %+v", h)
+	}
+	if h.DataUsed != "ORBIT" || h.Frame != "IGb20" || h.OrbitType != "HLM" || h.Agency != "IGS" {
+		t.Errorf("first line: %+v", h)
+	}
+	if h.GPSWeek != 2323 || h.SOW != 0 || h.Interval != 15*time.Minute {
+		t.Errorf("second line: %+v", h)
+	}
+	want := []string{"G02", "G03", "G04", "G06", "G14", "G17", "G19", "G21", "G22"}
+	if !slices.Equal(h.Sats, want) {
+		t.Errorf("satellites: get %v, want %v", h.Sats, want)
+	}
+	if h.FileType != 'G' || h.TimeSystem != "GPS" || len(h.Comments) != 4 {
+		t.Errorf("file type=%c, time system=%s, comments=%q", h.FileType, h.TimeSystem, h.Comments)
+	}
+
+	if len(f.Epochs) != 1 || !f.Epochs[0].Equal(t0) {
+		t.Fatalf("epochs: %v", f.Epochs)
+	}
+	rec, ok := f.Record("G14", t0)
+	if !ok || !rec.HasPos || !rec.HasClock {
+		t.Fatalf("G14: %+v, %v", rec, ok)
+	}
+	if rec.Pos != [3]float64{-12005459.353, 22848755.674, 5796967.796} || math.Abs(rec.Clock-448.162636e-6) > 1e-15 {
+		t.Errorf("G14: %+v", rec)
+	}
+
+	if _, ok := f.Record("G01", t0); ok {
+		t.Errorf("record of the satellite not in the file")
+	}
+	if _, ok := f.Record("G14", t0.Add(time.Second)); ok {
+		t.Errorf("record of the epoch not in the file")
+	}
+}
+
+// TestParseMissing checks the missing clock and position are reported.
+func TestParseMissing(t *testing.T) {
+	lines := readTestFile(t)
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "PG14"):
+			lines[i] = l[:46] + " 999999.999999"
+		case strings.HasPrefix(l, "PG02"):
+			lines[i] = "PG02      0.000000      0.000000      0.000000   -399.560198"
+		}
+	}
+
+	f, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	t0 := f.Epochs[0]
+	if rec, ok := f.Record("G14", t0); !ok || !rec.HasPos || rec.HasClock || rec.Clock != 0 {
+		t.Errorf("G14: %+v", rec)
+	}
+	if rec, ok := f.Record("G02", t0); !ok || rec.HasPos || !rec.HasClock {
+		t.Errorf("G02: %+v", rec)
+	}
+}
+
+// TestParseError checks the errors are reported with the line numbers.
+func TestParseError(t *testing.T) {
+	for _, tt := range []struct {
+		line    int // 1-based line number to replace
+		replace string
+		errLine int // line number of the error
+	}{
+		{1, "#aP2024  7 14  0  0  0.00000000       1 ORBIT IGb20 HLM  IGS", 1},
+		{2, "## 2323      0.00000000   xxx.00000000 60505 0.0000000000000", 2},
+		{3, "+   10   G02G03G04G06G14G17G19G21G22  0  0  0  0  0  0  0  0", 3},
+		{23, "*  2024  7 14  0  0  x.00000000", 23},
+		{24, "PG02 -18350.48872x  -6421.169947  18706.745770   -399.560198", 24},
+		{24, "PG01 -18350.488725  -6421.169947  18706.745770   -399.560198", 24},
+	} {
+		lines := readTestFile(t)
+		lines[tt.line-1] = tt.replace
+		_, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+		if !errors.Is(err, ErrFormat) {
+			t.Errorf("line %d: get err=%v, want %v", tt.line, err, ErrFormat)
+			continue
+		}
+		if prefix := fmt.Sprintf("line %d:", tt.errLine); !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("get err=%v, want prefix '%s'", err, prefix)
+		}
+	}
+}
+
+// testSP3d returns an SP3-d file of the multi-GNSS product with n
+// satellites, whose accuracy exponents are 1 to 9 in turn.
+func testSP3d(n int) (string, []string) {
+	var sats []string
+	for _, sys := range []struct {
+		c byte
+		n int
+	}{{'G', 32}, {'R', 27}, {'E', 36}, {'C', 46}, {'J', 7}} {
+		for k := 1; k <= sys.n && len(sats) < n; k++ {
+			sats = append(sats, fmt.Sprintf("%c%02d", sys.c, k))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("#dP2024  7 14  0  0  0.00000000       2 ORBIT IGS20 FIT  COD\n")
+	b.WriteString("## 2323      0.00000000   300.00000000 60505 0.0000000000000\n")
+	nlines := max(5, (n+16)/17)
+	for i := range nlines {
+		if i == 0 {
+			fmt.Fprintf(&b, "+  %3d   ", n)
+		} else {
+			b.WriteString("+        ")
+		}
+		for k := 17 * i; k < 17*(i+1); k++ {
+			if k < n {
+				b.WriteString(sats[k])
+			} else {
+				b.WriteString("  0")
+			}
+		}
+		b.WriteString("\n")
+	}
+	for i := range nlines {
+		b.WriteString("++       ")
+		for k := 17 * i; k < 17*(i+1); k++ {
+			if k < n {
+				fmt.Fprintf(&b, "%3d", k%9+1)
+			} else {
+				b.WriteString("  0")
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("%c M  cc GPS ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc\n")
+	b.WriteString("%c cc cc ccc ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc\n")
+	b.WriteString("%f  1.2500000  1.025000000  0.00000000000  0.000000000000000\n")
+	b.WriteString("%f  0.0000000  0.000000000  0.00000000000  0.000000000000000\n")
+	b.WriteString("%i    0    0    0    0      0      0      0      0         0\n")
+	b.WriteString("%i    0    0    0    0      0      0      0      0         0\n")
+	for i := range 6 {
+		fmt.Fprintf(&b, "/* MULTI-GNSS TEST HEADER, COMMENT LINE %d%s\n", i+1, strings.Repeat(".", 30))
+	}
+	b.WriteString("*  2024  7 14  0  0  0.00000000\n")
+	b.WriteString("PG01 -12005.459353  22848.755674   5796.967796    448.162636\n")
+	b.WriteString("PR01 -19010.942887   7137.091598  16892.674725    456.857028\n")
+	b.WriteString("/* COMMENT BETWEEN THE EPOCHS\n")
+	b.WriteString("*  2024  7 14  0  5  0.00000000\n")
+	b.WriteString("PG01 -12000.000000  22850.000000   5800.000000    448.162000\n")
+	b.WriteString("EOF\n")
+
+	return b.String(), sats
+}
+
+// TestParseSP3d checks the extended satellite list, the accuracies, and the
+// comments of SP3-d.
+func TestParseSP3d(t *testing.T) {
+	src, sats := testSP3d(120)
+	f, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	h := f.Header
+	if h.Version != 'd' || h.FileType != 'M' || h.Agency != "COD" || h.Interval != 5*time.Minute {
+		t.Errorf("header: %+v", h)
+	}
+	if !slices.Equal(h.Sats, sats) {
+		t.Errorf("satellites: get %v, want %v", h.Sats, sats)
+	}
+	if len(h.Accuracy) != len(sats) {
+		t.Fatalf("accuracies: %v", h.Accuracy)
+	}
+	for i, acc := range h.Accuracy {
+		if acc != i%9+1 {
+			t.Errorf("accuracy of %s: get %d, want %d", sats[i], acc, i%9+1)
+		}
+	}
+	if len(h.Comments) != 7 || len(h.Comments[0]) <= 60 || h.Comments[6] != "COMMENT BETWEEN THE EPOCHS" {
+		t.Errorf("comments: %q", h.Comments)
+	}
+
+	if len(f.Epochs) != 2 {
+		t.Fatalf("epochs: %v", f.Epochs)
+	}
+	if rec, ok := f.Record("R01", f.Epochs[0]); !ok || math.Abs(rec.Pos[0]+19010942.887) > 1e-6 {
+		t.Errorf("R01: %+v", rec)
+	}
+	if _, ok := f.Record("R01", f.Epochs[1]); ok {
+		t.Errorf("R01: record at the second epoch")
+	}
+
+	// more than 85 satellites are not allowed for SP3-c
+	if _, err := Parse(strings.NewReader(strings.Replace(src, "#d", "#c", 1))); !errors.Is(err, ErrFormat) {
+		t.Errorf("SP3-c with 120 satellites: get err=%v, want %v", err, ErrFormat)
+	}
+
+	// the file with fewer satellites has five lines for both versions
+	src, _ = testSP3d(40)
+	if _, err := Parse(strings.NewReader(src)); err != nil {
+		t.Errorf("SP3-d with 40 satellites: %v", err)
+	}
+	c := strings.Replace(src, "#d", "#c", 1)
+	c = strings.Replace(c, "/* COMMENT BETWEEN THE EPOCHS\n", "", 1)
+	if _, err := Parse(strings.NewReader(c)); err != nil {
+		t.Errorf("SP3-c with 40 satellites: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Package sp3 reads the SP3 precise orbit and clock files.
+
+The positions are converted into meters and the clocks into seconds from km
+and microseconds of the files. The epochs are in the time system of the file
+(see Header.TimeSystem), and are represented as time.Time in UTC without
+the leap seconds, as the other packages of the module.
+*/
+package sp3
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// BadClock is the clock value (microseconds) of the missing clock in SP3.
+const BadClock = 999999.999999
+
+// ErrFormat is returned when the file is not a valid SP3 file. The errors of
+// the parser are wrapped with the line number.
+var ErrFormat = errors.New("invalid sp3 format")
+
+// Header stores the header of an SP3 file.
+type Header struct {
+	// Version is the version character, 'c' or 'd', and PosVel is 'P' for
+	// the positions only or 'V' with the velocities.
+	Version byte
+	PosVel  byte
+
+	// Start is the first epoch, and NumEpochs is the number of the epochs
+	// declared in the header.
+	Start     time.Time
+	NumEpochs int
+
+	// DataUsed is the data descriptor, e.g., "ORBIT", Frame is the
+	// coordinate frame, e.g., "IGb20", OrbitType is, e.g., "FIT" or "HLM",
+	// and Agency is the agency generating the orbit.
+	DataUsed  string
+	Frame     string
+	OrbitType string
+	Agency    string
+
+	// GPSWeek and SOW (s) are the GPS week and the seconds of the week of
+	// the first epoch, and Interval is the epoch interval.
+	GPSWeek  int
+	SOW      float64
+	Interval time.Duration
+
+	// Sats is the list of the satellite IDs, e.g., "G01", and Accuracy is
+	// the accuracy exponents of the satellites in the same order, i.e., the
+	// accuracy is 2^Accuracy[i] mm, where 0 means unknown.
+	Sats     []string
+	Accuracy []int
+
+	// FileType is the satellite system of the file, e.g., 'G' or 'M' for
+	// the mixed file, and TimeSystem is, e.g., "GPS".
+	FileType   byte
+	TimeSystem string
+
+	// Comments stores the comment lines without the leading "/* ",
+	// including those after the header of SP3-d.
+	Comments []string
+}
+
+// Record is the position and the clock of a satellite at an epoch, and the
+// velocity and the clock rate if the file has the velocity records.
+// The missing values in the file (zero position or BadClock) are reported
+// by HasPos and HasClock, and the values are zero.
+type Record struct {
+	Pos   [3]float64 // position (ECEF, m)
+	Clock float64    // clock bias (s)
+
+	HasPos, HasClock bool
+
+	// PosSdev and ClockSdev are the exponents of the standard deviations of
+	// the position (x, y, z) and the clock for the base numbers of the
+	// header, usually 1.25 (mm) and 1.025 (psec), where 0 means unknown.
+	PosSdev   [3]int
+	ClockSdev int
+
+	// ClockEvent and Maneuver are the event flags of the clock and of the
+	// orbit, and ClockPred and OrbitPred are the flags of the predicted
+	// values.
+	ClockEvent, ClockPred bool
+	Maneuver, OrbitPred   bool
+
+	Vel       [3]float64 // velocity (ECEF, m/s)
+	ClockRate float64    // clock rate (s/s)
+
+	HasVel, HasClockRate bool
+}
+
+// File stores the contents of an SP3 file.
+type File struct {
+	Header Header
+
+	// Epochs stores the epochs in the order of the file.
+	Epochs []time.Time
+
+	// Records stores the records of the satellites, where Records[id][k] is
+	// the record of the satellite id at Epochs[k]. The zero Record is stored
+	// for the epoch without the record of the satellite.
+	Records map[string][]Record
+}
+
+// HasVelocity reports whether the file has any velocity record.
+func (f *File) HasVelocity() bool {
+	for _, recs := range f.Records {
+		for _, r := range recs {
+			if r.HasVel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EpochIndex returns the index of the epoch t in Epochs, or -1 if not found.
+func (f *File) EpochIndex(t time.Time) int {
+	k := sort.Search(len(f.Epochs), func(i int) bool { return !f.Epochs[i].Before(t) })
+	if k < len(f.Epochs) && f.Epochs[k].Equal(t) {
+		return k
+	}
+	return -1
+}
+
+// Record returns the record of the satellite id at the epoch t, and false if
+// the file has no record of the satellite at the epoch.
+func (f *File) Record(id string, t time.Time) (Record, bool) {
+	recs, ok := f.Records[id]
+	if !ok {
+		return Record{}, false
+	}
+	k := f.EpochIndex(t)
+	if k < 0 {
+		return Record{}, false
+	}
+	r := recs[k]
+	return r, r.HasPos || r.HasClock
+}
@@ -0,0 +1,198 @@
+package sp3
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// WriteFile writes f to the file of the name by Encode.
+func WriteFile(name string, f *File) error {
+	fp, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := Encode(fp, f); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// Encode writes f to w in SP3 format.
+//
+// The version is Header.Version, or 'c' if zero and 'd' for more than 85
+// satellites. The number of epochs, the first epoch, the GPS week and the
+// seconds of week are derived from Epochs, and the velocity records are
+// written for the records with HasVel. The base numbers of the accuracies are
+// written as the common values, 1.25 mm and 1.025 psec, and at least four
+// comment lines are written as required by SP3-c.
+//
+// The positions are written in km and the clocks in microseconds with six
+// decimals, i.e., the values are preserved to 1 mm and 1 psec.
+func Encode(w io.Writer, f *File) error {
+	h := f.Header
+	if len(f.Epochs) == 0 {
+		return fmt.Errorf("no epoch")
+	}
+	if h.Version == 0 {
+		h.Version = 'c'
+		if len(h.Sats) > maxSatsC {
+			h.Version = 'd'
+		}
+	}
+	if h.Version != 'c' && h.Version != 'd' {
+		return fmt.Errorf("unsupported version: '%c'", h.Version)
+	}
+	if h.Version == 'c' && len(h.Sats) > maxSatsC {
+		return fmt.Errorf("too many satellites for SP3-c: n=%d", len(h.Sats))
+	}
+	if h.Accuracy != nil && len(h.Accuracy) != len(h.Sats) {
+		return fmt.Errorf("size mismatch: sats=%d, accuracies=%d", len(h.Sats), len(h.Accuracy))
+	}
+	if h.PosVel == 0 {
+		h.PosVel = 'P'
+		if f.HasVelocity() {
+			h.PosVel = 'V'
+		}
+	}
+	if h.FileType == 0 {
+		h.FileType = 'M'
+	}
+	if h.TimeSystem == "" {
+		h.TimeSystem = "GPS"
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// first and second lines
+	start := f.Epochs[0]
+	fmt.Fprintf(bw, "#%c%c%s %7d %-5s %-5s %-3s %4s\n", h.Version, h.PosVel, formatEpoch(start),
+		len(f.Epochs), h.DataUsed, h.Frame, h.OrbitType, h.Agency)
+
+	week, sow := gnsstime.NewGPST(start).Week()
+	day := gnsstime.MJDDay(start)
+	frac := start.Sub(gnsstime.FromMJDDay(day)).Seconds() / 86400.
+	fmt.Fprintf(bw, "## %4d %15.8f %14.8f %5d %15.13f\n", week, sow, h.Interval.Seconds(), day, frac)
+
+	// satellites and accuracies
+	nlines := 5
+	if h.Version == 'd' {
+		nlines = max(nlines, (len(h.Sats)+satsPerLine-1)/satsPerLine)
+	}
+	for i := range nlines {
+		if i == 0 {
+			fmt.Fprintf(bw, "+  %3d   ", len(h.Sats))
+		} else {
+			bw.WriteString("+        ")
+		}
+		for k := i * satsPerLine; k < (i+1)*satsPerLine; k++ {
+			if k < len(h.Sats) {
+				bw.WriteString(h.Sats[k])
+			} else {
+				bw.WriteString("  0")
+			}
+		}
+		bw.WriteString("\n")
+	}
+	for i := range nlines {
+		bw.WriteString("++       ")
+		for k := i * satsPerLine; k < (i+1)*satsPerLine; k++ {
+			acc := 0
+			if k < len(h.Accuracy) {
+				acc = h.Accuracy[k]
+			}
+			fmt.Fprintf(bw, "%3d", acc)
+		}
+		bw.WriteString("\n")
+	}
+
+	fmt.Fprintf(bw, "%%c %c  cc %-3s ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc\n", h.FileType, h.TimeSystem)
+	bw.WriteString("%c cc cc ccc ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc\n")
+	bw.WriteString("%f  1.2500000  1.025000000  0.00000000000  0.000000000000000\n")
+	bw.WriteString("%f  0.0000000  0.000000000  0.00000000000  0.000000000000000\n")
+	bw.WriteString("%i    0    0    0    0      0      0      0      0         0\n")
+	bw.WriteString("%i    0    0    0    0      0      0      0      0         0\n")
+
+	maxComment := 57
+	if h.Version == 'd' {
+		maxComment = 77
+	}
+	for i := 0; i < max(4, len(h.Comments)); i++ {
+		var c string
+		if i < len(h.Comments) {
+			c = h.Comments[i]
+		}
+		if len(c) > maxComment {
+			c = c[:maxComment]
+		}
+		fmt.Fprintf(bw, "/* %s\n", c)
+	}
+
+	// records
+	for k, t := range f.Epochs {
+		fmt.Fprintf(bw, "*  %s\n", formatEpoch(t))
+		for _, id := range h.Sats {
+			recs := f.Records[id]
+			if k >= len(recs) {
+				continue
+			}
+			r := recs[k]
+			if !r.HasPos && !r.HasClock {
+				continue
+			}
+
+			clk := BadClock
+			if r.HasClock {
+				clk = r.Clock * 1e6
+			}
+			fmt.Fprintf(bw, "P%s%14.6f%14.6f%14.6f%14.6f%s\n", id, r.Pos[0]*1e-3, r.Pos[1]*1e-3, r.Pos[2]*1e-3, clk, formatFlags(r))
+
+			if r.HasVel {
+				rate := BadClock
+				if r.HasClockRate {
+					rate = r.ClockRate * 1e10
+				}
+				fmt.Fprintf(bw, "V%s%14.6f%14.6f%14.6f%14.6f\n", id, r.Vel[0]*10., r.Vel[1]*10., r.Vel[2]*10., rate)
+			}
+		}
+	}
+	bw.WriteString("EOF\n")
+
+	return bw.Flush()
+}
+
+// formatEpoch formats the epoch as "2024  7 14  0  0  0.00000000".
+func formatEpoch(t time.Time) string {
+	sec := float64(t.Second()) + float64(t.Nanosecond())*1e-9
+	return fmt.Sprintf("%4d %2d %2d %2d %2d %11.8f", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), math.Floor(sec*1e8+0.5)*1e-8)
+}
+
+// formatFlags formats the standard deviations and the flags of the columns
+// 61-80 of the position record, or returns "" if none is set.
+func formatFlags(r Record) string {
+	if r.PosSdev == [3]int{} && r.ClockSdev == 0 && !r.ClockEvent && !r.ClockPred && !r.Maneuver && !r.OrbitPred {
+		return ""
+	}
+
+	sdev := func(v, width int) string {
+		if v == 0 {
+			return strings.Repeat(" ", width)
+		}
+		return fmt.Sprintf("%*d", width, v)
+	}
+	flag := func(set bool, c byte) byte {
+		if set {
+			return c
+		}
+		return ' '
+	}
+	return fmt.Sprintf(" %s %s %s %s %c%c  %c%c", sdev(r.PosSdev[0], 2), sdev(r.PosSdev[1], 2), sdev(r.PosSdev[2], 2), sdev(r.ClockSdev, 3),
+		flag(r.ClockEvent, 'E'), flag(r.ClockPred, 'P'), flag(r.Maneuver, 'M'), flag(r.OrbitPred, 'P'))
+}
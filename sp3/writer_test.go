@@ -0,0 +1,130 @@
+package sp3
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTrip writes f and reads it back.
+func roundTrip(t *testing.T, f *File) *File {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Encode(&b, f); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	g, err := Parse(&b)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return g
+}
+
+// compareFiles compares the headers and the records to the printed
+// precision.
+func compareFiles(t *testing.T, f, g *File) {
+	t.Helper()
+	hf, hg := f.Header, g.Header
+	if hf.Version != hg.Version || hf.Frame != hg.Frame || hf.OrbitType != hg.OrbitType || hf.Agency != hg.Agency ||
+		hf.DataUsed != hg.DataUsed || hf.Interval != hg.Interval || !hf.Start.Equal(hg.Start) ||
+		hf.FileType != hg.FileType || hf.TimeSystem != hg.TimeSystem {
+		t.Errorf("header: get %+v, want %+v", hg, hf)
+	}
+	if !slices.Equal(hf.Sats, hg.Sats) || !slices.Equal(hf.Accuracy, hg.Accuracy) {
+		t.Errorf("satellites: get %v %v, want %v %v", hg.Sats, hg.Accuracy, hf.Sats, hf.Accuracy)
+	}
+	if !slices.EqualFunc(f.Epochs, g.Epochs, time.Time.Equal) {
+		t.Errorf("epochs: get %v, want %v", g.Epochs, f.Epochs)
+	}
+
+	for id, recs := range f.Records {
+		for k, r := range recs {
+			s := g.Records[id][k]
+			if r.HasPos != s.HasPos || r.HasClock != s.HasClock || r.HasVel != s.HasVel || r.HasClockRate != s.HasClockRate ||
+				r.PosSdev != s.PosSdev || r.ClockSdev != s.ClockSdev || r.ClockEvent != s.ClockEvent ||
+				r.ClockPred != s.ClockPred || r.Maneuver != s.Maneuver || r.OrbitPred != s.OrbitPred {
+				t.Errorf("%s at %d: get %+v, want %+v", id, k, s, r)
+				continue
+			}
+			for c := range 3 {
+				if math.Abs(r.Pos[c]-s.Pos[c]) > 5e-4 || math.Abs(r.Vel[c]-s.Vel[c]) > 5e-8 {
+					t.Errorf("%s at %d: get %+v, want %+v", id, k, s, r)
+				}
+			}
+			if math.Abs(r.Clock-s.Clock) > 5e-13 || math.Abs(r.ClockRate-s.ClockRate) > 5e-17 {
+				t.Errorf("%s at %d: clock: get %+v, want %+v", id, k, s, r)
+			}
+		}
+	}
+}
+
+// TestEncode checks the round trips of the fixture and of the files with the
+// velocities and the extended satellite list.
+func TestEncode(t *testing.T) {
+	f, err := ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	g := roundTrip(t, f)
+	compareFiles(t, f, g)
+	if !slices.Equal(f.Header.Comments, g.Header.Comments) || g.Header.GPSWeek != 2323 || g.Header.SOW != 0 {
+		t.Errorf("header: %+v", g.Header)
+	}
+
+	// the records are written in the same columns as the original
+	var b bytes.Buffer
+	if err := Encode(&b, f); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	orig := readTestFile(t)
+	for _, l := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(l, "P") || strings.HasPrefix(l, "*") || strings.HasPrefix(l, "#") || strings.HasPrefix(l, "##") {
+			if !slices.Contains(orig, l) {
+				t.Errorf("line not in the original: '%s'", l)
+			}
+		}
+	}
+
+	// velocities, missing clock and clock rate
+	f = testOrbitFile(4)
+	f.Header.Version, f.Header.FileType, f.Header.TimeSystem = 'c', 'G', "GPS"
+	f.Header.Accuracy = []int{5}
+	f.Records["G01"][1].Clock, f.Records["G01"][1].HasClock = 1.234567e-4, true
+	f.Records["G01"][2].ClockRate, f.Records["G01"][2].HasClockRate = 1.5e-12, true
+	g = roundTrip(t, f)
+	compareFiles(t, f, g)
+	if g.Header.PosVel != 'V' {
+		t.Errorf("position/velocity flag: %c", g.Header.PosVel)
+	}
+
+	// SP3-d
+	src, _ := testSP3d(120)
+	f, err = Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	compareFiles(t, f, roundTrip(t, f))
+
+	f.Header.Version = 'c'
+	if err := Encode(&b, f); err == nil {
+		t.Errorf("no error for SP3-c with 120 satellites")
+	}
+
+	// file
+	name := filepath.Join(t.TempDir(), "test.sp3")
+	if err := WriteFile(name, f); err == nil {
+		t.Errorf("no error for SP3-c with 120 satellites")
+	}
+	f.Header.Version = 'd'
+	if err := WriteFile(name, f); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("Stat: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package spp
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// signal is the pseudorange of a satellite corrected for the satellite
+// clock, and the satellite position at the transmission time in the frame at
+// that time.
+type signal struct {
+	id    gnss.SatID
+	code  string     // observation code, e.g., "C1C", or "C1C+C2W" of the combination
+	pos   [3]float64 // satellite position (m)
+	pr    float64    // corrected for the satellite clock (m)
+	clock float64    // satellite clock correction (m)
+	scale float64    // ionospheric delay relative to L1, zero if not modeled
+	dgnss bool       // corrected by DGNSS including the atmospheric delays
+}
+
+// model is the models of a pipeline, which are applied to the epochs by
+// processEpoch.
+type model struct {
+	sys byte
+
+	// signal returns the signal of the satellite id at the reception time t
+	// in GPS time.
+	signal func(e *rinex.Epoch, id gnss.SatID, t time.Time) (signal, error)
+
+	// iono returns the ionospheric delay (m) on L1, or nil if not modeled.
+	iono func(t time.Time, lat, lon, az, el float64) float64
+
+	// antenna returns the satellite data corrected for the antennas seen from
+	// the receiver position rcv (m), and the labels of the satellites without
+	// the antenna models, or nil if not modeled.
+	antenna func(t time.Time, rcv [3]float64, satDatas []bancroft.SatData) (corrected []bancroft.SatData, missing []string, err error)
+
+	// header is called with the header updated by the header lines of the
+	// events, e.g., the antenna changed, or nil.
+	header func(h rinex.ObsHeader) error
+
+	mask    float64 // elevation mask (deg)
+	weights bancroft.WeightModel
+	noTropo bool
+	mapping tropo.Mapping
+	maxIter int
+
+	// met returns the meteorological parameters at the epoch and the height
+	// (m) of the station, or nil for the standard atmosphere.
+	met func(t time.Time, h float64) tropo.Met
+}
+
+// processEpochs returns the results of the epochs of the reader in the time
+// system ts by the model, skipping the events and the cycle slip records. The
+// codes of RINEX 2 are converted to those of RINEX 3 by rinex.DefaultCodeMap.
+func processEpochs(or *rinex.ObsReader, ts string, m model) ([]Result, error) {
+	var results []Result
+	for {
+		e, err := or.Next()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("obs: %w", err)
+		}
+		if e.IsEvent() && len(e.HeaderLines) > 0 && m.header != nil {
+			if err := m.header(or.Header); err != nil {
+				return results, err
+			}
+		}
+		if e.Flag > rinex.FlagPowerFailure {
+			continue
+		}
+		if or.Header.Version < 3 {
+			rinex.DefaultCodeMap.NormalizeEpoch(e)
+		}
+		t, _ := gpsTime(e.Time, ts)
+		results = append(results, processEpoch(e, t, m))
+	}
+}
+
+// processEpoch returns the solution of the epoch e at the time t in GPS time.
+//
+// The initial position is solved without the corrections depending on the
+// position, and the corrections are iterated with the solutions of
+// bancroft.SolveLSQ until the position changes less than 1 mm.
+func processEpoch(e *rinex.Epoch, t time.Time, m model) (res Result) {
+	res.Epoch = t
+
+	var sigs []signal
+	for _, s := range e.Sats {
+		if s[0] != m.sys {
+			continue
+		}
+		id, err := gnss.ParseSatID(s)
+		if err != nil {
+			continue
+		}
+		sig, err := m.signal(e, id, t)
+		if err != nil {
+			res.Skipped = append(res.Skipped, Skip{id, err})
+			continue
+		}
+		sigs = append(sigs, sig)
+	}
+
+	satDatas := make([]bancroft.SatData, len(sigs))
+	for i, sig := range sigs {
+		x, y, z := bancroft.RotateSatPos(sig.pos[0], sig.pos[1], sig.pos[2], sig.pr/lightVelocity)
+		satDatas[i] = bancroft.SatData{X: x, Y: y, Z: z, PR: sig.pr, ID: sig.id}
+	}
+	sol, err := bancroft.Solve(satDatas, bancroft.CalcPosOpts{})
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	var skipped []Skip
+	var sats []SatInfo
+	for res.Iterations = 1; res.Iterations <= m.maxIter; res.Iterations++ {
+		pos := sol.Position
+		lat, lon, h := coord.XYZToLLH(pos[0], pos[1], pos[2])
+		met := tropo.StdAtmosphere(h)
+		if m.met != nil && !m.noTropo {
+			met = m.met(t, h)
+		}
+
+		skipped, sats, satDatas = nil, nil, nil
+		for _, sig := range sigs {
+			tau := math.Sqrt(sqr(sig.pos[0]-pos[0])+sqr(sig.pos[1]-pos[1])+sqr(sig.pos[2]-pos[2])) / lightVelocity
+			x, y, z := bancroft.RotateSatPos(sig.pos[0], sig.pos[1], sig.pos[2], tau)
+			az, el := coord.AzEl(pos, [3]float64{x, y, z})
+			if el < coord.Deg2Rad(m.mask) || el <= 0 {
+				skipped = append(skipped, Skip{sig.id, fmt.Errorf("%w: el=%.1f", ErrBelowMask, coord.Rad2Deg(el))})
+				continue
+			}
+
+			info := SatInfo{ID: sig.id, Code: sig.code, Az: az, El: el, Clock: sig.clock}
+			if m.iono != nil && sig.scale != 0 {
+				info.Iono = sig.scale * m.iono(t, lat, lon, az, el)
+			}
+			if !m.noTropo && !sig.dgnss && h > -1000. && h < 10000. {
+				info.Tropo = tropo.SlantDelayMapping(t, lat, lon, h, el, met, m.mapping)
+			}
+			sats = append(sats, info)
+			satDatas = append(satDatas, bancroft.SatData{
+				X: x, Y: y, Z: z,
+				PR: sig.pr - info.Iono - info.Tropo,
+				ID: sig.id,
+			})
+		}
+
+		if m.antenna != nil {
+			corrected, missing, err := m.antenna(t, pos, satDatas)
+			if err != nil {
+				res.Err = err
+				res.Skipped = append(res.Skipped, skipped...)
+				return res
+			}
+			satDatas, sats, skipped = dropMissing(corrected, sats, skipped, missing)
+		}
+
+		w, err := m.weights.Weights(satDatas, pos)
+		if err == nil {
+			sol, err = bancroft.SolveLSQ(satDatas, w)
+		}
+		if err != nil {
+			res.Err = err
+			res.Skipped = append(res.Skipped, skipped...)
+			return res
+		}
+
+		d := math.Sqrt(sqr(sol.Position[0]-pos[0]) + sqr(sol.Position[1]-pos[1]) + sqr(sol.Position[2]-pos[2]))
+		if d < 1e-3 {
+			break
+		}
+	}
+	if res.Iterations > m.maxIter {
+		res.Iterations = m.maxIter
+		sol.Err = fmt.Errorf("not converged: maxIter=%d", m.maxIter)
+	}
+
+	sol.Epoch = t
+	res.Solution = sol
+	res.Sats = sats
+	res.Skipped = append(res.Skipped, skipped...)
+	return res
+}
+
+// dropMissing removes the satellites of the labels from satDatas and sats,
+// and adds them to skipped with ErrNoAntenna.
+func dropMissing(satDatas []bancroft.SatData, sats []SatInfo, skipped []Skip, missing []string) ([]bancroft.SatData, []SatInfo, []Skip) {
+	if len(missing) == 0 {
+		return satDatas, sats, skipped
+	}
+	drop := make(map[string]bool)
+	for _, l := range missing {
+		drop[l] = true
+	}
+
+	var keptData []bancroft.SatData
+	var keptSats []SatInfo
+	for i, s := range satDatas {
+		if drop[s.Label(i)] {
+			skipped = append(skipped, Skip{s.ID, ErrNoAntenna})
+			continue
+		}
+		keptData = append(keptData, s)
+		keptSats = append(keptSats, sats[i])
+	}
+	return keptData, keptSats, skipped
+}
+
+// selectCode returns the code of the first band of the bands observed for
+// the satellite id selected by the priority p, and its pseudorange (m).
+func selectCode(e *rinex.Epoch, id gnss.SatID, bands string, p rinex.Priority) (string, float64, error) {
+	for _, band := range []byte(bands) {
+		if sig, ok := e.Select(id.String(), band, p); ok && sig.Pseudorange.Value > 0 {
+			return sig.Code, sig.Pseudorange.Value, nil
+		}
+	}
+	return "", 0., fmt.Errorf("%w: %s", ErrNoSignal, bands)
+}
+
+// gpsTime returns the epoch t of the time system ts in GPS time.
+func gpsTime(t time.Time, ts string) (time.Time, error) {
+	switch ts {
+	case "", "GPS", "GAL", "QZS":
+		return t, nil
+	case "BDT":
+		return gnsstime.NewBDT(t).GPST().Time(), nil
+	}
+	return t, fmt.Errorf("%w: %s", ErrTimeSystem, ts)
+}
+
+// seconds returns the duration of s seconds.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * 1e9))
+}
+
+func sqr(x float64) float64 {
+	return x * x
+}
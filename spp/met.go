@@ -0,0 +1,98 @@
+package spp
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// defaultMetMaxGap is the maximum gap of the meteorological data
+// interpolated if not given.
+const defaultMetMaxGap = 30 * time.Minute
+
+// metModel returns the meteorological parameters of the tropospheric
+// correction interpolated from a RINEX meteorological file, falling back to
+// the standard atmosphere.
+type metModel struct {
+	file   *rinex.MetFile
+	maxGap time.Duration
+
+	fallback bool // standard atmosphere at the last call, for the warnings
+}
+
+// newMetModel returns the model of the file and the maximum gap of the data
+// interpolated, which is defaultMetMaxGap if zero.
+func newMetModel(file *rinex.MetFile, maxGap time.Duration) *metModel {
+	if maxGap == 0 {
+		maxGap = defaultMetMaxGap
+	}
+	return &metModel{file: file, maxGap: maxGap}
+}
+
+// met returns the meteorological parameters at the epoch t and the height h
+// (m) of the station, i.e., the pressure (PR), the temperature (TD) and the
+// relative humidity (HR) interpolated from the file, or the standard
+// atmosphere with a warning if any of them is in a gap of the data. Those
+// not observed in the file are of the standard atmosphere.
+//
+// The pressure and the temperature are reduced to the height of the station
+// by the standard atmosphere if the heights of the sensors are given.
+func (m *metModel) met(t time.Time, h float64) tropo.Met {
+	std := tropo.StdAtmosphere(h)
+	p, err := m.value("PR", t, h)
+	if err == nil && !math.IsNaN(p) {
+		std.P = p
+	}
+	temp, err2 := m.value("TD", t, h)
+	if err2 == nil && !math.IsNaN(temp) {
+		std.T = temp
+	}
+	humi, err3 := m.value("HR", t, h)
+
+	if err := errors.Join(err, err2, err3); err != nil {
+		if !m.fallback {
+			logger.Printf("warning: %v: standard atmosphere used\n", err)
+		}
+		m.fallback = true
+		return tropo.StdAtmosphere(h)
+	}
+	if m.fallback {
+		logger.Printf("warning: meteorological data available from %v\n", t)
+	}
+	m.fallback = false
+
+	if math.IsNaN(humi) {
+		return std
+	}
+	return tropo.MetHumidity(std.P, std.T, humi/100)
+}
+
+// value returns the value of the type reduced to the height h (m), which is
+// the pressure (hPa), the temperature (K) or the relative humidity (%), or
+// NaN if the type is not observed in the file.
+func (m *metModel) value(typ string, t time.Time, h float64) (float64, error) {
+	if !slices.Contains(m.file.Header.Types, typ) {
+		return math.NaN(), nil
+	}
+	v, err := m.file.Interpolate(typ, t, m.maxGap)
+	if err != nil {
+		return 0, err
+	}
+	s, ok := m.file.Header.Sensor(typ)
+	switch typ {
+	case "PR":
+		if ok && s.HasPos {
+			v *= tropo.StdAtmosphere(h).P / tropo.StdAtmosphere(s.Height).P
+		}
+	case "TD":
+		v += 273.15
+		if ok && s.HasPos {
+			v += tropo.StdAtmosphere(h).T - tropo.StdAtmosphere(s.Height).T
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,102 @@
+package spp
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// testMetFile is the synthetic meteorological file of the fixture every 5
+// min, whose pressure sensor is at 40 m, and the data of 00:20 to 01:25 are
+// missing.
+const testMetFile = "../rinex/testdata/02551960_excerpt.24m"
+
+// captureLog redirects the logger to the returned buffer during the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w := logger.Writer()
+	logger.SetOutput(&buf)
+	t.Cleanup(func() { logger.SetOutput(w) })
+	return &buf
+}
+
+// TestMetModel checks the parameters interpolated and reduced to the height
+// of the station, and the standard atmosphere in the gap with a warning.
+func TestMetModel(t *testing.T) {
+	buf := captureLog(t)
+	f, err := rinex.ReadMetFile(testMetFile)
+	if err != nil {
+		t.Fatalf("ReadMetFile: %v", err)
+	}
+	m := newMetModel(f, 0)
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+
+	// 1007.35 hPa at 40 m reduced to 100 m, 24.05 deg C and 82.25 %
+	h := 100.
+	met := m.met(t0.Add(150*time.Second), h)
+	wantP := 1007.35 * tropo.StdAtmosphere(h).P / tropo.StdAtmosphere(40).P
+	want := tropo.MetHumidity(wantP, 273.15+24.05, 0.8225)
+	if math.Abs(met.P-want.P) > 1e-9 || math.Abs(met.T-want.T) > 1e-9 || math.Abs(met.E-want.E) > 1e-9 {
+		t.Errorf("interpolated: get %+v, want %+v", met, want)
+	}
+	if math.Abs(met.P-1000.2) > 0.1 || buf.Len() != 0 {
+		t.Errorf("pressure at 100 m: %v, log: %s", met.P, buf)
+	}
+
+	// the gaps of 75 min warned once
+	for _, min := range []int{30, 40, 50} {
+		if met := m.met(t0.Add(time.Duration(min)*time.Minute), h); met != tropo.StdAtmosphere(h) {
+			t.Errorf("%d min: get %+v, want the standard atmosphere", min, met)
+		}
+	}
+	if n := strings.Count(buf.String(), "warning"); n != 1 || !strings.Contains(buf.String(), "standard atmosphere") {
+		t.Errorf("warnings: %s", buf)
+	}
+	if met := newMetModel(f, 2*time.Hour).met(t0.Add(30*time.Minute), h); met == tropo.StdAtmosphere(h) {
+		t.Errorf("gap within the maximum: %+v", met)
+	}
+
+	// the standard atmosphere of the types not observed
+	f.Header.Types = []string{"PR"}
+	met = newMetModel(f, 0).met(t0, h)
+	std := tropo.StdAtmosphere(h)
+	if met.P == std.P || met.T != std.T || met.E != std.E {
+		t.Errorf("pressure only: get %+v", met)
+	}
+}
+
+// TestProcessSPPMet checks the tropospheric delays of the meteorological
+// data, which are close to those of the standard atmosphere.
+func TestProcessSPPMet(t *testing.T) {
+	captureLog(t)
+	f, err := rinex.ReadMetFile(testMetFile)
+	if err != nil {
+		t.Fatalf("ReadMetFile: %v", err)
+	}
+	std := process(t, Opts{})[0]
+	res := process(t, Opts{Met: f})[0]
+	if std.Err != nil || res.Err != nil || len(std.Sats) != len(res.Sats) {
+		t.Fatalf("results: %v, %v", std.Err, res.Err)
+	}
+
+	pos := res.Position
+	lat, _, h := coord.XYZToLLH(pos[0], pos[1], pos[2])
+	met := newMetModel(f, 0).met(res.Epoch, h)
+	for i, s := range res.Sats {
+		want := tropo.SlantDelayMet(lat, h, s.El, met)
+		if math.Abs(s.Tropo-want) > 1e-3 || s.Tropo == std.Sats[i].Tropo || math.Abs(s.Tropo/std.Sats[i].Tropo-1) > 0.05 {
+			t.Errorf("%v: get %.4f m, want %.4f m, standard atmosphere %.4f m", s.ID, s.Tropo, want, std.Sats[i].Tropo)
+		}
+	}
+	if e := bancroft.EvalSolution(res.Solution, testSite); e.ThreeD > 100 {
+		t.Errorf("error: %v", e)
+	}
+}
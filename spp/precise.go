@@ -0,0 +1,331 @@
+package spp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/clk"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/sp3"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// ErrNoAntenna is returned for the satellites and the receivers without the
+// antenna models of the frequencies.
+var ErrNoAntenna = errors.New("no antenna model")
+
+// ErrProducts is returned if the precise orbit or clock is not given.
+var ErrProducts = errors.New("missing precise products")
+
+// ErrCodes is returned for the bands of the options which are not those of
+// two frequencies of the system.
+var ErrCodes = errors.New("invalid observation bands")
+
+// DefaultPreciseBands is the bands of the two frequencies for the satellite
+// systems, i.e., those of the ionosphere-free combinations of the precise
+// clocks: L1/L2 of GPS, E1/E5a of Galileo and B1I/B3I of BeiDou.
+var DefaultPreciseBands = map[byte][2]byte{
+	'G': {'1', '2'},
+	'E': {'1', '5'},
+	'C': {'2', '6'},
+}
+
+// PrecisePriority is the priority of the codes of ProcessPrecise overriding
+// rinex.DefaultPriority, i.e., C1W before C1C on L1 of GPS as the codes of
+// the precise clocks.
+var PrecisePriority = rinex.Priority{
+	'G': {'1': "WPYCSLXM"},
+}
+
+// Antenna provides the phase center offsets and variations of the satellite
+// and the receiver antennas, e.g., antex.Collection.
+type Antenna interface {
+	bancroft.SatAntenna
+	bancroft.RcvAntenna
+
+	// RcvPCV returns the phase center variation (m) of the receiver antenna
+	// at the zenith angle (rad).
+	RcvPCV(antType string, sys byte, freq int, zen float64) (float64, error)
+}
+
+// Products is the precise products of ProcessPrecise.
+type Products struct {
+	// Orbit is the precise orbit of the center of mass, and Clock is the
+	// precise satellite clocks.
+	Orbit *sp3.File
+	Clock *clk.File
+
+	// Antenna is the antenna models of the satellites and the receiver
+	// type of the observation file, or nil to solve the position of the
+	// antenna phase center with the satellite positions of the center of
+	// mass, which are off by the satellite PCO of a meter or more.
+	Antenna Antenna
+}
+
+// PreciseOpts is the options of ProcessPrecise.
+type PreciseOpts struct {
+	// System is the satellite system used, i.e., 'G', 'E' or 'C' sharing the
+	// receiver clock. 'G' is used if zero.
+	System byte
+
+	// Bands is the bands of the two frequencies, e.g., {'1', '2'} of L1/L2,
+	// or DefaultPreciseBands of the system if both are zero.
+	Bands [2]byte
+
+	// Priority is the priority of the codes of each band (see
+	// rinex.Epoch.Select), or PrecisePriority if nil.
+	Priority rinex.Priority
+
+	// ElevationMask is the elevation cutoff angle (deg), or zero for no mask.
+	ElevationMask float64
+
+	// Weights is the noise model of the pseudoranges.
+	Weights bancroft.WeightModel
+
+	// NoTropo disables the tropospheric correction.
+	NoTropo bool
+
+	// Mapping is the mapping functions of the tropospheric correction.
+	// tropo.MappingSimple is used if zero.
+	Mapping tropo.Mapping
+
+	// Met is the RINEX meteorological file of the station, whose pressure,
+	// temperature and humidity interpolated at the epochs are used for the
+	// tropospheric correction instead of the standard atmosphere if not nil.
+	// The standard atmosphere is used with a warning at the epochs in the
+	// gaps of the data longer than MetMaxGap, which is 30 min if zero.
+	Met       *rinex.MetFile
+	MetMaxGap time.Duration
+
+	// MaxIter is the maximum number of the iterations of the corrections
+	// depending on the position. 10 is used if zero.
+	MaxIter int
+
+	// Orbit and Clock are the options of the interpolations of the products.
+	Orbit sp3.OrbitOpts
+	Clock clk.ClockOpts
+}
+
+// ProcessPrecise returns the code-based precise point positions of the
+// epochs of the RINEX observation file obsr with the precise products, in
+// the order of the epochs.
+//
+// The pipeline shares the models and the solver of ProcessSPP, with the
+// ionosphere-free combinations of the pseudoranges of the two frequencies
+// instead of the Klobuchar model, the interpolated precise orbits and clocks
+// with the periodic relativistic correction instead of the broadcast
+// ephemerides, and the antenna corrections if prod.Antenna is given: the
+// satellite PCO in the nominal yaw attitude and the PCV at the nadir angle
+// (see bancroft.ApplySatAntenna), and the receiver PCO and PCV at the zenith
+// angle of the antenna type of the header, with the eccentricity "ANTENNA:
+// DELTA H/E/N", i.e., the positions refer to the marker, which are updated by
+// the header lines of the events of the file. The group delays of
+// the codes other than those of the clocks, e.g., C1C instead of C1W of GPS,
+// are not corrected, which bias the positions by a few decimeters at most.
+//
+// The time systems of the observation file and the products must be the
+// same, and the epochs of the results are in the time system. The satellites
+// not used at each epoch are reported in Result.Skipped with the reasons,
+// e.g., sp3.ErrNoOrbit, clk.ErrNoClock or ErrNoAntenna.
+func ProcessPrecise(obsr io.Reader, prod Products, opts PreciseOpts) ([]Result, error) {
+	if opts.System == 0 {
+		opts.System = 'G'
+	}
+	if _, ok := carriers[opts.System]; !ok {
+		return nil, fmt.Errorf("%w: %c", ErrSystem, opts.System)
+	}
+	if opts.Bands == [2]byte{} {
+		opts.Bands = DefaultPreciseBands[opts.System]
+	}
+	if opts.Priority == nil {
+		opts.Priority = PrecisePriority
+	}
+	if opts.MaxIter == 0 {
+		opts.MaxIter = 10
+	}
+	if prod.Orbit == nil || prod.Clock == nil {
+		return nil, fmt.Errorf("%w: orbit=%t, clock=%t", ErrProducts, prod.Orbit != nil, prod.Clock != nil)
+	}
+
+	p := &precise{sys: opts.System, prod: prod, opts: opts}
+	var f [2]float64
+	for k, band := range opts.Bands {
+		var ok bool
+		if f[k], ok = carriers[opts.System][band]; !ok {
+			return nil, fmt.Errorf("%w: unknown band %q", ErrCodes, band)
+		}
+		p.freqs[k] = int(band - '0')
+	}
+	if f[0] == f[1] {
+		return nil, fmt.Errorf("%w: same frequencies: %q", ErrCodes, opts.Bands[:])
+	}
+	p.alpha = f[0] * f[0] / (f[0]*f[0] - f[1]*f[1])
+
+	or, err := rinex.NewObsReader(obsr)
+	if err != nil {
+		return nil, fmt.Errorf("obs: %w", err)
+	}
+	if err := checkTimeSystems(or.Header.TimeSystem, prod.Orbit.Header.TimeSystem, prod.Clock.Header.TimeSystem); err != nil {
+		return nil, err
+	}
+
+	m := model{
+		sys:     opts.System,
+		signal:  p.signal,
+		mask:    opts.ElevationMask,
+		weights: opts.Weights,
+		noTropo: opts.NoTropo,
+		mapping: opts.Mapping,
+		maxIter: opts.MaxIter,
+	}
+	if opts.Met != nil {
+		m.met = newMetModel(opts.Met, opts.MetMaxGap).met
+	}
+	if prod.Antenna != nil {
+		if err := p.setReceiver(or.Header); err != nil {
+			return nil, err
+		}
+		m.antenna = p.antenna
+		m.header = p.setReceiver
+	}
+
+	// the epochs are compared with the products in the common time system
+	return processEpochs(or, "", m)
+}
+
+// checkTimeSystems checks the time systems of the observation file and the
+// orbit and the clock files are the same, where the blank is GPS time.
+func checkTimeSystems(obs, orbit, clock string) error {
+	ts := [3]string{obs, orbit, clock}
+	for k := range ts {
+		if ts[k] == "" {
+			ts[k] = "GPS"
+		}
+	}
+	if ts[0] != ts[1] || ts[0] != ts[2] {
+		return fmt.Errorf("%w: obs=%s, orbit=%s, clock=%s", ErrTimeSystem, ts[0], ts[1], ts[2])
+	}
+	_, err := gpsTime(time.Time{}, ts[0])
+	return err
+}
+
+// precise is the models of ProcessPrecise.
+type precise struct {
+	sys  byte
+	prod Products
+	opts PreciseOpts
+
+	// ANTEX frequency numbers, and the coefficient of the first frequency
+	// of the ionosphere-free combination
+	freqs [2]int
+	alpha float64
+
+	// receiver antenna type, and the offsets (east, north, up, m) of the
+	// phase centers of the frequencies from the marker
+	antType string
+	offset  [2][3]float64
+}
+
+// signal returns the ionosphere-free pseudorange corrected for the precise
+// clock, and the precise position at the transmission time.
+func (p *precise) signal(e *rinex.Epoch, id gnss.SatID, t time.Time) (sig signal, err error) {
+	c1, pr1, err := selectCode(e, id, string(p.opts.Bands[0]), p.opts.Priority)
+	if err != nil {
+		return sig, err
+	}
+	c2, pr2, err := selectCode(e, id, string(p.opts.Bands[1]), p.opts.Priority)
+	if err != nil {
+		return sig, err
+	}
+	sig.id = id
+	sig.code = c1 + "+" + c2
+	sig.pr = p.alpha*pr1 + (1-p.alpha)*pr2
+
+	// the transmission time by the pseudorange and the satellite clock
+	sat := id.String()
+	tx := t.Add(-seconds(sig.pr / lightVelocity))
+	dts, err := p.prod.Clock.Clock(sat, tx, p.opts.Clock)
+	if err != nil {
+		return sig, err
+	}
+	tx = tx.Add(-seconds(dts))
+
+	// the velocity by the central difference of 1 s for the relativistic
+	// correction
+	var pos [3][3]float64
+	for k, dt := range []time.Duration{0, -time.Second / 2, time.Second / 2} {
+		if pos[k], err = p.prod.Orbit.Position(sat, tx.Add(dt), p.opts.Orbit); err != nil {
+			return sig, err
+		}
+	}
+	vel := [3]float64{pos[2][0] - pos[1][0], pos[2][1] - pos[1][1], pos[2][2] - pos[1][2]}
+
+	sig.pos = pos[0]
+	sig.clock = dts*lightVelocity + bancroft.RelativisticCorrection(pos[0], vel)
+	sig.pr += sig.clock
+	return sig, nil
+}
+
+// setReceiver sets the receiver antenna of the header, whose PCO of both
+// frequencies must be given. It is also called for the header lines of the
+// events, e.g., the antenna changed.
+func (p *precise) setReceiver(h rinex.ObsHeader) error {
+	p.antType = h.ANTEXType()
+	for k, f := range p.freqs {
+		neu, err := p.prod.Antenna.RcvPCO(p.antType, p.sys, f)
+		if err != nil {
+			return fmt.Errorf("%w: receiver '%s': %v", ErrNoAntenna, p.antType, err)
+		}
+
+		// PCO (north, east, up) and the eccentricity (height, east, north)
+		p.offset[k] = [3]float64{neu[1] + h.AntDelta[1], neu[0] + h.AntDelta[2], neu[2] + h.AntDelta[0]}
+	}
+	return nil
+}
+
+// antenna returns the satellite data corrected for the satellite antennas
+// by bancroft.ApplySatAntenna, and the pseudoranges for the receiver
+// antenna, of the ionosphere-free combination.
+//
+// The pseudorange to the marker is that to the phase center plus the
+// projection of the offset on the line of sight minus the PCV.
+func (p *precise) antenna(t time.Time, rcv [3]float64, satDatas []bancroft.SatData) (corrected []bancroft.SatData, missing []string, err error) {
+	sun := coord.SunPositionECEF(t)
+	var apc [2][]bancroft.SatData
+	for k, f := range p.freqs {
+		var m []string
+		if apc[k], m, err = bancroft.ApplySatAntenna(satDatas, t, p.prod.Antenna, f, rcv, sun); err != nil {
+			return nil, nil, err
+		}
+		missing = append(missing, m...)
+	}
+
+	w := [2]float64{p.alpha, 1 - p.alpha}
+	corrected = make([]bancroft.SatData, len(satDatas))
+	for i := range satDatas {
+		s1, s2 := apc[0][i], apc[1][i]
+		c := s1
+		c.X, c.Y, c.Z = w[0]*s1.X+w[1]*s2.X, w[0]*s1.Y+w[1]*s2.Y, w[0]*s1.Z+w[1]*s2.Z
+		c.PR = w[0]*s1.PR + w[1]*s2.PR
+
+		enu := coord.ECEFToENU(rcv, [3]float64{c.X, c.Y, c.Z})
+		rho := math.Sqrt(sqr(enu[0]) + sqr(enu[1]) + sqr(enu[2]))
+		for k, f := range p.freqs {
+			pcv, err := p.prod.Antenna.RcvPCV(p.antType, p.sys, f, math.Acos(enu[2]/rho))
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: receiver '%s': %v", ErrNoAntenna, p.antType, err)
+			}
+			d := p.offset[k]
+			corr := (enu[0]*d[0]+enu[1]*d[1]+enu[2]*d[2])/rho - pcv
+			c.PR += w[k] * corr
+		}
+		corrected[i] = c
+	}
+	return corrected, missing, nil
+}
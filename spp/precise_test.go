@@ -0,0 +1,286 @@
+package spp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/clk"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/sp3"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// the precise clocks at 30 s, where G03 misses the clock at 00:01:00
+const testClkFile = "../clk/testdata/synt23230.clk"
+
+var testPreciseSats = []string{"G14", "G04", "G22", "G06", "G17", "G03", "G21", "G19", "G02"}
+
+// readProducts returns the precise clocks and the orbit of the broadcast
+// ephemerides of testNavFile at 15 minutes from 22:00 to 02:00, in their fit
+// intervals.
+func readProducts(t *testing.T) Products {
+	t.Helper()
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	t0 := time.Date(2024, 7, 13, 22, 0, 0, 0, time.UTC)
+	orbit := &sp3.File{
+		Header:  sp3.Header{Start: t0, NumEpochs: 17, Interval: 15 * time.Minute, Sats: testPreciseSats, TimeSystem: "GPS"},
+		Records: map[string][]sp3.Record{},
+	}
+	for k := range orbit.Header.NumEpochs {
+		ep := t0.Add(time.Duration(k) * orbit.Header.Interval)
+		orbit.Epochs = append(orbit.Epochs, ep)
+		for _, id := range testPreciseSats {
+			s, err := brdc.SatellitePosition(id, ep)
+			if err != nil {
+				t.Fatalf("%s %v: %v", id, ep, err)
+			}
+			orbit.Records[id] = append(orbit.Records[id], sp3.Record{Pos: s.Pos, Clock: s.Clock - s.Relativity, HasPos: true, HasClock: true})
+		}
+	}
+
+	clock, err := clk.ReadFile(testClkFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return Products{Orbit: orbit, Clock: clock}
+}
+
+// simulate returns the observation file of the fixture header with the
+// C1C and C2W pseudoranges of the satellites at the epoch, simulated from
+// the products for the receiver clock dtr (s) and the phase center apc,
+// with the tropospheric delays and the ionospheric delays of 5 m on L1.
+func simulate(t *testing.T, prod Products, epoch time.Time, dtr float64, apc [3]float64, header string) string {
+	t.Helper()
+	lat, _, h := coord.XYZToLLH(apc[0], apc[1], apc[2])
+	f1, f2 := carriers['G']['1'], carriers['G']['2']
+
+	var lines []string
+	for _, id := range testPreciseSats {
+		// the travel time tau by the reception time epoch-dtr
+		var tau, dts float64
+		var sat, pos, vel [3]float64
+		for range 5 {
+			tx := epoch.Add(-seconds(dtr + tau))
+			var err error
+			if dts, err = prod.Clock.Clock(id, tx, clk.ClockOpts{MaxGap: time.Minute}); err != nil {
+				t.Fatalf("%s: %v", id, err)
+			}
+			pos, _ = prod.Orbit.Position(id, tx, sp3.OrbitOpts{})
+			p0, _ := prod.Orbit.Position(id, tx.Add(-time.Second/2), sp3.OrbitOpts{})
+			p1, _ := prod.Orbit.Position(id, tx.Add(time.Second/2), sp3.OrbitOpts{})
+			vel = [3]float64{p1[0] - p0[0], p1[1] - p0[1], p1[2] - p0[2]}
+			sat[0], sat[1], sat[2] = bancroft.RotateSatPos(pos[0], pos[1], pos[2], tau)
+			tau = math.Sqrt(sqr(sat[0]-apc[0])+sqr(sat[1]-apc[1])+sqr(sat[2]-apc[2])) / lightVelocity
+		}
+		_, el := coord.AzEl(apc, sat)
+		pr := (tau+dtr-dts)*lightVelocity - bancroft.RelativisticCorrection(pos, vel) + tropo.SlantDelay(lat, h, el)
+		i1 := 5. / math.Sin(el)
+		lines = append(lines, fmt.Sprintf("%s%14.3f  %48s%14.3f  ", id, pr+i1, "", pr+i1*f1*f1/(f2*f2)))
+	}
+	return header + fmt.Sprintf("> %s  0%3d\n", epoch.Format("2006 01 02 15 04 05.0000000"), len(lines)) + strings.Join(lines, "\n") + "\n"
+}
+
+// testHeader returns the header of the observation fixture with the
+// replacements of the pairs of old and new strings.
+func testHeader(t *testing.T, oldnew ...string) string {
+	t.Helper()
+	b, err := os.ReadFile(testObsFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	header := string(b[:strings.Index(string(b), "END OF HEADER")]) + "END OF HEADER\n"
+	return strings.NewReplacer(oldnew...).Replace(header)
+}
+
+// testAntenna is an Antenna of the up offset (m) of the receiver antenna
+// type of the fixture of the frequencies 1 and 2, and the satellites of zero
+// offsets except those of the missing set.
+type testAntenna struct {
+	up      [2]float64
+	missing map[gnss.SatID]bool
+}
+
+func (a testAntenna) SatPCO(id gnss.SatID, t time.Time, freq int) ([3]float64, error) {
+	if a.missing[id] {
+		return [3]float64{}, errors.New("not found")
+	}
+	return [3]float64{}, nil
+}
+
+func (a testAntenna) SatPCV(id gnss.SatID, t time.Time, freq int, nadir float64) (float64, error) {
+	return 0, nil
+}
+
+func (a testAntenna) RcvPCO(antType string, sys byte, freq int) ([3]float64, error) {
+	if antType != "TRM59800.80     GSI" || freq < 1 || freq > 2 {
+		return [3]float64{}, errors.New("not found")
+	}
+	return [3]float64{0, 0, a.up[freq-1]}, nil
+}
+
+func (a testAntenna) RcvPCV(antType string, sys byte, freq int, zen float64) (float64, error) {
+	return 0, nil
+}
+
+// TestProcessPrecise checks the position and the clock of the pseudoranges
+// simulated from the products, which are recovered within 1 cm, i.e., the
+// rounding of the pseudoranges to 1 mm amplified by the ionosphere-free
+// combination and the DOP, and the satellite without the clock at the epoch.
+func TestProcessPrecise(t *testing.T) {
+	prod := readProducts(t)
+	epoch := time.Date(2024, 7, 14, 0, 1, 0, 0, time.UTC)
+	const dtr = 1e-4
+	obs := simulate(t, prod, epoch, dtr, testSite, testHeader(t))
+
+	res, err := ProcessPrecise(strings.NewReader(obs), prod, PreciseOpts{})
+	if err != nil {
+		t.Fatalf("ProcessPrecise: %v", err)
+	}
+	if len(res) != 1 || res[0].Err != nil {
+		t.Fatalf("results: %+v", res)
+	}
+	r := res[0]
+	for k := range 3 {
+		if math.Abs(r.Position[k]-testSite[k]) > 1e-2 {
+			t.Errorf("position: get %v, want %v", r.Position, testSite)
+			break
+		}
+	}
+	if math.Abs(r.ClockBias+dtr) > 3e-11 || r.RMS > 1e-2 {
+		t.Errorf("clock: get %e, want %e, RMS=%e", r.ClockBias, -dtr, r.RMS)
+	}
+	if r.NumSats != 8 || len(r.Skipped) != 1 || r.Skipped[0].ID.String() != "G03" || !errors.Is(r.Skipped[0].Err, clk.ErrNoClock) {
+		t.Errorf("sats=%d, skipped=%+v", r.NumSats, r.Skipped)
+	}
+	for _, s := range r.Sats {
+		if s.Code != "C1C+C2W" || s.Iono != 0 || s.Tropo <= 0 {
+			t.Errorf("%+v", s)
+		}
+	}
+}
+
+// TestProcessPreciseAntenna checks the receiver PCO of the frequencies and
+// the eccentricity of the header, and the satellite without the antenna.
+func TestProcessPreciseAntenna(t *testing.T) {
+	prod := readProducts(t)
+	epoch := time.Date(2024, 7, 14, 0, 1, 0, 0, time.UTC)
+
+	// the ionosphere-free PCO of 0.1 m and 0.2 m of L1 and L2 is
+	// 2.5457*0.1 - 1.5457*0.2 = -0.0546 m, and the eccentricity is 0.5 m
+	prod.Antenna = testAntenna{up: [2]float64{0.1, 0.2}, missing: map[gnss.SatID]bool{{Sys: gnss.GPS, PRN: 22}: true}}
+	apc := coord.ENUToECEF(testSite, [3]float64{0, 0, 0.5 - 0.0546})
+	header := testHeader(t, "        0.0000        0.0000        0.0000                  ANTENNA: DELTA H/E/N",
+		"        0.5000        0.0000        0.0000                  ANTENNA: DELTA H/E/N")
+
+	for _, tt := range []struct {
+		ant  Antenna
+		want [3]float64
+	}{
+		{nil, apc},
+		{prod.Antenna, testSite},
+	} {
+		prod.Antenna = tt.ant
+		obs := simulate(t, prod, epoch, 0, apc, header)
+		res, err := ProcessPrecise(strings.NewReader(obs), prod, PreciseOpts{})
+		if err != nil {
+			t.Fatalf("ProcessPrecise: %v", err)
+		}
+		r := res[0]
+		if r.Err != nil {
+			t.Fatalf("%v", r.Err)
+		}
+		enu := coord.ECEFToENU(tt.want, r.Position)
+		if math.Abs(enu[0]) > 1e-2 || math.Abs(enu[1]) > 1e-2 || math.Abs(enu[2]) > 1e-2 {
+			t.Errorf("antenna=%v: ENU=%v", tt.ant != nil, enu)
+		}
+
+		var n int
+		for _, s := range r.Skipped {
+			if errors.Is(s.Err, ErrNoAntenna) {
+				n++
+			}
+		}
+		if tt.ant != nil && (n != 1 || r.NumSats != 7) {
+			t.Errorf("sats=%d, skipped=%+v", r.NumSats, r.Skipped)
+		}
+	}
+
+	// the receiver antenna type not in the model, and that changed by an
+	// event after the first epoch
+	prod.Antenna = testAntenna{}
+	obs := testHeader(t, "TRM59800.80     GSI", "TRM57971.00     GSI")
+	if _, err := ProcessPrecise(strings.NewReader(obs), prod, PreciseOpts{}); !errors.Is(err, ErrNoAntenna) {
+		t.Errorf("get err=%v, want %v", err, ErrNoAntenna)
+	}
+	obs = simulate(t, prod, epoch, 0, apc, header) +
+		">                              4  1\n" +
+		"1441041254          TRM57971.00     NONE                    ANT # / TYPE\n"
+	res, err := ProcessPrecise(strings.NewReader(obs), prod, PreciseOpts{})
+	if len(res) != 1 || !errors.Is(err, ErrNoAntenna) {
+		t.Errorf("get %d results, err=%v, want %v", len(res), err, ErrNoAntenna)
+	}
+}
+
+// TestProcessPreciseEpochs checks the satellites of the fixture at 00:00,
+// whose transmission times are before the first clocks.
+func TestProcessPreciseEpochs(t *testing.T) {
+	obs, err := os.Open(testObsFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer obs.Close()
+	res, err := ProcessPrecise(obs, readProducts(t), PreciseOpts{})
+	if err != nil {
+		t.Fatalf("ProcessPrecise: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("get %d epochs, want 2", len(res))
+	}
+	r := res[0]
+	if !errors.Is(r.Err, bancroft.ErrTooFewSats) || len(r.Skipped) != 9 {
+		t.Errorf("err=%v, skipped=%+v", r.Err, r.Skipped)
+	}
+	for _, s := range r.Skipped {
+		if !errors.Is(s.Err, clk.ErrNoClock) {
+			t.Errorf("%+v", s)
+		}
+	}
+}
+
+// TestProcessPreciseErrors checks the errors of the options and the time
+// systems.
+func TestProcessPreciseErrors(t *testing.T) {
+	prod := readProducts(t)
+	for _, tt := range []struct {
+		bands [2]byte
+		want  error
+	}{
+		{[2]byte{'1', '1'}, ErrCodes},
+		{[2]byte{'1', 0}, ErrCodes},
+		{[2]byte{'1', '6'}, ErrCodes},
+	} {
+		if _, err := ProcessPrecise(strings.NewReader(""), prod, PreciseOpts{Bands: tt.bands}); !errors.Is(err, tt.want) {
+			t.Errorf("%q: get err=%v, want %v", tt.bands[:], err, tt.want)
+		}
+	}
+
+	if _, err := ProcessPrecise(strings.NewReader(""), Products{Orbit: prod.Orbit}, PreciseOpts{}); !errors.Is(err, ErrProducts) {
+		t.Errorf("get err=%v, want %v", err, ErrProducts)
+	}
+
+	header := testHeader(t, "     GPS         TIME OF FIRST OBS", "     GAL         TIME OF FIRST OBS")
+	if _, err := ProcessPrecise(strings.NewReader(header), prod, PreciseOpts{}); !errors.Is(err, ErrTimeSystem) {
+		t.Errorf("get err=%v, want %v", err, ErrTimeSystem)
+	}
+}
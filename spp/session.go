@@ -0,0 +1,343 @@
+package spp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/iono"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/rinex"
+)
+
+// ErrSessionClosed is returned by Session.Push after the session is shut
+// down.
+var ErrSessionClosed = errors.New("session closed")
+
+// ErrStaleCorrection is returned for the satellites whose DGNSS corrections
+// are older than SessionOpts.MaxCorrectionAge.
+var ErrStaleCorrection = errors.New("stale dgnss correction")
+
+// DefaultSessionBuffer is the size of the buffers of the epochs and the
+// results of Session if not given.
+const DefaultSessionBuffer = 16
+
+// ephemerisRetention is the period of the ephemerides of a satellite retained
+// by Session before the latest one.
+const ephemerisRetention = 24 * time.Hour
+
+// DropPolicy is the policy of Session on the results not received by the
+// consumer when the buffer is full.
+type DropPolicy int
+
+// The drop policies.
+const (
+	DropOldest DropPolicy = iota // discard the oldest result buffered
+	DropNewest                   // discard the new result
+	Block                        // wait for the consumer, blocking Push
+)
+
+// SessionOpts is the options of NewSession.
+type SessionOpts struct {
+	Opts
+
+	// Buffer is the size of the buffers of the epochs pushed and the
+	// results, or DefaultSessionBuffer if zero, and Drop is the policy when
+	// the buffer of the results is full.
+	Buffer int
+	Drop   DropPolicy
+
+	// MaxCorrectionAge is the maximum age of the DGNSS corrections, or
+	// bancroft.DefaultMaxCorrectionAge if zero.
+	MaxCorrectionAge time.Duration
+}
+
+// Session computes the single point positions of the epochs pushed in real
+// time, e.g., those decoded by rtcm3.Decoder or ubx.Decoder, with the
+// ephemerides and the DGNSS corrections updated while running.
+//
+// The epochs are processed in the order of Push by a goroutine, and the
+// results are sent to Results. The updates of the ephemerides and the
+// corrections are safe concurrently with Push, and are used from the epochs
+// processed after the updates.
+type Session struct {
+	opts SessionOpts
+	base model
+	ctx  context.Context
+
+	in      chan *rinex.Epoch
+	out     chan Result
+	dropped atomic.Int64
+
+	// mu serializes the updates of state, which is replaced by the copies
+	// updated so that the epochs in process read the state without locks.
+	mu    sync.Mutex
+	state atomic.Pointer[sessionState]
+}
+
+// sessionState is the ephemerides and the corrections of a session.
+type sessionState struct {
+	brdc        *nav.File
+	corrections map[gnss.SatID]bancroft.PRCorrection
+}
+
+// NewSession returns the session processing the epochs until ctx is done,
+// without the ephemerides, i.e., the satellites are skipped by
+// nav.ErrNoEphemeris until UpdateNav or AddEphemeris. The options are those
+// of ProcessSPP except Met, which is interpolated as well.
+func NewSession(ctx context.Context, opts SessionOpts) (*Session, error) {
+	var err error
+	if opts.Opts, err = opts.Opts.withDefaults(); err != nil {
+		return nil, err
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultSessionBuffer
+	}
+
+	s := &Session{
+		opts: opts,
+		ctx:  ctx,
+		in:   make(chan *rinex.Epoch, opts.Buffer),
+		out:  make(chan Result, opts.Buffer),
+		base: model{
+			sys:     opts.System,
+			mask:    opts.ElevationMask,
+			weights: opts.Weights,
+			noTropo: opts.NoTropo,
+			mapping: opts.Mapping,
+			maxIter: opts.MaxIter,
+		},
+	}
+	if opts.Met != nil {
+		s.base.met = newMetModel(opts.Met, opts.MetMaxGap).met
+	}
+	s.state.Store(&sessionState{
+		brdc: &nav.File{Ephs: map[string][]nav.Ephemeris{}, Glo: map[string][]nav.GloEphemeris{}},
+	})
+	go s.run()
+	return s, nil
+}
+
+// Push queues the epoch of the observations in GPS time, waiting for the
+// buffer while ctx is not done. The epochs of the events are skipped.
+// ErrSessionClosed is returned after the session is shut down, and the error
+// of ctx if ctx is done first.
+func (s *Session) Push(ctx context.Context, e *rinex.Epoch) error {
+	if s.ctx.Err() != nil {
+		return ErrSessionClosed
+	}
+	select {
+	case s.in <- e:
+		return nil
+	case <-s.ctx.Done():
+		return ErrSessionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Results returns the channel of the results in the order of the epochs,
+// which is closed after the session is shut down. The results buffered are
+// received after the close.
+func (s *Session) Results() <-chan Result {
+	return s.out
+}
+
+// Dropped returns the number of the results dropped by the policy.
+func (s *Session) Dropped() int {
+	return int(s.dropped.Load())
+}
+
+// UpdateNav adds the ephemerides of the navigation file as AddEphemeris, and
+// replaces the ionospheric coefficients if given.
+func (s *Session) UpdateNav(f *nav.File) {
+	s.update(func(st *sessionState) {
+		if f.Header.HasIono {
+			st.brdc.Header.Iono, st.brdc.Header.HasIono = f.Header.Iono, true
+		}
+		for _, ephs := range f.Ephs {
+			for k := range ephs {
+				addEphemeris(st.brdc, &ephs[k])
+			}
+		}
+		for _, ephs := range f.Glo {
+			for k := range ephs {
+				addEphemeris(st.brdc, &ephs[k])
+			}
+		}
+	})
+}
+
+// AddEphemeris adds the ephemeris, e.g., decoded from the navigation
+// messages, which replaces that of the same epoch and issue of data. The
+// ephemerides of a satellite older than a day before the latest one are
+// removed.
+func (s *Session) AddEphemeris(eph nav.Broadcast) {
+	s.update(func(st *sessionState) {
+		addEphemeris(st.brdc, eph)
+	})
+}
+
+// UpdateCorrections adds the DGNSS corrections, which replace those of the
+// same satellites older. The corrected pseudoranges are used without the
+// ionospheric and the tropospheric models, and the satellites of the
+// corrections older than MaxCorrectionAge are skipped by ErrStaleCorrection.
+func (s *Session) UpdateCorrections(cs bancroft.CorrectionSet) {
+	s.update(func(st *sessionState) {
+		for _, c := range cs {
+			if old, ok := st.corrections[c.ID]; !ok || c.T0.After(old.T0) {
+				st.corrections[c.ID] = c
+			}
+		}
+	})
+}
+
+// update replaces the state by the copy updated by f.
+func (s *Session) update(f func(st *sessionState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.state.Load()
+	brdc := *old.brdc
+	brdc.Ephs = maps.Clone(old.brdc.Ephs)
+	brdc.Glo = maps.Clone(old.brdc.Glo)
+	st := &sessionState{brdc: &brdc, corrections: maps.Clone(old.corrections)}
+	if st.corrections == nil {
+		st.corrections = map[gnss.SatID]bancroft.PRCorrection{}
+	}
+	f(st)
+	s.state.Store(st)
+}
+
+// addEphemeris adds the ephemeris to the file in the order of the epochs. The
+// slices of the file are replaced rather than modified, which may be shared
+// with the previous states.
+func addEphemeris(f *nav.File, eph nav.Broadcast) {
+	switch e := eph.(type) {
+	case *nav.Ephemeris:
+		f.Ephs[e.ID] = insertEphemeris(f.Ephs[e.ID], *e, func(a, b *nav.Ephemeris) bool {
+			return a.TOE().Equal(b.TOE()) && a.IODE == b.IODE
+		})
+	case *nav.GloEphemeris:
+		f.Glo[e.ID] = insertEphemeris(f.Glo[e.ID], *e, func(a, b *nav.GloEphemeris) bool {
+			return a.TOE().Equal(b.TOE())
+		})
+	}
+}
+
+// insertEphemeris returns the copy of the ephemerides in the order of the
+// epochs with eph inserted or replacing the same one, without those older than
+// ephemerisRetention before the latest one.
+func insertEphemeris[E any, P interface {
+	*E
+	nav.Broadcast
+}](ephs []E, eph E, same func(a, b P) bool) []E {
+	out := make([]E, 0, len(ephs)+1)
+	for k := range ephs {
+		if !same(&ephs[k], &eph) {
+			out = append(out, ephs[k])
+		}
+	}
+	out = append(out, eph)
+	slices.SortStableFunc(out, func(a, b E) int {
+		return P(&a).TOE().Compare(P(&b).TOE())
+	})
+	latest := P(&out[len(out)-1]).TOE()
+	return slices.DeleteFunc(out, func(e E) bool {
+		return latest.Sub(P(&e).TOE()) > ephemerisRetention
+	})
+}
+
+// run processes the epochs pushed until the session is shut down.
+func (s *Session) run() {
+	defer close(s.out)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case e := <-s.in:
+			if e.Flag > rinex.FlagPowerFailure {
+				continue
+			}
+			s.send(processEpoch(e, e.Time, s.model()))
+		}
+	}
+}
+
+// send sends the result by the drop policy.
+func (s *Session) send(r Result) {
+	switch s.opts.Drop {
+	case Block:
+		select {
+		case s.out <- r:
+		case <-s.ctx.Done():
+		}
+		return
+	case DropNewest:
+		select {
+		case s.out <- r:
+		default:
+			s.dropped.Add(1)
+		}
+		return
+	}
+	for {
+		select {
+		case s.out <- r:
+			return
+		default:
+		}
+		select {
+		case <-s.out:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// model returns the model of the current state.
+func (s *Session) model() model {
+	st := s.state.Load()
+	opts := s.opts
+	m := s.base
+	m.signal = func(e *rinex.Epoch, id gnss.SatID, t time.Time) (signal, error) {
+		sig, err := satSignal(e, id, t, st.brdc, opts.Opts)
+		if err != nil {
+			return sig, err
+		}
+		return st.correct(sig, t, opts.MaxCorrectionAge)
+	}
+	if !opts.NoIono && st.brdc.Header.HasIono {
+		m.iono = func(t time.Time, lat, lon, az, el float64) float64 {
+			return iono.Klobuchar(st.brdc.Header.Iono, t, lat, lon, az, el)
+		}
+	}
+	return m
+}
+
+// correct returns the signal corrected by the DGNSS correction of the
+// satellite at the time t, or the signal as is without the correction.
+func (st *sessionState) correct(sig signal, t time.Time, maxAge time.Duration) (signal, error) {
+	c, ok := st.corrections[sig.id]
+	if !ok {
+		return sig, nil
+	}
+	corrected, n, err := bancroft.CorrectionSet{c}.Apply([]bancroft.SatData{{PR: sig.pr, ID: sig.id}}, t, maxAge)
+	if err != nil {
+		return sig, err
+	}
+	if n == 0 {
+		return sig, fmt.Errorf("%w: %v of %v", ErrStaleCorrection, sig.id, c.T0)
+	}
+	sig.pr = corrected[0].PR
+	sig.scale = 0
+	sig.dgnss = true
+	return sig, nil
+}
@@ -0,0 +1,319 @@
+package spp
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/rinex"
+)
+
+// testEpochs returns the epochs of the observation fixture.
+func testEpochs(t *testing.T) []*rinex.Epoch {
+	t.Helper()
+	f, err := os.Open(testObsFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	or, err := rinex.NewObsReader(f)
+	if err != nil {
+		t.Fatalf("NewObsReader: %v", err)
+	}
+	var epochs []*rinex.Epoch
+	for {
+		e, err := or.Next()
+		if err != nil {
+			return epochs
+		}
+		epochs = append(epochs, e)
+	}
+}
+
+// testSession returns the session of the options with the ephemerides of
+// the navigation fixture, which is shut down at the end of the test.
+func testSession(t *testing.T, opts SessionOpts) (*Session, context.CancelFunc) {
+	t.Helper()
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s, err := NewSession(ctx, opts)
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	s.UpdateNav(brdc)
+	return s, cancel
+}
+
+// shifted returns the copies of the epoch shifted by the seconds.
+func shifted(e *rinex.Epoch, n int) []*rinex.Epoch {
+	epochs := make([]*rinex.Epoch, n)
+	for k := range epochs {
+		c := *e
+		c.Time = e.Time.Add(time.Duration(k) * time.Second)
+		epochs[k] = &c
+	}
+	return epochs
+}
+
+// TestSession checks the results of the epochs pushed are those of
+// ProcessSPP.
+func TestSession(t *testing.T) {
+	want := process(t, Opts{ElevationMask: 10})
+	s, _ := testSession(t, SessionOpts{Opts: Opts{ElevationMask: 10}})
+
+	ctx := context.Background()
+	for _, e := range testEpochs(t) {
+		if err := s.Push(ctx, e); err != nil {
+			t.Fatalf("Push: %v", err)
+		}
+	}
+	for k, w := range want {
+		r := <-s.Results()
+		if !r.Epoch.Equal(w.Epoch) || r.Position != w.Position || r.ClockBias != w.ClockBias || len(r.Sats) != len(w.Sats) {
+			t.Errorf("%d: get %v %v, want %v %v", k, r.Epoch, r.Position, w.Epoch, w.Position)
+		}
+		if (r.Err == nil) != (w.Err == nil) {
+			t.Errorf("%d: get err=%v, want %v", k, r.Err, w.Err)
+		}
+	}
+}
+
+// TestSessionNoEphemeris checks the satellites are skipped until the
+// ephemerides are given.
+func TestSessionNoEphemeris(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := NewSession(ctx, SessionOpts{})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if err := s.Push(ctx, testEpochs(t)[0]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	r := <-s.Results()
+	if !errors.Is(r.Err, bancroft.ErrTooFewSats) || len(r.Skipped) != 9 {
+		t.Fatalf("get err=%v, skipped=%+v", r.Err, r.Skipped)
+	}
+	for _, sk := range r.Skipped {
+		if !errors.Is(sk.Err, nav.ErrNoEphemeris) {
+			t.Errorf("%+v", sk)
+		}
+	}
+
+	if _, err := NewSession(ctx, SessionOpts{Opts: Opts{System: 'R'}}); !errors.Is(err, ErrSystem) {
+		t.Errorf("get err=%v, want %v", err, ErrSystem)
+	}
+}
+
+// TestSessionConcurrent checks the updates of the ephemerides and the
+// corrections concurrent with the epochs, e.g., by go test -race.
+func TestSessionConcurrent(t *testing.T) {
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	s, _ := testSession(t, SessionOpts{Drop: Block})
+	epochs := shifted(testEpochs(t)[0], 50)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for k := range epochs {
+			for _, ephs := range brdc.Ephs {
+				s.AddEphemeris(&ephs[k%len(ephs)])
+			}
+			s.UpdateCorrections(nil)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, e := range epochs {
+			if err := s.Push(context.Background(), e); err != nil {
+				t.Errorf("Push: %v", err)
+			}
+		}
+	}()
+
+	for k, e := range epochs {
+		r := <-s.Results()
+		if !r.Epoch.Equal(e.Time) || r.Err != nil {
+			t.Errorf("%d: %v: %v", k, r.Epoch, r.Err)
+		}
+	}
+	wg.Wait()
+	if n := len(s.state.Load().brdc.Ephs["G14"]); n != len(brdc.Ephs["G14"]) {
+		t.Errorf("G14: get %d ephemerides, want %d", n, len(brdc.Ephs["G14"]))
+	}
+}
+
+// TestSessionDrop checks the results kept and dropped by the policies when
+// the results are not received.
+func TestSessionDrop(t *testing.T) {
+	const n = 5
+	for _, tt := range []struct {
+		drop DropPolicy
+		kept int // index of the epoch kept
+	}{
+		{DropNewest, 0},
+		{DropOldest, n - 1},
+	} {
+		s, cancel := testSession(t, SessionOpts{Buffer: 1, Drop: tt.drop})
+		epochs := shifted(testEpochs(t)[0], n)
+		for _, e := range epochs {
+			if err := s.Push(context.Background(), e); err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+		}
+		for start := time.Now(); s.Dropped() < n-1; time.Sleep(time.Millisecond) {
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("%v: dropped %d", tt.drop, s.Dropped())
+			}
+		}
+		cancel()
+
+		var got []Result
+		for r := range s.Results() {
+			got = append(got, r)
+		}
+		if len(got) != 1 || !got[0].Epoch.Equal(epochs[tt.kept].Time) || s.Dropped() != n-1 {
+			t.Errorf("%v: get %d results, dropped %d", tt.drop, len(got), s.Dropped())
+		}
+	}
+}
+
+// TestSessionBlock checks no result is dropped by Block.
+func TestSessionBlock(t *testing.T) {
+	s, _ := testSession(t, SessionOpts{Buffer: 1, Drop: Block})
+	epochs := shifted(testEpochs(t)[0], 10)
+	go func() {
+		for _, e := range epochs {
+			s.Push(context.Background(), e)
+		}
+	}()
+	for _, e := range epochs {
+		time.Sleep(time.Millisecond)
+		if r := <-s.Results(); !r.Epoch.Equal(e.Time) {
+			t.Errorf("get %v, want %v", r.Epoch, e.Time)
+		}
+	}
+	if s.Dropped() != 0 {
+		t.Errorf("dropped: get %d, want 0", s.Dropped())
+	}
+}
+
+// TestSessionCorrections checks the DGNSS corrections, where a correction
+// common to the satellites shifts the clock only, and the stale corrections
+// skip the satellites.
+func TestSessionCorrections(t *testing.T) {
+	want := process(t, Opts{NoIono: true, NoTropo: true})[0]
+	e := testEpochs(t)[0]
+
+	s, _ := testSession(t, SessionOpts{})
+	var cs bancroft.CorrectionSet
+	for _, sat := range want.Sats {
+		cs = append(cs, bancroft.PRCorrection{ID: sat.ID, T0: e.Time.Add(-10 * time.Second), PRC: 10, RRC: 0})
+	}
+	s.UpdateCorrections(cs)
+	if err := s.Push(context.Background(), e); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	r := <-s.Results()
+	if r.Err != nil || len(r.Sats) != len(want.Sats) {
+		t.Fatalf("get err=%v, sats=%+v", r.Err, r.Sats)
+	}
+	for k := range 3 {
+		if math.Abs(r.Position[k]-want.Position[k]) > 1e-3 {
+			t.Errorf("position: get %v, want %v", r.Position, want.Position)
+			break
+		}
+	}
+	if d := r.ClockBias - want.ClockBias; math.Abs(d+10/lightVelocity) > 1e-12 {
+		t.Errorf("clock: get %e, want %e", d, -10/lightVelocity)
+	}
+	for _, sat := range r.Sats {
+		if sat.Iono != 0 || sat.Tropo != 0 {
+			t.Errorf("%+v", sat)
+		}
+	}
+
+	// the older corrections are ignored, and those of the first epoch are
+	// stale after 30 s
+	for k := range cs {
+		cs[k].T0 = e.Time.Add(-time.Minute)
+		cs[k].PRC = 20
+	}
+	s.UpdateCorrections(cs)
+	if err := s.Push(context.Background(), shifted(e, 31)[30]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	r = <-s.Results()
+	if !errors.Is(r.Err, bancroft.ErrTooFewSats) {
+		t.Errorf("get err=%v, want %v", r.Err, bancroft.ErrTooFewSats)
+	}
+	var n int
+	for _, sk := range r.Skipped {
+		if errors.Is(sk.Err, ErrStaleCorrection) {
+			n++
+		}
+	}
+	if n != len(cs) {
+		t.Errorf("skipped: %+v", r.Skipped)
+	}
+}
+
+// TestSessionClose checks the shutdown by the context.
+func TestSessionClose(t *testing.T) {
+	s, cancel := testSession(t, SessionOpts{})
+	cancel()
+	if _, ok := <-s.Results(); ok {
+		t.Errorf("results not closed")
+	}
+	if err := s.Push(context.Background(), testEpochs(t)[0]); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("get err=%v, want %v", err, ErrSessionClosed)
+	}
+}
+
+// TestInsertEphemeris checks the ephemerides replaced and removed.
+func TestInsertEphemeris(t *testing.T) {
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	ephs := brdc.Ephs["G14"]
+	f := &nav.File{Ephs: map[string][]nav.Ephemeris{}}
+	for k := len(ephs) - 1; k >= 0; k-- {
+		addEphemeris(f, &ephs[k])
+		addEphemeris(f, &ephs[k])
+	}
+	got := f.Ephs["G14"]
+	if len(got) != len(ephs) {
+		t.Fatalf("get %d ephemerides, want %d", len(got), len(ephs))
+	}
+	for k := range got {
+		if !got[k].TOE().Equal(ephs[k].TOE()) {
+			t.Errorf("%d: get %v, want %v", k, got[k].TOE(), ephs[k].TOE())
+		}
+	}
+
+	later := ephs[len(ephs)-1]
+	later.Week += 1
+	later.IODE++
+	addEphemeris(f, &later)
+	if got := f.Ephs["G14"]; len(got) != 1 || got[0].IODE != later.IODE {
+		t.Errorf("get %+v", got)
+	}
+	if len(ephs) != len(brdc.Ephs["G14"]) || ephs[0].IODE == later.IODE {
+		t.Errorf("source modified")
+	}
+}
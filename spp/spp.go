@@ -0,0 +1,268 @@
+/*
+Package spp computes the single point positions of the epochs of a RINEX
+observation file with the broadcast ephemerides of a RINEX navigation file.
+
+The pseudoranges of a satellite system are corrected for the broadcast
+satellite clocks and group delays, the Klobuchar ionospheric model of the
+navigation header and the Saastamoinen tropospheric model of the standard
+atmosphere or the meteorological file (Opts.Met) with the mapping functions
+of Opts.Mapping, and the satellite positions are computed at the
+transmission times and rotated with the Earth during the signal travel
+times. Each epoch is solved by bancroft.SolveLSQ, i.e., the Bancroft
+solution refined by the least squares weighted by the elevations.
+
+ProcessPrecise computes the code-based precise point positions with the
+same models and solver, the ionosphere-free pseudoranges of two frequencies,
+the precise orbits and clocks of SP3 and RINEX clock files and the antenna
+models of ANTEX.
+
+Session computes the single point positions of the epochs pushed in real
+time, e.g., decoded from the streams of ntrip.Client, with the ephemerides
+and the DGNSS corrections updated concurrently, and sends the results to a
+channel of the bounded buffer.
+*/
+package spp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/iono"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+var logger = log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)
+
+// lightVelocity is the speed of light (m/s).
+const lightVelocity = 299792458.
+
+// ErrNoSignal is returned for the satellites without the pseudoranges of the
+// bands of Opts.Bands.
+var ErrNoSignal = errors.New("no pseudorange of the bands")
+
+// ErrBelowMask is returned for the satellites below the elevation mask.
+var ErrBelowMask = errors.New("below the elevation mask")
+
+// ErrTimeSystem is returned for the observation files of the unsupported
+// time systems.
+var ErrTimeSystem = errors.New("unsupported time system")
+
+// ErrSystem is returned for the unsupported satellite systems.
+var ErrSystem = errors.New("unsupported satellite system")
+
+// DefaultBands is the bands of the pseudoranges in the order of the priority
+// for the satellite systems, i.e., L1 of GPS, E1 of Galileo and B1I or B3I of
+// BeiDou.
+var DefaultBands = map[byte]string{
+	'G': "1",
+	'E': "1",
+	'C': "26",
+}
+
+// carriers is the carrier frequencies (Hz) of the bands, i.e., the second
+// characters of the observation codes.
+var carriers = map[byte]map[byte]float64{
+	'G': {'1': 1575.42e6, '2': 1227.60e6, '5': 1176.45e6},
+	'E': {'1': 1575.42e6, '5': 1176.45e6, '7': 1207.14e6},
+	'C': {'2': 1561.098e6, '6': 1268.52e6, '7': 1207.14e6},
+}
+
+// Opts is the options of ProcessSPP.
+type Opts struct {
+	// System is the satellite system used, i.e., 'G', 'E' or 'C' sharing the
+	// receiver clock. 'G' is used if zero.
+	System byte
+
+	// Bands is the bands of the pseudoranges (e.g., "1" of L1) in the order
+	// of the priority, i.e., the first band observed is used for each
+	// satellite. DefaultBands of the system is used if empty. The bands must
+	// be those of the broadcast group delays (see nav.Ephemeris.GroupDelay).
+	Bands string
+
+	// Priority is the priority of the codes of each band (see
+	// rinex.Epoch.Select), or rinex.DefaultPriority if nil.
+	Priority rinex.Priority
+
+	// ElevationMask is the elevation cutoff angle (deg), or zero for no mask.
+	ElevationMask float64
+
+	// Select is the criteria of the usable ephemerides.
+	Select nav.SelectOpts
+
+	// Weights is the noise model of the pseudoranges.
+	Weights bancroft.WeightModel
+
+	// NoIono and NoTropo disable the ionospheric and the tropospheric
+	// corrections.
+	NoIono, NoTropo bool
+
+	// Mapping is the mapping functions of the tropospheric correction.
+	// tropo.MappingSimple is used if zero.
+	Mapping tropo.Mapping
+
+	// Met is the RINEX meteorological file of the station, whose pressure,
+	// temperature and humidity interpolated at the epochs are used for the
+	// tropospheric correction instead of the standard atmosphere if not nil.
+	// The standard atmosphere is used with a warning at the epochs in the
+	// gaps of the data longer than MetMaxGap, which is 30 min if zero.
+	Met       *rinex.MetFile
+	MetMaxGap time.Duration
+
+	// MaxIter is the maximum number of the iterations of the corrections
+	// depending on the position. 10 is used if zero.
+	MaxIter int
+}
+
+// SatInfo is the diagnostics of a satellite used in the solution.
+type SatInfo struct {
+	ID   gnss.SatID
+	Code string // observation code of the pseudorange
+
+	// Az and El are the azimuth and the elevation angles (rad) seen from the
+	// solution.
+	Az, El float64
+
+	// Clock is the satellite clock correction (m) including the group delay
+	// added to the pseudorange, and Iono and Tropo are the delays (m)
+	// subtracted from it.
+	Clock, Iono, Tropo float64
+}
+
+// Skip is a satellite not used in the solution and the reason.
+type Skip struct {
+	ID  gnss.SatID
+	Err error
+}
+
+// Result is the solution of an epoch with the diagnostics. The Err of the
+// Solution is that of the epoch, e.g., bancroft.ErrTooFewSats, and the other
+// fields of the Solution are invalid if not nil.
+type Result struct {
+	bancroft.Solution
+
+	// Sats is the satellites used in the order of Solution.SatIDs, and
+	// Skipped is the satellites of the system not used.
+	Sats    []SatInfo
+	Skipped []Skip
+
+	// Iterations is the number of the iterations of the corrections.
+	Iterations int
+}
+
+// ProcessSPP returns the single point positions of the epochs of the RINEX
+// observation file obsr with the broadcast ephemerides of the RINEX
+// navigation file navr, in the order of the epochs.
+//
+// The epochs of the events, i.e., the flags other than 0 and 1, are skipped.
+// The errors of the epochs are stored in the results, and the error is
+// returned for the files which cannot be read, with the results of the
+// epochs read before the error.
+func ProcessSPP(obsr, navr io.Reader, opts Opts) ([]Result, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	brdc, err := nav.Parse(navr)
+	if err != nil {
+		return nil, fmt.Errorf("nav: %w", err)
+	}
+	if !opts.NoIono && !brdc.Header.HasIono {
+		logger.Printf("warning: no ionospheric coefficients in the navigation file\n")
+		opts.NoIono = true
+	}
+
+	or, err := rinex.NewObsReader(obsr)
+	if err != nil {
+		return nil, fmt.Errorf("obs: %w", err)
+	}
+	ts := or.Header.TimeSystem
+	if _, err := gpsTime(time.Time{}, ts); err != nil {
+		return nil, fmt.Errorf("obs: %w", err)
+	}
+
+	m := model{
+		sys: opts.System,
+		signal: func(e *rinex.Epoch, id gnss.SatID, t time.Time) (signal, error) {
+			return satSignal(e, id, t, brdc, opts)
+		},
+		mask:    opts.ElevationMask,
+		weights: opts.Weights,
+		noTropo: opts.NoTropo,
+		mapping: opts.Mapping,
+		maxIter: opts.MaxIter,
+	}
+	if opts.Met != nil {
+		m.met = newMetModel(opts.Met, opts.MetMaxGap).met
+	}
+	if !opts.NoIono {
+		m.iono = func(t time.Time, lat, lon, az, el float64) float64 {
+			return iono.Klobuchar(brdc.Header.Iono, t, lat, lon, az, el)
+		}
+	}
+	return processEpochs(or, ts, m)
+}
+
+// withDefaults returns the options of the defaults for the zero values, or
+// ErrSystem for the unsupported system.
+func (opts Opts) withDefaults() (Opts, error) {
+	if opts.System == 0 {
+		opts.System = 'G'
+	}
+	if _, ok := carriers[opts.System]; !ok {
+		return opts, fmt.Errorf("%w: %c", ErrSystem, opts.System)
+	}
+	if opts.Bands == "" {
+		opts.Bands = DefaultBands[opts.System]
+	}
+	if opts.MaxIter == 0 {
+		opts.MaxIter = 10
+	}
+	return opts, nil
+}
+
+// satSignal returns the pseudorange of the satellite id of the first band
+// observed, corrected for the satellite clock, and the satellite position at
+// the transmission time for the reception time t in GPS time.
+func satSignal(e *rinex.Epoch, id gnss.SatID, t time.Time, brdc *nav.File, opts Opts) (sig signal, err error) {
+	sig.id = id
+	if sig.code, sig.pr, err = selectCode(e, id, opts.Bands, opts.Priority); err != nil {
+		return sig, err
+	}
+	band := sig.code[1]
+	f, ok := carriers[opts.System][band]
+	if !ok {
+		return sig, fmt.Errorf("%w: band of %s", ErrNoSignal, sig.code)
+	}
+	sig.scale = iono.Scale(f)
+
+	eph, err := brdc.SelectUsable(id.String(), t, opts.Select)
+	if err != nil {
+		return sig, err
+	}
+	var gd float64
+	if k, ok := eph.(*nav.Ephemeris); ok {
+		if gd, err = k.GroupDelay(band); err != nil {
+			return sig, err
+		}
+	}
+
+	// the transmission time by the pseudorange and the satellite clock
+	tx := t.Add(-seconds(sig.pr / lightVelocity))
+	s := eph.State(tx)
+	tx = tx.Add(-seconds(s.Clock - gd))
+	s = eph.State(tx)
+
+	sig.pos = s.Pos
+	sig.clock = (s.Clock - gd) * lightVelocity
+	sig.pr += sig.clock
+	return sig, nil
+}
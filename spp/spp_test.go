@@ -0,0 +1,304 @@
+package spp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/iono"
+	"github.com/satoshi-pes/gnss/nav"
+	"github.com/satoshi-pes/gnss/rinex"
+	"github.com/satoshi-pes/gnss/tropo"
+)
+
+// the observations of GEONET site 0255 (KOMATSU) of the bancroft tests and
+// the broadcast ephemerides of the satellites, and the site position of the
+// RINEX header
+const (
+	testObsFile = "../rinex/testdata/02551960_excerpt.rnx"
+	testNavFile = "../nav/testdata/brdc1960_excerpt.rnx"
+)
+
+var testSite = [3]float64{-3721695.1985, 3545492.6126, 3763541.7139}
+
+func process(t *testing.T, opts Opts) []Result {
+	t.Helper()
+	obs, err := os.Open(testObsFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer obs.Close()
+	brdc, err := os.Open(testNavFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer brdc.Close()
+
+	res, err := ProcessSPP(obs, brdc, opts)
+	if err != nil {
+		t.Fatalf("ProcessSPP: %v", err)
+	}
+	return res
+}
+
+// TestProcessSPP checks the solutions of the observations of the fixture.
+//
+// The observations of GPS at 00:00 are real, but the ephemerides of the
+// fixture are synthetic, not a real BRDC: they are fitted to the precise
+// positions at 00:00 but not to the velocities, i.e., the positions at the
+// transmission times and the relativistic clock corrections are off, and the
+// solution of the first epoch is off by ~41 m to the north. The bound is
+// thus 50 m, and TestProcessSPPIGS checks the accuracy of a few meters with
+// the real BRDC and TestProcessSPPSimulated that of the processing. The
+// second epoch of the fixture is edited for the tests of the reader, and is
+// not checked.
+func TestProcessSPP(t *testing.T) {
+	res := process(t, Opts{ElevationMask: 10})
+	if len(res) != 2 {
+		t.Fatalf("get %d epochs, want 2", len(res))
+	}
+
+	t0 := time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC)
+	for k, r := range res {
+		if want := t0.Add(time.Duration(k) * 30 * time.Second); !r.Epoch.Equal(want) {
+			t.Errorf("epoch: get %v, want %v", r.Epoch, want)
+		}
+	}
+
+	r := res[0]
+	if r.Err != nil {
+		t.Fatalf("%v: %v", r.Epoch, r.Err)
+	}
+	e := bancroft.EvalSolution(r.Solution, testSite)
+	t.Logf("%v: sats=%d, iter=%d, ENU=%.3f %.3f %.3f, PDOP=%.2f", r.Epoch, r.NumSats, r.Iterations, e.ENU[0], e.ENU[1], e.ENU[2], r.DOP.PDOP)
+	if e.Horizontal > 50 || math.Abs(e.ENU[2]) > 50 {
+		t.Errorf("%v: ENU=%v", r.Epoch, e.ENU)
+	}
+	if len(r.Sats) != 9 || r.NumSats != 9 || len(r.Skipped) != 0 {
+		t.Errorf("%v: sats=%+v, skipped=%+v", r.Epoch, r.Sats, r.Skipped)
+	}
+	for i, s := range r.Sats {
+		if s.ID != r.SatIDs[i] || s.Code != "C1C" || s.El < coord.Deg2Rad(10) || s.Iono <= 0 || s.Tropo <= 0 {
+			t.Errorf("%v: %+v", r.Epoch, s)
+		}
+	}
+}
+
+// testIGSNavFile is the broadcast ephemerides of IGS of the day, which is not
+// distributed with the module; see the tests of nav.
+const testIGSNavFile = "../nav/testdata/BRDC00IGS_R_20241960000_01D_MN.rnx"
+
+// TestProcessSPPIGS checks the solution of the first epoch of the fixture with
+// the real ephemerides within 5 m horizontally and 10 m vertically, or skips
+// the test if the BRDC file is not in the testdata of nav.
+func TestProcessSPPIGS(t *testing.T) {
+	brdc, err := os.Open(testIGSNavFile)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no IGS product: %s", testIGSNavFile)
+	}
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer brdc.Close()
+	obs, err := os.Open(testObsFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer obs.Close()
+
+	res, err := ProcessSPP(obs, brdc, Opts{ElevationMask: 10})
+	if err != nil {
+		t.Fatalf("ProcessSPP: %v", err)
+	}
+	r := res[0]
+	if r.Err != nil {
+		t.Fatalf("%v: %v", r.Epoch, r.Err)
+	}
+	e := bancroft.EvalSolution(r.Solution, testSite)
+	if e.Horizontal > 5 || math.Abs(e.ENU[2]) > 10 || r.NumSats != 9 {
+		t.Errorf("%v: sats=%d, ENU=%v", r.Epoch, r.NumSats, e.ENU)
+	}
+}
+
+// TestProcessSPPSimulated checks the position and the clock of the
+// pseudoranges simulated from the ephemerides of the fixture with the same
+// models, which are recovered within 1 mm.
+func TestProcessSPPSimulated(t *testing.T) {
+	brdc, err := nav.ReadFile(testNavFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	epoch := time.Date(2024, 7, 14, 0, 10, 0, 0, time.UTC)
+	const dtr = 1e-4 // receiver clock (s)
+	lat, lon, h := coord.XYZToLLH(testSite[0], testSite[1], testSite[2])
+
+	var lines []string
+	for _, id := range []string{"G14", "G04", "G22", "G06", "G17", "G03", "G21", "G19", "G02"} {
+		eph, err := brdc.SelectUsable(id, epoch, nav.SelectOpts{})
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		gd, _ := eph.(*nav.Ephemeris).GroupDelay('1')
+
+		// the travel time tau by the reception time epoch-dtr
+		var tau float64
+		var s nav.SatState
+		var sat [3]float64
+		for range 5 {
+			s = eph.State(epoch.Add(-seconds(dtr + tau)))
+			sat[0], sat[1], sat[2] = bancroft.RotateSatPos(s.Pos[0], s.Pos[1], s.Pos[2], tau)
+			tau = math.Sqrt(sqr(sat[0]-testSite[0])+sqr(sat[1]-testSite[1])+sqr(sat[2]-testSite[2])) / lightVelocity
+		}
+		az, el := coord.AzEl(testSite, sat)
+		pr := (tau+dtr-s.Clock+gd)*lightVelocity + tropo.SlantDelay(lat, h, el) +
+			iono.Klobuchar(brdc.Header.Iono, epoch, lat, lon, az, el)
+		lines = append(lines, fmt.Sprintf("%s%14.3f  ", id, pr))
+	}
+
+	b, err := os.ReadFile(testObsFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	header := string(b[:strings.Index(string(b), "END OF HEADER")]) + "END OF HEADER\n"
+	obs := header + fmt.Sprintf("> 2024 07 14 00 10  0.0000000  0%3d\n", len(lines)) + strings.Join(lines, "\n") + "\n"
+
+	navr, err := os.Open(testNavFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer navr.Close()
+	res, err := ProcessSPP(strings.NewReader(obs), navr, Opts{})
+	if err != nil {
+		t.Fatalf("ProcessSPP: %v", err)
+	}
+	if len(res) != 1 || res[0].Err != nil {
+		t.Fatalf("results: %+v", res)
+	}
+	r := res[0]
+	for k := range 3 {
+		if math.Abs(r.Position[k]-testSite[k]) > 1e-3 {
+			t.Errorf("position: get %v, want %v", r.Position, testSite)
+			break
+		}
+	}
+	if math.Abs(r.ClockBias+dtr) > 1e-11 || r.RMS > 1e-3 {
+		t.Errorf("clock: get %e, want %e, RMS=%e", r.ClockBias, -dtr, r.RMS)
+	}
+}
+
+// TestProcessSPPCorrections checks the corrections improve the solution.
+func TestProcessSPPCorrections(t *testing.T) {
+	with := process(t, Opts{})[0]
+	without := process(t, Opts{NoIono: true, NoTropo: true})[0]
+	if with.Err != nil || without.Err != nil {
+		t.Fatalf("errors: %v, %v", with.Err, without.Err)
+	}
+	e1 := bancroft.EvalSolution(with.Solution, testSite)
+	e0 := bancroft.EvalSolution(without.Solution, testSite)
+	if math.Abs(e1.ENU[2]) >= math.Abs(e0.ENU[2]) {
+		t.Errorf("up: get %.3f m, without corrections %.3f m", e1.ENU[2], e0.ENU[2])
+	}
+	for _, s := range without.Sats {
+		if s.Iono != 0 || s.Tropo != 0 {
+			t.Errorf("%+v", s)
+		}
+	}
+}
+
+// TestProcessSPPMapping checks the tropospheric delays of the mapping
+// functions selected, which differ from the simple mapping function by at
+// most a few percent.
+func TestProcessSPPMapping(t *testing.T) {
+	simple := process(t, Opts{})[0]
+	gmf := process(t, Opts{Mapping: tropo.MappingGMF})[0]
+	if simple.Err != nil || gmf.Err != nil || len(simple.Sats) != len(gmf.Sats) {
+		t.Fatalf("results: %v, %v", simple.Err, gmf.Err)
+	}
+	for i, s := range gmf.Sats {
+		if r := s.Tropo / simple.Sats[i].Tropo; s.Tropo == simple.Sats[i].Tropo || math.Abs(r-1) > 0.05 {
+			t.Errorf("%v: get %.4f m, simple %.4f m", s.ID, s.Tropo, simple.Sats[i].Tropo)
+		}
+	}
+}
+
+// TestProcessSPPCodes checks the priority of the bands and the codes, and the
+// epochs of too few satellites.
+func TestProcessSPPCodes(t *testing.T) {
+	res := process(t, Opts{Bands: "21", Priority: rinex.Priority{'G': {'2': "W"}}})
+	for _, s := range res[0].Sats {
+		if s.Code != "C2W" {
+			t.Errorf("%+v", s)
+		}
+	}
+
+	// L5 of three satellites at the first epoch
+	res = process(t, Opts{Bands: "5"})
+	if r := res[0]; !errors.Is(r.Err, bancroft.ErrTooFewSats) {
+		t.Errorf("get err=%v, want %v", r.Err, bancroft.ErrTooFewSats)
+	}
+	var n int
+	for _, s := range res[0].Skipped {
+		if errors.Is(s.Err, ErrNoSignal) {
+			n++
+		}
+	}
+	if n != 6 {
+		t.Errorf("skipped: %+v", res[0].Skipped)
+	}
+}
+
+// TestProcessSPPRinex2 checks the codes of RINEX 2 are selected as those of
+// RINEX 3, i.e., C1 as C1C of the same solution.
+func TestProcessSPPRinex2(t *testing.T) {
+	want := process(t, Opts{})[0]
+	obs, err := os.Open("../rinex/testdata/02551960_excerpt.24o")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer obs.Close()
+	brdc, err := os.Open(testNavFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer brdc.Close()
+
+	res, err := ProcessSPP(obs, brdc, Opts{})
+	if err != nil {
+		t.Fatalf("ProcessSPP: %v", err)
+	}
+	if r := res[0]; r.Err != nil || r.Position != want.Position || len(r.Sats) != len(want.Sats) {
+		t.Fatalf("get %v %v, want %v", r.Position, r.Err, want.Position)
+	}
+	for _, s := range res[0].Sats {
+		if s.Code != "C1C" {
+			t.Errorf("%+v", s)
+		}
+	}
+}
+
+// TestProcessSPPErrors checks the errors of the options and the files.
+func TestProcessSPPErrors(t *testing.T) {
+	if _, err := ProcessSPP(strings.NewReader(""), strings.NewReader(""), Opts{System: 'R'}); !errors.Is(err, ErrSystem) {
+		t.Errorf("get err=%v, want %v", err, ErrSystem)
+	}
+
+	b, err := os.ReadFile(testObsFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	obs := strings.Replace(string(b), "     GPS         TIME OF FIRST OBS", "     TAI         TIME OF FIRST OBS", 1)
+	brdc, err := os.Open(testNavFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer brdc.Close()
+	if _, err := ProcessSPP(strings.NewReader(obs), brdc, Opts{}); !errors.Is(err, ErrTimeSystem) {
+		t.Errorf("get err=%v, want %v", err, ErrTimeSystem)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+package tide provides the displacements of the stations by the tides.
+
+The positions are ECEF in meters, and the displacements are returned in the
+local east, north, up frame (see coord.ENURotation) in meters.
+*/
+package tide
+
+import (
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// constants of the solid Earth tide
+const (
+	gmEarth = 3.986004415e14 // gravitational constant of the Earth (m^3/s^2)
+	gmSun   = 1.327124e20    // gravitational constant of the Sun (m^3/s^2)
+	gmMoon  = 4.902801e12    // gravitational constant of the Moon (m^3/s^2)
+	re      = 6378136.6      // equatorial radius of the Earth (m)
+
+	h3 = 0.292 // degree 3 Love number
+	l3 = 0.015 // degree 3 Shida number
+)
+
+// SolidEarth returns the displacement (ENU, m) of the station at pos by the
+// solid Earth tide at the epoch t, where sun and moon are the positions of the
+// Sun and the Moon (ECEF, m), e.g., coord.SunPositionECEF. The displacement
+// is to be subtracted from the solution to obtain the mean position.
+//
+// The model follows the step 1 of IERS Conventions 2010 (sec. 7.1.1) with the
+// latitude dependent degree 2 Love and Shida numbers, the degree 3 terms and
+// the out-of-phase terms, and the K1 radial correction of the step 2. The
+// permanent tide is included, i.e., the position is "conventional tide free".
+// The neglected terms of the step 2 are below a few millimeters.
+func SolidEarth(pos, sun, moon [3]float64, t time.Time) (enu [3]float64) {
+	lat, lon, _ := coord.XYZToLLH(pos[0], pos[1], pos[2])
+	R := coord.ENURotation(lat, lon)
+
+	var dr [3]float64
+	for _, b := range []struct {
+		r  [3]float64
+		gm float64
+	}{{sun, gmSun}, {moon, gmMoon}} {
+		d := degree23(pos, b.r, b.gm)
+		for k := range 3 {
+			dr[k] += d[k]
+		}
+	}
+	enu = coord.Rotate(R, dr)
+
+	// out-of-phase and the step 2 K1 correction are of the radial direction
+	// in the local frame
+	for _, b := range []struct {
+		r  [3]float64
+		gm float64
+	}{{sun, gmSun}, {moon, gmMoon}} {
+		enu[2] += outOfPhase(pos, b.r, b.gm, lon)
+	}
+	enu[2] += -0.0253 * math.Sin(lat) * math.Cos(lat) * math.Sin(coord.GMST(t)+lon)
+
+	return enu
+}
+
+// degree23 returns the in-phase displacement (ECEF, m) of the degree 2 and 3
+// by the body at rb (ECEF, m) with the gravitational constant gm.
+func degree23(pos, rb [3]float64, gm float64) (dr [3]float64) {
+	r := norm(pos)
+	rB := norm(rb)
+	if r == 0 || rB == 0 {
+		return dr
+	}
+	var er, eb [3]float64 // unit vectors of the station and the body
+	for k := range 3 {
+		er[k], eb[k] = pos[k]/r, rb[k]/rB
+	}
+	a := er[0]*eb[0] + er[1]*eb[1] + er[2]*eb[2]
+
+	// latitude dependence of the degree 2 numbers (eq. 7.2)
+	sinPhi := pos[2] / r
+	p2 := (3.*sinPhi*sinPhi - 1.) / 2.
+	h2 := 0.6078 - 0.0006*p2
+	l2 := 0.0847 + 0.0002*p2
+
+	k2 := gm / gmEarth * math.Pow(re, 4) / math.Pow(rB, 3)
+	k3 := k2 * re / rB
+
+	// eq. 7.5
+	c2r := h2 * (1.5*a*a - 0.5)
+	c2b := 3. * l2 * a
+	c3r := h3 * (2.5*a*a*a - 1.5*a)
+	c3b := l3 * (7.5*a*a - 1.5)
+	for k := range 3 {
+		dr[k] = k2*(c2r*er[k]+c2b*(eb[k]-a*er[k])) + k3*(c3r*er[k]+c3b*(eb[k]-a*er[k]))
+	}
+
+	return dr
+}
+
+// outOfPhase returns the radial displacement (m) by the out-of-phase
+// diurnal and semi-diurnal terms of the degree 2 (eq. 7.10a, 7.11a).
+func outOfPhase(pos, rb [3]float64, gm, lon float64) float64 {
+	rB := norm(rb)
+	if rB == 0 {
+		return 0.
+	}
+	latB := math.Asin(rb[2] / rB)
+	lonB := math.Atan2(rb[1], rb[0])
+	k2 := gm / gmEarth * math.Pow(re, 4) / math.Pow(rB, 3)
+
+	phi := math.Atan2(pos[2], math.Hypot(pos[0], pos[1])) // geocentric
+	du := 0.75 * 0.0025 * k2 * math.Sin(2.*latB) * math.Sin(2.*phi) * math.Sin(lon-lonB)
+	du += 0.75 * 0.0022 * k2 * sqr(math.Cos(latB)) * sqr(math.Cos(phi)) * math.Sin(2.*(lon-lonB))
+	return du
+}
+
+func norm(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
+
+func sqr(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,32 @@
+package tide
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// TestSolidEarth checks the displacement against the test case of
+// DEHANTTIDEINEL.F of IERS Conventions 2010 within 1 mm; the differences
+// are of the neglected terms of the step 2.
+func TestSolidEarth(t *testing.T) {
+	pos := [3]float64{4075578.385, 931852.890, 4801570.154}
+	sun := [3]float64{137859926952.015, 54228127881.4350, 23509422341.6960}
+	moon := [3]float64{-179996231.920342, -312468450.131567, -169288918.592160}
+	epoch := time.Date(2009, 4, 13, 0, 0, 0, 0, time.UTC)
+
+	// ECEF (m)
+	want := [3]float64{0.07700420357108125891, 0.06304056321824967613, 0.05516568152597246810}
+
+	enu := SolidEarth(pos, sun, moon, epoch)
+	lat, lon, _ := coord.XYZToLLH(pos[0], pos[1], pos[2])
+	get := coord.RotateT(coord.ENURotation(lat, lon), enu)
+
+	for k := range 3 {
+		if math.Abs(get[k]-want[k]) > 1e-3 {
+			t.Errorf("dr[%d]: get %.4f, want %.4f", k, get[k], want[k])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package track
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+)
+
+// feature is a feature of GeoJSON.
+type feature struct {
+	Type       string         `json:"type"`
+	Geometry   geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// geometry is a geometry of GeoJSON, whose coordinates are those of a
+// position or of the positions.
+type geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// WriteGeoJSON writes the solutions to the file of the name by
+// EncodeGeoJSON.
+func WriteGeoJSON(name string, sols []bancroft.Solution, opts Options) error {
+	fp, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := EncodeGeoJSON(fp, sols, opts); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// EncodeGeoJSON writes the solutions to w as a GeoJSON FeatureCollection of
+// the LineString of the track and the Points of the solutions, whose
+// coordinates are the longitude, the latitude (deg) and the height (m).
+//
+// The properties of the points are "time" (UTC, RFC 3339), "nsat", "hdop",
+// "quality" (FlagOK, FlagLow or FlagRejected) and "layer" ("track" or
+// "rejected"), and those of the LineString are "name", "layer" and
+// "coordTimes" of the times of the points. The LineString is omitted for
+// less than two points.
+func EncodeGeoJSON(w io.Writer, sols []bancroft.Solution, opts Options) error {
+	track, rejected := points(sols, opts)
+
+	features := []feature{}
+	if len(track) > 1 {
+		coords := make([][3]float64, len(track))
+		times := make([]string, len(track))
+		for k, p := range track {
+			coords[k] = [3]float64{p.Lon, p.Lat, p.Height}
+			times[k] = p.Time.Format(time.RFC3339Nano)
+		}
+		features = append(features, feature{
+			Type:     "Feature",
+			Geometry: geometry{"LineString", coords},
+			Properties: map[string]any{
+				"name":       opts.Name,
+				"layer":      "track",
+				"coordTimes": times,
+			},
+		})
+	}
+	for _, l := range []struct {
+		name   string
+		points []point
+	}{{"track", track}, {"rejected", rejected}} {
+		for _, p := range l.points {
+			features = append(features, feature{
+				Type:     "Feature",
+				Geometry: geometry{"Point", [3]float64{p.Lon, p.Lat, p.Height}},
+				Properties: map[string]any{
+					"time":    p.Time.Format(time.RFC3339Nano),
+					"nsat":    p.NumSats,
+					"hdop":    p.HDOP,
+					"quality": p.Flag,
+					"layer":   l.name,
+				},
+			})
+		}
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{"FeatureCollection", features})
+}
@@ -0,0 +1,92 @@
+package track
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestEncodeGeoJSON checks the features of the solutions decoded.
+func TestEncodeGeoJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := EncodeGeoJSON(&b, testSolutions(), Options{Name: "0227", Rejected: true}); err != nil {
+		t.Fatalf("EncodeGeoJSON: %v", err)
+	}
+
+	var fc struct {
+		Type     string
+		Features []struct {
+			Type     string
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &fc); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1+4+1 {
+		t.Fatalf("get %s of %d features", fc.Type, len(fc.Features))
+	}
+
+	line := fc.Features[0]
+	var coords [][3]float64
+	if err := json.Unmarshal(line.Geometry.Coordinates, &coords); err != nil {
+		t.Fatalf("coordinates: %v", err)
+	}
+	if line.Geometry.Type != "LineString" || len(coords) != 4 || line.Properties["name"] != "0227" {
+		t.Errorf("line: %s %v %v", line.Geometry.Type, coords, line.Properties)
+	}
+	if times := line.Properties["coordTimes"].([]any); len(times) != 4 || times[3] != "2024-07-14T00:00:05Z" {
+		t.Errorf("coordTimes: %v", times)
+	}
+
+	for k, want := range []struct {
+		time, quality, layer string
+		nsat                 float64
+	}{
+		{"2024-07-14T00:00:00Z", FlagOK, "track", 8},
+		{"2024-07-14T00:00:01Z", FlagOK, "track", 9},
+		{"2024-07-14T00:00:03Z", FlagLow, "track", 11},
+		{"2024-07-14T00:00:05Z", FlagOK, "track", 13},
+		{"2024-07-14T00:00:02Z", FlagRejected, "rejected", 10},
+	} {
+		f := fc.Features[1+k]
+		p := f.Properties
+		if f.Geometry.Type != "Point" || p["time"] != want.time || p["quality"] != want.quality || p["layer"] != want.layer || p["nsat"] != want.nsat || p["hdop"] != 0.8 {
+			t.Errorf("point %d: get %s %v, want %+v", k, f.Geometry.Type, p, want)
+		}
+	}
+	var c [3]float64
+	if err := json.Unmarshal(fc.Features[1].Geometry.Coordinates, &c); err != nil || c != coords[0] || c[0] != 140 || c[1] != 36 || c[2] != 100 {
+		t.Errorf("point coordinates: get %v, want %v", c, coords[0])
+	}
+}
+
+// TestWriteGeoJSON checks the file of the solutions without the rejected
+// layer and that of a point without the line.
+func TestWriteGeoJSON(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "track.geojson")
+	for _, tt := range []struct {
+		n, features int
+	}{{6, 4 + 1}, {1, 1}, {0, 0}} {
+		if err := WriteGeoJSON(name, testSolutions()[:tt.n], Options{}); err != nil {
+			t.Fatalf("WriteGeoJSON: %v", err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var fc struct{ Features []json.RawMessage }
+		if err := json.Unmarshal(data, &fc); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+		if fc.Features == nil || len(fc.Features) != tt.features {
+			t.Errorf("%d solutions: get %d features, want %d", tt.n, len(fc.Features), tt.features)
+		}
+	}
+}
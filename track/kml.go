@@ -0,0 +1,95 @@
+package track
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+)
+
+// kmlHeader is the header of KML with the schema of the data of the points.
+const kmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+<Document>
+<name>%s</name>
+<Schema id="solution">
+<gx:SimpleArrayField name="nsat" type="int"><displayName>nsat</displayName></gx:SimpleArrayField>
+<gx:SimpleArrayField name="hdop" type="float"><displayName>HDOP</displayName></gx:SimpleArrayField>
+<gx:SimpleArrayField name="quality" type="string"><displayName>quality</displayName></gx:SimpleArrayField>
+</Schema>
+`
+
+// WriteKML writes the solutions to the file of the name by EncodeKML.
+func WriteKML(name string, sols []bancroft.Solution, opts Options) error {
+	fp, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := EncodeKML(fp, sols, opts); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// EncodeKML writes the solutions to w as KML of a gx:Track of the times
+// (UTC) and the positions with the arrays of "nsat", "hdop" and "quality"
+// (see EncodeGeoJSON), in the folder "track", and that of the rejected
+// solutions in the folder "rejected" if opts.Rejected.
+//
+// The altitude mode is "absolute", i.e., the heights are regarded as above
+// the sea level, and should be orthometric (see Options.Geoid) to be drawn
+// at the heights.
+func EncodeKML(w io.Writer, sols []bancroft.Solution, opts Options) error {
+	track, rejected := points(sols, opts)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, kmlHeader, escape(opts.Name))
+	writeTrack(bw, "track", opts.Name, track)
+	if opts.Rejected {
+		writeTrack(bw, "rejected", opts.Name, rejected)
+	}
+	fmt.Fprintf(bw, "</Document>\n</kml>\n")
+	return bw.Flush()
+}
+
+// writeTrack writes the folder of the gx:Track of the points.
+func writeTrack(w io.Writer, folder, name string, ps []point) {
+	fmt.Fprintf(w, "<Folder>\n<name>%s</name>\n", folder)
+	fmt.Fprintf(w, "<Placemark>\n<name>%s</name>\n<gx:Track>\n<altitudeMode>absolute</altitudeMode>\n", escape(name))
+	for _, p := range ps {
+		fmt.Fprintf(w, "<when>%s</when>\n", p.Time.Format(time.RFC3339Nano))
+	}
+	for _, p := range ps {
+		fmt.Fprintf(w, "<gx:coord>%.9f %.9f %.3f</gx:coord>\n", p.Lon, p.Lat, p.Height)
+	}
+
+	fmt.Fprintf(w, "<ExtendedData>\n<SchemaData schemaUrl=\"#solution\">\n")
+	for _, a := range []struct {
+		name  string
+		value func(p point) string
+	}{
+		{"nsat", func(p point) string { return fmt.Sprint(p.NumSats) }},
+		{"hdop", func(p point) string { return fmt.Sprintf("%.2f", p.HDOP) }},
+		{"quality", func(p point) string { return p.Flag }},
+	} {
+		fmt.Fprintf(w, "<gx:SimpleArrayData name=\"%s\">\n", a.name)
+		for _, p := range ps {
+			fmt.Fprintf(w, "<gx:value>%s</gx:value>\n", a.value(p))
+		}
+		fmt.Fprintf(w, "</gx:SimpleArrayData>\n")
+	}
+	fmt.Fprintf(w, "</SchemaData>\n</ExtendedData>\n</gx:Track>\n</Placemark>\n</Folder>\n")
+}
+
+// escape returns s escaped for the texts of XML.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
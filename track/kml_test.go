@@ -0,0 +1,88 @@
+package track
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// kmlDoc is the elements of the tracks of KML.
+type kmlDoc struct {
+	Name    string `xml:"Document>name"`
+	Folders []struct {
+		Name      string `xml:"name"`
+		Placemark struct {
+			Name  string `xml:"name"`
+			Track struct {
+				When   []string `xml:"when"`
+				Coords []string `xml:"coord"`
+				Arrays []struct {
+					Name   string   `xml:"name,attr"`
+					Values []string `xml:"value"`
+				} `xml:"ExtendedData>SchemaData>SimpleArrayData"`
+			} `xml:"Track"`
+		}
+	} `xml:"Document>Folder"`
+}
+
+// TestEncodeKML checks the tracks of the solutions decoded.
+func TestEncodeKML(t *testing.T) {
+	var b bytes.Buffer
+	geoid := func(lat, lon float64) float64 { return 38.5 }
+	if err := EncodeKML(&b, testSolutions(), Options{Name: "R&D <0227>", Geoid: geoid, Rejected: true}); err != nil {
+		t.Fatalf("EncodeKML: %v", err)
+	}
+
+	var doc kmlDoc
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("xml.Unmarshal: %v", err)
+	}
+	if doc.Name != "R&D <0227>" || len(doc.Folders) != 2 {
+		t.Fatalf("get %q of %d folders", doc.Name, len(doc.Folders))
+	}
+
+	tr := doc.Folders[0].Placemark.Track
+	if doc.Folders[0].Name != "track" || len(tr.When) != 4 || len(tr.Coords) != 4 {
+		t.Fatalf("track: %+v", tr)
+	}
+	if tr.When[2] != "2024-07-14T00:00:03Z" || tr.Coords[0] != "140.000000000 36.000000000 61.500" {
+		t.Errorf("track: get %s %s", tr.When[2], tr.Coords[0])
+	}
+	for k, want := range [][]string{
+		{"8", "9", "11", "13"},
+		{"0.80", "0.80", "0.80", "0.80"},
+		{FlagOK, FlagOK, FlagLow, FlagOK},
+	} {
+		if k >= len(tr.Arrays) || !slices.Equal(tr.Arrays[k].Values, want) {
+			t.Errorf("array %d: get %+v, want %v", k, tr.Arrays, want)
+		}
+	}
+
+	rej := doc.Folders[1].Placemark.Track
+	if doc.Folders[1].Name != "rejected" || !slices.Equal(rej.When, []string{"2024-07-14T00:00:02Z"}) || len(rej.Arrays) != 3 || rej.Arrays[2].Values[0] != FlagRejected {
+		t.Errorf("rejected: %+v", rej)
+	}
+}
+
+// TestWriteKML checks the file of the solutions without the rejected layer.
+func TestWriteKML(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "track.kml")
+	if err := WriteKML(name, testSolutions(), Options{}); err != nil {
+		t.Fatalf("WriteKML: %v", err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var doc kmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("xml.Unmarshal: %v", err)
+	}
+	if len(doc.Folders) != 1 || len(doc.Folders[0].Placemark.Track.When) != 4 || strings.Contains(string(data), FlagRejected) {
+		t.Errorf("folders: %+v", doc.Folders)
+	}
+}
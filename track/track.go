@@ -0,0 +1,95 @@
+/*
+Package track exports the series of the solutions as the tracks of the maps,
+i.e., GeoJSON (RFC 7946) and KML with the timestamps.
+
+The positions are converted to the geodetic coordinates on WGS84, and the
+epochs of the solutions in GPS time are converted to UTC.
+*/
+package track
+
+import (
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// GeoidFunc returns the geoid height (m) at the latitude and the longitude
+// (rad), i.e., the ellipsoidal height minus the orthometric height, e.g., a
+// constant of a local survey.
+type GeoidFunc func(lat, lon float64) float64
+
+// Options is the options of the exporters.
+type Options struct {
+	// Name is the name of the track, e.g., the marker name.
+	Name string
+
+	// Geoid converts the heights to the orthometric heights if not nil, or
+	// the heights are the ellipsoidal heights.
+	Geoid GeoidFunc
+
+	// Rejected writes the solutions of Err as the points of a separate layer
+	// "rejected" if true, or they are omitted. The solutions without the
+	// positions are omitted regardless.
+	Rejected bool
+}
+
+// The quality flags of the points.
+const (
+	FlagOK       = "ok"       // solution
+	FlagLow      = "low"      // solution of Solution.LowQuality
+	FlagRejected = "rejected" // solution of Solution.Err
+)
+
+// point is a solution of the geodetic coordinates.
+type point struct {
+	Time     time.Time // UTC
+	Lat, Lon float64   // deg
+	Height   float64   // m
+	NumSats  int
+	HDOP     float64
+	Flag     string
+}
+
+// points returns the points of the solutions of the track and those of the
+// rejected layer.
+func points(sols []bancroft.Solution, opts Options) (track, rejected []point) {
+	for _, s := range sols {
+		if s.Position == [3]float64{} {
+			continue
+		}
+		lat, lon, h := s.LatLonHeight()
+		if opts.Geoid != nil {
+			h -= opts.Geoid(lat, lon)
+		}
+		p := point{
+			Time:    gnsstime.NewGPST(s.Epoch).UTC(),
+			Lat:     round(coord.Rad2Deg(lat), 1e9),
+			Lon:     round(coord.Rad2Deg(lon), 1e9),
+			Height:  round(h, 1e3),
+			NumSats: s.NumSats,
+			HDOP:    round(s.DOP.HDOP, 1e2),
+			Flag:    FlagOK,
+		}
+		switch {
+		case s.Err != nil:
+			if opts.Rejected {
+				p.Flag = FlagRejected
+				rejected = append(rejected, p)
+			}
+			continue
+		case s.LowQuality:
+			p.Flag = FlagLow
+		}
+		track = append(track, p)
+	}
+	return track, rejected
+}
+
+// round returns v rounded to 1/scale, e.g., 1e-9 deg (0.1 mm) of the
+// coordinates.
+func round(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,80 @@
+package track
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/bancroft"
+	"github.com/satoshi-pes/gnss/coord"
+)
+
+// testEpoch is 2024-07-14 00:00:00 UTC in GPS time.
+var testEpoch = time.Date(2024, 7, 14, 0, 0, 18, 0, time.UTC)
+
+// testSolutions returns the solutions every second along the east at the
+// latitude 36 deg, the longitude 140 deg and the ellipsoidal height 100 m,
+// where the third is rejected, the fourth is of low quality and the fifth
+// has no position.
+func testSolutions() []bancroft.Solution {
+	lat, lon := coord.Deg2Rad(36), coord.Deg2Rad(140)
+	x, y, z := coord.LLHToXYZ(lat, lon, 100)
+	var sols []bancroft.Solution
+	for k := range 6 {
+		s := bancroft.Solution{
+			Epoch:    testEpoch.Add(time.Duration(k) * time.Second),
+			Position: coord.ENUToECEF([3]float64{x, y, z}, [3]float64{float64(k), 0, 0}),
+			NumSats:  8 + k,
+			DOP:      bancroft.DOP{HDOP: 0.8},
+		}
+		switch k {
+		case 2:
+			s.Err = errors.New("rejected by RAIM")
+		case 3:
+			s.LowQuality = true
+		case 4:
+			s.Position = [3]float64{}
+			s.Err = errors.New("too few satellites")
+		}
+		sols = append(sols, s)
+	}
+	return sols
+}
+
+// TestPoints checks the points of the solutions with the options.
+func TestPoints(t *testing.T) {
+	sols := testSolutions()
+	track, rejected := points(sols, Options{})
+	if len(track) != 4 || len(rejected) != 0 {
+		t.Fatalf("number of points: %d %d", len(track), len(rejected))
+	}
+	for k, want := range []struct {
+		sec  int
+		flag string
+	}{{0, FlagOK}, {1, FlagOK}, {3, FlagLow}, {5, FlagOK}} {
+		p := track[k]
+		if tt := time.Date(2024, 7, 14, 0, 0, want.sec, 0, time.UTC); !p.Time.Equal(tt) || p.Flag != want.flag || p.NumSats != 8+want.sec {
+			t.Errorf("point %d: get %v %s %d, want %v %s %d", k, p.Time, p.Flag, p.NumSats, tt, want.flag, 8+want.sec)
+		}
+		if math.Abs(p.Lat-36) > 1e-6 || math.Abs(p.Height-100) > 1e-3 {
+			t.Errorf("point %d: get %.9f %.3f, want 36 100", k, p.Lat, p.Height)
+		}
+	}
+	// the radius of the parallel of the prime vertical radius
+	sin, cos := math.Sincos(coord.Deg2Rad(36))
+	r := (coord.WGS84.A/math.Sqrt(1-coord.WGS84.E2()*sin*sin) + 100) * cos
+	if dlon := track[3].Lon - track[0].Lon; math.Abs(dlon-coord.Rad2Deg(5/r)) > 1e-8 {
+		t.Errorf("longitude difference: %v", dlon)
+	}
+
+	// orthometric heights and the rejected layer
+	geoid := func(lat, lon float64) float64 { return 38.5 }
+	track, rejected = points(sols, Options{Geoid: geoid, Rejected: true})
+	if len(track) != 4 || len(rejected) != 1 || rejected[0].Flag != FlagRejected || rejected[0].NumSats != 10 {
+		t.Fatalf("rejected: %+v", rejected)
+	}
+	if math.Abs(track[0].Height-61.5) > 1e-3 {
+		t.Errorf("orthometric height: get %.3f, want 61.5", track[0].Height)
+	}
+}
@@ -0,0 +1,68 @@
+package tropo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// TestGMF checks the test case of gmf.f of the IERS Conventions (2010), and
+// the agreement with NMF at some stations.
+func TestGMF(t *testing.T) {
+	// NRAO, Green Bank, WV at MJD 55055
+	mh, mw := GMF(gnsstime.FromMJD(55055), 0.6708665767, -1.393397187, 844.715, math.Pi/2-1.278564131)
+	if math.Abs(mh-3.425245519339138678) > 1e-12 || math.Abs(mw-3.449589116182419257) > 1e-12 {
+		t.Errorf("IERS: get %.15f %.15f, want 3.425245519339139 3.449589116182419", mh, mw)
+	}
+
+	if mh, mw := GMF(gnsstime.FromMJD(55055), 0.6, 2.4, 0, math.Pi/2); math.Abs(mh-1) > 1e-12 || math.Abs(mw-1) > 1e-12 {
+		t.Errorf("zenith: get %v %v, want 1 1", mh, mw)
+	}
+
+	// the differences of NMF are within 0.5 % and 1.5 % at 5 deg elevation
+	for _, tt := range []struct {
+		name        string
+		lat, lon, h float64
+	}{
+		{"Tokyo", 35.7, 139.5, 100},
+		{"Hartebeesthoek", -25.9, 27.7, 1556},
+		{"Ny-Alesund", 78.9, 11.9, 80},
+		{"Kampala", 0.3, 32.5, 1200},
+	} {
+		for _, tm := range []time.Time{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC)} {
+			el := 5 * deg
+			gh, gw := GMF(tm, tt.lat*deg, tt.lon*deg, tt.h, el)
+			nh, nw := NMF(tt.lat*deg, tt.h, dayOfYear(tm), el)
+			if math.Abs(gh/nh-1) > 0.005 || math.Abs(gw/nw-1) > 0.015 {
+				t.Errorf("%s %v: GMF %v %v, NMF %v %v", tt.name, tm, gh, gw, nh, nw)
+			}
+		}
+	}
+
+	if mh, mw := GMF(gnsstime.FromMJD(55055), 0.6, 2.4, 0, -0.1); mh != 0 || mw != 0 {
+		t.Errorf("below the horizon: get %v %v", mh, mw)
+	}
+}
+
+// TestGMFHarmonics checks the spherical harmonics of the low degrees.
+func TestGMFHarmonics(t *testing.T) {
+	lat, lon := 0.6, 2.4
+	s, c := math.Sincos(lat)
+	h := gmfHarmonics(lat, lon)
+	for _, tt := range []struct {
+		k    int
+		want [2]float64
+	}{
+		{0, [2]float64{1, 0}},
+		{1, [2]float64{s, 0}},
+		{2, [2]float64{c * math.Cos(lon), c * math.Sin(lon)}},
+		{3, [2]float64{(3*s*s - 1) / 2, 0}},
+		{5, [2]float64{3 * c * c * math.Cos(2*lon), 3 * c * c * math.Sin(2*lon)}},
+	} {
+		if get := h[tt.k]; math.Abs(get[0]-tt.want[0]) > 1e-12 || math.Abs(get[1]-tt.want[1]) > 1e-12 {
+			t.Errorf("%d: get %v, want %v", tt.k, get, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tropo
+
+import (
+	"fmt"
+	"time"
+)
+
+// Mapping selects the hydrostatic and the wet mapping functions of the slant
+// delays.
+type Mapping int
+
+const (
+	// MappingSimple is SimpleMapping for both of the delays.
+	MappingSimple Mapping = iota
+
+	// MappingNMF is the Niell mapping functions of NMF.
+	MappingNMF
+
+	// MappingGMF is the Global Mapping Functions of GMF.
+	MappingGMF
+)
+
+// String returns the name of the mapping functions.
+func (m Mapping) String() string {
+	switch m {
+	case MappingSimple:
+		return "simple"
+	case MappingNMF:
+		return "NMF"
+	case MappingGMF:
+		return "GMF"
+	}
+	return fmt.Sprintf("Mapping(%d)", int(m))
+}
+
+// Eval returns the hydrostatic and the wet mapping functions of m for the
+// elevation el (rad) at the latitude lat, the longitude lon (rad) and the
+// height h (m) of the station at the epoch t. SimpleMapping is used for the
+// unknown m. Zeros are returned for a satellite below the horizon.
+func (m Mapping) Eval(t time.Time, lat, lon, h, el float64) (mh, mw float64) {
+	if el <= 0 {
+		return 0, 0
+	}
+	switch m {
+	case MappingNMF:
+		return NMF(lat, h, dayOfYear(t), el)
+	case MappingGMF:
+		return GMF(t, lat, lon, h, el)
+	}
+	s := SimpleMapping(el)
+	return s, s
+}
+
+// Wet returns the wet mapping function of m of the elevation (rad) at the
+// station and the epoch fixed, e.g., for bancroft.ZTDOpts.Mapping.
+func (m Mapping) Wet(t time.Time, lat, lon, h float64) func(el float64) float64 {
+	return func(el float64) float64 {
+		_, mw := m.Eval(t, lat, lon, h, el)
+		return mw
+	}
+}
+
+// SlantDelayMapping is the same as SlantDelayMet, but the zenith hydrostatic
+// and wet delays are mapped separately by m at the longitude lon (rad) and
+// the epoch t.
+func SlantDelayMapping(t time.Time, lat, lon, h, el float64, met Met, m Mapping) float64 {
+	if el <= 0 {
+		return 0.
+	}
+	zhd, zwd := Saastamoinen(lat, h, met)
+	mh, mw := m.Eval(t, lat, lon, h, el)
+	return zhd*mh + zwd*mw
+}
@@ -0,0 +1,49 @@
+package tropo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestMapping checks the mapping functions selected and the slant delays.
+func TestMapping(t *testing.T) {
+	tm := time.Date(2024, 7, 14, 6, 0, 0, 0, time.UTC)
+	lat, lon, h, el := 35.7*deg, 139.5*deg, 100., 10*deg
+	met := StdAtmosphere(h)
+	zhd, zwd := Saastamoinen(lat, h, met)
+
+	nh, nw := NMF(lat, h, dayOfYear(tm), el)
+	gh, gw := GMF(tm, lat, lon, h, el)
+	s := SimpleMapping(el)
+	for _, tt := range []struct {
+		m      Mapping
+		name   string
+		mh, mw float64
+	}{
+		{MappingSimple, "simple", s, s},
+		{MappingNMF, "NMF", nh, nw},
+		{MappingGMF, "GMF", gh, gw},
+	} {
+		if mh, mw := tt.m.Eval(tm, lat, lon, h, el); mh != tt.mh || mw != tt.mw {
+			t.Errorf("%s: get %v %v, want %v %v", tt.name, mh, mw, tt.mh, tt.mw)
+		}
+		if mw := tt.m.Wet(tm, lat, lon, h)(el); mw != tt.mw {
+			t.Errorf("%s: wet: get %v, want %v", tt.name, mw, tt.mw)
+		}
+		if name := tt.m.String(); name != tt.name {
+			t.Errorf("get %s, want %s", name, tt.name)
+		}
+		want := zhd*tt.mh + zwd*tt.mw
+		if d := SlantDelayMapping(tm, lat, lon, h, el, met, tt.m); math.Abs(d-want) > 1e-12 {
+			t.Errorf("%s: delay: get %v, want %v", tt.name, d, want)
+		}
+	}
+
+	if d, want := SlantDelayMapping(tm, lat, lon, h, el, met, MappingSimple), SlantDelayMet(lat, h, el, met); math.Abs(d-want) > 1e-12 {
+		t.Errorf("simple: get %v, want %v", d, want)
+	}
+	if d := SlantDelayMapping(tm, lat, lon, h, -0.1, met, MappingGMF); d != 0 {
+		t.Errorf("below the horizon: get %v", d)
+	}
+}
@@ -0,0 +1,105 @@
+package tropo
+
+import (
+	"math"
+	"time"
+)
+
+// niellLats is the latitudes (deg) of the coefficients of NMF.
+var niellLats = [5]float64{15, 30, 45, 60, 75}
+
+// the coefficients a, b and c of NMF at niellLats by Niell (1996), table 3,
+// i.e., the averages and the amplitudes of the hydrostatic coefficients and
+// the wet coefficients
+var (
+	niellHydroAvg = [3][5]float64{
+		{1.2769934e-3, 1.2683230e-3, 1.2465397e-3, 1.2196049e-3, 1.2045996e-3},
+		{2.9153695e-3, 2.9152299e-3, 2.9288445e-3, 2.9022565e-3, 2.9024912e-3},
+		{62.610505e-3, 62.837393e-3, 63.721774e-3, 63.824265e-3, 64.258455e-3},
+	}
+	niellHydroAmp = [3][5]float64{
+		{0, 1.2709626e-5, 2.6523662e-5, 3.4000452e-5, 4.1202191e-5},
+		{0, 2.1414979e-5, 3.0160779e-5, 7.2562722e-5, 11.723375e-5},
+		{0, 9.0128400e-5, 4.3497037e-5, 84.795348e-5, 170.37206e-5},
+	}
+	niellWet = [3][5]float64{
+		{5.8021897e-4, 5.6794847e-4, 5.8118019e-4, 5.9727542e-4, 6.1641693e-4},
+		{1.4275268e-3, 1.5138625e-3, 1.4572752e-3, 1.5007428e-3, 1.7599082e-3},
+		{4.3472961e-2, 4.6729510e-2, 4.3908931e-2, 4.4626982e-2, 5.4736038e-2},
+	}
+)
+
+// the coefficients of the height correction of the hydrostatic mapping
+// function by Niell (1996), which is also used by GMF
+const (
+	heightA = 2.53e-5
+	heightB = 5.49e-3
+	heightC = 1.14e-3
+)
+
+// NMF returns the hydrostatic and the wet Niell mapping functions (Niell,
+// 1996) for the elevation el (rad) at the latitude lat (rad) and the height h
+// (m) of the station on the day of year doy (1-366, fractional).
+//
+// The coefficients are interpolated linearly in the latitude, and the
+// seasonal variation of the hydrostatic coefficients is of the phase of doy
+// 28 shifted by half a year in the southern hemisphere. The height is used
+// for the height correction of the hydrostatic mapping function. Zeros are
+// returned for a satellite below the horizon.
+func NMF(lat, h, doy, el float64) (mh, mw float64) {
+	if el <= 0 {
+		return 0, 0
+	}
+	y := (doy - 28) / 365.25
+	if lat < 0 {
+		y += 0.5
+	}
+	cosy := math.Cos(2 * math.Pi * y)
+	latDeg := math.Abs(lat) * 180 / math.Pi
+
+	var ah, aw [3]float64
+	for i := range 3 {
+		ah[i] = niellInterp(niellHydroAvg[i], latDeg) - niellInterp(niellHydroAmp[i], latDeg)*cosy
+		aw[i] = niellInterp(niellWet[i], latDeg)
+	}
+	mh = marini(el, ah[0], ah[1], ah[2]) + heightCorrection(el, h)
+	mw = marini(el, aw[0], aw[1], aw[2])
+	return mh, mw
+}
+
+// dayOfYear returns the fractional day of year of t, i.e., 1 at 0h of
+// January 1.
+func dayOfYear(t time.Time) float64 {
+	t = t.UTC()
+	sod := t.Sub(t.Truncate(24 * time.Hour)).Seconds()
+	return float64(t.YearDay()) + sod/86400
+}
+
+// niellInterp returns the coefficient at the latitude latDeg (deg, >= 0)
+// interpolated linearly in niellLats, which is constant out of them.
+func niellInterp(coef [5]float64, latDeg float64) float64 {
+	if latDeg <= niellLats[0] {
+		return coef[0]
+	}
+	if latDeg >= niellLats[4] {
+		return coef[4]
+	}
+	i := int(latDeg/15) - 1
+	p := (latDeg - niellLats[i]) / 15
+	return (1-p)*coef[i] + p*coef[i+1]
+}
+
+// marini returns the mapping function of the continued fraction of the
+// coefficients a, b and c normalized to unity at the zenith (Herring, 1992).
+//
+//	m(el) = (1 + a/(1 + b/(1 + c))) / (sin(el) + a/(sin(el) + b/(sin(el) + c)))
+func marini(el, a, b, c float64) float64 {
+	s := math.Sin(el)
+	return (1 + a/(1+b/(1+c))) / (s + a/(s+b/(s+c)))
+}
+
+// heightCorrection returns the height correction of the hydrostatic mapping
+// function for the height h (m) by Niell (1996).
+func heightCorrection(el, h float64) float64 {
+	return (1/math.Sin(el) - marini(el, heightA, heightB, heightC)) * h * 1e-3
+}
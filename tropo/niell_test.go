@@ -0,0 +1,80 @@
+package tropo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const deg = math.Pi / 180
+
+// TestNMF checks the mapping functions at the zenith, at the latitude of the
+// table without the interpolation, and the seasonal variation.
+func TestNMF(t *testing.T) {
+	if mh, mw := NMF(45*deg, 0, 100, math.Pi/2); math.Abs(mh-1) > 1e-12 || math.Abs(mw-1) > 1e-12 {
+		t.Errorf("zenith: get %v %v, want 1 1", mh, mw)
+	}
+
+	// doy 28 at 45 deg north is of the average minus the amplitude
+	el := 5 * deg
+	wantH := marini(el, 1.2465397e-3-2.6523662e-5, 2.9288445e-3-3.0160779e-5, 63.721774e-3-4.3497037e-5)
+	wantW := marini(el, 5.8118019e-4, 1.4572752e-3, 4.3908931e-2)
+	if mh, mw := NMF(45*deg, 0, 28, el); math.Abs(mh-wantH) > 1e-12 || math.Abs(mw-wantW) > 1e-12 {
+		t.Errorf("45 deg: get %v %v, want %v %v", mh, mw, wantH, wantW)
+	}
+	// about 10.2 and 10.8 at 5 deg (Niell, 1996)
+	if math.Abs(wantH-10.15) > 0.05 || math.Abs(wantW-10.76) > 0.05 {
+		t.Errorf("5 deg: get %v %v", wantH, wantW)
+	}
+
+	// the southern hemisphere is shifted by half a year, and the equator has
+	// no seasonal variation
+	for _, doy := range []float64{10, 100, 200.5, 300} {
+		mh, mw := NMF(-35*deg, 0, doy, el)
+		nh, nw := NMF(35*deg, 0, doy+365.25/2, el)
+		if math.Abs(mh-nh) > 1e-12 || math.Abs(mw-nw) > 1e-12 {
+			t.Errorf("doy %v: south %v %v, north %v %v", doy, mh, mw, nh, nw)
+		}
+		eh, _ := NMF(10*deg, 0, doy, el)
+		e0, _ := NMF(0, 0, 28, el)
+		if eh != e0 {
+			t.Errorf("doy %v: equator %v, want %v", doy, eh, e0)
+		}
+	}
+
+	// continuous at the latitudes of the table
+	for _, lat := range []float64{15, 30, 45, 60, 75} {
+		a, _ := NMF((lat-1e-9)*deg, 0, 50, el)
+		b, _ := NMF((lat+1e-9)*deg, 0, 50, el)
+		if math.Abs(a-b) > 1e-9 {
+			t.Errorf("%v deg: %v and %v", lat, a, b)
+		}
+	}
+
+	// the hydrostatic mapping function increases with the height
+	h0, w0 := NMF(35*deg, 0, 50, el)
+	h1, w1 := NMF(35*deg, 1000, 50, el)
+	if d := h1 - h0; d < 0.01 || d > 0.05 || w1 != w0 {
+		t.Errorf("height: hydrostatic %v, wet %v", d, w1-w0)
+	}
+
+	if mh, mw := NMF(35*deg, 0, 50, -0.1); mh != 0 || mw != 0 {
+		t.Errorf("below the horizon: get %v %v", mh, mw)
+	}
+}
+
+// TestDayOfYear checks the fractional days of year.
+func TestDayOfYear(t *testing.T) {
+	for _, tt := range []struct {
+		t    time.Time
+		want float64
+	}{
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, 12, 31, 18, 0, 0, 0, time.UTC), 366.75},
+		{time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC), 60.5},
+	} {
+		if get := dayOfYear(tt.t); math.Abs(get-tt.want) > 1e-12 {
+			t.Errorf("%v: get %v, want %v", tt.t, get, tt.want)
+		}
+	}
+}
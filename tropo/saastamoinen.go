@@ -0,0 +1,88 @@
+/*
+package tropo provides tropospheric delay models for GNSS pseudoranges.
+
+Angles are in radians, heights are ellipsoidal heights in meters, and the
+delays are in meters. The meteorological parameters are the total pressure
+(hPa), the temperature (K) and the partial pressure of the water vapor (hPa).
+
+The zenith delays are mapped to the slant delays by SimpleMapping, or the
+hydrostatic and the wet mapping functions of NMF and GMF selected by Mapping.
+*/
+package tropo
+
+import "math"
+
+// Met defines the meteorological parameters at the station.
+type Met struct {
+	P float64 // total pressure (hPa)
+	T float64 // temperature (K)
+	E float64 // partial pressure of the water vapor (hPa)
+}
+
+// StdAtmosphere returns the meteorological parameters of the standard
+// atmosphere at the height h (m) with the relative humidity of 70 %.
+func StdAtmosphere(h float64) Met {
+	return StdAtmosphereHumidity(h, 0.7)
+}
+
+// StdAtmosphereHumidity returns the meteorological parameters of the standard
+// atmosphere at the height h (m) with the relative humidity humi (0-1).
+func StdAtmosphereHumidity(h, humi float64) Met {
+	if h < 0 {
+		h = 0
+	}
+	P := 1013.25 * math.Pow(1.-2.2557e-5*h, 5.2568)
+	T := 15. - 6.5e-3*h + 273.15
+	return MetHumidity(P, T, humi)
+}
+
+// MetHumidity returns the meteorological parameters of the total pressure p
+// (hPa), the temperature t (K) and the relative humidity humi (0-1).
+func MetHumidity(p, t, humi float64) Met {
+	// saturation water vapor pressure (hPa)
+	es := 6.108 * math.Exp((17.15*t-4684.)/(t-38.45))
+
+	return Met{P: p, T: t, E: humi * es}
+}
+
+// Saastamoinen returns the zenith hydrostatic and wet delays (m) by the
+// Saastamoinen model at the latitude lat (rad) and the height h (m).
+//
+//	zhd = 0.0022768*P / (1 - 0.00266*cos(2*lat) - 0.00028*h[km])
+//	zwd = 0.002277*(1255/T + 0.05)*e
+func Saastamoinen(lat, h float64, met Met) (zhd, zwd float64) {
+	zhd = 0.0022768 * met.P / (1. - 0.00266*math.Cos(2.*lat) - 0.00028*h*1e-3)
+	zwd = 0.002277 * (1255./met.T + 0.05) * met.E
+	return zhd, zwd
+}
+
+// SimpleMapping returns the simple mapping function for the elevation el
+// (rad) by Black and Eisner (1984), which is 1/sin(el) near the zenith and
+// remains finite at the horizon.
+//
+//	m(el) = 1.001 / sqrt(0.002001 + sin(el)^2)
+func SimpleMapping(el float64) float64 {
+	s := math.Sin(el)
+	return 1.001 / math.Sqrt(0.002001+s*s)
+}
+
+// SlantDelay returns the slant tropospheric delay (m) for the satellite at
+// the elevation el (rad) seen from the station at the latitude lat (rad) and
+// the height h (m), using the Saastamoinen zenith delays with the standard
+// atmosphere and SimpleMapping.
+//
+// The delay is to be subtracted from the pseudorange. Zero is returned for a
+// satellite below the horizon.
+func SlantDelay(lat, h, el float64) float64 {
+	return SlantDelayMet(lat, h, el, StdAtmosphere(h))
+}
+
+// SlantDelayMet is the same as SlantDelay, but with the given meteorological
+// parameters.
+func SlantDelayMet(lat, h, el float64, met Met) float64 {
+	if el <= 0 {
+		return 0.
+	}
+	zhd, zwd := Saastamoinen(lat, h, met)
+	return (zhd + zwd) * SimpleMapping(el)
+}
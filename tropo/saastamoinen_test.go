@@ -0,0 +1,44 @@
+package tropo
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSaastamoinen checks the zenith delays with the well known values.
+func TestSaastamoinen(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, h   float64
+		met      Met
+		zhd, zwd float64
+	}{
+		// 0.0022768*1013.25/(1-0.00266*cos(90deg)) = 2.3070 m
+		{"lat=45deg, sea level", math.Pi / 4, 0., Met{P: 1013.25, T: 288.15, E: 0.}, 2.3070, 0.},
+		// 0.002277*(1255/300+0.05)*20 = 0.1928 m
+		{"equator, humid", 0., 0., Met{P: 1013.25, T: 300., E: 20.}, 2.3131, 0.1928},
+	}
+
+	for _, tt := range tests {
+		zhd, zwd := Saastamoinen(tt.lat, tt.h, tt.met)
+		if math.Abs(zhd-tt.zhd) > 1e-4 || math.Abs(zwd-tt.zwd) > 1e-4 {
+			t.Errorf("%s: get (%.4f, %.4f), want (%.4f, %.4f)", tt.name, zhd, zwd, tt.zhd, tt.zwd)
+		}
+	}
+
+	// standard atmosphere at the sea level
+	met := StdAtmosphere(0.)
+	if math.Abs(met.P-1013.25) > 1e-9 || math.Abs(met.T-288.15) > 1e-9 {
+		t.Errorf("StdAtmosphere: %+v", met)
+	}
+
+	// slant delay: ~2.4 m at the zenith, ~10x at 5deg
+	d90 := SlantDelay(math.Pi/4, 0., math.Pi/2)
+	d5 := SlantDelay(math.Pi/4, 0., 5*math.Pi/180)
+	if d90 < 2.3 || d90 > 2.6 || d5/d90 < 9 || d5/d90 > 12 {
+		t.Errorf("SlantDelay: zenith=%f, el=5deg=%f", d90, d5)
+	}
+	if d := SlantDelay(0., 0., -0.1); d != 0 {
+		t.Errorf("SlantDelay below horizon: %f", d)
+	}
+}
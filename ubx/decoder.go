@@ -0,0 +1,163 @@
+package ubx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/satoshi-pes/gnss/rinex"
+)
+
+// Decoder decodes the messages of a stream into the epochs of the
+// observations of RXM-RAWX and the subframes of RXM-SFRBX.
+type Decoder struct {
+	// Subframes is the subframes of RXM-SFRBX decoded until the epoch
+	// returned by the last call of Next, which are cleared by Next.
+	Subframes []*Subframe
+
+	r     *Reader
+	stats Stats
+	last  time.Time // epoch of the last RXM-RAWX
+	locks map[lockKey]lockState
+}
+
+// lockKey is the key of the locktimes of the signals.
+type lockKey struct {
+	sat, code string
+}
+
+// lockState is the locktime and the half cycle of the last measurement of
+// a signal.
+type lockState struct {
+	lock    time.Duration
+	halfSub bool
+}
+
+// NewDecoder returns the decoder of the stream r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := newDecoder()
+	d.r = NewReader(r)
+	return d
+}
+
+// newDecoder returns the decoder without the reader.
+func newDecoder() *Decoder {
+	return &Decoder{stats: Stats{Messages: map[int]int{}}, locks: map[lockKey]lockState{}}
+}
+
+// Stats returns the counters of the frames and the messages.
+func (d *Decoder) Stats() Stats {
+	s := d.stats
+	if d.r != nil {
+		s.Frames, s.BadChecksum, s.Skipped = d.r.Stats.Frames, d.r.Stats.BadChecksum, d.r.Stats.Skipped
+	}
+	s.Messages = make(map[int]int, len(d.stats.Messages))
+	for k, v := range d.stats.Messages {
+		s.Messages[k] = v
+	}
+	return s
+}
+
+// Next returns the epoch of the next RXM-RAWX of the stream, and io.EOF at
+// the end of the stream. The messages failed to decode are skipped and
+// counted in Stats.
+func (d *Decoder) Next() (*rinex.Epoch, error) {
+	d.Subframes = nil
+	for {
+		f, err := d.r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e, err := d.Decode(f); err == nil && e != nil {
+			return e, nil
+		}
+	}
+}
+
+// Decode decodes the frame, and returns the epoch of RXM-RAWX, or nil for
+// RXM-SFRBX, whose subframe is appended to Subframes. ErrUnsupported is
+// returned for the other messages, and ErrFormat for the invalid contents.
+func (d *Decoder) Decode(f Frame) (*rinex.Epoch, error) {
+	e, err := d.decode(f)
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		d.stats.Unsupported++
+	case err != nil:
+		d.stats.Errors++
+	default:
+		d.stats.Messages[f.Type()]++
+	}
+	return e, err
+}
+
+func (d *Decoder) decode(f Frame) (*rinex.Epoch, error) {
+	switch f.Type() {
+	case TypeRAWX:
+		x, err := DecodeRawX(f.Payload)
+		if err != nil {
+			return nil, err
+		}
+		return d.epoch(x), nil
+	case TypeSFRBX:
+		s, err := DecodeSubframe(f.Payload)
+		if err != nil {
+			return nil, err
+		}
+		s.Time = d.last
+		d.Subframes = append(d.Subframes, s)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: 0x%04X", ErrUnsupported, f.Type())
+}
+
+// epoch returns the epoch of the observations of the measurements. The bit
+// 0 of LLI of the phases is set for the locktime zero or decreased, or the
+// subtraction of the half cycle changed, and the bit 1 for the half cycle
+// ambiguity unresolved.
+func (d *Decoder) epoch(x *RawX) *rinex.Epoch {
+	e := &rinex.Epoch{Time: x.Time(), Obs: map[string]rinex.SatObs{}}
+	d.last = e.Time
+	for _, m := range x.Meas {
+		sat, ok := m.Sat()
+		if !ok {
+			continue
+		}
+		code, ok := m.Code()
+		if !ok {
+			continue
+		}
+		id := sat.String()
+		obs := e.Obs[id]
+		if obs == nil {
+			obs = rinex.SatObs{}
+			e.Obs[id] = obs
+			e.Sats = append(e.Sats, id)
+		}
+
+		ssi := 0
+		if m.CNO > 0 {
+			ssi = min(max(m.CNO/6, 1), 9)
+			obs["S"+code] = rinex.Obs{Value: float64(m.CNO)}
+		}
+		if m.PrValid {
+			obs["C"+code] = rinex.Obs{Value: m.Pseudorange, SSI: ssi}
+		}
+		if m.CpValid {
+			key := lockKey{id, code}
+			lli := 0
+			if last, ok := d.locks[key]; m.Lock == 0 || ok && (m.Lock < last.lock || m.HalfCycleSub != last.halfSub) {
+				lli |= 1
+			}
+			d.locks[key] = lockState{m.Lock, m.HalfCycleSub}
+			if !m.HalfCycleValid {
+				lli |= 2
+			}
+			obs["L"+code] = rinex.Obs{Value: m.Carrier, LLI: lli, SSI: ssi}
+		}
+		if m.Doppler != 0 {
+			obs["D"+code] = rinex.Obs{Value: m.Doppler}
+		}
+	}
+	return e
+}
@@ -0,0 +1,197 @@
+package ubx
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// TestDecoder checks the epochs and the subframes of the fixture, the LLI
+// of the phases and the counters.
+func TestDecoder(t *testing.T) {
+	f, err := os.Open(testFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	d := NewDecoder(f)
+
+	e1, err := d.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(d.Subframes) != 0 {
+		t.Errorf("subframes before the first epoch: %d", len(d.Subframes))
+	}
+	t1 := time.Date(2024, 7, 11, 0, 0, 0, 500000, time.UTC)
+	if want := []string{"G05", "R12", "E11", "C20", "J02", "S37"}; !e1.Time.Equal(t1) || !equal(e1.Sats, want) {
+		t.Errorf("first: get %v %v, want %v %v", e1.Time, e1.Sats, t1, want)
+	}
+	for _, tt := range []struct {
+		id, code string
+		value    float64
+		lli, ssi int
+	}{
+		{"G05", "C1C", 21234567.123, 0, 7},
+		{"G05", "L1C", 111588123.456, 0, 7},
+		{"G05", "D1C", -1234.5, 0, 0},
+		{"G05", "S1C", 45, 0, 0},
+		{"G05", "L2L", 86951234.25, 0, 6},
+		{"R12", "L1C", 106234567.5, 0, 6},
+		{"E11", "L1C", 123265432.75, 2, 6},
+		{"E11", "C5Q", 23456791.5, 0, 6},
+		{"C20", "L2I", 196012345.25, 3, 5},
+		{"J02", "C1C", 38123456.0, 0, 7},
+		{"S37", "C1C", 38000000.0, 0, 5},
+	} {
+		o, ok := e1.Obs[tt.id][tt.code]
+		if !ok || o.Value != tt.value || o.LLI != tt.lli || o.SSI != tt.ssi {
+			t.Errorf("%s %s: get %+v (%v), want %v %d %d", tt.id, tt.code, o, ok, tt.value, tt.lli, tt.ssi)
+		}
+	}
+	for _, tt := range []struct{ id, code string }{{"J02", "D1C"}, {"S37", "L1C"}, {"S37", "D1C"}} {
+		if o, ok := e1.Obs[tt.id][tt.code]; ok {
+			t.Errorf("%s %s: get %+v, want none", tt.id, tt.code, o)
+		}
+	}
+
+	e2, err := d.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(d.Subframes) != 2 || d.Subframes[0].Sat.String() != "G05" || d.Subframes[1].Sat.String() != "R12" || !d.Subframes[1].Time.Equal(t1) {
+		t.Errorf("subframes: %+v", d.Subframes)
+	}
+	if !e2.Time.Equal(t1.Add(time.Second)) || len(e2.Sats) != 2 {
+		t.Errorf("second: get %v %v", e2.Time, e2.Sats)
+	}
+	for _, tt := range []struct {
+		id, code string
+		lli      int
+	}{
+		{"G05", "L1C", 1}, // locktime decreased
+		{"G05", "L2L", 1}, // half cycle subtracted
+		{"E11", "L1C", 0}, // half cycle resolved
+	} {
+		if o := e2.Obs[tt.id][tt.code]; o.LLI != tt.lli {
+			t.Errorf("%s %s: get LLI %d, want %d", tt.id, tt.code, o.LLI, tt.lli)
+		}
+	}
+
+	if _, err := d.Next(); err != io.EOF || len(d.Subframes) != 0 {
+		t.Errorf("end: get err=%v, %d subframes", err, len(d.Subframes))
+	}
+	s := d.Stats()
+	if s.Frames != 6 || s.BadChecksum != 1 || s.Skipped != 63 || s.Unsupported != 1 || s.Errors != 1 || s.Messages[TypeRAWX] != 2 || s.Messages[TypeSFRBX] != 2 {
+		t.Errorf("stats: get %+v", s)
+	}
+}
+
+// testCapturePattern is the streams captured from the u-blox receivers of
+// RXM-RAWX and RXM-SFRBX enabled, which are not distributed with the module.
+const testCapturePattern = "testdata/capture_*.ubx"
+
+// TestDecoderCapture checks the captured streams are decoded without the
+// errors, the pseudoranges of C1C of GPS, and the TOWs of the HOWs of the
+// subframes of GPS LNAV against the epochs of RXM-RAWX, or skips the test if
+// there are no captures.
+func TestDecoderCapture(t *testing.T) {
+	names, _ := filepath.Glob(testCapturePattern)
+	if len(names) == 0 {
+		t.Skipf("no capture in testdata: %s", testCapturePattern)
+	}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+		d := NewDecoder(f)
+
+		var nsub int
+		for {
+			e, err := d.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: Next: %v", name, err)
+			}
+			// the ranges of 20000 to 26000 km with the receiver clock
+			// within 1 ms
+			for id, obs := range e.Obs {
+				if o, ok := obs["C1C"]; ok && id[0] == 'G' && (o.Value < 1.9e7 || o.Value > 2.7e7) {
+					t.Errorf("%s %v: %s C1C: %.3f m", name, e.Time, id, o.Value)
+				}
+			}
+			for _, s := range d.Subframes {
+				if s.Sat.Sys != gnss.GPS || s.SigID != 0 || len(s.Words) != 10 || s.Time.IsZero() {
+					continue
+				}
+				checkLNAV(t, name, s)
+				nsub++
+			}
+		}
+		if s := d.Stats(); s.BadChecksum != 0 || s.Errors != 0 || s.Messages[TypeRAWX] == 0 || nsub == 0 {
+			t.Errorf("%s: stats=%+v, GPS subframes=%d", name, s, nsub)
+		}
+	}
+}
+
+// checkLNAV checks the preamble of the TLM word and the HOW of the subframe
+// of GPS LNAV, whose data bits are of the corrected polarity, where the TOW
+// count is that of the start of the next subframe, i.e., 0 to 7 s after the
+// last epoch of RXM-RAWX.
+func checkLNAV(t *testing.T, name string, s *Subframe) {
+	t.Helper()
+	tlm, how := s.Words[0], s.Words[1]
+	if tlm>>22&0xFF != 0x8B {
+		t.Errorf("%s %v: %s: TLM %08X", name, s.Time, s.Sat, tlm)
+		return
+	}
+	_, tow := gnsstime.NewGPST(s.Time).Week()
+	next := float64(how>>13&0x1FFFF) * 6
+	if d := math.Mod(next-tow+604800, 604800); d > 7 {
+		t.Errorf("%s %v: %s: TOW of HOW %.0f s", name, s.Time, s.Sat, next)
+	}
+	if id := how >> 8 & 7; id < 1 || id > 5 {
+		t.Errorf("%s %v: %s: subframe ID %d", name, s.Time, s.Sat, id)
+	}
+}
+
+// equal reports whether the slices are equal.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestDecoderDecode checks the errors of the frames decoded directly.
+func TestDecoderDecode(t *testing.T) {
+	d := newDecoder()
+	if _, err := d.Decode(Frame{Class: 0x01, ID: 0x07}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("NAV-PVT: get err=%v, want %v", err, ErrUnsupported)
+	}
+	if _, err := d.Decode(Frame{Class: 0x02, ID: 0x15, Payload: make([]byte, 17)}); !errors.Is(err, ErrFormat) {
+		t.Errorf("RXM-RAWX: get err=%v, want %v", err, ErrFormat)
+	}
+	if e, err := d.Decode(Frame{Class: 0x02, ID: 0x13, Payload: make([]byte, 8)}); e != nil || err != nil || len(d.Subframes) != 1 || !d.Subframes[0].Sat.IsZero() {
+		t.Errorf("RXM-SFRBX: get %v (err=%v), %+v", e, err, d.Subframes)
+	}
+	if s := d.Stats(); s.Unsupported != 1 || s.Errors != 1 || s.Messages[TypeSFRBX] != 1 {
+		t.Errorf("stats: %+v", s)
+	}
+}
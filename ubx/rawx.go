@@ -0,0 +1,122 @@
+package ubx
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+	"github.com/satoshi-pes/gnss/gnsstime"
+)
+
+// RawX is the multi-GNSS raw measurements of RXM-RAWX of an epoch.
+type RawX struct {
+	// TOW is the time of week (s) of the receiver in GPS time, Week is the
+	// GPS week, and LeapSeconds is GPS-UTC (s), which is valid if
+	// LeapValid.
+	TOW         float64
+	Week        int
+	LeapSeconds int
+	LeapValid   bool
+
+	// ClockReset is true if the clock of the receiver is reset, and Version
+	// is the version of the message.
+	ClockReset bool
+	Version    int
+
+	Meas []RawMeas
+}
+
+// RawMeas is a measurement of a signal of RXM-RAWX.
+type RawMeas struct {
+	// Pseudorange (m), Carrier (cycles) and Doppler (Hz) are the
+	// measurements, which are valid if PrValid and CpValid, where the
+	// Doppler is positive for the satellites approaching.
+	Pseudorange float64
+	Carrier     float64
+	Doppler     float64
+
+	// GNSSID, SvID, SigID and FreqID are the identifiers of the message,
+	// where FreqID is the frequency channel number plus 7 of GLONASS.
+	GNSSID, SvID, SigID, FreqID int
+
+	// Lock is the carrier phase locktime, and CNO is the carrier to noise
+	// ratio (dB-Hz).
+	Lock time.Duration
+	CNO  int
+
+	// PrStdev (m), CpStdev (cycles) and DoStdev (Hz) are the estimated
+	// standard deviations of the measurements.
+	PrStdev, CpStdev, DoStdev float64
+
+	// PrValid and CpValid are the validities of the pseudorange and the
+	// carrier phase, HalfCycleValid is true if the half cycle ambiguity is
+	// resolved, and HalfCycleSub is true if the half cycle is subtracted
+	// from the phase.
+	PrValid, CpValid             bool
+	HalfCycleValid, HalfCycleSub bool
+}
+
+// Sat returns the satellite of the measurement, and false if unknown.
+func (m *RawMeas) Sat() (gnss.SatID, bool) {
+	return satID(m.GNSSID, m.SvID)
+}
+
+// Code returns the observation code without the type of the signal, e.g.,
+// "1C", and false if unknown.
+func (m *RawMeas) Code() (string, bool) {
+	id, ok := m.Sat()
+	if !ok {
+		return "", false
+	}
+	return signalCode(id.Sys, m.SigID)
+}
+
+// Time returns the epoch of the receiver in GPS time.
+func (x *RawX) Time() time.Time {
+	return gnsstime.GPSTFromWeek(x.Week, x.TOW).Time()
+}
+
+// DecodeRawX decodes the payload of RXM-RAWX.
+func DecodeRawX(payload []byte) (*RawX, error) {
+	if len(payload) < 16 {
+		return nil, fmt.Errorf("%w: RXM-RAWX: %d bytes", ErrFormat, len(payload))
+	}
+	n := u1(payload, 11)
+	if len(payload) != 16+32*n {
+		return nil, fmt.Errorf("%w: RXM-RAWX: %d bytes of %d measurements", ErrFormat, len(payload), n)
+	}
+	stat := u1(payload, 12)
+	x := &RawX{
+		TOW:         r8(payload, 0),
+		Week:        u2(payload, 8),
+		LeapSeconds: i1(payload, 10),
+		LeapValid:   stat&0x01 != 0,
+		ClockReset:  stat&0x02 != 0,
+		Version:     u1(payload, 13),
+		Meas:        make([]RawMeas, n),
+	}
+	for k := range x.Meas {
+		p := payload[16+32*k:]
+		trk := u1(p, 30)
+		x.Meas[k] = RawMeas{
+			Pseudorange:    r8(p, 0),
+			Carrier:        r8(p, 8),
+			Doppler:        r4(p, 16),
+			GNSSID:         u1(p, 20),
+			SvID:           u1(p, 21),
+			SigID:          u1(p, 22),
+			FreqID:         u1(p, 23),
+			Lock:           time.Duration(u2(p, 24)) * time.Millisecond,
+			CNO:            u1(p, 26),
+			PrStdev:        0.01 * math.Pow(2, float64(u1(p, 27)&0x0F)),
+			CpStdev:        0.004 * float64(u1(p, 28)&0x0F),
+			DoStdev:        0.002 * math.Pow(2, float64(u1(p, 29)&0x0F)),
+			PrValid:        trk&0x01 != 0,
+			CpValid:        trk&0x02 != 0,
+			HalfCycleValid: trk&0x04 != 0,
+			HalfCycleSub:   trk&0x08 != 0,
+		}
+	}
+	return x, nil
+}
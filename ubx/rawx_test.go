@@ -0,0 +1,100 @@
+package ubx
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// testFrames returns the frames of the fixture of the type.
+func testFrames(t *testing.T, typ int) []Frame {
+	t.Helper()
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	var fs []Frame
+	for {
+		f, err := r.Next()
+		if err != nil {
+			return fs
+		}
+		if f.Type() == typ {
+			fs = append(fs, f)
+		}
+	}
+}
+
+// TestDecodeRawX checks the header and the measurements of the first
+// RXM-RAWX of the fixture.
+func TestDecodeRawX(t *testing.T) {
+	x, err := DecodeRawX(testFrames(t, TypeRAWX)[0].Payload)
+	if err != nil {
+		t.Fatalf("DecodeRawX: %v", err)
+	}
+	switch {
+	case x.TOW != 345600.0005 || x.Week != 2322 || x.LeapSeconds != 18 || !x.LeapValid || x.ClockReset || x.Version != 1:
+		t.Errorf("header: %+v", x)
+	case len(x.Meas) != 10:
+		t.Fatalf("number of measurements: get %d, want 10", len(x.Meas))
+	}
+	if want := time.Date(2024, 7, 11, 0, 0, 0, 500000, time.UTC); !x.Time().Equal(want) {
+		t.Errorf("time: get %v, want %v", x.Time(), want)
+	}
+
+	m := x.Meas[0]
+	switch {
+	case m.Pseudorange != 21234567.123 || m.Carrier != 111588123.456 || m.Doppler != -1234.5:
+		t.Errorf("measurement 0: %+v", m)
+	case m.GNSSID != 0 || m.SvID != 5 || m.SigID != 0 || m.FreqID != 0 || m.Lock != 64500*time.Millisecond || m.CNO != 45:
+		t.Errorf("measurement 0: %+v", m)
+	case m.PrStdev != 0.32 || math.Abs(m.CpStdev-0.012) > 1e-12 || m.DoStdev != 0.032:
+		t.Errorf("measurement 0: stdev %v %v %v", m.PrStdev, m.CpStdev, m.DoStdev)
+	case !m.PrValid || !m.CpValid || !m.HalfCycleValid || !m.HalfCycleSub:
+		t.Errorf("measurement 0: tracking status %+v", m)
+	}
+	if m := x.Meas[3]; !m.PrValid || !m.CpValid || m.HalfCycleValid || !m.HalfCycleSub {
+		t.Errorf("measurement 3: tracking status %+v", m)
+	}
+
+	for k, tt := range []struct {
+		sat  gnss.SatID
+		code string
+		ok   bool
+	}{
+		{gnss.SatID{Sys: gnss.GPS, PRN: 5}, "1C", true},
+		{gnss.SatID{Sys: gnss.GPS, PRN: 5}, "2L", true},
+		{gnss.SatID{Sys: gnss.GLONASS, PRN: 12}, "1C", true},
+		{gnss.SatID{Sys: gnss.Galileo, PRN: 11}, "1C", true},
+		{gnss.SatID{Sys: gnss.Galileo, PRN: 11}, "5Q", true},
+		{gnss.SatID{Sys: gnss.BeiDou, PRN: 20}, "2I", true},
+		{gnss.SatID{Sys: gnss.QZSS, PRN: 2}, "1C", true},
+		{gnss.SatID{Sys: gnss.SBAS, PRN: 37}, "1C", true},
+		{gnss.SatID{}, "", false},
+		{gnss.SatID{Sys: gnss.GPS, PRN: 10}, "", false},
+	} {
+		sat, _ := x.Meas[k].Sat()
+		code, ok := x.Meas[k].Code()
+		if sat != tt.sat || code != tt.code || ok != tt.ok {
+			t.Errorf("measurement %d: get %v %q %v, want %v %q %v", k, sat, code, ok, tt.sat, tt.code, tt.ok)
+		}
+	}
+}
+
+// TestDecodeRawXErrors checks the payloads of the invalid lengths.
+func TestDecodeRawXErrors(t *testing.T) {
+	p := testFrames(t, TypeRAWX)[0].Payload
+	for _, n := range []int{0, 15, 16, len(p) - 1, len(p) + 32} {
+		q := make([]byte, n)
+		copy(q, p)
+		if _, err := DecodeRawX(q); !errors.Is(err, ErrFormat) {
+			t.Errorf("%d bytes: get err=%v, want %v", n, err, ErrFormat)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package ubx
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Stats is the counters of the stream for the monitoring.
+type Stats struct {
+	// Frames is the number of the frames of the valid checksum, and
+	// BadChecksum is that of the invalid checksum, which are skipped.
+	Frames      int
+	BadChecksum int
+
+	// Skipped is the number of the bytes skipped to resynchronize on the
+	// sync characters, including those of the frames of the invalid
+	// checksum.
+	Skipped int
+
+	// Messages is the numbers of the messages decoded keyed by the message
+	// types, e.g., TypeRAWX, Unsupported is the number of the frames of the
+	// messages not decoded, and Errors is that of the messages failed to
+	// decode.
+	Messages    map[int]int
+	Unsupported int
+	Errors      int
+}
+
+// Reader reads the frames of a stream, resynchronizing on the sync
+// characters after the garbage bytes and the frames of the invalid checksum.
+type Reader struct {
+	// Stats is the counters of the frames read.
+	Stats Stats
+
+	r *bufio.Reader
+}
+
+// NewReader returns the reader of the frames of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 2*(6+maxPayload+2)), Stats: Stats{Messages: map[int]int{}}}
+}
+
+// Next returns the next frame of the valid checksum, and io.EOF at the end
+// of the stream, where the incomplete frame at the end is skipped. The other
+// errors are those of the underlying reader.
+func (r *Reader) Next() (Frame, error) {
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return Frame{}, err
+		}
+		if b != sync1 {
+			r.Stats.Skipped++
+			continue
+		}
+
+		// the second sync character, the class, the ID and the length
+		h, err := r.r.Peek(5)
+		if err != nil {
+			return Frame{}, r.end(len(h), err)
+		}
+		n := int(h[3]) | int(h[4])<<8
+		if h[0] != sync2 || n > maxPayload {
+			r.Stats.Skipped++
+			continue
+		}
+		data, err := r.r.Peek(5 + n + 2)
+		if err != nil {
+			return Frame{}, r.end(len(data), err)
+		}
+
+		ca, cb := checksum(data[1 : 5+n])
+		if ca != data[5+n] || cb != data[6+n] {
+			// resynchronize from the byte after the first sync character
+			r.Stats.BadChecksum++
+			r.Stats.Skipped++
+			continue
+		}
+		f := Frame{Class: data[1], ID: data[2], Payload: append([]byte(nil), data[5:5+n]...)}
+		r.r.Discard(5 + n + 2)
+		r.Stats.Frames++
+		return f, nil
+	}
+}
+
+// end returns the error at the end of the stream in a frame, whose first
+// sync character and the n bytes peeked are skipped.
+func (r *Reader) end(n int, err error) error {
+	r.r.Discard(n)
+	r.Stats.Skipped += 1 + n
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	return err
+}
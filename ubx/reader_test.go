@@ -0,0 +1,56 @@
+package ubx
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestReader checks the frames of the fixture and the counters.
+func TestReader(t *testing.T) {
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	r := NewReader(bytes.NewReader(data))
+	var types []int
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		types = append(types, f.Type())
+	}
+	want := []int{0x0107, TypeRAWX, TypeSFRBX, TypeSFRBX, TypeRAWX, TypeRAWX}
+	if len(types) != len(want) {
+		t.Fatalf("types: get %04X, want %04X", types, want)
+	}
+	for k := range want {
+		if types[k] != want[k] {
+			t.Errorf("types: get %04X, want %04X", types, want)
+			break
+		}
+	}
+	if s := r.Stats; s.Frames != 6 || s.BadChecksum != 1 || s.Skipped != 11+32+20 {
+		t.Errorf("stats: get %+v, want 6 frames, 1 bad checksum, 63 skipped", s)
+	}
+}
+
+// TestReaderSplit checks the frames of the stream of the bytes read one by
+// one, and the payload of the frame.
+func TestReaderSplit(t *testing.T) {
+	payload := []byte{1, 2, 3, 4, 5}
+	data := append([]byte{0x62, sync1}, testFrame(0x0A, 0x04, payload)...)
+	r := NewReader(io.MultiReader(bytes.NewReader(data[:3]), bytes.NewReader(data[3:9]), bytes.NewReader(data[9:])))
+	f, err := r.Next()
+	if err != nil || f.Class != 0x0A || f.ID != 0x04 || !bytes.Equal(f.Payload, payload) {
+		t.Errorf("get %+v (err=%v)", f, err)
+	}
+	if _, err := r.Next(); err != io.EOF || r.Stats.Skipped != 2 {
+		t.Errorf("end: get err=%v, skipped %d", err, r.Stats.Skipped)
+	}
+}
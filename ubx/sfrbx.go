@@ -0,0 +1,57 @@
+package ubx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// Subframe is the broadcast navigation data of RXM-SFRBX, e.g., a subframe
+// of GPS LNAV, a page of Galileo I/NAV and a string of GLONASS, for the
+// decoding of the ephemerides.
+type Subframe struct {
+	// Sat is the satellite, which is zero for the unknown satellites, and
+	// GNSSID, SvID, SigID and FreqID are the identifiers of the message.
+	Sat                         gnss.SatID
+	GNSSID, SvID, SigID, FreqID int
+
+	// Channel is the tracking channel, and Version is the version of the
+	// message.
+	Channel int
+	Version int
+
+	// Time is the epoch of the last RXM-RAWX of Decoder before the message
+	// for the resolution of the times of the data, or zero.
+	Time time.Time
+
+	// Words is the data words of the message in the layout of the systems,
+	// e.g., the 10 words of 30 bits of the bits 29 to 0 with the parity of
+	// GPS LNAV.
+	Words []uint32
+}
+
+// DecodeSubframe decodes the payload of RXM-SFRBX.
+func DecodeSubframe(payload []byte) (*Subframe, error) {
+	if len(payload) < 8 {
+		return nil, fmt.Errorf("%w: RXM-SFRBX: %d bytes", ErrFormat, len(payload))
+	}
+	n := u1(payload, 4)
+	if len(payload) != 8+4*n {
+		return nil, fmt.Errorf("%w: RXM-SFRBX: %d bytes of %d words", ErrFormat, len(payload), n)
+	}
+	s := &Subframe{
+		GNSSID:  u1(payload, 0),
+		SvID:    u1(payload, 1),
+		SigID:   u1(payload, 2),
+		FreqID:  u1(payload, 3),
+		Channel: u1(payload, 5),
+		Version: u1(payload, 6),
+		Words:   make([]uint32, n),
+	}
+	s.Sat, _ = satID(s.GNSSID, s.SvID)
+	for k := range s.Words {
+		s.Words[k] = u4(payload, 8+4*k)
+	}
+	return s, nil
+}
@@ -0,0 +1,45 @@
+package ubx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// TestDecodeSubframe checks the subframes of G05 and R12 of the fixture,
+// and the payloads of the invalid lengths.
+func TestDecodeSubframe(t *testing.T) {
+	fs := testFrames(t, TypeSFRBX)
+	if len(fs) != 2 {
+		t.Fatalf("frames: get %d, want 2", len(fs))
+	}
+	s, err := DecodeSubframe(fs[0].Payload)
+	switch {
+	case err != nil:
+		t.Fatalf("DecodeSubframe: %v", err)
+	case s.Sat != gnss.SatID{Sys: gnss.GPS, PRN: 5} || s.SigID != 0 || s.Channel != 3 || s.Version != 2 || len(s.Words) != 10:
+		t.Errorf("G05: %+v", s)
+	case s.Words[0] != 0x22C1234 || s.Words[9] != 0x22C1234+9*0x0101010:
+		t.Errorf("G05: words %08X", s.Words)
+	}
+
+	s, err = DecodeSubframe(fs[1].Payload)
+	switch {
+	case err != nil:
+		t.Fatalf("DecodeSubframe: %v", err)
+	case s.Sat != gnss.SatID{Sys: gnss.GLONASS, PRN: 12} || s.FreqID != 4 || s.Channel != 7 || len(s.Words) != 4:
+		t.Errorf("R12: %+v", s)
+	case s.Words[0] != 0x12345678 || s.Words[1] != 0x9ABCDEF0 || s.Words[3] != 1:
+		t.Errorf("R12: words %08X", s.Words)
+	}
+
+	p := fs[1].Payload
+	for _, n := range []int{0, 7, len(p) - 1, len(p) + 4} {
+		q := make([]byte, n)
+		copy(q, p)
+		if _, err := DecodeSubframe(q); !errors.Is(err, ErrFormat) {
+			t.Errorf("%d bytes: get err=%v, want %v", n, err, ErrFormat)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package ubx
+
+import "github.com/satoshi-pes/gnss"
+
+// gnssSystems is the satellite systems of the GNSS IDs of UBX.
+var gnssSystems = map[int]gnss.SatelliteSystem{
+	0: gnss.GPS, 1: gnss.SBAS, 2: gnss.Galileo, 3: gnss.BeiDou,
+	5: gnss.QZSS, 6: gnss.GLONASS, 7: gnss.NavIC,
+}
+
+// ubxSignals is the observation codes of RINEX 3 without the types of the
+// signal IDs of the systems of the u-blox generation 9 receivers, where the
+// signal ID of the older receivers is zero, i.e., of L1 C/A.
+var ubxSignals = map[gnss.SatelliteSystem]map[int]string{
+	gnss.GPS:     {0: "1C", 3: "2L", 4: "2S", 6: "5I", 7: "5Q"},
+	gnss.SBAS:    {0: "1C"},
+	gnss.Galileo: {0: "1C", 1: "1B", 3: "5I", 4: "5Q", 5: "7I", 6: "7Q", 8: "6B", 9: "6C", 10: "6A"},
+	gnss.BeiDou:  {0: "2I", 1: "2I", 2: "7I", 3: "7I", 4: "6I", 5: "1P", 6: "1D", 7: "5P", 8: "5D", 10: "6I"},
+	gnss.QZSS:    {0: "1C", 1: "1Z", 4: "2S", 5: "2L", 8: "5I", 9: "5Q"},
+	gnss.GLONASS: {0: "1C", 2: "2C"},
+	gnss.NavIC:   {0: "5A"},
+}
+
+// satID returns the satellite of the GNSS ID and the satellite ID, i.e.,
+// the PRNs of SBAS minus 100, and false for the unknown satellites, e.g.,
+// the slot 255 of GLONASS.
+func satID(gnssID, svID int) (gnss.SatID, bool) {
+	sys, ok := gnssSystems[gnssID]
+	if !ok {
+		return gnss.SatID{}, false
+	}
+	prn := svID
+	if sys == gnss.SBAS {
+		prn -= 100
+	}
+	if prn < 1 || prn > 99 {
+		return gnss.SatID{}, false
+	}
+	return gnss.SatID{Sys: sys, PRN: prn}, true
+}
+
+// signalCode returns the observation code of the signal ID of the system,
+// e.g., "1C", and false if unknown.
+func signalCode(sys gnss.SatelliteSystem, sigID int) (string, bool) {
+	code, ok := ubxSignals[sys][sigID]
+	return code, ok
+}
@@ -0,0 +1,52 @@
+package ubx
+
+import (
+	"testing"
+
+	"github.com/satoshi-pes/gnss"
+)
+
+// TestSatID checks the satellites of the GNSS IDs and the satellite IDs.
+func TestSatID(t *testing.T) {
+	for _, tt := range []struct {
+		gnssID, svID int
+		want         string
+		ok           bool
+	}{
+		{0, 32, "G32", true},
+		{1, 120, "S20", true},
+		{2, 36, "E36", true},
+		{3, 63, "C63", true},
+		{5, 7, "J07", true},
+		{6, 24, "R24", true},
+		{7, 14, "I14", true},
+		{6, 255, "", false},
+		{4, 1, "", false},
+		{1, 100, "", false},
+	} {
+		id, ok := satID(tt.gnssID, tt.svID)
+		if id.String() != tt.want || ok != tt.ok {
+			t.Errorf("%d %d: get %v %v, want %s %v", tt.gnssID, tt.svID, id, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestSignalCode checks the codes of the signal IDs.
+func TestSignalCode(t *testing.T) {
+	for _, tt := range []struct {
+		sys   gnss.SatelliteSystem
+		sigID int
+		want  string
+	}{
+		{gnss.GPS, 0, "1C"}, {gnss.GPS, 3, "2L"}, {gnss.GPS, 4, "2S"}, {gnss.GPS, 7, "5Q"},
+		{gnss.Galileo, 1, "1B"}, {gnss.Galileo, 6, "7Q"},
+		{gnss.BeiDou, 1, "2I"}, {gnss.BeiDou, 3, "7I"}, {gnss.BeiDou, 7, "5P"},
+		{gnss.QZSS, 1, "1Z"}, {gnss.QZSS, 5, "2L"},
+		{gnss.GLONASS, 2, "2C"},
+		{gnss.GPS, 5, ""}, {gnss.GLONASS, 1, ""},
+	} {
+		if get, ok := signalCode(tt.sys, tt.sigID); get != tt.want || ok != (tt.want != "") {
+			t.Errorf("%v %d: get %q %v, want %q", tt.sys, tt.sigID, get, ok, tt.want)
+		}
+	}
+}
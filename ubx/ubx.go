@@ -0,0 +1,75 @@
+/*
+Package ubx reads the UBX protocol of the u-blox receivers, i.e., the frames
+of the binary messages, and decodes the raw measurements of RXM-RAWX into the
+epochs of the observations of the rinex package with the observation codes
+of RINEX 3, and the navigation data of RXM-SFRBX for the ephemerides.
+
+The epochs are in GPS time, represented as time.Time in UTC without the leap
+seconds as the other packages of the module, and the fields of the messages
+are little-endian.
+*/
+package ubx
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	// ErrFormat is returned for the messages of the invalid contents, e.g.,
+	// shorter than the fields.
+	ErrFormat = errors.New("invalid ubx message")
+
+	// ErrUnsupported is returned for the messages not decoded.
+	ErrUnsupported = errors.New("unsupported ubx message")
+)
+
+// the sync characters of the frames
+const (
+	sync1 = 0xB5
+	sync2 = 0x62
+)
+
+// maxPayload is the maximum length of the payload of the frames read
+// (bytes), e.g., of RXM-RAWX of 255 measurements. The frames longer are
+// skipped as the garbage.
+const maxPayload = 8192
+
+// The message types of the class and the ID, e.g., 0x0215 of RXM-RAWX.
+const (
+	TypeRAWX  = 0x0215 // RXM-RAWX
+	TypeSFRBX = 0x0213 // RXM-SFRBX
+)
+
+// Frame is a frame of a message.
+type Frame struct {
+	// Class and ID are the message class and the message ID.
+	Class, ID byte
+
+	// Payload is the message without the header and the checksum.
+	Payload []byte
+}
+
+// Type returns the message type of the class and the ID, e.g., TypeRAWX.
+func (f Frame) Type() int {
+	return int(f.Class)<<8 | int(f.ID)
+}
+
+// checksum returns the 8-bit Fletcher checksum of the data, i.e., of the
+// class, the ID, the length and the payload.
+func checksum(data []byte) (a, b byte) {
+	for _, c := range data {
+		a += c
+		b += a
+	}
+	return a, b
+}
+
+// the little-endian fields of the payload at the offset
+func u1(p []byte, i int) int     { return int(p[i]) }
+func i1(p []byte, i int) int     { return int(int8(p[i])) }
+func u2(p []byte, i int) int     { return int(binary.LittleEndian.Uint16(p[i:])) }
+func u4(p []byte, i int) uint32  { return binary.LittleEndian.Uint32(p[i:]) }
+func r4(p []byte, i int) float64 { return float64(math.Float32frombits(u4(p, i))) }
+func r8(p []byte, i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(p[i:])) }
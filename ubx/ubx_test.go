@@ -0,0 +1,51 @@
+package ubx
+
+import (
+	"testing"
+)
+
+// the synthetic stream of the garbage bytes of 11 bytes including the
+// sync characters and the frame of the length too long, NAV-PVT, RXM-RAWX
+// of 10 measurements at TOW 345600.0005 s of the week 2322, RXM-SFRBX of
+// G05, RXM-SFRBX of R12 of the invalid checksum (32 bytes) followed by the
+// valid one, RXM-RAWX of the invalid length, RXM-RAWX at TOW 345601.0005 s,
+// and 20 bytes of a frame truncated at the end
+const testFile = "testdata/rawx_sfrbx.ubx"
+
+// testFrame returns the frame of the class, the ID and the payload.
+func testFrame(class, id byte, payload []byte) []byte {
+	b := []byte{sync1, sync2, class, id, byte(len(payload)), byte(len(payload) >> 8)}
+	b = append(b, payload...)
+	ca, cb := checksum(b[2:])
+	return append(b, ca, cb)
+}
+
+// TestChecksum checks the checksum of the frame of the poll request of
+// MON-VER, i.e., B5 62 0A 04 00 00 0E 34.
+func TestChecksum(t *testing.T) {
+	if a, b := checksum([]byte{0x0A, 0x04, 0x00, 0x00}); a != 0x0E || b != 0x34 {
+		t.Errorf("get %02X %02X, want 0E 34", a, b)
+	}
+	if f := (Frame{Class: 0x02, ID: 0x15}); f.Type() != TypeRAWX {
+		t.Errorf("type: get %04X, want %04X", f.Type(), TypeRAWX)
+	}
+}
+
+// TestFields checks the little-endian fields.
+func TestFields(t *testing.T) {
+	p := []byte{
+		0xFE, 0x34, 0x12, 0x78, 0x56, 0x34, 0x12, // u1, i1, u2, u4
+		0x00, 0x00, 0xC0, 0x3F, // r4 1.5
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0xC0, // r8 -2.5
+	}
+	switch {
+	case u1(p, 0) != 254 || i1(p, 0) != -2:
+		t.Errorf("u1, i1: %d %d", u1(p, 0), i1(p, 0))
+	case u2(p, 1) != 0x1234:
+		t.Errorf("u2: %04X", u2(p, 1))
+	case u4(p, 3) != 0x12345678:
+		t.Errorf("u4: %08X", u4(p, 3))
+	case r4(p, 7) != 1.5 || r8(p, 11) != -2.5:
+		t.Errorf("r4, r8: %v %v", r4(p, 7), r8(p, 11))
+	}
+}